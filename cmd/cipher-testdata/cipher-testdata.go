@@ -25,6 +25,7 @@ const (
 	inputTestDataFilename = "input-hashes.golden"
 	manyAddressesFilename = "many-addresses.golden"
 	seedFilenameFormat    = "seed-%04d.golden"
+	rfc6979Filename       = "rfc6979.golden"
 	randomSeedLength      = 1024
 )
 
@@ -40,6 +41,8 @@ Each of these files contains a seed, a number of secret keys,
 public keys and addresses generated from this seed.
 For each secret key, each hash from inputs will be signed,
 and the result saved to the file.
+Signatures use RFC6979 deterministic nonces, so the testsuite
+compares them exactly.
 Half of the seeds will be generated as SHA256(RandByte(1024)) and half will
 be generated as bip39 seeds. Seeds are base64 encoded in the JSON file.
 
@@ -52,7 +55,45 @@ public keys and addresses generated from this seed.
 The number of secret keys generated is much larger than for the other seeds.
 This file is used to test deterministic key generation more thoroughly.
 This file will not contain any signatures,
-because the filesize would be too large.`, inputTestDataFilename, manyAddressesFilename)
+because the filesize would be too large.
+
+A file named %s will be generated,
+which contains the published secp256k1 RFC6979 HMAC-SHA256 test vectors.
+Each vector has a secret key, a message, the SHA256 hash of the message
+and its signature.`, inputTestDataFilename, manyAddressesFilename, rfc6979Filename)
+
+// rfc6979Vectors are the published secp256k1 RFC6979 HMAC-SHA256 test vectors
+var rfc6979Vectors = []struct {
+	secret    string
+	message   string
+	signature string
+}{
+	{
+		secret:    "0000000000000000000000000000000000000000000000000000000000000001",
+		message:   "Satoshi Nakamoto",
+		signature: "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d82442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e501",
+	},
+	{
+		secret:    "0000000000000000000000000000000000000000000000000000000000000001",
+		message:   "All those moments will be lost in time, like tears in rain. Time to die...",
+		signature: "8600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc2100",
+	},
+	{
+		secret:    "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+		message:   "Satoshi Nakamoto",
+		signature: "fd567d121db66e382991534ada77a6bd3106f0a1098c231e47993447cd6af2d06b39cd0eb1bc8603e159ef5c20a5c8ad685a45b06ce9bebed3f153d10d93bed500",
+	},
+	{
+		secret:    "f8b8af8ce3c7cca5e300d33939540c10d45ce001b8f252bfbc57ba0342904181",
+		message:   "Alan Turing",
+		signature: "7063ae83e7f62bbb171798131b4a0564b956930092b33b07b395615d9ec7e15c58dfcc1e00a35e1572f366ffe34ba0fc47db1e7189759b9fb233c5b05ab388ea00",
+	},
+	{
+		secret:    "e91671c46231f833a6406ccbea0e3e392c76c167bac1cb013f6f1013980455c2",
+		message:   "There is a computer disease that anybody who works with computers knows about. It's a very serious disease and it interferes completely with the work. The trouble with computers is that you 'play' with them!",
+		signature: "b552edd27580141f3b2a5463048cb7cd3e047b97c9f98076c32dbdf85a68718b279fa72dd19bfae05577e06c7c0c1900c371fcd5893f7e1d56a37d30174671f601",
+	},
+}
 
 type job struct {
 	jobID        int
@@ -109,6 +150,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	fmt.Println("Generating", rfc6979Filename)
+
+	// Save the RFC6979 test vectors, after checking that they are reproduced by the cipher library
+	rfc6979Data := generateRFC6979TestData()
+	if err := testsuite.ValidateRFC6979Data(rfc6979Data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fn = filepath.Join(*outputDir, rfc6979Filename)
+	if err := file.SaveJSON(fn, rfc6979Data.ToJSON(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	fmt.Println("Generating seed data times", *seedsCount)
 
 	jobs := make([]job, 0, *seedsCount+1)
@@ -187,6 +242,22 @@ func generateInputTestData(inputsCount int) *testsuite.InputTestData {
 	}
 }
 
+func generateRFC6979TestData() *testsuite.RFC6979TestData {
+	vectors := make([]testsuite.RFC6979Vector, len(rfc6979Vectors))
+	for i, v := range rfc6979Vectors {
+		vectors[i] = testsuite.RFC6979Vector{
+			Secret:    cipher.MustSecKeyFromHex(v.secret),
+			Message:   []byte(v.message),
+			Hash:      cipher.SumSHA256([]byte(v.message)),
+			Signature: cipher.MustSigFromHex(v.signature),
+		}
+	}
+
+	return &testsuite.RFC6979TestData{
+		Vectors: vectors,
+	}
+}
+
 func generateSeedTestData(j job) *testsuite.SeedTestData {
 	data := &testsuite.SeedTestData{
 		Seed: j.seed,
@@ -0,0 +1,216 @@
+/*
+crawler crawls the skycoin peer network
+
+It connects to the seed peers, performs the introduction handshake and requests peers recursively,
+recording the user agent, height and node pubkey of each peer it reaches.
+The result can be written as JSON or CSV, and served over HTTP as a seed list.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon/crawler"
+	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/useragent"
+)
+
+var (
+	// Version of the crawler. Can be set by -ldflags
+	Version = "0.25.1-dev"
+
+	logger = logging.MustGetLogger("main")
+
+	// CoinName name of coin
+	CoinName = "skycoin"
+
+	// BlockchainPubkeyStr pubic key string
+	BlockchainPubkeyStr = "0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a"
+
+	// DefaultConnections the default trust node addresses, used as the default seeds
+	DefaultConnections = []string{
+		"118.178.135.93:6000",
+		"47.88.33.156:6000",
+		"121.41.103.148:6000",
+		"104.237.142.206:6000",
+		"176.58.126.224:6000",
+		"172.104.85.6:6000",
+		"139.162.7.132:6000",
+		"139.162.39.186:6000",
+		"45.33.111.142:6000",
+		"109.237.27.172:6000",
+		"172.104.41.14:6000",
+	}
+)
+
+const help = `crawler crawls the peer network, starting from the seed peers.
+
+It connects to each peer, performs the introduction handshake and requests peers with a GetPeersMessage,
+then crawls the peers that were received, until no new peers are found.
+The user agent, height and node pubkey of each reachable peer are recorded,
+along with the peers that it reported.
+
+The result is written to the -json and -csv files. If neither is given and -http is not set,
+the JSON result is printed to stdout.
+
+If -http is set, the result is served on that address, and the network is crawled again every -interval:
+
+	/peers.txt   reachable peers, one ip:port per line. This can be used as a node's -peerlist-url
+	/nodes.json  the crawl result as JSON
+	/nodes.csv   the crawled peers as CSV`
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "%s\n\nUsage of %s:\n", help, os.Args[0])
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	cfg := crawler.NewConfig()
+
+	seeds := flag.String("seeds", strings.Join(DefaultConnections, ","), "comma separated list of seed peers to crawl from")
+	blockchainPubkeyStr := flag.String("blockchain-pubkey", BlockchainPubkeyStr, "blockchain pubkey of the network to crawl")
+	coin := flag.String("coin", CoinName, "coin name sent in the user agent")
+	listenPort := flag.Int("port", int(cfg.ListenPort), "listen port sent in the introduction")
+	flag.IntVar(&cfg.Workers, "workers", cfg.Workers, "number of peers to crawl at once")
+	flag.DurationVar(&cfg.DialTimeout, "dial-timeout", cfg.DialTimeout, "how long to wait to connect to a peer")
+	flag.DurationVar(&cfg.PeerTimeout, "peer-timeout", cfg.PeerTimeout, "how long to stay connected to a peer, waiting for its introduction, peers and height")
+	flag.IntVar(&cfg.MaxPeers, "max-peers", cfg.MaxPeers, "maximum number of peers to crawl. 0 for no limit")
+	flag.BoolVar(&cfg.AllowLocalhost, "localhost", cfg.AllowLocalhost, "crawl localhost peers")
+	jsonFile := flag.String("json", "", "write the crawl result as JSON to this file")
+	csvFile := flag.String("csv", "", "write the crawled peers as CSV to this file")
+	httpAddr := flag.String("http", "", "serve the crawl result and seed list over HTTP on this address, e.g. 127.0.0.1:8080")
+	interval := flag.Duration("interval", time.Minute*30, "how often to crawl again when serving over HTTP")
+	logLevel := flag.String("log-level", "info", "choices are: debug, info, warn, error, fatal, panic")
+
+	flag.Parse()
+
+	// Logs go to stderr, so that the JSON result can be printed to stdout
+	logging.SetOutputTo(os.Stderr)
+	level, err := logging.LevelFromString(*logLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logging.SetLevel(level)
+
+	if err := run(cfg, *seeds, *blockchainPubkeyStr, *coin, *listenPort, *jsonFile, *csvFile, *httpAddr, *interval); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg crawler.Config, seeds, blockchainPubkeyStr, coin string, listenPort int, jsonFile, csvFile, httpAddr string, interval time.Duration) error {
+	for _, s := range strings.Split(seeds, ",") {
+		s = strings.TrimSpace(s)
+		if s != "" {
+			cfg.Seeds = append(cfg.Seeds, s)
+		}
+	}
+
+	pubkey, err := cipher.PubKeyFromHex(blockchainPubkeyStr)
+	if err != nil {
+		return fmt.Errorf("Invalid -blockchain-pubkey: %v", err)
+	}
+	cfg.BlockchainPubkey = pubkey
+
+	if listenPort <= 0 || listenPort > 65535 {
+		return fmt.Errorf("Invalid -port %d", listenPort)
+	}
+	cfg.ListenPort = uint16(listenPort)
+
+	cfg.UserAgent = useragent.Data{
+		Coin:    coin,
+		Version: Version,
+		Remark:  "crawler",
+	}
+
+	c, err := crawler.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	quit := make(chan struct{})
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigC
+		logger.Info("Stopping the crawl")
+		close(quit)
+	}()
+
+	if httpAddr == "" {
+		return writeResult(c.Crawl(quit), jsonFile, csvFile, jsonFile == "" && csvFile == "")
+	}
+
+	h := crawler.NewHandler()
+	errC := make(chan error, 1)
+	go func() {
+		logger.Infof("Serving the crawl result on http://%s", httpAddr)
+		errC <- http.ListenAndServe(httpAddr, h)
+	}()
+
+	for {
+		r := c.Crawl(quit)
+		h.SetResult(r)
+
+		if err := writeResult(r, jsonFile, csvFile, false); err != nil {
+			return err
+		}
+
+		select {
+		case <-quit:
+			return nil
+		case err := <-errC:
+			return err
+		case <-time.After(interval):
+		}
+	}
+}
+
+// writeResult writes the crawl result to the JSON and CSV files that are set, and to stdout as JSON if stdout is true
+func writeResult(r crawler.Result, jsonFile, csvFile string, stdout bool) error {
+	logger.Infof("Crawled %d peers, %d reachable", r.Stats.Nodes, r.Stats.Reachable)
+
+	if stdout {
+		return r.WriteJSON(os.Stdout)
+	}
+
+	if jsonFile != "" {
+		if err := writeFile(jsonFile, r.WriteJSON); err != nil {
+			return err
+		}
+	}
+
+	if csvFile != "" {
+		if err := writeFile(csvFile, r.WriteCSV); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFile(fn string, write func(w io.Writer) error) error {
+	f, err := os.Create(fn)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+
+	return f.Close()
+}
@@ -5,7 +5,9 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"regexp"
+	"strings"
 
 	"os"
 	"path/filepath"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/urfave/cli"
 
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/skycoin"
 	"github.com/skycoin/skycoin/src/util/logging"
 	"github.com/skycoin/skycoin/src/util/useragent"
@@ -34,6 +37,7 @@ func init() {
 	app.Version = Version
 	commands := cli.Commands{
 		createCoinCommand(),
+		createKeystoreCommand(),
 	}
 
 	app.Commands = commands
@@ -136,6 +140,11 @@ func createCoinCommand() cli.Command {
 				return err
 			}
 
+			if err := validateAddressVersion(config); err != nil {
+				log.Errorf("invalid address_version %d", config.Params.AddressVersion)
+				return err
+			}
+
 			coinDir := fmt.Sprintf("./cmd/%s", coinName)
 			// create new coin directory
 			// MkdirAll does not error out if the directory already exists
@@ -216,6 +225,80 @@ func createCoinCommand() cli.Command {
 	}
 }
 
+func createKeystoreCommand() cli.Command {
+	name := "createkeystore"
+	return cli.Command{
+		Name:  name,
+		Usage: "Create a keystore file with an encrypted blockchain secret key, for the block publisher",
+		Description: fmt.Sprintf("A new key pair is generated, unless a secret key is imported with --seckey-file.\n"+
+			"   The password is read from --password-file, the %s environment variable, or the terminal.\n"+
+			"   Set blockchain_pubkey_str in the config file to the printed pubkey, and run the block publisher\n"+
+			"   with -block-publisher -blockchain-keystore <keystore file>.", skycoin.KeystorePasswordEnv),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "output, o",
+				Usage: "keystore file path. An existing file is not overwritten",
+				Value: "blockchain.keystore",
+			},
+			cli.StringFlag{
+				Name:  "password-file, p",
+				Usage: "file containing the keystore password",
+			},
+			cli.StringFlag{
+				Name:  "seckey-file, s",
+				Usage: "file containing a hex encoded secret key to import, instead of generating a new key pair",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var sk cipher.SecKey
+			if seckeyFile := c.String("seckey-file"); seckeyFile != "" {
+				b, err := ioutil.ReadFile(seckeyFile)
+				if err != nil {
+					return err
+				}
+
+				sk, err = cipher.SecKeyFromHex(strings.TrimSpace(string(b)))
+				if err != nil {
+					return fmt.Errorf("invalid secret key in %s: %v", seckeyFile, err)
+				}
+			} else {
+				_, sk = cipher.GenerateKeyPair()
+			}
+
+			password, err := readNewKeystorePassword(c.String("password-file"))
+			if err != nil {
+				return err
+			}
+
+			ks, err := skycoin.NewKeystore(sk, password)
+			if err != nil {
+				return err
+			}
+
+			output := c.String("output")
+			if err := ks.Save(output); err != nil {
+				log.Errorf("failed to save keystore file %s", output)
+				return err
+			}
+
+			fmt.Printf("Created keystore %s\n", output)
+			fmt.Printf("blockchain_pubkey_str = \"%s\"\n", ks.PubKey)
+
+			return nil
+		},
+	}
+}
+
+// readNewKeystorePassword reads the password of a new keystore from a file, the environment,
+// or the terminal, where it must be entered twice
+func readNewKeystorePassword(passwordFile string) ([]byte, error) {
+	if passwordFile != "" || os.Getenv(skycoin.KeystorePasswordEnv) != "" {
+		return skycoin.ReadKeystorePassword(passwordFile)
+	}
+
+	return skycoin.PromptNewKeystorePassword()
+}
+
 func validateCoinName(s string) error {
 	x := regexp.MustCompile(fmt.Sprintf(`^%s$`, useragent.NamePattern))
 	if !x.MatchString(s) {
@@ -224,6 +307,31 @@ func validateCoinName(s string) error {
 	return nil
 }
 
+// validateAddressVersion checks that the genesis address and the distribution addresses
+// have the configured address version, otherwise the generated coin would fail to start
+func validateAddressVersion(config skycoin.Parameters) error {
+	addrs := append([]string{config.Node.GenesisAddressStr}, config.Params.DistributionAddresses...)
+	for _, a := range addrs {
+		if a == "" {
+			continue
+		}
+
+		addr, err := cipher.DecodeBase58AddressAnyVersion(a)
+		if err != nil {
+			return fmt.Errorf("invalid address %s: %v", a, err)
+		}
+
+		if addr.Version != config.Params.AddressVersion {
+			version := addr.Version
+			addr.Version = config.Params.AddressVersion
+			return fmt.Errorf("address %s has version %d, but address_version is %d. The address of the same key with version %d is %s",
+				a, version, addr.Version, addr.Version, addr.String())
+		}
+	}
+
+	return nil
+}
+
 func main() {
 	if e := app.Run(os.Args); e != nil {
 		log.Fatal(e)
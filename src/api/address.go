@@ -2,9 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/params"
 )
 
 // VerifyAddressRequest is the request data for POST /api/v2/address/verify
@@ -15,48 +17,72 @@ type VerifyAddressRequest struct {
 // VerifyAddressResponse is returned by POST /api/v2/address/verify
 type VerifyAddressResponse struct {
 	Version byte `json:"version"`
+	// Coin is the name of the coin whose chain the address belongs to.
+	// It is empty if the address belongs to the chain of an unknown coin
+	Coin string `json:"coin,omitempty"`
 }
 
 // addressVerifyHandler verifies a Skycoin address
 // Method: POST
 // URI: /api/v2/address/verify
-func addressVerifyHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
-		writeHTTPResponse(w, resp)
-		return
-	}
+func addressVerifyHandler(coinName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
 
-	if r.Header.Get("Content-Type") != ContentTypeJSON {
-		resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
-		writeHTTPResponse(w, resp)
-		return
-	}
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
 
-	var req VerifyAddressRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
-		writeHTTPResponse(w, resp)
-		return
-	}
+		var req VerifyAddressRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
 
-	if req.Address == "" {
-		resp := NewHTTPErrorResponse(http.StatusBadRequest, "address is required")
-		writeHTTPResponse(w, resp)
-		return
-	}
+		if req.Address == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "address is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
 
-	addr, err := cipher.DecodeBase58Address(req.Address)
+		addr, err := cipher.DecodeBase58AddressAnyVersion(req.Address)
 
-	if err != nil {
-		resp := NewHTTPErrorResponse(http.StatusUnprocessableEntity, err.Error())
-		writeHTTPResponse(w, resp)
-		return
-	}
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusUnprocessableEntity, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		// An address of another chain is invalid, but its version and the name of the coin
+		// with that address version are reported, so that the client can tell which chain it belongs to
+		if addr.Version != cipher.AddressVersion {
+			chain := "another chain"
+			otherCoin, ok := params.AddressVersionCoin(addr.Version)
+			if ok {
+				chain = otherCoin
+			}
 
-	writeHTTPResponse(w, HTTPResponse{
-		Data: VerifyAddressResponse{
-			Version: addr.Version,
-		},
-	})
+			resp := NewHTTPErrorResponse(http.StatusUnprocessableEntity, fmt.Sprintf("%v: the address belongs to %s", cipher.ErrAddressInvalidVersion, chain))
+			resp.Data = VerifyAddressResponse{
+				Version: addr.Version,
+				Coin:    otherCoin,
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: VerifyAddressResponse{
+				Version: addr.Version,
+				Coin:    coinName,
+			},
+		})
+	}
 }
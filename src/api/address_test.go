@@ -8,6 +8,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
 )
 
 func toJSON(t *testing.T, r interface{}) string {
@@ -17,14 +21,19 @@ func toJSON(t *testing.T, r interface{}) string {
 }
 
 func TestVerifyAddress(t *testing.T) {
+	testCoinName := "testcoin"
+	otherChainAddr := testutil.MakeAddress()
+	otherChainAddr.Version = 7
+
 	cases := []struct {
-		name         string
-		method       string
-		status       int
-		contentType  string
-		csrfDisabled bool
-		httpBody     string
-		httpResponse HTTPResponse
+		name           string
+		method         string
+		status         int
+		contentType    string
+		csrfDisabled   bool
+		addressVersion byte
+		httpBody       string
+		httpResponse   HTTPResponse
 	}{
 		{
 			name:         "405",
@@ -67,6 +76,42 @@ func TestVerifyAddress(t *testing.T) {
 			}),
 			httpResponse: NewHTTPErrorResponse(http.StatusUnprocessableEntity, "Invalid checksum"),
 		},
+		{
+			name:   "422 - Address of another chain",
+			method: http.MethodPost,
+			status: http.StatusUnprocessableEntity,
+			httpBody: toJSON(t, VerifyAddressRequest{
+				Address: otherChainAddr.String(),
+			}),
+			httpResponse: HTTPResponse{
+				Error: &HTTPError{
+					Code:    http.StatusUnprocessableEntity,
+					Message: "Address version invalid: the address belongs to another chain",
+				},
+				Data: VerifyAddressResponse{
+					Version: 7,
+				},
+			},
+		},
+		{
+			name:           "422 - Address of a known coin's chain",
+			method:         http.MethodPost,
+			status:         http.StatusUnprocessableEntity,
+			addressVersion: 7,
+			httpBody: toJSON(t, VerifyAddressRequest{
+				Address: "7cpQ7t3PZZXvjTst8G7Uvs7XH4LeM8fBPD",
+			}),
+			httpResponse: HTTPResponse{
+				Error: &HTTPError{
+					Code:    http.StatusUnprocessableEntity,
+					Message: "Address version invalid: the address belongs to skycoin",
+				},
+				Data: VerifyAddressResponse{
+					Version: 0,
+					Coin:    "skycoin",
+				},
+			},
+		},
 		{
 			name:   "200",
 			method: http.MethodPost,
@@ -77,6 +122,7 @@ func TestVerifyAddress(t *testing.T) {
 			httpResponse: HTTPResponse{
 				Data: VerifyAddressResponse{
 					Version: 0,
+					Coin:    testCoinName,
 				},
 			},
 		},
@@ -90,6 +136,7 @@ func TestVerifyAddress(t *testing.T) {
 			httpResponse: HTTPResponse{
 				Data: VerifyAddressResponse{
 					Version: 0,
+					Coin:    testCoinName,
 				},
 			},
 			csrfDisabled: true,
@@ -98,6 +145,13 @@ func TestVerifyAddress(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
+			if tc.addressVersion != 0 {
+				cipher.AddressVersion = tc.addressVersion
+				defer func() {
+					cipher.AddressVersion = params.AddressVersion
+				}()
+			}
+
 			endpoint := "/api/v2/address/verify"
 			gateway := &MockGatewayer{}
 
@@ -120,6 +174,7 @@ func TestVerifyAddress(t *testing.T) {
 			rr := httptest.NewRecorder()
 			cfg := defaultMuxConfig()
 			cfg.disableCSRF = tc.csrfDisabled
+			cfg.health.CoinName = testCoinName
 			handler := newServerMux(cfg, gateway, nil)
 			handler.ServeHTTP(rr, req)
 
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
@@ -74,6 +75,124 @@ func blockchainProgressHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// DoubleSignProofsResponse is returned by /api/v1/blockchain/double_sign_proofs
+type DoubleSignProofsResponse struct {
+	Proofs []readable.DoubleSignProof `json:"proofs"`
+}
+
+// doubleSignProofsHandler returns the saved proofs that the block publisher signed conflicting blocks
+// Method: GET
+// URI: /api/v1/blockchain/double_sign_proofs
+func doubleSignProofsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		proofs, err := gateway.GetDoubleSignProofs()
+		if err != nil {
+			err = fmt.Errorf("gateway.GetDoubleSignProofs failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		rProofs := make([]readable.DoubleSignProof, len(proofs))
+		for i, p := range proofs {
+			rp, err := readable.NewDoubleSignProof(p)
+			if err != nil {
+				wh.Error500(w, err.Error())
+				return
+			}
+			rProofs[i] = *rp
+		}
+
+		wh.SendJSONOr500(logger, w, DoubleSignProofsResponse{
+			Proofs: rProofs,
+		})
+	}
+}
+
+// BlockPublisherPolicy is the block publishing policy returned by /api/v2/blockchain/publisher
+type BlockPublisherPolicy struct {
+	MaxLatency wh.Duration `json:"max_latency"`
+	MinSpacing wh.Duration `json:"min_spacing"`
+	BurstSize  uint64      `json:"burst_size"`
+	Heartbeat  wh.Duration `json:"heartbeat"`
+}
+
+// BlockPublisherStats are the block publishing stats returned by /api/v2/blockchain/publisher
+type BlockPublisherStats struct {
+	Published      uint64      `json:"published"`
+	BurstPublished uint64      `json:"burst_published"`
+	Failures       uint64      `json:"failures"`
+	SpacingDelays  uint64      `json:"spacing_delays"`
+	Heartbeats     uint64      `json:"heartbeats"`
+	LastPublished  int64       `json:"last_published"`
+	LastHeartbeat  int64       `json:"last_heartbeat"`
+	PendingSince   int64       `json:"pending_since"`
+	LastLatency    wh.Duration `json:"last_latency"`
+	MaxLatency     wh.Duration `json:"max_latency"`
+}
+
+// BlockPublisherResponse is returned by /api/v2/blockchain/publisher
+type BlockPublisherResponse struct {
+	Policy BlockPublisherPolicy `json:"policy"`
+	Stats  BlockPublisherStats  `json:"stats"`
+}
+
+// unixOrZero returns the unix time of t, or 0 if t is the zero time
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// Returns the block publishing policy and stats of the block publisher.
+// Returns 403 if this node is not a block publisher
+// Method: GET
+// URI: /api/v2/blockchain/publisher
+func blockPublisherHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		policy, stats, ok := gateway.GetBlockPublishStats()
+		if !ok {
+			resp := NewHTTPErrorResponse(http.StatusForbidden, "this node is not a block publisher")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: BlockPublisherResponse{
+				Policy: BlockPublisherPolicy{
+					MaxLatency: wh.FromDuration(policy.MaxLatency),
+					MinSpacing: wh.FromDuration(policy.MinSpacing),
+					BurstSize:  policy.BurstSize,
+					Heartbeat:  wh.FromDuration(policy.Heartbeat),
+				},
+				Stats: BlockPublisherStats{
+					Published:      stats.Published,
+					BurstPublished: stats.BurstPublished,
+					Failures:       stats.Failures,
+					SpacingDelays:  stats.SpacingDelays,
+					Heartbeats:     stats.Heartbeats,
+					LastPublished:  unixOrZero(stats.LastPublished),
+					LastHeartbeat:  unixOrZero(stats.LastHeartbeat),
+					PendingSince:   unixOrZero(stats.PendingSince),
+					LastLatency:    wh.FromDuration(stats.LastLatency),
+					MaxLatency:     wh.FromDuration(stats.MaxLatency),
+				},
+			},
+		})
+	}
+}
+
 func parseBoolFlag(v string) (bool, error) {
 	if v == "" {
 		return false, nil
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"encoding/json"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/testutil"
+	wh "github.com/skycoin/skycoin/src/util/http"
 	"github.com/skycoin/skycoin/src/visor"
 )
 
@@ -212,6 +214,210 @@ func TestGetBlockchainProgress(t *testing.T) {
 	}
 }
 
+func TestDoubleSignProofs(t *testing.T) {
+	proof := visor.DoubleSignProof{
+		BlockA: coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: 10,
+					Time:  1000,
+				},
+			},
+			Sig: cipher.MustSigFromHex("8cf145e9ef4a4a5254bc57798a7a61dfed238768f94edc5635175c6b91bccd8ec1555da603c5e31b018e135b82b1525be8a92973c468a74b5b40b8da189cb465eb"),
+		},
+		BlockB: coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: 10,
+					Time:  1001,
+				},
+			},
+			Sig: cipher.MustSigFromHex("8015c8776de577d89c29d1cbd1d558ba4855dec94ba58f6c67d55ece5c85708b9906bd0b72b451e27008f3938fcec42c1a28ddac336ae8206d8e6443b95dde966c"),
+		},
+		Source:     "1.1.1.1:6000",
+		DetectedAt: 1540000000,
+	}
+
+	rProof, err := readable.NewDoubleSignProof(proof)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name                      string
+		method                    string
+		status                    int
+		err                       string
+		getDoubleSignProofsResult []visor.DoubleSignProof
+		getDoubleSignProofsErr    error
+		result                    DoubleSignProofsResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:                   "500 - gateway.GetDoubleSignProofs error",
+			method:                 http.MethodGet,
+			status:                 http.StatusInternalServerError,
+			err:                    "500 Internal Server Error - gateway.GetDoubleSignProofs failed: database error",
+			getDoubleSignProofsErr: errors.New("database error"),
+		},
+		{
+			name:   "200 - no proofs",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			result: DoubleSignProofsResponse{
+				Proofs: []readable.DoubleSignProof{},
+			},
+		},
+		{
+			name:                      "200",
+			method:                    http.MethodGet,
+			status:                    http.StatusOK,
+			getDoubleSignProofsResult: []visor.DoubleSignProof{proof},
+			result: DoubleSignProofsResponse{
+				Proofs: []readable.DoubleSignProof{*rProof},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetDoubleSignProofs").Return(tc.getDoubleSignProofsResult, tc.getDoubleSignProofsErr)
+
+			endpoint := "/api/v1/blockchain/double_sign_proofs"
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway, nil)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v` %s", status, tc.status, strings.TrimSpace(rr.Body.String()))
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg DoubleSignProofsResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.result, msg)
+		})
+	}
+}
+
+func TestBlockPublisher(t *testing.T) {
+	now := time.Unix(1542443907, 0).UTC()
+
+	policy := daemon.BlockPublishPolicy{
+		MaxLatency: time.Second * 10,
+		MinSpacing: time.Second,
+		BurstSize:  32768,
+	}
+
+	stats := daemon.BlockPublishStats{
+		Published:      12,
+		BurstPublished: 3,
+		Failures:       1,
+		SpacingDelays:  2,
+		LastPublished:  now,
+		LastLatency:    time.Second * 4,
+		MaxLatency:     time.Second * 10,
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		policy       daemon.BlockPublishPolicy
+		stats        daemon.BlockPublishStats
+		publisher    bool
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "403 - not a block publisher",
+			method:       http.MethodGet,
+			status:       http.StatusForbidden,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, "this node is not a block publisher"),
+		},
+		{
+			name:      "200",
+			method:    http.MethodGet,
+			status:    http.StatusOK,
+			policy:    policy,
+			stats:     stats,
+			publisher: true,
+			httpResponse: HTTPResponse{
+				Data: BlockPublisherResponse{
+					Policy: BlockPublisherPolicy{
+						MaxLatency: wh.FromDuration(time.Second * 10),
+						MinSpacing: wh.FromDuration(time.Second),
+						BurstSize:  32768,
+						Heartbeat:  wh.FromDuration(0),
+					},
+					Stats: BlockPublisherStats{
+						Published:      12,
+						BurstPublished: 3,
+						Failures:       1,
+						SpacingDelays:  2,
+						LastPublished:  now.Unix(),
+						LastLatency:    wh.FromDuration(time.Second * 4),
+						MaxLatency:     wh.FromDuration(time.Second * 10),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetBlockPublishStats").Return(tc.policy, tc.stats, tc.publisher)
+
+			endpoint := "/api/v2/blockchain/publisher"
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway, nil)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.NewDecoder(rr.Body).Decode(&rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var publisherRsp BlockPublisherResponse
+				err := json.Unmarshal(rsp.Data, &publisherRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(BlockPublisherResponse), publisherRsp)
+			}
+		})
+	}
+}
+
 func makeBadBlock(t *testing.T) *coin.Block {
 	genPublic, _ := cipher.GenerateKeyPair()
 	genAddress := cipher.AddressFromPubKey(genPublic)
@@ -242,6 +242,24 @@ func (c *Client) PostJSONV2(endpoint string, reqObj, respObj interface{}) (bool,
 
 	defer resp.Body.Close()
 
+	return decodeV2Response(resp, respObj)
+}
+
+// GetV2 makes a GET request to an endpoint which returns the standard v2 response format (see HTTPResponse).
+// The bool return value indicates whether or not the response data was decoded into respObj.
+func (c *Client) GetV2(endpoint string, respObj interface{}) (bool, error) {
+	resp, err := c.get(endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	defer resp.Body.Close()
+
+	return decodeV2Response(resp, respObj)
+}
+
+// decodeV2Response decodes a response in the standard v2 response format into respObj
+func decodeV2Response(resp *http.Response, respObj interface{}) (bool, error) {
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return false, err
@@ -257,7 +275,7 @@ func (c *Client) PostJSONV2(endpoint string, reqObj, respObj interface{}) (bool,
 		// occurs in the go HTTP stack, outside of the application's control.
 		// If this happens, treat the entire response body as the error message.
 		if resp.StatusCode != http.StatusOK {
-			return false, NewClientError(resp.Status, resp.StatusCode, string(body))
+			return false, NewClientError(resp.Status, resp.StatusCode, string(respBody))
 		}
 
 		return false, err
@@ -540,6 +558,26 @@ func (c *Client) BlockchainProgress() (*readable.BlockchainProgress, error) {
 	return &b, nil
 }
 
+// DoubleSignProofs makes a request to GET /api/v1/blockchain/double_sign_proofs
+func (c *Client) DoubleSignProofs() (*DoubleSignProofsResponse, error) {
+	var r DoubleSignProofsResponse
+	if err := c.Get("/api/v1/blockchain/double_sign_proofs", &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// BlockPublisher makes a request to GET /api/v2/blockchain/publisher
+func (c *Client) BlockPublisher() (*BlockPublisherResponse, error) {
+	var rsp BlockPublisherResponse
+	ok, err := c.GetV2("/api/v2/blockchain/publisher", &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // Balance makes a request to POST /api/v1/balance?addrs=xxx
 func (c *Client) Balance(addrs []string) (*BalanceResponse, error) {
 	v := url.Values{}
@@ -696,11 +734,12 @@ func (c *Client) Spend(id, dst string, coins uint64, password string) (*SpendRes
 
 // CreateTransactionRequest is sent to /api/v1/wallet/transaction
 type CreateTransactionRequest struct {
-	IgnoreUnconfirmed bool                           `json:"ignore_unconfirmed"`
-	HoursSelection    HoursSelection                 `json:"hours_selection"`
-	Wallet            CreateTransactionRequestWallet `json:"wallet"`
-	ChangeAddress     *string                        `json:"change_address,omitempty"`
-	To                []Receiver                     `json:"to"`
+	IgnoreUnconfirmed      bool                           `json:"ignore_unconfirmed"`
+	SpendUnconfirmedChange bool                           `json:"spend_unconfirmed_change"`
+	HoursSelection         HoursSelection                 `json:"hours_selection"`
+	Wallet                 CreateTransactionRequestWallet `json:"wallet"`
+	ChangeAddress          *string                        `json:"change_address,omitempty"`
+	To                     []Receiver                     `json:"to"`
 }
 
 // CreateTransactionRequestWallet defines a wallet to spend from and optionally which addresses in the wallet
@@ -1086,6 +1125,58 @@ func (c *Client) RawTransaction(txid string) (string, error) {
 	return rawTx, nil
 }
 
+// TransactionRelay makes a request to GET /api/v2/transaction/relay
+func (c *Client) TransactionRelay(txid string) (*readable.TxnRelay, error) {
+	v := url.Values{}
+	v.Add("txid", txid)
+	endpoint := "/api/v2/transaction/relay?" + v.Encode()
+
+	var rsp readable.TxnRelay
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// TransactionConflicts makes a request to GET /api/v2/transaction/conflicts
+func (c *Client) TransactionConflicts(txids, addrs []string) (*TxnConflictsResponse, error) {
+	v := url.Values{}
+	if len(txids) > 0 {
+		v.Add("txids", strings.Join(txids, ","))
+	}
+	if len(addrs) > 0 {
+		v.Add("addrs", strings.Join(addrs, ","))
+	}
+	endpoint := "/api/v2/transaction/conflicts?" + v.Encode()
+
+	var rsp TxnConflictsResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// TransactionConflictEvents makes a request to GET /api/v2/transaction/conflicts/events.
+// wait is the number of seconds to wait for an event if there are no events after since
+func (c *Client) TransactionConflictEvents(since uint64, wait int) (*TxnConflictEventsResponse, error) {
+	v := url.Values{}
+	v.Add("since", fmt.Sprint(since))
+	v.Add("wait", fmt.Sprint(wait))
+	endpoint := "/api/v2/transaction/conflicts/events?" + v.Encode()
+
+	var rsp TxnConflictEventsResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // VerifyTransaction makes a request to POST /api/v2/transaction/verify.
 func (c *Client) VerifyTransaction(encodedTxn string) (*VerifyTxnResponse, error) {
 	req := VerifyTxnRequest{
@@ -1229,6 +1320,39 @@ func (c *Client) RecoverWallet(id, seed, password string) (*WalletResponse, erro
 	return nil, err
 }
 
+// CreateHTLC makes a request to POST /api/v2/wallet/htlc/create
+func (c *Client) CreateHTLC(req HTLCCreateRequest) (*HTLCCreateResponse, error) {
+	var rsp HTLCCreateResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/htlc/create", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// ClaimHTLC makes a request to POST /api/v2/wallet/htlc/claim
+func (c *Client) ClaimHTLC(req HTLCSpendRequest) (*CreateTransactionResponse, error) {
+	var rsp CreateTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/htlc/claim", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// RefundHTLC makes a request to POST /api/v2/wallet/htlc/refund
+func (c *Client) RefundHTLC(req HTLCSpendRequest) (*CreateTransactionResponse, error) {
+	var rsp CreateTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/htlc/refund", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // Disconnect disconnect a connections by ID
 func (c *Client) Disconnect(id uint64) error {
 	v := url.Values{}
@@ -1237,3 +1361,62 @@ func (c *Client) Disconnect(id uint64) error {
 	var obj struct{}
 	return c.PostForm("/api/v1/network/connection/disconnect", strings.NewReader(v.Encode()), &obj)
 }
+
+// Connect makes a request to POST /api/v1/network/connection/connect
+func (c *Client) Connect(addr string) error {
+	v := url.Values{}
+	v.Add("addr", addr)
+
+	var obj struct{}
+	return c.PostForm("/api/v1/network/connection/connect", strings.NewReader(v.Encode()), &obj)
+}
+
+// AddPeer makes a request to POST /api/v1/network/peer/add
+func (c *Client) AddPeer(addr string) error {
+	v := url.Values{}
+	v.Add("addr", addr)
+
+	var obj struct{}
+	return c.PostForm("/api/v1/network/peer/add", strings.NewReader(v.Encode()), &obj)
+}
+
+// RemovePeer makes a request to POST /api/v1/network/peer/remove
+func (c *Client) RemovePeer(addr string) error {
+	v := url.Values{}
+	v.Add("addr", addr)
+
+	var obj struct{}
+	return c.PostForm("/api/v1/network/peer/remove", strings.NewReader(v.Encode()), &obj)
+}
+
+// TrustPeer makes a request to POST /api/v1/network/peer/trust
+func (c *Client) TrustPeer(addr string, trusted bool) error {
+	v := url.Values{}
+	v.Add("addr", addr)
+	v.Add("trusted", fmt.Sprint(trusted))
+
+	var obj struct{}
+	return c.PostForm("/api/v1/network/peer/trust", strings.NewReader(v.Encode()), &obj)
+}
+
+// SetPeerPrivate makes a request to POST /api/v1/network/peer/private
+func (c *Client) SetPeerPrivate(addr string, private bool) error {
+	v := url.Values{}
+	v.Add("addr", addr)
+	v.Add("private", fmt.Sprint(private))
+
+	var obj struct{}
+	return c.PostForm("/api/v1/network/peer/private", strings.NewReader(v.Encode()), &obj)
+}
+
+// ResetPeerRetries makes a request to POST /api/v1/network/peer/reset-retries.
+// If addr is empty, the retry counters of all peers are cleared
+func (c *Client) ResetPeerRetries(addr string) error {
+	v := url.Values{}
+	if addr != "" {
+		v.Add("addr", addr)
+	}
+
+	var obj struct{}
+	return c.PostForm("/api/v1/network/peer/reset-retries", strings.NewReader(v.Encode()), &obj)
+}
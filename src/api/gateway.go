@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
@@ -16,12 +18,14 @@ import (
 type Gatewayer interface {
 	Spend(wltID string, password []byte, coins uint64, dest cipher.Address) (*coin.Transaction, error)
 	CreateTransaction(w wallet.CreateTransactionParams) (*coin.Transaction, []wallet.UxBalance, error)
+	CreateHTLCSpend(p wallet.HTLCSpendParams) (*coin.Transaction, []wallet.UxBalance, error)
 	GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalances, error)
 	GetWallet(wltID string) (*wallet.Wallet, error)
 	GetWallets() (wallet.Wallets, error)
 	UpdateWalletLabel(wltID, label string) error
 	GetWalletUnconfirmedTransactions(wltID string) ([]visor.UnconfirmedTransaction, error)
 	GetWalletUnconfirmedTransactionsVerbose(wltID string) ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error)
+	GetWalletTxnBroadcasts(wltID string) ([]daemon.TxnBroadcast, error)
 	CreateWallet(wltName string, options wallet.Options) (*wallet.Wallet, error)
 	RecoverWallet(wltID, seed string, password []byte) (*wallet.Wallet, error)
 	NewAddresses(wltID string, password []byte, n uint64) ([]cipher.Address, error)
@@ -39,6 +43,7 @@ type Gatewayer interface {
 	GetBlocksInRangeVerbose(start, end uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error)
 	GetLastBlocks(num uint64) ([]coin.SignedBlock, error)
 	GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error)
+	GetDoubleSignProofs() ([]visor.DoubleSignProof, error)
 	GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error)
 	GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error)
 	GetBlockchainMetadata() (*visor.BlockchainMetadata, error)
@@ -46,6 +51,12 @@ type Gatewayer interface {
 	GetConnection(addr string) (*daemon.Connection, error)
 	GetConnections(f func(c daemon.Connection) bool) ([]daemon.Connection, error)
 	Disconnect(id uint64) error
+	ConnectPeer(addr string) error
+	AddPeer(addr string) error
+	RemovePeer(addr string) error
+	SetPeerTrusted(addr string, trusted bool) error
+	SetPeerPrivate(addr string, private bool) error
+	ResetPeerRetryTimes(addr string) error
 	GetDefaultConnections() []string
 	GetTrustConnections() []string
 	GetExchgConnection() []string
@@ -57,6 +68,10 @@ type Gatewayer interface {
 	GetTransactionsVerbose(flts []visor.TxFilter) ([]visor.Transaction, [][]visor.TransactionInput, error)
 	InjectBroadcastTransaction(txn coin.Transaction) error
 	ResendUnconfirmedTxns() ([]cipher.SHA256, error)
+	GetTxnRelay(txid cipher.SHA256) (daemon.TxnRelay, bool)
+	GetBlockPublishStats() (daemon.BlockPublishPolicy, daemon.BlockPublishStats, bool)
+	GetTxnConflicts(txids []cipher.SHA256, addrs []cipher.Address) []daemon.TxnConflict
+	GetTxnConflictEvents(since uint64, wait time.Duration) []daemon.TxnConflictEvent
 	GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error)
 	GetSpentOutputsForAddresses(addr []cipher.Address) ([][]historydb.UxOut, error)
 	GetVerboseTransactionsForAddress(a cipher.Address) ([]visor.Transaction, [][]visor.TransactionInput, error)
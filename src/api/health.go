@@ -18,23 +18,28 @@ type BlockchainMetadata struct {
 
 // HealthResponse is returned by the /health endpoint
 type HealthResponse struct {
-	BlockchainMetadata    BlockchainMetadata `json:"blockchain"`
-	Version               readable.BuildInfo `json:"version"`
-	CoinName              string             `json:"coin"`
-	DaemonUserAgent       string             `json:"user_agent"`
-	OpenConnections       int                `json:"open_connections"`
-	OutgoingConnections   int                `json:"outgoing_connections"`
-	IncomingConnections   int                `json:"incoming_connections"`
-	Uptime                wh.Duration        `json:"uptime"`
-	CSRFEnabled           bool               `json:"csrf_enabled"`
-	CSPEnabled            bool               `json:"csp_enabled"`
-	WalletAPIEnabled      bool               `json:"wallet_api_enabled"`
-	GUIEnabled            bool               `json:"gui_enabled"`
-	UnversionedAPIEnabled bool               `json:"unversioned_api_enabled"`
-	JSON20RPCEnabled      bool               `json:"json_rpc_enabled"`
-	UserVerifyTxn         readable.VerifyTxn `json:"user_verify_transaction"`
-	UnconfirmedVerifyTxn  readable.VerifyTxn `json:"unconfirmed_verify_transaction"`
-	StartedAt             int64              `json:"started_at"`
+	BlockchainMetadata    BlockchainMetadata        `json:"blockchain"`
+	Version               readable.BuildInfo        `json:"version"`
+	CoinName              string                    `json:"coin"`
+	DaemonUserAgent       string                    `json:"user_agent"`
+	OpenConnections       int                       `json:"open_connections"`
+	OutgoingConnections   int                       `json:"outgoing_connections"`
+	IncomingConnections   int                       `json:"incoming_connections"`
+	Uptime                wh.Duration               `json:"uptime"`
+	CSRFEnabled           bool                      `json:"csrf_enabled"`
+	CSPEnabled            bool                      `json:"csp_enabled"`
+	WalletAPIEnabled      bool                      `json:"wallet_api_enabled"`
+	GUIEnabled            bool                      `json:"gui_enabled"`
+	UnversionedAPIEnabled bool                      `json:"unversioned_api_enabled"`
+	JSON20RPCEnabled      bool                      `json:"json_rpc_enabled"`
+	UserVerifyTxn         readable.VerifyTxn        `json:"user_verify_transaction"`
+	UnconfirmedVerifyTxn  readable.VerifyTxn        `json:"unconfirmed_verify_transaction"`
+	StartedAt             int64                     `json:"started_at"`
+	Compression           readable.CompressionStats `json:"compression"`
+	UserAgents            map[string]int            `json:"user_agents"`
+	DoubleSignSeqs        []uint64                  `json:"double_sign_seqs"`
+	BlockAcceptanceHalted bool                      `json:"block_acceptance_halted"`
+	Alerts                []string                  `json:"alerts"`
 }
 
 // healthHandler returns node health data
@@ -59,6 +64,19 @@ func healthHandler(c muxConfig, gateway Gatewayer) http.HandlerFunc {
 
 		_, walletAPIEnabled := c.enabledAPISets[EndpointsWallet]
 
+		alerts := []string{}
+		for _, seq := range health.DoubleSignSeqs {
+			alerts = append(alerts, fmt.Sprintf("The block publisher signed conflicting blocks at seq %d", seq))
+		}
+		if health.BlockAcceptanceHalted {
+			alerts = append(alerts, fmt.Sprintf("Block acceptance is halted at seq %d", health.BlockAcceptanceHaltedSeq))
+		}
+
+		doubleSignSeqs := health.DoubleSignSeqs
+		if doubleSignSeqs == nil {
+			doubleSignSeqs = []uint64{}
+		}
+
 		userAgent, err := c.health.DaemonUserAgent.Build()
 		if err != nil {
 			wh.Error500(w, err.Error())
@@ -86,6 +104,11 @@ func healthHandler(c muxConfig, gateway Gatewayer) http.HandlerFunc {
 			UserVerifyTxn:         readable.NewVerifyTxn(params.UserVerifyTxn),
 			UnconfirmedVerifyTxn:  readable.NewVerifyTxn(health.UnconfirmedVerifyTxn),
 			StartedAt:             health.StartedAt.Unix(),
+			Compression:           readable.NewCompressionStats(health.Compression),
+			UserAgents:            health.UserAgents,
+			DoubleSignSeqs:        doubleSignSeqs,
+			BlockAcceptanceHalted: health.BlockAcceptanceHalted,
+			Alerts:                alerts,
 		})
 	}
 }
@@ -30,6 +30,7 @@ func TestHealthHandler(t *testing.T) {
 		getHealthErr     error
 		cfg              muxConfig
 		walletAPIEnabled bool
+		doubleSigned     bool
 	}{
 		{
 			name:   "405 method not allowed",
@@ -75,6 +76,15 @@ func TestHealthHandler(t *testing.T) {
 			},
 			walletAPIEnabled: false,
 		},
+
+		{
+			name:             "valid response, block publisher double signed",
+			method:           http.MethodGet,
+			code:             http.StatusOK,
+			cfg:              defaultMuxConfig(),
+			walletAPIEnabled: true,
+			doubleSigned:     true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -134,6 +144,16 @@ func TestHealthHandler(t *testing.T) {
 					MaxDropletPrecision: params.UserVerifyTxn.MaxDropletPrecision - 1,
 				},
 				StartedAt: time.Now().Add(time.Second * -4),
+				UserAgents: map[string]int{
+					"skycoin:0.25.0":        4,
+					daemon.UnknownUserAgent: 1,
+				},
+			}
+
+			if tc.doubleSigned {
+				health.DoubleSignSeqs = []uint64{21170, 21174}
+				health.BlockAcceptanceHalted = true
+				health.BlockAcceptanceHaltedSeq = 21170
 			}
 
 			gateway := &MockGatewayer{}
@@ -173,6 +193,7 @@ func TestHealthHandler(t *testing.T) {
 			require.Equal(t, health.OutgoingConnections+health.IncomingConnections, r.OpenConnections)
 			require.Equal(t, "skycoin", r.CoinName)
 			require.Equal(t, "skycoin:0.25.0(test)", r.DaemonUserAgent)
+			require.Equal(t, health.UserAgents, r.UserAgents)
 
 			require.Equal(t, unconfirmed, r.BlockchainMetadata.Unconfirmed)
 			require.Equal(t, unspents, r.BlockchainMetadata.Unspents)
@@ -202,6 +223,20 @@ func TestHealthHandler(t *testing.T) {
 			require.Equal(t, health.UnconfirmedVerifyTxn.MaxDropletPrecision, r.UnconfirmedVerifyTxn.MaxDropletPrecision)
 			require.True(t, time.Now().Unix() > r.StartedAt)
 
+			if tc.doubleSigned {
+				require.Equal(t, health.DoubleSignSeqs, r.DoubleSignSeqs)
+				require.True(t, r.BlockAcceptanceHalted)
+				require.Equal(t, []string{
+					"The block publisher signed conflicting blocks at seq 21170",
+					"The block publisher signed conflicting blocks at seq 21174",
+					"Block acceptance is halted at seq 21170",
+				}, r.Alerts)
+			} else {
+				require.Equal(t, []uint64{}, r.DoubleSignSeqs)
+				require.False(t, r.BlockAcceptanceHalted)
+				require.Equal(t, []string{}, r.Alerts)
+			}
+
 		})
 	}
 }
@@ -0,0 +1,407 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// HTLCContract is a hash-time-locked contract
+type HTLCContract struct {
+	Hashlock   string `json:"hashlock"`
+	Recipient  string `json:"recipient"`
+	Sender     string `json:"sender"`
+	LockHeight uint64 `json:"lock_height"`
+}
+
+// NewHTLCContract creates an HTLCContract from coin.HTLC
+func NewHTLCContract(h coin.HTLC) HTLCContract {
+	return HTLCContract{
+		Hashlock:   h.Hashlock.Hex(),
+		Recipient:  h.Recipient.String(),
+		Sender:     h.Sender.String(),
+		LockHeight: h.LockHeight,
+	}
+}
+
+// ToHTLC parses and verifies the contract
+func (c HTLCContract) ToHTLC() (coin.HTLC, error) {
+	hashlock, err := cipher.SHA256FromHex(c.Hashlock)
+	if err != nil {
+		return coin.HTLC{}, fmt.Errorf("invalid contract.hashlock: %v", err)
+	}
+
+	recipient, err := cipher.DecodeBase58Address(c.Recipient)
+	if err != nil {
+		return coin.HTLC{}, fmt.Errorf("invalid contract.recipient: %v", err)
+	}
+
+	sender, err := cipher.DecodeBase58Address(c.Sender)
+	if err != nil {
+		return coin.HTLC{}, fmt.Errorf("invalid contract.sender: %v", err)
+	}
+
+	h := coin.HTLC{
+		Hashlock:   hashlock,
+		Recipient:  recipient,
+		Sender:     sender,
+		LockHeight: c.LockHeight,
+	}
+
+	if err := h.Verify(); err != nil {
+		return coin.HTLC{}, err
+	}
+
+	return h, nil
+}
+
+// HTLCCreateRequest is the request data for POST /api/v2/wallet/htlc/create
+type HTLCCreateRequest struct {
+	Wallet        CreateTransactionRequestWallet `json:"wallet"`
+	Contract      HTLCContract                   `json:"contract"`
+	Coins         string                         `json:"coins"`
+	ChangeAddress *string                        `json:"change_address,omitempty"`
+}
+
+// toWalletParams parses the request into the contract and the parameters of the transaction that locks the coins
+func (r HTLCCreateRequest) toWalletParams() (coin.HTLC, wallet.CreateTransactionParams, error) {
+	h, err := r.Contract.ToHTLC()
+	if err != nil {
+		return coin.HTLC{}, wallet.CreateTransactionParams{}, err
+	}
+
+	if r.Wallet.ID == "" {
+		return coin.HTLC{}, wallet.CreateTransactionParams{}, errors.New("missing wallet.id")
+	}
+
+	coins, err := droplet.FromString(r.Coins)
+	if err != nil {
+		return coin.HTLC{}, wallet.CreateTransactionParams{}, fmt.Errorf("invalid coins: %v", err)
+	}
+
+	if coins == 0 {
+		return coin.HTLC{}, wallet.CreateTransactionParams{}, errors.New("coins must not be zero")
+	}
+
+	if coins%params.UserVerifyTxn.MaxDropletDivisor() != 0 {
+		return coin.HTLC{}, wallet.CreateTransactionParams{}, errors.New("coins has too many decimal places")
+	}
+
+	addresses := make([]cipher.Address, len(r.Wallet.Addresses))
+	for i, a := range r.Wallet.Addresses {
+		addresses[i], err = cipher.DecodeBase58Address(a)
+		if err != nil {
+			return coin.HTLC{}, wallet.CreateTransactionParams{}, fmt.Errorf("invalid wallet.addresses[%d]: %v", i, err)
+		}
+	}
+
+	uxouts := make([]cipher.SHA256, len(r.Wallet.UxOuts))
+	for i, o := range r.Wallet.UxOuts {
+		uxouts[i], err = cipher.SHA256FromHex(o)
+		if err != nil {
+			return coin.HTLC{}, wallet.CreateTransactionParams{}, fmt.Errorf("invalid wallet.unspents[%d]: %v", i, err)
+		}
+	}
+
+	if len(uxouts) != 0 && len(addresses) != 0 {
+		return coin.HTLC{}, wallet.CreateTransactionParams{}, errors.New("wallet.unspents and wallet.addresses cannot be combined")
+	}
+
+	var changeAddress *cipher.Address
+	if r.ChangeAddress != nil {
+		addr, err := cipher.DecodeBase58Address(*r.ChangeAddress)
+		if err != nil {
+			return coin.HTLC{}, wallet.CreateTransactionParams{}, fmt.Errorf("invalid change_address: %v", err)
+		}
+		changeAddress = &addr
+	}
+
+	shareFactor := decimal.New(5, -1)
+	return h, wallet.CreateTransactionParams{
+		HoursSelection: wallet.HoursSelection{
+			Type:        wallet.HoursSelectionTypeAuto,
+			Mode:        wallet.HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		},
+		Wallet: wallet.CreateTransactionWalletParams{
+			ID:        r.Wallet.ID,
+			Addresses: addresses,
+			UxOuts:    uxouts,
+			Password:  []byte(r.Wallet.Password),
+		},
+		ChangeAddress: changeAddress,
+		To: []coin.TransactionOutput{
+			{
+				Address: h.Address(),
+				Coins:   coins,
+			},
+		},
+	}, nil
+}
+
+// HTLCCreateResponse is returned by POST /api/v2/wallet/htlc/create
+type HTLCCreateResponse struct {
+	// Address that the coins are locked in
+	Address            string             `json:"address"`
+	Contract           HTLCContract       `json:"contract"`
+	Transaction        CreatedTransaction `json:"transaction"`
+	EncodedTransaction string             `json:"encoded_transaction"`
+}
+
+// HTLCRequestWallet defines the wallet that signs an HTLC claim or refund
+type HTLCRequestWallet struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+}
+
+// HTLCSpendRequest is the request data for POST /api/v2/wallet/htlc/claim and POST /api/v2/wallet/htlc/refund
+type HTLCSpendRequest struct {
+	Wallet   HTLCRequestWallet `json:"wallet"`
+	Contract HTLCContract      `json:"contract"`
+	// Preimage of the hashlock, required to claim the coins
+	Preimage string `json:"preimage,omitempty"`
+	// Address that receives the coins. Defaults to the recipient for a claim and to the sender for a refund
+	To string `json:"to,omitempty"`
+}
+
+// toWalletParams parses the request into wallet.HTLCSpendParams
+func (r HTLCSpendRequest) toWalletParams(claim bool) (wallet.HTLCSpendParams, error) {
+	h, err := r.Contract.ToHTLC()
+	if err != nil {
+		return wallet.HTLCSpendParams{}, err
+	}
+
+	if r.Wallet.ID == "" {
+		return wallet.HTLCSpendParams{}, errors.New("missing wallet.id")
+	}
+
+	p := wallet.HTLCSpendParams{
+		WalletID: r.Wallet.ID,
+		Password: []byte(r.Wallet.Password),
+		Contract: h,
+	}
+
+	switch {
+	case claim && r.Preimage == "":
+		return wallet.HTLCSpendParams{}, errors.New("missing preimage")
+	case claim:
+		preimage, err := cipher.SHA256FromHex(r.Preimage)
+		if err != nil {
+			return wallet.HTLCSpendParams{}, fmt.Errorf("invalid preimage: %v", err)
+		}
+		p.Preimage = &preimage
+	case r.Preimage != "":
+		return wallet.HTLCSpendParams{}, errors.New("preimage cannot be used for a refund")
+	}
+
+	if r.To != "" {
+		to, err := cipher.DecodeBase58Address(r.To)
+		if err != nil {
+			return wallet.HTLCSpendParams{}, fmt.Errorf("invalid to: %v", err)
+		}
+		p.To = &to
+	}
+
+	return p, nil
+}
+
+// htlcCreateHandler creates a transaction that locks coins of a wallet in an HTLC.
+// The contract sender must be an address of the wallet, so that the wallet can refund the coins.
+// HTLCs can only be created once the next block is at the HTLC activation height,
+// with a lock height of at least the activation height.
+// The transaction is not broadcast.
+// Method: POST
+// URI: /api/v2/wallet/htlc/create
+// Args: JSON body, see HTLCCreateRequest
+func htlcCreateHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req HTLCCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		h, p, err := req.toWalletParams()
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		// HTLC outputs can't be claimed or refunded before the HTLC activation height,
+		// so coins locked before then, or until before then, would be stuck until the activation
+		if h.LockHeight < params.HTLCActivationHeight {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("contract.lock_height must be at least the HTLC activation height %d", params.HTLCActivationHeight))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		blocks, err := gateway.GetLastBlocks(1)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(blocks) == 0 || blocks[0].Seq()+1 < params.HTLCActivationHeight {
+			resp := NewHTTPErrorResponse(http.StatusForbidden, fmt.Sprintf("HTLCs can't be created before the HTLC activation height %d", params.HTLCActivationHeight))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wlt, err := gateway.GetWallet(p.Wallet.ID)
+		if err != nil {
+			writeHTTPResponse(w, htlcErrorResponse(err))
+			return
+		}
+
+		if _, ok := wlt.GetEntry(h.Sender); !ok {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "contract.sender is not an address of the wallet")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txn, inputs, err := gateway.CreateTransaction(p)
+		if err != nil {
+			writeHTTPResponse(w, htlcErrorResponse(err))
+			return
+		}
+
+		cTxn, err := NewCreatedTransaction(txn, inputs)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: HTLCCreateResponse{
+				Address:            h.Address().String(),
+				Contract:           NewHTLCContract(h),
+				Transaction:        *cTxn,
+				EncodedTransaction: hex.EncodeToString(txn.Serialize()),
+			},
+		})
+	}
+}
+
+// htlcClaimHandler creates a transaction that claims the coins locked in an HTLC with the preimage of the hashlock.
+// The contract recipient must be an address of the wallet. The transaction is not broadcast.
+// Method: POST
+// URI: /api/v2/wallet/htlc/claim
+// Args: JSON body, see HTLCSpendRequest
+func htlcClaimHandler(gateway Gatewayer) http.HandlerFunc {
+	return htlcSpendHandler(gateway, true)
+}
+
+// htlcRefundHandler creates a transaction that refunds the coins locked in an HTLC once the
+// head block seq reaches the lock height.
+// The contract sender must be an address of the wallet. The transaction is not broadcast.
+// Method: POST
+// URI: /api/v2/wallet/htlc/refund
+// Args: JSON body, see HTLCSpendRequest
+func htlcRefundHandler(gateway Gatewayer) http.HandlerFunc {
+	return htlcSpendHandler(gateway, false)
+}
+
+func htlcSpendHandler(gateway Gatewayer, claim bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Header.Get("Content-Type") != ContentTypeJSON {
+			resp := NewHTTPErrorResponse(http.StatusUnsupportedMediaType, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req HTLCSpendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		p, err := req.toWalletParams(claim)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txn, inputs, err := gateway.CreateHTLCSpend(p)
+		if err != nil {
+			writeHTTPResponse(w, htlcErrorResponse(err))
+			return
+		}
+
+		txnResp, err := NewCreateTransactionResponse(txn, inputs)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: txnResp,
+		})
+	}
+}
+
+// htlcErrorResponse maps errors of the HTLC handlers to error responses
+func htlcErrorResponse(err error) HTTPResponse {
+	switch err.(type) {
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletAPIDisabled:
+			return NewHTTPErrorResponse(http.StatusForbidden, "")
+		case wallet.ErrWalletNotExist:
+			return NewHTTPErrorResponse(http.StatusNotFound, "")
+		default:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		}
+	case blockdb.ErrUnspentNotExist,
+		visor.ErrTxnViolatesHardConstraint,
+		visor.ErrTxnViolatesSoftConstraint,
+		visor.ErrTxnViolatesUserConstraint:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		switch err {
+		case fee.ErrTxnNoFee,
+			fee.ErrTxnInsufficientCoinHours,
+			visor.ErrUnconfirmedChainsDisabled:
+			return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		default:
+			return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		}
+	}
+}
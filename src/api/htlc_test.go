@@ -0,0 +1,533 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func makeHTLCTestTransaction(t *testing.T, to cipher.Address) (*coin.Transaction, []wallet.UxBalance) {
+	_, sk := cipher.GenerateKeyPair()
+	input := wallet.UxBalance{
+		Hash:           testutil.RandSHA256(t),
+		BkSeq:          10,
+		Time:           100,
+		Address:        cipher.MustAddressFromSecKey(sk),
+		Coins:          10e6,
+		InitialHours:   100,
+		Hours:          200,
+		SrcTransaction: testutil.RandSHA256(t),
+	}
+
+	txn := &coin.Transaction{}
+	txn.PushInput(input.Hash)
+	txn.PushOutput(to, 10e6, 50)
+	txn.SignInputs([]cipher.SecKey{sk})
+	err := txn.UpdateHeader()
+	require.NoError(t, err)
+
+	return txn, []wallet.UxBalance{input}
+}
+
+func TestHTLCCreate(t *testing.T) {
+	wlt, err := wallet.NewWallet("foo", wallet.Options{
+		Coin:      wallet.CoinTypeSkycoin,
+		Seed:      "fooseed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+
+	preimage := testutil.RandSHA256(t)
+	h := coin.HTLC{
+		Hashlock:   cipher.SumSHA256(preimage[:]),
+		Recipient:  testutil.MakeAddress(),
+		Sender:     wlt.Entries[0].SkycoinAddress(),
+		LockHeight: params.HTLCActivationHeight + 1000,
+	}
+	contract := NewHTLCContract(h)
+
+	earlyLockHeight := contract
+	earlyLockHeight.LockHeight = params.HTLCActivationHeight - 1
+
+	otherSender := contract
+	otherSender.Sender = testutil.MakeAddress().String()
+
+	badHashlock := contract
+	badHashlock.Hashlock = "foo"
+
+	shareFactor := decimal.New(5, -1)
+	walletParams := wallet.CreateTransactionParams{
+		HoursSelection: wallet.HoursSelection{
+			Type:        wallet.HoursSelectionTypeAuto,
+			Mode:        wallet.HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		},
+		Wallet: wallet.CreateTransactionWalletParams{
+			ID:        "foo",
+			Addresses: []cipher.Address{},
+			UxOuts:    []cipher.SHA256{},
+			Password:  []byte{},
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: h.Address(),
+				Coins:   2e6,
+			},
+		},
+	}
+
+	txn, inputs := makeHTLCTestTransaction(t, h.Address())
+	createdTxn, err := NewCreatedTransaction(txn, inputs)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name                 string
+		method               string
+		contentType          string
+		status               int
+		req                  *HTLCCreateRequest
+		headSeq              uint64
+		getLastBlocksErr     error
+		getWalletErr         error
+		createTransactionErr error
+		httpResponse         HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "415",
+			method:       http.MethodPost,
+			contentType:  ContentTypeForm,
+			status:       http.StatusUnsupportedMediaType,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnsupportedMediaType, ""),
+		},
+		{
+			name:   "400 - invalid hashlock",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: badHashlock,
+				Coins:    "2",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid contract.hashlock: encoding/hex: invalid byte: U+006F 'o'"),
+		},
+		{
+			name:   "400 - zero lock height",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &HTLCCreateRequest{
+				Wallet: CreateTransactionRequestWallet{ID: "foo"},
+				Contract: HTLCContract{
+					Hashlock:  contract.Hashlock,
+					Recipient: contract.Recipient,
+					Sender:    contract.Sender,
+				},
+				Coins: "2",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "HTLC lock height is zero"),
+		},
+		{
+			name:   "400 - too many decimal places",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: contract,
+				Coins:    "2.0001",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "coins has too many decimal places"),
+		},
+		{
+			name:   "400 - lock height before the activation height",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: earlyLockHeight,
+				Coins:    "2",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("contract.lock_height must be at least the HTLC activation height %d", params.HTLCActivationHeight)),
+		},
+		{
+			name:   "500 - GetLastBlocks error",
+			method: http.MethodPost,
+			status: http.StatusInternalServerError,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: contract,
+				Coins:    "2",
+			},
+			getLastBlocksErr: errors.New("GetLastBlocks error"),
+			httpResponse:     NewHTTPErrorResponse(http.StatusInternalServerError, "GetLastBlocks error"),
+		},
+		{
+			name:   "403 - before the activation height",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: contract,
+				Coins:    "2",
+			},
+			headSeq:      params.HTLCActivationHeight - 2,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, fmt.Sprintf("HTLCs can't be created before the HTLC activation height %d", params.HTLCActivationHeight)),
+		},
+		{
+			name:   "400 - sender not in wallet",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: otherSender,
+				Coins:    "2",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "contract.sender is not an address of the wallet"),
+		},
+		{
+			name:   "404 - wallet does not exist",
+			method: http.MethodPost,
+			status: http.StatusNotFound,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: contract,
+				Coins:    "2",
+			},
+			getWalletErr: wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "400 - insufficient balance",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: contract,
+				Coins:    "2",
+			},
+			createTransactionErr: wallet.ErrInsufficientBalance,
+			httpResponse:         NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrInsufficientBalance.Error()),
+		},
+		{
+			name:   "200",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			req: &HTLCCreateRequest{
+				Wallet:   CreateTransactionRequestWallet{ID: "foo"},
+				Contract: contract,
+				Coins:    "2",
+			},
+			httpResponse: HTTPResponse{
+				Data: HTLCCreateResponse{
+					Address:            h.Address().String(),
+					Contract:           contract,
+					Transaction:        *createdTxn,
+					EncodedTransaction: hex.EncodeToString(txn.Serialize()),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+
+			headSeq := tc.headSeq
+			if headSeq == 0 {
+				headSeq = params.HTLCActivationHeight - 1
+			}
+			if tc.getLastBlocksErr != nil {
+				gateway.On("GetLastBlocks", uint64(1)).Return(nil, tc.getLastBlocksErr)
+			} else {
+				gateway.On("GetLastBlocks", uint64(1)).Return([]coin.SignedBlock{
+					{
+						Block: coin.Block{
+							Head: coin.BlockHeader{
+								BkSeq: headSeq,
+							},
+						},
+					},
+				}, nil)
+			}
+
+			if tc.getWalletErr != nil {
+				gateway.On("GetWallet", "foo").Return(nil, tc.getWalletErr)
+			} else {
+				gateway.On("GetWallet", "foo").Return(wlt, nil)
+			}
+
+			if tc.createTransactionErr != nil {
+				gateway.On("CreateTransaction", walletParams).Return(nil, nil, tc.createTransactionErr)
+			} else {
+				gateway.On("CreateTransaction", walletParams).Return(txn, inputs, nil)
+			}
+
+			var body string
+			if tc.req != nil {
+				body = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/htlc/create", strings.NewReader(body))
+			require.NoError(t, err)
+
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = ContentTypeJSON
+			}
+			req.Header.Set("Content-Type", contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway, nil)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.NewDecoder(rr.Body).Decode(&rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var createRsp HTLCCreateResponse
+				err := json.Unmarshal(rsp.Data, &createRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(HTLCCreateResponse), createRsp)
+			}
+		})
+	}
+}
+
+func TestHTLCSpend(t *testing.T) {
+	preimage := testutil.RandSHA256(t)
+	h := coin.HTLC{
+		Hashlock:   cipher.SumSHA256(preimage[:]),
+		Recipient:  testutil.MakeAddress(),
+		Sender:     testutil.MakeAddress(),
+		LockHeight: 1000,
+	}
+	contract := NewHTLCContract(h)
+	to := testutil.MakeAddress()
+
+	txn, inputs := makeHTLCTestTransaction(t, h.Recipient)
+	txnRsp, err := NewCreateTransactionResponse(txn, inputs)
+	require.NoError(t, err)
+
+	refundErr := visor.NewErrTxnViolatesHardConstraint(errors.New("HTLC refund before the lock height"))
+
+	cases := []struct {
+		name          string
+		endpoint      string
+		method        string
+		status        int
+		req           *HTLCSpendRequest
+		gatewayParams *wallet.HTLCSpendParams
+		gatewayErr    error
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "405",
+			endpoint:     "/api/v2/wallet/htlc/claim",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:     "400 - missing wallet id",
+			endpoint: "/api/v2/wallet/htlc/claim",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			req: &HTLCSpendRequest{
+				Contract: contract,
+				Preimage: preimage.Hex(),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "missing wallet.id"),
+		},
+		{
+			name:     "400 - claim without preimage",
+			endpoint: "/api/v2/wallet/htlc/claim",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			req: &HTLCSpendRequest{
+				Wallet:   HTLCRequestWallet{ID: "foo"},
+				Contract: contract,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "missing preimage"),
+		},
+		{
+			name:     "400 - refund with preimage",
+			endpoint: "/api/v2/wallet/htlc/refund",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			req: &HTLCSpendRequest{
+				Wallet:   HTLCRequestWallet{ID: "foo"},
+				Contract: contract,
+				Preimage: preimage.Hex(),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "preimage cannot be used for a refund"),
+		},
+		{
+			name:     "400 - wrong preimage",
+			endpoint: "/api/v2/wallet/htlc/claim",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			req: &HTLCSpendRequest{
+				Wallet:   HTLCRequestWallet{ID: "foo"},
+				Contract: contract,
+				Preimage: h.Hashlock.Hex(),
+			},
+			gatewayParams: &wallet.HTLCSpendParams{
+				WalletID: "foo",
+				Password: []byte{},
+				Contract: h,
+				Preimage: &h.Hashlock,
+			},
+			gatewayErr:   wallet.ErrHTLCInvalidPreimage,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrHTLCInvalidPreimage.Error()),
+		},
+		{
+			name:     "200 - claim",
+			endpoint: "/api/v2/wallet/htlc/claim",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			req: &HTLCSpendRequest{
+				Wallet:   HTLCRequestWallet{ID: "foo", Password: "pwd"},
+				Contract: contract,
+				Preimage: preimage.Hex(),
+			},
+			gatewayParams: &wallet.HTLCSpendParams{
+				WalletID: "foo",
+				Password: []byte("pwd"),
+				Contract: h,
+				Preimage: &preimage,
+			},
+			httpResponse: HTTPResponse{
+				Data: *txnRsp,
+			},
+		},
+		{
+			name:     "400 - refund before the lock height",
+			endpoint: "/api/v2/wallet/htlc/refund",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			req: &HTLCSpendRequest{
+				Wallet:   HTLCRequestWallet{ID: "foo"},
+				Contract: contract,
+				To:       to.String(),
+			},
+			gatewayParams: &wallet.HTLCSpendParams{
+				WalletID: "foo",
+				Password: []byte{},
+				Contract: h,
+				To:       &to,
+			},
+			gatewayErr:   refundErr,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, refundErr.Error()),
+		},
+		{
+			name:     "403 - wallet api disabled",
+			endpoint: "/api/v2/wallet/htlc/refund",
+			method:   http.MethodPost,
+			status:   http.StatusForbidden,
+			req: &HTLCSpendRequest{
+				Wallet:   HTLCRequestWallet{ID: "foo"},
+				Contract: contract,
+			},
+			gatewayParams: &wallet.HTLCSpendParams{
+				WalletID: "foo",
+				Password: []byte{},
+				Contract: h,
+			},
+			gatewayErr:   wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:     "200 - refund",
+			endpoint: "/api/v2/wallet/htlc/refund",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			req: &HTLCSpendRequest{
+				Wallet:   HTLCRequestWallet{ID: "foo"},
+				Contract: contract,
+			},
+			gatewayParams: &wallet.HTLCSpendParams{
+				WalletID: "foo",
+				Password: []byte{},
+				Contract: h,
+			},
+			httpResponse: HTTPResponse{
+				Data: *txnRsp,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayParams != nil {
+				if tc.gatewayErr != nil {
+					gateway.On("CreateHTLCSpend", *tc.gatewayParams).Return(nil, nil, tc.gatewayErr)
+				} else {
+					gateway.On("CreateHTLCSpend", *tc.gatewayParams).Return(txn, inputs, nil)
+				}
+			}
+
+			var body string
+			if tc.req != nil {
+				body = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, tc.endpoint, strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway, nil)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.NewDecoder(rr.Body).Decode(&rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var spendRsp CreateTransactionResponse
+				err := json.Unmarshal(rsp.Data, &spendRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(CreateTransactionResponse), spendRsp)
+			}
+		})
+	}
+}
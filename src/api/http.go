@@ -460,10 +460,14 @@ func newServerMux(c muxConfig, gateway Gatewayer, rpc *webrpc.WebRPC) *http.Serv
 	webHandlerV1("/wallet/encrypt", forAPISet(walletEncryptHandler(gateway), []string{EndpointsWallet}))
 	webHandlerV1("/wallet/decrypt", forAPISet(walletDecryptHandler(gateway), []string{EndpointsWallet}))
 	webHandlerV2("/wallet/recover", forAPISet(walletRecoverHandler(gateway), []string{EndpointsWallet}))
+	webHandlerV2("/wallet/htlc/create", forAPISet(htlcCreateHandler(gateway), []string{EndpointsWallet}))
+	webHandlerV2("/wallet/htlc/claim", forAPISet(htlcClaimHandler(gateway), []string{EndpointsWallet}))
+	webHandlerV2("/wallet/htlc/refund", forAPISet(htlcRefundHandler(gateway), []string{EndpointsWallet}))
 
 	// Blockchain interface
 	webHandlerV1("/blockchain/metadata", forAPISet(blockchainMetadataHandler(gateway), []string{EndpointsRead, EndpointsStatus}))
 	webHandlerV1("/blockchain/progress", forAPISet(blockchainProgressHandler(gateway), []string{EndpointsRead, EndpointsStatus}))
+	webHandlerV1("/blockchain/double_sign_proofs", forAPISet(doubleSignProofsHandler(gateway), []string{EndpointsRead, EndpointsStatus}))
 	webHandlerV1("/block", forAPISet(blockHandler(gateway), []string{EndpointsRead}))
 	webHandlerV1("/blocks", forAPISet(blocksHandler(gateway), []string{EndpointsRead}))
 	webHandlerV1("/last_blocks", forAPISet(lastBlocksHandler(gateway), []string{EndpointsRead}))
@@ -477,11 +481,21 @@ func newServerMux(c muxConfig, gateway Gatewayer, rpc *webrpc.WebRPC) *http.Serv
 
 	// Network admin endpoints
 	webHandlerV1("/network/connection/disconnect", forAPISet(disconnectHandler(gateway), []string{EndpointsNetCtrl}))
+	webHandlerV1("/network/connection/connect", forAPISet(connectHandler(gateway), []string{EndpointsNetCtrl}))
+	webHandlerV1("/network/peer/add", forAPISet(addPeerHandler(gateway), []string{EndpointsNetCtrl}))
+	webHandlerV1("/network/peer/remove", forAPISet(removePeerHandler(gateway), []string{EndpointsNetCtrl}))
+	webHandlerV1("/network/peer/trust", forAPISet(trustPeerHandler(gateway), []string{EndpointsNetCtrl}))
+	webHandlerV1("/network/peer/private", forAPISet(privatePeerHandler(gateway), []string{EndpointsNetCtrl}))
+	webHandlerV1("/network/peer/reset-retries", forAPISet(resetPeerRetriesHandler(gateway), []string{EndpointsNetCtrl}))
 
 	// Transaction related endpoints
 	webHandlerV1("/pendingTxs", forAPISet(pendingTxnsHandler(gateway), []string{EndpointsRead}))
 	webHandlerV1("/transaction", forAPISet(transactionHandler(gateway), []string{EndpointsRead}))
+	webHandlerV2("/blockchain/publisher", forAPISet(blockPublisherHandler(gateway), []string{EndpointsRead, EndpointsStatus}))
 	webHandlerV2("/transaction/verify", forAPISet(verifyTxnHandler(gateway), []string{EndpointsRead}))
+	webHandlerV2("/transaction/relay", forAPISet(transactionRelayHandler(gateway), []string{EndpointsRead}))
+	webHandlerV2("/transaction/conflicts", forAPISet(transactionConflictsHandler(gateway), []string{EndpointsRead}))
+	webHandlerV2("/transaction/conflicts/events", forAPISet(transactionConflictEventsHandler(gateway), []string{EndpointsRead}))
 	webHandlerV1("/transactions", forAPISet(transactionsHandler(gateway), []string{EndpointsRead}))
 	webHandlerV1("/injectTransaction", forAPISet(injectTransactionHandler(gateway), []string{EndpointsTransaction, EndpointsWallet}))
 	webHandlerV1("/resendUnconfirmedTxns", forAPISet(resendUnconfirmedTxnsHandler(gateway), []string{EndpointsTransaction}))
@@ -497,7 +511,7 @@ func newServerMux(c muxConfig, gateway Gatewayer, rpc *webrpc.WebRPC) *http.Serv
 	webHandlerV2("/metrics", forAPISet(promhttp.Handler().(http.HandlerFunc), []string{EndpointsPrometheus}))
 
 	// Address related endpoints
-	webHandlerV2("/address/verify", forAPISet(addressVerifyHandler, []string{EndpointsRead}))
+	webHandlerV2("/address/verify", forAPISet(addressVerifyHandler(c.health.CoinName), []string{EndpointsRead}))
 
 	// Explorer endpoints
 	webHandlerV1("/explorer/address", forAPISet(transactionsForAddressHandler(gateway), []string{EndpointsRead}))
@@ -42,6 +42,7 @@ var endpoints = []string{
 	"/block",
 	"/blockchain/metadata",
 	"/blockchain/progress",
+	"/blockchain/double_sign_proofs",
 	"/blocks",
 	"/coinSupply",
 	"/explorer/address",
@@ -50,11 +51,17 @@ var endpoints = []string{
 	"/last_blocks",
 	"/version",
 	"/network/connection",
+	"/network/connection/connect",
 	"/network/connection/disconnect",
 	"/network/connections",
 	"/network/connections/exchange",
 	"/network/connections/trust",
 	"/network/defaultConnections",
+	"/network/peer/add",
+	"/network/peer/private",
+	"/network/peer/remove",
+	"/network/peer/reset-retries",
+	"/network/peer/trust",
 	"/outputs",
 	"/pendingTxs",
 	"/rawtx",
@@ -84,6 +91,7 @@ var endpoints = []string{
 	"/api/v1/block",
 	"/api/v1/blockchain/metadata",
 	"/api/v1/blockchain/progress",
+	"/api/v1/blockchain/double_sign_proofs",
 	"/api/v1/blocks",
 	"/api/v1/coinSupply",
 	"/api/v1/explorer/address",
@@ -92,10 +100,17 @@ var endpoints = []string{
 	"/api/v1/last_blocks",
 	"/api/v1/version",
 	"/api/v1/network/connection",
+	"/api/v1/network/connection/connect",
+	"/api/v1/network/connection/disconnect",
 	"/api/v1/network/connections",
 	"/api/v1/network/connections/exchange",
 	"/api/v1/network/connections/trust",
 	"/api/v1/network/defaultConnections",
+	"/api/v1/network/peer/add",
+	"/api/v1/network/peer/private",
+	"/api/v1/network/peer/remove",
+	"/api/v1/network/peer/reset-retries",
+	"/api/v1/network/peer/trust",
 	"/api/v1/outputs",
 	"/api/v1/pendingTxs",
 	"/api/v1/rawtx",
@@ -119,9 +134,16 @@ var endpoints = []string{
 	"/api/v1/wallets/folderName",
 	"/api/v1/webrpc",
 
+	"/api/v2/blockchain/publisher",
 	"/api/v2/transaction/verify",
+	"/api/v2/transaction/relay",
+	"/api/v2/transaction/conflicts",
+	"/api/v2/transaction/conflicts/events",
 	"/api/v2/address/verify",
 	"/api/v2/wallet/recover",
+	"/api/v2/wallet/htlc/create",
+	"/api/v2/wallet/htlc/claim",
+	"/api/v2/wallet/htlc/refund",
 }
 
 // TestEnableGUI tests enable gui option, EnableGUI isn't part of Gateway API,
@@ -7,6 +7,7 @@ import coin "github.com/skycoin/skycoin/src/coin"
 import daemon "github.com/skycoin/skycoin/src/daemon"
 import historydb "github.com/skycoin/skycoin/src/visor/historydb"
 import mock "github.com/stretchr/testify/mock"
+import time "time"
 import visor "github.com/skycoin/skycoin/src/visor"
 import wallet "github.com/skycoin/skycoin/src/wallet"
 
@@ -15,6 +16,66 @@ type MockGatewayer struct {
 	mock.Mock
 }
 
+// AddPeer provides a mock function with given fields: addr
+func (_m *MockGatewayer) AddPeer(addr string) error {
+	ret := _m.Called(addr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(addr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConnectPeer provides a mock function with given fields: addr
+func (_m *MockGatewayer) ConnectPeer(addr string) error {
+	ret := _m.Called(addr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(addr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateHTLCSpend provides a mock function with given fields: p
+func (_m *MockGatewayer) CreateHTLCSpend(p wallet.HTLCSpendParams) (*coin.Transaction, []wallet.UxBalance, error) {
+	ret := _m.Called(p)
+
+	var r0 *coin.Transaction
+	if rf, ok := ret.Get(0).(func(wallet.HTLCSpendParams) *coin.Transaction); ok {
+		r0 = rf(p)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coin.Transaction)
+		}
+	}
+
+	var r1 []wallet.UxBalance
+	if rf, ok := ret.Get(1).(func(wallet.HTLCSpendParams) []wallet.UxBalance); ok {
+		r1 = rf(p)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]wallet.UxBalance)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(wallet.HTLCSpendParams) error); ok {
+		r2 = rf(p)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // CreateTransaction provides a mock function with given fields: w
 func (_m *MockGatewayer) CreateTransaction(w wallet.CreateTransactionParams) (*coin.Transaction, []wallet.UxBalance, error) {
 	ret := _m.Called(w)
@@ -229,6 +290,38 @@ func (_m *MockGatewayer) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.Bal
 	return r0, r1
 }
 
+// GetBlockPublishStats provides a mock function with given fields:
+func (_m *MockGatewayer) GetBlockPublishStats() (daemon.BlockPublishPolicy, daemon.BlockPublishStats, bool) {
+	ret := _m.Called()
+
+	var r0 daemon.BlockPublishPolicy
+	if rf, ok := ret.Get(0).(func() daemon.BlockPublishPolicy); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(daemon.BlockPublishPolicy)
+		}
+	}
+
+	var r1 daemon.BlockPublishStats
+	if rf, ok := ret.Get(1).(func() daemon.BlockPublishStats); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(daemon.BlockPublishStats)
+		}
+	}
+
+	var r2 bool
+	if rf, ok := ret.Get(2).(func() bool); ok {
+		r2 = rf()
+	} else {
+		r2 = ret.Get(2).(bool)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBlockchainMetadata provides a mock function with given fields:
 func (_m *MockGatewayer) GetBlockchainMetadata() (*visor.BlockchainMetadata, error) {
 	ret := _m.Called()
@@ -447,6 +540,29 @@ func (_m *MockGatewayer) GetDefaultConnections() []string {
 	return r0
 }
 
+// GetDoubleSignProofs provides a mock function with given fields:
+func (_m *MockGatewayer) GetDoubleSignProofs() ([]visor.DoubleSignProof, error) {
+	ret := _m.Called()
+
+	var r0 []visor.DoubleSignProof
+	if rf, ok := ret.Get(0).(func() []visor.DoubleSignProof); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.DoubleSignProof)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetExchgConnection provides a mock function with given fields:
 func (_m *MockGatewayer) GetExchgConnection() []string {
 	ret := _m.Called()
@@ -823,6 +939,61 @@ func (_m *MockGatewayer) GetTrustConnections() []string {
 	return r0
 }
 
+// GetTxnConflictEvents provides a mock function with given fields: since, wait
+func (_m *MockGatewayer) GetTxnConflictEvents(since uint64, wait time.Duration) []daemon.TxnConflictEvent {
+	ret := _m.Called(since, wait)
+
+	var r0 []daemon.TxnConflictEvent
+	if rf, ok := ret.Get(0).(func(uint64, time.Duration) []daemon.TxnConflictEvent); ok {
+		r0 = rf(since, wait)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]daemon.TxnConflictEvent)
+		}
+	}
+
+	return r0
+}
+
+// GetTxnConflicts provides a mock function with given fields: txids, addrs
+func (_m *MockGatewayer) GetTxnConflicts(txids []cipher.SHA256, addrs []cipher.Address) []daemon.TxnConflict {
+	ret := _m.Called(txids, addrs)
+
+	var r0 []daemon.TxnConflict
+	if rf, ok := ret.Get(0).(func([]cipher.SHA256, []cipher.Address) []daemon.TxnConflict); ok {
+		r0 = rf(txids, addrs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]daemon.TxnConflict)
+		}
+	}
+
+	return r0
+}
+
+// GetTxnRelay provides a mock function with given fields: txid
+func (_m *MockGatewayer) GetTxnRelay(txid cipher.SHA256) (daemon.TxnRelay, bool) {
+	ret := _m.Called(txid)
+
+	var r0 daemon.TxnRelay
+	if rf, ok := ret.Get(0).(func(cipher.SHA256) daemon.TxnRelay); ok {
+		r0 = rf(txid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(daemon.TxnRelay)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(cipher.SHA256) bool); ok {
+		r1 = rf(txid)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // GetUnspentOutputsSummary provides a mock function with given fields: filters
 func (_m *MockGatewayer) GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error) {
 	ret := _m.Called(filters)
@@ -996,6 +1167,29 @@ func (_m *MockGatewayer) GetWalletSeed(wltID string, password []byte) (string, e
 	return r0, r1
 }
 
+// GetWalletTxnBroadcasts provides a mock function with given fields: wltID
+func (_m *MockGatewayer) GetWalletTxnBroadcasts(wltID string) ([]daemon.TxnBroadcast, error) {
+	ret := _m.Called(wltID)
+
+	var r0 []daemon.TxnBroadcast
+	if rf, ok := ret.Get(0).(func(string) []daemon.TxnBroadcast); ok {
+		r0 = rf(wltID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]daemon.TxnBroadcast)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(wltID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetWalletUnconfirmedTransactions provides a mock function with given fields: wltID
 func (_m *MockGatewayer) GetWalletUnconfirmedTransactions(wltID string) ([]visor.UnconfirmedTransaction, error) {
 	ret := _m.Called(wltID)
@@ -1134,6 +1328,20 @@ func (_m *MockGatewayer) RecoverWallet(wltID string, seed string, password []byt
 	return r0, r1
 }
 
+// RemovePeer provides a mock function with given fields: addr
+func (_m *MockGatewayer) RemovePeer(addr string) error {
+	ret := _m.Called(addr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(addr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResendUnconfirmedTxns provides a mock function with given fields:
 func (_m *MockGatewayer) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
 	ret := _m.Called()
@@ -1157,6 +1365,48 @@ func (_m *MockGatewayer) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
 	return r0, r1
 }
 
+// ResetPeerRetryTimes provides a mock function with given fields: addr
+func (_m *MockGatewayer) ResetPeerRetryTimes(addr string) error {
+	ret := _m.Called(addr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(addr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPeerPrivate provides a mock function with given fields: addr, private
+func (_m *MockGatewayer) SetPeerPrivate(addr string, private bool) error {
+	ret := _m.Called(addr, private)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, bool) error); ok {
+		r0 = rf(addr, private)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPeerTrusted provides a mock function with given fields: addr, trusted
+func (_m *MockGatewayer) SetPeerTrusted(addr string, trusted bool) error {
+	ret := _m.Called(addr, trusted)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, bool) error); ok {
+		r0 = rf(addr, trusted)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Spend provides a mock function with given fields: wltID, password, coins, dest
 func (_m *MockGatewayer) Spend(wltID string, password []byte, coins uint64, dest cipher.Address) (*coin.Transaction, error) {
 	ret := _m.Called(wltID, password, coins, dest)
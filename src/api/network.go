@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/pex"
 	"github.com/skycoin/skycoin/src/readable"
 	wh "github.com/skycoin/skycoin/src/util/http"
 )
@@ -50,6 +51,8 @@ func connectionHandler(gateway Gatewayer) http.HandlerFunc {
 // Connections wraps []Connection
 type Connections struct {
 	Connections []readable.Connection `json:"connections"`
+	// Number of introduced connections by user agent
+	UserAgents map[string]int `json:"user_agents"`
 }
 
 // NewConnections copies []daemon.Connection to a struct with json tags
@@ -61,6 +64,7 @@ func NewConnections(dconns []daemon.Connection) Connections {
 
 	return Connections{
 		Connections: conns,
+		UserAgents:  daemon.CountUserAgents(dconns),
 	}
 }
 
@@ -226,3 +230,201 @@ func disconnectHandler(gateway Gatewayer) http.HandlerFunc {
 		wh.SendJSONOr500(logger, w, struct{}{})
 	}
 }
+
+// connectHandler adds a peer to the peer list and starts an outgoing connection to it
+// URI: /api/v1/network/connection/connect
+// Method: POST
+// Args:
+//	addr: ip:port address of the peer
+func connectHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		addr := r.FormValue("addr")
+		if addr == "" {
+			wh.Error400(w, "addr is required")
+			return
+		}
+
+		if err := gateway.ConnectPeer(addr); err != nil {
+			writeNetCtrlError(w, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, struct{}{})
+	}
+}
+
+// addPeerHandler adds a peer to the peer list
+// URI: /api/v1/network/peer/add
+// Method: POST
+// Args:
+//	addr: ip:port address of the peer
+func addPeerHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		addr := r.FormValue("addr")
+		if addr == "" {
+			wh.Error400(w, "addr is required")
+			return
+		}
+
+		if err := gateway.AddPeer(addr); err != nil {
+			writeNetCtrlError(w, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, struct{}{})
+	}
+}
+
+// removePeerHandler removes a peer from the peer list
+// URI: /api/v1/network/peer/remove
+// Method: POST
+// Args:
+//	addr: ip:port address of the peer
+func removePeerHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		addr := r.FormValue("addr")
+		if addr == "" {
+			wh.Error400(w, "addr is required")
+			return
+		}
+
+		if err := gateway.RemovePeer(addr); err != nil {
+			writeNetCtrlError(w, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, struct{}{})
+	}
+}
+
+// trustPeerHandler marks a peer as trusted or untrusted
+// URI: /api/v1/network/peer/trust
+// Method: POST
+// Args:
+//	addr: ip:port address of the peer
+//	trusted: [optional] "true" or "false", defaults to "true"
+func trustPeerHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		addr := r.FormValue("addr")
+		if addr == "" {
+			wh.Error400(w, "addr is required")
+			return
+		}
+
+		trusted := true
+		if v := r.FormValue("trusted"); v != "" {
+			var err error
+			trusted, err = strconv.ParseBool(v)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("invalid 'trusted' value: %v", err))
+				return
+			}
+		}
+
+		if err := gateway.SetPeerTrusted(addr, trusted); err != nil {
+			writeNetCtrlError(w, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, struct{}{})
+	}
+}
+
+// privatePeerHandler marks a peer as private or public. Private peers are not shared with other peers
+// URI: /api/v1/network/peer/private
+// Method: POST
+// Args:
+//	addr: ip:port address of the peer
+//	private: [optional] "true" or "false", defaults to "true"
+func privatePeerHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		addr := r.FormValue("addr")
+		if addr == "" {
+			wh.Error400(w, "addr is required")
+			return
+		}
+
+		private := true
+		if v := r.FormValue("private"); v != "" {
+			var err error
+			private, err = strconv.ParseBool(v)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("invalid 'private' value: %v", err))
+				return
+			}
+		}
+
+		if err := gateway.SetPeerPrivate(addr, private); err != nil {
+			writeNetCtrlError(w, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, struct{}{})
+	}
+}
+
+// resetPeerRetriesHandler clears the connection retry counter of a peer, or of all peers
+// URI: /api/v1/network/peer/reset-retries
+// Method: POST
+// Args:
+//	addr: [optional] ip:port address of the peer. If not provided, the retry counters of all peers are cleared
+func resetPeerRetriesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		if err := gateway.ResetPeerRetryTimes(r.FormValue("addr")); err != nil {
+			writeNetCtrlError(w, err)
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, struct{}{})
+	}
+}
+
+// writeNetCtrlError writes the error response for an error returned by a network admin operation
+func writeNetCtrlError(w http.ResponseWriter, err error) {
+	switch err {
+	case daemon.ErrPeerNotExist:
+		wh.Error404(w, "")
+	case pex.ErrInvalidAddress,
+		daemon.ErrAlreadyConnected,
+		daemon.ErrAlreadyConnectedIP,
+		daemon.ErrPeerNotLocalhost:
+		wh.Error400(w, err.Error())
+	case daemon.ErrNetworkingDisabled,
+		daemon.ErrOutgoingConnectionsDisabled:
+		wh.Error403(w, err.Error())
+	case pex.ErrPeerlistFull:
+		wh.Error503(w, err.Error())
+	default:
+		wh.Error500(w, err.Error())
+	}
+}
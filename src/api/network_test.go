@@ -59,31 +59,42 @@ func TestConnection(t *testing.T) {
 					LastReceived: time.Unix(1111111, 0),
 				},
 				ConnectionDetails: daemon.ConnectionDetails{
-					Outgoing:    true,
-					ConnectedAt: time.Unix(222222, 0),
-					State:       daemon.ConnectionStateIntroduced,
-					Mirror:      6789,
-					ListenPort:  9877,
-					Height:      1234,
-					UserAgent:   useragent.MustParse("skycoin:0.25.1(foo)"),
+					Outgoing:        true,
+					ConnectedAt:     time.Unix(222222, 0),
+					State:           daemon.ConnectionStateIntroduced,
+					Mirror:          6789,
+					ListenPort:      9877,
+					Height:          1234,
+					UserAgent:       useragent.MustParse("skycoin:0.25.1(foo)"),
+					RTT:             time.Millisecond * 120,
+					BlocksLatency:   time.Millisecond * 1500,
+					BlocksResponses: 4,
+					BlocksFailures:  1,
 				},
 				Pex: pex.Peer{
 					Trusted: false,
+					Quality: 0.8,
 				},
 			},
 			result: &readable.Connection{
-				Addr:          "127.0.0.1:6061",
-				GnetID:        1,
-				LastSent:      99999,
-				LastReceived:  1111111,
-				ConnectedAt:   222222,
-				Outgoing:      true,
-				State:         daemon.ConnectionStateIntroduced,
-				Mirror:        6789,
-				ListenPort:    9877,
-				Height:        1234,
-				UserAgent:     useragent.MustParse("skycoin:0.25.1(foo)"),
-				IsTrustedPeer: false,
+				Addr:            "127.0.0.1:6061",
+				GnetID:          1,
+				LastSent:        99999,
+				LastReceived:    1111111,
+				ConnectedAt:     222222,
+				Outgoing:        true,
+				State:           daemon.ConnectionStateIntroduced,
+				Mirror:          6789,
+				ListenPort:      9877,
+				Height:          1234,
+				UserAgent:       useragent.MustParse("skycoin:0.25.1(foo)"),
+				IsTrustedPeer:   false,
+				RTT:             120,
+				BlocksLatency:   1500,
+				BlocksResponses: 4,
+				BlocksFailures:  1,
+				Quality:         0.8,
+				RelayMode:       readable.RelayModeFull,
 			},
 		},
 
@@ -159,6 +170,7 @@ func TestConnections(t *testing.T) {
 		},
 		Pex: pex.Peer{
 			Trusted: true,
+			Quality: 0.75,
 		},
 	}
 
@@ -176,7 +188,8 @@ func TestConnections(t *testing.T) {
 			Mirror:      9877,
 			ListenPort:  9879,
 			Height:      1234,
-			UserAgent:   useragent.MustParse("skycoin:0.25.1(foo)"),
+			UserAgent:   useragent.MustParse("skycoin:0.24.1"),
+			BlocksOnly:  true,
 		},
 	}
 
@@ -193,6 +206,8 @@ func TestConnections(t *testing.T) {
 		Height:        1234,
 		UserAgent:     useragent.MustParse("skycoin:0.25.1(foo)"),
 		IsTrustedPeer: true,
+		Quality:       0.75,
+		RelayMode:     readable.RelayModeFull,
 	}
 
 	readIntrIn := readable.Connection{
@@ -206,12 +221,18 @@ func TestConnections(t *testing.T) {
 		Mirror:        9877,
 		ListenPort:    9879,
 		Height:        1234,
-		UserAgent:     useragent.MustParse("skycoin:0.25.1(foo)"),
+		UserAgent:     useragent.MustParse("skycoin:0.24.1"),
 		IsTrustedPeer: false,
+		Quality:       pex.DefaultPeerQuality,
+		RelayMode:     readable.RelayModeBlocksOnly,
 	}
 
 	conns := []daemon.Connection{intrOut, intrIn}
 	readConns := []readable.Connection{readIntrOut, readIntrIn}
+	userAgents := map[string]int{
+		"skycoin:0.25.1(foo)": 1,
+		"skycoin:0.24.1":      1,
+	}
 
 	tt := []struct {
 		name                                 string
@@ -239,6 +260,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				UserAgents:  userAgents,
 			},
 		},
 
@@ -251,6 +273,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				UserAgents:  userAgents,
 			},
 		},
 
@@ -263,6 +286,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				UserAgents:  userAgents,
 			},
 		},
 
@@ -275,6 +299,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				UserAgents:  userAgents,
 			},
 		},
 
@@ -288,6 +313,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				UserAgents:  userAgents,
 			},
 		},
 
@@ -300,6 +326,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				UserAgents:  userAgents,
 			},
 		},
 
@@ -628,3 +655,469 @@ func TestDisconnect(t *testing.T) {
 		})
 	}
 }
+
+func TestConnect(t *testing.T) {
+	tt := []struct {
+		name       string
+		method     string
+		status     int
+		err        string
+		addr       string
+		connectErr error
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+
+		{
+			name:   "400 missing addr",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - addr is required",
+		},
+
+		{
+			name:       "400 invalid addr",
+			method:     http.MethodPost,
+			status:     http.StatusBadRequest,
+			err:        "400 Bad Request - Invalid address",
+			addr:       "foo",
+			connectErr: pex.ErrInvalidAddress,
+		},
+
+		{
+			name:       "400 already connected",
+			method:     http.MethodPost,
+			status:     http.StatusBadRequest,
+			err:        "400 Bad Request - Already connected to this peer",
+			addr:       "11.22.33.44:6000",
+			connectErr: daemon.ErrAlreadyConnected,
+		},
+
+		{
+			name:       "403 networking disabled",
+			method:     http.MethodPost,
+			status:     http.StatusForbidden,
+			err:        "403 Forbidden - Networking is disabled",
+			addr:       "11.22.33.44:6000",
+			connectErr: daemon.ErrNetworkingDisabled,
+		},
+
+		{
+			name:       "503 peer list full",
+			method:     http.MethodPost,
+			status:     http.StatusServiceUnavailable,
+			err:        "503 Service Unavailable - Peer list full",
+			addr:       "11.22.33.44:6000",
+			connectErr: pex.ErrPeerlistFull,
+		},
+
+		{
+			name:       "500 ConnectPeer error",
+			method:     http.MethodPost,
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - foo",
+			addr:       "11.22.33.44:6000",
+			connectErr: errors.New("foo"),
+		},
+
+		{
+			name:   "200",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			addr:   "11.22.33.44:6000",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("ConnectPeer", tc.addr).Return(tc.connectErr)
+
+			v := url.Values{}
+			if tc.addr != "" {
+				v.Add("addr", tc.addr)
+			}
+
+			testNetCtrlRequest(t, gateway, tc.method, "/api/v1/network/connection/connect", v, tc.status, tc.err)
+		})
+	}
+}
+
+func TestAddPeer(t *testing.T) {
+	tt := []struct {
+		name   string
+		method string
+		status int
+		err    string
+		addr   string
+		addErr error
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+
+		{
+			name:   "400 missing addr",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - addr is required",
+		},
+
+		{
+			name:   "400 invalid addr",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid address",
+			addr:   "foo",
+			addErr: pex.ErrInvalidAddress,
+		},
+
+		{
+			name:   "500 AddPeer error",
+			method: http.MethodPost,
+			status: http.StatusInternalServerError,
+			err:    "500 Internal Server Error - foo",
+			addr:   "11.22.33.44:6000",
+			addErr: errors.New("foo"),
+		},
+
+		{
+			name:   "200",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			addr:   "11.22.33.44:6000",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("AddPeer", tc.addr).Return(tc.addErr)
+
+			v := url.Values{}
+			if tc.addr != "" {
+				v.Add("addr", tc.addr)
+			}
+
+			testNetCtrlRequest(t, gateway, tc.method, "/api/v1/network/peer/add", v, tc.status, tc.err)
+		})
+	}
+}
+
+func TestRemovePeer(t *testing.T) {
+	tt := []struct {
+		name      string
+		method    string
+		status    int
+		err       string
+		addr      string
+		removeErr error
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+
+		{
+			name:   "400 missing addr",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - addr is required",
+		},
+
+		{
+			name:      "404 peer not found",
+			method:    http.MethodPost,
+			status:    http.StatusNotFound,
+			err:       "404 Not Found",
+			addr:      "11.22.33.44:6000",
+			removeErr: daemon.ErrPeerNotExist,
+		},
+
+		{
+			name:      "500 RemovePeer error",
+			method:    http.MethodPost,
+			status:    http.StatusInternalServerError,
+			err:       "500 Internal Server Error - foo",
+			addr:      "11.22.33.44:6000",
+			removeErr: errors.New("foo"),
+		},
+
+		{
+			name:   "200",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			addr:   "11.22.33.44:6000",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("RemovePeer", tc.addr).Return(tc.removeErr)
+
+			v := url.Values{}
+			if tc.addr != "" {
+				v.Add("addr", tc.addr)
+			}
+
+			testNetCtrlRequest(t, gateway, tc.method, "/api/v1/network/peer/remove", v, tc.status, tc.err)
+		})
+	}
+}
+
+func TestTrustPeer(t *testing.T) {
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		err           string
+		addr          string
+		trusted       string
+		expectTrusted bool
+		trustErr      error
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+
+		{
+			name:   "400 missing addr",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - addr is required",
+		},
+
+		{
+			name:    "400 invalid trusted",
+			method:  http.MethodPost,
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - invalid 'trusted' value: strconv.ParseBool: parsing \"foo\": invalid syntax",
+			addr:    "11.22.33.44:6000",
+			trusted: "foo",
+		},
+
+		{
+			name:          "404 peer not found",
+			method:        http.MethodPost,
+			status:        http.StatusNotFound,
+			err:           "404 Not Found",
+			addr:          "11.22.33.44:6000",
+			expectTrusted: true,
+			trustErr:      daemon.ErrPeerNotExist,
+		},
+
+		{
+			name:          "500 SetPeerTrusted error",
+			method:        http.MethodPost,
+			status:        http.StatusInternalServerError,
+			err:           "500 Internal Server Error - foo",
+			addr:          "11.22.33.44:6000",
+			expectTrusted: true,
+			trustErr:      errors.New("foo"),
+		},
+
+		{
+			name:          "200 default trusted",
+			method:        http.MethodPost,
+			status:        http.StatusOK,
+			addr:          "11.22.33.44:6000",
+			expectTrusted: true,
+		},
+
+		{
+			name:          "200 untrusted",
+			method:        http.MethodPost,
+			status:        http.StatusOK,
+			addr:          "11.22.33.44:6000",
+			trusted:       "false",
+			expectTrusted: false,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("SetPeerTrusted", tc.addr, tc.expectTrusted).Return(tc.trustErr)
+
+			v := url.Values{}
+			if tc.addr != "" {
+				v.Add("addr", tc.addr)
+			}
+			if tc.trusted != "" {
+				v.Add("trusted", tc.trusted)
+			}
+
+			testNetCtrlRequest(t, gateway, tc.method, "/api/v1/network/peer/trust", v, tc.status, tc.err)
+		})
+	}
+}
+
+func TestPrivatePeer(t *testing.T) {
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		err           string
+		addr          string
+		private       string
+		expectPrivate bool
+		privateErr    error
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+
+		{
+			name:   "400 missing addr",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - addr is required",
+		},
+
+		{
+			name:    "400 invalid private",
+			method:  http.MethodPost,
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - invalid 'private' value: strconv.ParseBool: parsing \"foo\": invalid syntax",
+			addr:    "11.22.33.44:6000",
+			private: "foo",
+		},
+
+		{
+			name:          "404 peer not found",
+			method:        http.MethodPost,
+			status:        http.StatusNotFound,
+			err:           "404 Not Found",
+			addr:          "11.22.33.44:6000",
+			expectPrivate: true,
+			privateErr:    daemon.ErrPeerNotExist,
+		},
+
+		{
+			name:          "200 default private",
+			method:        http.MethodPost,
+			status:        http.StatusOK,
+			addr:          "11.22.33.44:6000",
+			expectPrivate: true,
+		},
+
+		{
+			name:          "200 public",
+			method:        http.MethodPost,
+			status:        http.StatusOK,
+			addr:          "11.22.33.44:6000",
+			private:       "false",
+			expectPrivate: false,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("SetPeerPrivate", tc.addr, tc.expectPrivate).Return(tc.privateErr)
+
+			v := url.Values{}
+			if tc.addr != "" {
+				v.Add("addr", tc.addr)
+			}
+			if tc.private != "" {
+				v.Add("private", tc.private)
+			}
+
+			testNetCtrlRequest(t, gateway, tc.method, "/api/v1/network/peer/private", v, tc.status, tc.err)
+		})
+	}
+}
+
+func TestResetPeerRetries(t *testing.T) {
+	tt := []struct {
+		name     string
+		method   string
+		status   int
+		err      string
+		addr     string
+		resetErr error
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+
+		{
+			name:     "404 peer not found",
+			method:   http.MethodPost,
+			status:   http.StatusNotFound,
+			err:      "404 Not Found",
+			addr:     "11.22.33.44:6000",
+			resetErr: daemon.ErrPeerNotExist,
+		},
+
+		{
+			name:   "200 all peers",
+			method: http.MethodPost,
+			status: http.StatusOK,
+		},
+
+		{
+			name:   "200 one peer",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			addr:   "11.22.33.44:6000",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("ResetPeerRetryTimes", tc.addr).Return(tc.resetErr)
+
+			v := url.Values{}
+			if tc.addr != "" {
+				v.Add("addr", tc.addr)
+			}
+
+			testNetCtrlRequest(t, gateway, tc.method, "/api/v1/network/peer/reset-retries", v, tc.status, tc.err)
+		})
+	}
+}
+
+// testNetCtrlRequest sends a form request to a network admin endpoint and checks the response
+func testNetCtrlRequest(t *testing.T, gateway *MockGatewayer, method, endpoint string, v url.Values, expectStatus int, expectErr string) {
+	req, err := http.NewRequest(method, endpoint, strings.NewReader(v.Encode()))
+	require.NoError(t, err)
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	rr := httptest.NewRecorder()
+	handler := newServerMux(defaultMuxConfig(), gateway, nil)
+	handler.ServeHTTP(rr, req)
+
+	status := rr.Code
+	require.Equal(t, expectStatus, status, "got `%v` want `%v`", status, expectStatus)
+
+	if status != http.StatusOK {
+		require.Equal(t, expectErr, strings.TrimSpace(rr.Body.String()), "got `%v`| %d, want `%v`",
+			strings.TrimSpace(rr.Body.String()), status, expectErr)
+	} else {
+		var obj struct{}
+		err = json.Unmarshal(rr.Body.Bytes(), &obj)
+		require.NoError(t, err)
+	}
+}
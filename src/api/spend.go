@@ -16,6 +16,7 @@ import (
 	"github.com/skycoin/skycoin/src/util/droplet"
 	"github.com/skycoin/skycoin/src/util/fee"
 	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/visor/blockdb"
 	"github.com/skycoin/skycoin/src/wallet"
 )
@@ -254,11 +255,12 @@ func NewCreatedTransactionInput(out wallet.UxBalance) (*CreatedTransactionInput,
 
 // createTransactionRequest is sent to /wallet/transaction
 type createTransactionRequest struct {
-	IgnoreUnconfirmed bool                           `json:"ignore_unconfirmed"`
-	HoursSelection    hoursSelection                 `json:"hours_selection"`
-	Wallet            createTransactionRequestWallet `json:"wallet"`
-	ChangeAddress     *wh.Address                    `json:"change_address,omitempty"`
-	To                []receiver                     `json:"to"`
+	IgnoreUnconfirmed      bool                           `json:"ignore_unconfirmed"`
+	SpendUnconfirmedChange bool                           `json:"spend_unconfirmed_change"`
+	HoursSelection         hoursSelection                 `json:"hours_selection"`
+	Wallet                 createTransactionRequestWallet `json:"wallet"`
+	ChangeAddress          *wh.Address                    `json:"change_address,omitempty"`
+	To                     []receiver                     `json:"to"`
 }
 
 // createTransactionRequestWallet defines a wallet to spend from and optionally which addresses in the wallet
@@ -453,7 +455,8 @@ func (r createTransactionRequest) ToWalletParams() wallet.CreateTransactionParam
 	}
 
 	return wallet.CreateTransactionParams{
-		IgnoreUnconfirmed: r.IgnoreUnconfirmed,
+		IgnoreUnconfirmed:      r.IgnoreUnconfirmed,
+		SpendUnconfirmedChange: r.SpendUnconfirmedChange,
 		HoursSelection: wallet.HoursSelection{
 			Type:        r.HoursSelection.Type,
 			Mode:        r.HoursSelection.Mode,
@@ -513,7 +516,8 @@ func createTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 				switch err {
 				case fee.ErrTxnNoFee,
 					fee.ErrTxnInsufficientCoinHours,
-					wallet.ErrSpendingUnconfirmed:
+					wallet.ErrSpendingUnconfirmed,
+					visor.ErrUnconfirmedChainsDisabled:
 					wh.Error400(w, err.Error())
 				default:
 					wh.Error500(w, err.Error())
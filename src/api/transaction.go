@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
@@ -464,6 +465,155 @@ func rawTxnHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// Returns the relay state of an unconfirmed transaction: when and from which peer it was first received,
+// the peers it was announced to, the peers that requested it and the reasons it was rejected when received
+// Method: GET
+// URI: /api/v2/transaction/relay
+// Args:
+//	txid: transaction hash [required]
+func transactionRelayHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txid := r.FormValue("txid")
+		if txid == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "txid is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		h, err := cipher.SHA256FromHex(txid)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid txid: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		relay, ok := gateway.GetTxnRelay(h)
+		if !ok {
+			resp := NewHTTPErrorResponse(http.StatusNotFound, "transaction was not relayed recently")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: readable.NewTxnRelay(relay),
+		})
+	}
+}
+
+// maxTxnConflictEventsWait is the longest time a request for conflict events can wait for a new event
+const maxTxnConflictEventsWait = 30 * time.Second
+
+// TxnConflictsResponse the response data struct for /api/v2/transaction/conflicts
+type TxnConflictsResponse struct {
+	Conflicts []readable.TxnConflict `json:"conflicts"`
+}
+
+// Returns the recently seen outputs spent by more than one unconfirmed transaction.
+// Each conflict is evidence of an attempt to spend an output twice.
+// If neither txids nor addrs are specified, all recent conflicts are returned
+// Method: GET
+// URI: /api/v2/transaction/conflicts
+// Args:
+//	txids: comma-separated list of transaction hashes [optional]
+//	addrs: comma-separated list of addresses owning the conflicting outputs [optional]
+func transactionConflictsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txids, err := parseHashesFromStr(r.FormValue("txids"))
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		addrs, err := parseAddressesFromStr(r.FormValue("addrs"))
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		conflicts := gateway.GetTxnConflicts(txids, addrs)
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: TxnConflictsResponse{
+				Conflicts: readable.NewTxnConflicts(conflicts),
+			},
+		})
+	}
+}
+
+// TxnConflictEventsResponse the response data struct for /api/v2/transaction/conflicts/events
+type TxnConflictEventsResponse struct {
+	Events []readable.TxnConflictEvent `json:"events"`
+}
+
+// Returns the events emitted when a transaction conflict is detected, or when another transaction
+// spending the output of a known conflict is seen. Event IDs increase by one for each event.
+// If there are no events after since, the request waits up to wait seconds for the next event,
+// so that clients can follow the events by polling with the ID of the last event they received
+// Method: GET
+// URI: /api/v2/transaction/conflicts/events
+// Args:
+//	since: return events with an ID greater than this [optional, default 0]
+//	wait: seconds to wait for an event if there are none, at most 30 [optional, default 0]
+func transactionConflictEventsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var since uint64
+		if s := r.FormValue("since"); s != "" {
+			var err error
+			since, err = strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, "invalid since value")
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		var wait time.Duration
+		if s := r.FormValue("wait"); s != "" {
+			n, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, "invalid wait value")
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			wait = time.Duration(n) * time.Second
+			if n > uint64(maxTxnConflictEventsWait/time.Second) {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("wait must be at most %d", maxTxnConflictEventsWait/time.Second))
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		events := gateway.GetTxnConflictEvents(since, wait)
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: TxnConflictEventsResponse{
+				Events: readable.NewTxnConflictEvents(events),
+			},
+		})
+	}
+}
+
 // VerifyTxnRequest represents the data struct of the request for /api/v2/transaction/verify
 type VerifyTxnRequest struct {
 	EncodedTransaction string `json:"encoded_transaction"`
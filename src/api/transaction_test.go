@@ -1542,3 +1542,432 @@ func TestVerifyTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionRelay(t *testing.T) {
+	txid := testutil.RandSHA256(t)
+	now := time.Unix(1542443907, 0).UTC()
+
+	relay := daemon.TxnRelay{
+		Txid:        txid,
+		FirstSeen:   now,
+		Source:      "1.1.1.1:6000",
+		AnnouncedTo: []string{"2.2.2.2:6000", "3.3.3.3:6000"},
+		Rejections: []daemon.TxnRelayRejection{
+			{
+				Addr:   "4.4.4.4:6000",
+				Reason: "Transaction violates soft constraint: Transaction has zero coinhour fee",
+				Soft:   true,
+				Time:   now.Add(time.Second),
+			},
+		},
+		Updated: now.Add(time.Second * 2),
+	}
+
+	tt := []struct {
+		name         string
+		method       string
+		status       int
+		txid         string
+		gatewayTxid  cipher.SHA256
+		relay        daemon.TxnRelay
+		found        bool
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - txid is required",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "txid is required"),
+		},
+		{
+			name:         "400 - invalid txid",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			txid:         "abc",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid txid: encoding/hex: odd length hex string"),
+		},
+		{
+			name:         "404",
+			method:       http.MethodGet,
+			status:       http.StatusNotFound,
+			txid:         txid.Hex(),
+			gatewayTxid:  txid,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "transaction was not relayed recently"),
+		},
+		{
+			name:        "200",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+			txid:        txid.Hex(),
+			gatewayTxid: txid,
+			relay:       relay,
+			found:       true,
+			httpResponse: HTTPResponse{
+				Data: readable.TxnRelay{
+					Txid:        txid.Hex(),
+					FirstSeen:   now.Unix(),
+					Source:      "1.1.1.1:6000",
+					AnnouncedTo: []string{"2.2.2.2:6000", "3.3.3.3:6000"},
+					RequestedBy: []string{},
+					Rejections: []readable.TxnRelayRejection{
+						{
+							Address: "4.4.4.4:6000",
+							Reason:  "Transaction violates soft constraint: Transaction has zero coinhour fee",
+							Soft:    true,
+							Time:    now.Unix() + 1,
+						},
+					},
+					Updated: now.Unix() + 2,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v2/transaction/relay"
+			gateway := &MockGatewayer{}
+			gateway.On("GetTxnRelay", tc.gatewayTxid).Return(tc.relay, tc.found)
+
+			v := url.Values{}
+			if tc.txid != "" {
+				v.Add("txid", tc.txid)
+			}
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway, nil)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.NewDecoder(rr.Body).Decode(&rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var relayRsp readable.TxnRelay
+				err := json.Unmarshal(rsp.Data, &relayRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(readable.TxnRelay), relayRsp)
+			}
+		})
+	}
+}
+
+func TestTransactionConflicts(t *testing.T) {
+	txidA := testutil.RandSHA256(t)
+	txidB := testutil.RandSHA256(t)
+	uxID := testutil.RandSHA256(t)
+	addr := testutil.MakeAddress()
+	now := time.Unix(1542443907, 0).UTC()
+
+	conflict := daemon.TxnConflict{
+		UxID:       uxID,
+		Address:    addr,
+		Txids:      []cipher.SHA256{txidA, txidB},
+		Source:     "1.1.1.1:6000",
+		DetectedAt: now,
+		Updated:    now.Add(time.Second),
+	}
+
+	readableConflict := readable.TxnConflict{
+		UxID:       uxID.Hex(),
+		Address:    addr.String(),
+		Txids:      []string{txidA.Hex(), txidB.Hex()},
+		Source:     "1.1.1.1:6000",
+		DetectedAt: now.Unix(),
+		Updated:    now.Unix() + 1,
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		txids         string
+		addrs         string
+		gatewayTxids  []cipher.SHA256
+		gatewayAddrs  []cipher.Address
+		gatewayResult []daemon.TxnConflict
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid txid",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			txids:        "abc",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "SHA256 hash \"abc\" is invalid: encoding/hex: odd length hex string"),
+		},
+		{
+			name:         "400 - invalid address",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			addrs:        "badaddr",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "address \"badaddr\" is invalid: Invalid address length"),
+		},
+		{
+			name:          "200 - all",
+			method:        http.MethodGet,
+			status:        http.StatusOK,
+			gatewayTxids:  []cipher.SHA256{},
+			gatewayAddrs:  []cipher.Address{},
+			gatewayResult: []daemon.TxnConflict{conflict},
+			httpResponse: HTTPResponse{
+				Data: TxnConflictsResponse{
+					Conflicts: []readable.TxnConflict{readableConflict},
+				},
+			},
+		},
+		{
+			name:          "200 - txids and addrs",
+			method:        http.MethodGet,
+			status:        http.StatusOK,
+			txids:         txidB.Hex(),
+			addrs:         addr.String(),
+			gatewayTxids:  []cipher.SHA256{txidB},
+			gatewayAddrs:  []cipher.Address{addr},
+			gatewayResult: []daemon.TxnConflict{conflict},
+			httpResponse: HTTPResponse{
+				Data: TxnConflictsResponse{
+					Conflicts: []readable.TxnConflict{readableConflict},
+				},
+			},
+		},
+		{
+			name:          "200 - none",
+			method:        http.MethodGet,
+			status:        http.StatusOK,
+			txids:         txidA.Hex(),
+			gatewayTxids:  []cipher.SHA256{txidA},
+			gatewayAddrs:  []cipher.Address{},
+			gatewayResult: []daemon.TxnConflict{},
+			httpResponse: HTTPResponse{
+				Data: TxnConflictsResponse{
+					Conflicts: []readable.TxnConflict{},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v2/transaction/conflicts"
+			gateway := &MockGatewayer{}
+			gateway.On("GetTxnConflicts", tc.gatewayTxids, tc.gatewayAddrs).Return(tc.gatewayResult)
+
+			v := url.Values{}
+			if tc.txids != "" {
+				v.Add("txids", tc.txids)
+			}
+			if tc.addrs != "" {
+				v.Add("addrs", tc.addrs)
+			}
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway, nil)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.NewDecoder(rr.Body).Decode(&rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var conflictsRsp TxnConflictsResponse
+				err := json.Unmarshal(rsp.Data, &conflictsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(TxnConflictsResponse), conflictsRsp)
+			}
+		})
+	}
+}
+
+func TestTransactionConflictEvents(t *testing.T) {
+	txidA := testutil.RandSHA256(t)
+	txidB := testutil.RandSHA256(t)
+	uxID := testutil.RandSHA256(t)
+	addr := testutil.MakeAddress()
+	now := time.Unix(1542443907, 0).UTC()
+
+	events := []daemon.TxnConflictEvent{
+		{
+			ID:   4,
+			Time: now,
+			Conflict: daemon.TxnConflict{
+				UxID:       uxID,
+				Address:    addr,
+				Txids:      []cipher.SHA256{txidA, txidB},
+				DetectedAt: now,
+				Updated:    now,
+			},
+		},
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		since         string
+		wait          string
+		gatewaySince  uint64
+		gatewayWait   time.Duration
+		gatewayResult []daemon.TxnConflictEvent
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid since",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			since:        "-1",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid since value"),
+		},
+		{
+			name:         "400 - invalid wait",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			wait:         "foo",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid wait value"),
+		},
+		{
+			name:         "400 - wait too long",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			wait:         "31",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "wait must be at most 30"),
+		},
+		{
+			name:          "200 - no events",
+			method:        http.MethodGet,
+			status:        http.StatusOK,
+			gatewayResult: []daemon.TxnConflictEvent{},
+			httpResponse: HTTPResponse{
+				Data: TxnConflictEventsResponse{
+					Events: []readable.TxnConflictEvent{},
+				},
+			},
+		},
+		{
+			name:          "200",
+			method:        http.MethodGet,
+			status:        http.StatusOK,
+			since:         "3",
+			wait:          "30",
+			gatewaySince:  3,
+			gatewayWait:   time.Second * 30,
+			gatewayResult: events,
+			httpResponse: HTTPResponse{
+				Data: TxnConflictEventsResponse{
+					Events: []readable.TxnConflictEvent{
+						{
+							ID:   4,
+							Time: now.Unix(),
+							Conflict: readable.TxnConflict{
+								UxID:       uxID.Hex(),
+								Address:    addr.String(),
+								Txids:      []string{txidA.Hex(), txidB.Hex()},
+								DetectedAt: now.Unix(),
+								Updated:    now.Unix(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v2/transaction/conflicts/events"
+			gateway := &MockGatewayer{}
+			gateway.On("GetTxnConflictEvents", tc.gatewaySince, tc.gatewayWait).Return(tc.gatewayResult)
+
+			v := url.Values{}
+			if tc.since != "" {
+				v.Add("since", tc.since)
+			}
+			if tc.wait != "" {
+				v.Add("wait", tc.wait)
+			}
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway, nil)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.NewDecoder(rr.Body).Decode(&rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var eventsRsp TxnConflictEventsResponse
+				err := json.Unmarshal(rsp.Data, &eventsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(TxnConflictEventsResponse), eventsRsp)
+			}
+		})
+	}
+}
@@ -28,11 +28,13 @@ type SpendResult struct {
 // UnconfirmedTxnsResponse contains unconfirmed transaction data
 type UnconfirmedTxnsResponse struct {
 	Transactions []readable.UnconfirmedTransactions `json:"transactions"`
+	Broadcasts   []readable.TxnBroadcast            `json:"broadcasts"`
 }
 
 // UnconfirmedTxnsVerboseResponse contains verbose unconfirmed transaction data
 type UnconfirmedTxnsVerboseResponse struct {
 	Transactions []readable.UnconfirmedTransactionVerbose `json:"transactions"`
+	Broadcasts   []readable.TxnBroadcast                  `json:"broadcasts"`
 }
 
 // BalanceResponse address balance summary struct
@@ -570,6 +572,19 @@ func walletTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
 			}
 		}
 
+		// Rebroadcast status of the transactions created by the wallet.
+		// Includes transactions that were confirmed or dropped from the unconfirmed pool recently.
+		getBroadcasts := func() ([]readable.TxnBroadcast, bool) {
+			broadcasts, err := gateway.GetWalletTxnBroadcasts(wltID)
+			if err != nil {
+				logger.Errorf("get wallet transaction broadcasts failed: %v", err)
+				handleWalletError(err)
+				return nil, false
+			}
+
+			return readable.NewTxnBroadcasts(broadcasts), true
+		}
+
 		if verbose {
 			txns, inputs, err := gateway.GetWalletUnconfirmedTransactionsVerbose(wltID)
 			if err != nil {
@@ -588,8 +603,14 @@ func walletTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
 				vb[i] = *v
 			}
 
+			broadcasts, ok := getBroadcasts()
+			if !ok {
+				return
+			}
+
 			wh.SendJSONOr500(logger, w, UnconfirmedTxnsVerboseResponse{
 				Transactions: vb,
+				Broadcasts:   broadcasts,
 			})
 		} else {
 			txns, err := gateway.GetWalletUnconfirmedTransactions(wltID)
@@ -605,8 +626,14 @@ func walletTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
 				return
 			}
 
+			broadcasts, ok := getBroadcasts()
+			if !ok {
+				return
+			}
+
 			wh.SendJSONOr500(logger, w, UnconfirmedTxnsResponse{
 				Transactions: unconfirmedTxns,
+				Broadcasts:   broadcasts,
 			})
 		}
 	}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"encoding/json"
 
@@ -17,6 +18,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/util/fee"
@@ -1089,6 +1091,16 @@ func TestWalletTransactionsHandler(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	txnBroadcast := daemon.TxnBroadcast{
+		Txid:           uTxn.Transaction.Hash(),
+		Status:         daemon.TxnBroadcastPending,
+		Broadcasts:     2,
+		FirstBroadcast: time.Unix(1000, 0),
+		LastBroadcast:  time.Unix(1060, 0),
+		NextBroadcast:  time.Unix(1180, 0),
+		AcceptedBy:     []string{"127.0.0.1:6000"},
+	}
+
 	tt := []struct {
 		name                                         string
 		method                                       string
@@ -1099,8 +1111,11 @@ func TestWalletTransactionsHandler(t *testing.T) {
 		verbose                                      bool
 		gatewayGetWalletUnconfirmedTxnsResult        []visor.UnconfirmedTransaction
 		gatewayGetWalletUnconfirmedTxnsErr           error
-		gatewayGetWalletUnconfirmedTxnsVerboseResult []readable.UnconfirmedTransactionVerbose
+		gatewayGetWalletUnconfirmedTxnsVerboseResult []visor.UnconfirmedTransaction
+		gatewayGetWalletUnconfirmedTxnsVerboseInputs [][]visor.TransactionInput
 		gatewayGetWalletUnconfirmedTxnsVerboseErr    error
+		gatewayGetWalletTxnBroadcastsResult          []daemon.TxnBroadcast
+		gatewayGetWalletTxnBroadcastsErr             error
 		responseBody                                 interface{}
 	}{
 		{
@@ -1205,6 +1220,19 @@ func TestWalletTransactionsHandler(t *testing.T) {
 			gatewayGetWalletUnconfirmedTxnsVerboseErr: wallet.ErrWalletAPIDisabled,
 		},
 
+		{
+			name:   "500 - gateway.GetWalletTxnBroadcasts error",
+			method: http.MethodGet,
+			body: &httpBody{
+				walletID: "foo",
+			},
+			status:                                http.StatusInternalServerError,
+			err:                                   "500 Internal Server Error - gateway.GetWalletTxnBroadcasts error",
+			walletID:                              "foo",
+			gatewayGetWalletUnconfirmedTxnsResult: []visor.UnconfirmedTransaction{*uTxn},
+			gatewayGetWalletTxnBroadcastsErr:      errors.New("gateway.GetWalletTxnBroadcasts error"),
+		},
+
 		{
 			name:   "200 - OK",
 			method: http.MethodGet,
@@ -1213,11 +1241,15 @@ func TestWalletTransactionsHandler(t *testing.T) {
 			},
 			status:                                http.StatusOK,
 			walletID:                              "foo",
-			gatewayGetWalletUnconfirmedTxnsResult: make([]visor.UnconfirmedTransaction, 1),
+			gatewayGetWalletUnconfirmedTxnsResult: []visor.UnconfirmedTransaction{*uTxn},
+			gatewayGetWalletTxnBroadcastsResult:   []daemon.TxnBroadcast{txnBroadcast},
 			responseBody: UnconfirmedTxnsResponse{
 				Transactions: []readable.UnconfirmedTransactions{
 					*unconfirmedTxn,
 				},
+				Broadcasts: []readable.TxnBroadcast{
+					readable.NewTxnBroadcast(txnBroadcast),
+				},
 			},
 		},
 
@@ -1231,71 +1263,85 @@ func TestWalletTransactionsHandler(t *testing.T) {
 			verbose:  true,
 			status:   http.StatusOK,
 			walletID: "foo",
-			gatewayGetWalletUnconfirmedTxnsVerboseResult: make([]readable.UnconfirmedTransactionVerbose, 1),
+			gatewayGetWalletUnconfirmedTxnsVerboseResult: []visor.UnconfirmedTransaction{*uTxn},
+			gatewayGetWalletUnconfirmedTxnsVerboseInputs: [][]visor.TransactionInput{
+				{
+					visor.TransactionInput{},
+				},
+			},
 			responseBody: UnconfirmedTxnsVerboseResponse{
 				Transactions: []readable.UnconfirmedTransactionVerbose{
 					*unconfirmedTxnVerbose,
 				},
+				Broadcasts: []readable.TxnBroadcast{},
 			},
 		},
 	}
 
 	for _, tc := range tt {
-		gateway := &MockGatewayer{}
-		gateway.On("GetWalletUnconfirmedTransactions", tc.walletID).Return(tc.gatewayGetWalletUnconfirmedTxnsResult, tc.gatewayGetWalletUnconfirmedTxnsErr)
-		gateway.On("GetWalletUnconfirmedTransactionsVerbose", tc.walletID).Return(tc.gatewayGetWalletUnconfirmedTxnsVerboseResult, tc.gatewayGetWalletUnconfirmedTxnsVerboseErr)
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetWalletUnconfirmedTransactions", tc.walletID).Return(tc.gatewayGetWalletUnconfirmedTxnsResult, tc.gatewayGetWalletUnconfirmedTxnsErr)
+			gateway.On("GetWalletUnconfirmedTransactionsVerbose", tc.walletID).Return(tc.gatewayGetWalletUnconfirmedTxnsVerboseResult, tc.gatewayGetWalletUnconfirmedTxnsVerboseInputs, tc.gatewayGetWalletUnconfirmedTxnsVerboseErr)
+			gateway.On("GetWalletTxnBroadcasts", tc.walletID).Return(tc.gatewayGetWalletTxnBroadcastsResult, tc.gatewayGetWalletTxnBroadcastsErr)
 
-		endpoint := "/api/v1/wallet/transactions"
+			endpoint := "/api/v1/wallet/transactions"
 
-		v := url.Values{}
-		if tc.body != nil {
-			if tc.body.walletID != "" {
-				v.Add("id", tc.body.walletID)
+			v := url.Values{}
+			if tc.body != nil {
+				if tc.body.walletID != "" {
+					v.Add("id", tc.body.walletID)
+				}
+				if tc.body.verbose != "" {
+					v.Add("verbose", tc.body.verbose)
+				}
 			}
-		}
-		if len(v) > 0 {
-			endpoint += "?" + v.Encode()
-		}
-		req, err := http.NewRequest(tc.method, endpoint, nil)
-		require.NoError(t, err)
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
 
-		setCSRFParameters(t, tokenValid, req)
+			setCSRFParameters(t, tokenValid, req)
 
-		rr := httptest.NewRecorder()
+			rr := httptest.NewRecorder()
 
-		cfg := defaultMuxConfig()
-		cfg.disableCSRF = false
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
 
-		handler := newServerMux(cfg, gateway, nil)
-		handler.ServeHTTP(rr, req)
+			handler := newServerMux(cfg, gateway, nil)
+			handler.ServeHTTP(rr, req)
 
-		status := rr.Code
-		require.Equal(t, tc.status, status, "got `%v` want `%v`",
-			tc.name, status, tc.status)
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`",
+				tc.name, status, tc.status)
 
-		if status != http.StatusOK {
-			require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "got `%v`| %d, want `%v`",
-				strings.TrimSpace(rr.Body.String()), status, tc.err)
-			return
-		}
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "got `%v`| %d, want `%v`",
+					strings.TrimSpace(rr.Body.String()), status, tc.err)
+				return
+			}
 
-		if tc.verbose {
-			var msg UnconfirmedTxnsVerboseResponse
-			err = json.Unmarshal(rr.Body.Bytes(), &msg)
-			require.NoError(t, err)
-			// require.Equal on whole response might result in flaky tests as there is a time field attached to unconfirmed txn response
-			require.IsType(t, msg, tc.responseBody)
-			require.Len(t, msg.Transactions, 1)
-			require.Equal(t, msg.Transactions[0].Transaction, tc.responseBody.(UnconfirmedTxnsVerboseResponse).Transactions[0].Transaction)
-		} else {
-			var msg UnconfirmedTxnsResponse
-			err = json.Unmarshal(rr.Body.Bytes(), &msg)
-			require.NoError(t, err)
-			// require.Equal on whole response might result in flaky tests as there is a time field attached to unconfirmed txn response
-			require.IsType(t, msg, tc.responseBody)
-			require.Len(t, msg.Transactions, 1)
-			require.Equal(t, msg.Transactions[0].Transaction, tc.responseBody.(UnconfirmedTxnsResponse).Transactions[0].Transaction)
-		}
+			if tc.verbose {
+				var msg UnconfirmedTxnsVerboseResponse
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				// require.Equal on whole response might result in flaky tests as there is a time field attached to unconfirmed txn response
+				require.IsType(t, msg, tc.responseBody)
+				require.Len(t, msg.Transactions, 1)
+				require.Equal(t, msg.Transactions[0].Transaction, tc.responseBody.(UnconfirmedTxnsVerboseResponse).Transactions[0].Transaction)
+				require.Equal(t, tc.responseBody.(UnconfirmedTxnsVerboseResponse).Broadcasts, msg.Broadcasts)
+			} else {
+				var msg UnconfirmedTxnsResponse
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				// require.Equal on whole response might result in flaky tests as there is a time field attached to unconfirmed txn response
+				require.IsType(t, msg, tc.responseBody)
+				require.Len(t, msg.Transactions, 1)
+				require.Equal(t, msg.Transactions[0].Transaction, tc.responseBody.(UnconfirmedTxnsResponse).Transactions[0].Transaction)
+				require.Equal(t, tc.responseBody.(UnconfirmedTxnsResponse).Broadcasts, msg.Broadcasts)
+			}
+		})
 	}
 }
 
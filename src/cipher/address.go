@@ -39,6 +39,14 @@ In base 58 format the address is 20+1+4 bytes
 
 */
 
+// AddressVersion is the version byte of addresses of the active chain.
+// Each fiber coin has its own address version, so that an address of one chain
+// can't be mistaken for an address of another chain.
+// It is set to params.AddressVersion when the params package is initialized.
+// It must not be changed for a chain that already has a genesis block, since outputs of addresses
+// with another version can't be spent. The visor refuses to load a database whose genesis block has another version
+var AddressVersion byte
+
 // Checksum 4 bytes
 type Checksum [4]byte
 
@@ -69,7 +77,7 @@ type Address struct {
 // AddressFromPubKey creates Address from PubKey as ripemd160(sha256(sha256(pubkey)))
 func AddressFromPubKey(pubKey PubKey) Address {
 	return Address{
-		Version: 0,
+		Version: AddressVersion,
 		Key:     PubKeyRipemd160(pubKey),
 	}
 }
@@ -88,7 +96,7 @@ func MustAddressFromSecKey(secKey SecKey) Address {
 	return AddressFromPubKey(MustPubKeyFromSecKey(secKey))
 }
 
-// DecodeBase58Address creates an Address from its base58 encoding
+// DecodeBase58Address creates an Address of the active chain from its base58 encoding
 func DecodeBase58Address(addr string) (Address, error) {
 	b, err := base58.Decode(addr)
 	if err != nil {
@@ -97,6 +105,16 @@ func DecodeBase58Address(addr string) (Address, error) {
 	return AddressFromBytes(b)
 }
 
+// DecodeBase58AddressAnyVersion creates an Address from its base58 encoding,
+// without checking that its version is the version of the active chain
+func DecodeBase58AddressAnyVersion(addr string) (Address, error) {
+	b, err := base58.Decode(addr)
+	if err != nil {
+		return Address{}, err
+	}
+	return addressFromBytes(b)
+}
+
 // MustDecodeBase58Address creates an Address from its base58 encoding, panics on error
 func MustDecodeBase58Address(addr string) Address {
 	a, err := DecodeBase58Address(addr)
@@ -106,8 +124,21 @@ func MustDecodeBase58Address(addr string) Address {
 	return a
 }
 
-// AddressFromBytes converts []byte to an Address
+// AddressFromBytes converts []byte to an Address of the active chain
 func AddressFromBytes(b []byte) (Address, error) {
+	a, err := addressFromBytes(b)
+	if err != nil {
+		return Address{}, err
+	}
+
+	if a.Version != AddressVersion {
+		return Address{}, ErrAddressInvalidVersion
+	}
+
+	return a, nil
+}
+
+func addressFromBytes(b []byte) (Address, error) {
 	if len(b) != 20+1+4 {
 		return Address{}, ErrAddressInvalidLength
 	}
@@ -123,10 +154,6 @@ func AddressFromBytes(b []byte) (Address, error) {
 		return Address{}, ErrAddressInvalidChecksum
 	}
 
-	if a.Version != 0 {
-		return Address{}, ErrAddressInvalidVersion
-	}
-
 	return a, nil
 }
 
@@ -157,7 +184,7 @@ func (addr Address) Bytes() []byte {
 
 // Verify checks that the address appears valid for the public key
 func (addr Address) Verify(pubKey PubKey) error {
-	if addr.Version != 0x00 {
+	if addr.Version != AddressVersion {
 		return ErrAddressInvalidVersion
 	}
 
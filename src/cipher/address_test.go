@@ -173,6 +173,42 @@ func TestAddressVerify(t *testing.T) {
 	require.Error(t, a.Verify(p))
 }
 
+func TestAddressVersion(t *testing.T) {
+	p, _ := GenerateKeyPair()
+	a := AddressFromPubKey(p)
+	require.Equal(t, byte(0), a.Version)
+
+	defer func() {
+		AddressVersion = 0
+	}()
+	AddressVersion = 5
+
+	// Addresses are created with the version of the active chain
+	a2 := AddressFromPubKey(p)
+	require.Equal(t, byte(5), a2.Version)
+	require.Equal(t, a.Key, a2.Key)
+	require.NoError(t, a2.Verify(p))
+	require.Equal(t, ErrAddressInvalidVersion, a.Verify(p))
+
+	a3, err := DecodeBase58Address(a2.String())
+	require.NoError(t, err)
+	require.Equal(t, a2, a3)
+
+	// Addresses of another chain are rejected
+	_, err = DecodeBase58Address(a.String())
+	require.Equal(t, ErrAddressInvalidVersion, err)
+	_, err = AddressFromBytes(a.Bytes())
+	require.Equal(t, ErrAddressInvalidVersion, err)
+
+	// but can be decoded without checking the version
+	a3, err = DecodeBase58AddressAnyVersion(a.String())
+	require.NoError(t, err)
+	require.Equal(t, a, a3)
+
+	_, err = DecodeBase58AddressAnyVersion(a.String() + "1")
+	require.Error(t, err)
+}
+
 func TestAddressString(t *testing.T) {
 	p, _ := GenerateKeyPair()
 	a := AddressFromPubKey(p)
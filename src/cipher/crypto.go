@@ -80,6 +80,8 @@ var (
 	ErrPubKeyFromSecKeyMismatch = errors.New("impossible error TestSecKey, pubkey does not match recovered pubkey")
 	// ErrEmptySeed Seed input is empty
 	ErrEmptySeed = errors.New("Seed input is empty")
+	// ErrInvalidLengthEntropy Invalid extra entropy length
+	ErrInvalidLengthEntropy = errors.New("Invalid extra entropy length")
 )
 
 // PubKey public key
@@ -348,14 +350,25 @@ func (s Sig) Hex() string {
 	return hex.EncodeToString(s[:])
 }
 
-// SignHash sign hash
+// SignHash sign hash. The nonce is derived from the seckey and the hash (RFC6979),
+// so signing the same hash with the same seckey always produces the same signature
 func SignHash(hash SHA256, sec SecKey) (Sig, error) {
+	return SignHashWithEntropy(hash, sec, nil)
+}
+
+// SignHashWithEntropy sign hash, mixing extra entropy into the RFC6979 nonce.
+// entropy is nil or 32 bytes. The signature remains safe if the entropy is weak
+func SignHashWithEntropy(hash SHA256, sec SecKey, entropy []byte) (Sig, error) {
 	if secp256k1.VerifySeckey(sec[:]) != 1 {
 		// can't use sec.Verify() because that calls SignHash again, with DebugLevel2 set
 		return Sig{}, ErrInvalidSecKey
 	}
 
-	s := secp256k1.Sign(hash[:], sec[:])
+	if entropy != nil && len(entropy) != 32 {
+		return Sig{}, ErrInvalidLengthEntropy
+	}
+
+	s := secp256k1.SignWithEntropy(hash[:], sec[:], entropy)
 
 	sig, err := NewSig(s)
 	if err != nil {
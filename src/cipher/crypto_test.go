@@ -461,10 +461,35 @@ func TestSignHash(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, p, p2)
 
+	// Signatures are deterministic
+	sig2, err := SignHash(h, s)
+	require.NoError(t, err)
+	require.Equal(t, sig, sig2)
+
 	_, err = SignHash(h, SecKey{})
 	require.Equal(t, errors.New("Invalid secret key"), err)
 }
 
+func TestSignHashWithEntropy(t *testing.T) {
+	p, s := GenerateKeyPair()
+	h := SumSHA256(randBytes(t, 256))
+
+	sig, err := SignHashWithEntropy(h, s, nil)
+	require.NoError(t, err)
+	require.Equal(t, MustSignHash(h, s), sig)
+
+	sig2, err := SignHashWithEntropy(h, s, randBytes(t, 32))
+	require.NoError(t, err)
+	require.NotEqual(t, sig, sig2)
+	require.NoError(t, VerifyPubKeySignedHash(p, sig2, h))
+
+	_, err = SignHashWithEntropy(h, s, randBytes(t, 31))
+	require.Equal(t, ErrInvalidLengthEntropy, err)
+
+	_, err = SignHashWithEntropy(h, SecKey{}, nil)
+	require.Equal(t, ErrInvalidSecKey, err)
+}
+
 func TestMustSignHash(t *testing.T) {
 	p, s := GenerateKeyPair()
 	a := AddressFromPubKey(p)
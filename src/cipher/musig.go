@@ -0,0 +1,294 @@
+package cipher
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/cipher/secp256k1-go"
+)
+
+var (
+	// ErrMuSigDuplicatePubKey MuSig pubkeys contain a duplicate
+	ErrMuSigDuplicatePubKey = errors.New("MuSig pubkeys contain a duplicate")
+	// ErrMuSigNotSigner Seckey is not of one of the MuSig pubkeys
+	ErrMuSigNotSigner = errors.New("Seckey is not of one of the MuSig pubkeys")
+	// ErrMuSigUnknownSigner Pubkey is not of a signer of the MuSig session
+	ErrMuSigUnknownSigner = errors.New("Pubkey is not of a signer of the MuSig session")
+	// ErrMuSigNonceCommitmentExists Signer already has a different MuSig nonce commitment
+	ErrMuSigNonceCommitmentExists = errors.New("Signer already has a different MuSig nonce commitment")
+	// ErrMuSigMissingNonceCommitments MuSig nonce commitments of all of the signers are required
+	ErrMuSigMissingNonceCommitments = errors.New("MuSig nonce commitments of all of the signers are required")
+	// ErrMuSigNonceCommitmentMismatch MuSig nonce does not match its commitment
+	ErrMuSigNonceCommitmentMismatch = errors.New("MuSig nonce does not match its commitment")
+	// ErrMuSigMissingNonces MuSig nonces of all of the signers are required
+	ErrMuSigMissingNonces = errors.New("MuSig nonces of all of the signers are required")
+	// ErrInvalidMuSigNonce Invalid MuSig nonce
+	ErrInvalidMuSigNonce = errors.New("Invalid MuSig nonce")
+	// ErrMuSigNonceUsed MuSig session has already signed
+	ErrMuSigNonceUsed = errors.New("MuSig session has already signed")
+	// ErrInvalidMuSigPartialSig Invalid MuSig partial signature
+	ErrInvalidMuSigPartialSig = errors.New("Invalid MuSig partial signature")
+	// ErrMuSigMissingPartialSigs MuSig partial signatures of all of the signers are required
+	ErrMuSigMissingPartialSigs = errors.New("MuSig partial signatures of all of the signers are required")
+)
+
+// MuSigNonce public nonce of a signer of a MuSig session
+type MuSigNonce [33]byte
+
+// MuSigPartialSig partial signature of a signer of a MuSig session
+type MuSigPartialSig [32]byte
+
+// MuSigSession is the state of one of the holders of the pubkeys aggregated by AggregatePubKeys,
+// signing a hash together with the other holders into one Schnorr signature for the aggregated pubkey.
+// Each holder has its own session and sends the values it creates to the sessions of the others:
+//  1. the NonceCommitment, added to the other sessions with AddNonceCommitment
+//  2. once all of the commitments are added, the Nonce, added with AddNonce
+//  3. once all of the nonces are added, the PartialSign partial signature, added with AddPartialSig
+//
+// Once all of the partial signatures are added, Sig returns the Schnorr signature.
+// The seckeys are never shared, and a session signs only once, so that its nonce is never reused
+type MuSigSession struct {
+	hash        SHA256
+	pubkeys     []PubKey
+	aggPubKey   PubKey
+	pubkey      PubKey
+	seckey      SecKey
+	secNonce    []byte
+	commitments map[PubKey]SHA256
+	nonces      map[PubKey]MuSigNonce
+	partialSigs map[PubKey]MuSigPartialSig
+}
+
+// NewMuSigSession creates the MuSig session of the holder of seckey, signing hash
+// for the aggregated pubkey of pubkeys
+func NewMuSigSession(hash SHA256, pubkeys []PubKey, seckey SecKey) (*MuSigSession, error) {
+	if err := seckey.Verify(); err != nil {
+		return nil, err
+	}
+
+	pubkey := MustPubKeyFromSecKey(seckey)
+
+	isSigner := false
+	seen := make(map[PubKey]struct{}, len(pubkeys))
+	for _, p := range pubkeys {
+		if _, ok := seen[p]; ok {
+			return nil, ErrMuSigDuplicatePubKey
+		}
+		seen[p] = struct{}{}
+
+		if p == pubkey {
+			isSigner = true
+		}
+	}
+
+	aggPubKey, err := AggregatePubKeys(pubkeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSigner {
+		return nil, ErrMuSigNotSigner
+	}
+
+	secNonce, pubNonce := secp256k1.MuSigNonce(hash[:], seckey[:], aggPubKey[:])
+	if secNonce == nil {
+		return nil, ErrInvalidSecKey
+	}
+
+	var nonce MuSigNonce
+	copy(nonce[:], pubNonce)
+
+	s := &MuSigSession{
+		hash:        hash,
+		pubkeys:     append([]PubKey(nil), pubkeys...),
+		aggPubKey:   aggPubKey,
+		pubkey:      pubkey,
+		seckey:      seckey,
+		secNonce:    secNonce,
+		commitments: make(map[PubKey]SHA256, len(pubkeys)),
+		nonces:      make(map[PubKey]MuSigNonce, len(pubkeys)),
+		partialSigs: make(map[PubKey]MuSigPartialSig, len(pubkeys)),
+	}
+
+	s.commitments[pubkey] = muSigNonceCommitment(nonce)
+	s.nonces[pubkey] = nonce
+
+	return s, nil
+}
+
+func muSigNonceCommitment(nonce MuSigNonce) SHA256 {
+	return MustSHA256FromBytes(secp256k1.MuSigNonceCommitment(nonce[:]))
+}
+
+// AggPubKey returns the aggregated pubkey that the session signs for
+func (s *MuSigSession) AggPubKey() PubKey {
+	return s.aggPubKey
+}
+
+// PubKey returns the pubkey of the signer of the session
+func (s *MuSigSession) PubKey() PubKey {
+	return s.pubkey
+}
+
+// NonceCommitment returns the commitment to the nonce of the signer of the session
+func (s *MuSigSession) NonceCommitment() SHA256 {
+	return s.commitments[s.pubkey]
+}
+
+// AddNonceCommitment adds the nonce commitment of another signer
+func (s *MuSigSession) AddNonceCommitment(pubkey PubKey, commitment SHA256) error {
+	if !s.isSigner(pubkey) {
+		return ErrMuSigUnknownSigner
+	}
+
+	if c, ok := s.commitments[pubkey]; ok && c != commitment {
+		return ErrMuSigNonceCommitmentExists
+	}
+
+	s.commitments[pubkey] = commitment
+	return nil
+}
+
+// Nonce returns the nonce of the signer of the session.
+// It is only revealed once the nonce commitments of all of the signers are added,
+// so that no signer can choose its nonce after seeing the others
+func (s *MuSigSession) Nonce() (MuSigNonce, error) {
+	if len(s.commitments) != len(s.pubkeys) {
+		return MuSigNonce{}, ErrMuSigMissingNonceCommitments
+	}
+
+	return s.nonces[s.pubkey], nil
+}
+
+// AddNonce adds the nonce of another signer, which must match its nonce commitment
+func (s *MuSigSession) AddNonce(pubkey PubKey, nonce MuSigNonce) error {
+	if !s.isSigner(pubkey) {
+		return ErrMuSigUnknownSigner
+	}
+
+	c, ok := s.commitments[pubkey]
+	if !ok || c != muSigNonceCommitment(nonce) {
+		return ErrMuSigNonceCommitmentMismatch
+	}
+
+	if secp256k1.VerifyPubkey(nonce[:]) != 1 {
+		return ErrInvalidMuSigNonce
+	}
+
+	s.nonces[pubkey] = nonce
+	return nil
+}
+
+// PartialSign returns the partial signature of the signer of the session.
+// The nonces of all of the signers must be added first. A session signs only once
+func (s *MuSigSession) PartialSign() (MuSigPartialSig, error) {
+	if s.secNonce == nil {
+		return MuSigPartialSig{}, ErrMuSigNonceUsed
+	}
+
+	aggNonce, err := s.aggNonce()
+	if err != nil {
+		return MuSigPartialSig{}, err
+	}
+
+	ps := secp256k1.MuSigPartialSign(s.hash[:], s.seckey[:], s.secNonce, s.rawPubKeys(), aggNonce)
+
+	// Forget the nonce, so that it can't sign again with other nonces and leak the seckey
+	s.secNonce = nil
+
+	if ps == nil {
+		return MuSigPartialSig{}, ErrInvalidSecKey
+	}
+
+	var sig MuSigPartialSig
+	copy(sig[:], ps)
+	s.partialSigs[s.pubkey] = sig
+
+	return sig, nil
+}
+
+// AddPartialSig verifies and adds the partial signature of another signer
+func (s *MuSigSession) AddPartialSig(pubkey PubKey, sig MuSigPartialSig) error {
+	if !s.isSigner(pubkey) {
+		return ErrMuSigUnknownSigner
+	}
+
+	aggNonce, err := s.aggNonce()
+	if err != nil {
+		return err
+	}
+
+	nonce := s.nonces[pubkey]
+	if secp256k1.MuSigPartialVerify(s.hash[:], pubkey[:], nonce[:], sig[:], s.rawPubKeys(), aggNonce) != 1 {
+		return ErrInvalidMuSigPartialSig
+	}
+
+	s.partialSigs[pubkey] = sig
+	return nil
+}
+
+// Sig combines the partial signatures of all of the signers into a Schnorr signature of the hash
+// for the aggregated pubkey
+func (s *MuSigSession) Sig() (SchnorrSig, error) {
+	if len(s.partialSigs) != len(s.pubkeys) {
+		return SchnorrSig{}, ErrMuSigMissingPartialSigs
+	}
+
+	aggNonce, err := s.aggNonce()
+	if err != nil {
+		return SchnorrSig{}, err
+	}
+
+	partialSigs := make([][]byte, 0, len(s.pubkeys))
+	for _, p := range s.pubkeys {
+		ps := s.partialSigs[p]
+		partialSigs = append(partialSigs, ps[:])
+	}
+
+	sig, err := NewSchnorrSig(secp256k1.MuSigCombine(aggNonce, partialSigs))
+	if err != nil {
+		return SchnorrSig{}, ErrInvalidSchnorrSig
+	}
+
+	if err := VerifyPubKeySchnorrSignedHash(s.aggPubKey, sig, s.hash); err != nil {
+		return SchnorrSig{}, err
+	}
+
+	return sig, nil
+}
+
+func (s *MuSigSession) isSigner(pubkey PubKey) bool {
+	for _, p := range s.pubkeys {
+		if p == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MuSigSession) rawPubKeys() [][]byte {
+	raw := make([][]byte, len(s.pubkeys))
+	for i := range s.pubkeys {
+		raw[i] = s.pubkeys[i][:]
+	}
+	return raw
+}
+
+// aggNonce aggregates the nonces of all of the signers
+func (s *MuSigSession) aggNonce() ([]byte, error) {
+	if len(s.nonces) != len(s.pubkeys) {
+		return nil, ErrMuSigMissingNonces
+	}
+
+	nonces := make([][]byte, 0, len(s.pubkeys))
+	for _, p := range s.pubkeys {
+		n := s.nonces[p]
+		nonces = append(nonces, n[:])
+	}
+
+	aggNonce := secp256k1.MuSigAggregateNonces(nonces)
+	if aggNonce == nil {
+		return nil, ErrInvalidMuSigNonce
+	}
+
+	return aggNonce, nil
+}
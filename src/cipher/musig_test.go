@@ -0,0 +1,145 @@
+package cipher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newMuSigSessions(t *testing.T, hash SHA256, n int) []*MuSigSession {
+	var pubkeys []PubKey
+	var seckeys []SecKey
+	for i := 0; i < n; i++ {
+		p, s := GenerateKeyPair()
+		pubkeys = append(pubkeys, p)
+		seckeys = append(seckeys, s)
+	}
+
+	sessions := make([]*MuSigSession, n)
+	for i, s := range seckeys {
+		var err error
+		sessions[i], err = NewMuSigSession(hash, pubkeys, s)
+		require.NoError(t, err)
+	}
+	return sessions
+}
+
+func TestMuSigSession(t *testing.T) {
+	h := SumSHA256(randBytes(t, 32))
+	sessions := newMuSigSessions(t, h, 3)
+
+	aggPubKey := sessions[0].AggPubKey()
+	for _, s := range sessions {
+		require.Equal(t, aggPubKey, s.AggPubKey())
+	}
+
+	// Nonces are not revealed before all of the commitments are added
+	_, err := sessions[0].Nonce()
+	require.Equal(t, ErrMuSigMissingNonceCommitments, err)
+
+	for _, s := range sessions {
+		for _, o := range sessions {
+			if s != o {
+				require.NoError(t, s.AddNonceCommitment(o.PubKey(), o.NonceCommitment()))
+			}
+		}
+	}
+
+	// Partial signatures require all of the nonces
+	_, err = sessions[0].PartialSign()
+	require.Equal(t, ErrMuSigMissingNonces, err)
+
+	nonces := make([]MuSigNonce, len(sessions))
+	for i, s := range sessions {
+		nonces[i], err = s.Nonce()
+		require.NoError(t, err)
+	}
+
+	// A nonce must match its commitment
+	require.Equal(t, ErrMuSigNonceCommitmentMismatch, sessions[0].AddNonce(sessions[1].PubKey(), nonces[2]))
+	// A commitment can't be replaced once added
+	require.Equal(t, ErrMuSigNonceCommitmentExists, sessions[0].AddNonceCommitment(sessions[1].PubKey(), sessions[2].NonceCommitment()))
+
+	for _, s := range sessions {
+		for j, o := range sessions {
+			if s != o {
+				require.NoError(t, s.AddNonce(o.PubKey(), nonces[j]))
+			}
+		}
+	}
+
+	partialSigs := make([]MuSigPartialSig, len(sessions))
+	for i, s := range sessions {
+		partialSigs[i], err = s.PartialSign()
+		require.NoError(t, err)
+	}
+
+	// A session signs only once
+	_, err = sessions[0].PartialSign()
+	require.Equal(t, ErrMuSigNonceUsed, err)
+
+	_, err = sessions[0].Sig()
+	require.Equal(t, ErrMuSigMissingPartialSigs, err)
+
+	// A partial signature must be of its signer
+	require.Equal(t, ErrInvalidMuSigPartialSig, sessions[0].AddPartialSig(sessions[1].PubKey(), partialSigs[2]))
+
+	for _, s := range sessions {
+		for j, o := range sessions {
+			if s != o {
+				require.NoError(t, s.AddPartialSig(o.PubKey(), partialSigs[j]))
+			}
+		}
+	}
+
+	sig, err := sessions[0].Sig()
+	require.NoError(t, err)
+	require.NoError(t, VerifyPubKeySchnorrSignedHash(aggPubKey, sig, h))
+
+	// All of the signers combine the same signature
+	for _, s := range sessions[1:] {
+		sig2, err := s.Sig()
+		require.NoError(t, err)
+		require.Equal(t, sig, sig2)
+	}
+
+	// Unknown signers are refused
+	other, _ := GenerateKeyPair()
+	require.Equal(t, ErrMuSigUnknownSigner, sessions[0].AddNonceCommitment(other, SHA256{}))
+	require.Equal(t, ErrMuSigUnknownSigner, sessions[0].AddNonce(other, MuSigNonce{}))
+	require.Equal(t, ErrMuSigUnknownSigner, sessions[0].AddPartialSig(other, MuSigPartialSig{}))
+}
+
+func TestNewMuSigSession(t *testing.T) {
+	h := SumSHA256(randBytes(t, 32))
+	p, s := GenerateKeyPair()
+	p2, s2 := GenerateKeyPair()
+
+	_, err := NewMuSigSession(h, []PubKey{p, p2}, SecKey{})
+	require.Equal(t, ErrInvalidSecKey, err)
+
+	_, err = NewMuSigSession(h, nil, s)
+	require.Equal(t, ErrNoKeysToAggregate, err)
+
+	_, err = NewMuSigSession(h, []PubKey{p, p2, p}, s)
+	require.Equal(t, ErrMuSigDuplicatePubKey, err)
+
+	_, err = NewMuSigSession(h, []PubKey{p, {}}, s)
+	require.Equal(t, ErrInvalidPubKey, err)
+
+	_, err = NewMuSigSession(h, []PubKey{p2}, s)
+	require.Equal(t, ErrMuSigNotSigner, err)
+
+	session, err := NewMuSigSession(h, []PubKey{p2, p}, s)
+	require.NoError(t, err)
+	require.Equal(t, p, session.PubKey())
+
+	session2, err := NewMuSigSession(h, []PubKey{p, p2}, s2)
+	require.NoError(t, err)
+	require.Equal(t, session.AggPubKey(), session2.AggPubKey())
+
+	// Nonces are random, so that they are never reused
+	session3, err := NewMuSigSession(h, []PubKey{p2, p}, s)
+	require.NoError(t, err)
+	require.NotEqual(t, session.NonceCommitment(), session3.NonceCommitment())
+}
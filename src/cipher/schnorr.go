@@ -0,0 +1,169 @@
+package cipher
+
+import (
+	"encoding/hex"
+	"errors"
+	"log"
+
+	"github.com/skycoin/skycoin/src/cipher/secp256k1-go"
+)
+
+var (
+	// ErrInvalidLengthSchnorrSig Invalid Schnorr signature length
+	ErrInvalidLengthSchnorrSig = errors.New("Invalid Schnorr signature length")
+	// ErrInvalidSchnorrSig Invalid Schnorr signature
+	ErrInvalidSchnorrSig = errors.New("Invalid Schnorr signature")
+	// ErrInvalidSchnorrSigForMessage Invalid Schnorr signature for this message
+	ErrInvalidSchnorrSigForMessage = errors.New("Invalid Schnorr signature for this message")
+	// ErrSchnorrBatchLengthMismatch Schnorr batch pubkeys, sigs and hashes lengths differ
+	ErrSchnorrBatchLengthMismatch = errors.New("Schnorr batch pubkeys, sigs and hashes lengths differ")
+	// ErrNoKeysToAggregate No keys to aggregate
+	ErrNoKeysToAggregate = errors.New("No keys to aggregate")
+	// ErrInvalidAggregatedKey Aggregated key is invalid
+	ErrInvalidAggregatedKey = errors.New("Aggregated key is invalid")
+)
+
+// SchnorrSig BIP340 Schnorr signature
+type SchnorrSig [64]byte
+
+// NewSchnorrSig converts []byte to a SchnorrSig
+func NewSchnorrSig(b []byte) (SchnorrSig, error) {
+	s := SchnorrSig{}
+	if len(b) != len(s) {
+		return SchnorrSig{}, ErrInvalidLengthSchnorrSig
+	}
+	copy(s[:], b[:])
+	return s, nil
+}
+
+// MustNewSchnorrSig converts []byte to a SchnorrSig. Panics is []byte is not the exact size
+func MustNewSchnorrSig(b []byte) SchnorrSig {
+	s, err := NewSchnorrSig(b)
+	if err != nil {
+		log.Panic(err)
+	}
+	return s
+}
+
+// SchnorrSigFromHex converts a hex string to a Schnorr signature
+func SchnorrSigFromHex(s string) (SchnorrSig, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return SchnorrSig{}, ErrInvalidSchnorrSig
+	}
+	return NewSchnorrSig(b)
+}
+
+// Hex converts a Schnorr signature to hex string
+func (s SchnorrSig) Hex() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SignHashSchnorr creates a BIP340 Schnorr signature of hash.
+// The nonce is derived from the seckey and the hash, so the signature is deterministic
+func SignHashSchnorr(hash SHA256, sec SecKey) (SchnorrSig, error) {
+	return SignHashSchnorrWithEntropy(hash, sec, nil)
+}
+
+// SignHashSchnorrWithEntropy creates a BIP340 Schnorr signature of hash,
+// with entropy as the auxiliary random data. entropy is nil or 32 bytes
+func SignHashSchnorrWithEntropy(hash SHA256, sec SecKey, entropy []byte) (SchnorrSig, error) {
+	if secp256k1.VerifySeckey(sec[:]) != 1 {
+		return SchnorrSig{}, ErrInvalidSecKey
+	}
+
+	if entropy != nil && len(entropy) != 32 {
+		return SchnorrSig{}, ErrInvalidLengthEntropy
+	}
+
+	sig, err := NewSchnorrSig(secp256k1.SchnorrSignWithEntropy(hash[:], sec[:], entropy))
+	if err != nil {
+		return SchnorrSig{}, err
+	}
+
+	if DebugLevel2 || DebugLevel1 {
+		// Guard against coin loss
+		pubkey, err := PubKeyFromSecKey(sec)
+		if err != nil {
+			log.Panic("SignHashSchnorr error: pubkey from seckey failure")
+		}
+		if VerifyPubKeySchnorrSignedHash(pubkey, sig, hash) != nil {
+			log.Panic("SignHashSchnorr error: secp256k1.SchnorrSign returned invalid signature")
+		}
+	}
+
+	return sig, nil
+}
+
+// MustSignHashSchnorr creates a BIP340 Schnorr signature of hash, panics on error
+func MustSignHashSchnorr(hash SHA256, sec SecKey) SchnorrSig {
+	sig, err := SignHashSchnorr(hash, sec)
+	if err != nil {
+		log.Panic(err)
+	}
+	return sig
+}
+
+// VerifyPubKeySchnorrSignedHash verifies that hash was signed by PubKey with a Schnorr signature
+func VerifyPubKeySchnorrSignedHash(pubkey PubKey, sig SchnorrSig, hash SHA256) error {
+	if secp256k1.VerifyPubkey(pubkey[:]) != 1 {
+		return ErrInvalidPubKey
+	}
+	if secp256k1.SchnorrVerify(hash[:], sig[:], pubkey[:]) != 1 {
+		return ErrInvalidSchnorrSigForMessage
+	}
+	return nil
+}
+
+// VerifySchnorrSignedHashes verifies that each hash was signed by the PubKey at the same index,
+// with batch verification. It is faster than verifying each signature separately,
+// but does not report which signature is invalid
+func VerifySchnorrSignedHashes(pubkeys []PubKey, sigs []SchnorrSig, hashes []SHA256) error {
+	if len(pubkeys) != len(sigs) || len(pubkeys) != len(hashes) {
+		return ErrSchnorrBatchLengthMismatch
+	}
+
+	msgs := make([][]byte, len(hashes))
+	rawSigs := make([][]byte, len(sigs))
+	rawPubKeys := make([][]byte, len(pubkeys))
+	for i := range pubkeys {
+		if secp256k1.VerifyPubkey(pubkeys[i][:]) != 1 {
+			return ErrInvalidPubKey
+		}
+
+		msgs[i] = hashes[i][:]
+		rawSigs[i] = sigs[i][:]
+		rawPubKeys[i] = pubkeys[i][:]
+	}
+
+	if secp256k1.SchnorrBatchVerify(msgs, rawSigs, rawPubKeys) != 1 {
+		return ErrInvalidSchnorrSigForMessage
+	}
+
+	return nil
+}
+
+// AggregatePubKeys aggregates pubkeys into a single pubkey, so that inputs owned by the
+// address of the aggregated pubkey require the cooperation of all of the key holders to spend.
+// The key holders sign for the aggregated pubkey with a MuSigSession.
+// The result does not depend on the order of the pubkeys
+func AggregatePubKeys(pubkeys []PubKey) (PubKey, error) {
+	if len(pubkeys) == 0 {
+		return PubKey{}, ErrNoKeysToAggregate
+	}
+
+	raw := make([][]byte, len(pubkeys))
+	for i := range pubkeys {
+		if secp256k1.VerifyPubkey(pubkeys[i][:]) != 1 {
+			return PubKey{}, ErrInvalidPubKey
+		}
+		raw[i] = pubkeys[i][:]
+	}
+
+	agg := secp256k1.AggregatePubkeys(raw)
+	if agg == nil {
+		return PubKey{}, ErrInvalidAggregatedKey
+	}
+
+	return NewPubKey(agg)
+}
@@ -0,0 +1,113 @@
+package cipher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchnorrSig(t *testing.T) {
+	_, err := NewSchnorrSig(randBytes(t, 63))
+	require.Equal(t, ErrInvalidLengthSchnorrSig, err)
+	_, err = NewSchnorrSig(randBytes(t, 65))
+	require.Equal(t, ErrInvalidLengthSchnorrSig, err)
+
+	b := randBytes(t, 64)
+	s, err := NewSchnorrSig(b)
+	require.NoError(t, err)
+	require.Equal(t, b, s[:])
+
+	s2, err := SchnorrSigFromHex(s.Hex())
+	require.NoError(t, err)
+	require.Equal(t, s, s2)
+
+	_, err = SchnorrSigFromHex("xx")
+	require.Equal(t, ErrInvalidSchnorrSig, err)
+
+	require.Panics(t, func() {
+		MustNewSchnorrSig(randBytes(t, 63))
+	})
+}
+
+func TestSignHashSchnorr(t *testing.T) {
+	p, s := GenerateKeyPair()
+	h := SumSHA256(randBytes(t, 256))
+
+	sig, err := SignHashSchnorr(h, s)
+	require.NoError(t, err)
+	require.NoError(t, VerifyPubKeySchnorrSignedHash(p, sig, h))
+
+	// Signatures are deterministic
+	sig2 := MustSignHashSchnorr(h, s)
+	require.Equal(t, sig, sig2)
+
+	// Extra entropy is used as the BIP340 auxiliary data
+	sig3, err := SignHashSchnorrWithEntropy(h, s, randBytes(t, 32))
+	require.NoError(t, err)
+	require.NotEqual(t, sig, sig3)
+	require.NoError(t, VerifyPubKeySchnorrSignedHash(p, sig3, h))
+
+	_, err = SignHashSchnorrWithEntropy(h, s, randBytes(t, 31))
+	require.Equal(t, ErrInvalidLengthEntropy, err)
+
+	_, err = SignHashSchnorr(h, SecKey{})
+	require.Equal(t, ErrInvalidSecKey, err)
+
+	h2 := SumSHA256(randBytes(t, 256))
+	require.Equal(t, ErrInvalidSchnorrSigForMessage, VerifyPubKeySchnorrSignedHash(p, sig, h2))
+
+	p2, _ := GenerateKeyPair()
+	require.Equal(t, ErrInvalidSchnorrSigForMessage, VerifyPubKeySchnorrSignedHash(p2, sig, h))
+
+	require.Equal(t, ErrInvalidPubKey, VerifyPubKeySchnorrSignedHash(PubKey{}, sig, h))
+}
+
+func TestVerifySchnorrSignedHashes(t *testing.T) {
+	var pubkeys []PubKey
+	var sigs []SchnorrSig
+	var hashes []SHA256
+	for i := 0; i < 8; i++ {
+		p, s := GenerateKeyPair()
+		h := SumSHA256(randBytes(t, 32))
+		pubkeys = append(pubkeys, p)
+		sigs = append(sigs, MustSignHashSchnorr(h, s))
+		hashes = append(hashes, h)
+	}
+
+	require.NoError(t, VerifySchnorrSignedHashes(nil, nil, nil))
+	require.NoError(t, VerifySchnorrSignedHashes(pubkeys, sigs, hashes))
+	require.Equal(t, ErrSchnorrBatchLengthMismatch, VerifySchnorrSignedHashes(pubkeys[1:], sigs, hashes))
+
+	badHashes := append([]SHA256{}, hashes...)
+	badHashes[5] = SumSHA256(randBytes(t, 32))
+	require.Equal(t, ErrInvalidSchnorrSigForMessage, VerifySchnorrSignedHashes(pubkeys, sigs, badHashes))
+
+	badPubKeys := append([]PubKey{}, pubkeys...)
+	badPubKeys[2] = PubKey{}
+	require.Equal(t, ErrInvalidPubKey, VerifySchnorrSignedHashes(badPubKeys, sigs, hashes))
+}
+
+func TestAggregateKeys(t *testing.T) {
+	var pubkeys []PubKey
+	for i := 0; i < 3; i++ {
+		p, _ := GenerateKeyPair()
+		pubkeys = append(pubkeys, p)
+	}
+
+	aggPub, err := AggregatePubKeys(pubkeys)
+	require.NoError(t, err)
+
+	aggPub2, err := AggregatePubKeys([]PubKey{pubkeys[2], pubkeys[0], pubkeys[1]})
+	require.NoError(t, err)
+	require.Equal(t, aggPub, aggPub2)
+
+	// A single key is not its own aggregate, since it is multiplied by its coefficient
+	single, err := AggregatePubKeys(pubkeys[:1])
+	require.NoError(t, err)
+	require.NotEqual(t, pubkeys[0], single)
+
+	_, err = AggregatePubKeys(nil)
+	require.Equal(t, ErrNoKeysToAggregate, err)
+	_, err = AggregatePubKeys([]PubKey{pubkeys[0], {}})
+	require.Equal(t, ErrInvalidPubKey, err)
+}
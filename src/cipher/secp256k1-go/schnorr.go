@@ -0,0 +1,216 @@
+package secp256k1
+
+import (
+	"log"
+
+	secp "github.com/skycoin/skycoin/src/cipher/secp256k1-go/secp256k1-go2"
+)
+
+// SchnorrSign creates a 64 byte BIP340 Schnorr signature of a 32 byte message.
+// The nonce is derived from the seckey and the message, so the signature is deterministic
+func SchnorrSign(msg []byte, seckey []byte) []byte {
+	return SchnorrSignWithEntropy(msg, seckey, nil)
+}
+
+// SchnorrSignWithEntropy creates a 64 byte BIP340 Schnorr signature of a 32 byte message.
+// extraEntropy is nil or 32 bytes, and is used as the BIP340 auxiliary random data
+func SchnorrSignWithEntropy(msg []byte, seckey []byte, extraEntropy []byte) []byte {
+	if len(seckey) != 32 {
+		log.Panic("SchnorrSign, Invalid seckey length")
+	}
+	if secp.SeckeyIsValid(seckey) != 1 {
+		log.Panic("Attempting to sign with invalid seckey")
+	}
+	if len(msg) != 32 {
+		log.Panic("SchnorrSign, Invalid message length")
+	}
+	if extraEntropy == nil {
+		extraEntropy = make([]byte, 32)
+	}
+	if len(extraEntropy) != 32 {
+		log.Panic("SchnorrSign, Invalid extra entropy length")
+	}
+
+	sig := secp.SchnorrSign(msg, seckey, extraEntropy)
+	if sig == nil {
+		log.Panic("Secp256k1-go, SchnorrSign, signature operation failed")
+	}
+
+	return sig
+}
+
+// SchnorrVerify verifies a 64 byte BIP340 Schnorr signature of a 32 byte message,
+// for a 33 byte compressed pubkey. Only the x coordinate of the pubkey is committed to by the signature.
+// Returns 1 if the signature is valid
+func SchnorrVerify(msg []byte, sig []byte, pubkey []byte) int {
+	if len(msg) != 32 {
+		log.Panic("SchnorrVerify, invalid message length")
+	}
+	if len(sig) != 64 {
+		log.Panic("SchnorrVerify, invalid signature length")
+	}
+	if len(pubkey) != 33 {
+		log.Panic("SchnorrVerify, invalid pubkey length")
+	}
+
+	if secp.PubkeyIsValid(pubkey) != 1 {
+		return 0
+	}
+
+	if !secp.SchnorrVerify(msg, pubkey[1:], sig) {
+		return 0
+	}
+
+	return 1
+}
+
+// SchnorrBatchVerify verifies 64 byte BIP340 Schnorr signatures of 32 byte messages,
+// for 33 byte compressed pubkeys, at once.
+// Returns 1 if all of the signatures are valid
+func SchnorrBatchVerify(msgs [][]byte, sigs [][]byte, pubkeys [][]byte) int {
+	if len(msgs) != len(sigs) || len(msgs) != len(pubkeys) {
+		log.Panic("SchnorrBatchVerify, msgs, sigs and pubkeys lengths differ")
+	}
+
+	xonly := make([][]byte, len(pubkeys))
+	for i := range msgs {
+		if len(msgs[i]) != 32 {
+			log.Panic("SchnorrBatchVerify, invalid message length")
+		}
+		if len(sigs[i]) != 64 {
+			log.Panic("SchnorrBatchVerify, invalid signature length")
+		}
+		if len(pubkeys[i]) != 33 {
+			log.Panic("SchnorrBatchVerify, invalid pubkey length")
+		}
+
+		if secp.PubkeyIsValid(pubkeys[i]) != 1 {
+			return 0
+		}
+
+		xonly[i] = pubkeys[i][1:]
+	}
+
+	if !secp.SchnorrBatchVerify(msgs, xonly, sigs) {
+		return 0
+	}
+
+	return 1
+}
+
+// AggregatePubkeys aggregates 33 byte compressed pubkeys into a single 33 byte compressed pubkey,
+// which is signed for by the holders of the seckeys with the MuSig functions.
+// Returns nil if a pubkey is invalid
+func AggregatePubkeys(pubkeys [][]byte) []byte {
+	for _, pk := range pubkeys {
+		if len(pk) != 33 {
+			log.Panic("AggregatePubkeys, invalid pubkey length")
+		}
+	}
+
+	return secp.AggregatePubkeys(pubkeys)
+}
+
+// MuSigNonce creates a 32 byte secret nonce and its 33 byte compressed public nonce, for signing a 32 byte message
+// with a 32 byte seckey for a 33 byte compressed aggregated pubkey.
+// The nonce is derived from 32 bytes of random data, so that it is never reused.
+// Returns nil if the seckey is invalid
+func MuSigNonce(msg, seckey, aggPubkey []byte) ([]byte, []byte) {
+	if len(msg) != 32 {
+		log.Panic("MuSigNonce, invalid message length")
+	}
+	if len(seckey) != 32 {
+		log.Panic("MuSigNonce, invalid seckey length")
+	}
+	if len(aggPubkey) != 33 {
+		log.Panic("MuSigNonce, invalid pubkey length")
+	}
+
+	return secp.MuSigNonce(msg, seckey, aggPubkey, RandByte(32))
+}
+
+// MuSigNonceCommitment returns the 32 byte commitment to a 33 byte compressed public nonce
+func MuSigNonceCommitment(pubnonce []byte) []byte {
+	if len(pubnonce) != 33 {
+		log.Panic("MuSigNonceCommitment, invalid nonce length")
+	}
+
+	return secp.MuSigNonceCommitment(pubnonce)
+}
+
+// MuSigAggregateNonces aggregates 33 byte compressed public nonces into a 33 byte compressed nonce.
+// Returns nil if a nonce is invalid
+func MuSigAggregateNonces(pubnonces [][]byte) []byte {
+	for _, n := range pubnonces {
+		if len(n) != 33 {
+			log.Panic("MuSigAggregateNonces, invalid nonce length")
+		}
+	}
+
+	return secp.MuSigAggregateNonces(pubnonces)
+}
+
+// MuSigPartialSign creates a 32 byte partial signature of a 32 byte message, with a 32 byte seckey and secret nonce,
+// for the 33 byte compressed pubkeys of all of the signers and their 33 byte compressed aggregated nonce.
+// Returns nil if the seckey or the nonce is invalid, or if the seckey is not of one of the pubkeys
+func MuSigPartialSign(msg, seckey, secnonce []byte, pubkeys [][]byte, aggNonce []byte) []byte {
+	if len(msg) != 32 {
+		log.Panic("MuSigPartialSign, invalid message length")
+	}
+	if len(seckey) != 32 || len(secnonce) != 32 {
+		log.Panic("MuSigPartialSign, invalid seckey or nonce length")
+	}
+	if len(aggNonce) != 33 {
+		log.Panic("MuSigPartialSign, invalid aggregated nonce length")
+	}
+	for _, pk := range pubkeys {
+		if len(pk) != 33 {
+			log.Panic("MuSigPartialSign, invalid pubkey length")
+		}
+	}
+
+	return secp.MuSigPartialSign(msg, seckey, secnonce, pubkeys, aggNonce)
+}
+
+// MuSigPartialVerify verifies the 32 byte partial signature of a 32 byte message by the signer with
+// a 33 byte compressed pubkey and public nonce, for the 33 byte compressed pubkeys of all of the signers
+// and their 33 byte compressed aggregated nonce.
+// Returns 1 if the partial signature is valid
+func MuSigPartialVerify(msg, pubkey, pubnonce, partialSig []byte, pubkeys [][]byte, aggNonce []byte) int {
+	if len(msg) != 32 {
+		log.Panic("MuSigPartialVerify, invalid message length")
+	}
+	if len(pubkey) != 33 || len(pubnonce) != 33 || len(aggNonce) != 33 {
+		log.Panic("MuSigPartialVerify, invalid pubkey or nonce length")
+	}
+	if len(partialSig) != 32 {
+		log.Panic("MuSigPartialVerify, invalid partial signature length")
+	}
+	for _, pk := range pubkeys {
+		if len(pk) != 33 {
+			log.Panic("MuSigPartialVerify, invalid pubkey length")
+		}
+	}
+
+	if !secp.MuSigPartialVerify(msg, pubkey, pubnonce, partialSig, pubkeys, aggNonce) {
+		return 0
+	}
+
+	return 1
+}
+
+// MuSigCombine combines the 32 byte partial signatures of all of the signers into a 64 byte
+// BIP340 Schnorr signature, for their 33 byte compressed aggregated nonce.
+// Returns nil if the nonce or a partial signature is invalid
+func MuSigCombine(aggNonce []byte, partialSigs [][]byte) []byte {
+	if len(aggNonce) != 33 {
+		log.Panic("MuSigCombine, invalid aggregated nonce length")
+	}
+	for _, ps := range partialSigs {
+		if len(ps) != 32 {
+			log.Panic("MuSigCombine, invalid partial signature length")
+		}
+	}
+
+	return secp.MuSigCombine(aggNonce, partialSigs)
+}
@@ -693,3 +693,179 @@ func Test_Abnormal_Keys3(t *testing.T) {
 	}
 
 }
+
+func Test_Schnorr(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		pubkey, seckey := GenerateKeyPair()
+		msg := RandByte(32)
+
+		sig := SchnorrSign(msg, seckey)
+		if len(sig) != 64 {
+			t.Fatal("invalid signature length")
+		}
+
+		if SchnorrVerify(msg, sig, pubkey) != 1 {
+			t.Fatal("valid signature failed to verify")
+		}
+
+		if SchnorrVerify(RandByte(32), sig, pubkey) != 0 {
+			t.Fatal("signature verified for a different message")
+		}
+
+		pubkey2, _ := GenerateKeyPair()
+		if SchnorrVerify(msg, sig, pubkey2) != 0 {
+			t.Fatal("signature verified for a different pubkey")
+		}
+
+		if SchnorrVerify(msg, sig, make([]byte, 33)) != 0 {
+			t.Fatal("signature verified for an invalid pubkey")
+		}
+	}
+}
+
+func Test_SchnorrBatchVerify(t *testing.T) {
+	var msgs, sigs, pubkeys [][]byte
+	for i := 0; i < 16; i++ {
+		pubkey, seckey := GenerateKeyPair()
+		msg := RandByte(32)
+		msgs = append(msgs, msg)
+		sigs = append(sigs, SchnorrSign(msg, seckey))
+		pubkeys = append(pubkeys, pubkey)
+	}
+
+	if SchnorrBatchVerify(msgs, sigs, pubkeys) != 1 {
+		t.Fatal("valid batch failed to verify")
+	}
+
+	pubkeys[3], pubkeys[4] = pubkeys[4], pubkeys[3]
+	if SchnorrBatchVerify(msgs, sigs, pubkeys) != 0 {
+		t.Fatal("batch with swapped pubkeys verified")
+	}
+}
+
+func Test_AggregateKeys(t *testing.T) {
+	var pubkeys, seckeys [][]byte
+	for i := 0; i < 5; i++ {
+		pubkey, seckey := GenerateKeyPair()
+		pubkeys = append(pubkeys, pubkey)
+		seckeys = append(seckeys, seckey)
+	}
+
+	aggPub := AggregatePubkeys(pubkeys)
+	if VerifyPubkey(aggPub) != 1 {
+		t.Fatal("invalid aggregated pubkey")
+	}
+
+	msg := RandByte(32)
+
+	var secnonces, pubnonces, commitments [][]byte
+	for _, sk := range seckeys {
+		secnonce, pubnonce := MuSigNonce(msg, sk, aggPub)
+		secnonces = append(secnonces, secnonce)
+		pubnonces = append(pubnonces, pubnonce)
+		commitments = append(commitments, MuSigNonceCommitment(pubnonce))
+	}
+
+	for i := range pubnonces {
+		if !bytes.Equal(commitments[i], MuSigNonceCommitment(pubnonces[i])) {
+			t.Fatal("nonce does not match its commitment")
+		}
+	}
+
+	aggNonce := MuSigAggregateNonces(pubnonces)
+	if aggNonce == nil {
+		t.Fatal("failed to aggregate nonces")
+	}
+
+	var partialSigs [][]byte
+	for i, sk := range seckeys {
+		ps := MuSigPartialSign(msg, sk, secnonces[i], pubkeys, aggNonce)
+		if MuSigPartialVerify(msg, pubkeys[i], pubnonces[i], ps, pubkeys, aggNonce) != 1 {
+			t.Fatal("partial signature failed to verify")
+		}
+		if MuSigPartialVerify(msg, pubkeys[(i+1)%len(pubkeys)], pubnonces[i], ps, pubkeys, aggNonce) != 0 {
+			t.Fatal("partial signature verified for another pubkey")
+		}
+		partialSigs = append(partialSigs, ps)
+	}
+
+	sig := MuSigCombine(aggNonce, partialSigs)
+	if SchnorrVerify(msg, sig, aggPub) != 1 {
+		t.Fatal("combined signature failed to verify")
+	}
+
+	if SchnorrVerify(msg, MuSigCombine(aggNonce, partialSigs[1:]), aggPub) != 0 {
+		t.Fatal("signature without all of the partial signatures verified")
+	}
+}
+
+func Test_Sign_RFC6979(t *testing.T) {
+	// secp256k1 RFC6979 HMAC-SHA256 test vectors, with SHA256 of the message as the hash
+	cases := []struct {
+		seckey string
+		msg    string
+		sig    string
+	}{
+		{
+			seckey: "0000000000000000000000000000000000000000000000000000000000000001",
+			msg:    "Satoshi Nakamoto",
+			sig:    "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d82442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e501",
+		},
+		{
+			seckey: "0000000000000000000000000000000000000000000000000000000000000001",
+			msg:    "All those moments will be lost in time, like tears in rain. Time to die...",
+			sig:    "8600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc2100",
+		},
+		{
+			seckey: "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+			msg:    "Satoshi Nakamoto",
+			sig:    "fd567d121db66e382991534ada77a6bd3106f0a1098c231e47993447cd6af2d06b39cd0eb1bc8603e159ef5c20a5c8ad685a45b06ce9bebed3f153d10d93bed500",
+		},
+		{
+			seckey: "f8b8af8ce3c7cca5e300d33939540c10d45ce001b8f252bfbc57ba0342904181",
+			msg:    "Alan Turing",
+			sig:    "7063ae83e7f62bbb171798131b4a0564b956930092b33b07b395615d9ec7e15c58dfcc1e00a35e1572f366ffe34ba0fc47db1e7189759b9fb233c5b05ab388ea00",
+		},
+		{
+			seckey: "e91671c46231f833a6406ccbea0e3e392c76c167bac1cb013f6f1013980455c2",
+			msg:    "There is a computer disease that anybody who works with computers knows about. It's a very serious disease and it interferes completely with the work. The trouble with computers is that you 'play' with them!",
+			sig:    "b552edd27580141f3b2a5463048cb7cd3e047b97c9f98076c32dbdf85a68718b279fa72dd19bfae05577e06c7c0c1900c371fcd5893f7e1d56a37d30174671f601",
+		},
+	}
+
+	for _, tc := range cases {
+		seckey, err := hex.DecodeString(tc.seckey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := SumSHA256([]byte(tc.msg))
+
+		sig := Sign(msg, seckey)
+		if hex.EncodeToString(sig) != tc.sig {
+			t.Fatalf("signature mismatch for %q: %x", tc.msg, sig)
+		}
+
+		if VerifySignature(msg, sig, PubkeyFromSeckey(seckey)) != 1 {
+			t.Fatal("signature failed to verify")
+		}
+	}
+}
+
+func Test_SignWithEntropy(t *testing.T) {
+	pubkey, seckey := GenerateKeyPair()
+	msg := RandByte(32)
+
+	sig := Sign(msg, seckey)
+	if !bytes.Equal(sig, SignWithEntropy(msg, seckey, nil)) {
+		t.Fatal("signature without extra entropy differs from Sign")
+	}
+
+	sig2 := SignWithEntropy(msg, seckey, RandByte(32))
+	if bytes.Equal(sig, sig2) {
+		t.Fatal("extra entropy did not change the signature")
+	}
+
+	if VerifySignature(msg, sig2, pubkey) != 1 {
+		t.Fatal("signature with extra entropy failed to verify")
+	}
+}
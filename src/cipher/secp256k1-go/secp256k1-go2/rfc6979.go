@@ -0,0 +1,91 @@
+package secp256k1go
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"log"
+)
+
+/*
+RFC6979 deterministic nonces: https://tools.ietf.org/html/rfc6979#section-3.2
+
+The nonce is derived from the seckey and the message with HMAC-SHA256, so signing
+does not depend on the quality of the host's entropy, and signing the same message with
+the same key always produces the same signature.
+
+Optional extra entropy is appended to the seed as described in section 3.6.
+*/
+
+// RFC6979 generates the candidate nonces of a signature
+type RFC6979 struct {
+	k [32]byte
+	v [32]byte
+	// set once the first nonce has been generated
+	started bool
+}
+
+// NewRFC6979 creates the nonce generator of a 32 byte message signed by a 32 byte seckey.
+// extraEntropy is nil or 32 bytes
+func NewRFC6979(seckey, msg, extraEntropy []byte) *RFC6979 {
+	if len(seckey) != 32 || len(msg) != 32 {
+		log.Panic("NewRFC6979: seckey and msg must be 32 bytes")
+	}
+	if extraEntropy != nil && len(extraEntropy) != 32 {
+		log.Panic("NewRFC6979: extraEntropy must be 32 bytes")
+	}
+
+	// bits2octets(msg): the message is reduced modulo the curve order
+	var h Number
+	h.SetBytes(msg)
+	h.mod(&TheCurve.Order)
+
+	seed := make([]byte, 0, 96)
+	seed = append(seed, seckey...)
+	seed = append(seed, h.getBin(32)...)
+	seed = append(seed, extraEntropy...)
+
+	var r RFC6979
+	for i := range r.v {
+		r.v[i] = 0x01
+	}
+
+	r.k = r.hmac(r.v[:], []byte{0x00}, seed)
+	r.v = r.hmac(r.v[:])
+	r.k = r.hmac(r.v[:], []byte{0x01}, seed)
+	r.v = r.hmac(r.v[:])
+
+	return &r
+}
+
+func (r *RFC6979) hmac(msgs ...[]byte) [32]byte {
+	h := hmac.New(sha256.New, r.k[:])
+	for _, m := range msgs {
+		h.Write(m) // nolint: errcheck
+	}
+
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// Next returns the next candidate nonce, in the range [1, n-1].
+// The first candidate is used unless signing with it fails
+func (r *RFC6979) Next() []byte {
+	for {
+		if r.started {
+			r.k = r.hmac(r.v[:], []byte{0x00})
+			r.v = r.hmac(r.v[:])
+		}
+		r.started = true
+
+		r.v = r.hmac(r.v[:])
+
+		var k Number
+		k.SetBytes(r.v[:])
+		if k.Sign() > 0 && k.Cmp(&TheCurve.Order.Int) < 0 {
+			nonce := make([]byte, 32)
+			copy(nonce, r.v[:])
+			return nonce
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package secp256k1go
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+// secp256k1 RFC6979 HMAC-SHA256 test vectors, with SHA256 of the message as the hash
+var rfc6979Vectors = []struct {
+	seckey string
+	msg    string
+	nonce  string
+}{
+	{
+		seckey: "0000000000000000000000000000000000000000000000000000000000000001",
+		msg:    "Satoshi Nakamoto",
+		nonce:  "8F8A276C19F4149656B280621E358CCE24F5F52542772691EE69063B74F15D15",
+	},
+	{
+		seckey: "0000000000000000000000000000000000000000000000000000000000000001",
+		msg:    "All those moments will be lost in time, like tears in rain. Time to die...",
+		nonce:  "38AA22D72376B4DBC472E06C3BA403EE0A394DA63FC58D88686C611ABA98D6B3",
+	},
+	{
+		seckey: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364140",
+		msg:    "Satoshi Nakamoto",
+		nonce:  "33A19B60E25FB6F4435AF53A3D42D493644827367E6453928554F43E49AA6F90",
+	},
+	{
+		seckey: "F8B8AF8CE3C7CCA5E300D33939540C10D45CE001B8F252BFBC57BA0342904181",
+		msg:    "Alan Turing",
+		nonce:  "525A82B70E67874398067543FD84C83D30C175FDC45FDEEE082FE13B1D7CFDF1",
+	},
+	{
+		seckey: "E91671C46231F833A6406CCBEA0E3E392C76C167BAC1CB013F6F1013980455C2",
+		msg:    "There is a computer disease that anybody who works with computers knows about. It's a very serious disease and it interferes completely with the work. The trouble with computers is that you 'play' with them!",
+		nonce:  "1F4B84C23A86A221D233F2521BE018D9318639D5B8BBD6374A8A59232D16AD3D",
+	},
+}
+
+func TestRFC6979(t *testing.T) {
+	for i, v := range rfc6979Vectors {
+		seckey := mustHexDecode(t, v.seckey)
+		msg := sha256.Sum256([]byte(v.msg))
+
+		nonces := NewRFC6979(seckey, msg[:], nil)
+		nonce := nonces.Next()
+		if !bytes.Equal(nonce, mustHexDecode(t, v.nonce)) {
+			t.Errorf("vector %d: nonce mismatch, got %x", i, nonce)
+		}
+
+		// The generator is deterministic
+		if !bytes.Equal(nonce, NewRFC6979(seckey, msg[:], nil).Next()) {
+			t.Errorf("vector %d: nonce is not deterministic", i)
+		}
+
+		// The next candidates differ
+		if bytes.Equal(nonce, nonces.Next()) {
+			t.Errorf("vector %d: next nonce is the same", i)
+		}
+
+		// Extra entropy changes the nonce
+		extra := sha256.Sum256([]byte("extra entropy"))
+		if bytes.Equal(nonce, NewRFC6979(seckey, msg[:], extra[:]).Next()) {
+			t.Errorf("vector %d: extra entropy does not change the nonce", i)
+		}
+	}
+}
@@ -0,0 +1,586 @@
+package secp256k1go
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"log"
+	"sort"
+)
+
+/*
+BIP340 Schnorr signatures: https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki
+
+Public keys are 32 byte x coordinates, of the point with an even y coordinate.
+Signatures are the 32 byte x coordinate of R followed by the 32 byte scalar s.
+
+Keys are aggregated MuSig style: each key is multiplied by a coefficient that commits to all of
+the keys, so that no participant can choose its key to cancel out the keys of the others.
+
+The holders of the aggregated keys sign together in three rounds, without revealing their seckeys:
+- each signer creates a nonce with MuSigNonce and sends the MuSigNonceCommitment of its public nonce
+- once all of the commitments are received, each signer reveals its public nonce, which must match its commitment
+- each signer creates a partial signature with MuSigPartialSign for the MuSigAggregateNonces of the public nonces
+The partial signatures are checked with MuSigPartialVerify and combined with MuSigCombine
+into a BIP340 signature valid for the aggregated pubkey.
+*/
+
+const (
+	schnorrAuxTag           = "BIP0340/aux"
+	schnorrNonceTag         = "BIP0340/nonce"
+	schnorrChallengeTag     = "BIP0340/challenge"
+	schnorrBatchTag         = "Skycoin/SchnorrBatch"
+	keyAggListTag           = "Skycoin/KeyAggList"
+	keyAggCoefTag           = "Skycoin/KeyAggCoefficient"
+	muSigNonceTag           = "Skycoin/MuSigNonce"
+	muSigNonceCommitmentTag = "Skycoin/MuSigNonceCommitment"
+)
+
+// taggedHash returns SHA256(SHA256(tag) || SHA256(tag) || msgs...)
+func taggedHash(tag string, msgs ...[]byte) []byte {
+	t := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(t[:]) // nolint: errcheck
+	h.Write(t[:]) // nolint: errcheck
+	for _, m := range msgs {
+		h.Write(m) // nolint: errcheck
+	}
+	return h.Sum(nil)
+}
+
+// liftX returns the point with x coordinate x and an even y coordinate
+func liftX(x []byte) (XY, bool) {
+	var n Number
+	n.SetBytes(x)
+	if n.Cmp(&TheCurve.p.Int) >= 0 {
+		return XY{}, false
+	}
+
+	var fx Field
+	fx.SetB32(x)
+
+	var pt XY
+	pt.SetXO(&fx, false)
+	pt.Y.Normalize()
+	if pt.Y.IsOdd() {
+		pt.Y.Negate(&pt.Y, 1)
+		pt.Y.Normalize()
+	}
+
+	if !pt.IsValid() {
+		return XY{}, false
+	}
+
+	return pt, true
+}
+
+// normalizedXY converts a point to affine coordinates with normalized fields
+func normalizedXY(a *XYZ) XY {
+	var pt XY
+	pt.SetXYZ(a)
+	pt.X.Normalize()
+	pt.Y.Normalize()
+	return pt
+}
+
+func schnorrChallenge(rx, px, msg []byte) Number {
+	var e Number
+	e.SetBytes(taggedHash(schnorrChallengeTag, rx, px, msg))
+	e.mod(&TheCurve.Order)
+	return e
+}
+
+// SchnorrSign creates a BIP340 signature of a 32 byte message with auxiliary random data.
+// Returns nil if the seckey is invalid
+func SchnorrSign(msg, seckey, auxRand []byte) []byte {
+	if len(msg) != 32 || len(seckey) != 32 || len(auxRand) != 32 {
+		log.Panic("SchnorrSign: msg, seckey and auxRand must be 32 bytes")
+	}
+
+	var d Number
+	d.SetBytes(seckey)
+	if d.Sign() <= 0 || d.Cmp(&TheCurve.Order.Int) >= 0 {
+		return nil
+	}
+
+	var pj XYZ
+	ECmultGen(&pj, &d)
+	p := normalizedXY(&pj)
+	if p.Y.IsOdd() {
+		d.Sub(&TheCurve.Order.Int, &d.Int)
+	}
+
+	px := make([]byte, 32)
+	p.X.GetB32(px)
+
+	t := taggedHash(schnorrAuxTag, auxRand)
+	db := d.getBin(32)
+	for i := range t {
+		t[i] ^= db[i]
+	}
+
+	var k Number
+	k.SetBytes(taggedHash(schnorrNonceTag, t, px, msg))
+	k.mod(&TheCurve.Order)
+	if k.Sign() == 0 {
+		return nil
+	}
+
+	var rj XYZ
+	ECmultGen(&rj, &k)
+	r := normalizedXY(&rj)
+	if r.Y.IsOdd() {
+		k.Sub(&TheCurve.Order.Int, &k.Int)
+	}
+
+	sig := make([]byte, 64)
+	r.X.GetB32(sig[:32])
+
+	e := schnorrChallenge(sig[:32], px, msg)
+
+	var s Number
+	s.modMul(&e, &d, &TheCurve.Order)
+	s.Add(&s.Int, &k.Int)
+	s.mod(&TheCurve.Order)
+	copy(sig[32:], s.getBin(32))
+
+	// Guard against a faulty signature, as recommended by BIP340
+	if !SchnorrVerify(msg, px, sig) {
+		log.Panic("SchnorrSign: created an invalid signature")
+	}
+
+	return sig
+}
+
+// schnorrParse parses the pubkey and signature, and computes the challenge
+func schnorrParse(msg, pubkey, sig []byte) (p XY, r Number, s Number, e Number, ok bool) {
+	if len(msg) != 32 || len(pubkey) != 32 || len(sig) != 64 {
+		log.Panic("Schnorr verify: msg and pubkey must be 32 bytes, sig must be 64 bytes")
+	}
+
+	p, ok = liftX(pubkey)
+	if !ok {
+		return
+	}
+
+	r.SetBytes(sig[:32])
+	if r.Cmp(&TheCurve.p.Int) >= 0 {
+		ok = false
+		return
+	}
+
+	s.SetBytes(sig[32:])
+	if s.Cmp(&TheCurve.Order.Int) >= 0 {
+		ok = false
+		return
+	}
+
+	e = schnorrChallenge(sig[:32], pubkey, msg)
+	return
+}
+
+// SchnorrVerify verifies a BIP340 signature of a 32 byte message for a 32 byte x-only pubkey
+func SchnorrVerify(msg, pubkey, sig []byte) bool {
+	p, _, s, e, ok := schnorrParse(msg, pubkey, sig)
+	if !ok {
+		return false
+	}
+
+	// R = s*G - e*P
+	var negE Number
+	negE.Sub(&TheCurve.Order.Int, &e.Int)
+	negE.mod(&TheCurve.Order)
+
+	var pj, rj XYZ
+	pj.SetXY(&p)
+	pj.ECmult(&rj, &negE, &s)
+	if rj.IsInfinity() {
+		return false
+	}
+
+	r := normalizedXY(&rj)
+	if r.Y.IsOdd() {
+		return false
+	}
+
+	var rx [32]byte
+	r.X.GetB32(rx[:])
+	return bytes.Equal(rx[:], sig[:32])
+}
+
+// SchnorrBatchVerify verifies BIP340 signatures of 32 byte messages for 32 byte x-only pubkeys at once.
+// Returns true if all of the signatures are valid.
+// The signatures are combined with coefficients derived from all of the inputs,
+// so that invalid signatures can't cancel each other out
+func SchnorrBatchVerify(msgs, pubkeys, sigs [][]byte) bool {
+	if len(msgs) != len(pubkeys) || len(msgs) != len(sigs) {
+		log.Panic("SchnorrBatchVerify: msgs, pubkeys and sigs must have the same length")
+	}
+
+	if len(sigs) == 0 {
+		return true
+	}
+
+	var seedData []byte
+	for i := range sigs {
+		seedData = append(seedData, pubkeys[i]...)
+		seedData = append(seedData, msgs[i]...)
+		seedData = append(seedData, sigs[i]...)
+	}
+	seed := taggedHash(schnorrBatchTag, seedData)
+
+	// Check that (a_1*s_1 + ... + a_u*s_u)*G == a_1*R_1 + a_1*e_1*P_1 + ... + a_u*R_u + a_u*e_u*P_u,
+	// with a_1 = 1 and random a_i for i > 1
+	var sum Number
+	acc := XYZ{Infinity: true}
+	for i := range sigs {
+		p, r, s, e, ok := schnorrParse(msgs[i], pubkeys[i], sigs[i])
+		if !ok {
+			return false
+		}
+
+		rp, ok := liftX(r.getBin(32))
+		if !ok {
+			return false
+		}
+
+		var a Number
+		a.SetInt64(1)
+		if i > 0 {
+			var ib [4]byte
+			binary.LittleEndian.PutUint32(ib[:], uint32(i))
+			a.SetBytes(taggedHash(schnorrBatchTag, seed, ib[:]))
+			a.mod(&TheCurve.Order)
+		}
+
+		var as, ae, zero Number
+		as.modMul(&a, &s, &TheCurve.Order)
+		sum.Add(&sum.Int, &as.Int)
+		sum.mod(&TheCurve.Order)
+		ae.modMul(&a, &e, &TheCurve.Order)
+
+		var pj, rj, t XYZ
+		pj.SetXY(&p)
+		pj.ECmult(&t, &ae, &zero)
+		acc.Add(&acc, &t)
+
+		rj.SetXY(&rp)
+		rj.ECmult(&t, &a, &zero)
+		acc.Add(&acc, &t)
+	}
+
+	var g, negG XYZ
+	ECmultGen(&g, &sum)
+	g.Neg(&negG)
+	acc.Add(&acc, &negG)
+	return acc.IsInfinity()
+}
+
+// sortedPubkeys returns a sorted copy of 33 byte compressed pubkeys
+func sortedPubkeys(pubkeys [][]byte) [][]byte {
+	sorted := make([][]byte, len(pubkeys))
+	copy(sorted, pubkeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+	return sorted
+}
+
+// keyAgg aggregates 33 byte compressed pubkeys, and returns the aggregated point
+// with the MuSig coefficient of each pubkey
+func keyAgg(pubkeys [][]byte) (XY, map[string]Number, bool) {
+	if len(pubkeys) == 0 {
+		return XY{}, nil, false
+	}
+
+	sorted := sortedPubkeys(pubkeys)
+
+	var list []byte
+	for _, pk := range sorted {
+		if len(pk) != 33 || PubkeyIsValid(pk) != 1 {
+			return XY{}, nil, false
+		}
+		list = append(list, pk...)
+	}
+	l := taggedHash(keyAggListTag, list)
+
+	coefs := make(map[string]Number, len(sorted))
+	acc := XYZ{Infinity: true}
+	for _, pk := range sorted {
+		var p XY
+		if !p.ParsePubkey(pk) || !p.IsValid() {
+			return XY{}, nil, false
+		}
+
+		var a Number
+		a.SetBytes(taggedHash(keyAggCoefTag, l, pk))
+		a.mod(&TheCurve.Order)
+		coefs[string(pk)] = a
+
+		var pj, t XYZ
+		var zero Number
+		pj.SetXY(&p)
+		pj.ECmult(&t, &a, &zero)
+		acc.Add(&acc, &t)
+	}
+
+	if acc.IsInfinity() {
+		return XY{}, nil, false
+	}
+
+	return normalizedXY(&acc), coefs, true
+}
+
+// AggregatePubkeys aggregates 33 byte compressed pubkeys into a single 33 byte compressed pubkey.
+// The pubkeys are sorted first, so the result does not depend on their order.
+// Returns nil if a pubkey is invalid or if the aggregated key is the point at infinity
+func AggregatePubkeys(pubkeys [][]byte) []byte {
+	agg, _, ok := keyAgg(pubkeys)
+	if !ok {
+		return nil
+	}
+	return agg.Bytes()
+}
+
+// negateScalar sets n to -n modulo the curve order
+func negateScalar(n *Number) {
+	n.Sub(&TheCurve.Order.Int, &n.Int)
+	n.mod(&TheCurve.Order)
+}
+
+// muSigSession holds the values that the signers of a message for an aggregated pubkey
+// derive from the pubkeys and the aggregated nonce
+type muSigSession struct {
+	coefs map[string]Number
+	// The aggregated pubkey has an odd y coordinate, so the keys of the signers are negated
+	negKey bool
+	// The aggregated nonce has an odd y coordinate, so the nonces of the signers are negated
+	negNonce bool
+	rx       []byte
+	e        Number
+}
+
+func newMuSigSession(msg []byte, pubkeys [][]byte, aggNonce []byte) (muSigSession, bool) {
+	if len(msg) != 32 || len(aggNonce) != 33 {
+		log.Panic("MuSig: msg must be 32 bytes and aggNonce must be 33 bytes")
+	}
+
+	p, coefs, ok := keyAgg(pubkeys)
+	if !ok {
+		return muSigSession{}, false
+	}
+
+	var r XY
+	if !r.ParsePubkey(aggNonce) || !r.IsValid() {
+		return muSigSession{}, false
+	}
+	r.X.Normalize()
+	r.Y.Normalize()
+
+	px := make([]byte, 32)
+	p.X.GetB32(px)
+	rx := make([]byte, 32)
+	r.X.GetB32(rx)
+
+	return muSigSession{
+		coefs:    coefs,
+		negKey:   p.Y.IsOdd(),
+		negNonce: r.Y.IsOdd(),
+		rx:       rx,
+		e:        schnorrChallenge(rx, px, msg),
+	}, true
+}
+
+// MuSigNonce derives a secret nonce for signing a 32 byte message for a 33 byte compressed aggregated pubkey,
+// from the 32 byte seckey of the signer and 32 bytes of fresh random data,
+// and returns it with its 33 byte compressed public nonce.
+// rand must never be reused, since a seckey leaks if its nonce signs with two different sets of nonces.
+// Returns nil if the seckey is invalid
+func MuSigNonce(msg, seckey, aggPubkey, rand []byte) (secnonce, pubnonce []byte) {
+	if len(msg) != 32 || len(seckey) != 32 || len(aggPubkey) != 33 || len(rand) != 32 {
+		log.Panic("MuSigNonce: msg, seckey and rand must be 32 bytes, aggPubkey must be 33 bytes")
+	}
+
+	if SeckeyIsValid(seckey) != 1 {
+		return nil, nil
+	}
+
+	var k Number
+	k.SetBytes(taggedHash(muSigNonceTag, rand, seckey, aggPubkey, msg))
+	k.mod(&TheCurve.Order)
+	if k.Sign() == 0 {
+		return nil, nil
+	}
+
+	var rj XYZ
+	ECmultGen(&rj, &k)
+	r := normalizedXY(&rj)
+
+	return k.getBin(32), r.Bytes()
+}
+
+// MuSigNonceCommitment returns the 32 byte commitment to a 33 byte compressed public nonce.
+// Signers exchange the commitments to their nonces before the nonces,
+// so that no signer can choose its nonce after seeing the nonces of the others
+func MuSigNonceCommitment(pubnonce []byte) []byte {
+	if len(pubnonce) != 33 {
+		log.Panic("MuSigNonceCommitment: pubnonce must be 33 bytes")
+	}
+
+	return taggedHash(muSigNonceCommitmentTag, pubnonce)
+}
+
+// MuSigAggregateNonces sums 33 byte compressed public nonces into a 33 byte compressed aggregated nonce.
+// Returns nil if a nonce is invalid or if the sum is the point at infinity
+func MuSigAggregateNonces(pubnonces [][]byte) []byte {
+	if len(pubnonces) == 0 {
+		return nil
+	}
+
+	acc := XYZ{Infinity: true}
+	for _, n := range pubnonces {
+		if len(n) != 33 {
+			log.Panic("MuSigAggregateNonces: pubnonces must be 33 bytes")
+		}
+
+		var r XY
+		if !r.ParsePubkey(n) || !r.IsValid() {
+			return nil
+		}
+		acc.AddXY(&acc, &r)
+	}
+
+	if acc.IsInfinity() {
+		return nil
+	}
+
+	agg := normalizedXY(&acc)
+	return agg.Bytes()
+}
+
+// MuSigPartialSign creates the 32 byte partial signature of a 32 byte message by a signer of an aggregated pubkey,
+// with its 32 byte seckey and secret nonce, the 33 byte compressed pubkeys of all of the signers
+// and their 33 byte compressed aggregated nonce.
+// Returns nil if the seckey or the nonce are invalid, or if the seckey is not of one of the pubkeys
+func MuSigPartialSign(msg, seckey, secnonce []byte, pubkeys [][]byte, aggNonce []byte) []byte {
+	if len(seckey) != 32 || len(secnonce) != 32 {
+		log.Panic("MuSigPartialSign: seckey and secnonce must be 32 bytes")
+	}
+
+	if SeckeyIsValid(seckey) != 1 || SeckeyIsValid(secnonce) != 1 {
+		return nil
+	}
+
+	s, ok := newMuSigSession(msg, pubkeys, aggNonce)
+	if !ok {
+		return nil
+	}
+
+	a, ok := s.coefs[string(GeneratePublicKey(seckey))]
+	if !ok {
+		return nil
+	}
+
+	var x, k Number
+	x.SetBytes(seckey)
+	k.SetBytes(secnonce)
+	if s.negKey {
+		negateScalar(&x)
+	}
+	if s.negNonce {
+		negateScalar(&k)
+	}
+
+	// s_i = k_i + e*a_i*x_i
+	var ea, sig Number
+	ea.modMul(&s.e, &a, &TheCurve.Order)
+	sig.modMul(&ea, &x, &TheCurve.Order)
+	sig.Add(&sig.Int, &k.Int)
+	sig.mod(&TheCurve.Order)
+
+	return sig.getBin(32)
+}
+
+// MuSigPartialVerify verifies the 32 byte partial signature of a 32 byte message by the signer
+// with a 33 byte compressed pubkey and public nonce, for the 33 byte compressed pubkeys of all of the signers
+// and their 33 byte compressed aggregated nonce
+func MuSigPartialVerify(msg, pubkey, pubnonce, partialSig []byte, pubkeys [][]byte, aggNonce []byte) bool {
+	if len(pubkey) != 33 || len(pubnonce) != 33 || len(partialSig) != 32 {
+		log.Panic("MuSigPartialVerify: pubkey and pubnonce must be 33 bytes, partialSig must be 32 bytes")
+	}
+
+	s, ok := newMuSigSession(msg, pubkeys, aggNonce)
+	if !ok {
+		return false
+	}
+
+	a, ok := s.coefs[string(pubkey)]
+	if !ok {
+		return false
+	}
+
+	var p, r XY
+	if !p.ParsePubkey(pubkey) || !p.IsValid() || !r.ParsePubkey(pubnonce) || !r.IsValid() {
+		return false
+	}
+
+	var sig Number
+	sig.SetBytes(partialSig)
+	if sig.Cmp(&TheCurve.Order.Int) >= 0 {
+		return false
+	}
+
+	// Check that s_i*G == R_i + e*a_i*P_i, with P_i and R_i negated like the keys and nonces
+	var ea Number
+	ea.modMul(&s.e, &a, &TheCurve.Order)
+	if s.negKey {
+		negateScalar(&ea)
+	}
+	if s.negNonce {
+		var negR XY
+		r.Neg(&negR)
+		r = negR
+	}
+	negateScalar(&sig)
+
+	var pj, acc XYZ
+	pj.SetXY(&p)
+	pj.ECmult(&acc, &ea, &sig)
+	acc.AddXY(&acc, &r)
+	return acc.IsInfinity()
+}
+
+// MuSigCombine combines the 32 byte partial signatures of all of the signers of an aggregated pubkey,
+// for their 33 byte compressed aggregated nonce, into a 64 byte BIP340 signature.
+// Returns nil if the aggregated nonce or a partial signature is invalid
+func MuSigCombine(aggNonce []byte, partialSigs [][]byte) []byte {
+	if len(aggNonce) != 33 {
+		log.Panic("MuSigCombine: aggNonce must be 33 bytes")
+	}
+
+	var r XY
+	if !r.ParsePubkey(aggNonce) || !r.IsValid() {
+		return nil
+	}
+	r.X.Normalize()
+
+	var sum Number
+	for _, ps := range partialSigs {
+		if len(ps) != 32 {
+			log.Panic("MuSigCombine: partialSigs must be 32 bytes")
+		}
+
+		var s Number
+		s.SetBytes(ps)
+		if s.Cmp(&TheCurve.Order.Int) >= 0 {
+			return nil
+		}
+		sum.Add(&sum.Int, &s.Int)
+		sum.mod(&TheCurve.Order)
+	}
+
+	sig := make([]byte, 64)
+	r.X.GetB32(sig[:32])
+	copy(sig[32:], sum.getBin(32))
+	return sig
+}
@@ -0,0 +1,354 @@
+package secp256k1go
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// Test vectors from https://github.com/bitcoin/bips/blob/master/bip-0340/test-vectors.csv
+var schnorrVectors = []struct {
+	seckey  string
+	pubkey  string
+	auxRand string
+	msg     string
+	sig     string
+	valid   bool
+}{
+	{
+		seckey:  "0000000000000000000000000000000000000000000000000000000000000003",
+		pubkey:  "F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		auxRand: "0000000000000000000000000000000000000000000000000000000000000000",
+		msg:     "0000000000000000000000000000000000000000000000000000000000000000",
+		sig:     "E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0",
+		valid:   true,
+	},
+	{
+		seckey:  "B7E151628AED2A6ABF7158809CF4F3C762E7160F38B4DA56A784D9045190CFEF",
+		pubkey:  "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		auxRand: "0000000000000000000000000000000000000000000000000000000000000001",
+		msg:     "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:     "6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0A",
+		valid:   true,
+	},
+	{
+		seckey:  "C90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B14E5C9",
+		pubkey:  "DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8",
+		auxRand: "C87AA53824B4D7AE2EB035A2B5BBBCCC080E76CDC6D1692C4B0B62D798E6D906",
+		msg:     "7E2D58D8B3BCDF1ABADEC7829054F90DDA9805AAB56C77333024B9D0A508B75C",
+		sig:     "5831AAEED7B44BB74E5EAB94BA9D4294C49BCF2A60728D8B4C200F50DD313C1BAB745879A5AD954A72C45A91C3A51D3C7ADEA98D82F8481E0E1E03674A6F3FB7",
+		valid:   true,
+	},
+	{
+		seckey:  "0B432B2677937381AEF05BB02A66ECD012773062CF3FA2549E44F58ED2401710",
+		pubkey:  "25D1DFF95105F5253C4022F628A996AD3A0D95FBF21D468A1B33F8C160D8F517",
+		auxRand: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF",
+		msg:     "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF",
+		sig:     "7EB0509757E246F19449885651611CB965ECC1A187DD51B64FDA1EDC9637D5EC97582B9CB13DB3933705B32BA982AF5AF25FD78881EBB32771FC5922EFC66EA3",
+		valid:   true,
+	},
+	{
+		// r has leading zeros
+		pubkey: "D69C3509BB99E412E68B0FE8544E72837DFA30746D8BE2AA65975F29D22DC7B9",
+		msg:    "4DF3C3F68FCC83B27E9D42C90431A72499F17875C81A599B566C9889B9696703",
+		sig:    "00000000000000000000003B78CE563F89A0ED9414F5AA28AD0D96D6795F9C6376AFB1548AF603B3EB45C9F8207DEE1060CB71C04E80F593060B07D28308D7F4",
+		valid:  true,
+	},
+	{
+		// public key not on the curve
+		pubkey: "EEFDEA4CDB677750A420FEE807EACF21EB9898AE79B9768766E4FAA04A2D4A34",
+		msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:    "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E17776969E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B",
+	},
+	{
+		// has_even_y(R) is false
+		pubkey: "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:    "FFF97BD5755EEEA420453A14355235D382F6472F8568A18B2F057A14602975563CC27944640AC607CD107AE10923D9EF7A73C643E166BE5EBEAFA34B1AC553E2",
+	},
+	{
+		// negated message
+		pubkey: "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:    "1FA62E331EDBC21C394792D2AB1100A7B432B013DF3F6FF4F99FCB33E0E1515F28890B3EDB6E7189B630448B515CE4F8622A954CFE545735AAEA5134FCCDB2BD",
+	},
+	{
+		// negated s value
+		pubkey: "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:    "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E177769961764B3AA9B2FFCB6EF947B6887A226E8D7C93E00C5ED0C1834FF0D0C2E6DA6",
+	},
+	{
+		// sG - eP is infinite
+		pubkey: "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:    "0000000000000000000000000000000000000000000000000000000000000000123DDA8328AF9C23A94C1FEECFD123BA4FB73476F0D594DCB65C6425BD186051",
+	},
+	{
+		// sig[0:32] is equal to field size
+		pubkey: "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:    "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F69E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B",
+	},
+	{
+		// sig[32:64] is equal to curve order
+		pubkey: "DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:    "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E177769FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141",
+	},
+	{
+		// public key exceeds field size
+		pubkey: "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30",
+		msg:    "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		sig:    "6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E17776969E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B",
+	},
+}
+
+func mustHexDecode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestSchnorrSign(t *testing.T) {
+	for i, v := range schnorrVectors {
+		if v.seckey == "" {
+			continue
+		}
+
+		seckey := mustHexDecode(t, v.seckey)
+		msg := mustHexDecode(t, v.msg)
+		auxRand := mustHexDecode(t, v.auxRand)
+
+		sig := SchnorrSign(msg, seckey, auxRand)
+		if !bytes.Equal(sig, mustHexDecode(t, v.sig)) {
+			t.Errorf("vector %d: signature mismatch, got %x", i, sig)
+		}
+
+		pubkey := GeneratePublicKey(seckey)
+		if !bytes.Equal(pubkey[1:], mustHexDecode(t, v.pubkey)) {
+			t.Errorf("vector %d: pubkey mismatch, got %x", i, pubkey[1:])
+		}
+	}
+
+	msg := mustHexDecode(t, schnorrVectors[0].msg)
+	aux := mustHexDecode(t, schnorrVectors[0].auxRand)
+	if SchnorrSign(msg, make([]byte, 32), aux) != nil {
+		t.Error("signed with a zero seckey")
+	}
+	order := mustHexDecode(t, "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141")
+	if SchnorrSign(msg, order, aux) != nil {
+		t.Error("signed with a seckey equal to the curve order")
+	}
+}
+
+func TestSchnorrVerify(t *testing.T) {
+	for i, v := range schnorrVectors {
+		msg := mustHexDecode(t, v.msg)
+		pubkey := mustHexDecode(t, v.pubkey)
+		sig := mustHexDecode(t, v.sig)
+
+		if SchnorrVerify(msg, pubkey, sig) != v.valid {
+			t.Errorf("vector %d: expected valid=%v", i, v.valid)
+		}
+	}
+}
+
+func TestSchnorrBatchVerify(t *testing.T) {
+	var msgs, pubkeys, sigs [][]byte
+	for _, v := range schnorrVectors {
+		if !v.valid {
+			continue
+		}
+		msgs = append(msgs, mustHexDecode(t, v.msg))
+		pubkeys = append(pubkeys, mustHexDecode(t, v.pubkey))
+		sigs = append(sigs, mustHexDecode(t, v.sig))
+	}
+
+	if !SchnorrBatchVerify(nil, nil, nil) {
+		t.Error("empty batch is invalid")
+	}
+
+	if !SchnorrBatchVerify(msgs, pubkeys, sigs) {
+		t.Error("valid batch is invalid")
+	}
+
+	for i := range sigs {
+		if !SchnorrBatchVerify(msgs[i:i+1], pubkeys[i:i+1], sigs[i:i+1]) {
+			t.Errorf("valid batch of signature %d is invalid", i)
+		}
+	}
+
+	// Swapping the messages of two signatures invalidates the batch
+	swapped := make([][]byte, len(msgs))
+	copy(swapped, msgs)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+	if SchnorrBatchVerify(swapped, pubkeys, sigs) {
+		t.Error("batch with swapped messages is valid")
+	}
+
+	// Each invalid vector invalidates the batch
+	for i, v := range schnorrVectors {
+		if v.valid {
+			continue
+		}
+		bm := append([][]byte{mustHexDecode(t, v.msg)}, msgs...)
+		bp := append([][]byte{mustHexDecode(t, v.pubkey)}, pubkeys...)
+		bs := append([][]byte{mustHexDecode(t, v.sig)}, sigs...)
+		if SchnorrBatchVerify(bm, bp, bs) {
+			t.Errorf("batch with invalid vector %d is valid", i)
+		}
+	}
+
+	// Two invalid signatures which would cancel out without the random coefficients
+	s0 := make([]byte, 64)
+	s1 := make([]byte, 64)
+	copy(s0, sigs[0])
+	copy(s1, sigs[1])
+	var a, b Number
+	a.SetBytes(s0[32:])
+	b.SetBytes(s1[32:])
+	a.Add(&a.Int, big.NewInt(1))
+	b.Sub(&b.Int, big.NewInt(1))
+	a.mod(&TheCurve.Order)
+	b.mod(&TheCurve.Order)
+	copy(s0[32:], a.getBin(32))
+	copy(s1[32:], b.getBin(32))
+	if SchnorrBatchVerify(msgs[:2], pubkeys[:2], [][]byte{s0, s1}) {
+		t.Error("batch with cancelling signatures is valid")
+	}
+}
+
+func TestAggregatePubkeys(t *testing.T) {
+	var seckeys, pubkeys [][]byte
+	for i := 0; i < 3; i++ {
+		sec := sha256.Sum256([]byte{byte(i)})
+		seckeys = append(seckeys, sec[:])
+		pubkeys = append(pubkeys, GeneratePublicKey(sec[:]))
+	}
+
+	agg := AggregatePubkeys(pubkeys)
+	if len(agg) != 33 || PubkeyIsValid(agg) != 1 {
+		t.Fatalf("invalid aggregated pubkey %x", agg)
+	}
+
+	// The order of the keys does not matter
+	reversed := [][]byte{pubkeys[2], pubkeys[1], pubkeys[0]}
+	if !bytes.Equal(agg, AggregatePubkeys(reversed)) {
+		t.Error("aggregated pubkey depends on the order of the pubkeys")
+	}
+
+	// The aggregated pubkey is not the plain sum of the pubkeys
+	if bytes.Equal(agg, AggregatePubkeys(pubkeys[:2])) {
+		t.Error("aggregated pubkey does not depend on all of the pubkeys")
+	}
+
+	if AggregatePubkeys(nil) != nil {
+		t.Error("aggregated no pubkeys")
+	}
+	if AggregatePubkeys([][]byte{pubkeys[0], make([]byte, 33)}) != nil {
+		t.Error("aggregated an invalid pubkey")
+	}
+}
+
+func TestMuSig(t *testing.T) {
+	// The keys and nonces are negated when the aggregated pubkey or nonce has an odd y coordinate,
+	// sign with several key sets and messages to cover all of the cases
+	var negKey, negNonce int
+	for n := 0; n < 16; n++ {
+		var seckeys, pubkeys [][]byte
+		for i := 0; i < 3; i++ {
+			sec := sha256.Sum256([]byte{byte(n), byte(i)})
+			seckeys = append(seckeys, sec[:])
+			pubkeys = append(pubkeys, GeneratePublicKey(sec[:]))
+		}
+
+		agg := AggregatePubkeys(pubkeys)
+		msg := sha256.Sum256([]byte{byte(n)})
+
+		var secnonces, pubnonces [][]byte
+		for i, sk := range seckeys {
+			rand := sha256.Sum256([]byte{byte(n), byte(i), 1})
+			secnonce, pubnonce := MuSigNonce(msg[:], sk, agg, rand[:])
+			if !bytes.Equal(GeneratePublicKey(secnonce), pubnonce) {
+				t.Fatal("public nonce does not match the secret nonce")
+			}
+			if len(MuSigNonceCommitment(pubnonce)) != 32 {
+				t.Fatal("invalid nonce commitment length")
+			}
+			secnonces = append(secnonces, secnonce)
+			pubnonces = append(pubnonces, pubnonce)
+		}
+
+		aggNonce := MuSigAggregateNonces(pubnonces)
+		if len(aggNonce) != 33 {
+			t.Fatalf("invalid aggregated nonce %x", aggNonce)
+		}
+
+		if agg[0] == 0x03 {
+			negKey++
+		}
+		if aggNonce[0] == 0x03 {
+			negNonce++
+		}
+
+		var partialSigs [][]byte
+		for i, sk := range seckeys {
+			ps := MuSigPartialSign(msg[:], sk, secnonces[i], pubkeys, aggNonce)
+			if len(ps) != 32 {
+				t.Fatalf("invalid partial signature %x", ps)
+			}
+			if !MuSigPartialVerify(msg[:], pubkeys[i], pubnonces[i], ps, pubkeys, aggNonce) {
+				t.Fatalf("partial signature %d is invalid", i)
+			}
+			if MuSigPartialVerify(msg[:], pubkeys[i], pubnonces[(i+1)%3], ps, pubkeys, aggNonce) {
+				t.Fatalf("partial signature %d is valid for another nonce", i)
+			}
+			partialSigs = append(partialSigs, ps)
+		}
+
+		sig := MuSigCombine(aggNonce, partialSigs)
+		if !SchnorrVerify(msg[:], agg[1:], sig) {
+			t.Fatal("combined signature is invalid")
+		}
+
+		// The partial signature of another message is invalid
+		other := sha256.Sum256([]byte{byte(n), 2})
+		ps := MuSigPartialSign(other[:], seckeys[0], secnonces[0], pubkeys, aggNonce)
+		if MuSigPartialVerify(msg[:], pubkeys[0], pubnonces[0], ps, pubkeys, aggNonce) {
+			t.Fatal("partial signature of another message is valid")
+		}
+	}
+
+	if negKey == 0 || negKey == 16 || negNonce == 0 || negNonce == 16 {
+		t.Fatalf("the key and nonce parities are not all covered: %d odd keys, %d odd nonces", negKey, negNonce)
+	}
+
+	sec := sha256.Sum256([]byte{0})
+	pubkey := GeneratePublicKey(sec[:])
+	other := sha256.Sum256([]byte{1})
+	msg := sha256.Sum256([]byte{2})
+	secnonce, pubnonce := MuSigNonce(msg[:], sec[:], pubkey, msg[:])
+
+	// The seckey must be of one of the pubkeys
+	if MuSigPartialSign(msg[:], other[:], secnonce, [][]byte{pubkey}, pubnonce) != nil {
+		t.Error("partial signature by a seckey of another pubkey")
+	}
+
+	if s, p := MuSigNonce(msg[:], make([]byte, 32), pubkey, msg[:]); s != nil || p != nil {
+		t.Error("nonce of an invalid seckey")
+	}
+	if MuSigAggregateNonces(nil) != nil {
+		t.Error("aggregated no nonces")
+	}
+	if MuSigAggregateNonces([][]byte{pubnonce, make([]byte, 33)}) != nil {
+		t.Error("aggregated an invalid nonce")
+	}
+	if MuSigCombine(make([]byte, 33), [][]byte{msg[:]}) != nil {
+		t.Error("combined with an invalid nonce")
+	}
+}
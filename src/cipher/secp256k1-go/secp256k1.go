@@ -226,18 +226,28 @@ func DeterministicKeyPairIterator(seedIn []byte) ([]byte, []byte, []byte) {
 	return seed1, pubkey, seckey
 }
 
-// Sign sign hash
+// Sign signs a 32 byte hash with an RFC6979 deterministic nonce
 func Sign(msg []byte, seckey []byte) []byte {
+	return SignWithEntropy(msg, seckey, nil)
+}
+
+// SignWithEntropy signs a 32 byte hash with an RFC6979 deterministic nonce.
+// extraEntropy is nil or 32 bytes, and is mixed into the nonce, so that signatures with different
+// extra entropy differ while remaining safe if the extra entropy is weak
+func SignWithEntropy(msg []byte, seckey []byte, extraEntropy []byte) []byte {
 	if len(seckey) != 32 {
 		log.Panic("Sign, Invalid seckey length")
 	}
 	if secp.SeckeyIsValid(seckey) != 1 {
 		log.Panic("Attempting to sign with invalid seckey")
 	}
-	if len(msg) == 0 {
-		log.Panic("Sign, message nil")
+	if len(msg) != 32 {
+		log.Panic("Sign, Invalid message length")
 	}
-	var nonce = RandByte(32)
+	if extraEntropy != nil && len(extraEntropy) != 32 {
+		log.Panic("Sign, Invalid extra entropy length")
+	}
+
 	var sig = make([]byte, 65)
 	var recid int
 
@@ -249,12 +259,14 @@ func Sign(msg []byte, seckey []byte) []byte {
 
 	seckey1.SetBytes(seckey)
 	msg1.SetBytes(msg)
-	nonce1.SetBytes(nonce)
 
-	ret := cSig.Sign(&seckey1, &msg1, &nonce1, &recid)
-
-	if ret != 1 {
-		log.Panic("Secp25k1-go, Sign, signature operation failed")
+	// The first nonce only fails if s is zero, in which case the next nonce is used
+	nonces := secp.NewRFC6979(seckey, msg, extraEntropy)
+	for {
+		nonce1.SetBytes(nonces.Next())
+		if cSig.Sign(&seckey1, &msg1, &nonce1, &recid) == 1 {
+			break
+		}
 	}
 
 	sigBytes := cSig.Bytes()
@@ -32,6 +32,19 @@ type SeedTestDataJSON struct {
 	Keys []KeysTestDataJSON `json:"keys"`
 }
 
+// RFC6979VectorJSON contains a secret key, a message and the deterministic signature of its hash
+type RFC6979VectorJSON struct {
+	Secret    string `json:"secret"`
+	Message   string `json:"message"`
+	Hash      string `json:"hash"`
+	Signature string `json:"signature"`
+}
+
+// RFC6979TestDataJSON contains RFC6979 deterministic signature vectors
+type RFC6979TestDataJSON struct {
+	Vectors []RFC6979VectorJSON `json:"vectors"`
+}
+
 // InputTestData contains hashes to be signed
 type InputTestData struct {
 	Hashes []cipher.SHA256
@@ -279,11 +292,101 @@ func ValidateSeedData(seedData *SeedTestData, inputData *InputTestData) error {
 					return errors.New("created signature is null")
 				}
 
-				// NOTE: signatures are not deterministic, they use a nonce,
-				// so we don't compare the generated sig to the provided sig
+				// Signatures use RFC6979 deterministic nonces,
+				// so the generated sig must match the provided sig
+				if sig != sig2 {
+					return errors.New("created signature does not match provided signature")
+				}
 			}
 		}
 	}
 
 	return nil
 }
+
+// RFC6979Vector contains a secret key, a message and the deterministic signature of its hash
+type RFC6979Vector struct {
+	Secret    cipher.SecKey
+	Message   []byte
+	Hash      cipher.SHA256
+	Signature cipher.Sig
+}
+
+// RFC6979TestData contains RFC6979 deterministic signature vectors
+type RFC6979TestData struct {
+	Vectors []RFC6979Vector
+}
+
+// ToJSON converts RFC6979TestData to RFC6979TestDataJSON
+func (d *RFC6979TestData) ToJSON() *RFC6979TestDataJSON {
+	vectors := make([]RFC6979VectorJSON, len(d.Vectors))
+	for i, v := range d.Vectors {
+		vectors[i] = RFC6979VectorJSON{
+			Secret:    v.Secret.Hex(),
+			Message:   string(v.Message),
+			Hash:      v.Hash.Hex(),
+			Signature: v.Signature.Hex(),
+		}
+	}
+
+	return &RFC6979TestDataJSON{
+		Vectors: vectors,
+	}
+}
+
+// RFC6979TestDataFromJSON converts RFC6979TestDataJSON to RFC6979TestData
+func RFC6979TestDataFromJSON(d *RFC6979TestDataJSON) (*RFC6979TestData, error) {
+	vectors := make([]RFC6979Vector, len(d.Vectors))
+	for i, v := range d.Vectors {
+		s, err := cipher.SecKeyFromHex(v.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		h, err := cipher.SHA256FromHex(v.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		sig, err := cipher.SigFromHex(v.Signature)
+		if err != nil {
+			return nil, err
+		}
+
+		vectors[i] = RFC6979Vector{
+			Secret:    s,
+			Message:   []byte(v.Message),
+			Hash:      h,
+			Signature: sig,
+		}
+	}
+
+	return &RFC6979TestData{
+		Vectors: vectors,
+	}, nil
+}
+
+// ValidateRFC6979Data validates the provided RFC6979TestData against the current cipher library
+func ValidateRFC6979Data(data *RFC6979TestData) error {
+	for _, v := range data.Vectors {
+		if cipher.SumSHA256(v.Message) != v.Hash {
+			return errors.New("message hash does not match provided hash")
+		}
+
+		sig, err := cipher.SignHash(v.Hash, v.Secret)
+		if err != nil {
+			return fmt.Errorf("cipher.SignHash failed: %v", err)
+		}
+
+		if sig != v.Signature {
+			return errors.New("created signature does not match provided signature")
+		}
+
+		p := cipher.MustPubKeyFromSecKey(v.Secret)
+		if err := cipher.VerifyPubKeySignedHash(p, sig, v.Hash); err != nil {
+			return fmt.Errorf("cipher.VerifyPubKeySignedHash failed: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -15,6 +15,7 @@ const (
 	testdataDir           = "./testdata/"
 	manyAddressesFilename = "many-addresses.golden"
 	inputHashesFilename   = "input-hashes.golden"
+	rfc6979Filename       = "rfc6979.golden"
 	seedFileRegex         = `seed-\d+.golden`
 )
 
@@ -62,6 +63,21 @@ func TestSeedSignatures(t *testing.T) {
 	}
 }
 
+func TestRFC6979Signatures(t *testing.T) {
+	fn := filepath.Join(testdataDir, rfc6979Filename)
+
+	var dataJSON RFC6979TestDataJSON
+	err := file.LoadJSON(fn, &dataJSON)
+	require.NoError(t, err)
+
+	data, err := RFC6979TestDataFromJSON(&dataJSON)
+	require.NoError(t, err)
+	require.NotEmpty(t, data.Vectors)
+
+	err = ValidateRFC6979Data(data)
+	require.NoError(t, err)
+}
+
 func traverseFiles(dir string, filenameTemplate string) ([]string, error) { // nolint: unparam
 	files := make([]string, 0)
 	if err := filepath.Walk(dir, func(path string, f os.FileInfo, _ error) error {
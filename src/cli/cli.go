@@ -231,7 +231,9 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		createRawTxCmd(),
 		decodeRawTxCmd(),
 		decryptWalletCmd(),
+		doubleSignProofsCmd(),
 		encryptWalletCmd(),
+		htlcSwapCmd(),
 		lastBlocksCmd(),
 		listAddressesCmd(),
 		listWalletsCmd(),
@@ -250,6 +252,13 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		walletOutputsCmd(),
 		richlistCmd(),
 		addressTransactionsCmd(),
+		connectPeerCmd(),
+		addPeerCmd(),
+		removePeerCmd(),
+		trustPeerCmd(),
+		privatePeerCmd(),
+		resetPeerRetriesCmd(),
+		signPeerListCmd(),
 	}
 
 	skyCLI.Version = Version
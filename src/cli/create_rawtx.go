@@ -380,7 +380,7 @@ func validateSendAmounts(toAddrs []SendAmount) error {
 // PUBLIC
 
 // CreateRawTxFromWallet creates a transaction from any address or combination of addresses in a wallet
-func CreateRawTxFromWallet(c GetOutputser, walletFile, chgAddr string, toAddrs []SendAmount, pr PasswordReader) (*coin.Transaction, error) {
+func CreateRawTxFromWallet(c CreateRawTxer, walletFile, chgAddr string, toAddrs []SendAmount, pr PasswordReader) (*coin.Transaction, error) {
 	// check change address
 	cAddr, err := cipher.DecodeBase58Address(chgAddr)
 	if err != nil {
@@ -434,7 +434,7 @@ func CreateRawTxFromWallet(c GetOutputser, walletFile, chgAddr string, toAddrs [
 }
 
 // CreateRawTxFromAddress creates a transaction from a specific address in a wallet
-func CreateRawTxFromAddress(c GetOutputser, addr, walletFile, chgAddr string, toAddrs []SendAmount, pr PasswordReader) (*coin.Transaction, error) {
+func CreateRawTxFromAddress(c CreateRawTxer, addr, walletFile, chgAddr string, toAddrs []SendAmount, pr PasswordReader) (*coin.Transaction, error) {
 	// check if the address is in the default wallet.
 	wlt, err := wallet.Load(walletFile)
 	if err != nil {
@@ -495,8 +495,15 @@ type GetOutputser interface {
 	OutputsForAddresses([]string) (*readable.UnspentOutputsSummary, error)
 }
 
-// CreateRawTx creates a transaction from a set of addresses contained in a loaded *wallet.Wallet
-func CreateRawTx(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr string, toAddrs []SendAmount, password []byte) (*coin.Transaction, error) {
+// CreateRawTxer implements the unspent output and block querying needed to create a transaction
+type CreateRawTxer interface {
+	GetOutputser
+	BlockBySeq(uint64) (*readable.Block, error)
+}
+
+// CreateRawTx creates a transaction from a set of addresses contained in a loaded *wallet.Wallet.
+// After the replay protection activation height, the transaction is replay protected for the chain of the node
+func CreateRawTx(c CreateRawTxer, wlt *wallet.Wallet, inAddrs []string, chgAddr string, toAddrs []SendAmount, password []byte) (*coin.Transaction, error) {
 	if err := validateSendAmounts(toAddrs); err != nil {
 		return nil, err
 	}
@@ -512,7 +519,17 @@ func CreateRawTx(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr s
 		return nil, err
 	}
 
-	txn, err := createRawTx(outputs, wlt, chgAddr, toAddrs, password)
+	head, err := outputs.Head.ToCoinBlockHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	genesisHash, err := replayProtectionGenesisHash(c, head)
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err := createRawTx(outputs, wlt, chgAddr, toAddrs, password, genesisHash)
 	if err != nil {
 		return nil, err
 	}
@@ -527,15 +544,15 @@ func CreateRawTx(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr s
 		}
 	}
 
-	head, err := outputs.Head.ToCoinBlockHeader()
-	if err != nil {
-		return nil, err
+	var gbHash cipher.SHA256
+	if genesisHash != nil {
+		gbHash = *genesisHash
 	}
 
 	if err := visor.VerifySingleTxnSoftConstraints(*txn, head.Time, inUxsFiltered, params.UserVerifyTxn); err != nil {
 		return nil, err
 	}
-	if err := visor.VerifySingleTxnHardConstraints(*txn, head, inUxsFiltered); err != nil {
+	if err := visor.VerifySingleTxnHardConstraints(*txn, head, inUxsFiltered, gbHash); err != nil {
 		return nil, err
 	}
 	if err := visor.VerifySingleTxnUserConstraints(*txn); err != nil {
@@ -545,7 +562,28 @@ func CreateRawTx(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr s
 	return txn, nil
 }
 
-func createRawTx(uxouts *readable.UnspentOutputsSummary, wlt *wallet.Wallet, chgAddr string, toAddrs []SendAmount, password []byte) (*coin.Transaction, error) {
+// replayProtectionGenesisHash returns the hash of the genesis block of the node's chain,
+// or nil if the next block does not accept replay protected transactions
+func replayProtectionGenesisHash(c CreateRawTxer, head coin.BlockHeader) (*cipher.SHA256, error) {
+	if head.BkSeq+1 < params.ReplayProtectionActivationHeight {
+		return nil, nil
+	}
+
+	b, err := c.BlockBySeq(0)
+	if err != nil {
+		return nil, err
+	}
+
+	gbHead, err := b.Head.ToCoinBlockHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	genesisHash := gbHead.Hash()
+	return &genesisHash, nil
+}
+
+func createRawTx(uxouts *readable.UnspentOutputsSummary, wlt *wallet.Wallet, chgAddr string, toAddrs []SendAmount, password []byte, genesisHash *cipher.SHA256) (*coin.Transaction, error) {
 	// Calculate total required coins
 	var totalCoins uint64
 	for _, arg := range toAddrs {
@@ -572,7 +610,7 @@ func createRawTx(uxouts *readable.UnspentOutputsSummary, wlt *wallet.Wallet, chg
 			return nil, err
 		}
 
-		return NewTransaction(spendOutputs, keys, txOuts)
+		return NewTransaction(spendOutputs, keys, txOuts, genesisHash)
 	}
 
 	makeTx := func() (*coin.Transaction, error) {
@@ -717,7 +755,8 @@ func getKeys(wlt *wallet.Wallet, outs []wallet.UxBalance) ([]cipher.SecKey, erro
 }
 
 // NewTransaction creates a transaction. The transaction should be validated against hard and soft constraints before transmission.
-func NewTransaction(utxos []wallet.UxBalance, keys []cipher.SecKey, outs []coin.TransactionOutput) (*coin.Transaction, error) {
+// If genesisHash is not nil, the transaction is replay protected for the chain of that genesis block.
+func NewTransaction(utxos []wallet.UxBalance, keys []cipher.SecKey, outs []coin.TransactionOutput, genesisHash *cipher.SHA256) (*coin.Transaction, error) {
 	txn := coin.Transaction{}
 	for _, u := range utxos {
 		txn.PushInput(u.Hash)
@@ -727,7 +766,11 @@ func NewTransaction(utxos []wallet.UxBalance, keys []cipher.SecKey, outs []coin.
 		txn.PushOutput(o.Address, o.Coins, o.Hours)
 	}
 
-	txn.SignInputs(keys)
+	if genesisHash != nil {
+		txn.SignInputsForChain(keys, *genesisHash)
+	} else {
+		txn.SignInputs(keys)
+	}
 
 	err := txn.UpdateHeader()
 	if err != nil {
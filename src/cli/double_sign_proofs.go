@@ -0,0 +1,25 @@
+package cli
+
+import (
+	gcli "github.com/spf13/cobra"
+)
+
+func doubleSignProofsCmd() *gcli.Command {
+	return &gcli.Command{
+		Short: "Show the proofs that the block publisher signed conflicting blocks",
+		Long: `Show the proofs that the block publisher signed two different blocks at the same seq.
+    The node stops accepting blocks at the lowest seq with a proof, unless it runs with -disable-double-sign-halt.`,
+		Use:                   "doubleSignProofs",
+		Args:                  gcli.NoArgs,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *gcli.Command, _ []string) error {
+			proofs, err := apiClient.DoubleSignProofs()
+			if err != nil {
+				return err
+			}
+
+			return printJSON(proofs)
+		},
+	}
+}
@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	gcli "github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+)
+
+// Bitcoin script opcodes used by the HTLC redeem script
+const (
+	opIf                  = 0x63
+	opElse                = 0x67
+	opEndIf               = 0x68
+	opDrop                = 0x75
+	opDup                 = 0x76
+	opSize                = 0x82
+	opEqualVerify         = 0x88
+	opSHA256              = 0xa8
+	opHash160             = 0xa9
+	opCheckSig            = 0xac
+	opCheckLockTimeVerify = 0xb1
+)
+
+// bitcoinP2SHVersion is the version byte of mainnet bitcoin P2SH addresses
+const bitcoinP2SHVersion = 0x05
+
+// HTLCSwap is the output of the htlcSwap command
+type HTLCSwap struct {
+	// Secret is only known to the initiator of the swap, and is omitted if the hashlock was provided
+	Secret   string          `json:"secret,omitempty"`
+	Hashlock string          `json:"hashlock"`
+	Skycoin  HTLCSwapSkycoin `json:"skycoin"`
+	Bitcoin  HTLCSwapBitcoin `json:"bitcoin"`
+}
+
+// HTLCSwapSkycoin is the skycoin side of an atomic swap
+type HTLCSwapSkycoin struct {
+	Address  string           `json:"address"`
+	Contract api.HTLCContract `json:"contract"`
+}
+
+// HTLCSwapBitcoin is the bitcoin side of an atomic swap
+type HTLCSwapBitcoin struct {
+	Address      string `json:"address"`
+	RedeemScript string `json:"redeem_script"`
+	Recipient    string `json:"recipient"`
+	Refund       string `json:"refund"`
+	LockTime     uint32 `json:"lock_time"`
+}
+
+func htlcSwapCmd() *gcli.Command {
+	htlcSwapCmd := &gcli.Command{
+		Short: "Generate the matching skycoin and bitcoin HTLCs of an atomic swap",
+		Use:   "htlcSwap",
+		Long: `Generate the matching skycoin and bitcoin hash-time-locked contracts of an atomic swap, offline.
+    Both contracts share the same hashlock. If neither a secret nor a hashlock is provided,
+    a random secret is generated. Only the initiator of the swap knows the secret, and the
+    counterparty creates its contract from the hashlock.
+
+    The skycoin coins are locked by sending them to the skycoin HTLC address with the
+    /api/v2/wallet/htlc/create endpoint, and the bitcoin coins by sending them to the P2SH address.
+
+    The lock time of the contract funded by the initiator must be later than the lock time of
+    the counterparty's contract, so that the counterparty has time to claim after the secret is revealed.
+
+    Skycoin HTLCs can only be created once the next block reaches the HTLC activation height,
+    with a lock height of at least the activation height. A warning is printed to stderr if the
+    lock height is too low, or if the node is reachable and has not reached the activation height yet.`,
+		Args:                  gcli.NoArgs,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *gcli.Command, _ []string) error {
+			secretStr, err := c.Flags().GetString("secret")
+			if err != nil {
+				return err
+			}
+
+			hashlockStr, err := c.Flags().GetString("hashlock")
+			if err != nil {
+				return err
+			}
+
+			var secret *cipher.SHA256
+			var hashlock cipher.SHA256
+			switch {
+			case secretStr != "" && hashlockStr != "":
+				return errors.New("secret and hashlock cannot be combined")
+			case secretStr != "":
+				s, err := cipher.SHA256FromHex(secretStr)
+				if err != nil {
+					return fmt.Errorf("invalid secret: %v", err)
+				}
+				secret = &s
+				hashlock = cipher.SumSHA256(s[:])
+			case hashlockStr != "":
+				hashlock, err = cipher.SHA256FromHex(hashlockStr)
+				if err != nil {
+					return fmt.Errorf("invalid hashlock: %v", err)
+				}
+			default:
+				s := cipher.SumSHA256(cipher.RandByte(32))
+				secret = &s
+				hashlock = cipher.SumSHA256(s[:])
+			}
+
+			skyRecipient, err := htlcSwapSkycoinAddressFlag(c, "sky-recipient")
+			if err != nil {
+				return err
+			}
+
+			skySender, err := htlcSwapSkycoinAddressFlag(c, "sky-sender")
+			if err != nil {
+				return err
+			}
+
+			skyLockHeight, err := c.Flags().GetUint64("sky-lock-height")
+			if err != nil {
+				return err
+			}
+
+			btcRecipient, err := htlcSwapBitcoinAddressFlag(c, "btc-recipient")
+			if err != nil {
+				return err
+			}
+
+			btcRefund, err := htlcSwapBitcoinAddressFlag(c, "btc-refund")
+			if err != nil {
+				return err
+			}
+
+			btcLockTime, err := c.Flags().GetUint32("btc-lock-time")
+			if err != nil {
+				return err
+			}
+
+			h := coin.HTLC{
+				Hashlock:   hashlock,
+				Recipient:  skyRecipient,
+				Sender:     skySender,
+				LockHeight: skyLockHeight,
+			}
+
+			swap, err := NewHTLCSwap(secret, hashlock, h, btcRecipient, btcRefund, btcLockTime)
+			if err != nil {
+				return err
+			}
+
+			// The swap is generated offline, the head block is only checked if the node is reachable
+			var headSeq *uint64
+			if progress, err := apiClient.BlockchainProgress(); err == nil {
+				headSeq = &progress.Current
+			}
+
+			for _, w := range htlcSwapActivationWarnings(h, headSeq) {
+				fmt.Fprintln(os.Stderr, "Warning:", w)
+			}
+
+			return printJSON(swap)
+		},
+	}
+
+	htlcSwapCmd.Flags().String("secret", "", "Hex encoded 32 byte secret. A random secret is generated if neither the secret nor the hashlock is provided")
+	htlcSwapCmd.Flags().String("hashlock", "", "Hex encoded SHA256 of the secret, for the counterparty that does not know the secret")
+	htlcSwapCmd.Flags().String("sky-recipient", "", "Skycoin address that can claim the skycoin HTLC with the secret")
+	htlcSwapCmd.Flags().String("sky-sender", "", "Skycoin address that locks the coins, and can refund them after the lock height")
+	htlcSwapCmd.Flags().Uint64("sky-lock-height", 0, "Block seq after which the skycoin HTLC can be refunded")
+	htlcSwapCmd.Flags().String("btc-recipient", "", "Bitcoin P2PKH address that can claim the bitcoin HTLC with the secret")
+	htlcSwapCmd.Flags().String("btc-refund", "", "Bitcoin P2PKH address that can refund the bitcoin HTLC after the lock time")
+	htlcSwapCmd.Flags().Uint32("btc-lock-time", 0, "Block height or unix time after which the bitcoin HTLC can be refunded, as used by OP_CHECKLOCKTIMEVERIFY")
+
+	return htlcSwapCmd
+}
+
+func htlcSwapSkycoinAddressFlag(c *gcli.Command, name string) (cipher.Address, error) {
+	s, err := c.Flags().GetString(name)
+	if err != nil {
+		return cipher.Address{}, err
+	}
+
+	if s == "" {
+		return cipher.Address{}, fmt.Errorf("missing %s", name)
+	}
+
+	addr, err := cipher.DecodeBase58Address(s)
+	if err != nil {
+		return cipher.Address{}, fmt.Errorf("invalid %s: %v", name, err)
+	}
+
+	return addr, nil
+}
+
+func htlcSwapBitcoinAddressFlag(c *gcli.Command, name string) (cipher.BitcoinAddress, error) {
+	s, err := c.Flags().GetString(name)
+	if err != nil {
+		return cipher.BitcoinAddress{}, err
+	}
+
+	if s == "" {
+		return cipher.BitcoinAddress{}, fmt.Errorf("missing %s", name)
+	}
+
+	addr, err := cipher.DecodeBase58BitcoinAddress(s)
+	if err != nil {
+		return cipher.BitcoinAddress{}, fmt.Errorf("invalid %s: %v", name, err)
+	}
+
+	return addr, nil
+}
+
+// NewHTLCSwap creates the matching skycoin and bitcoin HTLCs of an atomic swap
+func NewHTLCSwap(secret *cipher.SHA256, hashlock cipher.SHA256, h coin.HTLC, btcRecipient, btcRefund cipher.BitcoinAddress, btcLockTime uint32) (*HTLCSwap, error) {
+	if secret != nil && cipher.SumSHA256(secret[:]) != hashlock {
+		return nil, errors.New("secret does not match the hashlock")
+	}
+
+	if h.Hashlock != hashlock {
+		return nil, errors.New("skycoin HTLC hashlock does not match the hashlock")
+	}
+
+	if err := h.Verify(); err != nil {
+		return nil, err
+	}
+
+	if btcRecipient.Null() {
+		return nil, errors.New("bitcoin recipient is the null address")
+	}
+
+	if btcRefund.Null() {
+		return nil, errors.New("bitcoin refund is the null address")
+	}
+
+	if btcLockTime == 0 {
+		return nil, errors.New("bitcoin lock time is zero")
+	}
+
+	script := BitcoinHTLCScript(hashlock, btcRecipient, btcRefund, btcLockTime)
+
+	swap := &HTLCSwap{
+		Hashlock: hashlock.Hex(),
+		Skycoin: HTLCSwapSkycoin{
+			Address:  h.Address().String(),
+			Contract: api.NewHTLCContract(h),
+		},
+		Bitcoin: HTLCSwapBitcoin{
+			Address:      BitcoinP2SHAddress(script).String(),
+			RedeemScript: hex.EncodeToString(script),
+			Recipient:    btcRecipient.String(),
+			Refund:       btcRefund.String(),
+			LockTime:     btcLockTime,
+		},
+	}
+
+	if secret != nil {
+		swap.Secret = secret.Hex()
+	}
+
+	return swap, nil
+}
+
+// htlcSwapActivationWarnings returns warnings about a skycoin HTLC that the node would refuse to create,
+// because of the HTLC activation height. headSeq is the seq of the head block of the node, nil if unknown
+func htlcSwapActivationWarnings(h coin.HTLC, headSeq *uint64) []string {
+	var warnings []string
+
+	if h.LockHeight < params.HTLCActivationHeight {
+		warnings = append(warnings, fmt.Sprintf("the skycoin HTLC lock height %d is before the HTLC activation height %d. HTLC outputs can't be refunded before the activation height, and the node refuses to create this HTLC", h.LockHeight, params.HTLCActivationHeight))
+	}
+
+	if headSeq != nil && *headSeq+1 < params.HTLCActivationHeight {
+		warnings = append(warnings, fmt.Sprintf("the node's head block seq %d is before the HTLC activation height %d. The node refuses to create HTLCs until the next block reaches the activation height", *headSeq, params.HTLCActivationHeight))
+	}
+
+	return warnings
+}
+
+// BitcoinHTLCScript creates the redeem script of a bitcoin HTLC.
+// The recipient claims the coins with <sig> <pubkey> <secret> OP_TRUE,
+// and the refund address refunds them after the lock time with <sig> <pubkey> OP_FALSE:
+//
+//	OP_IF
+//	  OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 <hashlock> OP_EQUALVERIFY OP_DUP OP_HASH160 <recipient>
+//	OP_ELSE
+//	  <lock time> OP_CHECKLOCKTIMEVERIFY OP_DROP OP_DUP OP_HASH160 <refund>
+//	OP_ENDIF
+//	OP_EQUALVERIFY OP_CHECKSIG
+func BitcoinHTLCScript(hashlock cipher.SHA256, recipient, refund cipher.BitcoinAddress, lockTime uint32) []byte {
+	var script []byte
+
+	script = append(script, opIf, opSize)
+	script = appendScriptNum(script, int64(len(cipher.SHA256{})))
+	script = append(script, opEqualVerify, opSHA256)
+	script = appendScriptData(script, hashlock[:])
+	script = append(script, opEqualVerify, opDup, opHash160)
+	script = appendScriptData(script, recipient.Key[:])
+
+	script = append(script, opElse)
+	script = appendScriptNum(script, int64(lockTime))
+	script = append(script, opCheckLockTimeVerify, opDrop, opDup, opHash160)
+	script = appendScriptData(script, refund.Key[:])
+
+	script = append(script, opEndIf, opEqualVerify, opCheckSig)
+
+	return script
+}
+
+// BitcoinP2SHAddress returns the mainnet pay-to-script-hash address of a redeem script
+func BitcoinP2SHAddress(script []byte) cipher.BitcoinAddress {
+	h := cipher.SumSHA256(script)
+	return cipher.BitcoinAddress{
+		Version: bitcoinP2SHVersion,
+		Key:     cipher.HashRipemd160(h[:]),
+	}
+}
+
+// appendScriptData appends a push of less than 76 bytes of data
+func appendScriptData(script, data []byte) []byte {
+	script = append(script, byte(len(data)))
+	return append(script, data...)
+}
+
+// appendScriptNum appends the minimal push of a positive number
+func appendScriptNum(script []byte, n int64) []byte {
+	if n <= 16 {
+		if n == 0 {
+			return append(script, 0x00)
+		}
+		// OP_1 to OP_16
+		return append(script, byte(0x50+n))
+	}
+
+	var b []byte
+	for n > 0 {
+		b = append(b, byte(n&0xff))
+		n >>= 8
+	}
+
+	// The sign bit is the high bit of the last byte
+	if b[len(b)-1]&0x80 != 0 {
+		b = append(b, 0x00)
+	}
+
+	return appendScriptData(script, b)
+}
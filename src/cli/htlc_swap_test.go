@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestBitcoinHTLCScript(t *testing.T) {
+	hashlock := cipher.SumSHA256([]byte("secret"))
+	recipient := cipher.MustDecodeBase58BitcoinAddress("1Q1pE5vPGEEMqRcVRMbtBK842Y6Pzo6nK9")
+	refund := cipher.MustDecodeBase58BitcoinAddress("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+
+	cases := []struct {
+		name     string
+		lockTime uint32
+		push     string
+	}{
+		{
+			name:     "small number",
+			lockTime: 10,
+			push:     "5a",
+		},
+		{
+			name:     "sign bit",
+			lockTime: 128,
+			push:     "028000",
+		},
+		{
+			name:     "block height",
+			lockTime: 500000,
+			push:     "0320a107",
+		},
+		{
+			name:     "unix time",
+			lockTime: 1546300800,
+			push:     "0480ad2a5c",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			expect := "63" + "82" + "0120" + "88" + "a8" + "20" + hashlock.Hex() +
+				"88" + "76" + "a9" + "14" + hex.EncodeToString(recipient.Key[:]) +
+				"67" + tc.push + "b1" + "75" + "76" + "a9" + "14" + hex.EncodeToString(refund.Key[:]) +
+				"68" + "88" + "ac"
+
+			script := BitcoinHTLCScript(hashlock, recipient, refund, tc.lockTime)
+			require.Equal(t, expect, hex.EncodeToString(script))
+
+			addr := BitcoinP2SHAddress(script)
+			require.Equal(t, byte(0x05), addr.Version)
+			require.Equal(t, byte('3'), addr.String()[0])
+		})
+	}
+}
+
+func TestNewHTLCSwap(t *testing.T) {
+	secret := testutil.RandSHA256(t)
+	hashlock := cipher.SumSHA256(secret[:])
+	h := coin.HTLC{
+		Hashlock:   hashlock,
+		Recipient:  testutil.MakeAddress(),
+		Sender:     testutil.MakeAddress(),
+		LockHeight: 1000,
+	}
+	recipient := cipher.MustDecodeBase58BitcoinAddress("1Q1pE5vPGEEMqRcVRMbtBK842Y6Pzo6nK9")
+	refund := cipher.MustDecodeBase58BitcoinAddress("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+
+	swap, err := NewHTLCSwap(&secret, hashlock, h, recipient, refund, 500000)
+	require.NoError(t, err)
+
+	script := BitcoinHTLCScript(hashlock, recipient, refund, 500000)
+	require.Equal(t, HTLCSwap{
+		Secret:   secret.Hex(),
+		Hashlock: hashlock.Hex(),
+		Skycoin: HTLCSwapSkycoin{
+			Address:  h.Address().String(),
+			Contract: api.NewHTLCContract(h),
+		},
+		Bitcoin: HTLCSwapBitcoin{
+			Address:      BitcoinP2SHAddress(script).String(),
+			RedeemScript: hex.EncodeToString(script),
+			Recipient:    recipient.String(),
+			Refund:       refund.String(),
+			LockTime:     500000,
+		},
+	}, *swap)
+
+	// The counterparty only knows the hashlock
+	swap2, err := NewHTLCSwap(nil, hashlock, h, recipient, refund, 500000)
+	require.NoError(t, err)
+	require.Empty(t, swap2.Secret)
+	require.Equal(t, swap.Skycoin, swap2.Skycoin)
+	require.Equal(t, swap.Bitcoin, swap2.Bitcoin)
+
+	wrongSecret := testutil.RandSHA256(t)
+	_, err = NewHTLCSwap(&wrongSecret, hashlock, h, recipient, refund, 500000)
+	require.EqualError(t, err, "secret does not match the hashlock")
+
+	h2 := h
+	h2.LockHeight = 0
+	_, err = NewHTLCSwap(&secret, hashlock, h2, recipient, refund, 500000)
+	require.EqualError(t, err, "HTLC lock height is zero")
+
+	_, err = NewHTLCSwap(&secret, hashlock, h, recipient, refund, 0)
+	require.EqualError(t, err, "bitcoin lock time is zero")
+}
+
+func TestHTLCSwapActivationWarnings(t *testing.T) {
+	h := coin.HTLC{
+		Hashlock:   testutil.RandSHA256(t),
+		Recipient:  testutil.MakeAddress(),
+		Sender:     testutil.MakeAddress(),
+		LockHeight: params.HTLCActivationHeight,
+	}
+
+	activated := params.HTLCActivationHeight - 1
+	require.Empty(t, htlcSwapActivationWarnings(h, &activated))
+	require.Empty(t, htlcSwapActivationWarnings(h, nil))
+
+	notActivated := params.HTLCActivationHeight - 2
+	require.Equal(t, []string{
+		fmt.Sprintf("the node's head block seq %d is before the HTLC activation height %d. The node refuses to create HTLCs until the next block reaches the activation height", notActivated, params.HTLCActivationHeight),
+	}, htlcSwapActivationWarnings(h, &notActivated))
+
+	h.LockHeight = params.HTLCActivationHeight - 1
+	require.Equal(t, []string{
+		fmt.Sprintf("the skycoin HTLC lock height %d is before the HTLC activation height %d. HTLC outputs can't be refunded before the activation height, and the node refuses to create this HTLC", h.LockHeight, params.HTLCActivationHeight),
+	}, htlcSwapActivationWarnings(h, nil))
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func connectPeerCmd() *cobra.Command {
+	return &cobra.Command{
+		Short:                 "Connect to a peer",
+		Long:                  "Add a peer to the node's peer list and start an outgoing connection to it. Use the status command or the /network/connections API to follow the connection.",
+		Use:                   "connectPeer [ip:port]",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return apiClient.Connect(args[0])
+		},
+	}
+}
+
+func addPeerCmd() *cobra.Command {
+	return &cobra.Command{
+		Short:                 "Add a peer to the peer list",
+		Use:                   "addPeer [ip:port]",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return apiClient.AddPeer(args[0])
+		},
+	}
+}
+
+func removePeerCmd() *cobra.Command {
+	return &cobra.Command{
+		Short:                 "Remove a peer from the peer list",
+		Long:                  "Remove a peer from the peer list. Peers from the node's default connections are added back when the node restarts.",
+		Use:                   "removePeer [ip:port]",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return apiClient.RemovePeer(args[0])
+		},
+	}
+}
+
+func trustPeerCmd() *cobra.Command {
+	trustPeerCmd := &cobra.Command{
+		Short:                 "Mark a peer as trusted",
+		Use:                   "trustPeer [ip:port]",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			untrust, err := c.Flags().GetBool("untrust")
+			if err != nil {
+				return err
+			}
+
+			return apiClient.TrustPeer(args[0], !untrust)
+		},
+	}
+
+	trustPeerCmd.Flags().BoolP("untrust", "u", false, "Mark the peer as untrusted instead")
+	return trustPeerCmd
+}
+
+func privatePeerCmd() *cobra.Command {
+	privatePeerCmd := &cobra.Command{
+		Short:                 "Mark a peer as private",
+		Long:                  "Mark a peer as private. Private peers are not shared with other peers.",
+		Use:                   "privatePeer [ip:port]",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			public, err := c.Flags().GetBool("public")
+			if err != nil {
+				return err
+			}
+
+			return apiClient.SetPeerPrivate(args[0], !public)
+		},
+	}
+
+	privatePeerCmd.Flags().BoolP("public", "p", false, "Mark the peer as public instead")
+	return privatePeerCmd
+}
+
+func resetPeerRetriesCmd() *cobra.Command {
+	return &cobra.Command{
+		Short:                 "Clear the connection retry counter of a peer",
+		Long:                  "Clear the connection retry counter of a peer, so that the node tries to connect to it again without waiting. If no peer is given, the retry counters of all peers are cleared.",
+		Use:                   "resetPeerRetries [ip:port]",
+		Args:                  cobra.MaximumNArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			var addr string
+			if len(args) == 1 {
+				addr = args[0]
+			}
+
+			return apiClient.ResetPeerRetries(addr)
+		},
+	}
+}
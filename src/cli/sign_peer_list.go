@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon/pex"
+)
+
+func signPeerListCmd() *cobra.Command {
+	signPeerListCmd := &cobra.Command{
+		Short: "Sign a peers list file",
+		Use:   "signPeerList [flags] [peers file] [secret key]",
+		Long: fmt.Sprintf(`Sign a peers.txt file for nodes that download it with -peerlist-pubkey.
+    The signature is written to the peers file path with a %s suffix,
+    which must be published next to the peers file. The signature covers the
+    current time, and nodes refuse a peers list signed longer ago than their
+    -peerlist-max-age, so the peers file must be signed again periodically.
+
+    Use caution when passing the secret key. If you have command history enabled
+    the secret key can be recovered from the history log.`, pex.PeerListSignatureSuffix),
+		Args:                  cobra.ExactArgs(2),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			sigFile, err := c.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+			if sigFile == "" {
+				sigFile = args[0] + pex.PeerListSignatureSuffix
+			}
+
+			seckey, err := cipher.SecKeyFromHex(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid secret key: %v", err)
+			}
+
+			body, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			sig, err := pex.SignPeerList(body, seckey, time.Now())
+			if err != nil {
+				return err
+			}
+
+			if err := ioutil.WriteFile(sigFile, []byte(sig), 0644); err != nil {
+				return err
+			}
+
+			fmt.Println(sigFile)
+			return nil
+		},
+	}
+
+	signPeerListCmd.Flags().StringP("output", "o", "", "Write the signature to this file instead")
+	return signPeerListCmd
+}
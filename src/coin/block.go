@@ -74,6 +74,16 @@ func NewBlock(prev Block, currentTime uint64, uxHash cipher.SHA256, txns Transac
 	}, nil
 }
 
+// NewEmptyBlock creates a block without transactions, which the block publisher creates as a heartbeat.
+// Blocks without transactions are only valid from the empty block activation height
+func NewEmptyBlock(prev Block, currentTime uint64, uxHash cipher.SHA256) *Block {
+	var body BlockBody
+	return &Block{
+		Head: NewBlockHeader(prev.Head, uxHash, currentTime, 0, body),
+		Body: body,
+	}
+}
+
 // NewGenesisBlock creates genesis block
 func NewGenesisBlock(genesisAddr cipher.Address, genesisCoins, timestamp uint64) (*Block, error) {
 	txn := Transaction{}
@@ -90,6 +90,24 @@ func TestNewBlock(t *testing.T) {
 	require.Equal(t, b.Head.UxHash, uxHash)
 }
 
+func TestNewEmptyBlock(t *testing.T) {
+	prev := Block{Head: BlockHeader{Version: 0x02, Time: 100, BkSeq: 98}}
+	uxHash := testutil.RandSHA256(t)
+
+	b := NewEmptyBlock(prev, 133, uxHash)
+	require.Empty(t, b.Body.Transactions)
+	require.Equal(t, uint64(0), b.Head.Fee)
+	require.Equal(t, b.Body.Hash(), b.Head.BodyHash)
+	require.Equal(t, prev.HashHeader(), b.Head.PrevHash)
+	require.Equal(t, uint64(133), b.Head.Time)
+	require.Equal(t, prev.Head.BkSeq+1, b.Head.BkSeq)
+	require.Equal(t, uxHash, b.Head.UxHash)
+
+	require.Panics(t, func() {
+		NewEmptyBlock(prev, 100, uxHash)
+	})
+}
+
 func TestBlockHashHeader(t *testing.T) {
 	uxHash := testutil.RandSHA256(t)
 	b, err := makeNewBlock(uxHash)
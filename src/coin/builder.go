@@ -0,0 +1,373 @@
+package coin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// TxnSigner signs the inputs of transactions built by TxnBuilder.
+// Implementations can keep the secret keys elsewhere, such as in a hardware wallet
+type TxnSigner interface {
+	// SignHash signs hash with the secret key of addr, the owner of the input being signed
+	SignHash(addr cipher.Address, hash cipher.SHA256) (cipher.Sig, error)
+}
+
+// SecKeySigner is a TxnSigner of secret keys indexed by their address
+type SecKeySigner map[cipher.Address]cipher.SecKey
+
+// NewSecKeySigner creates a SecKeySigner of keys
+func NewSecKeySigner(keys ...cipher.SecKey) (SecKeySigner, error) {
+	s := make(SecKeySigner, len(keys))
+	for _, k := range keys {
+		addr, err := cipher.AddressFromSecKey(k)
+		if err != nil {
+			return nil, err
+		}
+		s[addr] = k
+	}
+
+	return s, nil
+}
+
+// SignHash implements TxnSigner
+func (s SecKeySigner) SignHash(addr cipher.Address, hash cipher.SHA256) (cipher.Sig, error) {
+	k, ok := s[addr]
+	if !ok {
+		return cipher.Sig{}, fmt.Errorf("No secret key for address %s", addr)
+	}
+
+	return cipher.SignHash(hash, k)
+}
+
+// builderOutput is an output added to a TxnBuilder
+type builderOutput struct {
+	address cipher.Address
+	coins   uint64
+	hours   uint64
+	// set if the hours are distributed by the builder
+	autoHours bool
+}
+
+// TxnBuilder builds and signs transactions for programs that embed the coin package,
+// so that they do not have to push inputs and outputs, distribute hours and sign in the right order.
+//
+// The hours of the inputs, less the fee required by the burn factor and the hours of the outputs
+// added with explicit hours, are distributed to the outputs added without hours and to the change output,
+// proportional to their coins. Transactions are built with TxnTypeDefault, or with TxnTypeReplayProtected
+// for ForChain. Spending HTLC outputs and Schnorr signatures are not supported.
+//
+// Methods that add to the builder return the builder, so that calls can be chained.
+// The first error is kept and returned by the methods that build the transaction
+type TxnBuilder struct {
+	headTime    uint64
+	burnFactor  uint32
+	uxIn        UxArray
+	outputs     []builderOutput
+	changeAddr  *cipher.Address
+	genesisHash *cipher.SHA256
+	err         error
+}
+
+// NewTxnBuilder creates a TxnBuilder. headTime is the time of the head block, at which the hours of the inputs
+// are calculated. burnFactor is the inverse of the fraction of the input hours burned as a fee,
+// such as params.UserVerifyTxn.BurnFactor
+func NewTxnBuilder(headTime uint64, burnFactor uint32) *TxnBuilder {
+	b := &TxnBuilder{
+		headTime:   headTime,
+		burnFactor: burnFactor,
+	}
+
+	if burnFactor == 0 {
+		b.err = errors.New("Burn factor must be greater than 0")
+	}
+
+	return b
+}
+
+// Err returns the first error of the methods that add to the builder
+func (b *TxnBuilder) Err() error {
+	return b.err
+}
+
+// AddInput adds an unspent output to spend
+func (b *TxnBuilder) AddInput(ux UxOut) *TxnBuilder {
+	return b.AddInputs(UxArray{ux})
+}
+
+// AddInputs adds unspent outputs to spend
+func (b *TxnBuilder) AddInputs(uxa UxArray) *TxnBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	for _, ux := range uxa {
+		if len(b.uxIn) >= math.MaxUint16 {
+			b.err = errors.New("Too many inputs")
+			return b
+		}
+
+		h := ux.Hash()
+		for _, in := range b.uxIn {
+			if in.Hash() == h {
+				b.err = errors.New("Duplicate input")
+				return b
+			}
+		}
+
+		b.uxIn = append(b.uxIn, ux)
+	}
+
+	return b
+}
+
+// AddOutput adds an output with an explicit amount of hours
+func (b *TxnBuilder) AddOutput(addr cipher.Address, coins, hours uint64) *TxnBuilder {
+	return b.addOutput(builderOutput{
+		address: addr,
+		coins:   coins,
+		hours:   hours,
+	})
+}
+
+// AddOutputAutoHours adds an output whose hours are distributed by the builder, proportional to its coins
+func (b *TxnBuilder) AddOutputAutoHours(addr cipher.Address, coins uint64) *TxnBuilder {
+	return b.addOutput(builderOutput{
+		address:   addr,
+		coins:     coins,
+		autoHours: true,
+	})
+}
+
+func (b *TxnBuilder) addOutput(o builderOutput) *TxnBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	// Leave room for the change output
+	if len(b.outputs) >= math.MaxUint16-1 {
+		b.err = errors.New("Too many outputs")
+		return b
+	}
+
+	if o.coins == 0 {
+		b.err = errors.New("Zero coin output")
+		return b
+	}
+
+	b.outputs = append(b.outputs, o)
+	return b
+}
+
+// ChangeAddress sets the address of the change output, which receives the coins of the inputs
+// that are not sent to the other outputs. Its hours are distributed by the builder, proportional to its coins.
+// There is no change output if all of the coins are sent to the other outputs
+func (b *TxnBuilder) ChangeAddress(addr cipher.Address) *TxnBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	b.changeAddr = &addr
+	return b
+}
+
+// ForChain makes the transaction replay protected for the chain whose genesis block hash is genesisHash
+func (b *TxnBuilder) ForChain(genesisHash cipher.SHA256) *TxnBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	b.genesisHash = &genesisHash
+	return b
+}
+
+// build creates the unsigned transaction and returns it with the hours of its inputs
+func (b *TxnBuilder) build() (*Transaction, uint64, error) {
+	if b.err != nil {
+		return nil, 0, b.err
+	}
+
+	if len(b.uxIn) == 0 {
+		return nil, 0, errors.New("No inputs")
+	}
+	if len(b.outputs) == 0 {
+		return nil, 0, errors.New("No outputs")
+	}
+
+	var inCoins, inHours uint64
+	for _, ux := range b.uxIn {
+		var err error
+		inCoins, err = AddUint64(inCoins, ux.Body.Coins)
+		if err != nil {
+			return nil, 0, errors.New("Input coins overflow")
+		}
+
+		hours, err := ux.CoinHours(b.headTime)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		inHours, err = AddUint64(inHours, hours)
+		if err != nil {
+			return nil, 0, errors.New("Input hours overflow")
+		}
+	}
+
+	outputs := make([]builderOutput, len(b.outputs))
+	copy(outputs, b.outputs)
+
+	var outCoins, explicitHours uint64
+	for _, o := range outputs {
+		var err error
+		outCoins, err = AddUint64(outCoins, o.coins)
+		if err != nil {
+			return nil, 0, errors.New("Output coins overflow")
+		}
+
+		explicitHours, err = AddUint64(explicitHours, o.hours)
+		if err != nil {
+			return nil, 0, errors.New("Output hours overflow")
+		}
+	}
+
+	if outCoins > inCoins {
+		return nil, 0, errors.New("Insufficient coins")
+	}
+
+	if changeCoins := inCoins - outCoins; changeCoins > 0 {
+		if b.changeAddr == nil {
+			return nil, 0, errors.New("Change address is required to spend the remaining coins of the inputs")
+		}
+
+		outputs = append(outputs, builderOutput{
+			address:   *b.changeAddr,
+			coins:     changeCoins,
+			autoHours: true,
+		})
+	}
+
+	fee := RequiredFee(inHours, b.burnFactor)
+	if fee == 0 {
+		return nil, 0, errors.New("Inputs have no coin hours to pay the fee")
+	}
+
+	if explicitHours > inHours-fee {
+		return nil, 0, errors.New("Insufficient coin hours for the outputs and fee")
+	}
+
+	// Distribute the remaining hours to the outputs without explicit hours
+	var autoCoins []uint64
+	for _, o := range outputs {
+		if o.autoHours {
+			autoCoins = append(autoCoins, o.coins)
+		}
+	}
+
+	if len(autoCoins) != 0 {
+		autoHours, err := DistributeCoinHoursProportional(autoCoins, inHours-fee-explicitHours)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		j := 0
+		for i := range outputs {
+			if outputs[i].autoHours {
+				outputs[i].hours = autoHours[j]
+				j++
+			}
+		}
+	}
+
+	txn := &Transaction{}
+	for _, ux := range b.uxIn {
+		txn.PushInput(ux.Hash())
+	}
+	for _, o := range outputs {
+		txn.PushOutput(o.address, o.coins, o.hours)
+	}
+
+	txn.InnerHash = txn.HashInner()
+	if b.genesisHash != nil {
+		txn.Type = TxnTypeReplayProtected
+	}
+
+	return txn, inHours, nil
+}
+
+// Unsigned returns the transaction without signatures
+func (b *TxnBuilder) Unsigned() (*Transaction, error) {
+	txn, _, err := b.build()
+	return txn, err
+}
+
+// Fee returns the coin hours burned by the transaction, before signing
+func (b *TxnBuilder) Fee() (uint64, error) {
+	txn, inHours, err := b.build()
+	if err != nil {
+		return 0, err
+	}
+
+	outHours, err := txn.OutputHours()
+	if err != nil {
+		return 0, err
+	}
+
+	return inHours - outHours, nil
+}
+
+// Size returns the size of the signed transaction, before signing
+func (b *TxnBuilder) Size() (uint32, error) {
+	txn, _, err := b.build()
+	if err != nil {
+		return 0, err
+	}
+
+	// Signatures have a fixed size
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	return txn.Size()
+}
+
+// Sign builds the transaction and signs its inputs with signer.
+// The signed transaction is checked with Verify, VerifyInputForChain and VerifyUserConstraints
+func (b *TxnBuilder) Sign(signer TxnSigner) (*Transaction, error) {
+	txn, _, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+
+	var genesisHash cipher.SHA256
+	innerHash := txn.InnerHash
+	if b.genesisHash != nil {
+		genesisHash = *b.genesisHash
+		innerHash = txn.ChainInnerHash(genesisHash)
+	}
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	for i, ux := range b.uxIn {
+		sig, err := signer.SignHash(ux.Body.Address, cipher.AddSHA256(innerHash, txn.In[i]))
+		if err != nil {
+			return nil, err
+		}
+		txn.Sigs[i] = sig
+	}
+
+	if err := txn.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	if err := txn.Verify(); err != nil {
+		return nil, err
+	}
+
+	if err := txn.VerifyInputForChain(b.uxIn, genesisHash); err != nil {
+		return nil, err
+	}
+
+	if err := txn.VerifyUserConstraints(); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
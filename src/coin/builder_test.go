@@ -0,0 +1,185 @@
+package coin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+// badSigner signs with a key that does not own the inputs
+type badSigner struct{}
+
+func (badSigner) SignHash(addr cipher.Address, hash cipher.SHA256) (cipher.Sig, error) {
+	_, s := cipher.GenerateKeyPair()
+	return cipher.SignHash(hash, s)
+}
+
+func TestTxnBuilderSign(t *testing.T) {
+	ux, s := makeUxOutWithSecret(t)
+	ux2, s2 := makeUxOutWithSecret(t)
+	signer, err := NewSecKeySigner(s, s2)
+	require.NoError(t, err)
+
+	headTime := ux.Head.Time + 3600
+	inHours, err := UxArray{ux, ux2}.CoinHours(headTime)
+	require.NoError(t, err)
+
+	to := makeAddress()
+	to2 := makeAddress()
+	change := makeAddress()
+
+	b := NewTxnBuilder(headTime, 2).
+		AddInputs(UxArray{ux, ux2}).
+		AddOutputAutoHours(to, 5e5).
+		AddOutput(to2, 2e5, 10).
+		ChangeAddress(change)
+	require.NoError(t, b.Err())
+
+	fee, err := b.Fee()
+	require.NoError(t, err)
+	require.Equal(t, RequiredFee(inHours, 2), fee)
+
+	size, err := b.Size()
+	require.NoError(t, err)
+
+	txn, err := b.Sign(signer)
+	require.NoError(t, err)
+	require.Equal(t, TxnTypeDefault, txn.Type)
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInput(UxArray{ux, ux2}))
+
+	txnSize, err := txn.Size()
+	require.NoError(t, err)
+	require.Equal(t, size, txnSize)
+
+	require.Equal(t, []cipher.SHA256{ux.Hash(), ux2.Hash()}, txn.In)
+	require.Len(t, txn.Out, 3)
+	require.Equal(t, to2, txn.Out[1].Address)
+	require.Equal(t, uint64(10), txn.Out[1].Hours)
+	require.Equal(t, change, txn.Out[2].Address)
+	require.Equal(t, uint64(13e5), txn.Out[2].Coins)
+
+	// The hours left after the fee are distributed proportional to the coins
+	outHours, err := txn.OutputHours()
+	require.NoError(t, err)
+	require.Equal(t, inHours-fee, outHours)
+	require.True(t, txn.Out[2].Hours > txn.Out[0].Hours)
+
+	// The transaction is the same as one signed with SignInputs
+	txn2, err := b.Unsigned()
+	require.NoError(t, err)
+	require.Empty(t, txn2.Sigs)
+	txn2.SignInputs([]cipher.SecKey{s, s2})
+	require.NoError(t, txn2.UpdateHeader())
+	require.Equal(t, txn, txn2)
+
+	// No change output when all of the coins are spent
+	txn, err = NewTxnBuilder(headTime, 2).
+		AddInput(ux).
+		AddOutputAutoHours(to, ux.Body.Coins).
+		Sign(signer)
+	require.NoError(t, err)
+	require.Len(t, txn.Out, 1)
+}
+
+func TestTxnBuilderForChain(t *testing.T) {
+	ux, s := makeUxOutWithSecret(t)
+	signer, err := NewSecKeySigner(s)
+	require.NoError(t, err)
+	genesisHash := testutil.RandSHA256(t)
+
+	txn, err := NewTxnBuilder(ux.Head.Time, 2).
+		AddInput(ux).
+		AddOutputAutoHours(makeAddress(), ux.Body.Coins).
+		ForChain(genesisHash).
+		Sign(signer)
+	require.NoError(t, err)
+	require.Equal(t, TxnTypeReplayProtected, txn.Type)
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInputForChain(UxArray{ux}, genesisHash))
+	require.EqualError(t, txn.VerifyInputForChain(UxArray{ux}, testutil.RandSHA256(t)), "Signature not valid for output being spent")
+}
+
+func TestTxnBuilderErrors(t *testing.T) {
+	ux, s := makeUxOutWithSecret(t)
+	signer, err := NewSecKeySigner(s)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		b    *TxnBuilder
+		err  string
+	}{
+		{
+			name: "zero burn factor",
+			b:    NewTxnBuilder(ux.Head.Time, 0).AddInput(ux).AddOutputAutoHours(makeAddress(), ux.Body.Coins),
+			err:  "Burn factor must be greater than 0",
+		},
+		{
+			name: "no inputs",
+			b:    NewTxnBuilder(ux.Head.Time, 2).AddOutputAutoHours(makeAddress(), ux.Body.Coins),
+			err:  "No inputs",
+		},
+		{
+			name: "no outputs",
+			b:    NewTxnBuilder(ux.Head.Time, 2).AddInput(ux),
+			err:  "No outputs",
+		},
+		{
+			name: "duplicate input",
+			b:    NewTxnBuilder(ux.Head.Time, 2).AddInput(ux).AddInput(ux).AddOutputAutoHours(makeAddress(), ux.Body.Coins),
+			err:  "Duplicate input",
+		},
+		{
+			name: "zero coin output",
+			b:    NewTxnBuilder(ux.Head.Time, 2).AddInput(ux).AddOutput(makeAddress(), 0, 10),
+			err:  "Zero coin output",
+		},
+		{
+			name: "insufficient coins",
+			b:    NewTxnBuilder(ux.Head.Time, 2).AddInput(ux).AddOutputAutoHours(makeAddress(), ux.Body.Coins+1),
+			err:  "Insufficient coins",
+		},
+		{
+			name: "no change address",
+			b:    NewTxnBuilder(ux.Head.Time, 2).AddInput(ux).AddOutputAutoHours(makeAddress(), ux.Body.Coins-1),
+			err:  "Change address is required to spend the remaining coins of the inputs",
+		},
+		{
+			name: "insufficient hours",
+			b:    NewTxnBuilder(ux.Head.Time, 2).AddInput(ux).AddOutput(makeAddress(), ux.Body.Coins, ux.Body.Hours/2+1),
+			err:  "Insufficient coin hours for the outputs and fee",
+		},
+		{
+			name: "null address",
+			b:    NewTxnBuilder(ux.Head.Time, 2).AddInput(ux).AddOutputAutoHours(cipher.Address{}, ux.Body.Coins),
+			err:  "Transaction output is sent to the null address",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.b.Sign(signer)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+
+	// The inputs have no hours to pay the fee
+	ux2 := ux
+	ux2.Body.Hours = 0
+	_, err = NewTxnBuilder(ux2.Head.Time, 2).AddInput(ux2).AddOutputAutoHours(makeAddress(), ux2.Body.Coins).Unsigned()
+	require.EqualError(t, err, "Inputs have no coin hours to pay the fee")
+
+	b := NewTxnBuilder(ux.Head.Time, 2).AddInput(ux).AddOutputAutoHours(makeAddress(), ux.Body.Coins)
+
+	// The signer has no key for the input
+	_, err = b.Sign(SecKeySigner{})
+	require.EqualError(t, err, "No secret key for address "+ux.Body.Address.String())
+
+	// The signer signs with the wrong key
+	_, err = b.Sign(badSigner{})
+	require.EqualError(t, err, "Signature not valid for output being spent")
+}
@@ -0,0 +1,236 @@
+package coin
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+/*
+Hash-time-locked contracts (HTLC) lock coins to a hashlock and a timelock:
+- the recipient can claim the coins by revealing the preimage of the hashlock
+- the sender can refund the coins once the head block seq reaches the lock height
+
+Coins are locked by sending them to the HTLC address, which is the ripemd160 of the SHA256
+of the serialized contract. The contract is not published when the coins are locked.
+
+The transaction encoding is fixed, so a transaction that spends HTLC outputs has the
+TxnTypeHTLC type, and reveals the contract with HTLC witnesses appended to Sigs after the
+input signatures. A witness is 65 bytes, the size of a signature, and only reveals what
+can't be recovered from the signature of its input:
+- a claim reveals the preimage, the sender and the lock height. The recipient is the signer
+- a refund reveals the hashlock, the recipient and the lock height. The sender is the signer
+*/
+
+const (
+	// TxnTypeDefault is the type of transactions that only spend outputs with signatures
+	TxnTypeDefault uint8 = 0
+	// TxnTypeHTLC is the type of transactions that spend HTLC outputs
+	TxnTypeHTLC uint8 = 1
+	// TxnTypeSchnorr is the type of transactions that authorize their inputs with Schnorr signatures
+	TxnTypeSchnorr uint8 = 2
+	// TxnTypeReplayProtected is the type of transactions whose signatures commit to the genesis hash of their chain
+	TxnTypeReplayProtected uint8 = 3
+)
+
+const (
+	// HTLCClaim is the HTLC witness type of a claim by the recipient with the preimage
+	HTLCClaim uint8 = 1
+	// HTLCRefund is the HTLC witness type of a refund to the sender after the lock height
+	HTLCRefund uint8 = 2
+)
+
+// HTLC is a hash-time-locked contract
+type HTLC struct {
+	Hashlock   cipher.SHA256  // SHA256 of the secret preimage
+	Recipient  cipher.Address // can claim the coins with the preimage
+	Sender     cipher.Address // can refund the coins once the head block seq reaches LockHeight
+	LockHeight uint64
+}
+
+// Verify checks that the contract is well formed
+func (h HTLC) Verify() error {
+	if h.Hashlock.Null() {
+		return errors.New("HTLC hashlock is null")
+	}
+	if h.Recipient.Null() {
+		return errors.New("HTLC recipient is the null address")
+	}
+	if h.Sender.Null() {
+		return errors.New("HTLC sender is the null address")
+	}
+	if h.LockHeight == 0 {
+		return errors.New("HTLC lock height is zero")
+	}
+	return nil
+}
+
+// Address returns the address that the coins locked by the contract are sent to
+func (h HTLC) Address() cipher.Address {
+	r1 := cipher.SumSHA256(encoder.Serialize(h))
+	return cipher.Address{
+		Version: cipher.AddressVersion,
+		Key:     cipher.HashRipemd160(r1[:]),
+	}
+}
+
+// HTLCWitness reveals the contract of an HTLC output spent by a TxnTypeHTLC transaction
+type HTLCWitness struct {
+	Type         uint8          // HTLCClaim or HTLCRefund
+	Input        uint16         // index of the input spending the HTLC output
+	Secret       cipher.SHA256  // the preimage for a claim, the hashlock for a refund
+	Counterparty cipher.Address // the sender for a claim, the recipient for a refund
+	LockHeight   uint64
+}
+
+// NewHTLCClaimWitness creates the witness of a claim of an HTLC output spent by an input
+func NewHTLCClaimWitness(input uint16, h HTLC, preimage cipher.SHA256) HTLCWitness {
+	return HTLCWitness{
+		Type:         HTLCClaim,
+		Input:        input,
+		Secret:       preimage,
+		Counterparty: h.Sender,
+		LockHeight:   h.LockHeight,
+	}
+}
+
+// NewHTLCRefundWitness creates the witness of a refund of an HTLC output spent by an input
+func NewHTLCRefundWitness(input uint16, h HTLC) HTLCWitness {
+	return HTLCWitness{
+		Type:         HTLCRefund,
+		Input:        input,
+		Secret:       h.Hashlock,
+		Counterparty: h.Recipient,
+		LockHeight:   h.LockHeight,
+	}
+}
+
+// Contract returns the contract revealed by the witness, given the address that signed the input
+func (w HTLCWitness) Contract(signer cipher.Address) HTLC {
+	if w.Type == HTLCClaim {
+		return HTLC{
+			Hashlock:   cipher.SumSHA256(w.Secret[:]),
+			Recipient:  signer,
+			Sender:     w.Counterparty,
+			LockHeight: w.LockHeight,
+		}
+	}
+
+	return HTLC{
+		Hashlock:   w.Secret,
+		Recipient:  w.Counterparty,
+		Sender:     signer,
+		LockHeight: w.LockHeight,
+	}
+}
+
+// Sig encodes the witness in a signature sized entry of Transaction.Sigs.
+// The last byte is padding and is always zero
+func (w HTLCWitness) Sig() cipher.Sig {
+	var sig cipher.Sig
+	copy(sig[:], encoder.Serialize(w))
+	return sig
+}
+
+// HTLCWitnessFromSig decodes an HTLC witness from an entry of Transaction.Sigs
+func HTLCWitnessFromSig(sig cipher.Sig) (HTLCWitness, error) {
+	var w HTLCWitness
+	n := len(sig) - 1
+	if sig[n] != 0 {
+		return HTLCWitness{}, errors.New("HTLC witness padding is not zero")
+	}
+
+	if err := encoder.DeserializeRaw(sig[:n], &w); err != nil {
+		return HTLCWitness{}, err
+	}
+
+	switch w.Type {
+	case HTLCClaim, HTLCRefund:
+	default:
+		return HTLCWitness{}, errors.New("HTLC witness type invalid")
+	}
+
+	return w, nil
+}
+
+// PushHTLCWitness appends an HTLC witness after the input signatures and sets the transaction type to TxnTypeHTLC.
+// Witnesses must be pushed after signing the inputs, in the order of their inputs
+func (txn *Transaction) PushHTLCWitness(w HTLCWitness) {
+	txn.Type = TxnTypeHTLC
+	txn.Sigs = append(txn.Sigs, w.Sig())
+}
+
+// HTLCWitnesses returns the HTLC witnesses of a TxnTypeHTLC transaction.
+// Returns nil for other transaction types
+func (txn *Transaction) HTLCWitnesses() ([]HTLCWitness, error) {
+	if txn.Type != TxnTypeHTLC {
+		return nil, nil
+	}
+
+	if len(txn.Sigs) <= len(txn.In) {
+		return nil, errors.New("HTLC transaction has no HTLC witnesses")
+	}
+
+	sigs := txn.Sigs[len(txn.In):]
+	if len(sigs) > len(txn.In) {
+		return nil, errors.New("HTLC transaction has more HTLC witnesses than inputs")
+	}
+
+	witnesses := make([]HTLCWitness, len(sigs))
+	for i, sig := range sigs {
+		w, err := HTLCWitnessFromSig(sig)
+		if err != nil {
+			return nil, err
+		}
+
+		if int(w.Input) >= len(txn.In) {
+			return nil, errors.New("HTLC witness input index out of range")
+		}
+
+		if i > 0 && w.Input <= witnesses[i-1].Input {
+			return nil, errors.New("HTLC witnesses are not sorted by input index")
+		}
+
+		witnesses[i] = w
+	}
+
+	return witnesses, nil
+}
+
+// VerifyHTLCTimelocks checks that the HTLC outputs refunded by the transaction are
+// past their lock height, given the head block seq
+func (txn *Transaction) VerifyHTLCTimelocks(headSeq uint64) error {
+	witnesses, err := txn.HTLCWitnesses()
+	if err != nil {
+		return err
+	}
+
+	for _, w := range witnesses {
+		if w.Type == HTLCRefund && headSeq < w.LockHeight {
+			return errors.New("HTLC refund before the lock height")
+		}
+	}
+
+	return nil
+}
+
+// verifyHTLCInput checks that an input spending an HTLC output was signed by the recipient or
+// sender of the contract revealed by its witness, according to the witness type
+func verifyHTLCInput(address cipher.Address, sig cipher.Sig, hash cipher.SHA256, w HTLCWitness) error {
+	pubkey, err := cipher.PubKeyFromSig(sig, hash)
+	if err != nil {
+		return errors.New("Signature not valid for output being spent")
+	}
+
+	signer := cipher.AddressFromPubKey(pubkey)
+	if w.Contract(signer).Address() != address {
+		return errors.New("HTLC witness does not match the output being spent")
+	}
+
+	if err := cipher.VerifyAddressSignedHash(signer, sig, hash); err != nil {
+		return errors.New("Signature not valid for output being spent")
+	}
+
+	return nil
+}
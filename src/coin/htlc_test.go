@@ -0,0 +1,227 @@
+package coin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func makeHTLC(t *testing.T) (HTLC, cipher.SHA256, cipher.SecKey, cipher.SecKey) {
+	recipientPubKey, recipientSecKey := cipher.GenerateKeyPair()
+	senderPubKey, senderSecKey := cipher.GenerateKeyPair()
+	preimage := testutil.RandSHA256(t)
+
+	return HTLC{
+		Hashlock:   cipher.SumSHA256(preimage[:]),
+		Recipient:  cipher.AddressFromPubKey(recipientPubKey),
+		Sender:     cipher.AddressFromPubKey(senderPubKey),
+		LockHeight: 100,
+	}, preimage, recipientSecKey, senderSecKey
+}
+
+func makeHTLCUxOut(t *testing.T, h HTLC) UxOut {
+	return UxOut{
+		Head: UxHead{
+			Time:  100,
+			BkSeq: 2,
+		},
+		Body: UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        h.Address(),
+			Coins:          10e6,
+			Hours:          100,
+		},
+	}
+}
+
+func makeHTLCSpend(t *testing.T, ux UxOut, sk cipher.SecKey, w HTLCWitness) Transaction {
+	txn := Transaction{}
+	txn.PushInput(ux.Hash())
+	txn.PushOutput(makeAddress(), ux.Body.Coins, 50)
+	txn.SignInputs([]cipher.SecKey{sk})
+	txn.PushHTLCWitness(w)
+	require.NoError(t, txn.UpdateHeader())
+	return txn
+}
+
+func TestHTLCAddress(t *testing.T) {
+	h, _, _, _ := makeHTLC(t)
+	require.NoError(t, h.Verify())
+
+	addr := h.Address()
+	require.Equal(t, byte(0), addr.Version)
+	require.Equal(t, addr, h.Address())
+
+	h2 := h
+	h2.LockHeight++
+	require.NotEqual(t, addr, h2.Address())
+
+	h2 = h
+	h2.Hashlock = cipher.SHA256{}
+	require.EqualError(t, h2.Verify(), "HTLC hashlock is null")
+
+	h2 = h
+	h2.Recipient = cipher.Address{}
+	require.EqualError(t, h2.Verify(), "HTLC recipient is the null address")
+
+	h2 = h
+	h2.Sender = cipher.Address{}
+	require.EqualError(t, h2.Verify(), "HTLC sender is the null address")
+
+	h2 = h
+	h2.LockHeight = 0
+	require.EqualError(t, h2.Verify(), "HTLC lock height is zero")
+}
+
+func TestHTLCWitnessSig(t *testing.T) {
+	h, preimage, _, _ := makeHTLC(t)
+
+	for _, w := range []HTLCWitness{
+		NewHTLCClaimWitness(3, h, preimage),
+		NewHTLCRefundWitness(4, h),
+	} {
+		sig := w.Sig()
+		require.Equal(t, byte(0), sig[len(sig)-1])
+
+		w2, err := HTLCWitnessFromSig(sig)
+		require.NoError(t, err)
+		require.Equal(t, w, w2)
+	}
+
+	require.Equal(t, h, NewHTLCClaimWitness(0, h, preimage).Contract(h.Recipient))
+	require.Equal(t, h, NewHTLCRefundWitness(0, h).Contract(h.Sender))
+
+	sig := NewHTLCClaimWitness(0, h, preimage).Sig()
+	sig[len(sig)-1] = 1
+	_, err := HTLCWitnessFromSig(sig)
+	require.EqualError(t, err, "HTLC witness padding is not zero")
+
+	sig = NewHTLCClaimWitness(0, h, preimage).Sig()
+	sig[0] = 3
+	_, err = HTLCWitnessFromSig(sig)
+	require.EqualError(t, err, "HTLC witness type invalid")
+}
+
+func TestHTLCClaim(t *testing.T) {
+	h, preimage, recipientSecKey, senderSecKey := makeHTLC(t)
+	ux := makeHTLCUxOut(t, h)
+
+	txn := makeHTLCSpend(t, ux, recipientSecKey, NewHTLCClaimWitness(0, h, preimage))
+	require.Equal(t, TxnTypeHTLC, txn.Type)
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInput(UxArray{ux}))
+
+	// A claim can be made before and after the lock height
+	require.NoError(t, txn.VerifyHTLCTimelocks(0))
+	require.NoError(t, txn.VerifyHTLCTimelocks(h.LockHeight+1))
+
+	// The transaction survives serialization
+	txn2, err := TransactionDeserialize(txn.Serialize())
+	require.NoError(t, err)
+	require.Equal(t, txn, txn2)
+	require.NoError(t, txn2.VerifyInput(UxArray{ux}))
+
+	// Wrong preimage
+	txn = makeHTLCSpend(t, ux, recipientSecKey, NewHTLCClaimWitness(0, h, testutil.RandSHA256(t)))
+	require.NoError(t, txn.Verify())
+	require.EqualError(t, txn.VerifyInput(UxArray{ux}), "HTLC witness does not match the output being spent")
+
+	// Only the recipient can claim
+	txn = makeHTLCSpend(t, ux, senderSecKey, NewHTLCClaimWitness(0, h, preimage))
+	require.NoError(t, txn.Verify())
+	require.EqualError(t, txn.VerifyInput(UxArray{ux}), "HTLC witness does not match the output being spent")
+
+	// The HTLC output can't be spent without a witness
+	txn = Transaction{}
+	txn.PushInput(ux.Hash())
+	txn.PushOutput(makeAddress(), ux.Body.Coins, 50)
+	txn.SignInputs([]cipher.SecKey{recipientSecKey})
+	require.NoError(t, txn.UpdateHeader())
+	require.NoError(t, txn.Verify())
+	require.EqualError(t, txn.VerifyInput(UxArray{ux}), "Signature not valid for output being spent")
+
+	// A witness can't spend a regular output
+	regularUx, regularSecKey := makeUxOutWithSecret(t)
+	txn = makeHTLCSpend(t, regularUx, regularSecKey, NewHTLCClaimWitness(0, h, preimage))
+	require.NoError(t, txn.Verify())
+	require.EqualError(t, txn.VerifyInput(UxArray{regularUx}), "HTLC witness does not match the output being spent")
+}
+
+func TestHTLCRefund(t *testing.T) {
+	h, _, recipientSecKey, senderSecKey := makeHTLC(t)
+	ux := makeHTLCUxOut(t, h)
+
+	txn := makeHTLCSpend(t, ux, senderSecKey, NewHTLCRefundWitness(0, h))
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInput(UxArray{ux}))
+
+	// A refund can only be made once the head block reaches the lock height
+	require.EqualError(t, txn.VerifyHTLCTimelocks(h.LockHeight-1), "HTLC refund before the lock height")
+	require.NoError(t, txn.VerifyHTLCTimelocks(h.LockHeight))
+
+	// Only the sender can refund
+	txn = makeHTLCSpend(t, ux, recipientSecKey, NewHTLCRefundWitness(0, h))
+	require.NoError(t, txn.Verify())
+	require.EqualError(t, txn.VerifyInput(UxArray{ux}), "HTLC witness does not match the output being spent")
+
+	// The lock height is part of the contract
+	h2 := h
+	h2.LockHeight = 1
+	txn = makeHTLCSpend(t, ux, senderSecKey, NewHTLCRefundWitness(0, h2))
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyHTLCTimelocks(1))
+	require.EqualError(t, txn.VerifyInput(UxArray{ux}), "HTLC witness does not match the output being spent")
+}
+
+func TestHTLCTransactionVerify(t *testing.T) {
+	h, preimage, recipientSecKey, _ := makeHTLC(t)
+	ux := makeHTLCUxOut(t, h)
+
+	// HTLC transaction without witnesses
+	txn := makeHTLCSpend(t, ux, recipientSecKey, NewHTLCClaimWitness(0, h, preimage))
+	txn.Sigs = txn.Sigs[:1]
+	require.NoError(t, txn.UpdateHeader())
+	require.EqualError(t, txn.Verify(), "HTLC transaction has no HTLC witnesses")
+
+	// Witnesses in a default transaction
+	txn = makeHTLCSpend(t, ux, recipientSecKey, NewHTLCClaimWitness(0, h, preimage))
+	txn.Type = TxnTypeDefault
+	require.EqualError(t, txn.Verify(), "Invalid number of signatures")
+
+	// More witnesses than inputs
+	txn = makeHTLCSpend(t, ux, recipientSecKey, NewHTLCClaimWitness(0, h, preimage))
+	txn.PushHTLCWitness(NewHTLCClaimWitness(0, h, preimage))
+	require.NoError(t, txn.UpdateHeader())
+	require.EqualError(t, txn.Verify(), "HTLC transaction has more HTLC witnesses than inputs")
+
+	// Witness for an input that does not exist
+	txn = makeHTLCSpend(t, ux, recipientSecKey, NewHTLCClaimWitness(1, h, preimage))
+	require.EqualError(t, txn.Verify(), "HTLC witness input index out of range")
+
+	// Witnesses out of order
+	ux2 := makeHTLCUxOut(t, h)
+	txn = Transaction{}
+	txn.PushInput(ux.Hash())
+	txn.PushInput(ux2.Hash())
+	txn.PushOutput(makeAddress(), ux.Body.Coins+ux2.Body.Coins, 50)
+	txn.SignInputs([]cipher.SecKey{recipientSecKey, recipientSecKey})
+	txn.PushHTLCWitness(NewHTLCClaimWitness(1, h, preimage))
+	txn.PushHTLCWitness(NewHTLCClaimWitness(0, h, preimage))
+	require.NoError(t, txn.UpdateHeader())
+	require.EqualError(t, txn.Verify(), "HTLC witnesses are not sorted by input index")
+
+	txn.Sigs = txn.Sigs[:2]
+	txn.PushHTLCWitness(NewHTLCClaimWitness(0, h, preimage))
+	txn.PushHTLCWitness(NewHTLCClaimWitness(1, h, preimage))
+	require.NoError(t, txn.UpdateHeader())
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInput(UxArray{ux, ux2}))
+
+	// Unknown transaction type
+	txn = makeHTLCSpend(t, ux, recipientSecKey, NewHTLCClaimWitness(0, h, preimage))
+	txn.Type = 4
+	require.EqualError(t, txn.Verify(), "transaction type invalid")
+}
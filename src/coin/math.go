@@ -3,6 +3,7 @@ package coin
 import (
 	"errors"
 	"math"
+	"math/big"
 )
 
 var (
@@ -78,3 +79,111 @@ func IntToUint32(a int) (uint32, error) {
 
 	return uint32(a), nil
 }
+
+// DistributeCoinHoursProportional distributes hours amongst coins proportional to the coins amount
+func DistributeCoinHoursProportional(coins []uint64, hours uint64) ([]uint64, error) {
+	if len(coins) == 0 {
+		return nil, errors.New("DistributeCoinHoursProportional coins array must not be empty")
+	}
+
+	coinsInt := make([]*big.Int, len(coins))
+
+	var total uint64
+	for i, c := range coins {
+		if c == 0 {
+			return nil, errors.New("DistributeCoinHoursProportional coins array has a zero value")
+		}
+
+		var err error
+		total, err = AddUint64(total, c)
+		if err != nil {
+			return nil, err
+		}
+
+		cInt64, err := Uint64ToInt64(c)
+		if err != nil {
+			return nil, err
+		}
+
+		coinsInt[i] = big.NewInt(cInt64)
+	}
+
+	totalInt64, err := Uint64ToInt64(total)
+	if err != nil {
+		return nil, err
+	}
+	totalInt := big.NewInt(totalInt64)
+
+	hoursInt64, err := Uint64ToInt64(hours)
+	if err != nil {
+		return nil, err
+	}
+	hoursInt := big.NewInt(hoursInt64)
+
+	var assignedHours uint64
+	addrHours := make([]uint64, len(coins))
+	for i, c := range coinsInt {
+		// Scale the ratio of coins to total coins proportionally by calculating
+		// (coins * totalHours) / totalCoins
+		// The remainder is truncated, remaining hours are appended after this
+		num := &big.Int{}
+		num.Mul(c, hoursInt)
+
+		fracInt := big.Int{}
+		fracInt.Div(num, totalInt)
+
+		if !fracInt.IsUint64() {
+			return nil, errors.New("DistributeCoinHoursProportional calculated fractional hours is not representable as a uint64")
+		}
+
+		fracHours := fracInt.Uint64()
+
+		addrHours[i] = fracHours
+		assignedHours, err = AddUint64(assignedHours, fracHours)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hours < assignedHours {
+		return nil, errors.New("DistributeCoinHoursProportional assigned hours exceeding input hours, this is a bug")
+	}
+
+	remainingHours := hours - assignedHours
+
+	if remainingHours > uint64(len(coins)) {
+		return nil, errors.New("DistributeCoinHoursProportional remaining hours exceed len(coins), this is a bug")
+	}
+
+	// For remaining hours lost due to fractional cutoff when scaling,
+	// first provide at least 1 coin hour to coins that were assigned 0.
+	i := 0
+	for remainingHours > 0 && i < len(coins) {
+		if addrHours[i] == 0 {
+			addrHours[i] = 1
+			remainingHours--
+		}
+		i++
+	}
+
+	// Then, assign the extra coin hours
+	i = 0
+	for remainingHours > 0 {
+		addrHours[i] = addrHours[i] + 1
+		remainingHours--
+		i++
+	}
+
+	return addrHours, nil
+}
+
+// RequiredFee returns the coinhours fee required for an amount of hours
+// The required fee is calculated as hours/burnFactor, rounded up.
+func RequiredFee(hours uint64, burnFactor uint32) uint64 {
+	feeHours := hours / uint64(burnFactor)
+	if hours%uint64(burnFactor) != 0 {
+		feeHours++
+	}
+
+	return feeHours
+}
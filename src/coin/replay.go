@@ -0,0 +1,29 @@
+package coin
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+/*
+Fiber coins share the transaction format, so a transaction signed for one chain is valid
+on another chain whenever the outputs that it spends exist on both chains, for example
+after an airdrop that copies the unspent outputs of a chain.
+
+The inputs of TxnTypeReplayProtected transactions sign SHA256(InnerHash+genesisHash)
+in place of the inner hash, so that their signatures are only valid on the chain
+with that genesis block. Transactions of the other types are not replay protected.
+*/
+
+// ChainInnerHash returns the hash that the inputs of a TxnTypeReplayProtected transaction sign
+// in place of the inner hash, for the chain whose genesis block hash is genesisHash
+func (txn *Transaction) ChainInnerHash(genesisHash cipher.SHA256) cipher.SHA256 {
+	return cipher.AddSHA256(txn.InnerHash, genesisHash)
+}
+
+// SignInputsForChain signs all inputs in the transaction with signatures that are only valid on the chain
+// whose genesis block hash is genesisHash, and sets the transaction type to TxnTypeReplayProtected
+func (txn *Transaction) SignInputsForChain(keys []cipher.SecKey, genesisHash cipher.SHA256) {
+	txn.InnerHash = txn.HashInner() // update hash
+	txn.signInputs(keys, txn.ChainInnerHash(genesisHash))
+	txn.Type = TxnTypeReplayProtected
+}
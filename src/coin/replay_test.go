@@ -0,0 +1,78 @@
+package coin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestTransactionSignInputsForChain(t *testing.T) {
+	genesisHash := testutil.RandSHA256(t)
+	otherGenesisHash := testutil.RandSHA256(t)
+
+	ux, s := makeUxOutWithSecret(t)
+	ux2, s2 := makeUxOutWithSecret(t)
+
+	txn := Transaction{}
+	txn.PushInput(ux.Hash())
+	txn.PushInput(ux2.Hash())
+	txn.PushOutput(makeAddress(), 1e6, 50)
+
+	txn2 := copyTransaction(txn)
+
+	txn.SignInputsForChain([]cipher.SecKey{s, s2}, genesisHash)
+	require.Equal(t, TxnTypeReplayProtected, txn.Type)
+	require.Len(t, txn.Sigs, 2)
+	require.Equal(t, txn.HashInner(), txn.InnerHash)
+	require.NoError(t, txn.UpdateHeader())
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInputForChain(UxArray{ux, ux2}, genesisHash))
+
+	// The chain is required to verify the inputs
+	require.EqualError(t, txn.VerifyInput(UxArray{ux, ux2}), "Replay protected transaction inputs must be verified with VerifyInputForChain")
+
+	require.Panics(t, func() {
+		txn.SignInputsForChain([]cipher.SecKey{s, s2}, genesisHash)
+	})
+
+	// The signatures are not valid on another chain
+	require.EqualError(t, txn.VerifyInputForChain(UxArray{ux, ux2}, otherGenesisHash), "Signature not valid for output being spent")
+
+	// The signatures differ from the signatures without replay protection
+	txn2.SignInputs([]cipher.SecKey{s, s2})
+	require.Equal(t, TxnTypeDefault, txn2.Type)
+	require.Equal(t, txn.InnerHash, txn2.InnerHash)
+	require.NotEqual(t, txn.Sigs, txn2.Sigs)
+
+	// Transactions without replay protection are valid on any chain
+	require.NoError(t, txn2.UpdateHeader())
+	require.NoError(t, txn2.VerifyInputForChain(UxArray{ux, ux2}, genesisHash))
+	require.NoError(t, txn2.VerifyInputForChain(UxArray{ux, ux2}, otherGenesisHash))
+	require.NoError(t, txn2.VerifyInput(UxArray{ux, ux2}))
+
+	// A transaction without replay protection can't be turned into one
+	txn3 := copyTransaction(txn2)
+	txn3.Type = TxnTypeReplayProtected
+	require.NoError(t, txn3.UpdateHeader())
+	require.NoError(t, txn3.Verify())
+	require.EqualError(t, txn3.VerifyInputForChain(UxArray{ux, ux2}, genesisHash), "Signature not valid for output being spent")
+
+	// Invalid number of signatures
+	txn3 = copyTransaction(txn)
+	txn3.Sigs = txn3.Sigs[:1]
+	require.NoError(t, txn3.UpdateHeader())
+	require.EqualError(t, txn3.Verify(), "Invalid number of signatures")
+}
+
+func TestTransactionChainInnerHash(t *testing.T) {
+	txn := makeTransaction(t)
+	genesisHash := testutil.RandSHA256(t)
+
+	h := txn.ChainInnerHash(genesisHash)
+	require.Equal(t, cipher.AddSHA256(txn.InnerHash, genesisHash), h)
+	require.NotEqual(t, txn.InnerHash, h)
+	require.NotEqual(t, h, txn.ChainInnerHash(testutil.RandSHA256(t)))
+}
@@ -0,0 +1,221 @@
+package coin
+
+import (
+	"errors"
+	"log"
+	"math"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+/*
+Transactions of the TxnTypeSchnorr type authorize their inputs with BIP340 Schnorr signatures
+instead of one recoverable signature per input. All of the inputs owned by the same address
+share a single signature of the inner hash, so a transaction that spends many outputs of an
+address needs one signature for all of them.
+
+A Schnorr signature does not reveal its pubkey, so each signer takes two signature sized
+entries of Transaction.Sigs, ordered by the first input owned by the signer:
+- the 64 byte Schnorr signature of the inner hash, padded with a zero byte
+- the 33 byte compressed pubkey of the signer, padded with zero bytes
+
+The pubkey of a signer can be aggregated from the pubkeys of several key holders with
+cipher.AggregatePubKeys, so that the outputs of its address are spent with one signature
+that all of the key holders make together with cipher.MuSigSession, without sharing their seckeys.
+The signature is added to the transaction with Transaction.SetSchnorrWitnesses.
+
+The signatures don't depend on the outputs being spent, so they are batch verified by
+Transaction.Verify, and Transaction.VerifyInput checks that the signers own the inputs.
+*/
+
+// SchnorrWitness is a signer of a TxnTypeSchnorr transaction
+type SchnorrWitness struct {
+	PubKey cipher.PubKey
+	Sig    cipher.SchnorrSig
+}
+
+// Sigs encodes the witness in two signature sized entries of Transaction.Sigs
+func (w SchnorrWitness) Sigs() []cipher.Sig {
+	var sig, pubkey cipher.Sig
+	copy(sig[:], w.Sig[:])
+	copy(pubkey[:], w.PubKey[:])
+	return []cipher.Sig{sig, pubkey}
+}
+
+// SchnorrWitnessFromSigs decodes a Schnorr witness from two entries of Transaction.Sigs
+func SchnorrWitnessFromSigs(sig, pubkey cipher.Sig) (SchnorrWitness, error) {
+	var w SchnorrWitness
+	if !isZero(sig[len(w.Sig):]) || !isZero(pubkey[len(w.PubKey):]) {
+		return SchnorrWitness{}, errors.New("Schnorr witness padding is not zero")
+	}
+
+	copy(w.Sig[:], sig[:])
+	copy(w.PubKey[:], pubkey[:])
+
+	if err := w.PubKey.Verify(); err != nil {
+		return SchnorrWitness{}, errors.New("Schnorr witness pubkey invalid")
+	}
+
+	return w, nil
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SchnorrWitnesses returns the signers of a TxnTypeSchnorr transaction.
+// Returns nil for other transaction types
+func (txn *Transaction) SchnorrWitnesses() ([]SchnorrWitness, error) {
+	if txn.Type != TxnTypeSchnorr {
+		return nil, nil
+	}
+
+	if len(txn.Sigs) == 0 {
+		return nil, errors.New("Schnorr transaction has no signers")
+	}
+
+	if len(txn.Sigs)%2 != 0 {
+		return nil, errors.New("Schnorr transaction has an odd number of signatures")
+	}
+
+	if len(txn.Sigs)/2 > len(txn.In) {
+		return nil, errors.New("Schnorr transaction has more signers than inputs")
+	}
+
+	witnesses := make([]SchnorrWitness, len(txn.Sigs)/2)
+	pubkeys := make(map[cipher.PubKey]struct{}, len(witnesses))
+	for i := range witnesses {
+		w, err := SchnorrWitnessFromSigs(txn.Sigs[2*i], txn.Sigs[2*i+1])
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := pubkeys[w.PubKey]; ok {
+			return nil, errors.New("Duplicate Schnorr signer")
+		}
+		pubkeys[w.PubKey] = struct{}{}
+
+		witnesses[i] = w
+	}
+
+	return witnesses, nil
+}
+
+// SignInputsSchnorr signs all inputs in the transaction with Schnorr signatures and sets the transaction type to TxnTypeSchnorr.
+// keys has one key per input, and inputs with the same key share a signature
+func (txn *Transaction) SignInputsSchnorr(keys []cipher.SecKey) {
+	txn.InnerHash = txn.HashInner() // update hash
+
+	if len(txn.Sigs) != 0 {
+		log.Panic("Transaction has been signed")
+	}
+	if len(keys) != len(txn.In) {
+		log.Panic("Invalid number of keys")
+	}
+	if len(keys) > math.MaxUint16 {
+		log.Panic("Too many keys")
+	}
+	if len(keys) == 0 {
+		log.Panic("No keys")
+	}
+
+	signed := make(map[cipher.SecKey]struct{}, len(keys))
+	var witnesses []SchnorrWitness
+	for _, k := range keys {
+		if _, ok := signed[k]; ok {
+			continue
+		}
+		signed[k] = struct{}{}
+
+		witnesses = append(witnesses, SchnorrWitness{
+			PubKey: cipher.MustPubKeyFromSecKey(k),
+			Sig:    cipher.MustSignHashSchnorr(txn.InnerHash, k),
+		})
+	}
+
+	txn.SetSchnorrWitnesses(witnesses)
+}
+
+// SetSchnorrWitnesses sets the signers of the transaction and sets the transaction type to TxnTypeSchnorr.
+// The signatures are made of the inner hash outside of the transaction, such as by the holders of an
+// aggregated pubkey with cipher.MuSigSession. The witnesses must be ordered by the first input owned by their signer
+func (txn *Transaction) SetSchnorrWitnesses(witnesses []SchnorrWitness) {
+	txn.InnerHash = txn.HashInner() // update hash
+
+	if len(txn.Sigs) != 0 {
+		log.Panic("Transaction has been signed")
+	}
+	if len(witnesses) == 0 {
+		log.Panic("No witnesses")
+	}
+	if len(witnesses) > len(txn.In) {
+		log.Panic("More witnesses than inputs")
+	}
+
+	sigs := make([]cipher.Sig, 0, 2*len(witnesses))
+	for _, w := range witnesses {
+		sigs = append(sigs, w.Sigs()...)
+	}
+
+	txn.Type = TxnTypeSchnorr
+	txn.Sigs = sigs
+}
+
+// verifySchnorrSigs batch verifies the signatures of a TxnTypeSchnorr transaction
+func (txn *Transaction) verifySchnorrSigs() error {
+	witnesses, err := txn.SchnorrWitnesses()
+	if err != nil {
+		return err
+	}
+
+	pubkeys := make([]cipher.PubKey, len(witnesses))
+	sigs := make([]cipher.SchnorrSig, len(witnesses))
+	hashes := make([]cipher.SHA256, len(witnesses))
+	for i, w := range witnesses {
+		pubkeys[i] = w.PubKey
+		sigs[i] = w.Sig
+		hashes[i] = txn.InnerHash
+	}
+
+	return cipher.VerifySchnorrSignedHashes(pubkeys, sigs, hashes)
+}
+
+// verifySchnorrInputs checks that each input of a TxnTypeSchnorr transaction is owned by a signer,
+// that each signer owns an input and that the signers are ordered by their first input
+func (txn *Transaction) verifySchnorrInputs(uxIn UxArray) error {
+	witnesses, err := txn.SchnorrWitnesses()
+	if err != nil {
+		return err
+	}
+
+	signers := make(map[cipher.Address]int, len(witnesses))
+	for i, w := range witnesses {
+		signers[cipher.AddressFromPubKey(w.PubKey)] = i
+	}
+
+	next := 0
+	for i := range txn.In {
+		j, ok := signers[uxIn[i].Body.Address]
+		if !ok {
+			return errors.New("Signature not valid for output being spent")
+		}
+
+		switch {
+		case j == next:
+			next++
+		case j > next:
+			return errors.New("Schnorr signers are not sorted by input")
+		}
+	}
+
+	if next != len(witnesses) {
+		return errors.New("Schnorr signer does not own an input")
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+package coin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func makeUxOutForAddress(t *testing.T, addr cipher.Address) UxOut {
+	return UxOut{
+		Head: UxHead{
+			Time:  100,
+			BkSeq: 2,
+		},
+		Body: UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        addr,
+			Coins:          1e6,
+			Hours:          100,
+		},
+	}
+}
+
+func makeSchnorrTransaction(t *testing.T, uxIn UxArray, keys []cipher.SecKey) Transaction {
+	txn := Transaction{}
+	for _, ux := range uxIn {
+		txn.PushInput(ux.Hash())
+	}
+	txn.PushOutput(makeAddress(), 1e6, 50)
+	txn.SignInputsSchnorr(keys)
+	require.NoError(t, txn.UpdateHeader())
+	return txn
+}
+
+func TestSchnorrWitnessSigs(t *testing.T) {
+	p, s := cipher.GenerateKeyPair()
+	h := testutil.RandSHA256(t)
+	w := SchnorrWitness{
+		PubKey: p,
+		Sig:    cipher.MustSignHashSchnorr(h, s),
+	}
+
+	sigs := w.Sigs()
+	require.Len(t, sigs, 2)
+
+	w2, err := SchnorrWitnessFromSigs(sigs[0], sigs[1])
+	require.NoError(t, err)
+	require.Equal(t, w, w2)
+
+	sig := sigs[0]
+	sig[64] = 1
+	_, err = SchnorrWitnessFromSigs(sig, sigs[1])
+	require.EqualError(t, err, "Schnorr witness padding is not zero")
+
+	pubkey := sigs[1]
+	pubkey[40] = 1
+	_, err = SchnorrWitnessFromSigs(sigs[0], pubkey)
+	require.EqualError(t, err, "Schnorr witness padding is not zero")
+
+	_, err = SchnorrWitnessFromSigs(sigs[0], cipher.Sig{})
+	require.EqualError(t, err, "Schnorr witness pubkey invalid")
+}
+
+func TestSchnorrTransactionVerify(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	pubkey2, seckey2 := cipher.GenerateKeyPair()
+
+	uxMulti := makeUxOutForAddress(t, cipher.AddressFromPubKey(pubkey2))
+	uxMulti2 := makeUxOutForAddress(t, cipher.AddressFromPubKey(pubkey2))
+	ux := makeUxOutForAddress(t, cipher.AddressFromPubKey(pubkey))
+	uxIn := UxArray{uxMulti, ux, uxMulti2}
+
+	// Inputs owned by the same key share a signature
+	txn := makeSchnorrTransaction(t, uxIn, []cipher.SecKey{seckey2, seckey, seckey2})
+	require.Equal(t, TxnTypeSchnorr, txn.Type)
+	require.Len(t, txn.Sigs, 4)
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInput(uxIn))
+
+	witnesses, err := txn.SchnorrWitnesses()
+	require.NoError(t, err)
+	require.Len(t, witnesses, 2)
+	require.Equal(t, pubkey2, witnesses[0].PubKey)
+	require.Equal(t, pubkey, witnesses[1].PubKey)
+
+	// Other transaction types have no Schnorr witnesses
+	txn2 := makeTransaction(t)
+	witnesses, err = txn2.SchnorrWitnesses()
+	require.NoError(t, err)
+	require.Nil(t, witnesses)
+
+	// No signers
+	txn2 = copyTransaction(txn)
+	txn2.Sigs = nil
+	require.NoError(t, txn2.UpdateHeader())
+	require.EqualError(t, txn2.Verify(), "Schnorr transaction has no signers")
+
+	// Odd number of signatures
+	txn2 = copyTransaction(txn)
+	txn2.Sigs = txn2.Sigs[:3]
+	require.NoError(t, txn2.UpdateHeader())
+	require.EqualError(t, txn2.Verify(), "Schnorr transaction has an odd number of signatures")
+
+	// More signers than inputs
+	txn2 = makeSchnorrTransaction(t, UxArray{ux}, []cipher.SecKey{seckey})
+	txn2.Sigs = append(txn2.Sigs, txn.Sigs[:2]...)
+	require.NoError(t, txn2.UpdateHeader())
+	require.EqualError(t, txn2.Verify(), "Schnorr transaction has more signers than inputs")
+
+	// Duplicate signer
+	txn2 = copyTransaction(txn)
+	txn2.Sigs[2] = txn2.Sigs[0]
+	txn2.Sigs[3] = txn2.Sigs[1]
+	require.NoError(t, txn2.UpdateHeader())
+	require.EqualError(t, txn2.Verify(), "Duplicate Schnorr signer")
+
+	// Invalid signature
+	txn2 = copyTransaction(txn)
+	txn2.Sigs[2][10] ^= 1
+	require.NoError(t, txn2.UpdateHeader())
+	require.Equal(t, cipher.ErrInvalidSchnorrSigForMessage, txn2.Verify())
+
+	// Signatures of another transaction
+	txn2 = makeSchnorrTransaction(t, uxIn, []cipher.SecKey{seckey2, seckey, seckey2})
+	txn2.PushOutput(makeAddress(), 1e6, 50)
+	require.NoError(t, txn2.UpdateHeader())
+	require.Equal(t, cipher.ErrInvalidSchnorrSigForMessage, txn2.Verify())
+
+	// Signers not sorted by their first input
+	txn2 = copyTransaction(txn)
+	txn2.Sigs = append(txn2.Sigs[2:], txn2.Sigs[:2]...)
+	require.NoError(t, txn2.UpdateHeader())
+	require.NoError(t, txn2.Verify())
+	require.EqualError(t, txn2.VerifyInput(uxIn), "Schnorr signers are not sorted by input")
+
+	// Input not owned by a signer
+	other := makeUxOutForAddress(t, makeAddress())
+	txn2 = makeSchnorrTransaction(t, UxArray{uxMulti, ux, other}, []cipher.SecKey{seckey2, seckey, seckey2})
+	require.NoError(t, txn2.Verify())
+	require.EqualError(t, txn2.VerifyInput(UxArray{uxMulti, ux, other}), "Signature not valid for output being spent")
+
+	// Signer that does not own an input
+	txn2 = makeSchnorrTransaction(t, UxArray{uxMulti, uxMulti2}, []cipher.SecKey{seckey2, seckey2})
+	txn2.Sigs = append(txn2.Sigs, SchnorrWitness{
+		PubKey: pubkey,
+		Sig:    cipher.MustSignHashSchnorr(txn2.InnerHash, seckey),
+	}.Sigs()...)
+	require.NoError(t, txn2.UpdateHeader())
+	require.NoError(t, txn2.Verify())
+	require.EqualError(t, txn2.VerifyInput(UxArray{uxMulti, uxMulti2}), "Schnorr signer does not own an input")
+}
+
+func TestSchnorrTransactionMuSig(t *testing.T) {
+	// Two key holders co-sign for their aggregated pubkey, without sharing their seckeys
+	pubkey1, seckey1 := cipher.GenerateKeyPair()
+	pubkey2, seckey2 := cipher.GenerateKeyPair()
+	aggPubKey, err := cipher.AggregatePubKeys([]cipher.PubKey{pubkey1, pubkey2})
+	require.NoError(t, err)
+
+	pubkey, seckey := cipher.GenerateKeyPair()
+
+	uxAgg := makeUxOutForAddress(t, cipher.AddressFromPubKey(aggPubKey))
+	ux := makeUxOutForAddress(t, cipher.AddressFromPubKey(pubkey))
+	uxAgg2 := makeUxOutForAddress(t, cipher.AddressFromPubKey(aggPubKey))
+	uxIn := UxArray{uxAgg, ux, uxAgg2}
+
+	txn := Transaction{}
+	for _, ux := range uxIn {
+		txn.PushInput(ux.Hash())
+	}
+	txn.PushOutput(makeAddress(), 3e6, 50)
+	h := txn.HashInner()
+
+	s1, err := cipher.NewMuSigSession(h, []cipher.PubKey{pubkey1, pubkey2}, seckey1)
+	require.NoError(t, err)
+	s2, err := cipher.NewMuSigSession(h, []cipher.PubKey{pubkey2, pubkey1}, seckey2)
+	require.NoError(t, err)
+	require.Equal(t, aggPubKey, s1.AggPubKey())
+
+	require.NoError(t, s1.AddNonceCommitment(pubkey2, s2.NonceCommitment()))
+	require.NoError(t, s2.AddNonceCommitment(pubkey1, s1.NonceCommitment()))
+
+	n1, err := s1.Nonce()
+	require.NoError(t, err)
+	n2, err := s2.Nonce()
+	require.NoError(t, err)
+	require.NoError(t, s1.AddNonce(pubkey2, n2))
+	require.NoError(t, s2.AddNonce(pubkey1, n1))
+
+	ps1, err := s1.PartialSign()
+	require.NoError(t, err)
+	ps2, err := s2.PartialSign()
+	require.NoError(t, err)
+	require.NoError(t, s1.AddPartialSig(pubkey2, ps2))
+
+	aggSig, err := s1.Sig()
+	require.NoError(t, err)
+
+	// Both key holders combine the same signature
+	require.NoError(t, s2.AddPartialSig(pubkey1, ps1))
+	aggSig2, err := s2.Sig()
+	require.NoError(t, err)
+	require.Equal(t, aggSig, aggSig2)
+
+	txn.SetSchnorrWitnesses([]SchnorrWitness{
+		{
+			PubKey: aggPubKey,
+			Sig:    aggSig,
+		},
+		{
+			PubKey: pubkey,
+			Sig:    cipher.MustSignHashSchnorr(h, seckey),
+		},
+	})
+	require.Equal(t, TxnTypeSchnorr, txn.Type)
+	require.Equal(t, h, txn.InnerHash)
+	require.Len(t, txn.Sigs, 4)
+	require.NoError(t, txn.UpdateHeader())
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInput(uxIn))
+
+	// A signature of only one of the key holders is not valid for the aggregated pubkey
+	txn2 := Transaction{}
+	for _, ux := range uxIn {
+		txn2.PushInput(ux.Hash())
+	}
+	txn2.PushOutput(makeAddress(), 3e6, 50)
+	txn2.SetSchnorrWitnesses([]SchnorrWitness{
+		{
+			PubKey: aggPubKey,
+			Sig:    cipher.MustSignHashSchnorr(h, seckey1),
+		},
+		{
+			PubKey: pubkey,
+			Sig:    cipher.MustSignHashSchnorr(h, seckey),
+		},
+	})
+	require.NoError(t, txn2.UpdateHeader())
+	require.Equal(t, cipher.ErrInvalidSchnorrSigForMessage, txn2.Verify())
+
+	// The partial signature of one key holder is not accepted for the other
+	require.Equal(t, cipher.ErrInvalidMuSigPartialSig, s2.AddPartialSig(pubkey1, ps2))
+
+	require.Panics(t, func() {
+		txn.SetSchnorrWitnesses([]SchnorrWitness{{PubKey: pubkey}})
+	})
+}
+
+func TestTransactionSignInputsSchnorr(t *testing.T) {
+	ux, s := makeUxOutWithSecret(t)
+	ux2, s2 := makeUxOutWithSecret(t)
+
+	txn := Transaction{}
+	txn.PushInput(ux.Hash())
+	txn.PushInput(ux2.Hash())
+	txn.PushOutput(makeAddress(), 1e6, 50)
+
+	require.Panics(t, func() {
+		txn.SignInputsSchnorr([]cipher.SecKey{s})
+	})
+
+	txn.SignInputsSchnorr([]cipher.SecKey{s, s2})
+	require.Equal(t, TxnTypeSchnorr, txn.Type)
+	require.Len(t, txn.Sigs, 4)
+	require.Equal(t, txn.HashInner(), txn.InnerHash)
+	require.NoError(t, txn.UpdateHeader())
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInput(UxArray{ux, ux2}))
+
+	require.Panics(t, func() {
+		txn.SignInputsSchnorr([]cipher.SecKey{s, s2})
+	})
+
+	// Many inputs of the same address take less space than with a signature per input
+	txn = Transaction{}
+	txn2 := Transaction{}
+	var keys []cipher.SecKey
+	for i := 0; i < 10; i++ {
+		h := testutil.RandSHA256(t)
+		txn.PushInput(h)
+		txn2.PushInput(h)
+		keys = append(keys, s)
+	}
+	txn.PushOutput(makeAddress(), 1e6, 50)
+	txn2.PushOutput(makeAddress(), 1e6, 50)
+	txn.SignInputs(keys)
+	txn2.SignInputsSchnorr(keys)
+	require.Len(t, txn2.Sigs, 2)
+	require.True(t, len(txn2.Serialize()) < len(txn.Serialize()))
+}
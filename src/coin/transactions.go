@@ -74,9 +74,27 @@ func (txn *Transaction) Verify() error {
 		return errors.New("No outputs")
 	}
 
+	switch txn.Type {
+	case TxnTypeDefault, TxnTypeHTLC, TxnTypeSchnorr, TxnTypeReplayProtected:
+	default:
+		return errors.New("transaction type invalid")
+	}
+
 	// Check signature index fields
-	if len(txn.Sigs) != len(txn.In) {
-		return errors.New("Invalid number of signatures")
+	if txn.Type == TxnTypeSchnorr {
+		// Signers of TxnTypeSchnorr transactions share a signature between their inputs
+		if _, err := txn.SchnorrWitnesses(); err != nil {
+			return err
+		}
+	} else {
+		// HTLC witnesses are appended to the signatures of TxnTypeHTLC transactions
+		witnesses, err := txn.HTLCWitnesses()
+		if err != nil {
+			return err
+		}
+		if len(txn.Sigs)-len(witnesses) != len(txn.In) {
+			return errors.New("Invalid number of signatures")
+		}
 	}
 	if len(txn.Sigs) >= math.MaxUint16 {
 		return errors.New("Too many signatures and inputs")
@@ -91,10 +109,6 @@ func (txn *Transaction) Verify() error {
 		return errors.New("Duplicate spend")
 	}
 
-	if txn.Type != 0 {
-		return errors.New("transaction type invalid")
-	}
-
 	txnSize, err := txn.Size()
 	if err != nil {
 		return err
@@ -120,11 +134,21 @@ func (txn *Transaction) Verify() error {
 	}
 
 	// Validate signature
-	for i, sig := range txn.Sigs {
-		hash := cipher.AddSHA256(txn.InnerHash, txn.In[i])
-		if err := cipher.VerifySignedHash(sig, hash); err != nil {
+	// The signatures of TxnTypeReplayProtected transactions commit to the genesis hash,
+	// which is not known here, so they are only checked by VerifyInputForChain
+	switch txn.Type {
+	case TxnTypeSchnorr:
+		if err := txn.verifySchnorrSigs(); err != nil {
 			return err
 		}
+	case TxnTypeReplayProtected:
+	default:
+		for i, sig := range txn.Sigs[:len(txn.In)] {
+			hash := cipher.AddSHA256(txn.InnerHash, txn.In[i])
+			if err := cipher.VerifySignedHash(sig, hash); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Prevent zero coin outputs
@@ -148,13 +172,34 @@ func (txn *Transaction) Verify() error {
 	return nil
 }
 
-// VerifyInput verifies the input
+// VerifyInput verifies the input.
+// The signatures of TxnTypeReplayProtected transactions commit to the genesis hash of a chain,
+// so their inputs can't be verified here, use VerifyInputForChain
 func (txn Transaction) VerifyInput(uxIn UxArray) error {
+	if txn.Type == TxnTypeReplayProtected {
+		return errors.New("Replay protected transaction inputs must be verified with VerifyInputForChain")
+	}
+
+	return txn.verifyInput(uxIn, cipher.SHA256{})
+}
+
+// VerifyInputForChain verifies the input on the chain whose genesis block has the hash genesisHash,
+// which the signatures of TxnTypeReplayProtected transactions commit to.
+// The inputs of transactions of the other types are verified the same as by VerifyInput
+func (txn Transaction) VerifyInputForChain(uxIn UxArray, genesisHash cipher.SHA256) error {
+	return txn.verifyInput(uxIn, genesisHash)
+}
+
+func (txn Transaction) verifyInput(uxIn UxArray, genesisHash cipher.SHA256) error {
+	witnesses, witnessesErr := txn.HTLCWitnesses()
 	if err := func() error {
 		if len(txn.In) != len(uxIn) {
 			return errors.New("txn.In != uxIn")
 		}
-		if len(txn.In) != len(txn.Sigs) {
+		if witnessesErr != nil {
+			return witnessesErr
+		}
+		if txn.Type != TxnTypeSchnorr && len(txn.In) != len(txn.Sigs)-len(witnesses) {
 			return errors.New("txn.In != txn.Sigs")
 		}
 		if txn.InnerHash != txn.HashInner() {
@@ -173,9 +218,33 @@ func (txn Transaction) VerifyInput(uxIn UxArray) error {
 		return err
 	}
 
+	// The signatures of TxnTypeSchnorr transactions are checked by Verify
+	if txn.Type == TxnTypeSchnorr {
+		return txn.verifySchnorrInputs(uxIn)
+	}
+
+	htlcWitnesses := make(map[int]HTLCWitness, len(witnesses))
+	for _, w := range witnesses {
+		htlcWitnesses[int(w.Input)] = w
+	}
+
+	innerHash := txn.InnerHash
+	if txn.Type == TxnTypeReplayProtected {
+		innerHash = txn.ChainInnerHash(genesisHash)
+	}
+
 	// Check signatures against unspent address
 	for i := range txn.In {
-		hash := cipher.AddSHA256(txn.InnerHash, txn.In[i]) // use inner hash, not outer hash
+		hash := cipher.AddSHA256(innerHash, txn.In[i]) // use inner hash, not outer hash
+
+		// HTLC outputs are spent by the recipient or sender of the contract revealed by the witness
+		if w, ok := htlcWitnesses[i]; ok {
+			if err := verifyHTLCInput(uxIn[i].Body.Address, txn.Sigs[i], hash, w); err != nil {
+				return err
+			}
+			continue
+		}
+
 		err := cipher.VerifyAddressSignedHash(uxIn[i].Body.Address, txn.Sigs[i], hash)
 		if err != nil {
 			return errors.New("Signature not valid for output being spent")
@@ -185,6 +254,18 @@ func (txn Transaction) VerifyInput(uxIn UxArray) error {
 	return nil
 }
 
+// VerifyUserConstraints applies additional verification for a transaction created by the user,
+// that does not apply to transactions received over the network or included in blocks
+func (txn Transaction) VerifyUserConstraints() error {
+	for _, o := range txn.Out {
+		if o.Address.Null() {
+			return errors.New("Transaction output is sent to the null address")
+		}
+	}
+
+	return nil
+}
+
 // PushInput adds a UxArray to the Transaction given the hash of a UxOut.
 // Returns the signature index for later signing
 func (txn *Transaction) PushInput(uxOut cipher.SHA256) uint16 {
@@ -218,7 +299,10 @@ func (txn *Transaction) PushOutput(dst cipher.Address, coins, hours uint64) {
 // SignInputs signs all inputs in the transaction
 func (txn *Transaction) SignInputs(keys []cipher.SecKey) {
 	txn.InnerHash = txn.HashInner() // update hash
+	txn.signInputs(keys, txn.InnerHash)
+}
 
+func (txn *Transaction) signInputs(keys []cipher.SecKey, innerHash cipher.SHA256) {
 	if len(txn.Sigs) != 0 {
 		log.Panic("Transaction has been signed")
 	}
@@ -233,7 +317,6 @@ func (txn *Transaction) SignInputs(keys []cipher.SecKey) {
 	}
 
 	sigs := make([]cipher.Sig, len(txn.In))
-	innerHash := txn.HashInner()
 	for i, k := range keys {
 		h := cipher.AddSHA256(innerHash, txn.In[i]) // hash to sign
 		sigs[i] = cipher.MustSignHash(h, k)
@@ -280,7 +363,6 @@ func (txn *Transaction) UpdateHeader() error {
 		return err
 	}
 	txn.Length = s
-	txn.Type = byte(0x00)
 	txn.InnerHash = txn.HashInner()
 	return nil
 }
@@ -0,0 +1,180 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+)
+
+// BlockPublishPolicy controls when the block publisher creates blocks
+type BlockPublishPolicy struct {
+	// Maximum time that valid unconfirmed transactions wait before a block is created
+	MaxLatency time.Duration
+	// Minimum time between blocks
+	MinSpacing time.Duration
+	// A block is created without waiting for MaxLatency when the total size of the
+	// valid unconfirmed transactions exceeds this many bytes
+	BurstSize uint64
+	// If not 0, an empty block is created when no block was created for this long.
+	// Empty blocks are only created from the empty block activation height
+	Heartbeat time.Duration
+}
+
+// BlockPublishStats records the activity of the block publisher
+type BlockPublishStats struct {
+	// Number of blocks created, including heartbeat blocks
+	Published uint64
+	// Number of blocks created early, because the unconfirmed pool exceeded the burst size
+	BurstPublished uint64
+	// Number of failed attempts to create a block
+	Failures uint64
+	// Number of blocks delayed by the minimum spacing
+	SpacingDelays uint64
+	// Number of empty heartbeat blocks created
+	Heartbeats uint64
+	// Time the last block was created
+	LastPublished time.Time
+	// Time the last empty heartbeat block was created
+	LastHeartbeat time.Time
+	// Time the unconfirmed pool was first seen with valid transactions since the last block was created.
+	// Zero if there are no valid unconfirmed transactions
+	PendingSince time.Time
+	// How long the transactions in the last block waited before it was created
+	LastLatency time.Duration
+	// The longest time transactions waited before a block was created
+	MaxLatency time.Duration
+}
+
+type blockPublishAction int
+
+const (
+	blockPublishNone blockPublishAction = iota
+	blockPublishInterval
+	blockPublishBurst
+	blockPublishHeartbeat
+)
+
+func (a blockPublishAction) String() string {
+	switch a {
+	case blockPublishInterval:
+		return "interval"
+	case blockPublishBurst:
+		return "burst"
+	case blockPublishHeartbeat:
+		return "heartbeat"
+	default:
+		return "none"
+	}
+}
+
+// blockPublisher decides when the block publisher creates blocks, according to a BlockPublishPolicy
+type blockPublisher struct {
+	sync.RWMutex
+	policy BlockPublishPolicy
+	stats  BlockPublishStats
+	// Time of the last block, or of startup or of the last skipped heartbeat
+	lastActivity time.Time
+	// No block is created before this time after a failed attempt
+	retryAfter time.Time
+	// Whether the pending block was already counted as delayed by the minimum spacing
+	delayed bool
+}
+
+func newBlockPublisher(policy BlockPublishPolicy, now time.Time) *blockPublisher {
+	return &blockPublisher{
+		policy:       policy,
+		lastActivity: now,
+	}
+}
+
+// next returns the action to take, given the number and total size of the valid unconfirmed transactions
+func (p *blockPublisher) next(now time.Time, poolTxns int, poolSize uint64) blockPublishAction {
+	p.Lock()
+	defer p.Unlock()
+
+	if poolTxns == 0 {
+		p.stats.PendingSince = time.Time{}
+		if p.policy.Heartbeat > 0 && now.Sub(p.lastActivity) >= p.policy.Heartbeat && !now.Before(p.retryAfter) {
+			return blockPublishHeartbeat
+		}
+		return blockPublishNone
+	}
+
+	if p.stats.PendingSince.IsZero() {
+		p.stats.PendingSince = now
+	}
+
+	if now.Before(p.retryAfter) {
+		return blockPublishNone
+	}
+
+	burst := p.policy.BurstSize > 0 && poolSize > p.policy.BurstSize
+	if !burst && now.Sub(p.stats.PendingSince) < p.policy.MaxLatency {
+		return blockPublishNone
+	}
+
+	if !p.stats.LastPublished.IsZero() && now.Sub(p.stats.LastPublished) < p.policy.MinSpacing {
+		if !p.delayed {
+			p.stats.SpacingDelays++
+			p.delayed = true
+		}
+		return blockPublishNone
+	}
+
+	if burst {
+		return blockPublishBurst
+	}
+	return blockPublishInterval
+}
+
+// published records that a block was created for the action
+func (p *blockPublisher) published(now time.Time, a blockPublishAction) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.stats.Published++
+	switch a {
+	case blockPublishBurst:
+		p.stats.BurstPublished++
+	case blockPublishHeartbeat:
+		p.stats.Heartbeats++
+		p.stats.LastHeartbeat = now
+	}
+
+	if !p.stats.PendingSince.IsZero() {
+		latency := now.Sub(p.stats.PendingSince)
+		p.stats.LastLatency = latency
+		if latency > p.stats.MaxLatency {
+			p.stats.MaxLatency = latency
+		}
+	}
+
+	p.stats.LastPublished = now
+	p.stats.PendingSince = time.Time{}
+	p.lastActivity = now
+	p.delayed = false
+}
+
+// failed records a failed attempt to create a block. The next attempt is made after MaxLatency
+func (p *blockPublisher) failed(now time.Time) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.stats.Failures++
+	p.retryAfter = now.Add(p.policy.MaxLatency)
+}
+
+// heartbeatSkipped records that no heartbeat block was created because empty blocks are not activated yet.
+// The next heartbeat is attempted after another heartbeat period
+func (p *blockPublisher) heartbeatSkipped(now time.Time) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.lastActivity = now
+}
+
+// getStats returns the policy and a copy of the stats
+func (p *blockPublisher) getStats() (BlockPublishPolicy, BlockPublishStats) {
+	p.RLock()
+	defer p.RUnlock()
+	return p.policy, p.stats
+}
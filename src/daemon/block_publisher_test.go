@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockPublisher(t *testing.T) {
+	start := time.Unix(1542443907, 0).UTC()
+	p := newBlockPublisher(BlockPublishPolicy{
+		MaxLatency: time.Second * 10,
+		MinSpacing: time.Second * 3,
+		BurstSize:  1000,
+	}, start)
+
+	// Nothing to publish
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second), 0, 0))
+
+	// Pending transactions wait for the max latency
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second*2), 1, 300))
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second*11), 2, 600))
+	require.Equal(t, blockPublishInterval, p.next(start.Add(time.Second*12), 2, 600))
+	p.published(start.Add(time.Second*12), blockPublishInterval)
+
+	_, stats := p.getStats()
+	require.Equal(t, BlockPublishStats{
+		Published:     1,
+		LastPublished: start.Add(time.Second * 12),
+		LastLatency:   time.Second * 10,
+		MaxLatency:    time.Second * 10,
+	}, stats)
+
+	// The pool exceeds the burst size, but the min spacing has not passed
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second*13), 5, 1500))
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second*14), 5, 1500))
+	require.Equal(t, blockPublishBurst, p.next(start.Add(time.Second*15), 5, 1500))
+	p.published(start.Add(time.Second*15), blockPublishBurst)
+
+	_, stats = p.getStats()
+	require.Equal(t, BlockPublishStats{
+		Published:      2,
+		BurstPublished: 1,
+		SpacingDelays:  1,
+		LastPublished:  start.Add(time.Second * 15),
+		LastLatency:    time.Second * 2,
+		MaxLatency:     time.Second * 10,
+	}, stats)
+
+	// A failed attempt is retried after the max latency
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second*20), 1, 300))
+	require.Equal(t, blockPublishInterval, p.next(start.Add(time.Second*30), 1, 300))
+	p.failed(start.Add(time.Second * 30))
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second*31), 6, 1500))
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second*39), 6, 1500))
+	require.Equal(t, blockPublishBurst, p.next(start.Add(time.Second*40), 6, 1500))
+	p.published(start.Add(time.Second*40), blockPublishBurst)
+
+	_, stats = p.getStats()
+	require.Equal(t, BlockPublishStats{
+		Published:      3,
+		BurstPublished: 2,
+		Failures:       1,
+		SpacingDelays:  1,
+		LastPublished:  start.Add(time.Second * 40),
+		LastLatency:    time.Second * 20,
+		MaxLatency:     time.Second * 20,
+	}, stats)
+
+	// No heartbeat block is created if it is disabled
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Hour), 0, 0))
+}
+
+func TestBlockPublisherHeartbeat(t *testing.T) {
+	start := time.Unix(1542443907, 0).UTC()
+	p := newBlockPublisher(BlockPublishPolicy{
+		MaxLatency: time.Second * 10,
+		MinSpacing: time.Second,
+		BurstSize:  1000,
+		Heartbeat:  time.Minute,
+	}, start)
+
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Second*59), 0, 0))
+	require.Equal(t, blockPublishHeartbeat, p.next(start.Add(time.Minute), 0, 0))
+
+	// Before the empty block activation height the heartbeat is skipped, and attempted again after the heartbeat interval
+	p.heartbeatSkipped(start.Add(time.Minute))
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Minute*2-time.Second), 0, 0))
+	require.Equal(t, blockPublishHeartbeat, p.next(start.Add(time.Minute*2), 0, 0))
+	p.published(start.Add(time.Minute*2), blockPublishHeartbeat)
+
+	// The heartbeat block is created when no block was created for the heartbeat interval
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Minute*2+time.Second*10), 1, 300))
+	require.Equal(t, blockPublishInterval, p.next(start.Add(time.Minute*2+time.Second*20), 1, 300))
+	p.published(start.Add(time.Minute*2+time.Second*20), blockPublishInterval)
+
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Minute*3+time.Second*19), 0, 0))
+	require.Equal(t, blockPublishHeartbeat, p.next(start.Add(time.Minute*3+time.Second*20), 0, 0))
+
+	// A failed heartbeat block is attempted again after MaxLatency
+	p.failed(start.Add(time.Minute*3 + time.Second*20))
+	require.Equal(t, blockPublishNone, p.next(start.Add(time.Minute*3+time.Second*29), 0, 0))
+	require.Equal(t, blockPublishHeartbeat, p.next(start.Add(time.Minute*3+time.Second*30), 0, 0))
+	p.published(start.Add(time.Minute*3+time.Second*30), blockPublishHeartbeat)
+
+	_, stats := p.getStats()
+	require.Equal(t, BlockPublishStats{
+		Published:     3,
+		Failures:      1,
+		Heartbeats:    2,
+		LastPublished: start.Add(time.Minute*3 + time.Second*30),
+		LastHeartbeat: start.Add(time.Minute*3 + time.Second*30),
+		LastLatency:   time.Second * 10,
+		MaxLatency:    time.Second * 10,
+	}, stats)
+}
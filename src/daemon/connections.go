@@ -8,6 +8,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/util/iputil"
 	"github.com/skycoin/skycoin/src/util/useragent"
@@ -63,6 +64,29 @@ type ConnectionDetails struct {
 	Height               uint64
 	UserAgent            useragent.Data
 	UnconfirmedVerifyTxn params.VerifyTxn
+	// Time the reported height last increased
+	HeightUpdatedAt time.Time
+	// Smoothed round trip time measured from ping and pong messages, 0 if not measured yet
+	RTT time.Duration
+	// Time the outstanding ping was sent, zero if no ping is outstanding
+	PingSentAt time.Time
+	// Smoothed time between sending a GetBlocksMessage and receiving a GiveBlocksMessage, 0 if not measured yet
+	BlocksLatency time.Duration
+	// Time the outstanding GetBlocksMessage was sent, zero if no request is outstanding
+	BlocksRequestedAt time.Time
+	// Number of GiveBlocksMessages received in reply to a GetBlocksMessage
+	BlocksResponses uint64
+	// Number of GetBlocksMessages that timed out or were answered with a block that failed to execute
+	BlocksFailures uint64
+	// Node pubkey reported in the introduction, null if the peer did not report one
+	NodePubKey cipher.PubKey
+	// Whether the peer proved that it owns NodePubKey, by signing the auth challenge sent in our introduction
+	NodeAuthenticated bool
+	// Whether the peer is in blocks-only mode. Transactions are not announced or sent to blocks-only peers
+	BlocksOnly bool
+
+	// Random challenge sent in our introduction, which the peer signs to prove that it owns NodePubKey
+	authChallenge cipher.SHA256
 }
 
 // HasIntroduced returns true if the connection has introduced
@@ -297,6 +321,8 @@ func (c *Connections) introduced(addr string, gnetID uint64, m *IntroductionMess
 	conn.ListenPort = listenPort
 	conn.UserAgent = m.userAgent
 	conn.UnconfirmedVerifyTxn = m.unconfirmedVerifyTxn
+	conn.NodePubKey = m.nodePubKey
+	conn.BlocksOnly = m.blocksOnly
 
 	if !conn.Outgoing {
 		listenAddr := conn.ListenAddr()
@@ -392,10 +418,118 @@ func (c *Connections) SetHeight(addr string, gnetID uint64, height uint64) error
 	defer c.Unlock()
 
 	return c.modify(addr, gnetID, func(c *ConnectionDetails) {
+		if height > c.Height || c.HeightUpdatedAt.IsZero() {
+			c.HeightUpdatedAt = time.Now().UTC()
+		}
 		c.Height = height
 	})
 }
 
+// modifyByAddr modifies a connection by address, regardless of its gnet ID.
+// It is used for messages whose send results do not carry the gnet ID
+func (c *Connections) modifyByAddr(addr string, f func(c *ConnectionDetails)) error {
+	conn := c.conns[addr]
+	if conn == nil {
+		return ErrConnectionNotExist
+	}
+
+	return c.modify(addr, conn.gnetID, f)
+}
+
+// pingSent records the time a ping was sent to a connection.
+// If a ping is already outstanding and has not timed out, the time is not changed,
+// so that the next pong is matched to the oldest ping.
+func (c *Connections) pingSent(addr string, now time.Time, timeout time.Duration) error {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.modifyByAddr(addr, func(c *ConnectionDetails) {
+		if c.PingSentAt.IsZero() || now.Sub(c.PingSentAt) > timeout {
+			c.PingSentAt = now
+		}
+	})
+}
+
+// pongReceived updates the connection's smoothed RTT from the outstanding ping.
+// Returns the measured RTT sample, or 0 if no ping was outstanding
+func (c *Connections) pongReceived(addr string, gnetID uint64, now time.Time) (time.Duration, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	var rtt time.Duration
+	err := c.modify(addr, gnetID, func(c *ConnectionDetails) {
+		if c.PingSentAt.IsZero() {
+			return
+		}
+
+		rtt = now.Sub(c.PingSentAt)
+		if rtt < 0 {
+			rtt = 0
+		}
+
+		c.RTT = smoothDuration(c.RTT, rtt)
+		c.PingSentAt = time.Time{}
+	})
+
+	return rtt, err
+}
+
+// blocksRequested records the time a GetBlocksMessage was sent to a connection.
+// If a request is already outstanding, the time is not changed
+func (c *Connections) blocksRequested(addr string, now time.Time) error {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.modifyByAddr(addr, func(c *ConnectionDetails) {
+		if c.BlocksRequestedAt.IsZero() {
+			c.BlocksRequestedAt = now
+		}
+	})
+}
+
+// blocksReceived records a GiveBlocksMessage received from a connection.
+// failed is true if a block in the message failed to execute
+func (c *Connections) blocksReceived(addr string, gnetID uint64, failed bool, now time.Time) error {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.modify(addr, gnetID, func(c *ConnectionDetails) {
+		if !c.BlocksRequestedAt.IsZero() {
+			latency := now.Sub(c.BlocksRequestedAt)
+			if latency < 0 {
+				latency = 0
+			}
+
+			c.BlocksLatency = smoothDuration(c.BlocksLatency, latency)
+			c.BlocksRequestedAt = time.Time{}
+		}
+
+		c.BlocksResponses++
+		if failed {
+			c.BlocksFailures++
+		}
+	})
+}
+
+// expireBlocksRequests clears the GetBlocksMessages which have been outstanding for longer than timeout.
+// If the connection reported a height greater than headSeq, it should have replied, and the request is
+// recorded as a failure
+func (c *Connections) expireBlocksRequests(headSeq uint64, now time.Time, timeout time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, conn := range c.conns {
+		if conn.BlocksRequestedAt.IsZero() || now.Sub(conn.BlocksRequestedAt) <= timeout {
+			continue
+		}
+
+		conn.BlocksRequestedAt = time.Time{}
+		if conn.Height > headSeq {
+			conn.BlocksFailures++
+		}
+	}
+}
+
 func (c *Connections) updateMirror(ip string, mirror uint32, port uint16) error {
 	x := c.mirrors[mirror]
 	if x == nil {
@@ -3,6 +3,7 @@ package daemon
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -446,6 +447,111 @@ func TestConnectionsSetHeight(t *testing.T) {
 	c = conns.get(addr)
 	require.NotNil(t, c)
 	require.Equal(t, height, c.Height)
+	require.False(t, c.HeightUpdatedAt.IsZero())
+
+	// The update time is unchanged if the height does not increase
+	updatedAt := c.HeightUpdatedAt
+	time.Sleep(time.Millisecond)
+	err = conns.SetHeight(addr, 1, height)
+	require.NoError(t, err)
+	c = conns.get(addr)
+	require.Equal(t, updatedAt, c.HeightUpdatedAt)
+}
+
+func TestConnectionsPingPong(t *testing.T) {
+	conns := NewConnections()
+	addr := "127.0.0.1:6060"
+	now := time.Unix(1000, 0).UTC()
+
+	err := conns.pingSent(addr, now, pingTimeout)
+	require.Equal(t, ErrConnectionNotExist, err)
+
+	_, err = conns.connected(addr, 1)
+	require.NoError(t, err)
+
+	// A pong without an outstanding ping is not measured
+	rtt, err := conns.pongReceived(addr, 1, now)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), rtt)
+
+	require.NoError(t, conns.pingSent(addr, now, pingTimeout))
+	// A second ping before the pong keeps the time of the first ping
+	require.NoError(t, conns.pingSent(addr, now.Add(time.Second), pingTimeout))
+
+	_, err = conns.pongReceived(addr, 2, now.Add(time.Millisecond*800))
+	require.Equal(t, ErrConnectionGnetIDMismatch, err)
+
+	rtt, err = conns.pongReceived(addr, 1, now.Add(time.Millisecond*800))
+	require.NoError(t, err)
+	require.Equal(t, time.Millisecond*800, rtt)
+
+	c := conns.get(addr)
+	require.Equal(t, time.Millisecond*800, c.RTT)
+	require.True(t, c.PingSentAt.IsZero())
+
+	// Later samples are smoothed
+	now = now.Add(time.Second * 10)
+	require.NoError(t, conns.pingSent(addr, now, pingTimeout))
+	rtt, err = conns.pongReceived(addr, 1, now)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), rtt)
+
+	c = conns.get(addr)
+	require.Equal(t, time.Millisecond*700, c.RTT)
+
+	// A ping whose pong never arrived is replaced after the timeout
+	require.NoError(t, conns.pingSent(addr, now, pingTimeout))
+	require.NoError(t, conns.pingSent(addr, now.Add(pingTimeout+time.Second), pingTimeout))
+	c = conns.get(addr)
+	require.Equal(t, now.Add(pingTimeout+time.Second), c.PingSentAt)
+}
+
+func TestConnectionsBlocksRequests(t *testing.T) {
+	conns := NewConnections()
+	addr := "127.0.0.1:6060"
+	now := time.Unix(1000, 0).UTC()
+
+	err := conns.blocksRequested(addr, now)
+	require.Equal(t, ErrConnectionNotExist, err)
+
+	_, err = conns.connected(addr, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, conns.blocksRequested(addr, now))
+	require.NoError(t, conns.blocksRequested(addr, now.Add(time.Second)))
+
+	require.NoError(t, conns.blocksReceived(addr, 1, false, now.Add(time.Second*2)))
+	c := conns.get(addr)
+	require.Equal(t, time.Second*2, c.BlocksLatency)
+	require.True(t, c.BlocksRequestedAt.IsZero())
+	require.Equal(t, uint64(1), c.BlocksResponses)
+	require.Equal(t, uint64(0), c.BlocksFailures)
+
+	// An unsolicited response with an invalid block is a failure, and is not used to measure latency
+	require.NoError(t, conns.blocksReceived(addr, 1, true, now.Add(time.Minute)))
+	c = conns.get(addr)
+	require.Equal(t, time.Second*2, c.BlocksLatency)
+	require.Equal(t, uint64(2), c.BlocksResponses)
+	require.Equal(t, uint64(1), c.BlocksFailures)
+
+	// Unanswered requests expire after the timeout, and are failures if the peer has newer blocks
+	require.NoError(t, conns.SetHeight(addr, 1, 10))
+	require.NoError(t, conns.blocksRequested(addr, now))
+
+	conns.expireBlocksRequests(10, now.Add(time.Minute), time.Minute)
+	c = conns.get(addr)
+	require.False(t, c.BlocksRequestedAt.IsZero())
+
+	conns.expireBlocksRequests(10, now.Add(time.Minute+time.Second), time.Minute)
+	c = conns.get(addr)
+	require.True(t, c.BlocksRequestedAt.IsZero())
+	require.Equal(t, uint64(1), c.BlocksFailures)
+
+	require.NoError(t, conns.blocksRequested(addr, now))
+	conns.expireBlocksRequests(9, now.Add(time.Minute+time.Second), time.Minute)
+	c = conns.get(addr)
+	require.True(t, c.BlocksRequestedAt.IsZero())
+	require.Equal(t, uint64(2), c.BlocksFailures)
 }
 
 func TestConnectionsModifyMirrorPanics(t *testing.T) {
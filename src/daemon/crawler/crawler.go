@@ -0,0 +1,288 @@
+/*
+Package crawler crawls the peer network.
+
+The crawler connects to a set of seed peers and speaks the peer protocol with them.
+It performs the introduction handshake, requests peers with a GetPeersMessage and records the user agent,
+height and node pubkey of each peer. The peers that are received are crawled in turn, until no new peers are found.
+*/
+package crawler
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/iputil"
+	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/useragent"
+)
+
+var logger = logging.MustGetLogger("crawler")
+
+// Config configuration for the Crawler
+type Config struct {
+	// Addresses of the peers to start crawling from
+	Seeds []string
+	// Blockchain pubkey sent in the introduction. Peers of other blockchains disconnect the crawler
+	BlockchainPubkey cipher.PubKey
+	// User agent sent in the introduction
+	UserAgent useragent.Data
+	// Protocol version sent in the introduction
+	ProtocolVersion int32
+	// Listen port sent in the introduction
+	ListenPort uint16
+	// Transaction verification parameters sent in the introduction
+	VerifyTxn params.VerifyTxn
+	// Number of peers to crawl at once
+	Workers int
+	// How long to wait to establish a connection to a peer
+	DialTimeout time.Duration
+	// How long to stay connected to a peer, waiting for its introduction, peers and height
+	PeerTimeout time.Duration
+	// Maximum number of peers to crawl. If 0, the crawl ends when no new peers are found
+	MaxPeers int
+	// Maximum length of a message received from a peer
+	MaxMessageLength int
+	// Crawl localhost addresses received from peers. Seeds are crawled regardless
+	AllowLocalhost bool
+}
+
+// NewConfig returns a Config with defaults set
+func NewConfig() Config {
+	return Config{
+		ProtocolVersion:  2,
+		ListenPort:       6000,
+		VerifyTxn:        params.UserVerifyTxn,
+		Workers:          32,
+		DialTimeout:      time.Second * 5,
+		PeerTimeout:      time.Second * 10,
+		MaxMessageLength: 256 * 1024,
+	}
+}
+
+// Crawler crawls the peer network
+type Crawler struct {
+	Config     Config
+	userAgent  string
+	mirror     uint32
+	nodePubKey cipher.PubKey
+	nodeSecKey cipher.SecKey
+}
+
+// New creates a Crawler
+func New(c Config) (*Crawler, error) {
+	if len(c.Seeds) == 0 {
+		return nil, errors.New("No seeds to crawl")
+	}
+	if c.Workers <= 0 {
+		return nil, errors.New("Workers must be > 0")
+	}
+
+	userAgent, err := c.UserAgent.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.VerifyTxn.Validate(); err != nil {
+		return nil, err
+	}
+
+	// The node key is only used to send an auth challenge in the introduction, so that peers prove they own their node pubkey
+	nodePubKey, nodeSecKey := cipher.GenerateKeyPair()
+
+	return &Crawler{
+		Config:     c,
+		userAgent:  userAgent,
+		mirror:     rand.New(rand.NewSource(time.Now().UTC().UnixNano())).Uint32(),
+		nodePubKey: nodePubKey,
+		nodeSecKey: nodeSecKey,
+	}, nil
+}
+
+// Crawl crawls the seeds and the peers they report, recursively.
+// Closing quit stops crawling new peers; the peers that are being crawled are still included in the Result
+func (c *Crawler) Crawl(quit <-chan struct{}) Result {
+	startedAt := time.Now().UTC()
+
+	jobs := make(chan string)
+	results := make(chan Node)
+	for i := 0; i < c.Config.Workers; i++ {
+		go func() {
+			for addr := range jobs {
+				results <- c.crawlPeer(addr)
+			}
+		}()
+	}
+	defer close(jobs)
+
+	seen := make(map[string]struct{})
+	var pending []string
+	for _, addr := range c.Config.Seeds {
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		pending = append(pending, addr)
+	}
+
+	nodes := make(map[string]Node)
+	started := 0
+	inflight := 0
+
+	limitReached := func() bool {
+		return c.Config.MaxPeers > 0 && started >= c.Config.MaxPeers
+	}
+
+	for inflight > 0 || (len(pending) > 0 && !limitReached()) {
+		// Only send a job when there is a pending peer, otherwise wait for results
+		var jobC chan string
+		var next string
+		if len(pending) > 0 && !limitReached() {
+			jobC = jobs
+			next = pending[0]
+		}
+
+		select {
+		case jobC <- next:
+			pending = pending[1:]
+			started++
+			inflight++
+
+		case n := <-results:
+			inflight--
+			nodes[n.Address] = n
+
+			logger.WithField("addr", n.Address).WithField("reachable", n.Reachable).WithField("peers", len(n.Peers)).Debug("Crawled peer")
+
+			for _, addr := range n.Peers {
+				if _, ok := seen[addr]; ok {
+					continue
+				}
+				seen[addr] = struct{}{}
+
+				if !c.crawlable(addr) {
+					continue
+				}
+
+				pending = append(pending, addr)
+			}
+
+		case <-quit:
+			pending = nil
+			quit = nil
+		}
+	}
+
+	return newResult(startedAt, time.Now().UTC(), nodes)
+}
+
+// crawlable returns true if an address received from a peer is a valid ip:port to crawl
+func (c *Crawler) crawlable(addr string) bool {
+	ip, port, err := iputil.SplitAddr(addr)
+	if err != nil || port == 0 {
+		return false
+	}
+
+	if iputil.IsLocalhost(ip) && !c.Config.AllowLocalhost {
+		return false
+	}
+
+	return true
+}
+
+// Result is the result of a crawl
+type Result struct {
+	StartedAt  int64  `json:"started_at"`
+	FinishedAt int64  `json:"finished_at"`
+	Stats      Stats  `json:"stats"`
+	Nodes      []Node `json:"nodes"`
+}
+
+func newResult(startedAt, finishedAt time.Time, nodes map[string]Node) Result {
+	r := Result{
+		StartedAt:  startedAt.Unix(),
+		FinishedAt: finishedAt.Unix(),
+		Nodes:      make([]Node, 0, len(nodes)),
+	}
+
+	for _, n := range nodes {
+		r.Nodes = append(r.Nodes, n)
+	}
+
+	sort.Slice(r.Nodes, func(i, j int) bool {
+		return r.Nodes[i].Address < r.Nodes[j].Address
+	})
+
+	r.Stats = newStats(r.Nodes)
+
+	return r
+}
+
+// Reachable returns the nodes that completed the introduction
+func (r Result) Reachable() []Node {
+	var nodes []Node
+	for _, n := range r.Nodes {
+		if n.Reachable {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// UnknownUserAgent is the key in Stats.UserAgents and Stats.Versions of reachable nodes that did not send a user agent
+const UnknownUserAgent = "unknown"
+
+// Stats summarizes the nodes found by a crawl
+type Stats struct {
+	// Number of nodes that were crawled
+	Nodes int `json:"nodes"`
+	// Number of nodes that completed the introduction
+	Reachable int `json:"reachable"`
+	// Number of connections between reachable nodes and the peers they reported
+	Edges int `json:"edges"`
+	// Highest height reported by a reachable node
+	MaxHeight uint64 `json:"max_height"`
+	// Number of reachable nodes by user agent
+	UserAgents map[string]int `json:"user_agents"`
+	// Number of reachable nodes by version
+	Versions map[string]int `json:"versions"`
+}
+
+func newStats(nodes []Node) Stats {
+	s := Stats{
+		Nodes:      len(nodes),
+		UserAgents: make(map[string]int),
+		Versions:   make(map[string]int),
+	}
+
+	for _, n := range nodes {
+		if !n.Reachable {
+			continue
+		}
+
+		s.Reachable++
+		s.Edges += len(n.Peers)
+
+		if n.Height > s.MaxHeight {
+			s.MaxHeight = n.Height
+		}
+
+		userAgent := UnknownUserAgent
+		version := UnknownUserAgent
+		if !n.UserAgent.Empty() {
+			userAgent, _ = n.UserAgent.Build() // nolint: errcheck
+			if userAgent == "" {
+				userAgent = UnknownUserAgent
+			}
+			version = n.UserAgent.Version
+		}
+
+		s.UserAgents[userAgent]++
+		s.Versions[version]++
+	}
+
+	return s
+}
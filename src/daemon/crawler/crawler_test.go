@@ -0,0 +1,348 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/util/useragent"
+)
+
+var (
+	testBlockchainPubkey, testBlockchainSeckey = cipher.GenerateKeyPair()
+	testUserAgent                              = useragent.Data{
+		Coin:    "skycoin",
+		Version: "0.25.0",
+	}
+)
+
+// freeAddr returns a localhost address with a port that is not in use
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+// newTestDaemon creates a block publishing node on addr, which connects to its default connections
+func newTestDaemon(t *testing.T, addr string, defaultConnections []string) (*daemon.Daemon, func()) {
+	db, dbCleanup := testutil.PrepareDB(t)
+
+	dir, err := ioutil.TempDir("", "crawler")
+	require.NoError(t, err)
+
+	_, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+
+	cfg := daemon.NewConfig()
+	cfg.Daemon.Address = "127.0.0.1"
+	fmt.Sscan(port, &cfg.Daemon.Port) // nolint: errcheck
+	cfg.Daemon.LocalhostOnly = true
+	cfg.Daemon.DataDirectory = dir
+	cfg.Daemon.BlockchainPubkey = testBlockchainPubkey
+	cfg.Daemon.UserAgent = testUserAgent
+	cfg.Daemon.DefaultConnections = defaultConnections
+	cfg.Daemon.OutgoingRate = time.Millisecond * 100
+	cfg.Daemon.OutgoingTrustedRate = time.Millisecond * 100
+	cfg.Daemon.BlocksAnnounceRate = time.Millisecond * 100
+	cfg.Pool.DefaultConnections = defaultConnections
+	cfg.Pex.DataDirectory = dir
+	cfg.Pex.DefaultConnections = defaultConnections
+	cfg.Visor.IsBlockPublisher = true
+	cfg.Visor.BlockchainPubkey = testBlockchainPubkey
+	cfg.Visor.BlockchainSeckey = testBlockchainSeckey
+	cfg.Visor.GenesisAddress = cipher.AddressFromPubKey(testBlockchainPubkey)
+	cfg.Visor.GenesisCoinVolume = 100e12
+	cfg.Visor.GenesisTimestamp = 1426562704
+	cfg.Visor.DBPath = db.Path()
+
+	// Each daemon registers the messages with gnet.
+	// The daemons must all be created before any of them runs
+	gnet.EraseMessages()
+
+	d, err := daemon.NewDaemon(cfg, db)
+	require.NoError(t, err)
+	require.NoError(t, d.Init())
+
+	return d, func() {
+		dbCleanup()
+		os.RemoveAll(dir) // nolint: errcheck
+	}
+}
+
+// runTestDaemon runs a daemon created by newTestDaemon, returning a function that stops it
+func runTestDaemon(t *testing.T, d *daemon.Daemon) func() {
+	errC := make(chan error, 1)
+	go func() {
+		errC <- d.Run()
+	}()
+
+	return func() {
+		d.Shutdown()
+		require.NoError(t, <-errC)
+	}
+}
+
+func newTestCrawler(t *testing.T, seeds []string) *Crawler {
+	cfg := NewConfig()
+	cfg.Seeds = seeds
+	cfg.BlockchainPubkey = testBlockchainPubkey
+	cfg.UserAgent = useragent.Data{
+		Coin:    "skycoin",
+		Version: "0.25.0",
+		Remark:  "crawler",
+	}
+	cfg.ListenPort = 6000
+	cfg.DialTimeout = time.Second
+	cfg.PeerTimeout = time.Second * 2
+	cfg.AllowLocalhost = true
+
+	c, err := New(cfg)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew(t *testing.T) {
+	cfg := NewConfig()
+	cfg.UserAgent = testUserAgent
+
+	_, err := New(cfg)
+	require.Error(t, err)
+
+	cfg.Seeds = []string{"127.0.0.1:6000"}
+	_, err = New(cfg)
+	require.NoError(t, err)
+
+	cfg.UserAgent = useragent.Data{}
+	_, err = New(cfg)
+	require.Error(t, err)
+}
+
+func TestCrawl(t *testing.T) {
+	addrA := freeAddr(t)
+	addrB := freeAddr(t)
+	addrC := freeAddr(t)
+	unreachable := freeAddr(t)
+
+	// A connects to B and B connects to C, so A reports B and B reports C
+	dA, cleanupA := newTestDaemon(t, addrA, []string{addrB})
+	defer cleanupA()
+	dB, cleanupB := newTestDaemon(t, addrB, []string{addrC})
+	defer cleanupB()
+	dC, cleanupC := newTestDaemon(t, addrC, nil)
+	defer cleanupC()
+
+	defer runTestDaemon(t, dC)()
+	defer runTestDaemon(t, dB)()
+	defer runTestDaemon(t, dA)()
+
+	c := newTestCrawler(t, []string{addrA, unreachable})
+
+	// Wait for the nodes to connect to each other
+	var r Result
+	for i := 0; i < 50; i++ {
+		r = c.Crawl(nil)
+		if r.Stats.Reachable == 3 {
+			break
+		}
+		time.Sleep(time.Millisecond * 200)
+	}
+
+	require.Equal(t, 4, r.Stats.Nodes)
+	require.Equal(t, 3, r.Stats.Reachable)
+	require.Equal(t, map[string]int{"skycoin:0.25.0": 3}, r.Stats.UserAgents)
+	require.Equal(t, map[string]int{"0.25.0": 3}, r.Stats.Versions)
+	require.Len(t, r.Reachable(), 3)
+
+	nodes := make(map[string]Node)
+	for _, n := range r.Nodes {
+		nodes[n.Address] = n
+	}
+
+	for _, addr := range []string{addrA, addrB, addrC} {
+		n := nodes[addr]
+		require.True(t, n.Reachable, addr)
+		require.Empty(t, n.Error)
+		require.Equal(t, testUserAgent, n.UserAgent)
+		require.Equal(t, int32(2), n.ProtocolVersion)
+		require.Equal(t, uint64(0), n.Height)
+		require.NotEmpty(t, n.NodePubKey)
+		require.True(t, n.NodeAuthenticated)
+		require.False(t, n.BlocksOnly)
+	}
+
+	require.Contains(t, nodes[addrA].Peers, addrB)
+	require.Contains(t, nodes[addrB].Peers, addrC)
+
+	require.False(t, nodes[unreachable].Reachable)
+	require.NotEmpty(t, nodes[unreachable].Error)
+
+	// Only the seeds are crawled when MaxPeers is reached
+	c.Config.MaxPeers = 1
+	r = c.Crawl(nil)
+	require.Equal(t, 1, r.Stats.Nodes)
+	require.Equal(t, addrA, r.Nodes[0].Address)
+
+	// Localhost peers that are received are not crawled unless allowed
+	c.Config.MaxPeers = 0
+	c.Config.AllowLocalhost = false
+	r = c.Crawl(nil)
+	require.Equal(t, 2, r.Stats.Nodes)
+	require.Equal(t, 1, r.Stats.Reachable)
+
+	// Nodes of another blockchain are not reachable
+	c.Config.BlockchainPubkey = testutil.MakePubKey()
+	r = c.Crawl(nil)
+	require.Equal(t, 0, r.Stats.Reachable)
+}
+
+func testResult() Result {
+	return newResult(time.Unix(1542443907, 0), time.Unix(1542443910, 0), map[string]Node{
+		"1.2.3.4:6000": {
+			Address:           "1.2.3.4:6000",
+			Reachable:         true,
+			ProtocolVersion:   2,
+			UserAgent:         testUserAgent,
+			Height:            180,
+			NodePubKey:        "0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a",
+			NodeAuthenticated: true,
+			Peers:             []string{"5.6.7.8:6000", "9.9.9.9:6000"},
+			CrawledAt:         1542443908,
+		},
+		"5.6.7.8:6000": {
+			Address:   "5.6.7.8:6000",
+			Reachable: true,
+			UserAgent: useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.24.1",
+			},
+			Height:     175,
+			BlocksOnly: true,
+			Peers:      []string{"1.2.3.4:6000"},
+			CrawledAt:  1542443908,
+		},
+		"9.9.9.9:6000": {
+			Address:   "9.9.9.9:6000",
+			Error:     "dial tcp 9.9.9.9:6000: i/o timeout",
+			Peers:     []string{},
+			CrawledAt: 1542443909,
+		},
+	})
+}
+
+func TestResultStats(t *testing.T) {
+	r := testResult()
+
+	require.Equal(t, Stats{
+		Nodes:     3,
+		Reachable: 2,
+		Edges:     3,
+		MaxHeight: 180,
+		UserAgents: map[string]int{
+			"skycoin:0.25.0": 1,
+			"skycoin:0.24.1": 1,
+		},
+		Versions: map[string]int{
+			"0.25.0": 1,
+			"0.24.1": 1,
+		},
+	}, r.Stats)
+
+	// Nodes are sorted by address
+	require.Equal(t, "1.2.3.4:6000", r.Nodes[0].Address)
+	require.Equal(t, "9.9.9.9:6000", r.Nodes[2].Address)
+}
+
+func TestResultWrite(t *testing.T) {
+	r := testResult()
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJSON(&buf))
+
+	var r2 Result
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &r2))
+	require.Equal(t, r, r2)
+
+	buf.Reset()
+	require.NoError(t, r.WriteCSV(&buf))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	require.Equal(t, csvHeader, rows[0])
+	require.Equal(t, []string{
+		"1.2.3.4:6000",
+		"true",
+		"",
+		"2",
+		"skycoin:0.25.0",
+		"skycoin",
+		"0.25.0",
+		"180",
+		"0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a",
+		"true",
+		"false",
+		"2",
+		"1542443908",
+	}, rows[1])
+	require.Equal(t, "dial tcp 9.9.9.9:6000: i/o timeout", rows[3][2])
+
+	buf.Reset()
+	require.NoError(t, r.WriteSeeds(&buf))
+	require.Equal(t, "1.2.3.4:6000\n5.6.7.8:6000\n", buf.String())
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler()
+
+	cases := []struct {
+		path        string
+		contentType string
+	}{
+		{"/peers.txt", "text/plain; charset=utf-8"},
+		{"/nodes.json", "application/json"},
+		{"/nodes.csv", "text/csv; charset=utf-8"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+			h.SetResult(testResult())
+
+			rr = httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			require.NotEmpty(t, rr.Body.String())
+
+			rr = httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.path, nil))
+			require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+			h.result = nil
+		})
+	}
+
+	h.SetResult(testResult())
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/peers.txt", nil))
+	require.Equal(t, []string{"1.2.3.4:6000", "5.6.7.8:6000"}, strings.Fields(rr.Body.String()))
+}
@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvHeader is the header row written by WriteCSV
+var csvHeader = []string{
+	"address",
+	"reachable",
+	"error",
+	"protocol_version",
+	"user_agent",
+	"coin",
+	"version",
+	"height",
+	"node_pubkey",
+	"node_authenticated",
+	"blocks_only",
+	"peers",
+	"crawled_at",
+}
+
+// WriteJSON writes the Result as indented JSON
+func (r Result) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "    ")
+	return e.Encode(r)
+}
+
+// WriteCSV writes the nodes of the Result as CSV, one row per node.
+// The peers column has the number of peers reported by the node
+func (r Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, n := range r.Nodes {
+		var userAgent string
+		if !n.UserAgent.Empty() {
+			userAgent, _ = n.UserAgent.Build() // nolint: errcheck
+		}
+
+		if err := cw.Write([]string{
+			n.Address,
+			strconv.FormatBool(n.Reachable),
+			n.Error,
+			strconv.FormatInt(int64(n.ProtocolVersion), 10),
+			userAgent,
+			n.UserAgent.Coin,
+			n.UserAgent.Version,
+			strconv.FormatUint(n.Height, 10),
+			n.NodePubKey,
+			strconv.FormatBool(n.NodeAuthenticated),
+			strconv.FormatBool(n.BlocksOnly),
+			strconv.Itoa(len(n.Peers)),
+			strconv.FormatInt(n.CrawledAt, 10),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSeeds writes the addresses of the reachable nodes, one ip:port per line.
+// This is the peers list format that nodes download from their peer list URL
+func (r Result) WriteSeeds(w io.Writer) error {
+	for _, n := range r.Reachable() {
+		if _, err := fmt.Fprintln(w, n.Address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
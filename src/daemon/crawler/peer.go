@@ -0,0 +1,236 @@
+package crawler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
+	"github.com/skycoin/skycoin/src/util/useragent"
+)
+
+var (
+	// ErrInvalidMessageLength is returned when a peer sends a message with an invalid length
+	ErrInvalidMessageLength = errors.New("Peer sent a message with an invalid length")
+	// ErrNoIntroduction is returned when a peer does not send an IntroductionMessage before the peer timeout
+	ErrNoIntroduction = errors.New("Peer did not send an introduction")
+
+	introPrefix          = gnet.MessagePrefixFromString("INTR")
+	getPeersPrefix       = gnet.MessagePrefixFromString("GETP")
+	givePeersPrefix      = gnet.MessagePrefixFromString("GIVP")
+	getBlocksPrefix      = gnet.MessagePrefixFromString("GETB")
+	announceBlocksPrefix = gnet.MessagePrefixFromString("ANNB")
+	nodeAuthPrefix       = gnet.MessagePrefixFromString("AUTH")
+	pingPrefix           = gnet.MessagePrefixFromString("PING")
+	pongPrefix           = gnet.MessagePrefixFromString("PONG")
+	disconnectPrefix     = gnet.MessagePrefixFromString("DISC")
+)
+
+// Node is a peer that was crawled
+type Node struct {
+	// Address of the peer, ip:port
+	Address string `json:"address"`
+	// Reachable is true if the peer sent a valid introduction
+	Reachable bool `json:"reachable"`
+	// Error that ended the crawl of the peer, if any
+	Error string `json:"error,omitempty"`
+	// Protocol version from the peer's introduction
+	ProtocolVersion int32 `json:"protocol_version"`
+	// User agent from the peer's introduction
+	UserAgent useragent.Data `json:"user_agent"`
+	// Height reported by the peer
+	Height uint64 `json:"height"`
+	// Hex encoded node pubkey from the peer's introduction. Empty if the peer did not send one
+	NodePubKey string `json:"node_pubkey"`
+	// NodeAuthenticated is true if the peer signed the crawler's auth challenge with NodePubKey
+	NodeAuthenticated bool `json:"node_authenticated"`
+	// BlocksOnly is true if the peer asked not to receive transactions in its introduction
+	BlocksOnly bool `json:"blocks_only"`
+	// Peers reported by the peer
+	Peers []string `json:"peers"`
+	// When the peer was crawled, unix time
+	CrawledAt int64 `json:"crawled_at"`
+}
+
+// crawlPeer connects to a peer and records its introduction, height and peers.
+// The connection is closed once they are all received, or after the peer timeout
+func (c *Crawler) crawlPeer(addr string) Node {
+	n := Node{
+		Address:   addr,
+		Peers:     []string{},
+		CrawledAt: time.Now().UTC().Unix(),
+	}
+
+	if err := c.crawlConn(addr, &n); err != nil {
+		n.Error = err.Error()
+	}
+
+	return n
+}
+
+func (c *Crawler) crawlConn(addr string, n *Node) error {
+	conn, err := net.DialTimeout("tcp", addr, c.Config.DialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(c.Config.PeerTimeout)); err != nil {
+		return err
+	}
+
+	// Advertise blocks-only mode, so that the peer does not send transactions to the crawler
+	challenge := cipher.SumSHA256(cipher.RandByte(32))
+	intro := daemon.NewIntroductionMessage(c.mirror, c.Config.ProtocolVersion, c.Config.ListenPort, c.Config.BlockchainPubkey,
+		c.userAgent, c.Config.VerifyTxn, c.nodePubKey, challenge, true, false)
+	if err := writeMessage(conn, introPrefix, intro); err != nil {
+		return err
+	}
+
+	var nodePubKey cipher.PubKey
+	var gotPeers, gotHeight, gotAuth bool
+	done := func() bool {
+		return n.Reachable && gotPeers && gotHeight && (gotAuth || nodePubKey.Null())
+	}
+
+	r := bufio.NewReader(conn)
+	for !done() {
+		prefix, data, err := readMessage(r, c.Config.MaxMessageLength)
+		if err != nil {
+			// The peer may have no peers to give, or no blocks to announce.
+			// Once it has introduced, running out of time is not an error
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				if n.Reachable {
+					return nil
+				}
+				return ErrNoIntroduction
+			}
+			return err
+		}
+
+		switch prefix {
+		case introPrefix:
+			var m daemon.IntroductionMessage
+			if err := encoder.DeserializeRaw(data, &m); err != nil {
+				return err
+			}
+			if err := m.VerifyExtra(c.Config.BlockchainPubkey); err != nil {
+				return err
+			}
+
+			n.Reachable = true
+			n.ProtocolVersion = m.ProtocolVersion
+			n.UserAgent = m.UserAgent()
+
+			nodePubKey = m.NodePubKey()
+			if !nodePubKey.Null() {
+				n.NodePubKey = nodePubKey.Hex()
+			}
+			n.BlocksOnly = m.BlocksOnly()
+
+			if err := writeMessage(conn, getPeersPrefix, daemon.NewGetPeersMessage()); err != nil {
+				return err
+			}
+
+		case givePeersPrefix:
+			var m daemon.GivePeersMessage
+			if err := encoder.DeserializeRaw(data, &m); err != nil {
+				return err
+			}
+			n.Peers = append(n.Peers, m.GetPeers()...)
+			gotPeers = true
+
+		case getBlocksPrefix:
+			// The peer requests the blocks after its last block
+			var m daemon.GetBlocksMessage
+			if err := encoder.DeserializeRaw(data, &m); err != nil {
+				return err
+			}
+			if m.LastBlock > n.Height {
+				n.Height = m.LastBlock
+			}
+			gotHeight = true
+
+		case announceBlocksPrefix:
+			var m daemon.AnnounceBlocksMessage
+			if err := encoder.DeserializeRaw(data, &m); err != nil {
+				return err
+			}
+			if m.MaxBkSeq > n.Height {
+				n.Height = m.MaxBkSeq
+			}
+			gotHeight = true
+
+		case nodeAuthPrefix:
+			var m daemon.NodeAuthMessage
+			if err := encoder.DeserializeRaw(data, &m); err != nil {
+				return err
+			}
+			if nodePubKey.Null() {
+				continue
+			}
+			n.NodeAuthenticated = daemon.VerifyNodeAuth(nodePubKey, challenge, m.Sig) == nil
+			gotAuth = true
+
+		case pingPrefix:
+			if err := writeMessage(conn, pongPrefix, &daemon.PongMessage{}); err != nil {
+				return err
+			}
+
+		case disconnectPrefix:
+			var m daemon.DisconnectMessage
+			if err := encoder.DeserializeRaw(data, &m); err != nil {
+				return err
+			}
+			return fmt.Errorf("Disconnected by peer: %v", daemon.DisconnectCodeToReason(m.ReasonCode))
+		}
+	}
+
+	return nil
+}
+
+// writeMessage writes a message to a peer, with its length prefix and message ID
+func writeMessage(w io.Writer, prefix gnet.MessagePrefix, msg interface{}) error {
+	data := encoder.Serialize(msg)
+
+	m := make([]byte, 0, 4+len(prefix)+len(data))
+	m = append(m, encoder.SerializeAtomic(uint32(len(prefix)+len(data)))...)
+	m = append(m, prefix[:]...)
+	m = append(m, data...)
+
+	_, err := w.Write(m)
+	return err
+}
+
+// readMessage reads a message from a peer, returning its message ID and data
+func readMessage(r io.Reader, maxMsgLength int) (gnet.MessagePrefix, []byte, error) {
+	var prefix gnet.MessagePrefix
+
+	var lengthBytes [4]byte
+	if _, err := io.ReadFull(r, lengthBytes[:]); err != nil {
+		return prefix, nil, err
+	}
+
+	var length uint32
+	if _, err := encoder.DeserializeAtomic(lengthBytes[:], &length); err != nil {
+		return prefix, nil, err
+	}
+
+	if int(length) < len(prefix) || int(length) > maxMsgLength {
+		return prefix, nil, ErrInvalidMessageLength
+	}
+
+	m := make([]byte, length)
+	if _, err := io.ReadFull(r, m); err != nil {
+		return prefix, nil, err
+	}
+
+	copy(prefix[:], m[:len(prefix)])
+	return prefix, m[len(prefix):], nil
+}
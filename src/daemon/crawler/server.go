@@ -0,0 +1,77 @@
+package crawler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+
+	wh "github.com/skycoin/skycoin/src/util/http"
+)
+
+// Handler serves the result of the latest crawl over HTTP:
+//
+//	/peers.txt   the reachable nodes as a seed list, one ip:port per line, which can be used as a node's peer list URL
+//	/nodes.json  the result as JSON
+//	/nodes.csv   the nodes as CSV
+//
+// Requests fail with 503 until a result is set with SetResult
+type Handler struct {
+	sync.RWMutex
+	result *Result
+	mux    *http.ServeMux
+}
+
+// NewHandler creates a Handler
+func NewHandler() *Handler {
+	h := &Handler{
+		mux: http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("/peers.txt", h.write("text/plain; charset=utf-8", Result.WriteSeeds))
+	h.mux.HandleFunc("/nodes.json", h.write("application/json", Result.WriteJSON))
+	h.mux.HandleFunc("/nodes.csv", h.write("text/csv; charset=utf-8", Result.WriteCSV))
+
+	return h
+}
+
+// SetResult sets the result served by the Handler
+func (h *Handler) SetResult(r Result) {
+	h.Lock()
+	defer h.Unlock()
+	h.result = &r
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) write(contentType string, f func(Result, io.Writer) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		h.RLock()
+		result := h.result
+		h.RUnlock()
+
+		if result == nil {
+			wh.Error503(w, "The first crawl has not finished")
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := f(*result, &buf); err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		if _, err := buf.WriteTo(w); err != nil {
+			logger.WithError(err).Error("http write failed")
+		}
+	}
+}
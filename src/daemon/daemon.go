@@ -17,6 +17,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon/gnet"
+	"github.com/skycoin/skycoin/src/daemon/lan"
 	"github.com/skycoin/skycoin/src/daemon/pex"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/util/elapse"
@@ -33,6 +34,16 @@ var (
 	ErrNetworkingDisabled = errors.New("Networking is disabled")
 	// ErrNoPeerAcceptsTxn is returned if no peer will propagate a transaction broadcasted with BroadcastUserTransaction
 	ErrNoPeerAcceptsTxn = errors.New("No peer will propagate this transaction")
+	// ErrOutgoingConnectionsDisabled is returned if outgoing connections are disabled
+	ErrOutgoingConnectionsDisabled = errors.New("Outgoing connections disabled")
+	// ErrPeerNotLocalhost is returned when connecting to a peer that is not on localhost, in localhost only mode
+	ErrPeerNotLocalhost = errors.New("Not localhost")
+	// ErrAlreadyConnected is returned when connecting to a peer that is already connected
+	ErrAlreadyConnected = errors.New("Already connected to this peer")
+	// ErrAlreadyConnectedIP is returned when connecting to a peer whose base IP is already connected
+	ErrAlreadyConnectedIP = errors.New("Already connected to a peer with this base IP")
+	// ErrPeerNotExist is returned when a peer does not exist in the peer list
+	ErrPeerNotExist = errors.New("Peer does not exist in the peer list")
 
 	logger = logging.MustGetLogger("daemon")
 )
@@ -63,6 +74,7 @@ type Config struct {
 	Pex      pex.Config
 	Gateway  GatewayConfig
 	Visor    visor.Config
+	LAN      lan.Config
 }
 
 // NewConfig returns a Config with defaults set
@@ -74,6 +86,7 @@ func NewConfig() Config {
 		Gateway:  NewGatewayConfig(),
 		Messages: NewMessagesConfig(),
 		Visor:    visor.NewConfig(),
+		LAN:      lan.NewConfig(),
 	}
 }
 
@@ -119,6 +132,31 @@ func (cfg *Config) preprocess() (Config, error) {
 		config.Daemon.MaxPendingConnections = config.Daemon.MaxOutgoingConnections
 	}
 
+	if config.Daemon.DisableNetworking {
+		config.LAN.Enabled = false
+	}
+	if config.Daemon.DisableIncomingConnections {
+		// Peers could not connect to this node, so don't announce it
+		config.LAN.DisableAnnounce = true
+	}
+	config.LAN.ChainID = config.Daemon.BlockchainPubkey
+	config.LAN.ListenPort = uint16(config.Daemon.Port)
+
+	if config.Visor.IsBlockPublisher {
+		if config.Daemon.BlockCreationInterval == 0 {
+			return Config{}, errors.New("BlockCreationInterval must be > 0")
+		}
+		if config.Daemon.BlockPublishMinSpacing < time.Second {
+			return Config{}, errors.New("BlockPublishMinSpacing must be at least 1s")
+		}
+		if config.Daemon.BlockPublishCheckRate <= 0 {
+			return Config{}, errors.New("BlockPublishCheckRate must be > 0")
+		}
+	}
+	if config.Daemon.BlockPublishBurstSize == 0 {
+		config.Daemon.BlockPublishBurstSize = uint64(config.Visor.MaxBlockSize)
+	}
+
 	config.Pool.MaxConnections = config.Daemon.MaxConnections
 	config.Pool.MaxOutgoingConnections = config.Daemon.MaxOutgoingConnections
 
@@ -131,6 +169,12 @@ func (cfg *Config) preprocess() (Config, error) {
 	}
 	config.Daemon.userAgent = userAgent
 
+	peerVersions, err := newPeerVersionPolicy(config.Daemon.MinPeerVersions, config.Daemon.PreferredPeerVersions, config.Daemon.BuggyPeerVersions)
+	if err != nil {
+		return Config{}, err
+	}
+	config.Daemon.peerVersions = peerVersions
+
 	return config, nil
 }
 
@@ -186,17 +230,74 @@ type DaemonConfig struct { // nolint: golint
 	BlocksResponseCount uint64
 	// Max announce txns hash number
 	MaxTxnAnnounceNum int
-	// How often new blocks are created by the signing node, in seconds
+	// Blocks-only mode. Peers are asked not to announce or send transactions to this node in the introduction,
+	// transactions from peers are ignored, and the unconfirmed pool is not relayed.
+	// Transactions submitted to this node are still broadcast
+	BlocksOnly bool
+	// Don't compress messages sent to peers, or advertise that peers can compress messages sent to this node
+	DisableCompression bool
+	// Maximum time, in seconds, that valid unconfirmed transactions wait before the block publisher creates a block
 	BlockCreationInterval uint64
+	// Minimum time between blocks created by the block publisher.
+	// Must be at least one second, because block times are in seconds and must increase
+	BlockPublishMinSpacing time.Duration
+	// The block publisher creates a block without waiting for BlockCreationInterval when the total size of the
+	// valid unconfirmed transactions exceeds this many bytes. If 0, the maximum block size is used
+	BlockPublishBurstSize uint64
+	// If not 0, the block publisher creates an empty block when it did not create a block for this long,
+	// so that peers can tell that it is alive. Empty blocks are only created from params.EmptyBlockActivationHeight
+	BlockPublishHeartbeat time.Duration
+	// How often the block publisher checks the unconfirmed pool
+	BlockPublishCheckRate time.Duration
 	// How often to check the unconfirmed pool for transactions that become valid
 	UnconfirmedRefreshRate time.Duration
 	// How often to remove transactions that become permanently invalid from the unconfirmed pool
 	UnconfirmedRemoveInvalidRate time.Duration
+	// How often to check the transactions created by local wallets, and rebroadcast the unconfirmed ones that are due
+	RebroadcastCheckRate time.Duration
+	// How long to wait before the first rebroadcast of a transaction created by a local wallet.
+	// The wait doubles after each rebroadcast, up to RebroadcastMaxDelay
+	RebroadcastInitialDelay time.Duration
+	// Maximum time to wait between rebroadcasts of a transaction created by a local wallet
+	RebroadcastMaxDelay time.Duration
+	// How long to remember the relay state of a transaction after its last update
+	TxnRelayRetention time.Duration
+	// Maximum number of transactions to remember the relay state of
+	TxnRelayMaxTxns int
+	// Maximum number of transactions that were rejected each time they were received to remember the relay state of,
+	// in addition to TxnRelayMaxTxns
+	TxnRelayMaxRejectedTxns int
+	// How long to remember an output spent by conflicting transactions after the last conflicting transaction was seen
+	TxnConflictRetention time.Duration
+	// Maximum number of transaction conflicts to relay to peers per minute
+	TxnConflictRelayRate int
+	// How often to record the quality of connections in the peer list and disconnect peers with a stale height
+	PeerQualityRate time.Duration
+	// How long to wait for a reply to a GetBlocksMessage before it counts as a failure
+	BlocksRequestTimeout time.Duration
+	// Number of connections to request blocks from, preferring the highest quality connections.
+	// If 0, blocks are requested from all connections
+	BlocksRequestPeers int
+	// Outgoing connections whose reported height lags behind ours by more than this many blocks,
+	// and has not increased for StaleHeightTimeout, are disconnected. If 0, no connections are disconnected
+	MaxPeerHeightLag uint64
+	// How long a lagging connection's reported height can stay unchanged before it is disconnected
+	StaleHeightTimeout time.Duration
 	// Default "trusted" peers
 	DefaultConnections []string
 	// User agent (sent in introduction messages)
 	UserAgent useragent.Data
 	userAgent string // parsed from UserAgent in preprocess()
+	// Minimum accepted user agent version of peers, by coin name, in the format "$COIN:$VERSION".
+	// Peers of a listed coin with a lower version are disconnected after their introduction
+	MinPeerVersions []string
+	// User agent versions to prefer when making outgoing connections, as semver ranges in the format "[$COIN:]$RANGE".
+	// Peers last seen with a matching version are connected to before other peers
+	PreferredPeerVersions []string
+	// User agent versions that are known to be buggy, as semver ranges in the format "[$COIN:]$RANGE".
+	// Peers with a matching version are disconnected after their introduction
+	BuggyPeerVersions []string
+	peerVersions      peerVersionPolicy // parsed from MinPeerVersions, PreferredPeerVersions and BuggyPeerVersions in preprocess()
 	// Transaction verification parameters for unconfirmed transactions
 	UnconfirmedVerifyTxn params.VerifyTxn
 	// Random nonce value for detecting self-connection in introduction messages
@@ -230,8 +331,23 @@ func NewDaemonConfig() DaemonConfig {
 		BlocksResponseCount:          20,
 		MaxTxnAnnounceNum:            16,
 		BlockCreationInterval:        10,
+		BlockPublishMinSpacing:       time.Second,
+		BlockPublishCheckRate:        time.Second,
 		UnconfirmedRefreshRate:       time.Minute,
 		UnconfirmedRemoveInvalidRate: time.Minute,
+		RebroadcastCheckRate:         time.Second * 10,
+		RebroadcastInitialDelay:      time.Minute,
+		RebroadcastMaxDelay:          time.Hour,
+		TxnRelayRetention:            time.Hour,
+		TxnRelayMaxTxns:              10000,
+		TxnRelayMaxRejectedTxns:      1000,
+		TxnConflictRetention:         time.Minute * 30,
+		TxnConflictRelayRate:         10,
+		PeerQualityRate:              time.Minute,
+		BlocksRequestTimeout:         time.Second * 30,
+		BlocksRequestPeers:           3,
+		MaxPeerHeightLag:             1000,
+		StaleHeightTimeout:           time.Minute * 10,
 		Mirror:                       rand.New(rand.NewSource(time.Now().UTC().UnixNano())).Uint32(),
 		UnconfirmedVerifyTxn:         params.UserVerifyTxn,
 	}
@@ -245,12 +361,18 @@ type daemoner interface {
 	Disconnect(addr string, r gnet.DisconnectReason) error
 	sendMessage(addr string, msg gnet.Message) error
 	broadcastMessage(msg gnet.Message) ([]uint64, error)
+	broadcastTxnsMessage(msg gnet.Message) ([]uint64, error)
 	disconnectNow(addr string, r gnet.DisconnectReason) error
 	addPeers(addrs []string) int
 	recordPeerHeight(addr string, gnetID, height uint64)
+	recordBlocksReceived(addr string, gnetID uint64, failed bool)
 	getSignedBlocksSince(seq, count uint64) ([]coin.SignedBlock, error)
 	headBkSeq() (uint64, bool, error)
 	executeSignedBlock(b coin.SignedBlock) error
+	detectDoubleSign(addr string, b coin.SignedBlock)
+	addDoubleSignProof(p visor.DoubleSignProof) error
+	detectTxnConflicts(addr string, txn coin.Transaction)
+	addTxnConflict(addr string, a, b coin.Transaction) error
 	filterKnownUnconfirmed(txns []cipher.SHA256) ([]cipher.SHA256, error)
 	getKnownUnconfirmed(txns []cipher.SHA256) (coin.Transactions, error)
 	requestBlocksFromAddr(addr string) error
@@ -258,8 +380,14 @@ type daemoner interface {
 	daemonConfig() DaemonConfig
 	pexConfig() pex.Config
 	injectTransaction(txn coin.Transaction) (bool, *visor.ErrTxnViolatesSoftConstraint, error)
+	recordTxnsAccepted(addr string, txns []cipher.SHA256)
+	recordTxnReceived(addr string, txid cipher.SHA256)
+	recordTxnRejected(addr string, txid cipher.SHA256, err error, soft bool)
+	recordTxnsRequested(addr string, txids []cipher.SHA256)
 	recordMessageEvent(m asyncMessage, c *gnet.MessageContext) error
 	connectionIntroduced(addr string, gnetID uint64, m *IntroductionMessage) (*connection, error)
+	connectionAuthenticated(addr string, gnetID uint64, sig cipher.Sig) error
+	sendNodeAuth(addr string, challenge cipher.SHA256) error
 	sendRandomPeers(addr string) error
 }
 
@@ -274,11 +402,24 @@ type Daemon struct {
 	pex      *pex.Pex
 	Gateway  *Gateway
 	visor    *visor.Visor
+	// LAN discovery. nil if disabled
+	lan *lan.Discovery
 
 	// Cache of announced transactions that are flushed to the database periodically
 	announcedTxns *announcedTxnsCache
+	// Transactions created by local wallets, which are rebroadcast until confirmed
+	txnBroadcasts *txnBroadcasts
+	// Relay state of unconfirmed transactions, for debugging transaction propagation
+	txnRelays *txnRelays
+	// Outputs spent by conflicting transactions
+	txnConflicts *txnConflicts
+	// Block publishing policy and stats. Only used by the block publisher
+	blockPublisher *blockPublisher
 	// Cache of connection metadata
 	connections *Connections
+	// Node key, which identifies this node to peers that pin it as a trusted peer
+	nodePubKey cipher.PubKey
+	nodeSecKey cipher.SecKey
 	// connect, disconnect, message, error events channel
 	events chan interface{}
 	// quit channel
@@ -304,6 +445,12 @@ func NewDaemon(config Config, db *dbutil.DB) (*Daemon, error) {
 		return nil, err
 	}
 
+	nodePubKey, nodeSecKey, err := loadNodeKey(config.Daemon.DataDirectory)
+	if err != nil {
+		return nil, err
+	}
+	logger.WithField("nodePubKey", nodePubKey.Hex()).Info("Loaded node key")
+
 	d := &Daemon{
 		Config:   config.Daemon,
 		Messages: NewMessages(config.Messages),
@@ -311,17 +458,37 @@ func NewDaemon(config Config, db *dbutil.DB) (*Daemon, error) {
 		visor:    vs,
 
 		announcedTxns: newAnnouncedTxnsCache(),
+		txnBroadcasts: newTxnBroadcasts(config.Daemon.RebroadcastInitialDelay, config.Daemon.RebroadcastMaxDelay),
+		txnRelays:     newTxnRelays(config.Daemon.TxnRelayRetention, config.Daemon.TxnRelayMaxTxns, config.Daemon.TxnRelayMaxRejectedTxns),
+		txnConflicts:  newTxnConflicts(config.Daemon.TxnConflictRetention, config.Daemon.TxnConflictRelayRate),
 		connections:   NewConnections(),
+		nodePubKey:    nodePubKey,
+		nodeSecKey:    nodeSecKey,
 		events:        make(chan interface{}, config.Pool.EventChannelSize),
 		quit:          make(chan struct{}),
 		done:          make(chan struct{}),
 	}
 
+	d.blockPublisher = newBlockPublisher(BlockPublishPolicy{
+		MaxLatency: time.Duration(config.Daemon.BlockCreationInterval) * time.Second,
+		MinSpacing: config.Daemon.BlockPublishMinSpacing,
+		BurstSize:  config.Daemon.BlockPublishBurstSize,
+		Heartbeat:  config.Daemon.BlockPublishHeartbeat,
+	}, time.Now().UTC())
+
 	d.pool, err = NewPool(config.Pool, d)
 	if err != nil {
 		return nil, err
 	}
 
+	if config.LAN.Enabled {
+		config.LAN.NodePubKey = nodePubKey
+		d.lan, err = lan.New(config.LAN, d.addLANPeer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	d.Gateway = NewGateway(config.Gateway, d)
 	d.Messages.Config.Register()
 
@@ -375,6 +542,11 @@ func (dm *Daemon) Shutdown() {
 	logger.Info("Shutting down Pex")
 	dm.pex.Shutdown()
 
+	if dm.lan != nil {
+		logger.Info("Shutting down LAN discovery")
+		dm.lan.Shutdown()
+	}
+
 	<-dm.done
 }
 
@@ -410,6 +582,17 @@ func (dm *Daemon) Run() error {
 		}
 	}()
 
+	if dm.lan != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := dm.lan.Run(); err != nil {
+				logger.WithError(err).Error("daemon.lan.Run failed")
+				errC <- err
+			}
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -426,18 +609,21 @@ func (dm *Daemon) Run() error {
 		}
 	}()
 
-	blockInterval := time.Duration(dm.Config.BlockCreationInterval)
-	blockCreationTicker := time.NewTicker(time.Second * blockInterval)
+	blockPublishTicker := time.NewTicker(dm.Config.BlockPublishCheckRate)
 	if !dm.visor.Config.IsBlockPublisher {
-		blockCreationTicker.Stop()
+		blockPublishTicker.Stop()
 	}
 
 	unconfirmedRefreshTicker := time.NewTicker(dm.Config.UnconfirmedRefreshRate)
 	defer unconfirmedRefreshTicker.Stop()
 	unconfirmedRemoveInvalidTicker := time.NewTicker(dm.Config.UnconfirmedRemoveInvalidRate)
 	defer unconfirmedRemoveInvalidTicker.Stop()
+	rebroadcastTicker := time.NewTicker(dm.Config.RebroadcastCheckRate)
+	defer rebroadcastTicker.Stop()
 	blocksRequestTicker := time.NewTicker(dm.Config.BlocksRequestRate)
 	defer blocksRequestTicker.Stop()
+	peerQualityTicker := time.NewTicker(dm.Config.PeerQualityRate)
+	defer peerQualityTicker.Stop()
 	blocksAnnounceTicker := time.NewTicker(dm.Config.BlocksAnnounceRate)
 	defer blocksAnnounceTicker.Stop()
 
@@ -620,23 +806,11 @@ loop:
 				logger.WithError(err).Error()
 			}
 
-		case <-blockCreationTicker.C:
-			// Create blocks, if block publisher
-			elapser.Register("blockCreationTicker.C")
+		case <-blockPublishTicker.C:
+			// Create blocks according to the block publishing policy, if block publisher
+			elapser.Register("blockPublishTicker.C")
 			if dm.visor.Config.IsBlockPublisher {
-				sb, err := dm.createAndPublishBlock()
-				if err != nil {
-					logger.WithError(err).Error("Failed to create and publish block")
-					continue
-				}
-
-				// Not a critical error, but we want it visible in logs
-				head := sb.Block.Head
-				logger.Critical().WithFields(logrus.Fields{
-					"version": head.Version,
-					"seq":     head.BkSeq,
-					"time":    head.Time,
-				}).Info("Created and published a new block")
+				dm.publishBlocks()
 			}
 
 		case <-unconfirmedRefreshTicker.C:
@@ -664,12 +838,27 @@ loop:
 				logger.Infof("Remove %d txns from pool that began violating hard constraints", len(removedTxns))
 			}
 
+		case <-rebroadcastTicker.C:
+			elapser.Register("rebroadcastTicker")
+			// Rebroadcast the transactions created by local wallets until they are confirmed
+			if !dm.Config.DisableNetworking {
+				dm.rebroadcastWalletTxns()
+				dm.txnRelays.purge(time.Now().UTC())
+				dm.txnConflicts.purge(time.Now().UTC())
+			}
+
 		case <-blocksRequestTicker.C:
 			elapser.Register("blocksRequestTicker")
 			if err := dm.requestBlocks(); err != nil {
 				logger.WithError(err).Warning("requestBlocks failed")
 			}
 
+		case <-peerQualityTicker.C:
+			elapser.Register("peerQualityTicker")
+			if !dm.Config.DisableNetworking {
+				dm.updatePeerQuality()
+			}
+
 		case <-blocksAnnounceTicker.C:
 			elapser.Register("blocksAnnounceTicker")
 			if err := dm.announceBlocks(); err != nil {
@@ -696,7 +885,7 @@ loop:
 // the connectionErrors channel.
 func (dm *Daemon) connectToPeer(p pex.Peer) error {
 	if dm.Config.DisableOutgoingConnections {
-		return errors.New("Outgoing connections disabled")
+		return ErrOutgoingConnectionsDisabled
 	}
 
 	a, _, err := iputil.SplitAddr(p.Addr)
@@ -706,16 +895,16 @@ func (dm *Daemon) connectToPeer(p pex.Peer) error {
 	}
 
 	if dm.Config.LocalhostOnly && !iputil.IsLocalhost(a) {
-		return errors.New("Not localhost")
+		return ErrPeerNotLocalhost
 	}
 
 	if c := dm.connections.get(p.Addr); c != nil {
-		return errors.New("Already connected to this peer")
+		return ErrAlreadyConnected
 	}
 
 	cnt := dm.connections.IPCount(a)
 	if !dm.Config.LocalhostOnly && cnt != 0 {
-		return errors.New("Already connected to a peer with this base IP")
+		return ErrAlreadyConnectedIP
 	}
 
 	logger.WithField("addr", p.Addr).Debug("Establishing outgoing connection")
@@ -752,6 +941,27 @@ func (dm *Daemon) makePrivateConnections() {
 	}
 }
 
+// addLANPeer adds a peer discovered on the local network to the peer list as a private peer.
+// Private peers are connected to by makePrivateConnections, and are not shared with other peers
+func (dm *Daemon) addLANPeer(addr string, nodePubKey cipher.PubKey) {
+	fields := logrus.Fields{
+		"addr":       addr,
+		"nodePubKey": nodePubKey.Hex(),
+	}
+
+	if err := dm.pex.AddPeer(addr); err != nil {
+		logger.WithError(err).WithFields(fields).Warning("Failed to add LAN peer")
+		return
+	}
+
+	if err := dm.pex.SetPrivate(addr, true); err != nil {
+		logger.WithError(err).WithFields(fields).Error("pex.SetPrivate for LAN peer failed")
+		return
+	}
+
+	logger.WithFields(fields).Debug("Added LAN peer")
+}
+
 // connectToTrustedPeers tries to connect to all trusted peers
 func (dm *Daemon) connectToTrustedPeers() {
 	if dm.Config.DisableOutgoingConnections {
@@ -778,9 +988,12 @@ func (dm *Daemon) maybeConnectToTrustedPeer() error {
 
 	peers := dm.pex.TrustedPublic()
 	for _, p := range peers {
-		// Don't make a connection if we have a trusted peer connection
-		if len(dm.connections.getByListenAddr(p.Addr)) != 0 {
-			return nil
+		// Don't make a connection if we have a trusted peer connection.
+		// Introduced connections to peers pinned by their node pubkey only count once they are authenticated
+		for _, c := range dm.connections.getByListenAddr(p.Addr) {
+			if !c.HasIntroduced() || dm.isTrustedConnection(*c) {
+				return nil
+			}
 		}
 	}
 
@@ -816,8 +1029,18 @@ func (dm *Daemon) connectToRandomPeer() {
 		return
 	}
 
-	// Make a connection to a random (public) peer
-	peers := dm.pex.RandomPublic(dm.Config.MaxOutgoingConnections - dm.connections.OutgoingLen())
+	// Make connections to random (public) peers, preferring peers with a higher quality score
+	n := dm.Config.MaxOutgoingConnections - dm.connections.OutgoingLen()
+	var peers pex.Peers
+	if dm.Config.peerVersions.hasPreferred() {
+		// Fill the outgoing slots with peers last seen with a preferred version first
+		peers = dm.Config.peerVersions.preferPeers(dm.pex.BestPublic(0))
+		if len(peers) > n {
+			peers = peers[:n]
+		}
+	} else {
+		peers = dm.pex.BestPublic(n)
+	}
 	for _, p := range peers {
 		if err := dm.connectToPeer(p); err != nil {
 			logger.WithError(err).WithField("addr", p.Addr).Warning("connectToPeer failed")
@@ -856,6 +1079,17 @@ func (dm *Daemon) isTrustedPeer(addr string) bool {
 	return peer.Trusted
 }
 
+// isTrustedConnection returns true if the connection's peer is trusted.
+// If the trusted peer is pinned by its node pubkey, the connection must have proven that it owns the pubkey
+func (dm *Daemon) isTrustedConnection(c connection) bool {
+	peer, ok := dm.pex.GetPeer(c.peerAddr())
+	if !ok {
+		return false
+	}
+
+	return c.trustedAs(peer)
+}
+
 // recordMessageEvent records an asyncMessage to the messageEvent chan.  Do not access
 // messageEvent directly.
 func (dm *Daemon) recordMessageEvent(m asyncMessage, c *gnet.MessageContext) error {
@@ -958,6 +1192,12 @@ func (dm *Daemon) onConnectEvent(e ConnectEvent) {
 		return
 	}
 
+	challenge := newAuthChallenge()
+	if err := dm.connections.setAuthChallenge(e.Addr, e.GnetID, challenge); err != nil {
+		logger.Critical().WithError(err).WithFields(fields).Error("connections.setAuthChallenge failed")
+		return
+	}
+
 	logger.WithFields(fields).Debug("Sending introduction message")
 
 	if err := dm.sendMessage(e.Addr, NewIntroductionMessage(
@@ -967,6 +1207,10 @@ func (dm *Daemon) onConnectEvent(e ConnectEvent) {
 		dm.Config.BlockchainPubkey,
 		dm.Config.userAgent,
 		dm.Config.UnconfirmedVerifyTxn,
+		dm.nodePubKey,
+		challenge,
+		dm.Config.BlocksOnly,
+		!dm.Config.DisableCompression,
 	)); err != nil {
 		logger.WithFields(fields).WithError(err).Error("Send IntroductionMessage failed")
 		return
@@ -1078,6 +1322,18 @@ func (dm *Daemon) handleMessageSendResult(r gnet.SendResult) {
 
 	if m, ok := r.Message.(SendingTxnsMessage); ok {
 		dm.announcedTxns.add(m.GetFiltered())
+		dm.txnRelays.announced(r.Addr, m.GetFiltered(), time.Now().UTC())
+	}
+
+	switch r.Message.(type) {
+	case *PingMessage:
+		if err := dm.connections.pingSent(r.Addr, time.Now().UTC(), pingTimeout); err != nil {
+			logger.WithError(err).WithField("addr", r.Addr).Debug("connections.pingSent failed")
+		}
+	case *GetBlocksMessage:
+		if err := dm.connections.blocksRequested(r.Addr, time.Now().UTC()); err != nil {
+			logger.WithError(err).WithField("addr", r.Addr).Debug("connections.blocksRequested failed")
+		}
 	}
 
 	if m, ok := r.Message.(*DisconnectMessage); ok {
@@ -1087,7 +1343,9 @@ func (dm *Daemon) handleMessageSendResult(r gnet.SendResult) {
 	}
 }
 
-// requestBlocks sends a GetBlocksMessage to all connections
+// requestBlocks sends a GetBlocksMessage to the highest quality connections which could have new blocks.
+// If Config.BlocksRequestPeers is 0 or no connection reported a height greater than ours,
+// the message is sent to all connections
 func (dm *Daemon) requestBlocks() error {
 	if dm.Config.DisableNetworking {
 		return ErrNetworkingDisabled
@@ -1103,7 +1361,16 @@ func (dm *Daemon) requestBlocks() error {
 
 	m := NewGetBlocksMessage(headSeq, dm.Config.BlocksResponseCount)
 
-	if _, err := dm.broadcastMessage(m); err != nil {
+	addrs := dm.blocksRequestAddrs(headSeq)
+	if len(addrs) == 0 {
+		if _, err := dm.broadcastMessage(m); err != nil {
+			logger.WithError(err).Debug("Broadcast GetBlocksMessage failed")
+			return err
+		}
+		return nil
+	}
+
+	if _, err := dm.pool.Pool.BroadcastMessage(m, addrs); err != nil {
 		logger.WithError(err).Debug("Broadcast GetBlocksMessage failed")
 		return err
 	}
@@ -1111,6 +1378,119 @@ func (dm *Daemon) requestBlocks() error {
 	return nil
 }
 
+// blocksRequestAddrs returns the addresses of the Config.BlocksRequestPeers highest quality introduced
+// connections that reported a height greater than headSeq, or that have not reported a height yet.
+// Returns nil if Config.BlocksRequestPeers is 0 or there are no such connections
+func (dm *Daemon) blocksRequestAddrs(headSeq uint64) []string {
+	if dm.Config.BlocksRequestPeers == 0 {
+		return nil
+	}
+
+	var conns []connection
+	for _, c := range dm.connections.all() {
+		if c.HasIntroduced() && (c.Height == 0 || c.Height > headSeq) {
+			conns = append(conns, c)
+		}
+	}
+
+	sortConnectionsByQuality(conns, dm.peerQuality)
+
+	if len(conns) > dm.Config.BlocksRequestPeers {
+		conns = conns[:dm.Config.BlocksRequestPeers]
+	}
+
+	addrs := make([]string, len(conns))
+	for i, c := range conns {
+		addrs[i] = c.Addr
+	}
+
+	return addrs
+}
+
+// peerQuality returns the quality score of a connection's peer in the peer list
+func (dm *Daemon) peerQuality(c connection) float64 {
+	p, ok := dm.pex.GetPeer(c.peerAddr())
+	if !ok {
+		return pex.DefaultPeerQuality
+	}
+	return p.QualityScore()
+}
+
+// updatePeerQuality expires unanswered block requests, records the quality of connections in the peer list
+// and disconnects outgoing connections whose reported height is stale
+func (dm *Daemon) updatePeerQuality() {
+	headSeq, ok, err := dm.visor.HeadBkSeq()
+	if err != nil {
+		logger.WithError(err).Error("updatePeerQuality dm.visor.HeadBkSeq failed")
+		return
+	}
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	dm.connections.expireBlocksRequests(headSeq, now, dm.Config.BlocksRequestTimeout)
+
+	for _, c := range dm.connections.all() {
+		if !c.HasIntroduced() {
+			continue
+		}
+
+		dm.recordConnectionQuality(c)
+
+		if dm.isStaleHeight(c, headSeq, now) {
+			logger.WithFields(logrus.Fields{
+				"addr":    c.Addr,
+				"height":  c.Height,
+				"headSeq": headSeq,
+			}).Info("Disconnecting peer with a stale height")
+
+			if err := dm.pex.RecordQuality(c.Addr, 0); err != nil {
+				logger.WithError(err).WithField("addr", c.Addr).Debug("pex.RecordQuality failed")
+			}
+
+			if err := dm.Disconnect(c.Addr, ErrDisconnectStaleHeight); err != nil {
+				logger.WithError(err).WithField("addr", c.Addr).Error("Disconnect")
+			}
+		}
+	}
+}
+
+// recordConnectionQuality records the connection's measured quality in the peer list
+func (dm *Daemon) recordConnectionQuality(c connection) {
+	q, ok := c.quality()
+	if !ok {
+		return
+	}
+
+	addr := c.peerAddr()
+	if addr == "" {
+		return
+	}
+
+	if err := dm.pex.RecordQuality(addr, q); err != nil {
+		logger.WithError(err).WithField("addr", addr).Debug("pex.RecordQuality failed")
+	}
+}
+
+// isStaleHeight returns true if the connection is an outgoing, untrusted connection whose reported height
+// lags behind headSeq by more than Config.MaxPeerHeightLag and has not increased for Config.StaleHeightTimeout
+func (dm *Daemon) isStaleHeight(c connection, headSeq uint64, now time.Time) bool {
+	if dm.Config.MaxPeerHeightLag == 0 || !c.Outgoing || c.HeightUpdatedAt.IsZero() {
+		return false
+	}
+
+	if c.Height+dm.Config.MaxPeerHeightLag >= headSeq {
+		return false
+	}
+
+	if now.Sub(c.HeightUpdatedAt) <= dm.Config.StaleHeightTimeout {
+		return false
+	}
+
+	return !dm.isTrustedConnection(c)
+}
+
 // announceBlocks sends an AnnounceBlocksMessage to all connections
 func (dm *Daemon) announceBlocks() error {
 	if dm.Config.DisableNetworking {
@@ -1135,19 +1515,20 @@ func (dm *Daemon) announceBlocks() error {
 	return nil
 }
 
-// announceTxns announces given transaction hashes
+// announceTxns announces given transaction hashes.
+// Nothing is announced in blocks-only mode
 func (dm *Daemon) announceTxns(txns []cipher.SHA256) error {
 	if dm.Config.DisableNetworking {
 		return ErrNetworkingDisabled
 	}
 
-	if len(txns) == 0 {
+	if len(txns) == 0 || dm.Config.BlocksOnly {
 		return nil
 	}
 
 	m := NewAnnounceTxnsMessage(txns)
 
-	if _, err := dm.broadcastMessage(m); err != nil {
+	if _, err := dm.broadcastTxnsMessage(m); err != nil {
 		logger.WithError(err).Debug("Broadcast AnnounceTxnsMessage failed")
 		return err
 	}
@@ -1177,6 +1558,76 @@ func (dm *Daemon) createAndPublishBlock() (*coin.SignedBlock, error) {
 	return &sb, err
 }
 
+// createAndPublishEmptyBlock creates a block without transactions and sends it to the network.
+// It returns visor.ErrEmptyBlockNotActivated before the empty block activation height,
+// and otherwise behaves like createAndPublishBlock
+func (dm *Daemon) createAndPublishEmptyBlock() (*coin.SignedBlock, error) {
+	if dm.Config.DisableNetworking {
+		return nil, ErrNetworkingDisabled
+	}
+
+	sb, err := dm.visor.CreateAndExecuteEmptyBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	err = dm.broadcastBlock(sb)
+
+	return &sb, err
+}
+
+// publishBlocks creates and publishes a block, or an empty heartbeat block,
+// if the block publishing policy calls for it
+func (dm *Daemon) publishBlocks() {
+	n, size, err := dm.visor.GetUnconfirmedPoolSize()
+	if err != nil {
+		logger.WithError(err).Error("dm.visor.GetUnconfirmedPoolSize failed")
+		return
+	}
+
+	now := time.Now().UTC()
+	action := dm.blockPublisher.next(now, n, size)
+
+	var sb *coin.SignedBlock
+	switch action {
+	case blockPublishNone:
+		return
+
+	case blockPublishHeartbeat:
+		sb, err = dm.createAndPublishEmptyBlock()
+		if err == visor.ErrEmptyBlockNotActivated {
+			logger.WithField("activationHeight", params.EmptyBlockActivationHeight).Debug("Skipping heartbeat block, empty blocks are not activated yet")
+			dm.blockPublisher.heartbeatSkipped(now)
+			return
+		}
+
+	default:
+		sb, err = dm.createAndPublishBlock()
+	}
+
+	if sb == nil {
+		dm.blockPublisher.failed(now)
+		logger.WithError(err).Error("Failed to create and publish block")
+		return
+	}
+
+	dm.blockPublisher.published(now, action)
+	if err != nil {
+		logger.WithError(err).Error("Created a new block but failed to publish it")
+	}
+
+	// Not a critical error, but we want it visible in logs
+	head := sb.Block.Head
+	logger.Critical().WithFields(logrus.Fields{
+		"version":   head.Version,
+		"seq":       head.BkSeq,
+		"time":      head.Time,
+		"reason":    action,
+		"poolTxns":  n,
+		"poolBytes": size,
+	}).Info("Created and published a new block")
+}
+
 // ResendUnconfirmedTxns resends all unconfirmed transactions and returns the hashes that were successfully rebroadcast.
 // It does not return an error if broadcasting fails.
 func (dm *Daemon) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
@@ -1200,14 +1651,14 @@ func (dm *Daemon) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
 	return txids, nil
 }
 
-// BroadcastTransaction broadcasts a single transaction to all peers.
+// BroadcastTransaction broadcasts a single transaction to all peers that are not in blocks-only mode.
 func (dm *Daemon) BroadcastTransaction(txn coin.Transaction) ([]uint64, error) {
 	if dm.Config.DisableNetworking {
 		return nil, ErrNetworkingDisabled
 	}
 
 	m := NewGiveTxnsMessage(coin.Transactions{txn})
-	ids, err := dm.broadcastMessage(m)
+	ids, err := dm.broadcastTxnsMessage(m)
 	if err != nil {
 		logger.WithError(err).Error("Broadcast GiveTxnsMessage failed")
 		return nil, err
@@ -1235,9 +1686,95 @@ func (dm *Daemon) BroadcastUserTransaction(txn coin.Transaction, head *coin.Sign
 
 	logger.Debugf("BroadcastUserTransaction transaction propagated by %d/%d conns", accepts, len(ids))
 
+	// Rebroadcast the transaction until it is confirmed
+	owners := make([]cipher.Address, 0, len(inputs))
+	for _, ux := range inputs {
+		owners = append(owners, ux.Body.Address)
+	}
+	dm.txnBroadcasts.add(txn, owners, time.Now().UTC())
+
 	return nil
 }
 
+// rebroadcastWalletTxns updates the status of the transactions created by local wallets,
+// and rebroadcasts the unconfirmed ones that are due
+func (dm *Daemon) rebroadcastWalletTxns() {
+	now := time.Now().UTC()
+
+	for _, txid := range dm.txnBroadcasts.pending() {
+		status, err := dm.walletTxnStatus(txid)
+		if err != nil {
+			logger.WithError(err).WithField("txid", txid.Hex()).Error("walletTxnStatus failed")
+			continue
+		}
+
+		if status != TxnBroadcastPending {
+			logger.WithFields(logrus.Fields{
+				"txid":   txid.Hex(),
+				"status": status,
+			}).Info("Stop rebroadcasting transaction")
+		}
+
+		dm.txnBroadcasts.setStatus(txid, status, now)
+	}
+
+	for _, txid := range dm.txnBroadcasts.due(now) {
+		txn, err := dm.visor.GetUnconfirmedTxn(txid)
+		if err != nil {
+			logger.WithError(err).WithField("txid", txid.Hex()).Error("GetUnconfirmedTxn failed")
+			continue
+		}
+
+		// Peers will not propagate transactions that violate soft constraints, so wait until it is valid again
+		if txn == nil || txn.IsValid == 0 {
+			dm.txnBroadcasts.rebroadcast(txid, false, now)
+			continue
+		}
+
+		logger.WithField("txid", txid.Hex()).Debug("Rebroadcast wallet transaction")
+		_, err = dm.BroadcastTransaction(txn.Transaction)
+		dm.txnBroadcasts.rebroadcast(txid, err == nil, now)
+	}
+
+	dm.txnBroadcasts.purge(now)
+}
+
+// walletTxnStatus returns the broadcast status of a transaction created by a local wallet
+func (dm *Daemon) walletTxnStatus(txid cipher.SHA256) (TxnBroadcastStatus, error) {
+	txn, err := dm.visor.GetTransaction(txid)
+	if err != nil {
+		return "", err
+	}
+
+	if txn != nil {
+		if txn.Status.Confirmed {
+			return TxnBroadcastConfirmed, nil
+		}
+		return TxnBroadcastPending, nil
+	}
+
+	// The transaction was dropped from the unconfirmed pool.
+	// If any of its inputs were spent by a different transaction, it was conflicted,
+	// otherwise it became invalid.
+	tb, ok := dm.txnBroadcasts.get(txid)
+	if !ok {
+		return "", fmt.Errorf("transaction %s is not tracked", txid.Hex())
+	}
+
+	for _, in := range tb.inputs {
+		ux, err := dm.visor.GetUxOutByID(in)
+		if err != nil {
+			return "", err
+		}
+
+		if ux != nil && !ux.SpentTxnID.Null() && ux.SpentTxnID != txid {
+			return TxnBroadcastConflicted, nil
+		}
+	}
+
+	return TxnBroadcastInvalid, nil
+}
+
 // checkBroadcastTxnRecipients checks whether or not the recipients of a txn broadcast would accept the transaction as valid,
 // based upon their reported txn verification parameters.
 // If no recipient would accept the txn, an error is returned.
@@ -1388,6 +1925,15 @@ func (dm *Daemon) connectionIntroduced(addr string, gnetID uint64, m *Introducti
 		"listenAddr": listenAddr,
 	}
 
+	// A trusted peer that is pinned by its node pubkey must report that pubkey
+	if p, ok := dm.pex.GetPeer(listenAddr); ok && p.Trusted && !p.PubKey.Null() && p.PubKey != c.NodePubKey {
+		logger.WithFields(fields).WithFields(logrus.Fields{
+			"nodePubKey":       c.NodePubKey.Hex(),
+			"pinnedNodePubKey": p.PubKey.Hex(),
+		}).Warning("Trusted peer's node pubkey does not match")
+		return nil, ErrDisconnectNodePubKeyNotMatched
+	}
+
 	if c.Outgoing {
 		// For successful outgoing connections, mark the peer as having an incoming port in the pex peerlist
 		// The peer should already be in the peerlist, since we use the peerlist to choose an outgoing connection to make
@@ -1410,9 +1956,31 @@ func (dm *Daemon) connectionIntroduced(addr string, gnetID uint64, m *Introducti
 
 	dm.pex.ResetRetryTimes(listenAddr)
 
+	// Compress messages to peers that can decompress them
+	if m.compression && !dm.Config.DisableCompression {
+		if err := dm.pool.Pool.EnableCompression(addr); err != nil {
+			logger.WithError(err).WithFields(fields).Warning("pool.EnableCompression failed")
+		}
+	}
+
 	return c, nil
 }
 
+// connectionAuthenticated verifies a node auth signature from a connection.
+// Returns ErrDisconnectInvalidNodeAuth if the signature is invalid
+func (dm *Daemon) connectionAuthenticated(addr string, gnetID uint64, sig cipher.Sig) error {
+	return dm.connections.authenticate(addr, gnetID, sig)
+}
+
+// sendNodeAuth signs the auth challenge of a peer's IntroductionMessage with the node key
+func (dm *Daemon) sendNodeAuth(addr string, challenge cipher.SHA256) error {
+	if dm.nodePubKey.Null() {
+		return errors.New("Node key is not set")
+	}
+
+	return dm.sendMessage(addr, NewNodeAuthMessage(challenge, dm.nodeSecKey))
+}
+
 // sendRandomPeers sends a random sample of peers to another peer
 func (dm *Daemon) sendRandomPeers(addr string) error {
 	peers := dm.pex.RandomExchangeable(dm.pex.Config.ReplyCount)
@@ -1425,12 +1993,17 @@ func (dm *Daemon) sendRandomPeers(addr string) error {
 	return dm.sendMessage(addr, m)
 }
 
-// announceAllValidTxns broadcasts valid unconfirmed transactions
+// announceAllValidTxns broadcasts valid unconfirmed transactions.
+// The unconfirmed pool is not relayed in blocks-only mode
 func (dm *Daemon) announceAllValidTxns() error {
 	if dm.Config.DisableNetworking {
 		return ErrNetworkingDisabled
 	}
 
+	if dm.Config.BlocksOnly {
+		return nil
+	}
+
 	// Get valid unconfirmed transaction hashes
 	hashes, err := dm.visor.GetAllValidUnconfirmedTxHashes()
 	if err != nil {
@@ -1442,7 +2015,7 @@ func (dm *Daemon) announceAllValidTxns() error {
 
 	for _, hs := range hashesSet {
 		m := NewAnnounceTxnsMessage(hs)
-		if _, err := dm.broadcastMessage(m); err != nil {
+		if _, err := dm.broadcastTxnsMessage(m); err != nil {
 			logger.WithError(err).Debug("Broadcast AnnounceTxnsMessage failed")
 			return err
 		}
@@ -1501,6 +2074,24 @@ func (dm *Daemon) broadcastMessage(msg gnet.Message) ([]uint64, error) {
 	return dm.pool.Pool.BroadcastMessage(msg, addrs)
 }
 
+// broadcastTxnsMessage sends a transaction announcement or transactions to all introduced connections
+// that are not in blocks-only mode
+func (dm *Daemon) broadcastTxnsMessage(msg gnet.Message) ([]uint64, error) {
+	if dm.Config.DisableNetworking {
+		return nil, ErrNetworkingDisabled
+	}
+
+	conns := dm.connections.all()
+	var addrs []string
+	for _, c := range conns {
+		if c.HasIntroduced() && !c.BlocksOnly {
+			addrs = append(addrs, c.Addr)
+		}
+	}
+
+	return dm.pool.Pool.BroadcastMessage(msg, addrs)
+}
+
 // disconnectNow disconnects from a peer immediately without sending a DisconnectMessage. Any pending messages
 // will not be sent to the peer.
 func (dm *Daemon) disconnectNow(addr string, r gnet.DisconnectReason) error {
@@ -1524,6 +2115,30 @@ func (dm *Daemon) recordPeerHeight(addr string, gnetID, height uint64) {
 	}
 }
 
+// recordBlocksReceived records the delivery of blocks by a peer. failed is true if a block failed to execute
+func (dm *Daemon) recordBlocksReceived(addr string, gnetID uint64, failed bool) {
+	if err := dm.connections.blocksReceived(addr, gnetID, failed, time.Now().UTC()); err != nil {
+		logger.WithError(err).WithField("addr", addr).Debug("connections.blocksReceived failed")
+	}
+}
+
+// recordPong measures the connection's RTT from the ping that the pong replies to
+func (dm *Daemon) recordPong(addr string, gnetID uint64) {
+	rtt, err := dm.connections.pongReceived(addr, gnetID, time.Now().UTC())
+	if err != nil {
+		logger.WithError(err).WithField("addr", addr).Debug("connections.pongReceived failed")
+		return
+	}
+
+	if dm.Config.LogPings && rtt != 0 {
+		logger.WithFields(logrus.Fields{
+			"addr":   addr,
+			"gnetID": gnetID,
+			"rtt":    rtt,
+		}).Debug("Measured RTT")
+	}
+}
+
 // getSignedBlocksSince returns N signed blocks since given seq
 func (dm *Daemon) getSignedBlocksSince(seq, count uint64) ([]coin.SignedBlock, error) {
 	return dm.visor.GetSignedBlocksSince(seq, count)
@@ -1539,11 +2154,146 @@ func (dm *Daemon) executeSignedBlock(b coin.SignedBlock) error {
 	return dm.visor.ExecuteSignedBlock(b)
 }
 
+// detectDoubleSign compares a block received from a peer with the block at the same seq in the blockchain.
+// If the block publisher signed both, the proof is saved and broadcast
+func (dm *Daemon) detectDoubleSign(addr string, b coin.SignedBlock) {
+	p, added, err := dm.visor.DetectDoubleSign(b, addr)
+	if err != nil {
+		logger.WithError(err).WithField("addr", addr).Error("visor.DetectDoubleSign failed")
+		return
+	}
+
+	if added {
+		dm.broadcastDoubleSignProof(*p)
+	}
+}
+
+// addDoubleSignProof saves a double sign proof received from a peer, and relays it if it was not seen before.
+// Returns visor.ErrInvalidDoubleSignProof if the proof is invalid
+func (dm *Daemon) addDoubleSignProof(p visor.DoubleSignProof) error {
+	added, err := dm.visor.AddDoubleSignProof(p)
+	if err != nil {
+		return err
+	}
+
+	if added {
+		dm.broadcastDoubleSignProof(p)
+	}
+
+	return nil
+}
+
+// broadcastDoubleSignProof sends a double sign proof to all connections
+func (dm *Daemon) broadcastDoubleSignProof(p visor.DoubleSignProof) {
+	if _, err := dm.broadcastMessage(NewDoubleSignProofMessage(p)); err != nil {
+		logger.WithError(err).WithField("seq", p.Seq()).Warning("Broadcast DoubleSignProofMessage failed")
+	}
+}
+
+// detectTxnConflicts records the outputs spent by txn which are also spent by other unconfirmed transactions,
+// and relays the conflicts to peers. addr is the address of the peer that sent txn,
+// or empty if txn was injected into this node
+func (dm *Daemon) detectTxnConflicts(addr string, txn coin.Transaction) {
+	conflicts, err := dm.visor.GetUnconfirmedConflicts(txn)
+	if err != nil {
+		logger.WithError(err).WithField("txid", txn.Hash().Hex()).Error("visor.GetUnconfirmedConflicts failed")
+		return
+	}
+
+	now := time.Now().UTC()
+	txid := txn.Hash()
+	for _, c := range conflicts {
+		if !dm.txnConflicts.add(c.UxOut, []cipher.SHA256{c.Txid, txid}, addr, now) {
+			continue
+		}
+
+		logger.WithFields(logrus.Fields{
+			"addr":  addr,
+			"uxid":  c.UxOut.Hash().Hex(),
+			"txidA": c.Txid.Hex(),
+			"txidB": txid.Hex(),
+		}).Warning("Detected conflicting transactions spending the same output")
+
+		other, err := dm.visor.GetUnconfirmedTxn(c.Txid)
+		if err != nil {
+			logger.WithError(err).WithField("txid", c.Txid.Hex()).Error("visor.GetUnconfirmedTxn failed")
+			continue
+		}
+		if other == nil {
+			continue
+		}
+
+		dm.relayTxnConflict(other.Transaction, txn, now)
+	}
+}
+
+// addTxnConflict records conflict evidence received from a peer, and relays it if it was not seen before.
+// Returns visor.ErrInvalidTxnConflict if the transactions do not both spend an unspent output
+func (dm *Daemon) addTxnConflict(addr string, a, b coin.Transaction) error {
+	uxs, err := dm.visor.VerifyTxnConflict(a, b)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var added bool
+	for _, ux := range uxs {
+		if dm.txnConflicts.add(ux, []cipher.SHA256{a.Hash(), b.Hash()}, addr, now) {
+			added = true
+		}
+	}
+
+	if added {
+		dm.relayTxnConflict(a, b, now)
+	}
+
+	return nil
+}
+
+// relayTxnConflict sends conflicting transactions to all connections that relay transactions,
+// unless the relay rate is exceeded
+func (dm *Daemon) relayTxnConflict(a, b coin.Transaction, now time.Time) {
+	if dm.Config.BlocksOnly {
+		return
+	}
+
+	if !dm.txnConflicts.allowRelay(now) {
+		logger.WithField("txidB", b.Hash().Hex()).Debug("Transaction conflict relay rate exceeded")
+		return
+	}
+
+	if _, err := dm.broadcastTxnsMessage(NewTxnConflictMessage(a, b)); err != nil {
+		logger.WithError(err).Warning("Broadcast TxnConflictMessage failed")
+	}
+}
+
 // filterKnownUnconfirmed returns unconfirmed txn hashes with known ones removed
 func (dm *Daemon) filterKnownUnconfirmed(txns []cipher.SHA256) ([]cipher.SHA256, error) {
 	return dm.visor.FilterKnownUnconfirmed(txns)
 }
 
+// recordTxnsAccepted records that a peer announced transactions to us.
+// A peer announces a transaction after accepting it into its unconfirmed pool.
+func (dm *Daemon) recordTxnsAccepted(addr string, txns []cipher.SHA256) {
+	dm.txnBroadcasts.accepted(addr, txns)
+}
+
+// recordTxnReceived records that a transaction received from a peer was added to the unconfirmed pool
+func (dm *Daemon) recordTxnReceived(addr string, txid cipher.SHA256) {
+	dm.txnRelays.received(addr, txid, time.Now().UTC())
+}
+
+// recordTxnRejected records why a transaction received from a peer was rejected.
+// soft is true if the transaction was still added to the unconfirmed pool, and then recordTxnReceived is called too
+func (dm *Daemon) recordTxnRejected(addr string, txid cipher.SHA256, err error, soft bool) {
+	dm.txnRelays.rejected(addr, txid, err.Error(), soft, time.Now().UTC())
+}
+
+// recordTxnsRequested records that a peer requested transactions from us
+func (dm *Daemon) recordTxnsRequested(addr string, txids []cipher.SHA256) {
+	dm.txnRelays.requested(addr, txids, time.Now().UTC())
+}
+
 // getKnownUnconfirmed returns unconfirmed txn hashes with known ones removed
 func (dm *Daemon) getKnownUnconfirmed(txns []cipher.SHA256) (coin.Transactions, error) {
 	return dm.visor.GetKnownUnconfirmed(txns)
@@ -1,6 +1,9 @@
 package daemon
 
 import (
+	"io/ioutil"
+	"os"
+	"sort"
 	"testing"
 	"time"
 
@@ -8,7 +11,9 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon/pex"
 	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/util/fee"
 	"github.com/skycoin/skycoin/src/util/useragent"
 	"github.com/skycoin/skycoin/src/visor"
@@ -340,3 +345,58 @@ func TestCheckBroadcastTxnRecipients(t *testing.T) {
 		})
 	}
 }
+
+func TestAddLANPeer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lan")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := pex.NewConfig()
+	cfg.DataDirectory = dir
+	cfg.AllowLocalhost = true
+	px, err := pex.New(cfg)
+	require.NoError(t, err)
+
+	d := &Daemon{
+		pex: px,
+	}
+
+	d.addLANPeer("192.168.1.5:6000", testutil.MakePubKey())
+	d.addLANPeer("127.0.0.1:6001", testutil.MakePubKey())
+	d.addLANPeer("0.0.0.0:6002", testutil.MakePubKey())
+
+	// LAN peers are private, so that they are not shared with other peers
+	var addrs []string
+	for _, p := range px.Private() {
+		addrs = append(addrs, p.Addr)
+	}
+	sort.Strings(addrs)
+	require.Equal(t, []string{"127.0.0.1:6001", "192.168.1.5:6000"}, addrs)
+	require.Empty(t, px.BestPublic(0))
+}
+
+func TestConfigPreprocessLAN(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Daemon.Port = 6001
+	cfg.Daemon.BlockchainPubkey = testutil.MakePubKey()
+	cfg.Daemon.UserAgent = useragent.MustParse("skycoin:0.25.0")
+	cfg.LAN.Enabled = true
+
+	c, err := cfg.preprocess()
+	require.NoError(t, err)
+	require.True(t, c.LAN.Enabled)
+	require.False(t, c.LAN.DisableAnnounce)
+	require.Equal(t, cfg.Daemon.BlockchainPubkey, c.LAN.ChainID)
+	require.Equal(t, uint16(6001), c.LAN.ListenPort)
+
+	cfg.Daemon.DisableIncomingConnections = true
+	c, err = cfg.preprocess()
+	require.NoError(t, err)
+	require.True(t, c.LAN.Enabled)
+	require.True(t, c.LAN.DisableAnnounce)
+
+	cfg.Daemon.DisableNetworking = true
+	c, err = cfg.preprocess()
+	require.NoError(t, err)
+	require.False(t, c.LAN.Enabled)
+}
@@ -45,6 +45,18 @@ var (
 	ErrDisconnectInvalidMaxTransactionSize gnet.DisconnectReason = errors.New("Invalid max transaction size in introduction message")
 	// ErrDisconnectInvalidMaxDropletPrecision invalid max droplet precision in introduction message
 	ErrDisconnectInvalidMaxDropletPrecision gnet.DisconnectReason = errors.New("Invalid max droplet precision in introduction message")
+	// ErrDisconnectStaleHeight the peer's reported height lags far behind ours and has stopped increasing
+	ErrDisconnectStaleHeight gnet.DisconnectReason = errors.New("Reported block height is stale")
+	// ErrDisconnectNodePubKeyNotMatched the node pubkey in the introduction does not match the pinned pubkey of a trusted peer
+	ErrDisconnectNodePubKeyNotMatched gnet.DisconnectReason = errors.New("Node pubkey does not match")
+	// ErrDisconnectInvalidNodeAuth the node auth signature is invalid
+	ErrDisconnectInvalidNodeAuth gnet.DisconnectReason = errors.New("Invalid node auth signature")
+	// ErrDisconnectPeerVersionTooLow the version in the peer's user agent is below the minimum accepted version for its coin
+	ErrDisconnectPeerVersionTooLow gnet.DisconnectReason = errors.New("User agent version is below the minimum accepted version")
+	// ErrDisconnectBuggyPeerVersion the version in the peer's user agent is known to be buggy
+	ErrDisconnectBuggyPeerVersion gnet.DisconnectReason = errors.New("User agent version is known to be buggy")
+	// ErrDisconnectInvalidDoubleSignProof the peer sent a double sign proof that does not prove double signing
+	ErrDisconnectInvalidDoubleSignProof gnet.DisconnectReason = errors.New("Invalid double sign proof")
 
 	// ErrDisconnectUnknownReason used when mapping an unknown reason code to an error. Is not sent over the network.
 	ErrDisconnectUnknownReason gnet.DisconnectReason = errors.New("Unknown DisconnectReason")
@@ -71,6 +83,12 @@ var (
 		ErrDisconnectInvalidBurnFactor:             17,
 		ErrDisconnectInvalidMaxTransactionSize:     18,
 		ErrDisconnectInvalidMaxDropletPrecision:    19,
+		ErrDisconnectStaleHeight:                   20,
+		ErrDisconnectNodePubKeyNotMatched:          21,
+		ErrDisconnectInvalidNodeAuth:               22,
+		ErrDisconnectPeerVersionTooLow:             23,
+		ErrDisconnectBuggyPeerVersion:              24,
+		ErrDisconnectInvalidDoubleSignProof:        25,
 
 		// gnet codes are registered here, but they are not sent in a DISC
 		// message by gnet. Only daemon sends a DISC packet.
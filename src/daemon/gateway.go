@@ -40,7 +40,9 @@ func NewGatewayConfig() GatewayConfig {
 	}
 }
 
-// Gateway RPC interface wrapper for daemon state
+// Gateway RPC interface wrapper for daemon state.
+// Methods that modify state are serialized through the daemon run loop,
+// all other methods are safe to call concurrently.
 type Gateway struct {
 	Config GatewayConfig
 
@@ -71,13 +73,12 @@ func (gw *Gateway) Shutdown() {
 	gw.strand("wait-shutdown", func() {})
 }
 
+// strand serializes a call through the daemon run loop.
+// Only operations that write to the database or change daemon state are stranded.
+// Read-only queries are called directly; visor reads are performed inside
+// a dbutil.DB.View transaction, which gives them a consistent snapshot of the database
+// and allows them to run concurrently with each other and with the daemon run loop.
 func (gw *Gateway) strand(name string, f func()) {
-	// The Spend() method requires strand to be safe
-	if !gw.Config.EnableSpendMethod {
-		f()
-		return
-	}
-
 	name = fmt.Sprintf("daemon.Gateway.%s", name)
 	if err := strand.Strand(logger, gw.requests, name, func() error {
 		f()
@@ -95,11 +96,42 @@ type Connection struct {
 	ConnectionDetails
 }
 
+// IsTrusted returns true if the connection is to a trusted peer.
+// Connections to trusted peers that are pinned by their node pubkey are only trusted once the peer has proven that it owns the pubkey
+func (c Connection) IsTrusted() bool {
+	return c.trustedAs(c.Pex)
+}
+
+// UnknownUserAgent is the key in the user agent counts of introduced connections that did not send a user agent
+const UnknownUserAgent = "unknown"
+
+// CountUserAgents returns the number of introduced connections by user agent
+func CountUserAgents(conns []Connection) map[string]int {
+	counts := make(map[string]int)
+	for _, c := range conns {
+		if !c.HasIntroduced() {
+			continue
+		}
+
+		userAgent := UnknownUserAgent
+		if !c.UserAgent.Empty() {
+			if ua, err := c.UserAgent.Build(); err == nil {
+				userAgent = ua
+			}
+		}
+
+		counts[userAgent]++
+	}
+	return counts
+}
+
 // GnetConnectionDetails connection data from gnet
 type GnetConnectionDetails struct {
-	ID           uint64
-	LastSent     time.Time
-	LastReceived time.Time
+	ID               uint64
+	LastSent         time.Time
+	LastReceived     time.Time
+	Compression      bool
+	CompressionStats gnet.CompressionStats
 }
 
 func newConnection(dc *connection, gc *gnet.Connection, pp *pex.Peer) Connection {
@@ -112,9 +144,11 @@ func newConnection(dc *connection, gc *gnet.Connection, pp *pex.Peer) Connection
 
 	if gc != nil {
 		c.Gnet = GnetConnectionDetails{
-			ID:           gc.ID,
-			LastSent:     gc.LastSent,
-			LastReceived: gc.LastReceived,
+			ID:               gc.ID,
+			LastSent:         gc.LastSent,
+			LastReceived:     gc.LastReceived,
+			Compression:      gc.CompressionEnabled(),
+			CompressionStats: gc.CompressionStats,
 		}
 	}
 
@@ -151,12 +185,7 @@ func (gw *Gateway) newConnection(c *connection) (*Connection, error) {
 
 // GetConnections returns solicited (outgoing) connections
 func (gw *Gateway) GetConnections(f func(c Connection) bool) ([]Connection, error) {
-	var conns []Connection
-	var err error
-	gw.strand("GetConnections", func() {
-		conns, err = gw.getConnections(f)
-	})
-	return conns, err
+	return gw.getConnections(f)
 }
 
 func (gw *Gateway) getConnections(f func(c Connection) bool) ([]Connection, error) {
@@ -193,21 +222,14 @@ func (gw *Gateway) getConnections(f func(c Connection) bool) ([]Connection, erro
 
 // GetDefaultConnections returns the default hardcoded connection addresses
 func (gw *Gateway) GetDefaultConnections() []string {
-	var conns []string
-	gw.strand("GetDefaultConnections", func() {
-		conns = make([]string, len(gw.d.Config.DefaultConnections))
-		copy(conns[:], gw.d.Config.DefaultConnections[:])
-	})
+	conns := make([]string, len(gw.d.Config.DefaultConnections))
+	copy(conns[:], gw.d.Config.DefaultConnections[:])
 	return conns
 }
 
 // GetConnection returns a *Connection of specific address
 func (gw *Gateway) GetConnection(addr string) (*Connection, error) {
-	var c *connection
-	gw.strand("GetConnection", func() {
-		c = gw.d.connections.get(addr)
-	})
-
+	c := gw.d.connections.get(addr)
 	if c == nil {
 		return nil, nil
 	}
@@ -232,20 +254,130 @@ func (gw *Gateway) Disconnect(gnetID uint64) error {
 
 // GetTrustConnections returns all trusted connections
 func (gw *Gateway) GetTrustConnections() []string {
-	var conn []string
-	gw.strand("GetTrustConnections", func() {
-		conn = gw.d.pex.Trusted().ToAddrs()
-	})
-	return conn
+	return gw.d.pex.Trusted().ToAddrs()
 }
 
 // GetExchgConnection returns all connections to peers found through peer exchange
 func (gw *Gateway) GetExchgConnection() []string {
-	var conn []string
-	gw.strand("GetExchgConnection", func() {
-		conn = gw.d.pex.RandomExchangeable(0).ToAddrs()
+	return gw.d.pex.RandomExchangeable(0).ToAddrs()
+}
+
+// ConnectPeer adds a peer to the peer list and starts an outgoing connection to it.
+// The connection is established asynchronously; its progress can be followed with GetConnection
+func (gw *Gateway) ConnectPeer(addr string) error {
+	if gw.d.Config.DisableNetworking {
+		return ErrNetworkingDisabled
+	}
+
+	var err error
+	gw.strand("ConnectPeer", func() {
+		if err = gw.d.pex.AddPeer(addr); err != nil {
+			return
+		}
+
+		p, ok := gw.d.pex.GetPeer(addr)
+		if !ok {
+			err = ErrPeerNotExist
+			return
+		}
+
+		if err = gw.d.connectToPeer(p); err != nil {
+			return
+		}
+
+		err = gw.d.pex.Save()
+	})
+	return err
+}
+
+// AddPeer adds a peer to the peer list, and saves the peer list
+func (gw *Gateway) AddPeer(addr string) error {
+	var err error
+	gw.strand("AddPeer", func() {
+		if err = gw.d.pex.AddPeer(addr); err != nil {
+			return
+		}
+
+		err = gw.d.pex.Save()
+	})
+	return err
+}
+
+// RemovePeer removes a peer from the peer list, and saves the peer list.
+// Peers in the default connections are added back when the node restarts
+func (gw *Gateway) RemovePeer(addr string) error {
+	var err error
+	gw.strand("RemovePeer", func() {
+		if _, ok := gw.d.pex.GetPeer(addr); !ok {
+			err = ErrPeerNotExist
+			return
+		}
+
+		gw.d.pex.RemovePeer(addr)
+		err = gw.d.pex.Save()
+	})
+	return err
+}
+
+// SetPeerTrusted marks a peer as trusted or untrusted, and saves the peer list
+func (gw *Gateway) SetPeerTrusted(addr string, trusted bool) error {
+	var err error
+	gw.strand("SetPeerTrusted", func() {
+		if _, ok := gw.d.pex.GetPeer(addr); !ok {
+			err = ErrPeerNotExist
+			return
+		}
+
+		if trusted {
+			err = gw.d.pex.SetTrusted(addr)
+		} else {
+			err = gw.d.pex.SetUntrusted(addr)
+		}
+		if err != nil {
+			return
+		}
+
+		err = gw.d.pex.Save()
 	})
-	return conn
+	return err
+}
+
+// SetPeerPrivate marks a peer as private or public, and saves the peer list.
+// Private peers are not shared with other peers
+func (gw *Gateway) SetPeerPrivate(addr string, private bool) error {
+	var err error
+	gw.strand("SetPeerPrivate", func() {
+		if _, ok := gw.d.pex.GetPeer(addr); !ok {
+			err = ErrPeerNotExist
+			return
+		}
+
+		if err = gw.d.pex.SetPrivate(addr, private); err != nil {
+			return
+		}
+
+		err = gw.d.pex.Save()
+	})
+	return err
+}
+
+// ResetPeerRetryTimes clears the connection retry counter of a peer, or of all peers if addr is empty
+func (gw *Gateway) ResetPeerRetryTimes(addr string) error {
+	var err error
+	gw.strand("ResetPeerRetryTimes", func() {
+		if addr == "" {
+			gw.d.pex.ResetAllRetryTimes()
+			return
+		}
+
+		if _, ok := gw.d.pex.GetPeer(addr); !ok {
+			err = ErrPeerNotExist
+			return
+		}
+
+		gw.d.pex.ResetRetryTimes(addr)
+	})
+	return err
 }
 
 /* Blockchain & Transaction status */
@@ -304,22 +436,13 @@ func EstimateBlockchainHeight(headSeq uint64, peers []PeerBlockchainHeight) uint
 
 // GetBlockchainProgress returns a *BlockchainProgress
 func (gw *Gateway) GetBlockchainProgress() (*BlockchainProgress, error) {
-	var headSeq uint64
-	var err error
-	var conns []connection
-	gw.strand("GetBlockchainProgress", func() {
-		headSeq, _, err = gw.v.HeadBkSeq()
-		if err != nil {
-			return
-		}
-
-		conns = gw.d.connections.all()
-	})
-
+	headSeq, _, err := gw.v.HeadBkSeq()
 	if err != nil {
 		return nil, err
 	}
 
+	conns := gw.d.connections.all()
+
 	return newBlockchainProgress(headSeq, conns), nil
 }
 
@@ -336,152 +459,79 @@ func (gw *Gateway) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
 
 // GetBlockchainMetadata returns a *visor.BlockchainMetadata
 func (gw *Gateway) GetBlockchainMetadata() (*visor.BlockchainMetadata, error) {
-	var bcm *visor.BlockchainMetadata
-	var err error
-	gw.strand("GetBlockchainMetadata", func() {
-		bcm, err = gw.v.GetBlockchainMetadata()
-	})
-	return bcm, err
+	return gw.v.GetBlockchainMetadata()
 }
 
 // GetSignedBlockByHash returns the block by hash
 func (gw *Gateway) GetSignedBlockByHash(hash cipher.SHA256) (*coin.SignedBlock, error) {
-	var b *coin.SignedBlock
-	var err error
-	gw.strand("GetSignedBlockByHash", func() {
-		b, err = gw.v.GetSignedBlockByHash(hash)
-	})
-	return b, err
+	return gw.v.GetSignedBlockByHash(hash)
 }
 
 // GetSignedBlockByHashVerbose returns the block by hash with verbose transaction inputs
 func (gw *Gateway) GetSignedBlockByHashVerbose(hash cipher.SHA256) (*coin.SignedBlock, [][]visor.TransactionInput, error) {
-	var b *coin.SignedBlock
-	var inputs [][]visor.TransactionInput
-	var err error
-	gw.strand("GetSignedBlockByHashVerbose", func() {
-		b, inputs, err = gw.v.GetSignedBlockByHashVerbose(hash)
-	})
-	return b, inputs, err
+	return gw.v.GetSignedBlockByHashVerbose(hash)
 }
 
 // GetSignedBlockBySeq returns block by seq
 func (gw *Gateway) GetSignedBlockBySeq(seq uint64) (*coin.SignedBlock, error) {
-	var b *coin.SignedBlock
-	var err error
-	gw.strand("GetSignedBlockBySeq", func() {
-		b, err = gw.v.GetSignedBlockBySeq(seq)
-	})
-	return b, err
+	return gw.v.GetSignedBlockBySeq(seq)
+}
+
+// GetDoubleSignProofs returns the saved proofs that the block publisher signed conflicting blocks
+func (gw *Gateway) GetDoubleSignProofs() ([]visor.DoubleSignProof, error) {
+	return gw.v.GetDoubleSignProofs()
 }
 
 // GetSignedBlockBySeqVerbose returns the block by seq with verbose transaction inputs
 func (gw *Gateway) GetSignedBlockBySeqVerbose(seq uint64) (*coin.SignedBlock, [][]visor.TransactionInput, error) {
-	var b *coin.SignedBlock
-	var inputs [][]visor.TransactionInput
-	var err error
-	gw.strand("GetSignedBlockBySeqVerbose", func() {
-		b, inputs, err = gw.v.GetSignedBlockBySeqVerbose(seq)
-	})
-	return b, inputs, err
+	return gw.v.GetSignedBlockBySeqVerbose(seq)
 }
 
 // GetBlocks returns blocks matching given block sequences
 func (gw *Gateway) GetBlocks(seqs []uint64) ([]coin.SignedBlock, error) {
-	var blocks []coin.SignedBlock
-	var err error
-	gw.strand("GetBlocks", func() {
-		blocks, err = gw.v.GetBlocks(seqs)
-	})
-	return blocks, err
+	return gw.v.GetBlocks(seqs)
 }
 
 // GetBlocksVerbose returns blocks matching given block sequences, with verbose transaction input data
 func (gw *Gateway) GetBlocksVerbose(seqs []uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error) {
-	var blocks []coin.SignedBlock
-	var inputs [][][]visor.TransactionInput
-	var err error
-	gw.strand("GetBlocksVerbose", func() {
-		blocks, inputs, err = gw.v.GetBlocksVerbose(seqs)
-	})
-	return blocks, inputs, err
+	return gw.v.GetBlocksVerbose(seqs)
 }
 
 // GetBlocksInRange returns blocks between start and end, including start and end
 func (gw *Gateway) GetBlocksInRange(start, end uint64) ([]coin.SignedBlock, error) {
-	var blocks []coin.SignedBlock
-	var err error
-	gw.strand("GetBlocksInRange", func() {
-		blocks, err = gw.v.GetBlocksInRange(start, end)
-	})
-	return blocks, err
+	return gw.v.GetBlocksInRange(start, end)
 }
 
 // GetBlocksInRangeVerbose returns blocks between start and end, including start and end,
 // and returns the blocks' verbose transaction input data
 func (gw *Gateway) GetBlocksInRangeVerbose(start, end uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error) {
-	var blocks []coin.SignedBlock
-	var inputs [][][]visor.TransactionInput
-	var err error
-	gw.strand("GetBlocksInRangeVerbose", func() {
-		blocks, inputs, err = gw.v.GetBlocksInRangeVerbose(start, end)
-	})
-	return blocks, inputs, err
+	return gw.v.GetBlocksInRangeVerbose(start, end)
 }
 
 // GetLastBlocks get last N blocks
 func (gw *Gateway) GetLastBlocks(num uint64) ([]coin.SignedBlock, error) {
-	var blocks []coin.SignedBlock
-	var err error
-	gw.strand("GetLastBlocks", func() {
-		blocks, err = gw.v.GetLastBlocks(num)
-	})
-	return blocks, err
+	return gw.v.GetLastBlocks(num)
 }
 
 // GetLastBlocksVerbose get last N blocks with verbose transaction input data
 func (gw *Gateway) GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error) {
-	var blocks []coin.SignedBlock
-	var inputs [][][]visor.TransactionInput
-	var err error
-	gw.strand("GetLastBlocksVerbose", func() {
-		blocks, inputs, err = gw.v.GetLastBlocksVerbose(num)
-	})
-	return blocks, inputs, err
+	return gw.v.GetLastBlocksVerbose(num)
 }
 
 // GetUnspentOutputsSummary gets unspent outputs and returns the filtered results,
 // Note: all filters will be executed as the pending sequence in 'AND' mode.
 func (gw *Gateway) GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error) {
-	var summary *visor.UnspentOutputsSummary
-	var err error
-	gw.strand("GetUnspentOutputsSummary", func() {
-		summary, err = gw.v.GetUnspentOutputsSummary(filters)
-	})
-	return summary, err
+	return gw.v.GetUnspentOutputsSummary(filters)
 }
 
 // GetTransaction returns transaction by txid
 func (gw *Gateway) GetTransaction(txid cipher.SHA256) (*visor.Transaction, error) {
-	var txn *visor.Transaction
-	var err error
-
-	gw.strand("GetTransaction", func() {
-		txn, err = gw.v.GetTransaction(txid)
-	})
-
-	return txn, err
+	return gw.v.GetTransaction(txid)
 }
 
 // GetTransactionVerbose gets verbose transaction result by txid.
 func (gw *Gateway) GetTransactionVerbose(txid cipher.SHA256) (*visor.Transaction, []visor.TransactionInput, error) {
-	var txn *visor.Transaction
-	var inputs []visor.TransactionInput
-	var err error
-	gw.strand("GetTransactionVerbose", func() {
-		txn, inputs, err = gw.v.GetTransactionWithInputs(txid)
-	})
-	return txn, inputs, err
+	return gw.v.GetTransactionWithInputs(txid)
 }
 
 // InjectBroadcastTransaction injects transaction to the unconfirmed pool and broadcasts it.
@@ -506,6 +556,10 @@ func (gw *Gateway) InjectBroadcastTransaction(txn coin.Transaction) error {
 
 			return nil
 		})
+
+		if err == nil && !gw.d.Config.DisableNetworking {
+			gw.d.detectTxnConflicts("", txn)
+		}
 	})
 	return err
 }
@@ -513,85 +567,42 @@ func (gw *Gateway) InjectBroadcastTransaction(txn coin.Transaction) error {
 // GetVerboseTransactionsForAddress returns transactions and their verbose input data for a given address.
 // These transactions include confirmed and unconfirmed transactions
 func (gw *Gateway) GetVerboseTransactionsForAddress(a cipher.Address) ([]visor.Transaction, [][]visor.TransactionInput, error) {
-	var err error
-	var txns []visor.Transaction
-	var inputs [][]visor.TransactionInput
-	gw.strand("GetVerboseTransactionsForAddress", func() {
-		txns, inputs, err = gw.v.GetVerboseTransactionsForAddress(a)
-	})
-	return txns, inputs, err
+	return gw.v.GetVerboseTransactionsForAddress(a)
 }
 
 // GetTransactions returns transactions filtered by zero or more visor.TxFilter
 func (gw *Gateway) GetTransactions(flts []visor.TxFilter) ([]visor.Transaction, error) {
-	var txns []visor.Transaction
-	var err error
-	gw.strand("GetTransactions", func() {
-		txns, err = gw.v.GetTransactions(flts)
-	})
-	return txns, err
+	return gw.v.GetTransactions(flts)
 }
 
 // GetTransactionsVerbose returns transactions filtered by zero or more visor.TxFilter
 func (gw *Gateway) GetTransactionsVerbose(flts []visor.TxFilter) ([]visor.Transaction, [][]visor.TransactionInput, error) {
-	var txns []visor.Transaction
-	var inputs [][]visor.TransactionInput
-	var err error
-	gw.strand("GetTransactionsVerbose", func() {
-		txns, inputs, err = gw.v.GetTransactionsWithInputs(flts)
-	})
-	return txns, inputs, err
+	return gw.v.GetTransactionsWithInputs(flts)
 }
 
 // GetUxOutByID gets UxOut by hash id.
 func (gw *Gateway) GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error) {
-	var uxout *historydb.UxOut
-	var err error
-	gw.strand("GetUxOutByID", func() {
-		uxout, err = gw.v.GetUxOutByID(id)
-	})
-	return uxout, err
+	return gw.v.GetUxOutByID(id)
 }
 
 // GetSpentOutputsForAddresses gets all the spent outputs of a set of addresses
 func (gw *Gateway) GetSpentOutputsForAddresses(addresses []cipher.Address) ([][]historydb.UxOut, error) {
-	var uxOuts [][]historydb.UxOut
-	var err error
-	gw.strand("GetSpentOutputsForAddresses", func() {
-		uxOuts, err = gw.v.GetSpentOutputsForAddresses(addresses)
-	})
-	return uxOuts, err
+	return gw.v.GetSpentOutputsForAddresses(addresses)
 }
 
 // GetAllUnconfirmedTransactions returns all unconfirmed transactions
 func (gw *Gateway) GetAllUnconfirmedTransactions() ([]visor.UnconfirmedTransaction, error) {
-	var txns []visor.UnconfirmedTransaction
-	var err error
-	gw.strand("GetAllUnconfirmedTransactions", func() {
-		txns, err = gw.v.GetAllUnconfirmedTransactions()
-	})
-	return txns, err
+	return gw.v.GetAllUnconfirmedTransactions()
 }
 
 // GetAllUnconfirmedTransactionsVerbose returns all unconfirmed transactions with verbose transaction inputs
 func (gw *Gateway) GetAllUnconfirmedTransactionsVerbose() ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error) {
-	var txns []visor.UnconfirmedTransaction
-	var inputs [][]visor.TransactionInput
-	var err error
-	gw.strand("GetAllUnconfirmedTransactionsVerbose", func() {
-		txns, inputs, err = gw.v.GetAllUnconfirmedTransactionsVerbose()
-	})
-	return txns, inputs, err
+	return gw.v.GetAllUnconfirmedTransactionsVerbose()
 }
 
 // GetUnconfirmedTransactions returns addresses related unconfirmed transactions
 func (gw *Gateway) GetUnconfirmedTransactions(addrs []cipher.Address) ([]visor.UnconfirmedTransaction, error) {
-	var txns []visor.UnconfirmedTransaction
-	var err error
-	gw.strand("GetUnconfirmedTransactions", func() {
-		txns, err = gw.v.GetUnconfirmedTransactions(visor.SendsToAddresses(addrs))
-	})
-	return txns, err
+	return gw.v.GetUnconfirmedTransactions(visor.SendsToAddresses(addrs))
 }
 
 // Spend spends coins from given wallet and broadcasts it,
@@ -643,14 +654,15 @@ func (gw *Gateway) CreateTransaction(params wallet.CreateTransactionParams) (*co
 	if !gw.Config.EnableWalletAPI {
 		return nil, nil, wallet.ErrWalletAPIDisabled
 	}
+	return gw.v.CreateTransaction(params)
+}
 
-	var txn *coin.Transaction
-	var inputs []wallet.UxBalance
-	var err error
-	gw.strand("CreateTransaction", func() {
-		txn, inputs, err = gw.v.CreateTransaction(params)
-	})
-	return txn, inputs, err
+// CreateHTLCSpend creates a transaction that claims or refunds the outputs locked by an HTLC
+func (gw *Gateway) CreateHTLCSpend(params wallet.HTLCSpendParams) (*coin.Transaction, []wallet.UxBalance, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, nil, wallet.ErrWalletAPIDisabled
+	}
+	return gw.v.CreateHTLCSpend(params)
 }
 
 // CreateWallet creates wallet
@@ -717,28 +729,12 @@ func (gw *Gateway) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.Ad
 	if !gw.Config.EnableWalletAPI {
 		return walletBalance, addressBalances, wallet.ErrWalletAPIDisabled
 	}
-
-	var err error
-	gw.strand("GetWalletBalance", func() {
-		walletBalance, addressBalances, err = gw.v.GetWalletBalance(wltID)
-	})
-	return walletBalance, addressBalances, err
+	return gw.v.GetWalletBalance(wltID)
 }
 
 // GetBalanceOfAddrs gets balance of given addresses
 func (gw *Gateway) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error) {
-	var balance []wallet.BalancePair
-	var err error
-
-	gw.strand("GetBalanceOfAddrs", func() {
-		balance, err = gw.v.GetBalanceOfAddrs(addrs)
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	return balance, nil
+	return gw.v.GetBalanceOfAddrs(addrs)
 }
 
 // GetWalletDir returns path for storing wallet files
@@ -781,13 +777,7 @@ func (gw *Gateway) GetWallet(wltID string) (*wallet.Wallet, error) {
 	if !gw.Config.EnableWalletAPI {
 		return nil, wallet.ErrWalletAPIDisabled
 	}
-
-	var w *wallet.Wallet
-	var err error
-	gw.strand("GetWallet", func() {
-		w, err = gw.v.Wallets.GetWallet(wltID)
-	})
-	return w, err
+	return gw.v.Wallets.GetWallet(wltID)
 }
 
 // GetWallets returns wallets
@@ -795,13 +785,7 @@ func (gw *Gateway) GetWallets() (wallet.Wallets, error) {
 	if !gw.Config.EnableWalletAPI {
 		return nil, wallet.ErrWalletAPIDisabled
 	}
-
-	var w wallet.Wallets
-	var err error
-	gw.strand("GetWallets", func() {
-		w, err = gw.v.Wallets.GetWallets()
-	})
-	return w, err
+	return gw.v.Wallets.GetWallets()
 }
 
 // GetWalletUnconfirmedTransactions returns all unconfirmed transactions in given wallet
@@ -809,13 +793,7 @@ func (gw *Gateway) GetWalletUnconfirmedTransactions(wltID string) ([]visor.Uncon
 	if !gw.Config.EnableWalletAPI {
 		return nil, wallet.ErrWalletAPIDisabled
 	}
-
-	var txns []visor.UnconfirmedTransaction
-	var err error
-	gw.strand("GetWalletUnconfirmedTransactions", func() {
-		txns, err = gw.v.GetWalletUnconfirmedTransactions(wltID)
-	})
-	return txns, err
+	return gw.v.GetWalletUnconfirmedTransactions(wltID)
 }
 
 // GetWalletUnconfirmedTransactionsVerbose returns all unconfirmed transactions in given wallet
@@ -823,14 +801,63 @@ func (gw *Gateway) GetWalletUnconfirmedTransactionsVerbose(wltID string) ([]viso
 	if !gw.Config.EnableWalletAPI {
 		return nil, nil, wallet.ErrWalletAPIDisabled
 	}
+	return gw.v.GetWalletUnconfirmedTransactionsVerbose(wltID)
+}
 
-	var txns []visor.UnconfirmedTransaction
-	var inputs [][]visor.TransactionInput
-	var err error
-	gw.strand("GetWalletUnconfirmedTransactionsVerbose", func() {
-		txns, inputs, err = gw.v.GetWalletUnconfirmedTransactionsVerbose(wltID)
-	})
-	return txns, inputs, err
+// GetWalletTxnBroadcasts returns the broadcast status of the transactions created by the given wallet
+func (gw *Gateway) GetWalletTxnBroadcasts(wltID string) ([]TxnBroadcast, error) {
+	if !gw.Config.EnableWalletAPI {
+		return nil, wallet.ErrWalletAPIDisabled
+	}
+
+	addrs, err := gw.v.Wallets.GetSkycoinAddresses(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	return gw.d.txnBroadcasts.forAddresses(addrs), nil
+}
+
+// GetTxnConflicts returns the recently seen outputs spent by conflicting transactions,
+// involving any of the transactions txids or outputs owned by any of addrs.
+// If both txids and addrs are empty, all conflicts are returned
+func (gw *Gateway) GetTxnConflicts(txids []cipher.SHA256, addrs []cipher.Address) []TxnConflict {
+	return gw.d.txnConflicts.filter(txids, addrs)
+}
+
+// GetTxnConflictEvents returns the conflict events after the event with ID since.
+// If there are none, waits up to wait for the next event
+func (gw *Gateway) GetTxnConflictEvents(since uint64, wait time.Duration) []TxnConflictEvent {
+	events, notify := gw.d.txnConflicts.eventsSince(since)
+	if len(events) > 0 || wait <= 0 {
+		return events
+	}
+
+	select {
+	case <-notify:
+		events, _ = gw.d.txnConflicts.eventsSince(since)
+	case <-time.After(wait):
+	case <-gw.quit:
+	}
+
+	return events
+}
+
+// GetBlockPublishStats returns the block publishing policy and its stats.
+// The bool return value is false if this node is not a block publisher
+func (gw *Gateway) GetBlockPublishStats() (BlockPublishPolicy, BlockPublishStats, bool) {
+	if !gw.v.Config.IsBlockPublisher {
+		return BlockPublishPolicy{}, BlockPublishStats{}, false
+	}
+
+	policy, stats := gw.d.blockPublisher.getStats()
+	return policy, stats, true
+}
+
+// GetTxnRelay returns the relay state of a transaction.
+// The bool return value is false if the transaction was not sent to or received from a peer during the retention period
+func (gw *Gateway) GetTxnRelay(txid cipher.SHA256) (TxnRelay, bool) {
+	return gw.d.txnRelays.get(txid)
 }
 
 // UnloadWallet removes wallet of given id from memory.
@@ -852,13 +879,7 @@ func (gw *Gateway) GetWalletSeed(id string, password []byte) (string, error) {
 	if !gw.Config.EnableWalletAPI {
 		return "", wallet.ErrWalletAPIDisabled
 	}
-
-	var seed string
-	var err error
-	gw.strand("GetWalletSeed", func() {
-		seed, err = gw.v.Wallets.GetWalletSeed(id, password)
-	})
-	return seed, err
+	return gw.v.Wallets.GetWalletSeed(id, password)
 }
 
 // GetRichlist returns rich list as desc order.
@@ -906,14 +927,7 @@ func (gw *Gateway) GetRichlist(includeDistribution bool) (visor.Richlist, error)
 
 // GetAddressCount returns count number of unique address with uxouts > 0.
 func (gw *Gateway) GetAddressCount() (uint64, error) {
-	var count uint64
-	var err error
-
-	gw.strand("GetAddressCount", func() {
-		count, err = gw.v.AddressCount()
-	})
-
-	return count, err
+	return gw.v.AddressCount()
 }
 
 // Health is returned by the /health endpoint
@@ -924,57 +938,77 @@ type Health struct {
 	Uptime               time.Duration
 	UnconfirmedVerifyTxn params.VerifyTxn
 	StartedAt            time.Time
+	// Bytes of the compressed messages sent and received on the open connections
+	Compression gnet.CompressionStats
+	// Number of introduced connections by user agent
+	UserAgents map[string]int
+	// Seqs that the block publisher signed conflicting blocks at
+	DoubleSignSeqs []uint64
+	// Whether block acceptance is halted because the block publisher signed conflicting blocks
+	BlockAcceptanceHalted bool
+	// Seq that block acceptance is halted at
+	BlockAcceptanceHaltedSeq uint64
 }
 
 // GetHealth returns statistics about the running node
 func (gw *Gateway) GetHealth() (*Health, error) {
-	var health *Health
-	var err error
-	gw.strand("GetHealth", func() {
-		var metadata *visor.BlockchainMetadata
-		metadata, err = gw.v.GetBlockchainMetadata()
-		if err != nil {
-			return
-		}
+	metadata, err := gw.v.GetBlockchainMetadata()
+	if err != nil {
+		return nil, err
+	}
 
-		conns, err := gw.getConnections(func(c Connection) bool {
-			return c.State != ConnectionStatePending
-		})
-		if err != nil {
-			return
-		}
+	conns, err := gw.getConnections(func(c Connection) bool {
+		return c.State != ConnectionStatePending
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		outgoingConns := 0
-		incomingConns := 0
-		for _, c := range conns {
-			if c.Outgoing {
-				outgoingConns++
-			} else {
-				incomingConns++
-			}
-		}
+	proofs, err := gw.v.GetDoubleSignProofs()
+	if err != nil {
+		return nil, err
+	}
+
+	doubleSignSeqs := make([]uint64, len(proofs))
+	for i, p := range proofs {
+		doubleSignSeqs[i] = p.Seq()
+	}
+
+	haltedSeq, halted, err := gw.v.BlockAcceptanceHalted()
+	if err != nil {
+		return nil, err
+	}
 
-		health = &Health{
-			BlockchainMetadata:   *metadata,
-			OutgoingConnections:  outgoingConns,
-			IncomingConnections:  incomingConns,
-			Uptime:               time.Since(gw.v.StartedAt),
-			UnconfirmedVerifyTxn: gw.d.Config.UnconfirmedVerifyTxn,
-			StartedAt:            gw.v.StartedAt,
+	outgoingConns := 0
+	incomingConns := 0
+	var compression gnet.CompressionStats
+	for _, c := range conns {
+		if c.Outgoing {
+			outgoingConns++
+		} else {
+			incomingConns++
 		}
-	})
+		compression.Add(c.Gnet.CompressionStats)
+	}
 
-	return health, err
+	return &Health{
+		BlockchainMetadata:   *metadata,
+		OutgoingConnections:  outgoingConns,
+		IncomingConnections:  incomingConns,
+		Uptime:               time.Since(gw.v.StartedAt),
+		UnconfirmedVerifyTxn: gw.d.Config.UnconfirmedVerifyTxn,
+		StartedAt:            gw.v.StartedAt,
+		Compression:          compression,
+		UserAgents:           CountUserAgents(conns),
+
+		DoubleSignSeqs:           doubleSignSeqs,
+		BlockAcceptanceHalted:    halted,
+		BlockAcceptanceHaltedSeq: haltedSeq,
+	}, nil
 }
 
 // VerifyTxnVerbose verifies an isolated transaction and returns []wallet.UxBalance of
 // transaction inputs, whether the transaction is confirmed and error if any
 func (gw *Gateway) VerifyTxnVerbose(txn *coin.Transaction) ([]wallet.UxBalance, bool, error) {
-	var uxs []wallet.UxBalance
-	var isTxnConfirmed bool
-	var err error
-	gw.strand("VerifyTxnVerbose", func() {
-		uxs, isTxnConfirmed, err = gw.v.VerifyTxnVerbose(txn)
-	})
-	return uxs, isTxnConfirmed, err
+	return gw.v.VerifyTxnVerbose(txn)
 }
@@ -0,0 +1,297 @@
+package daemon
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon/strand"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+const (
+	// loadTestGenesisTime is the time of the genesis block of the load test chain
+	loadTestGenesisTime uint64 = 1e9
+	// loadTestBlockTimeIncrement is the time between blocks of the load test chain
+	loadTestBlockTimeIncrement uint64 = 10
+	// loadTestFanoutOutputs is the number of outputs created by each of the fan out transactions
+	loadTestFanoutOutputs = 500
+	// loadTestFanoutTxns is the number of fan out transactions, half of their outputs are spent by blocks
+	// and the other half by injected transactions
+	loadTestFanoutTxns = 4
+	// loadTestBlocks is the number of blocks after the fan out blocks
+	loadTestBlocks = 300
+	// loadTestBlockTxns is the number of transactions in each of the loadTestBlocks blocks
+	loadTestBlockTxns = 4
+	// loadTestPopulatedBlocks is the number of blocks in the database before the benchmark starts,
+	// the rest of the blocks are executed while it runs
+	loadTestPopulatedBlocks = 100
+	// loadTestBlockWriteInterval is the interval at which the remaining blocks are executed
+	loadTestBlockWriteInterval = time.Millisecond * 10
+	// loadTestInjectInterval is the interval at which transactions are injected
+	loadTestInjectInterval = time.Millisecond * 2
+)
+
+// loadTestChain is a blockchain generated for the load test benchmarks
+type loadTestChain struct {
+	pubkey cipher.PubKey
+	// blocks starting with the genesis block
+	blocks []coin.SignedBlock
+	// txns spending outputs that are not spent by blocks, to be injected to the unconfirmed pool
+	txns coin.Transactions
+}
+
+// makeLoadTestChain generates a chain with a block publisher visor. After the genesis block,
+// fan out transactions create outputs that are spent by the transactions of the following blocks,
+// whose outputs are spent again by later blocks
+func makeLoadTestChain(b *testing.B) *loadTestChain {
+	db, shutdown := testutil.PrepareDB(b)
+	defer shutdown()
+
+	pubkey, seckey := cipher.GenerateKeyPair()
+	addr := cipher.AddressFromPubKey(pubkey)
+	signer, err := coin.NewSecKeySigner(seckey)
+	require.NoError(b, err)
+
+	c := visor.NewConfig()
+	c.IsBlockPublisher = true
+	c.BlockchainPubkey = pubkey
+	c.BlockchainSeckey = seckey
+	c.GenesisAddress = addr
+	c.GenesisCoinVolume = 100e12
+	c.GenesisTimestamp = loadTestGenesisTime
+
+	v, err := visor.NewVisor(c, db)
+	require.NoError(b, err)
+	require.NoError(b, v.Init())
+
+	var gb *coin.SignedBlock
+	err = db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.Blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(b, err)
+
+	chain := &loadTestChain{
+		pubkey: pubkey,
+		blocks: []coin.SignedBlock{*gb},
+	}
+
+	head := gb.Block
+	addBlock := func(txns coin.Transactions) coin.Block {
+		var sb coin.SignedBlock
+		err := db.View("", func(tx *dbutil.Tx) error {
+			block, err := v.Blockchain.NewBlock(tx, txns, head.Time()+loadTestBlockTimeIncrement)
+			if err != nil {
+				return err
+			}
+
+			sb = coin.SignedBlock{
+				Block: *block,
+				Sig:   cipher.MustSignHash(block.HashHeader(), seckey),
+			}
+			return nil
+		})
+		require.NoError(b, err)
+		require.NoError(b, v.ExecuteSignedBlock(sb))
+
+		chain.blocks = append(chain.blocks, sb)
+		head = sb.Block
+		return head
+	}
+
+	spend := func(ux coin.UxOut, headTime uint64) coin.Transaction {
+		txn, err := coin.NewTxnBuilder(headTime, params.UserVerifyTxn.BurnFactor).
+			AddInput(ux).
+			AddOutputAutoHours(addr, ux.Body.Coins).
+			Sign(signer)
+		require.NoError(b, err)
+		return *txn
+	}
+
+	// Fan out the genesis output
+	change := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	var outputs coin.UxArray
+	for i := 0; i < loadTestFanoutTxns; i++ {
+		builder := coin.NewTxnBuilder(head.Time(), params.UserVerifyTxn.BurnFactor).
+			AddInput(change).
+			ChangeAddress(addr)
+		for j := 0; j < loadTestFanoutOutputs; j++ {
+			// Vary the coins, outputs of a transaction must differ
+			builder.AddOutput(addr, 1e6+uint64(i*loadTestFanoutOutputs+j)*1e3, 1e6)
+		}
+
+		txn, err := builder.Sign(signer)
+		require.NoError(b, err)
+
+		block := addBlock(coin.Transactions{*txn})
+		uxOut := coin.CreateUnspents(block.Head, *txn)
+		outputs = append(outputs, uxOut[:loadTestFanoutOutputs]...)
+		change = uxOut[loadTestFanoutOutputs]
+	}
+
+	reserved := outputs[:len(outputs)/2]
+	queue := outputs[len(outputs)/2:]
+
+	for _, ux := range reserved {
+		chain.txns = append(chain.txns, spend(ux, ux.Head.Time))
+	}
+
+	// Spend the outputs in blocks, and spend the outputs of those blocks again once the queue comes back around
+	for i := 0; i < loadTestBlocks; i++ {
+		var txns coin.Transactions
+		for j := 0; j < loadTestBlockTxns; j++ {
+			txns = append(txns, spend(queue[0], head.Time()))
+			queue = queue[1:]
+		}
+
+		block := addBlock(txns)
+		for _, txn := range txns {
+			queue = append(queue, coin.CreateUnspents(block.Head, txn)...)
+		}
+	}
+
+	return chain
+}
+
+// newLoadTestGateway creates a Gateway backed by a visor database populated with the first blocks of chain.
+// A goroutine simulating the daemon run loop processes stranded requests, while writers execute the
+// rest of the blocks and inject transactions through the run loop at a steady rate, until they run out.
+// The returned function stops the run loop and writers and cleans up the database.
+func newLoadTestGateway(b *testing.B, chain *loadTestChain) (*Gateway, func()) {
+	db, shutdown := testutil.PrepareDB(b)
+
+	c := visor.NewConfig()
+	c.BlockchainPubkey = chain.pubkey
+
+	v, err := visor.NewVisor(c, db)
+	require.NoError(b, err)
+
+	for _, sb := range chain.blocks[:loadTestPopulatedBlocks] {
+		require.NoError(b, v.ExecuteSignedBlock(sb))
+	}
+
+	gw := &Gateway{
+		d:        &Daemon{},
+		v:        v,
+		requests: make(chan strand.Request, 32),
+		quit:     make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-gw.quit:
+				return
+			case req := <-gw.requests:
+				req.Func() // nolint: errcheck
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(loadTestBlockWriteInterval)
+		defer ticker.Stop()
+
+		for _, sb := range chain.blocks[loadTestPopulatedBlocks:] {
+			select {
+			case <-gw.quit:
+				return
+			case <-ticker.C:
+			}
+
+			gw.strand("ExecuteSignedBlock", func() {
+				if err := gw.v.ExecuteSignedBlock(sb); err != nil {
+					b.Error(err)
+				}
+			})
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(loadTestInjectInterval)
+		defer ticker.Stop()
+
+		for _, txn := range chain.txns {
+			select {
+			case <-gw.quit:
+				return
+			case <-ticker.C:
+			}
+
+			gw.strand("InjectTransaction", func() {
+				if _, _, err := gw.v.InjectForeignTransaction(txn); err != nil {
+					b.Error(err)
+				}
+			})
+		}
+	}()
+
+	return gw, func() {
+		close(gw.quit)
+		wg.Wait()
+		shutdown()
+	}
+}
+
+// BenchmarkGatewayReadBusyDaemon measures the latency of a read query of populated blocks, while
+// blocks are executed and transactions are injected through the daemon run loop.
+// "stranded" reproduces the old behavior of serializing reads through the run loop,
+// "concurrent" is the current behavior.
+func BenchmarkGatewayReadBusyDaemon(b *testing.B) {
+	chain := makeLoadTestChain(b)
+
+	read := func(gw *Gateway) error {
+		blocks, err := gw.GetBlocksInRange(loadTestPopulatedBlocks-10, loadTestPopulatedBlocks-1)
+		if err != nil {
+			return err
+		}
+		if len(blocks) != 10 {
+			return fmt.Errorf("got %d blocks", len(blocks))
+		}
+		return nil
+	}
+
+	b.Run("stranded", func(b *testing.B) {
+		gw, shutdown := newLoadTestGateway(b, chain)
+		defer shutdown()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var err error
+			gw.strand("GetBlocksInRange", func() {
+				err = read(gw)
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("concurrent", func(b *testing.B) {
+		gw, shutdown := newLoadTestGateway(b, chain)
+		defer shutdown()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := read(gw); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -349,3 +349,16 @@ func TestGateway_CreateTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestGateway_ConnectPeer(t *testing.T) {
+	gw := &Gateway{
+		d: &Daemon{
+			Config: DaemonConfig{
+				DisableNetworking: true,
+			},
+		},
+	}
+
+	err := gw.ConnectPeer("11.22.33.44:6000")
+	require.Equal(t, ErrNetworkingDisabled, err)
+}
@@ -0,0 +1,141 @@
+package gnet
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"net"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// CompressedPrefix is the message ID of a compressed message.
+// A compressed message contains the length of the uncompressed message, followed by the
+// DEFLATE compressed message ID and message data.
+// Compressed messages are only sent to connections that enabled compression with EnableCompression
+var CompressedPrefix = MessagePrefix{'C', 'M', 'P', 'R'}
+
+// CompressionStats counts the bytes of the compressed messages sent and received on a connection.
+// Sizes include the length prefix and message ID. Messages that were not compressed are not counted
+type CompressionStats struct {
+	// Size of the compressed messages sent, before compression
+	SentBytes uint64
+	// Size of the compressed messages sent, after compression
+	SentCompressedBytes uint64
+	// Size of the compressed messages received, after decompression
+	ReceivedBytes uint64
+	// Size of the compressed messages received, before decompression
+	ReceivedCompressedBytes uint64
+}
+
+// SentRatio returns the compressed size of the compressed messages sent, relative to their uncompressed size.
+// Returns 0 if no compressed messages were sent
+func (s CompressionStats) SentRatio() float64 {
+	if s.SentBytes == 0 {
+		return 0
+	}
+	return float64(s.SentCompressedBytes) / float64(s.SentBytes)
+}
+
+// ReceivedRatio returns the compressed size of the compressed messages received, relative to their uncompressed size.
+// Returns 0 if no compressed messages were received
+func (s CompressionStats) ReceivedRatio() float64 {
+	if s.ReceivedBytes == 0 {
+		return 0
+	}
+	return float64(s.ReceivedCompressedBytes) / float64(s.ReceivedBytes)
+}
+
+// Add adds the counters of another CompressionStats
+func (s *CompressionStats) Add(o CompressionStats) {
+	s.SentBytes += o.SentBytes
+	s.SentCompressedBytes += o.SentCompressedBytes
+	s.ReceivedBytes += o.ReceivedBytes
+	s.ReceivedCompressedBytes += o.ReceivedCompressedBytes
+}
+
+// Serializes a Message over a net.Conn, compressing it if it is larger than threshold bytes
+// and compression makes it smaller.
+// Returns the CompressionStats of the message, which are empty if it was not compressed
+func sendCompressedMessage(conn net.Conn, msg Message, timeout time.Duration, threshold int) (CompressionStats, error) {
+	m := EncodeMessage(msg)
+
+	var stats CompressionStats
+	if len(m) > threshold {
+		if cm, ok := compressMessage(m[messageLengthSize:]); ok {
+			stats.SentBytes = uint64(len(m))
+			stats.SentCompressedBytes = uint64(len(cm))
+			m = cm
+		}
+	}
+
+	return stats, sendByteMessage(conn, m, timeout)
+}
+
+// compressMessage packs the message ID and data of an encoded message into a compressed message,
+// including the length prefix. Returns false if the compressed message would not be smaller
+func compressMessage(data []byte) ([]byte, bool) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, messageLengthSize)) // length prefix, written below
+	buf.Write(CompressedPrefix[:])
+	buf.Write(encoder.SerializeAtomic(uint32(len(data))))
+
+	w, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		// flate.NewWriter only fails for an invalid compression level
+		logger.Panicf("flate.NewWriter failed: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		logger.Panicf("flate.Writer.Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		logger.Panicf("flate.Writer.Close failed: %v", err)
+	}
+
+	if buf.Len() >= messageLengthSize+len(data) {
+		return nil, false
+	}
+
+	m := buf.Bytes()
+	copy(m[:messageLengthSize], encoder.SerializeAtomic(uint32(len(m)-messageLengthSize)))
+	return m, true
+}
+
+// isCompressedMessage returns true if the message ID and data read from a connection are a compressed message
+func isCompressedMessage(msg []byte) bool {
+	return len(msg) >= len(CompressedPrefix) && bytes.Equal(msg[:len(CompressedPrefix)], CompressedPrefix[:])
+}
+
+// decompressMessage decompresses the data of a compressed message, following its message ID,
+// to the message ID and data of the original message.
+// The uncompressed length is checked against maxMsgLength before decompressing,
+// and decompression stops after that many bytes, so that a small message can't decompress to an unbounded size
+func decompressMessage(data []byte, maxMsgLength int) ([]byte, error) {
+	var length uint32
+	if len(data) < 4 {
+		return nil, ErrDisconnectMalformedMessage
+	}
+	if _, err := encoder.DeserializeAtomic(data[:4], &length); err != nil {
+		return nil, ErrDisconnectMalformedMessage
+	}
+
+	if int(length) < messagePrefixLength || int(length) > maxMsgLength {
+		return nil, ErrDisconnectInvalidMessageLength
+	}
+
+	r := flate.NewReader(bytes.NewReader(data[4:]))
+	defer r.Close() // nolint: errcheck
+
+	m := make([]byte, length)
+	if _, err := io.ReadFull(r, m); err != nil {
+		return nil, ErrDisconnectMalformedMessage
+	}
+
+	// The compressed data must not contain more than the declared length
+	if n, _ := r.Read(make([]byte, 1)); n != 0 {
+		return nil, ErrDisconnectMalformedMessage
+	}
+
+	return m, nil
+}
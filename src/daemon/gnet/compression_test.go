@@ -0,0 +1,216 @@
+package gnet
+
+import (
+	"bytes"
+	"compress/flate"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// compressedMessageData creates the data of a compressed message following its message ID,
+// with a declared uncompressed length that may not match the compressed data
+func compressedMessageData(t *testing.T, length uint32, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(encoder.SerializeAtomic(length))
+	w, err := flate.NewWriter(&buf, flate.BestSpeed)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCompressMessage(t *testing.T) {
+	data := append(BytePrefix[:], bytes.Repeat([]byte{7}, 4096)...)
+
+	m, ok := compressMessage(data)
+	require.True(t, ok)
+	require.True(t, len(m) < len(data))
+
+	// The length prefix is the length of the compressed message
+	var length uint32
+	_, err := encoder.DeserializeAtomic(m[:messageLengthSize], &length)
+	require.NoError(t, err)
+	require.Equal(t, len(m)-messageLengthSize, int(length))
+
+	msg := m[messageLengthSize:]
+	require.True(t, isCompressedMessage(msg))
+
+	d, err := decompressMessage(msg[len(CompressedPrefix):], len(data))
+	require.NoError(t, err)
+	require.Equal(t, data, d)
+
+	// The uncompressed length is checked against the max message length before decompressing
+	_, err = decompressMessage(msg[len(CompressedPrefix):], len(data)-1)
+	require.Equal(t, ErrDisconnectInvalidMessageLength, err)
+
+	// Data that does not compress is not compressed
+	_, ok = compressMessage(BytePrefix[:])
+	require.False(t, ok)
+}
+
+func TestDecompressMessageInvalid(t *testing.T) {
+	data := bytes.Repeat([]byte{7}, 4096)
+
+	cases := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{
+			name: "valid",
+			data: compressedMessageData(t, 4096, data),
+		},
+		{
+			name: "no length",
+			data: []byte{1, 2},
+			err:  ErrDisconnectMalformedMessage,
+		},
+		{
+			name: "length too short",
+			data: compressedMessageData(t, messagePrefixLength-1, data[:messagePrefixLength-1]),
+			err:  ErrDisconnectInvalidMessageLength,
+		},
+		{
+			name: "length exceeds max message length",
+			data: compressedMessageData(t, 1024*1024, bytes.Repeat([]byte{7}, 1024*1024)),
+			err:  ErrDisconnectInvalidMessageLength,
+		},
+		{
+			name: "decompresses to more than the length",
+			data: compressedMessageData(t, 1024, bytes.Repeat([]byte{7}, 1024*1024)),
+			err:  ErrDisconnectMalformedMessage,
+		},
+		{
+			name: "decompresses to less than the length",
+			data: compressedMessageData(t, 8192, data),
+			err:  ErrDisconnectMalformedMessage,
+		},
+		{
+			name: "invalid compressed data",
+			data: append(encoder.SerializeAtomic(uint32(4096)), []byte("not compressed")...),
+			err:  ErrDisconnectMalformedMessage,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := decompressMessage(tc.data, 256*1024)
+			require.Equal(t, tc.err, err)
+			if tc.err == nil {
+				require.Equal(t, data, d)
+			}
+		})
+	}
+}
+
+func TestCompressionStats(t *testing.T) {
+	var s CompressionStats
+	require.Equal(t, 0.0, s.SentRatio())
+	require.Equal(t, 0.0, s.ReceivedRatio())
+
+	s.Add(CompressionStats{
+		SentBytes:               1000,
+		SentCompressedBytes:     200,
+		ReceivedBytes:           400,
+		ReceivedCompressedBytes: 300,
+	})
+	s.Add(CompressionStats{
+		SentBytes:           1000,
+		SentCompressedBytes: 400,
+	})
+
+	require.Equal(t, 0.3, s.SentRatio())
+	require.Equal(t, 0.75, s.ReceivedRatio())
+}
+
+func TestSendCompressedMessage(t *testing.T) {
+	resetHandler()
+	EraseMessages()
+	RegisterMessage(BytePrefix, ByteMessage{})
+	VerifyMessages()
+	defer resetHandler()
+
+	var sent []byte
+	sendByteMessage = func(conn net.Conn, msg []byte, tm time.Duration) error {
+		sent = msg
+		return nil
+	}
+
+	// Messages below the threshold are not compressed
+	m := NewByteMessage(7)
+	stats, err := sendCompressedMessage(nil, m, 0, 1024)
+	require.NoError(t, err)
+	require.Equal(t, CompressionStats{}, stats)
+	require.Equal(t, EncodeMessage(m), sent)
+
+	// Messages that don't get smaller are not compressed
+	stats, err = sendCompressedMessage(nil, m, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, CompressionStats{}, stats)
+	require.Equal(t, EncodeMessage(m), sent)
+}
+
+func TestPoolReceiveCompressedMessage(t *testing.T) {
+	wait()
+	resetHandler()
+	EraseMessages()
+	RegisterMessage(BytePrefix, ByteMessage{})
+	VerifyMessages()
+
+	cfg := newTestConfig()
+	p, err := NewConnectionPool(cfg, nil)
+	require.NoError(t, err)
+
+	q := make(chan struct{})
+	go func() {
+		defer close(q)
+		err := p.Run()
+		require.NoError(t, err)
+	}()
+	wait()
+
+	c := NewConnection(p, 1, NewDummyConn(addr), 10, true)
+	err = p.strand("addConnection", func() error {
+		p.pool[c.ID] = c
+		p.addresses[c.Addr()] = c
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.False(t, c.CompressionEnabled())
+	require.NoError(t, p.EnableCompression(addr))
+	require.True(t, c.CompressionEnabled())
+	require.Error(t, p.EnableCompression("127.0.0.1:9999"))
+
+	// A compressed message is decompressed and its stats are recorded
+	b := append(CompressedPrefix[:], compressedMessageData(t, 5, append(BytePrefix[:], 7))...)
+	err = p.receiveMessage(c, b)
+	require.NoError(t, err)
+
+	gc, err := p.GetConnection(addr)
+	require.NoError(t, err)
+	require.Equal(t, CompressionStats{
+		ReceivedBytes:           uint64(messageLengthSize + 5),
+		ReceivedCompressedBytes: uint64(messageLengthSize + len(b)),
+	}, gc.CompressionStats)
+
+	// A compressed message that decompresses to a compressed message is not accepted
+	inner := append(CompressedPrefix[:], compressedMessageData(t, 5, append(BytePrefix[:], 7))...)
+	b = append(CompressedPrefix[:], compressedMessageData(t, uint32(len(inner)), inner)...)
+	err = p.receiveMessage(c, b)
+	require.Equal(t, ErrDisconnectUnknownMessage, err)
+
+	// The uncompressed length is checked against MaxMessageLength
+	b = append(CompressedPrefix[:], compressedMessageData(t, uint32(cfg.MaxMessageLength+1), bytes.Repeat([]byte{7}, cfg.MaxMessageLength+1))...)
+	err = p.receiveMessage(c, b)
+	require.Equal(t, ErrDisconnectInvalidMessageLength, err)
+
+	p.Shutdown()
+	<-q
+}
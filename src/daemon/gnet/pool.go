@@ -11,6 +11,7 @@ import (
 	"net"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"io"
@@ -104,8 +105,11 @@ type Config struct {
 	MaxOutgoingConnections int
 	// Maximum allowed default outgoing connection number
 	MaxDefaultPeerOutgoingConnections int
-	// Messages greater than length are rejected and the sender disconnected
+	// Messages greater than length are rejected and the sender disconnected.
+	// The uncompressed length of compressed messages is checked too
 	MaxMessageLength int
+	// Messages larger than this many bytes are compressed, for connections that enabled compression
+	CompressionThreshold int
 	// Timeout is the timeout for dialing new connections.  Use a
 	// timeout of 0 to ignore timeout.
 	DialTimeout time.Duration
@@ -141,6 +145,7 @@ func NewConfig() Config {
 		Port:                              0,
 		MaxConnections:                    128,
 		MaxMessageLength:                  256 * 1024,
+		CompressionThreshold:              1024,
 		MaxDefaultPeerOutgoingConnections: 1,
 		DialTimeout:                       time.Second * 30,
 		ReadTimeout:                       time.Second * 30,
@@ -176,6 +181,10 @@ type Connection struct {
 	// Message send queue.
 	WriteQueue chan Message
 	Solicited  bool
+	// Bytes of the compressed messages sent and received
+	CompressionStats CompressionStats
+	// Set to 1 if messages sent to the connection are compressed. Accessed atomically
+	compression int32
 }
 
 // NewConnection creates a new Connection tied to a ConnectionPool
@@ -202,6 +211,11 @@ func (conn *Connection) String() string {
 	return conn.Addr()
 }
 
+// CompressionEnabled returns true if messages sent to the connection are compressed
+func (conn *Connection) CompressionEnabled() bool {
+	return atomic.LoadInt32(&conn.compression) == 1
+}
+
 // Close close the connection and write queue
 func (conn *Connection) Close() error {
 	err := conn.Conn.Close()
@@ -662,7 +676,13 @@ func (pool *ConnectionPool) sendLoop(conn *Connection, timeout time.Duration, qc
 				continue
 			}
 
-			err := sendMessage(conn.Conn, m, timeout)
+			var err error
+			var stats CompressionStats
+			if conn.CompressionEnabled() {
+				stats, err = sendCompressedMessage(conn.Conn, m, timeout, pool.Config.CompressionThreshold)
+			} else {
+				err = sendMessage(conn.Conn, m, timeout)
+			}
 
 			// Update last sent before writing to SendResult,
 			// this allows a write to SendResult to be used as a sync marker,
@@ -671,6 +691,11 @@ func (pool *ConnectionPool) sendLoop(conn *Connection, timeout time.Duration, qc
 				if err := pool.updateLastSent(conn.Addr(), Now()); err != nil {
 					logger.WithField("addr", conn.Addr()).WithError(err).Warning("updateLastSent failed")
 				}
+				if stats.SentBytes != 0 {
+					if err := pool.recordCompression(conn.Addr(), stats); err != nil {
+						logger.WithField("addr", conn.Addr()).WithError(err).Warning("recordCompression failed")
+					}
+				}
 			}
 
 			sr := newSendResult(conn.Addr(), m, err)
@@ -770,6 +795,15 @@ func (pool *ConnectionPool) updateLastSent(addr string, t time.Time) error {
 	})
 }
 
+func (pool *ConnectionPool) recordCompression(addr string, stats CompressionStats) error {
+	return pool.strand("recordCompression", func() error {
+		if conn, ok := pool.addresses[addr]; ok {
+			conn.CompressionStats.Add(stats)
+		}
+		return nil
+	})
+}
+
 func (pool *ConnectionPool) updateLastRecv(addr string, t time.Time) error {
 	return pool.strand("updateLastRecv", func() error {
 		if conn, ok := pool.addresses[addr]; ok {
@@ -913,6 +947,20 @@ func (pool *ConnectionPool) Size() (l int, err error) {
 	return
 }
 
+// EnableCompression compresses the messages larger than Config.CompressionThreshold that are sent to a connection.
+// It must only be enabled for connections that reported that they can decompress messages
+func (pool *ConnectionPool) EnableCompression(addr string) error {
+	return pool.strand("EnableCompression", func() error {
+		conn, ok := pool.addresses[addr]
+		if !ok {
+			return fmt.Errorf("Tried to enable compression for %s, but we are not connected", addr)
+		}
+
+		atomic.StoreInt32(&conn.compression, 1)
+		return nil
+	})
+}
+
 // SendMessage sends a Message to a Connection
 func (pool *ConnectionPool) SendMessage(addr string, msg Message) error {
 	if pool.Config.DebugPrint {
@@ -995,6 +1043,23 @@ func (pool *ConnectionPool) BroadcastMessage(msg Message, addrs []string) ([]uin
 // first return value.  Otherwise, error will be nil and DisconnectReason will
 // be the value returned from the message handler.
 func (pool *ConnectionPool) receiveMessage(c *Connection, msg []byte) error {
+	if isCompressedMessage(msg) {
+		data, err := decompressMessage(msg[len(CompressedPrefix):], pool.Config.MaxMessageLength)
+		if err != nil {
+			logger.WithError(err).WithField("connID", c.ID).Warning("decompressMessage failed")
+			return err
+		}
+
+		if err := pool.recordCompression(c.Addr(), CompressionStats{
+			ReceivedBytes:           uint64(messageLengthSize + len(data)),
+			ReceivedCompressedBytes: uint64(messageLengthSize + len(msg)),
+		}); err != nil {
+			return err
+		}
+
+		msg = data
+	}
+
 	m, err := convertToMessage(c.ID, msg, pool.Config.DebugPrint)
 	if err != nil {
 		return err
@@ -0,0 +1,333 @@
+/*
+Package lan discovers peers on the local network with UDP multicast.
+
+Each node periodically sends an announcement to a multicast group, with the blockchain pubkey
+of its chain, the port it accepts connections on and its node pubkey.
+Announcements from other nodes of the same chain are passed to a Handler, with the address of the
+announcing node, which is the source IP of the announcement and the announced port.
+
+This is intended for local test networks and air-gapped deployments, where peers cannot be found with
+peer exchange or a downloaded peers list.
+*/
+package lan
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/util/logging"
+)
+
+const (
+	// DefaultAddress is the default multicast group and port that announcements are sent to
+	DefaultAddress = "239.255.13.37:6679"
+
+	// announcementVersion is the version of the announcement format
+	announcementVersion = 1
+	// maxPacketSize is the size of the buffer that announcements are read into.
+	// Larger packets are truncated, and fail to deserialize
+	maxPacketSize = 512
+)
+
+var (
+	// ErrInvalidAnnouncement is returned when an announcement cannot be deserialized
+	ErrInvalidAnnouncement = errors.New("Invalid announcement")
+	// ErrUnknownAnnouncementVersion is returned when an announcement has an unknown version
+	ErrUnknownAnnouncementVersion = errors.New("Unknown announcement version")
+
+	// announcementMagic is the first bytes of an announcement
+	announcementMagic = [4]byte{'S', 'K', 'Y', 'L'}
+	// announcementSize is the size of a serialized announcement
+	announcementSize = len(encoder.Serialize(announcement{}))
+
+	logger = logging.MustGetLogger("lan")
+)
+
+// Config configures LAN discovery
+type Config struct {
+	// Enable LAN discovery
+	Enabled bool
+	// Multicast group and port, ip:port
+	Address string
+	// Name of the network interface to send and receive announcements on.
+	// If empty, the system default multicast interface is used
+	Interface string
+	// Don't announce this node, only listen for announcements
+	DisableAnnounce bool
+	// How often to announce this node
+	AnnounceRate time.Duration
+	// Announcements from the same address are passed to the handler at most once per PeerRateLimit
+	PeerRateLimit time.Duration
+	// Maximum number of announcements to accept per second, from all addresses.
+	// Announcements beyond the limit are dropped
+	MaxAnnouncementsPerSecond int
+	// Blockchain pubkey that identifies the chain. Announcements for other chains are ignored
+	ChainID cipher.PubKey
+	// Port that this node accepts connections on
+	ListenPort uint16
+	// Node pubkey of this node. Announcements with this node pubkey are ignored
+	NodePubKey cipher.PubKey
+}
+
+// NewConfig creates a Config with defaults set
+func NewConfig() Config {
+	return Config{
+		Enabled:                   false,
+		Address:                   DefaultAddress,
+		AnnounceRate:              time.Second * 30,
+		PeerRateLimit:             time.Minute,
+		MaxAnnouncementsPerSecond: 10,
+	}
+}
+
+// Handler is called with the address and node pubkey of a node that announced itself
+type Handler func(addr string, nodePubKey cipher.PubKey)
+
+// Discovery announces this node and listens for announcements from other nodes
+type Discovery struct {
+	Config  Config
+	handler Handler
+	group   *net.UDPAddr
+	ifi     *net.Interface
+	limiter *rateLimiter
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+// New creates a Discovery
+func New(cfg Config, handler Handler) (*Discovery, error) {
+	if handler == nil {
+		return nil, errors.New("handler is required")
+	}
+	if cfg.AnnounceRate <= 0 {
+		return nil, errors.New("AnnounceRate must be greater than 0")
+	}
+	if cfg.MaxAnnouncementsPerSecond <= 0 {
+		return nil, errors.New("MaxAnnouncementsPerSecond must be greater than 0")
+	}
+	if cfg.ListenPort == 0 && !cfg.DisableAnnounce {
+		return nil, errors.New("ListenPort is required")
+	}
+
+	group, err := net.ResolveUDPAddr("udp4", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid LAN discovery address %q: %v", cfg.Address, err)
+	}
+	if !group.IP.IsMulticast() {
+		return nil, fmt.Errorf("Invalid LAN discovery address %q: not a multicast address", cfg.Address)
+	}
+
+	var ifi *net.Interface
+	if cfg.Interface != "" {
+		ifi, err = net.InterfaceByName(cfg.Interface)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid LAN discovery interface %q: %v", cfg.Interface, err)
+		}
+	}
+
+	return &Discovery{
+		Config:  cfg,
+		handler: handler,
+		group:   group,
+		ifi:     ifi,
+		limiter: newRateLimiter(cfg.PeerRateLimit, cfg.MaxAnnouncementsPerSecond),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Run joins the multicast group, then announces this node every AnnounceRate
+// and passes the announcements of other nodes to the handler, until Shutdown is called
+func (d *Discovery) Run() error {
+	defer close(d.done)
+
+	conn, err := net.ListenMulticastUDP("udp4", d.ifi, d.group)
+	if err != nil {
+		logger.WithError(err).WithField("addr", d.group.String()).Error("Failed to join the LAN discovery multicast group")
+		return err
+	}
+
+	logger.WithField("addr", d.group.String()).Info("LAN discovery started")
+	defer logger.Info("LAN discovery stopped")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.receive(conn)
+	}()
+
+	var announceC <-chan time.Time
+	if !d.Config.DisableAnnounce {
+		d.announce(conn)
+		announceTicker := time.NewTicker(d.Config.AnnounceRate)
+		defer announceTicker.Stop()
+		announceC = announceTicker.C
+	}
+
+	for {
+		select {
+		case <-announceC:
+			d.announce(conn)
+		case <-d.quit:
+			// Closing the connection stops the receive loop
+			if err := conn.Close(); err != nil {
+				logger.WithError(err).Error("LAN discovery conn.Close failed")
+			}
+			wg.Wait()
+			return nil
+		}
+	}
+}
+
+// Shutdown stops Run
+func (d *Discovery) Shutdown() {
+	close(d.quit)
+	<-d.done
+}
+
+func (d *Discovery) announce(conn *net.UDPConn) {
+	a := newAnnouncement(d.Config.ChainID, d.Config.ListenPort, d.Config.NodePubKey)
+	if _, err := conn.WriteToUDP(a.Serialize(), d.group); err != nil {
+		logger.WithError(err).Warning("Failed to send LAN announcement")
+	}
+}
+
+func (d *Discovery) receive(conn *net.UDPConn) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-d.quit:
+			default:
+				logger.WithError(err).Error("LAN discovery read failed")
+			}
+			return
+		}
+
+		d.handlePacket(buf[:n], src, time.Now())
+	}
+}
+
+func (d *Discovery) handlePacket(data []byte, src *net.UDPAddr, now time.Time) {
+	a, err := deserializeAnnouncement(data)
+	if err != nil {
+		logger.WithError(err).WithField("src", src.String()).Debug("Ignoring invalid LAN announcement")
+		return
+	}
+
+	if a.ChainID != d.Config.ChainID || a.NodePubKey == d.Config.NodePubKey || a.Port == 0 {
+		return
+	}
+
+	addr := d.peerAddr(src.IP, a.Port)
+
+	if !d.limiter.allow(addr, now) {
+		return
+	}
+
+	logger.WithField("addr", addr).Debug("Received LAN announcement")
+	d.handler(addr, a.NodePubKey)
+}
+
+// peerAddr returns the address to connect to an announcing node.
+// Announcements received on the loopback interface were sent by this host,
+// so the node is reached on localhost, whatever the source address of the announcement
+func (d *Discovery) peerAddr(ip net.IP, port uint16) string {
+	if d.ifi != nil && d.ifi.Flags&net.FlagLoopback != 0 {
+		ip = net.IPv4(127, 0, 0, 1)
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+}
+
+// announcement is sent to the multicast group by each node
+type announcement struct {
+	Magic      [4]byte
+	Version    uint8
+	ChainID    cipher.PubKey
+	Port       uint16
+	NodePubKey cipher.PubKey
+}
+
+func newAnnouncement(chainID cipher.PubKey, port uint16, nodePubKey cipher.PubKey) announcement {
+	return announcement{
+		Magic:      announcementMagic,
+		Version:    announcementVersion,
+		ChainID:    chainID,
+		Port:       port,
+		NodePubKey: nodePubKey,
+	}
+}
+
+// Serialize serializes the announcement
+func (a announcement) Serialize() []byte {
+	return encoder.Serialize(a)
+}
+
+func deserializeAnnouncement(data []byte) (announcement, error) {
+	if len(data) != announcementSize {
+		return announcement{}, ErrInvalidAnnouncement
+	}
+
+	var a announcement
+	if err := encoder.DeserializeRaw(data, &a); err != nil || a.Magic != announcementMagic {
+		return announcement{}, ErrInvalidAnnouncement
+	}
+
+	if a.Version != announcementVersion {
+		return announcement{}, ErrUnknownAnnouncementVersion
+	}
+
+	return a, nil
+}
+
+// rateLimiter limits how often announcements are accepted, per address and in total
+type rateLimiter struct {
+	peerRate  time.Duration
+	maxPerSec int
+	seen      map[string]time.Time
+	window    time.Time
+	count     int
+}
+
+func newRateLimiter(peerRate time.Duration, maxPerSec int) *rateLimiter {
+	return &rateLimiter{
+		peerRate:  peerRate,
+		maxPerSec: maxPerSec,
+		seen:      make(map[string]time.Time),
+	}
+}
+
+// allow returns true if an announcement from addr received at now is accepted
+func (r *rateLimiter) allow(addr string, now time.Time) bool {
+	if now.Sub(r.window) >= time.Second {
+		r.window = now
+		r.count = 0
+
+		// Forget the addresses that are no longer limited, so that the map does not grow without bound
+		for a, t := range r.seen {
+			if now.Sub(t) >= r.peerRate {
+				delete(r.seen, a)
+			}
+		}
+	}
+
+	if r.count >= r.maxPerSec {
+		return false
+	}
+
+	if t, ok := r.seen[addr]; ok && now.Sub(t) < r.peerRate {
+		return false
+	}
+
+	r.count++
+	r.seen[addr] = now
+	return true
+}
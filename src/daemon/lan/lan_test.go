@@ -0,0 +1,272 @@
+package lan
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+// loopbackInterface returns the name of the loopback interface
+func loopbackInterface(t *testing.T) string {
+	ifis, err := net.Interfaces()
+	require.NoError(t, err)
+	for _, ifi := range ifis {
+		if ifi.Flags&net.FlagLoopback != 0 && ifi.Flags&net.FlagUp != 0 {
+			return ifi.Name
+		}
+	}
+	t.Skip("No loopback interface")
+	return ""
+}
+
+func newTestConfig(chainID cipher.PubKey, port uint16) Config {
+	cfg := NewConfig()
+	cfg.Enabled = true
+	cfg.ChainID = chainID
+	cfg.ListenPort = port
+	cfg.NodePubKey = testutil.MakePubKey()
+	return cfg
+}
+
+func TestNew(t *testing.T) {
+	handler := func(string, cipher.PubKey) {}
+	chainID := testutil.MakePubKey()
+
+	cases := []struct {
+		name    string
+		cfg     func(Config) Config
+		handler Handler
+		err     string
+	}{
+		{
+			name: "ok",
+			cfg: func(c Config) Config {
+				return c
+			},
+			handler: handler,
+		},
+		{
+			name: "no handler",
+			cfg: func(c Config) Config {
+				return c
+			},
+			err: "handler is required",
+		},
+		{
+			name: "no announce rate",
+			cfg: func(c Config) Config {
+				c.AnnounceRate = 0
+				return c
+			},
+			handler: handler,
+			err:     "AnnounceRate must be greater than 0",
+		},
+		{
+			name: "no max announcements",
+			cfg: func(c Config) Config {
+				c.MaxAnnouncementsPerSecond = 0
+				return c
+			},
+			handler: handler,
+			err:     "MaxAnnouncementsPerSecond must be greater than 0",
+		},
+		{
+			name: "no listen port",
+			cfg: func(c Config) Config {
+				c.ListenPort = 0
+				return c
+			},
+			handler: handler,
+			err:     "ListenPort is required",
+		},
+		{
+			name: "no listen port, announce disabled",
+			cfg: func(c Config) Config {
+				c.ListenPort = 0
+				c.DisableAnnounce = true
+				return c
+			},
+			handler: handler,
+		},
+		{
+			name: "not multicast",
+			cfg: func(c Config) Config {
+				c.Address = "127.0.0.1:6679"
+				return c
+			},
+			handler: handler,
+			err:     `Invalid LAN discovery address "127.0.0.1:6679": not a multicast address`,
+		},
+		{
+			name: "unknown interface",
+			cfg: func(c Config) Config {
+				c.Interface = "nonexistent0"
+				return c
+			},
+			handler: handler,
+			// The rest of the error is platform specific
+			err: `Invalid LAN discovery interface "nonexistent0": `,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.cfg(newTestConfig(chainID, 6000)), tc.handler)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.True(t, strings.HasPrefix(err.Error(), tc.err), err.Error())
+			}
+		})
+	}
+}
+
+func TestAnnouncement(t *testing.T) {
+	chainID := testutil.MakePubKey()
+	nodePubKey := testutil.MakePubKey()
+
+	a := newAnnouncement(chainID, 6000, nodePubKey)
+	b := a.Serialize()
+	require.Len(t, b, announcementSize)
+	require.Equal(t, 73, announcementSize)
+
+	a2, err := deserializeAnnouncement(b)
+	require.NoError(t, err)
+	require.Equal(t, a, a2)
+
+	_, err = deserializeAnnouncement(b[:len(b)-1])
+	require.Equal(t, ErrInvalidAnnouncement, err)
+
+	_, err = deserializeAnnouncement(append(b, 0))
+	require.Equal(t, ErrInvalidAnnouncement, err)
+
+	badMagic := append([]byte{}, b...)
+	badMagic[0] = 'X'
+	_, err = deserializeAnnouncement(badMagic)
+	require.Equal(t, ErrInvalidAnnouncement, err)
+
+	badVersion := append([]byte{}, b...)
+	badVersion[4] = announcementVersion + 1
+	_, err = deserializeAnnouncement(badVersion)
+	require.Equal(t, ErrUnknownAnnouncementVersion, err)
+}
+
+func TestHandlePacket(t *testing.T) {
+	chainID := testutil.MakePubKey()
+	otherNode := testutil.MakePubKey()
+
+	var received []string
+	d, err := New(newTestConfig(chainID, 6000), func(addr string, nodePubKey cipher.PubKey) {
+		require.Equal(t, otherNode, nodePubKey)
+		received = append(received, addr)
+	})
+	require.NoError(t, err)
+
+	src := &net.UDPAddr{
+		IP:   net.ParseIP("192.168.1.5"),
+		Port: 6679,
+	}
+	now := time.Now()
+
+	// Invalid data, other chains, this node's own announcements and the zero port are ignored
+	d.handlePacket([]byte("foo"), src, now)
+	d.handlePacket(newAnnouncement(testutil.MakePubKey(), 6001, otherNode).Serialize(), src, now)
+	d.handlePacket(newAnnouncement(chainID, 6000, d.Config.NodePubKey).Serialize(), src, now)
+	d.handlePacket(newAnnouncement(chainID, 0, otherNode).Serialize(), src, now)
+	require.Empty(t, received)
+
+	d.handlePacket(newAnnouncement(chainID, 6001, otherNode).Serialize(), src, now)
+	require.Equal(t, []string{"192.168.1.5:6001"}, received)
+
+	// The same address is rate limited
+	d.handlePacket(newAnnouncement(chainID, 6001, otherNode).Serialize(), src, now.Add(time.Second))
+	require.Len(t, received, 1)
+
+	d.handlePacket(newAnnouncement(chainID, 6001, otherNode).Serialize(), src, now.Add(d.Config.PeerRateLimit))
+	require.Len(t, received, 2)
+}
+
+func TestRateLimiter(t *testing.T) {
+	r := newRateLimiter(time.Minute, 2)
+	now := time.Now()
+
+	require.True(t, r.allow("1.1.1.1:6000", now))
+	require.False(t, r.allow("1.1.1.1:6000", now))
+	require.True(t, r.allow("2.2.2.2:6000", now))
+
+	// The limit per second is reached
+	require.False(t, r.allow("3.3.3.3:6000", now))
+	require.False(t, r.allow("3.3.3.3:6000", now.Add(time.Millisecond*999)))
+
+	now = now.Add(time.Second)
+	require.True(t, r.allow("3.3.3.3:6000", now))
+	require.False(t, r.allow("1.1.1.1:6000", now))
+
+	// Addresses that are no longer limited are forgotten when the window resets
+	now = now.Add(time.Minute)
+	require.True(t, r.allow("1.1.1.1:6000", now))
+	require.Len(t, r.seen, 1)
+}
+
+func TestDiscoveryLoopback(t *testing.T) {
+	ifi := loopbackInterface(t)
+	chainID := testutil.MakePubKey()
+
+	var mx sync.Mutex
+	foundA := make(map[string]cipher.PubKey)
+	foundB := make(map[string]cipher.PubKey)
+
+	newDiscovery := func(port uint16, found map[string]cipher.PubKey) *Discovery {
+		cfg := newTestConfig(chainID, port)
+		cfg.Address = "239.255.13.37:16679"
+		cfg.Interface = ifi
+		cfg.AnnounceRate = time.Millisecond * 50
+		d, err := New(cfg, func(addr string, nodePubKey cipher.PubKey) {
+			mx.Lock()
+			defer mx.Unlock()
+			found[addr] = nodePubKey
+		})
+		require.NoError(t, err)
+		return d
+	}
+
+	a := newDiscovery(6001, foundA)
+	b := newDiscovery(6002, foundB)
+
+	errC := make(chan error, 2)
+	go func() {
+		errC <- a.Run()
+	}()
+	defer a.Shutdown()
+	go func() {
+		errC <- b.Run()
+	}()
+	defer b.Shutdown()
+
+	done := func() bool {
+		mx.Lock()
+		defer mx.Unlock()
+		return len(foundA) > 0 && len(foundB) > 0
+	}
+
+	for i := 0; i < 100 && !done(); i++ {
+		select {
+		case err := <-errC:
+			t.Skipf("Multicast is not available on the loopback interface: %v", err)
+		case <-time.After(time.Millisecond * 20):
+		}
+	}
+
+	mx.Lock()
+	defer mx.Unlock()
+	require.Equal(t, map[string]cipher.PubKey{"127.0.0.1:6002": b.Config.NodePubKey}, foundA)
+	require.Equal(t, map[string]cipher.PubKey{"127.0.0.1:6001": a.Config.NodePubKey}, foundB)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/util/iputil"
 	"github.com/skycoin/skycoin/src/util/useragent"
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 // Message represent a packet to be serialized over the network by
@@ -59,6 +61,9 @@ func getMessageConfigs() []MessageConfig {
 		NewMessageConfig("GIVT", GiveTxnsMessage{}),
 		NewMessageConfig("ANNT", AnnounceTxnsMessage{}),
 		NewMessageConfig("DISC", DisconnectMessage{}),
+		NewMessageConfig("AUTH", NodeAuthMessage{}),
+		NewMessageConfig("DBLS", DoubleSignProofMessage{}),
+		NewMessageConfig("CFLT", TxnConflictMessage{}),
 	}
 }
 
@@ -237,6 +242,10 @@ type IntroductionMessage struct {
 	c                    *gnet.MessageContext `enc:"-"`
 	userAgent            useragent.Data       `enc:"-"`
 	unconfirmedVerifyTxn params.VerifyTxn     `enc:"-"`
+	nodePubKey           cipher.PubKey        `enc:"-"`
+	authChallenge        cipher.SHA256        `enc:"-"`
+	blocksOnly           bool                 `enc:"-"`
+	compression          bool                 `enc:"-"`
 
 	// Mirror is a random value generated on client startup that is used to identify self-connections
 	Mirror uint32
@@ -255,20 +264,35 @@ type IntroductionMessage struct {
 	// MaxTxnSize          uint32 // max txn size for announced txns
 	// MaxDropletPrecision uint8 // maximum number of decimal places for announced txns
 	// UserAgent           string `enc:",maxlen=256"`
+	// NodePubKey          cipher.PubKey // node pubkey, optional
+	// AuthChallenge       cipher.SHA256 // random challenge for the peer to sign in a NodeAuthMessage, sent with NodePubKey
+	// RelayFlags          uint8 // optional, sent after AuthChallenge. RelayFlagBlocksOnly is set if the peer does not want transactions
+	// Compression         uint8 // optional, sent after RelayFlags. CompressionFlate is set if the peer can decompress messages
 	Extra []byte `enc:",omitempty"`
 }
 
-// NewIntroductionMessage creates introduction message
-func NewIntroductionMessage(mirror uint32, version int32, port uint16, pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn) *IntroductionMessage {
+// RelayFlagBlocksOnly is set in the RelayFlags of an IntroductionMessage by a node that runs in blocks-only mode.
+// Peers do not announce or send transactions to a blocks-only node
+const RelayFlagBlocksOnly uint8 = 1
+
+// CompressionFlate is set in the Compression byte of an IntroductionMessage by a node that can decompress
+// gnet messages compressed with DEFLATE. Messages are only compressed for peers that set it
+const CompressionFlate uint8 = 1
+
+// NewIntroductionMessage creates introduction message.
+// If nodePubKey is null, the node pubkey and auth challenge are not included.
+// The relay flags and compression follow the auth challenge, so blocksOnly and compression
+// can only be advertised with a node pubkey
+func NewIntroductionMessage(mirror uint32, version int32, port uint16, pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn, nodePubKey cipher.PubKey, authChallenge cipher.SHA256, blocksOnly, compression bool) *IntroductionMessage {
 	return &IntroductionMessage{
 		Mirror:          mirror,
 		ProtocolVersion: version,
 		ListenPort:      port,
-		Extra:           newIntroductionMessageExtra(pubkey, userAgent, verifyParams),
+		Extra:           newIntroductionMessageExtra(pubkey, userAgent, verifyParams, nodePubKey, authChallenge, blocksOnly, compression),
 	}
 }
 
-func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn) []byte {
+func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn, nodePubKey cipher.PubKey, authChallenge cipher.SHA256, blocksOnly, compression bool) []byte {
 	if len(userAgent) > useragent.MaxLen {
 		logger.WithFields(logrus.Fields{
 			"userAgent": userAgent,
@@ -295,6 +319,23 @@ func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyP
 	i += len(verifyParamsSerialized)
 	copy(extra[i:], userAgentSerialized)
 
+	if !nodePubKey.Null() {
+		extra = append(extra, nodePubKey[:]...)
+		extra = append(extra, authChallenge[:]...)
+
+		if blocksOnly || compression {
+			var relayFlags uint8
+			if blocksOnly {
+				relayFlags |= RelayFlagBlocksOnly
+			}
+			extra = append(extra, relayFlags)
+		}
+
+		if compression {
+			extra = append(extra, CompressionFlate)
+		}
+	}
+
 	return extra
 }
 
@@ -339,6 +380,8 @@ func (intro *IntroductionMessage) process(d daemoner) {
 			return
 		case ErrConnectionIPMirrorExists:
 			reason = ErrDisconnectConnectedTwice
+		case ErrDisconnectNodePubKeyNotMatched:
+			reason = ErrDisconnectNodePubKeyNotMatched
 		case pex.ErrPeerlistFull:
 			reason = ErrDisconnectPeerlistFull
 			// Send more peers before disconnecting
@@ -357,6 +400,13 @@ func (intro *IntroductionMessage) process(d daemoner) {
 		return
 	}
 
+	// Prove that we own our node pubkey, if the peer sent an auth challenge
+	if !intro.nodePubKey.Null() {
+		if err := d.sendNodeAuth(addr, intro.authChallenge); err != nil {
+			logger.WithError(err).WithFields(fields).Warning("sendNodeAuth failed")
+		}
+	}
+
 	// Request blocks immediately after they're confirmed
 	if err := d.requestBlocksFromAddr(addr); err != nil {
 		logger.WithError(err).WithFields(fields).Warning("requestBlocksFromAddr")
@@ -397,6 +447,30 @@ func (intro *IntroductionMessage) verify(d daemoner) error {
 
 	logger.WithFields(fields).WithField("protocolVersion", intro.ProtocolVersion).Debug("Peer protocol version accepted")
 
+	if err := intro.verifyExtra(dc.BlockchainPubkey, fields); err != nil {
+		return err
+	}
+
+	// Disconnect if the peer's version is below the minimum for its coin, or is known to be buggy
+	if err := dc.peerVersions.check(intro.userAgent); err != nil {
+		logger.WithError(err).WithFields(fields).WithFields(logrus.Fields{
+			"coin":    intro.userAgent.Coin,
+			"version": intro.userAgent.Version,
+		}).Info("Peer user agent version rejected")
+		return err
+	}
+
+	return nil
+}
+
+// VerifyExtra checks the blockchain pubkey in the Extra data of an IntroductionMessage received from a peer,
+// and parses the user agent, transaction verification parameters, node pubkey and flags that follow it.
+// It is used by tools that speak the peer protocol without running a Daemon, such as the network crawler
+func (intro *IntroductionMessage) VerifyExtra(blockchainPubkey cipher.PubKey) error {
+	return intro.verifyExtra(blockchainPubkey, logrus.Fields{})
+}
+
+func (intro *IntroductionMessage) verifyExtra(blockchainPubkey cipher.PubKey, fields logrus.Fields) error {
 	// v24 does not send blockchain pubkey or user agent
 	// v25 sends blockchain pubkey and user agent
 	// v24 and v25 check the blockchain pubkey and user agent, would accept message with no Pubkey and user agent
@@ -409,10 +483,10 @@ func (intro *IntroductionMessage) verify(d daemoner) error {
 		}
 		copy(bcPubKey[:], intro.Extra[:len(bcPubKey)])
 
-		if dc.BlockchainPubkey != bcPubKey {
+		if blockchainPubkey != bcPubKey {
 			logger.WithFields(fields).WithFields(logrus.Fields{
 				"pubkey":       bcPubKey.Hex(),
-				"daemonPubkey": dc.BlockchainPubkey.Hex(),
+				"daemonPubkey": blockchainPubkey.Hex(),
 			}).Warning("Blockchain pubkey does not match")
 			return ErrDisconnectBlockchainPubkeyNotMatched
 		}
@@ -447,7 +521,7 @@ func (intro *IntroductionMessage) verify(d daemoner) error {
 		}
 
 		userAgentSerialized := intro.Extra[len(bcPubKey)+9:]
-		userAgent, _, err := encoder.DeserializeString(userAgentSerialized, useragent.MaxLen)
+		userAgent, n, err := encoder.DeserializeString(userAgentSerialized, useragent.MaxLen)
 		if err != nil {
 			logger.WithError(err).WithFields(fields).Warning("Extra data user agent string could not be deserialized")
 			return ErrDisconnectInvalidExtraData
@@ -458,11 +532,96 @@ func (intro *IntroductionMessage) verify(d daemoner) error {
 			logger.WithError(err).WithFields(fields).WithField("userAgent", userAgent).Warning("User agent is invalid")
 			return ErrDisconnectInvalidUserAgent
 		}
+
+		// The node pubkey and auth challenge are optional. Older clients ignore them.
+		// Data that is not a valid node pubkey and auth challenge is ignored too,
+		// the peer is then treated as a peer that did not send a node pubkey
+		nodeAuth := userAgentSerialized[n:]
+		nodeAuthLen := len(intro.nodePubKey) + len(intro.authChallenge)
+		if len(nodeAuth) >= nodeAuthLen {
+			var nodePubKey cipher.PubKey
+			copy(nodePubKey[:], nodeAuth[:len(nodePubKey)])
+
+			if err := nodePubKey.Verify(); err != nil {
+				logger.WithError(err).WithFields(fields).Debug("IntroductionMessage node pubkey is invalid, ignoring it")
+			} else {
+				intro.nodePubKey = nodePubKey
+				copy(intro.authChallenge[:], nodeAuth[len(nodePubKey):])
+
+				// The relay flags are optional. A peer that does not send them relays transactions
+				if len(nodeAuth) > nodeAuthLen {
+					intro.blocksOnly = nodeAuth[nodeAuthLen]&RelayFlagBlocksOnly != 0
+				}
+
+				// The compression is optional. A peer that does not send it can't decompress messages
+				if len(nodeAuth) > nodeAuthLen+1 {
+					intro.compression = nodeAuth[nodeAuthLen+1]&CompressionFlate != 0
+				}
+			}
+		}
 	}
 
 	return nil
 }
 
+// UserAgent returns the user agent parsed from the Extra data by VerifyExtra
+func (intro *IntroductionMessage) UserAgent() useragent.Data {
+	return intro.userAgent
+}
+
+// NodePubKey returns the node pubkey parsed from the Extra data by VerifyExtra.
+// It is null if the peer did not send a node pubkey
+func (intro *IntroductionMessage) NodePubKey() cipher.PubKey {
+	return intro.nodePubKey
+}
+
+// BlocksOnly returns true if the peer asked not to receive transactions, parsed from the Extra data by VerifyExtra
+func (intro *IntroductionMessage) BlocksOnly() bool {
+	return intro.blocksOnly
+}
+
+// NodeAuthMessage is sent in reply to an IntroductionMessage that includes a node pubkey and auth challenge.
+// It proves that the sender owns the node pubkey sent in its own IntroductionMessage,
+// so that trusted peers which are pinned by their node pubkey can be authenticated
+type NodeAuthMessage struct {
+	c *gnet.MessageContext `enc:"-"`
+	// Sig signs the auth challenge of the recipient's IntroductionMessage with the sender's node secret key
+	Sig cipher.Sig
+}
+
+// NewNodeAuthMessage creates a NodeAuthMessage replying to an auth challenge
+func NewNodeAuthMessage(challenge cipher.SHA256, nodeSecKey cipher.SecKey) *NodeAuthMessage {
+	return &NodeAuthMessage{
+		Sig: cipher.MustSignHash(nodeAuthHash(challenge), nodeSecKey),
+	}
+}
+
+// Handle records message event in daemon
+func (auth *NodeAuthMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	auth.c = mc
+	return daemon.(daemoner).recordMessageEvent(auth, mc)
+}
+
+// process an event queued by Handle()
+func (auth *NodeAuthMessage) process(d daemoner) {
+	fields := logrus.Fields{
+		"addr":   auth.c.Addr,
+		"gnetID": auth.c.ConnID,
+	}
+
+	if err := d.connectionAuthenticated(auth.c.Addr, auth.c.ConnID, auth.Sig); err != nil {
+		logger.WithError(err).WithFields(fields).Warning("connectionAuthenticated failed")
+		if err == ErrDisconnectInvalidNodeAuth {
+			if err := d.Disconnect(auth.c.Addr, ErrDisconnectInvalidNodeAuth); err != nil {
+				logger.WithError(err).WithFields(fields).Warning("Disconnect")
+			}
+		}
+		return
+	}
+
+	logger.WithFields(fields).Debug("Peer node pubkey authenticated")
+}
+
 // PingMessage Sent to keep a connection alive. A PongMessage is sent in reply.
 type PingMessage struct {
 	c *gnet.MessageContext `enc:"-"`
@@ -489,20 +648,21 @@ func (ping *PingMessage) process(d daemoner) {
 	}
 }
 
-// PongMessage Sent in reply to a PingMessage.  No action is taken when this is received.
+// PongMessage Sent in reply to a PingMessage. It is used to measure the connection's RTT.
 type PongMessage struct {
 }
 
 // Handle handles message
 func (pong *PongMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
-	// There is nothing to do; gnet updates Connection.LastMessage internally
-	// when this is received
-	if daemon.(*Daemon).Config.LogPings {
+	// gnet updates Connection.LastMessage internally when this is received
+	d := daemon.(*Daemon)
+	if d.Config.LogPings {
 		logger.WithFields(logrus.Fields{
 			"addr":   mc.Addr,
 			"gnetID": mc.ConnID,
 		}).Debug("Received pong")
 	}
+	d.recordPong(mc.Addr, mc.ConnID)
 	return nil
 }
 
@@ -630,6 +790,11 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 	// It is not necessary that the blocks be executed together in a single transaction.
 
 	processed := 0
+	failed := false
+	defer func() {
+		d.recordBlocksReceived(m.c.Addr, m.c.ConnID, failed)
+	}()
+
 	maxSeq, ok, err := d.headBkSeq()
 	if err != nil {
 		logger.WithError(err).Error("d.headBkSeq failed")
@@ -640,6 +805,9 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 		return
 	}
 
+	// Whether the peer sent its block at our head seq, which was compared with our head block
+	checkedHead := false
+
 	for _, b := range m.Blocks {
 		// To minimize waste when receiving multiple responses from peers
 		// we only break out of the loop if the block itself is invalid.
@@ -648,6 +816,12 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 		// the reply with 15 was received first, we would toss the one with 20
 		// even though we could process it at the time.
 		if b.Seq() <= maxSeq {
+			// Blocks that we already have at the same seq are compared with ours,
+			// to detect the block publisher signing conflicting blocks
+			d.detectDoubleSign(m.c.Addr, b)
+			if b.Seq() == maxSeq {
+				checkedHead = true
+			}
 			continue
 		}
 
@@ -655,12 +829,30 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 		if err == nil {
 			logger.Critical().WithField("seq", b.Block.Head.BkSeq).Info("Added new block")
 			processed++
-		} else {
-			logger.Critical().WithError(err).WithField("seq", b.Block.Head.BkSeq).Error("Failed to execute received block")
-			// Blocks must be received in order, so if one fails its assumed
-			// the rest are failing
+			continue
+		}
+
+		if err == visor.ErrBlockAcceptanceHalted {
+			// The peer is not at fault
+			logger.Critical().WithField("seq", b.Block.Head.BkSeq).Error("Block acceptance is halted, ignoring received block")
 			break
 		}
+
+		logger.Critical().WithError(err).WithField("seq", b.Block.Head.BkSeq).Error("Failed to execute received block")
+		failed = true
+
+		// The block builds on a different block at our head seq.
+		// Request that block from the peer, to check whether the block publisher signed both
+		if err == visor.ErrBlockPrevHashMismatch && !checkedHead && maxSeq > 0 {
+			gbm := NewGetBlocksMessage(maxSeq-1, d.daemonConfig().BlocksResponseCount)
+			if err := d.sendMessage(m.c.Addr, gbm); err != nil {
+				logger.WithError(err).WithField("addr", m.c.Addr).Warning("Send GetBlocksMessage failed")
+			}
+		}
+
+		// Blocks must be received in order, so if one fails its assumed
+		// the rest are failing
+		break
 	}
 	if processed == 0 {
 		return
@@ -695,6 +887,113 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 	}
 }
 
+// DoubleSignProofMessage carries two different blocks at the same seq, both signed by the block publisher.
+// It is broadcast by a node that detects the conflicting blocks, and relayed by nodes that had not seen the proof
+type DoubleSignProofMessage struct {
+	BlockA coin.SignedBlock
+	BlockB coin.SignedBlock
+	c      *gnet.MessageContext `enc:"-"`
+}
+
+// NewDoubleSignProofMessage creates a DoubleSignProofMessage
+func NewDoubleSignProofMessage(p visor.DoubleSignProof) *DoubleSignProofMessage {
+	return &DoubleSignProofMessage{
+		BlockA: p.BlockA,
+		BlockB: p.BlockB,
+	}
+}
+
+// Handle handle message
+func (m *DoubleSignProofMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process process message
+func (m *DoubleSignProofMessage) process(d daemoner) {
+	if d.daemonConfig().DisableNetworking {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   m.c.Addr,
+		"gnetID": m.c.ConnID,
+		"seq":    m.BlockA.Seq(),
+	}
+
+	p := visor.DoubleSignProof{
+		BlockA:     m.BlockA,
+		BlockB:     m.BlockB,
+		Source:     m.c.Addr,
+		DetectedAt: time.Now().UTC().Unix(),
+	}
+
+	if err := d.addDoubleSignProof(p); err != nil {
+		if _, ok := err.(visor.ErrInvalidDoubleSignProof); ok {
+			logger.WithError(err).WithFields(fields).Warning("Received an invalid double sign proof")
+			if err := d.Disconnect(m.c.Addr, ErrDisconnectInvalidDoubleSignProof); err != nil {
+				logger.WithError(err).WithFields(fields).Warning("Disconnect")
+			}
+			return
+		}
+
+		logger.WithError(err).WithFields(fields).Error("addDoubleSignProof failed")
+	}
+}
+
+// TxnConflictMessage carries two different transactions which spend the same output.
+// It is broadcast by a node that sees a transaction conflicting with one in its unconfirmed pool,
+// and relayed by nodes that had not seen the conflict. Relays are rate limited
+type TxnConflictMessage struct {
+	TxnA coin.Transaction
+	TxnB coin.Transaction
+	c    *gnet.MessageContext `enc:"-"`
+}
+
+// NewTxnConflictMessage creates a TxnConflictMessage
+func NewTxnConflictMessage(a, b coin.Transaction) *TxnConflictMessage {
+	return &TxnConflictMessage{
+		TxnA: a,
+		TxnB: b,
+	}
+}
+
+// Handle handle message
+func (m *TxnConflictMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process process message
+func (m *TxnConflictMessage) process(d daemoner) {
+	if d.daemonConfig().DisableNetworking {
+		return
+	}
+
+	// A blocks-only node does not track transactions from peers
+	if d.daemonConfig().BlocksOnly {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   m.c.Addr,
+		"gnetID": m.c.ConnID,
+		"txidA":  m.TxnA.Hash().Hex(),
+		"txidB":  m.TxnB.Hash().Hex(),
+	}
+
+	if err := d.addTxnConflict(m.c.Addr, m.TxnA, m.TxnB); err != nil {
+		// The evidence becomes invalid when one of the transactions is confirmed,
+		// so an honest peer can send evidence that is invalid for this node
+		if _, ok := err.(visor.ErrInvalidTxnConflict); ok {
+			logger.WithError(err).WithFields(fields).Debug("Received invalid transaction conflict evidence")
+			return
+		}
+
+		logger.WithError(err).WithFields(fields).Error("addTxnConflict failed")
+	}
+}
+
 // AnnounceBlocksMessage tells a peer our highest known BkSeq. The receiving peer can choose
 // to send GetBlocksMessage in response
 type AnnounceBlocksMessage struct {
@@ -736,6 +1035,9 @@ func (abm *AnnounceBlocksMessage) process(d daemoner) {
 		return
 	}
 
+	// Record the announced block as this peer's highest block
+	d.recordPeerHeight(abm.c.Addr, abm.c.ConnID, abm.MaxBkSeq)
+
 	if headBkSeq >= abm.MaxBkSeq {
 		return
 	}
@@ -788,6 +1090,14 @@ func (atm *AnnounceTxnsMessage) process(d daemoner) {
 		"gnetID": atm.c.ConnID,
 	}
 
+	// The peer announces transactions that it accepted, including the ones we broadcast
+	d.recordTxnsAccepted(atm.c.Addr, atm.Transactions)
+
+	// A blocks-only node does not accept transactions from peers
+	if d.daemonConfig().BlocksOnly {
+		return
+	}
+
 	unknown, err := d.filterKnownUnconfirmed(atm.Transactions)
 	if err != nil {
 		logger.WithError(err).Error("AnnounceTxnsMessage d.filterKnownUnconfirmed failed")
@@ -844,6 +1154,8 @@ func (gtm *GetTxnsMessage) process(d daemoner) {
 		return
 	}
 
+	d.recordTxnsRequested(gtm.c.Addr, known.Hashes())
+
 	// Reply to sender with GiveTxnsMessage
 	m := NewGiveTxnsMessage(known)
 	if err := d.sendMessage(gtm.c.Addr, m); err != nil {
@@ -881,6 +1193,13 @@ func (gtm *GiveTxnsMessage) process(d daemoner) {
 		return
 	}
 
+	// A blocks-only node does not accept transactions from peers.
+	// Peers that know about it do not send any, but older clients still might
+	if d.daemonConfig().BlocksOnly {
+		logger.WithField("addr", gtm.c.Addr).Debugf("Blocks-only mode, ignoring %d transactions", len(gtm.Transactions))
+		return
+	}
+
 	hashes := make([]cipher.SHA256, 0, len(gtm.Transactions))
 	// Update unconfirmed pool with these transactions
 	for _, txn := range gtm.Transactions {
@@ -890,15 +1209,23 @@ func (gtm *GiveTxnsMessage) process(d daemoner) {
 		known, softErr, err := d.injectTransaction(txn)
 		if err != nil {
 			logger.WithError(err).WithField("txid", txn.Hash().Hex()).Warning("Failed to record transaction")
+			d.recordTxnRejected(gtm.c.Addr, txn.Hash(), err, false)
 			continue
-		} else if softErr != nil {
+		}
+
+		d.recordTxnReceived(gtm.c.Addr, txn.Hash())
+
+		if softErr != nil {
 			logger.WithError(err).WithField("txid", txn.Hash().Hex()).Warning("Transaction soft violation")
+			d.recordTxnRejected(gtm.c.Addr, txn.Hash(), *softErr, true)
 			// Allow soft txn violations to rebroadcast
 		} else if known {
 			logger.WithField("txid", txn.Hash().Hex()).Debug("Duplicate transaction")
 			continue
 		}
 
+		d.detectTxnConflicts(gtm.c.Addr, txn)
+
 		hashes = append(hashes, txn.Hash())
 	}
 
@@ -908,7 +1235,7 @@ func (gtm *GiveTxnsMessage) process(d daemoner) {
 
 	// Announce these transactions to peers
 	m := NewAnnounceTxnsMessage(hashes)
-	if ids, err := d.broadcastMessage(m); err != nil {
+	if ids, err := d.broadcastTxnsMessage(m); err != nil {
 		logger.WithError(err).Warning("Broadcast AnnounceTxnsMessage failed")
 	} else {
 		logger.Debugf("Announced %d transactions to %d peers", len(hashes), len(ids))
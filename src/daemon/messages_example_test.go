@@ -424,7 +424,7 @@ func ExampleIntroductionMessage() {
 		BurnFactor:          2,
 		MaxTransactionSize:  32768,
 		MaxDropletPrecision: 3,
-	})
+	}, cipher.PubKey{}, cipher.SHA256{}, false, false)
 	fmt.Println("IntroductionMessage:")
 	var mai = NewMessagesAnnotationsIterator(message)
 	w := bufio.NewWriter(os.Stdout)
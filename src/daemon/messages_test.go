@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -16,7 +17,9 @@ import (
 	"github.com/skycoin/skycoin/src/daemon/gnet"
 	"github.com/skycoin/skycoin/src/daemon/pex"
 	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/util/useragent"
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 func TestIntroductionMessage(t *testing.T) {
@@ -25,6 +28,8 @@ func TestIntroductionMessage(t *testing.T) {
 
 	pubkey, _ := cipher.GenerateKeyPair()
 	pubkey2, _ := cipher.GenerateKeyPair()
+	nodePubKey, _ := cipher.GenerateKeyPair()
+	authChallenge := newAuthChallenge()
 
 	type daemonMockValue struct {
 		protocolVersion          uint32
@@ -39,6 +44,8 @@ func TestIntroductionMessage(t *testing.T) {
 		requestBlocksFromAddrErr error
 		announceAllTxnsErr       error
 		sendRandomPeersErr       error
+		sendNodeAuthErr          error
+		peerVersions             peerVersionPolicy
 	}
 
 	tt := []struct {
@@ -48,6 +55,10 @@ func TestIntroductionMessage(t *testing.T) {
 		mockValue            daemonMockValue
 		userAgent            useragent.Data
 		unconfirmedVerifyTxn params.VerifyTxn
+		nodePubKey           cipher.PubKey
+		authChallenge        cipher.SHA256
+		blocksOnly           bool
+		compression          bool
 		intro                *IntroductionMessage
 	}{
 		{
@@ -142,7 +153,41 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}),
+				}, cipher.PubKey{}, cipher.SHA256{}, false, false),
+			},
+		},
+		{
+			name: "INTR message with user agent version below the minimum",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:           10000,
+				protocolVersion:  1,
+				pubkey:           pubkey,
+				disconnectReason: ErrDisconnectPeerVersionTooLow,
+				peerVersions:     mustNewPeerVersionPolicy(t, []string{"skycoin:0.25.0"}, nil, nil),
+			},
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra:           newIntroductionMessageExtra(pubkey, "skycoin:0.24.1", params.UserVerifyTxn, cipher.PubKey{}, cipher.SHA256{}, false, false),
+			},
+		},
+		{
+			name: "INTR message with buggy user agent version",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:           10000,
+				protocolVersion:  1,
+				pubkey:           pubkey,
+				disconnectReason: ErrDisconnectBuggyPeerVersion,
+				peerVersions:     mustNewPeerVersionPolicy(t, []string{"skycoin:0.24.0"}, nil, []string{"skycoin:>=0.24.1 <0.24.3"}),
+			},
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra:           newIntroductionMessageExtra(pubkey, "skycoin:0.24.1", params.UserVerifyTxn, cipher.PubKey{}, cipher.SHA256{}, false, false),
 			},
 		},
 		{
@@ -185,7 +230,165 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}), []byte("additional data")...),
+				}, cipher.PubKey{}, cipher.SHA256{}, false, false), []byte("additional data")...),
+			},
+		},
+		{
+			name: "INTR message with node pubkey and auth challenge",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:          10000,
+				protocolVersion: 1,
+				pubkey:          pubkey,
+				connectionIntroduced: &connection{
+					Addr: "121.121.121.121:6000",
+					ConnectionDetails: ConnectionDetails{
+						ListenPort: 6000,
+						UserAgent: useragent.Data{
+							Coin:    "skycoin",
+							Version: "0.24.1",
+						},
+						NodePubKey: nodePubKey,
+					},
+				},
+			},
+			userAgent: useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.24.1",
+			},
+			unconfirmedVerifyTxn: params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			},
+			nodePubKey:    nodePubKey,
+			authChallenge: authChallenge,
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra: newIntroductionMessageExtra(pubkey, "skycoin:0.24.1", params.VerifyTxn{
+					BurnFactor:          4,
+					MaxTransactionSize:  32768,
+					MaxDropletPrecision: 3,
+				}, nodePubKey, authChallenge, false, false),
+			},
+		},
+		{
+			name: "INTR message with blocks-only relay flag",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:          10000,
+				protocolVersion: 1,
+				pubkey:          pubkey,
+				connectionIntroduced: &connection{
+					Addr: "121.121.121.121:6000",
+					ConnectionDetails: ConnectionDetails{
+						ListenPort: 6000,
+						UserAgent: useragent.Data{
+							Coin:    "skycoin",
+							Version: "0.24.1",
+						},
+						NodePubKey: nodePubKey,
+						BlocksOnly: true,
+					},
+				},
+			},
+			userAgent: useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.24.1",
+			},
+			unconfirmedVerifyTxn: params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			},
+			nodePubKey:    nodePubKey,
+			authChallenge: authChallenge,
+			blocksOnly:    true,
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra: newIntroductionMessageExtra(pubkey, "skycoin:0.24.1", params.VerifyTxn{
+					BurnFactor:          4,
+					MaxTransactionSize:  32768,
+					MaxDropletPrecision: 3,
+				}, nodePubKey, authChallenge, true, false),
+			},
+		},
+		{
+			name: "INTR message with compression",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:          10000,
+				protocolVersion: 1,
+				pubkey:          pubkey,
+				connectionIntroduced: &connection{
+					Addr: "121.121.121.121:6000",
+					ConnectionDetails: ConnectionDetails{
+						ListenPort: 6000,
+						UserAgent: useragent.Data{
+							Coin:    "skycoin",
+							Version: "0.24.1",
+						},
+						NodePubKey: nodePubKey,
+					},
+				},
+			},
+			userAgent: useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.24.1",
+			},
+			unconfirmedVerifyTxn: params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			},
+			nodePubKey:    nodePubKey,
+			authChallenge: authChallenge,
+			compression:   true,
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra: newIntroductionMessageExtra(pubkey, "skycoin:0.24.1", params.VerifyTxn{
+					BurnFactor:          4,
+					MaxTransactionSize:  32768,
+					MaxDropletPrecision: 3,
+				}, nodePubKey, authChallenge, false, true),
+			},
+		},
+		{
+			name: "INTR message with node pubkey not matching trusted peer",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:                  10000,
+				protocolVersion:         1,
+				pubkey:                  pubkey,
+				disconnectReason:        ErrDisconnectNodePubKeyNotMatched,
+				connectionIntroducedErr: ErrDisconnectNodePubKeyNotMatched,
+			},
+			userAgent: useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.24.1",
+			},
+			unconfirmedVerifyTxn: params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			},
+			nodePubKey:    nodePubKey,
+			authChallenge: authChallenge,
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra: newIntroductionMessageExtra(pubkey, "skycoin:0.24.1", params.VerifyTxn{
+					BurnFactor:          4,
+					MaxTransactionSize:  32768,
+					MaxDropletPrecision: 3,
+				}, nodePubKey, authChallenge, false, false),
 			},
 		},
 		{
@@ -205,7 +408,7 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}),
+				}, cipher.PubKey{}, cipher.SHA256{}, false, false),
 			},
 		},
 		{
@@ -365,7 +568,7 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}),
+				}, cipher.PubKey{}, cipher.SHA256{}, false, false),
 			},
 		},
 		{
@@ -424,6 +627,7 @@ func TestIntroductionMessage(t *testing.T) {
 				},
 				Mirror:           tc.mockValue.mirror,
 				BlockchainPubkey: tc.mockValue.pubkey,
+				peerVersions:     tc.mockValue.peerVersions,
 			})
 			d.On("recordMessageEvent", tc.intro, mc).Return(tc.mockValue.recordMessageEventErr)
 			d.On("Disconnect", tc.addr, tc.mockValue.disconnectReason).Return(tc.mockValue.disconnectErr)
@@ -439,12 +643,19 @@ func TestIntroductionMessage(t *testing.T) {
 				if tc.unconfirmedVerifyTxn != m.unconfirmedVerifyTxn {
 					return false
 				}
+				if tc.nodePubKey != m.nodePubKey || tc.authChallenge != m.authChallenge {
+					return false
+				}
+				if tc.blocksOnly != m.blocksOnly || tc.compression != m.compression {
+					return false
+				}
 
 				return true
 			})).Return(tc.mockValue.connectionIntroduced, tc.mockValue.connectionIntroducedErr)
 			d.On("requestBlocksFromAddr", tc.addr).Return(tc.mockValue.requestBlocksFromAddrErr)
 			d.On("announceAllValidTxns").Return(tc.mockValue.announceAllTxnsErr)
 			d.On("sendRandomPeers", tc.addr).Return(tc.mockValue.sendRandomPeersErr)
+			d.On("sendNodeAuth", tc.addr, tc.authChallenge).Return(tc.mockValue.sendNodeAuthErr)
 
 			err := tc.intro.Handle(mc, d)
 			require.NoError(t, err)
@@ -456,6 +667,387 @@ func TestIntroductionMessage(t *testing.T) {
 			} else {
 				d.AssertNotCalled(t, "Disconnect", mock.Anything, mock.Anything)
 			}
+
+			if tc.mockValue.disconnectReason == nil && !tc.nodePubKey.Null() {
+				d.AssertCalled(t, "sendNodeAuth", tc.addr, tc.authChallenge)
+			} else {
+				d.AssertNotCalled(t, "sendNodeAuth", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestNodeAuthMessage(t *testing.T) {
+	defer gnet.EraseMessages()
+	setupMsgEncoding()
+
+	cases := []struct {
+		name                       string
+		connectionAuthenticatedErr error
+		disconnect                 bool
+	}{
+		{
+			name: "authenticated",
+		},
+		{
+			name:                       "invalid signature",
+			connectionAuthenticatedErr: ErrDisconnectInvalidNodeAuth,
+			disconnect:                 true,
+		},
+		{
+			name:                       "connection gone",
+			connectionAuthenticatedErr: ErrConnectionNotExist,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := "121.121.121.121:6000"
+			mc := &gnet.MessageContext{
+				Addr:   addr,
+				ConnID: 1,
+			}
+
+			_, sk := cipher.GenerateKeyPair()
+			m := NewNodeAuthMessage(newAuthChallenge(), sk)
+
+			d := &mockDaemoner{}
+			d.On("recordMessageEvent", m, mc).Return(nil)
+			d.On("connectionAuthenticated", addr, uint64(1), m.Sig).Return(tc.connectionAuthenticatedErr)
+			d.On("Disconnect", addr, ErrDisconnectInvalidNodeAuth).Return(nil)
+
+			err := m.Handle(mc, d)
+			require.NoError(t, err)
+
+			m.process(d)
+
+			d.AssertCalled(t, "connectionAuthenticated", addr, uint64(1), m.Sig)
+			if tc.disconnect {
+				d.AssertCalled(t, "Disconnect", addr, ErrDisconnectInvalidNodeAuth)
+			} else {
+				d.AssertNotCalled(t, "Disconnect", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTxnsMessagesBlocksOnly(t *testing.T) {
+	defer gnet.EraseMessages()
+	setupMsgEncoding()
+
+	addr := "121.121.121.121:6000"
+	mc := &gnet.MessageContext{
+		Addr:   addr,
+		ConnID: 1,
+	}
+
+	txn := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+	}
+
+	d := &mockDaemoner{}
+	d.On("daemonConfig").Return(DaemonConfig{
+		BlocksOnly: true,
+	})
+	d.On("recordTxnsAccepted", addr, []cipher.SHA256{txn.Hash()})
+
+	// Announced transactions are not requested, but the peers that accepted our transactions are still recorded
+	atm := NewAnnounceTxnsMessage([]cipher.SHA256{txn.Hash()})
+	atm.c = mc
+	atm.process(d)
+
+	d.AssertCalled(t, "recordTxnsAccepted", addr, []cipher.SHA256{txn.Hash()})
+	d.AssertNotCalled(t, "filterKnownUnconfirmed", mock.Anything)
+	d.AssertNotCalled(t, "sendMessage", mock.Anything, mock.Anything)
+
+	// Transactions from peers are ignored and not relayed
+	gtm := NewGiveTxnsMessage(coin.Transactions{txn})
+	gtm.c = mc
+	gtm.process(d)
+
+	d.AssertNotCalled(t, "injectTransaction", mock.Anything)
+	d.AssertNotCalled(t, "broadcastTxnsMessage", mock.Anything)
+}
+
+func TestTxnsMessagesRecordRelay(t *testing.T) {
+	defer gnet.EraseMessages()
+	setupMsgEncoding()
+
+	addr := "121.121.121.121:6000"
+	mc := &gnet.MessageContext{
+		Addr:   addr,
+		ConnID: 1,
+	}
+
+	newTxn := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+	}
+	softTxn := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+	}
+	hardTxn := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+	}
+
+	softErr := visor.ErrTxnViolatesSoftConstraint{
+		Err: errors.New("soft"),
+	}
+	hardErr := visor.NewErrTxnViolatesHardConstraint(errors.New("hard"))
+
+	d := &mockDaemoner{}
+	d.On("daemonConfig").Return(DaemonConfig{})
+	d.On("recordTxnReceived", addr, mock.Anything)
+	d.On("recordTxnRejected", addr, mock.Anything, mock.Anything, mock.Anything)
+	d.On("injectTransaction", newTxn).Return(false, nil, nil)
+	d.On("injectTransaction", softTxn).Return(false, &softErr, nil)
+	d.On("injectTransaction", hardTxn).Return(false, nil, hardErr)
+	d.On("broadcastTxnsMessage", mock.Anything).Return([]uint64{1}, nil)
+	d.On("detectTxnConflicts", addr, mock.Anything)
+
+	// Transactions added to the pool and the reasons received transactions were rejected are recorded
+	gtm := NewGiveTxnsMessage(coin.Transactions{newTxn, softTxn, hardTxn})
+	gtm.c = mc
+	gtm.process(d)
+
+	d.AssertCalled(t, "recordTxnReceived", addr, newTxn.Hash())
+	d.AssertCalled(t, "recordTxnReceived", addr, softTxn.Hash())
+	d.AssertNotCalled(t, "recordTxnReceived", addr, hardTxn.Hash())
+	d.AssertCalled(t, "recordTxnRejected", addr, softTxn.Hash(), softErr, true)
+	d.AssertCalled(t, "recordTxnRejected", addr, hardTxn.Hash(), hardErr, false)
+	d.AssertNumberOfCalls(t, "recordTxnRejected", 2)
+	// Only the transactions added to the pool are checked for conflicts
+	d.AssertCalled(t, "detectTxnConflicts", addr, newTxn)
+	d.AssertCalled(t, "detectTxnConflicts", addr, softTxn)
+	d.AssertNumberOfCalls(t, "detectTxnConflicts", 2)
+	d.AssertCalled(t, "broadcastTxnsMessage", NewAnnounceTxnsMessage([]cipher.SHA256{newTxn.Hash(), softTxn.Hash()}))
+
+	// Only the requested transactions that are known are recorded
+	unknown := testutil.RandSHA256(t)
+	d.On("getKnownUnconfirmed", []cipher.SHA256{newTxn.Hash(), unknown}).Return(coin.Transactions{newTxn}, nil)
+	d.On("recordTxnsRequested", addr, []cipher.SHA256{newTxn.Hash()})
+	d.On("sendMessage", addr, NewGiveTxnsMessage(coin.Transactions{newTxn})).Return(nil)
+
+	gettm := NewGetTxnsMessage([]cipher.SHA256{newTxn.Hash(), unknown})
+	gettm.c = mc
+	gettm.process(d)
+
+	d.AssertCalled(t, "recordTxnsRequested", addr, []cipher.SHA256{newTxn.Hash()})
+	d.AssertCalled(t, "sendMessage", addr, NewGiveTxnsMessage(coin.Transactions{newTxn}))
+}
+
+func TestGiveBlocksMessageDoubleSign(t *testing.T) {
+	defer gnet.EraseMessages()
+	setupMsgEncoding()
+
+	addr := "121.121.121.121:6000"
+	mc := &gnet.MessageContext{
+		Addr:   addr,
+		ConnID: 1,
+	}
+
+	makeBlock := func(seq uint64) coin.SignedBlock {
+		return coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: seq,
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		name        string
+		blocks      []coin.SignedBlock
+		executeErr  error
+		failed      bool
+		requestFrom uint64
+	}{
+		{
+			name:   "known blocks are compared",
+			blocks: []coin.SignedBlock{makeBlock(9), makeBlock(10)},
+		},
+		{
+			name:        "prev hash mismatch requests the block at the head seq",
+			blocks:      []coin.SignedBlock{makeBlock(11)},
+			executeErr:  visor.ErrBlockPrevHashMismatch,
+			failed:      true,
+			requestFrom: 9,
+		},
+		{
+			name:       "prev hash mismatch after comparing the head block",
+			blocks:     []coin.SignedBlock{makeBlock(10), makeBlock(11)},
+			executeErr: visor.ErrBlockPrevHashMismatch,
+			failed:     true,
+		},
+		{
+			name:       "block acceptance halted",
+			blocks:     []coin.SignedBlock{makeBlock(11)},
+			executeErr: visor.ErrBlockAcceptanceHalted,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewGiveBlocksMessage(tc.blocks)
+			m.c = mc
+
+			d := &mockDaemoner{}
+			d.On("daemonConfig").Return(DaemonConfig{
+				BlocksResponseCount: 20,
+			})
+			d.On("headBkSeq").Return(uint64(10), true, nil)
+			d.On("detectDoubleSign", addr, mock.Anything)
+			d.On("executeSignedBlock", mock.Anything).Return(tc.executeErr)
+			d.On("sendMessage", addr, mock.Anything).Return(nil)
+			d.On("recordBlocksReceived", addr, uint64(1), tc.failed)
+
+			m.process(d)
+
+			for _, b := range tc.blocks {
+				if b.Seq() <= 10 {
+					d.AssertCalled(t, "detectDoubleSign", addr, b)
+				}
+			}
+
+			if tc.requestFrom != 0 {
+				d.AssertCalled(t, "sendMessage", addr, NewGetBlocksMessage(tc.requestFrom, 20))
+			} else {
+				d.AssertNotCalled(t, "sendMessage", mock.Anything, mock.Anything)
+			}
+
+			d.AssertCalled(t, "recordBlocksReceived", addr, uint64(1), tc.failed)
+		})
+	}
+}
+
+func TestDoubleSignProofMessage(t *testing.T) {
+	defer gnet.EraseMessages()
+	setupMsgEncoding()
+
+	cases := []struct {
+		name       string
+		addErr     error
+		disconnect bool
+	}{
+		{
+			name: "valid proof",
+		},
+		{
+			name:       "invalid proof",
+			addErr:     visor.NewErrInvalidDoubleSignProof(errors.New("blocks are the same")),
+			disconnect: true,
+		},
+		{
+			name:   "database error",
+			addErr: errors.New("database error"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := "121.121.121.121:6000"
+			mc := &gnet.MessageContext{
+				Addr:   addr,
+				ConnID: 1,
+			}
+
+			m := &DoubleSignProofMessage{
+				BlockA: coin.SignedBlock{
+					Block: coin.Block{Head: coin.BlockHeader{BkSeq: 10, Time: 1}},
+				},
+				BlockB: coin.SignedBlock{
+					Block: coin.Block{Head: coin.BlockHeader{BkSeq: 10, Time: 2}},
+				},
+			}
+
+			isProof := mock.MatchedBy(func(p visor.DoubleSignProof) bool {
+				return p.Source == addr &&
+					p.BlockA.HashHeader() == m.BlockA.HashHeader() &&
+					p.BlockB.HashHeader() == m.BlockB.HashHeader()
+			})
+
+			d := &mockDaemoner{}
+			d.On("recordMessageEvent", m, mc).Return(nil)
+			d.On("daemonConfig").Return(DaemonConfig{})
+			d.On("addDoubleSignProof", isProof).Return(tc.addErr)
+			d.On("Disconnect", addr, ErrDisconnectInvalidDoubleSignProof).Return(nil)
+
+			err := m.Handle(mc, d)
+			require.NoError(t, err)
+
+			m.process(d)
+
+			d.AssertCalled(t, "addDoubleSignProof", isProof)
+			if tc.disconnect {
+				d.AssertCalled(t, "Disconnect", addr, ErrDisconnectInvalidDoubleSignProof)
+			} else {
+				d.AssertNotCalled(t, "Disconnect", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTxnConflictMessage(t *testing.T) {
+	defer gnet.EraseMessages()
+	setupMsgEncoding()
+
+	cases := []struct {
+		name       string
+		blocksOnly bool
+		addErr     error
+	}{
+		{
+			name: "valid conflict",
+		},
+		{
+			name:   "invalid conflict",
+			addErr: visor.NewErrInvalidTxnConflict(errors.New("transactions are the same")),
+		},
+		{
+			name:   "database error",
+			addErr: errors.New("database error"),
+		},
+		{
+			name:       "blocks only",
+			blocksOnly: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := "121.121.121.121:6000"
+			mc := &gnet.MessageContext{
+				Addr:   addr,
+				ConnID: 1,
+			}
+
+			in := testutil.RandSHA256(t)
+			m := NewTxnConflictMessage(coin.Transaction{
+				In: []cipher.SHA256{in},
+			}, coin.Transaction{
+				In:  []cipher.SHA256{in},
+				Out: []coin.TransactionOutput{{Coins: 1}},
+			})
+
+			d := &mockDaemoner{}
+			d.On("recordMessageEvent", m, mc).Return(nil)
+			d.On("daemonConfig").Return(DaemonConfig{
+				BlocksOnly: tc.blocksOnly,
+			})
+			d.On("addTxnConflict", addr, m.TxnA, m.TxnB).Return(tc.addErr)
+
+			err := m.Handle(mc, d)
+			require.NoError(t, err)
+
+			m.process(d)
+
+			// Invalid evidence is not a reason to disconnect, because one of the transactions may have been confirmed
+			d.AssertNotCalled(t, "Disconnect", mock.Anything, mock.Anything)
+			if tc.blocksOnly {
+				d.AssertNotCalled(t, "addTxnConflict", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				d.AssertCalled(t, "addTxnConflict", addr, m.TxnA, m.TxnB)
+			}
 		})
 	}
 }
@@ -490,7 +1082,52 @@ func TestMessageEncodeDecode(t *testing.T) {
 					BurnFactor:          2,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}),
+				}, cipher.PubKey{}, cipher.SHA256{}, false, false),
+			},
+		},
+		{
+			goldenFile: "intro-msg-node-auth.golden",
+			obj:        &IntroductionMessage{},
+			msg: &IntroductionMessage{
+				Mirror:          99998888,
+				ListenPort:      8888,
+				ProtocolVersion: 12341234,
+				Extra: newIntroductionMessageExtra(introPubKey, "skycoin:0.25.0(foo)", params.VerifyTxn{
+					BurnFactor:          2,
+					MaxTransactionSize:  32768,
+					MaxDropletPrecision: 3,
+				}, cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a"),
+					cipher.MustSHA256FromHex("23dc4b68c0fc790989bb82f04b9d5174baab6f0f6808ed35be9b93cb73c69108"), false, false),
+			},
+		},
+		{
+			goldenFile: "intro-msg-blocks-only.golden",
+			obj:        &IntroductionMessage{},
+			msg: &IntroductionMessage{
+				Mirror:          99998888,
+				ListenPort:      8888,
+				ProtocolVersion: 12341234,
+				Extra: newIntroductionMessageExtra(introPubKey, "skycoin:0.25.0(foo)", params.VerifyTxn{
+					BurnFactor:          2,
+					MaxTransactionSize:  32768,
+					MaxDropletPrecision: 3,
+				}, cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a"),
+					cipher.MustSHA256FromHex("23dc4b68c0fc790989bb82f04b9d5174baab6f0f6808ed35be9b93cb73c69108"), true, false),
+			},
+		},
+		{
+			goldenFile: "intro-msg-compression.golden",
+			obj:        &IntroductionMessage{},
+			msg: &IntroductionMessage{
+				Mirror:          99998888,
+				ListenPort:      8888,
+				ProtocolVersion: 12341234,
+				Extra: newIntroductionMessageExtra(introPubKey, "skycoin:0.25.0(foo)", params.VerifyTxn{
+					BurnFactor:          2,
+					MaxTransactionSize:  32768,
+					MaxDropletPrecision: 3,
+				}, cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a"),
+					cipher.MustSHA256FromHex("23dc4b68c0fc790989bb82f04b9d5174baab6f0f6808ed35be9b93cb73c69108"), false, true),
 			},
 		},
 		{
@@ -761,6 +1398,89 @@ func TestMessageEncodeDecode(t *testing.T) {
 				},
 			},
 		},
+		{
+			goldenFile: "double-sign-proof-msg.golden",
+			obj:        &DoubleSignProofMessage{},
+			msg: &DoubleSignProofMessage{
+				BlockA: coin.SignedBlock{
+					Sig: cipher.MustSigFromHex("8cf145e9ef4a4a5254bc57798a7a61dfed238768f94edc5635175c6b91bccd8ec1555da603c5e31b018e135b82b1525be8a92973c468a74b5b40b8da189cb465eb"),
+					Block: coin.Block{
+						Head: coin.BlockHeader{
+							Version:  0,
+							Time:     1538036613,
+							BkSeq:    12345,
+							Fee:      1234,
+							PrevHash: cipher.MustSHA256FromHex("59cb7d0e2ce8a03d1054afcc28a22fe864a8813460d241db38c59d10e7c29132"),
+							BodyHash: cipher.MustSHA256FromHex("6d421469409591f0c3112884c8cf10f8bca5d8ab87c9c30dea2ea73b6751bbf9"),
+							UxHash:   cipher.MustSHA256FromHex("6ea6a972cf06d25908b29953aeddb68c3b6f3a9903e8f964dc89b0abc0645dea"),
+						},
+					},
+				},
+				BlockB: coin.SignedBlock{
+					Sig: cipher.MustSigFromHex("8015c8776de577d89c29d1cbd1d558ba4855dec94ba58f6c67d55ece5c85708b9906bd0b72b451e27008f3938fcec42c1a28ddac336ae8206d8e6443b95dde966c"),
+					Block: coin.Block{
+						Head: coin.BlockHeader{
+							Version:  0,
+							Time:     1538036614,
+							BkSeq:    12345,
+							Fee:      4321,
+							PrevHash: cipher.MustSHA256FromHex("59cb7d0e2ce8a03d1054afcc28a22fe864a8813460d241db38c59d10e7c29132"),
+							BodyHash: cipher.MustSHA256FromHex("9a67fbb00216ae99f334d4efa2c9c42a25aac5d1a5bbb2058fe5705cfe0e30ea"),
+							UxHash:   cipher.MustSHA256FromHex("6ea6a972cf06d25908b29953aeddb68c3b6f3a9903e8f964dc89b0abc0645dea"),
+						},
+					},
+				},
+			},
+		},
+		{
+			goldenFile: "txn-conflict-msg.golden",
+			obj:        &TxnConflictMessage{},
+			msg: &TxnConflictMessage{
+				TxnA: coin.Transaction{
+					Length:    220,
+					Type:      0,
+					InnerHash: cipher.MustSHA256FromHex("1773d8901df96bba4c6d65499e11e6ec73a9978c611d1463898ffbc2b49773fc"),
+					Sigs: []cipher.Sig{
+						cipher.MustSigFromHex("a711880ae54d1b6b9adade2ef1e743d6d539a78b0cecf1af08107e467956de80ef1d49fb5e896c9d0870ef8bf8a4d328ca0ecf7c1956866867ec56064e68f8a374"),
+					},
+					In: []cipher.SHA256{
+						cipher.MustSHA256FromHex("703f84ee0702b44fc89ce573a239d5fbf185bf5d4e7fc8f4930262bcda1e8fb0"),
+					},
+					Out: []coin.TransactionOutput{
+						{
+							Address: cipher.MustDecodeBase58Address("29VEn56iRr2TpVVpPoPxUJPfFWuhbLSBRdU"),
+							Coins:   1000000,
+							Hours:   22,
+						},
+					},
+				},
+				TxnB: coin.Transaction{
+					Length:    220,
+					Type:      0,
+					InnerHash: cipher.MustSHA256FromHex("6d421469409591f0c3112884c8cf10f8bca5d8ab87c9c30dea2ea73b6751bbf9"),
+					Sigs: []cipher.Sig{
+						cipher.MustSigFromHex("f9890ddd93f9479e364261ebc647326d2fd57e50b7728795adbf507c956f9eb44f77207b528700c4cef338290cdfc17f814dc3d94e3d711e92492ecc7b8abef808"),
+					},
+					In: []cipher.SHA256{
+						cipher.MustSHA256FromHex("703f84ee0702b44fc89ce573a239d5fbf185bf5d4e7fc8f4930262bcda1e8fb0"),
+					},
+					Out: []coin.TransactionOutput{
+						{
+							Address: cipher.MustDecodeBase58Address("2bqs99tysFtfs8QPT81kpZWnzTT1rWd8xtQ"),
+							Coins:   1000000,
+							Hours:   22,
+						},
+					},
+				},
+			},
+		},
+		{
+			goldenFile: "node-auth-msg.golden",
+			obj:        &NodeAuthMessage{},
+			msg: &NodeAuthMessage{
+				Sig: cipher.MustSigFromHex("8cf145e9ef4a4a5254bc57798a7a61dfed238768f94edc5635175c6b91bccd8ec1555da603c5e31b018e135b82b1525be8a92973c468a74b5b40b8da189cb465eb"),
+			},
+		},
 	}
 
 	if update {
@@ -14,6 +14,20 @@ type mockDaemoner struct {
 	mock.Mock
 }
 
+// addDoubleSignProof provides a mock function with given fields: p
+func (_m *mockDaemoner) addDoubleSignProof(p visor.DoubleSignProof) error {
+	ret := _m.Called(p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(visor.DoubleSignProof) error); ok {
+		r0 = rf(p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // addPeers provides a mock function with given fields: addrs
 func (_m *mockDaemoner) addPeers(addrs []string) int {
 	ret := _m.Called(addrs)
@@ -28,6 +42,20 @@ func (_m *mockDaemoner) addPeers(addrs []string) int {
 	return r0
 }
 
+// addTxnConflict provides a mock function with given fields: addr, a, b
+func (_m *mockDaemoner) addTxnConflict(addr string, a coin.Transaction, b coin.Transaction) error {
+	ret := _m.Called(addr, a, b)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, coin.Transaction, coin.Transaction) error); ok {
+		r0 = rf(addr, a, b)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // announceAllValidTxns provides a mock function with given fields:
 func (_m *mockDaemoner) announceAllValidTxns() error {
 	ret := _m.Called()
@@ -65,6 +93,43 @@ func (_m *mockDaemoner) broadcastMessage(msg gnet.Message) ([]uint64, error) {
 	return r0, r1
 }
 
+// broadcastTxnsMessage provides a mock function with given fields: msg
+func (_m *mockDaemoner) broadcastTxnsMessage(msg gnet.Message) ([]uint64, error) {
+	ret := _m.Called(msg)
+
+	var r0 []uint64
+	if rf, ok := ret.Get(0).(func(gnet.Message) []uint64); ok {
+		r0 = rf(msg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(gnet.Message) error); ok {
+		r1 = rf(msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// connectionAuthenticated provides a mock function with given fields: addr, gnetID, sig
+func (_m *mockDaemoner) connectionAuthenticated(addr string, gnetID uint64, sig cipher.Sig) error {
+	ret := _m.Called(addr, gnetID, sig)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, uint64, cipher.Sig) error); ok {
+		r0 = rf(addr, gnetID, sig)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // connectionIntroduced provides a mock function with given fields: addr, gnetID, m
 func (_m *mockDaemoner) connectionIntroduced(addr string, gnetID uint64, m *IntroductionMessage) (*connection, error) {
 	ret := _m.Called(addr, gnetID, m)
@@ -102,6 +167,16 @@ func (_m *mockDaemoner) daemonConfig() DaemonConfig {
 	return r0
 }
 
+// detectDoubleSign provides a mock function with given fields: addr, b
+func (_m *mockDaemoner) detectDoubleSign(addr string, b coin.SignedBlock) {
+	_m.Called(addr, b)
+}
+
+// detectTxnConflicts provides a mock function with given fields: addr, txn
+func (_m *mockDaemoner) detectTxnConflicts(addr string, txn coin.Transaction) {
+	_m.Called(addr, txn)
+}
+
 // disconnectNow provides a mock function with given fields: addr, r
 func (_m *mockDaemoner) disconnectNow(addr string, r gnet.DisconnectReason) error {
 	ret := _m.Called(addr, r)
@@ -271,6 +346,11 @@ func (_m *mockDaemoner) pexConfig() pex.Config {
 	return r0
 }
 
+// recordBlocksReceived provides a mock function with given fields: addr, gnetID, failed
+func (_m *mockDaemoner) recordBlocksReceived(addr string, gnetID uint64, failed bool) {
+	_m.Called(addr, gnetID, failed)
+}
+
 // recordMessageEvent provides a mock function with given fields: m, c
 func (_m *mockDaemoner) recordMessageEvent(m asyncMessage, c *gnet.MessageContext) error {
 	ret := _m.Called(m, c)
@@ -290,6 +370,26 @@ func (_m *mockDaemoner) recordPeerHeight(addr string, gnetID uint64, height uint
 	_m.Called(addr, gnetID, height)
 }
 
+// recordTxnReceived provides a mock function with given fields: addr, txid
+func (_m *mockDaemoner) recordTxnReceived(addr string, txid cipher.SHA256) {
+	_m.Called(addr, txid)
+}
+
+// recordTxnRejected provides a mock function with given fields: addr, txid, err, soft
+func (_m *mockDaemoner) recordTxnRejected(addr string, txid cipher.SHA256, err error, soft bool) {
+	_m.Called(addr, txid, err, soft)
+}
+
+// recordTxnsAccepted provides a mock function with given fields: addr, txns
+func (_m *mockDaemoner) recordTxnsAccepted(addr string, txns []cipher.SHA256) {
+	_m.Called(addr, txns)
+}
+
+// recordTxnsRequested provides a mock function with given fields: addr, txids
+func (_m *mockDaemoner) recordTxnsRequested(addr string, txids []cipher.SHA256) {
+	_m.Called(addr, txids)
+}
+
 // requestBlocksFromAddr provides a mock function with given fields: addr
 func (_m *mockDaemoner) requestBlocksFromAddr(addr string) error {
 	ret := _m.Called(addr)
@@ -318,6 +418,20 @@ func (_m *mockDaemoner) sendMessage(addr string, msg gnet.Message) error {
 	return r0
 }
 
+// sendNodeAuth provides a mock function with given fields: addr, challenge
+func (_m *mockDaemoner) sendNodeAuth(addr string, challenge cipher.SHA256) error {
+	ret := _m.Called(addr, challenge)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, cipher.SHA256) error); ok {
+		r0 = rf(addr, challenge)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // sendRandomPeers provides a mock function with given fields: addr
 func (_m *mockDaemoner) sendRandomPeers(addr string) error {
 	ret := _m.Called(addr)
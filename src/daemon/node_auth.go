@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon/pex"
+)
+
+// NodeKeyFilename is the name of the file in the data directory where the node's secret key is saved.
+// The node's pubkey identifies it to peers that configure it as a trusted peer with pubkey@ip:port
+const NodeKeyFilename = "node.key"
+
+// nodeAuthPrefix is prepended to the auth challenge before signing it,
+// so that a node auth signature can't be reused as any other kind of signature
+const nodeAuthPrefix = "skycoin-node-auth:"
+
+// loadNodeKey loads the node's key from the data directory, creating a new key if the key file does not exist.
+// If dataDir is empty, a new key is created and not saved
+func loadNodeKey(dataDir string) (cipher.PubKey, cipher.SecKey, error) {
+	if dataDir == "" {
+		pk, sk := cipher.GenerateKeyPair()
+		return pk, sk, nil
+	}
+
+	fn := filepath.Join(dataDir, NodeKeyFilename)
+
+	b, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		pk, sk := cipher.GenerateKeyPair()
+		if err := ioutil.WriteFile(fn, []byte(sk.Hex()+"\n"), 0600); err != nil {
+			return cipher.PubKey{}, cipher.SecKey{}, fmt.Errorf("save node key file %s failed: %v", fn, err)
+		}
+		return pk, sk, nil
+	} else if err != nil {
+		return cipher.PubKey{}, cipher.SecKey{}, fmt.Errorf("read node key file %s failed: %v", fn, err)
+	}
+
+	sk, err := cipher.SecKeyFromHex(strings.TrimSpace(string(b)))
+	if err != nil {
+		return cipher.PubKey{}, cipher.SecKey{}, fmt.Errorf("invalid node key file %s: %v", fn, err)
+	}
+
+	pk, err := cipher.PubKeyFromSecKey(sk)
+	if err != nil {
+		return cipher.PubKey{}, cipher.SecKey{}, fmt.Errorf("invalid node key file %s: %v", fn, err)
+	}
+
+	return pk, sk, nil
+}
+
+// newAuthChallenge creates a random auth challenge for an IntroductionMessage
+func newAuthChallenge() cipher.SHA256 {
+	return cipher.SumSHA256(cipher.RandByte(32))
+}
+
+// nodeAuthHash returns the hash that is signed in a NodeAuthMessage, in reply to the auth challenge of an IntroductionMessage
+func nodeAuthHash(challenge cipher.SHA256) cipher.SHA256 {
+	return cipher.SumSHA256(append([]byte(nodeAuthPrefix), challenge[:]...))
+}
+
+// VerifyNodeAuth verifies the signature of a NodeAuthMessage sent in reply to the auth challenge of an IntroductionMessage,
+// proving that the peer owns the node pubkey from its own IntroductionMessage
+func VerifyNodeAuth(nodePubKey cipher.PubKey, challenge cipher.SHA256, sig cipher.Sig) error {
+	return cipher.VerifyPubKeySignedHash(nodePubKey, sig, nodeAuthHash(challenge))
+}
+
+// trustedAs returns true if the connection is trusted as the peer p.
+// If the trusted peer is pinned by its node pubkey, the connection must have proven that it owns the pubkey
+func (c ConnectionDetails) trustedAs(p pex.Peer) bool {
+	if !p.Trusted {
+		return false
+	}
+
+	return p.PubKey.Null() || (c.NodeAuthenticated && c.NodePubKey == p.PubKey)
+}
+
+// setAuthChallenge records the auth challenge sent to a connection in our IntroductionMessage
+func (c *Connections) setAuthChallenge(addr string, gnetID uint64, challenge cipher.SHA256) error {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.modify(addr, gnetID, func(c *ConnectionDetails) {
+		c.authChallenge = challenge
+	})
+}
+
+// authenticate verifies a NodeAuthMessage signature from a connection, with the node pubkey from its
+// IntroductionMessage and the auth challenge that was sent to it.
+// Returns ErrDisconnectInvalidNodeAuth if the signature is invalid or the connection did not report a node pubkey
+func (c *Connections) authenticate(addr string, gnetID uint64, sig cipher.Sig) error {
+	c.Lock()
+	defer c.Unlock()
+
+	var authErr error
+	if err := c.modify(addr, gnetID, func(c *ConnectionDetails) {
+		if c.State != ConnectionStateIntroduced || c.NodePubKey.Null() || c.authChallenge.Null() {
+			authErr = ErrDisconnectInvalidNodeAuth
+			return
+		}
+
+		if err := VerifyNodeAuth(c.NodePubKey, c.authChallenge, sig); err != nil {
+			authErr = ErrDisconnectInvalidNodeAuth
+			return
+		}
+
+		c.NodeAuthenticated = true
+	}); err != nil {
+		return err
+	}
+
+	return authErr
+}
@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestLoadNodeKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nodekey")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The key is created if it does not exist
+	pk, sk, err := loadNodeKey(dir)
+	require.NoError(t, err)
+	require.Equal(t, cipher.MustPubKeyFromSecKey(sk), pk)
+
+	fi, err := os.Stat(filepath.Join(dir, NodeKeyFilename))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// The saved key is loaded
+	pk2, sk2, err := loadNodeKey(dir)
+	require.NoError(t, err)
+	require.Equal(t, pk, pk2)
+	require.Equal(t, sk, sk2)
+
+	// An invalid key file is an error
+	err = ioutil.WriteFile(filepath.Join(dir, NodeKeyFilename), []byte("foo"), 0600)
+	require.NoError(t, err)
+	_, _, err = loadNodeKey(dir)
+	require.Error(t, err)
+
+	// Without a data directory, a new key is created each time
+	pk, _, err = loadNodeKey("")
+	require.NoError(t, err)
+	pk2, _, err = loadNodeKey("")
+	require.NoError(t, err)
+	require.NotEqual(t, pk, pk2)
+}
+
+func TestConnectionsAuthenticate(t *testing.T) {
+	conns := NewConnections()
+	addr := "127.0.0.1:6060"
+
+	nodePubKey, nodeSecKey := cipher.GenerateKeyPair()
+	_, otherSecKey := cipher.GenerateKeyPair()
+	challenge := newAuthChallenge()
+
+	_, err := conns.pending(addr)
+	require.NoError(t, err)
+	_, err = conns.connected(addr, 1)
+	require.NoError(t, err)
+	require.NoError(t, conns.setAuthChallenge(addr, 1, challenge))
+
+	// A connection that has not introduced can't authenticate
+	err = conns.authenticate(addr, 1, NewNodeAuthMessage(challenge, nodeSecKey).Sig)
+	require.Equal(t, ErrDisconnectInvalidNodeAuth, err)
+
+	_, err = conns.introduced(addr, 1, &IntroductionMessage{
+		Mirror:     1,
+		ListenPort: 6060,
+		nodePubKey: nodePubKey,
+	})
+	require.NoError(t, err)
+
+	c := conns.get(addr)
+	require.Equal(t, nodePubKey, c.NodePubKey)
+	require.False(t, c.NodeAuthenticated)
+
+	err = conns.authenticate(addr, 2, NewNodeAuthMessage(challenge, nodeSecKey).Sig)
+	require.Equal(t, ErrConnectionGnetIDMismatch, err)
+
+	// Signed by another key
+	err = conns.authenticate(addr, 1, NewNodeAuthMessage(challenge, otherSecKey).Sig)
+	require.Equal(t, ErrDisconnectInvalidNodeAuth, err)
+
+	// Signed a different challenge
+	err = conns.authenticate(addr, 1, NewNodeAuthMessage(newAuthChallenge(), nodeSecKey).Sig)
+	require.Equal(t, ErrDisconnectInvalidNodeAuth, err)
+
+	require.False(t, conns.get(addr).NodeAuthenticated)
+
+	err = conns.authenticate(addr, 1, NewNodeAuthMessage(challenge, nodeSecKey).Sig)
+	require.NoError(t, err)
+	require.True(t, conns.get(addr).NodeAuthenticated)
+
+	// A connection that did not report a node pubkey can't authenticate
+	addr2 := "127.0.0.1:6061"
+	_, err = conns.connected(addr2, 2)
+	require.NoError(t, err)
+	require.NoError(t, conns.setAuthChallenge(addr2, 2, challenge))
+	_, err = conns.introduced(addr2, 2, &IntroductionMessage{
+		Mirror:     2,
+		ListenPort: 6061,
+	})
+	require.NoError(t, err)
+
+	err = conns.authenticate(addr2, 2, NewNodeAuthMessage(challenge, nodeSecKey).Sig)
+	require.Equal(t, ErrDisconnectInvalidNodeAuth, err)
+}
@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// rttSmoothingFactor is the weight of a new sample in the smoothed RTT and block latency,
+	// the same factor used by TCP for its smoothed RTT
+	rttSmoothingFactor = 0.125
+	// pingTimeout is how long to wait for a pong before a new ping is matched instead
+	pingTimeout = time.Minute
+	// referenceRTT is the RTT that scores half of the RTT component of a connection's quality
+	referenceRTT = time.Millisecond * 500
+	// referenceBlocksLatency is the block delivery latency that scores half of the
+	// block delivery component of a connection's quality
+	referenceBlocksLatency = time.Second * 2
+)
+
+// smoothDuration applies an exponentially weighted moving average to a duration sample
+func smoothDuration(avg, sample time.Duration) time.Duration {
+	if avg == 0 {
+		return sample
+	}
+
+	return time.Duration((1-rttSmoothingFactor)*float64(avg) + rttSmoothingFactor*float64(sample))
+}
+
+// latencyScore maps a latency to a score in (0, 1], where ref scores 0.5
+func latencyScore(latency, ref time.Duration) float64 {
+	if latency <= 0 {
+		return 1
+	}
+
+	return float64(ref) / float64(ref+latency)
+}
+
+// quality returns a quality score for the connection in [0, 1], computed from its RTT,
+// block delivery latency and block request failure rate.
+// Returns false if nothing has been measured for the connection yet
+func (c ConnectionDetails) quality() (float64, bool) {
+	var scores []float64
+	if c.RTT > 0 {
+		scores = append(scores, latencyScore(c.RTT, referenceRTT))
+	}
+	if c.BlocksLatency > 0 {
+		scores = append(scores, latencyScore(c.BlocksLatency, referenceBlocksLatency))
+	}
+
+	requests := c.BlocksResponses + c.BlocksFailures
+	if len(scores) == 0 && requests == 0 {
+		return 0, false
+	}
+
+	q := 1.0
+	if len(scores) != 0 {
+		q = 0
+		for _, s := range scores {
+			q += s
+		}
+		q /= float64(len(scores))
+	}
+
+	if requests != 0 {
+		q *= 1 - float64(c.BlocksFailures)/float64(requests)
+	}
+
+	return q, true
+}
+
+// peerAddr returns the address of the connection's peer in the peer list
+func (c *connection) peerAddr() string {
+	if c.Outgoing {
+		return c.Addr
+	}
+	return c.ListenAddr()
+}
+
+// sortConnectionsByQuality sorts connections by quality, highest first.
+// peerQuality returns the quality of a connection that has not been measured yet
+func sortConnectionsByQuality(conns []connection, peerQuality func(c connection) float64) {
+	qualities := make(map[string]float64, len(conns))
+	for _, c := range conns {
+		q, ok := c.quality()
+		if !ok {
+			q = peerQuality(c)
+		}
+		qualities[c.Addr] = q
+	}
+
+	sort.SliceStable(conns, func(i, j int) bool {
+		return qualities[conns[i].Addr] > qualities[conns[j].Addr]
+	})
+}
@@ -0,0 +1,115 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmoothDuration(t *testing.T) {
+	require.Equal(t, time.Second, smoothDuration(0, time.Second))
+	require.Equal(t, time.Millisecond*1125, smoothDuration(time.Second, time.Second*2))
+}
+
+func TestConnectionDetailsQuality(t *testing.T) {
+	cases := []struct {
+		name    string
+		details ConnectionDetails
+		quality float64
+		ok      bool
+	}{
+		{
+			name: "not measured",
+		},
+		{
+			name: "rtt only",
+			details: ConnectionDetails{
+				RTT: referenceRTT,
+			},
+			quality: 0.5,
+			ok:      true,
+		},
+		{
+			name: "rtt and blocks latency",
+			details: ConnectionDetails{
+				RTT:             referenceRTT,
+				BlocksLatency:   referenceBlocksLatency * 3,
+				BlocksResponses: 1,
+			},
+			quality: 0.375,
+			ok:      true,
+		},
+		{
+			name: "failures",
+			details: ConnectionDetails{
+				RTT:             referenceRTT,
+				BlocksLatency:   referenceBlocksLatency,
+				BlocksResponses: 3,
+				BlocksFailures:  1,
+			},
+			quality: 0.375,
+			ok:      true,
+		},
+		{
+			name: "failures only",
+			details: ConnectionDetails{
+				BlocksFailures: 2,
+			},
+			quality: 0,
+			ok:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, ok := tc.details.quality()
+			require.Equal(t, tc.ok, ok)
+			require.InDelta(t, tc.quality, q, 1e-9)
+		})
+	}
+}
+
+func TestSortConnectionsByQuality(t *testing.T) {
+	conns := []connection{
+		{
+			Addr: "127.0.0.1:6000",
+		},
+		{
+			Addr: "127.0.0.1:6001",
+			ConnectionDetails: ConnectionDetails{
+				RTT: referenceRTT * 3,
+			},
+		},
+		{
+			Addr: "127.0.0.1:6002",
+			ConnectionDetails: ConnectionDetails{
+				RTT: referenceRTT / 4,
+			},
+		},
+		{
+			Addr: "127.0.0.1:6003",
+		},
+	}
+
+	peerQualities := map[string]float64{
+		"127.0.0.1:6000": 0.5,
+		"127.0.0.1:6003": 0.9,
+	}
+
+	sortConnectionsByQuality(conns, func(c connection) float64 {
+		return peerQualities[c.Addr]
+	})
+
+	var addrs []string
+	for _, c := range conns {
+		addrs = append(addrs, c.Addr)
+	}
+
+	require.Equal(t, []string{
+		"127.0.0.1:6003",
+		"127.0.0.1:6002",
+		"127.0.0.1:6000",
+		"127.0.0.1:6001",
+	}, addrs)
+}
@@ -0,0 +1,179 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+
+	"github.com/skycoin/skycoin/src/daemon/pex"
+	"github.com/skycoin/skycoin/src/util/useragent"
+)
+
+// versionRange is a semver range of user agent versions, optionally restricted to a coin name
+type versionRange struct {
+	coin string // if empty, the range applies to all coins
+	rng  semver.Range
+}
+
+// parseVersionRange parses a version range in the format "[$COIN:]$RANGE",
+// where $RANGE is a semver range such as ">=0.24.0 <0.25.1" or "0.24.0 || 0.24.1"
+func parseVersionRange(s string) (versionRange, error) {
+	var coin string
+	r := s
+	if i := strings.Index(s, ":"); i != -1 {
+		coin = strings.TrimSpace(s[:i])
+		r = s[i+1:]
+		if coin == "" {
+			return versionRange{}, fmt.Errorf("Invalid version range %q: missing coin name", s)
+		}
+	}
+
+	rng, err := semver.ParseRange(strings.TrimSpace(r))
+	if err != nil {
+		return versionRange{}, fmt.Errorf("Invalid version range %q: %v", s, err)
+	}
+
+	return versionRange{
+		coin: coin,
+		rng:  rng,
+	}, nil
+}
+
+// match returns true if the user agent's coin and version are in the range
+func (r versionRange) match(ua useragent.Data, v semver.Version) bool {
+	if r.coin != "" && r.coin != ua.Coin {
+		return false
+	}
+	return r.rng(v)
+}
+
+// peerVersionPolicy decides which peers are accepted and preferred, based on the user agent in their introduction
+type peerVersionPolicy struct {
+	// Minimum accepted version, by coin name
+	minVersions map[string]semver.Version
+	// Versions to prefer when making outgoing connections
+	preferred []versionRange
+	// Versions that are known to be buggy, which are disconnected
+	buggy []versionRange
+}
+
+// newPeerVersionPolicy creates a peerVersionPolicy.
+// minVersions are in the format "$COIN:$VERSION", e.g. "skycoin:0.25.0".
+// preferred and buggy are version ranges in the format "[$COIN:]$RANGE", e.g. "skycoin:>=0.24.0 <0.24.2"
+func newPeerVersionPolicy(minVersions, preferred, buggy []string) (peerVersionPolicy, error) {
+	p := peerVersionPolicy{
+		minVersions: make(map[string]semver.Version, len(minVersions)),
+	}
+
+	for _, s := range minVersions {
+		i := strings.Index(s, ":")
+		if i == -1 {
+			return peerVersionPolicy{}, fmt.Errorf("Invalid minimum peer version %q: must be in the format $COIN:$VERSION", s)
+		}
+
+		coin := strings.TrimSpace(s[:i])
+		if coin == "" {
+			return peerVersionPolicy{}, fmt.Errorf("Invalid minimum peer version %q: missing coin name", s)
+		}
+
+		v, err := semver.Parse(strings.TrimSpace(s[i+1:]))
+		if err != nil {
+			return peerVersionPolicy{}, fmt.Errorf("Invalid minimum peer version %q: %v", s, err)
+		}
+
+		if _, ok := p.minVersions[coin]; ok {
+			return peerVersionPolicy{}, fmt.Errorf("Duplicate minimum peer version for coin %q", coin)
+		}
+
+		p.minVersions[coin] = v
+	}
+
+	for _, s := range preferred {
+		r, err := parseVersionRange(s)
+		if err != nil {
+			return peerVersionPolicy{}, err
+		}
+		p.preferred = append(p.preferred, r)
+	}
+
+	for _, s := range buggy {
+		r, err := parseVersionRange(s)
+		if err != nil {
+			return peerVersionPolicy{}, err
+		}
+		p.buggy = append(p.buggy, r)
+	}
+
+	return p, nil
+}
+
+// check returns a DisconnectReason if a peer with this user agent should not be accepted.
+// Peers that do not send a user agent are accepted, since older peers do not send one
+func (p peerVersionPolicy) check(ua useragent.Data) error {
+	if ua.Empty() {
+		return nil
+	}
+
+	v, err := semver.Parse(ua.Version)
+	if err != nil {
+		// A parsed user agent always has a valid version, so this should not occur.
+		// Treat it as below the minimum if there is one for the coin
+		if _, ok := p.minVersions[ua.Coin]; ok {
+			return ErrDisconnectPeerVersionTooLow
+		}
+		return nil
+	}
+
+	if minVersion, ok := p.minVersions[ua.Coin]; ok && v.LT(minVersion) {
+		return ErrDisconnectPeerVersionTooLow
+	}
+
+	for _, r := range p.buggy {
+		if r.match(ua, v) {
+			return ErrDisconnectBuggyPeerVersion
+		}
+	}
+
+	return nil
+}
+
+// hasPreferred returns true if preferred versions are configured
+func (p peerVersionPolicy) hasPreferred() bool {
+	return len(p.preferred) != 0
+}
+
+// isPreferred returns true if the user agent's version is in one of the preferred ranges
+func (p peerVersionPolicy) isPreferred(ua useragent.Data) bool {
+	if ua.Empty() {
+		return false
+	}
+
+	v, err := semver.Parse(ua.Version)
+	if err != nil {
+		return false
+	}
+
+	for _, r := range p.preferred {
+		if r.match(ua, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// preferPeers reorders peers so that peers last seen with a preferred version come first.
+// The order is otherwise unchanged
+func (p peerVersionPolicy) preferPeers(peers pex.Peers) pex.Peers {
+	sorted := make(pex.Peers, 0, len(peers))
+	var others pex.Peers
+	for _, peer := range peers {
+		if p.isPreferred(peer.UserAgent) {
+			sorted = append(sorted, peer)
+		} else {
+			others = append(others, peer)
+		}
+	}
+	return append(sorted, others...)
+}
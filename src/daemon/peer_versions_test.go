@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/daemon/pex"
+	"github.com/skycoin/skycoin/src/util/useragent"
+)
+
+func mustNewPeerVersionPolicy(t *testing.T, minVersions, preferred, buggy []string) peerVersionPolicy {
+	p, err := newPeerVersionPolicy(minVersions, preferred, buggy)
+	require.NoError(t, err)
+	return p
+}
+
+func TestNewPeerVersionPolicy(t *testing.T) {
+	cases := []struct {
+		name        string
+		minVersions []string
+		preferred   []string
+		buggy       []string
+		err         string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:        "valid",
+			minVersions: []string{"skycoin:0.25.0", " mdl : 0.24.1 "},
+			preferred:   []string{">=0.25.0", "skycoin:0.25.1 || >=0.26.0"},
+			buggy:       []string{"skycoin:>=0.24.0 <0.24.2"},
+		},
+		{
+			name:        "min version missing coin",
+			minVersions: []string{"0.25.0"},
+			err:         `Invalid minimum peer version "0.25.0": must be in the format $COIN:$VERSION`,
+		},
+		{
+			name:        "min version empty coin",
+			minVersions: []string{":0.25.0"},
+			err:         `Invalid minimum peer version ":0.25.0": missing coin name`,
+		},
+		{
+			name:        "min version invalid version",
+			minVersions: []string{"skycoin:0.25"},
+			err:         `Invalid minimum peer version "skycoin:0.25": No Major.Minor.Patch elements found`,
+		},
+		{
+			name:        "min version duplicate coin",
+			minVersions: []string{"skycoin:0.25.0", "skycoin:0.24.0"},
+			err:         `Duplicate minimum peer version for coin "skycoin"`,
+		},
+		{
+			name:      "preferred invalid range",
+			preferred: []string{"skycoin:>=foo"},
+			err:       `Invalid version range "skycoin:>=foo": Could not get version from string: ">=foo"`,
+		},
+		{
+			name:  "buggy empty coin",
+			buggy: []string{":0.24.0"},
+			err:   `Invalid version range ":0.24.0": missing coin name`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newPeerVersionPolicy(tc.minVersions, tc.preferred, tc.buggy)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Equal(t, tc.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestPeerVersionPolicyCheck(t *testing.T) {
+	p := mustNewPeerVersionPolicy(t, []string{"skycoin:0.24.0"}, nil, []string{"skycoin:>=0.24.1 <0.24.3", "0.20.0"})
+
+	cases := []struct {
+		userAgent string
+		err       error
+	}{
+		{"", nil},
+		{"skycoin:0.23.9", ErrDisconnectPeerVersionTooLow},
+		{"skycoin:0.24.0", nil},
+		{"skycoin:0.24.1(foo)", ErrDisconnectBuggyPeerVersion},
+		{"skycoin:0.24.2", ErrDisconnectBuggyPeerVersion},
+		{"skycoin:0.24.3", nil},
+		{"skycoin:0.25.0", nil},
+		// No minimum version for other coins, and the range without a coin applies to all coins
+		{"mdl:0.1.0", nil},
+		{"mdl:0.24.1", nil},
+		{"mdl:0.20.0", ErrDisconnectBuggyPeerVersion},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.userAgent, func(t *testing.T) {
+			var ua useragent.Data
+			if tc.userAgent != "" {
+				ua = useragent.MustParse(tc.userAgent)
+			}
+			require.Equal(t, tc.err, p.check(ua))
+		})
+	}
+
+	// Nothing is rejected without a policy
+	require.NoError(t, peerVersionPolicy{}.check(useragent.MustParse("skycoin:0.1.0")))
+}
+
+func TestPeerVersionPolicyPreferPeers(t *testing.T) {
+	p := mustNewPeerVersionPolicy(t, nil, []string{"skycoin:>=0.25.0"}, nil)
+	require.True(t, p.hasPreferred())
+	require.False(t, peerVersionPolicy{}.hasPreferred())
+
+	require.True(t, p.isPreferred(useragent.MustParse("skycoin:0.25.1")))
+	require.False(t, p.isPreferred(useragent.MustParse("skycoin:0.24.1")))
+	require.False(t, p.isPreferred(useragent.MustParse("mdl:0.25.1")))
+	require.False(t, p.isPreferred(useragent.Data{}))
+
+	peers := pex.Peers{
+		{Addr: "1.1.1.1:6000"},
+		{Addr: "2.2.2.2:6000", UserAgent: useragent.MustParse("skycoin:0.25.0")},
+		{Addr: "3.3.3.3:6000", UserAgent: useragent.MustParse("skycoin:0.24.1")},
+		{Addr: "4.4.4.4:6000", UserAgent: useragent.MustParse("skycoin:0.26.0")},
+	}
+
+	var addrs []string
+	for _, p := range p.preferPeers(peers) {
+		addrs = append(addrs, p.Addr)
+	}
+
+	require.Equal(t, []string{"2.2.2.2:6000", "4.4.4.4:6000", "1.1.1.1:6000", "3.3.3.3:6000"}, addrs)
+}
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/util/file"
 	"github.com/skycoin/skycoin/src/util/useragent"
 )
@@ -209,6 +212,16 @@ func (pl *peerlist) setTrusted(addr string, trusted bool) error {
 	return fmt.Errorf("set peer.Trusted failed: %v does not exist in peer list", addr)
 }
 
+// setPubKey pins the node pubkey of a peer
+func (pl *peerlist) setPubKey(addr string, pubkey cipher.PubKey) error {
+	if p, ok := pl.peers[addr]; ok {
+		p.PubKey = pubkey
+		return nil
+	}
+
+	return fmt.Errorf("set peer.PubKey failed: %v does not exist in peer list", addr)
+}
+
 // setAllUntrusted unsets the trusted field on all peers
 func (pl *peerlist) setAllUntrusted() {
 	for _, p := range pl.peers {
@@ -238,6 +251,16 @@ func (pl *peerlist) setUserAgent(addr string, userAgent useragent.Data) error {
 	return fmt.Errorf("set peer.UserAgent failed: %v does not exist in peer list", addr)
 }
 
+// recordQuality records a quality sample for a peer
+func (pl *peerlist) recordQuality(addr string, sample float64) error {
+	if p, ok := pl.peers[addr]; ok {
+		p.RecordQuality(sample)
+		return nil
+	}
+
+	return fmt.Errorf("set peer.Quality failed: %v does not exist in peer list", addr)
+}
+
 // len returns number of peers
 func (pl *peerlist) len() int {
 	return len(pl.peers)
@@ -284,6 +307,34 @@ func (pl *peerlist) random(count int, flts []Filter) Peers {
 	return ps
 }
 
+// Returns n random peers, or all of the peers, whichever is lower.
+// Peers are chosen with a probability proportional to their quality score, and are ordered by selection.
+// If count is 0, all of the peers are returned.
+func (pl *peerlist) weightedRandom(count int, flts []Filter) Peers {
+	ps := pl.getCanTryPeers(flts)
+	if len(ps) == 0 {
+		return Peers{}
+	}
+
+	max := count
+	if max == 0 || max > len(ps) {
+		max = len(ps)
+	}
+
+	// Weighted random sampling without replacement (Efraimidis and Spirakis):
+	// each peer gets the key u^(1/w) for a uniform random u, and the peers with the largest keys are chosen
+	keys := make(map[string]float64, len(ps))
+	for _, p := range ps {
+		keys[p.Addr] = math.Pow(rand.Float64(), 1/p.QualityScore())
+	}
+
+	sort.Slice(ps, func(i, j int) bool {
+		return keys[ps[i].Addr] > keys[ps[j].Addr]
+	})
+
+	return ps[:max]
+}
+
 // save saves known peers to disk as a newline delimited list of addresses to
 // <dir><PeerCacheFilename>
 func (pl *peerlist) save(fn string) error {
@@ -358,6 +409,7 @@ type PeerJSON struct {
 	HasIncomePort   *bool `json:"HasIncomePort,omitempty"` // Whether this peer has incoming port [DEPRECATED]
 	HasIncomingPort *bool // Whether this peer has incoming port
 	UserAgent       useragent.Data
+	Quality         float64 `json:",omitempty"` // Connection quality score, 0 if it was never measured
 }
 
 // newPeerJSON returns a PeerJSON from a Peer
@@ -369,6 +421,7 @@ func newPeerJSON(p Peer) PeerJSON {
 		Trusted:         p.Trusted,
 		HasIncomingPort: &p.HasIncomingPort,
 		UserAgent:       p.UserAgent,
+		Quality:         p.Quality,
 	}
 }
 
@@ -406,6 +459,11 @@ func newPeerFromJSON(p PeerJSON) (*Peer, error) {
 		return nil, err
 	}
 
+	quality := p.Quality
+	if quality < 0 || quality > 1 {
+		return nil, fmt.Errorf("Invalid Quality %v, must be between 0 and 1", quality)
+	}
+
 	return &Peer{
 		Addr:            addr,
 		LastSeen:        lastSeen,
@@ -413,5 +471,6 @@ func newPeerFromJSON(p PeerJSON) (*Peer, error) {
 		Trusted:         p.Trusted,
 		HasIncomingPort: hasIncomingPort,
 		UserAgent:       p.UserAgent,
+		Quality:         quality,
 	}, nil
 }
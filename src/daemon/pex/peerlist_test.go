@@ -493,6 +493,15 @@ func TestPeerlistSave(t *testing.T) {
 				testPeers[1]: {Addr: testPeers[1]},
 			},
 		},
+		{
+			"save quality",
+			[]Peer{
+				{Addr: testPeers[0], Quality: 0.75},
+			},
+			map[string]Peer{
+				testPeers[0]: {Addr: testPeers[0], Quality: 0.75},
+			},
+		},
 		{
 			"save one peer",
 			[]Peer{
@@ -592,6 +601,29 @@ func TestPeerJSONParsing(t *testing.T) {
 	p, err = newPeerFromJSON(pj)
 	require.NoError(t, err)
 	check(p)
+	require.Equal(t, 0.0, p.Quality)
+	require.Equal(t, DefaultPeerQuality, p.QualityScore())
+
+	pj = load(`{
+        "Addr": "11.22.33.44:6000",
+        "LastSeen": 1506235338,
+        "Private": true,
+        "Trusted": true,
+        "HasIncomingPort": true,
+        "Quality": 0.25
+    }`)
+	p, err = newPeerFromJSON(pj)
+	require.NoError(t, err)
+	check(p)
+	require.Equal(t, 0.25, p.Quality)
+
+	pj = load(`{
+        "Addr": "11.22.33.44:6000",
+        "LastSeen": 1506235338,
+        "Quality": 1.5
+    }`)
+	_, err = newPeerFromJSON(pj)
+	require.Error(t, err)
 }
 
 func peersEqualWithSeenAllowedDiff(t *testing.T, expected Peer, actual Peer) {
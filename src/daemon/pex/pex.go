@@ -20,6 +20,7 @@ import (
 	"github.com/cenkalti/backoff"
 	"github.com/sirupsen/logrus"
 
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/util/logging"
 	"github.com/skycoin/skycoin/src/util/useragent"
 )
@@ -40,6 +41,16 @@ const (
 	oldPeerCacheFilename = "peers.txt"
 	// MaxPeerRetryTimes is the maximum number of times to retry a peer
 	MaxPeerRetryTimes = 10
+	// DefaultPeerQuality is the quality score of a peer whose quality was never measured
+	DefaultPeerQuality = 0.5
+	// MinPeerQuality is the lowest quality score a peer can have, so that poor peers can still be selected occasionally
+	MinPeerQuality = 0.01
+	// peerQualitySmoothingFactor is the weight of a new sample in a peer's quality score
+	peerQualitySmoothingFactor = 0.2
+	// PeerListSignatureSuffix is appended to the peers list URL to get the URL of its detached signature
+	PeerListSignatureSuffix = ".sig"
+	// DefaultPeerListMaxAge is the default maximum time since a signed peers list was signed for it to be used
+	DefaultPeerListMaxAge = time.Hour * 24 * 30
 )
 
 var (
@@ -55,6 +66,12 @@ var (
 	ErrPortTooLow = errors.New("Port must be >= 1024")
 	// ErrBlacklistedAddress returned when attempting to add a blacklisted peer
 	ErrBlacklistedAddress = errors.New("Blacklisted address")
+	// ErrInvalidPubKey is returned when the pubkey of a pubkey@ip:port trusted peer is malformed
+	ErrInvalidPubKey = errors.New("Invalid trusted peer pubkey")
+	// ErrInvalidPeerListSignature is returned when a downloaded peers list does not have a valid signature
+	ErrInvalidPeerListSignature = errors.New("Invalid peers list signature")
+	// ErrPeerListExpired is returned when a downloaded peers list was signed too long ago
+	ErrPeerListExpired = errors.New("Peers list signature is expired")
 
 	// Logging. See http://godoc.org/github.com/op/go-logging for
 	// instructions on how to include this log's output
@@ -96,6 +113,26 @@ func validateAddress(ipPort string, allowLocalhost bool) (string, error) {
 	return ipPort, nil
 }
 
+// ParseTrustedPeer parses a trusted peer of the form ip:port or pubkey@ip:port.
+// pubkey is the hex encoded node pubkey that the peer must prove it owns when connecting.
+// The returned pubkey is null if the peer does not pin a pubkey.
+// The address is not validated
+func ParseTrustedPeer(s string) (string, cipher.PubKey, error) {
+	pts := strings.Split(s, "@")
+	switch len(pts) {
+	case 1:
+		return s, cipher.PubKey{}, nil
+	case 2:
+		pubkey, err := cipher.PubKeyFromHex(pts[0])
+		if err != nil {
+			return "", cipher.PubKey{}, ErrInvalidPubKey
+		}
+		return pts[1], pubkey, nil
+	default:
+		return "", cipher.PubKey{}, ErrInvalidAddress
+	}
+}
+
 // Peer represents a known peer
 type Peer struct {
 	Addr            string         // An address of the form ip:port
@@ -104,6 +141,8 @@ type Peer struct {
 	Trusted         bool           // Whether this peer is trusted
 	HasIncomingPort bool           // Whether this peer has accessible public port
 	UserAgent       useragent.Data // Peer's last reported user agent
+	Quality         float64        // Connection quality score in (0, 1], 0 if it was never measured
+	PubKey          cipher.PubKey  `json:"-"` // Pinned node pubkey of a trusted peer, from DefaultConnections
 	RetryTimes      int            `json:"-"` // records the retry times
 }
 
@@ -151,6 +190,31 @@ func (peer *Peer) CanTry() bool {
 	return now-peer.LastSeen > t
 }
 
+// QualityScore returns the peer's quality score, or DefaultPeerQuality if it was never measured
+func (peer *Peer) QualityScore() float64 {
+	if peer.Quality == 0 {
+		return DefaultPeerQuality
+	}
+	return peer.Quality
+}
+
+// RecordQuality updates the peer's quality score with a new sample.
+// The score is an exponentially weighted moving average of the samples
+func (peer *Peer) RecordQuality(sample float64) {
+	if sample < MinPeerQuality {
+		sample = MinPeerQuality
+	} else if sample > 1 {
+		sample = 1
+	}
+
+	if peer.Quality == 0 {
+		peer.Quality = sample
+		return
+	}
+
+	peer.Quality = (1-peerQualitySmoothingFactor)*peer.Quality + peerQualitySmoothingFactor*sample
+}
+
 // String returns the peer address
 func (peer *Peer) String() string {
 	return peer.Addr
@@ -184,11 +248,17 @@ type Config struct {
 	DownloadPeerList bool
 	// Download peers list from this URL
 	PeerListURL string
+	// If set, a downloaded peers list is only used if its detached signature,
+	// downloaded from PeerListURL + PeerListSignatureSuffix, was made by this pubkey
+	PeerListPubKey cipher.PubKey
+	// A signed peers list is only used if it was signed less than this long ago
+	PeerListMaxAge time.Duration
 	// Set all peers as untrusted (even if loaded from DefaultConnections)
 	DisableTrustedPeers bool
 	// Load peers from this file on disk. NOTE: this is different from the peers file cache in the data directory
 	CustomPeersFile string
-	// Default "trusted" connections
+	// Default "trusted" connections, of the form ip:port or pubkey@ip:port.
+	// If a pubkey is given, the peer must prove that it owns the pubkey when connecting to be treated as trusted
 	DefaultConnections []string
 }
 
@@ -208,6 +278,7 @@ func NewConfig() Config {
 		NetworkDisabled:     false,
 		DownloadPeerList:    false,
 		PeerListURL:         DefaultPeerListURL,
+		PeerListMaxAge:      DefaultPeerListMaxAge,
 		DisableTrustedPeers: false,
 		CustomPeersFile:     "",
 	}
@@ -239,7 +310,13 @@ func New(cfg Config) (*Pex, error) {
 	}
 
 	// Load default hardcoded peers
-	for _, addr := range cfg.DefaultConnections {
+	for _, conn := range cfg.DefaultConnections {
+		addr, pubkey, err := ParseTrustedPeer(conn)
+		if err != nil {
+			logger.Critical().WithError(err).WithField("addr", conn).Error("Invalid default peer")
+			return nil, err
+		}
+
 		// Default peers will mark as trusted peers.
 		if err := pex.AddPeer(addr); err != nil {
 			logger.Critical().WithError(err).Error("Add default peer failed")
@@ -249,6 +326,10 @@ func New(cfg Config) (*Pex, error) {
 			logger.Critical().WithError(err).Error("pex.SetTrusted for default peer failed")
 			return nil, err
 		}
+		if err := pex.setPubKey(addr, pubkey); err != nil {
+			logger.Critical().WithError(err).Error("pex.setPubKey for default peer failed")
+			return nil, err
+		}
 	}
 
 	if cfg.DisableTrustedPeers {
@@ -329,6 +410,20 @@ func (px *Pex) downloadPeers() error {
 		return err
 	}
 
+	if !px.Config.PeerListPubKey.Null() {
+		sigURL := px.Config.PeerListURL + PeerListSignatureSuffix
+		sig, err := backoffDownloadText(sigURL)
+		if err != nil {
+			logger.WithError(err).WithField("url", sigURL).Error("Failed to download peers list signature")
+			return err
+		}
+
+		if err := VerifyPeerList([]byte(body), sig, px.Config.PeerListPubKey, time.Now(), px.Config.PeerListMaxAge); err != nil {
+			logger.WithError(err).WithField("url", px.Config.PeerListURL).Error("Refusing peers list without a valid, unexpired signature")
+			return err
+		}
+	}
+
 	peers := parseRemotePeerList(body)
 	logger.WithField("url", px.Config.PeerListURL).Infof("Downloaded peers list, got %d peers", len(peers))
 
@@ -410,7 +505,12 @@ func (px *Pex) loadCustom(fn string) error {
 	return nil
 }
 
-// SavePeers persists the peerlist
+// Save persists the peerlist
+func (px *Pex) Save() error {
+	return px.save()
+}
+
+// save persists the peerlist
 func (px *Pex) save() error {
 	px.Lock()
 	defer px.Unlock()
@@ -526,6 +626,34 @@ func (px *Pex) SetTrusted(addr string) error {
 	return px.peerlist.setTrusted(cleanAddr, true)
 }
 
+// setPubKey pins the node pubkey of a peer
+func (px *Pex) setPubKey(addr string, pubkey cipher.PubKey) error {
+	px.Lock()
+	defer px.Unlock()
+
+	cleanAddr, err := validateAddress(addr, px.Config.AllowLocalhost)
+	if err != nil {
+		logger.WithError(err).WithField("addr", addr).Error("Invalid address")
+		return ErrInvalidAddress
+	}
+
+	return px.peerlist.setPubKey(cleanAddr, pubkey)
+}
+
+// SetUntrusted unsets peer's trusted value
+func (px *Pex) SetUntrusted(addr string) error {
+	px.Lock()
+	defer px.Unlock()
+
+	cleanAddr, err := validateAddress(addr, px.Config.AllowLocalhost)
+	if err != nil {
+		logger.WithError(err).WithField("addr", addr).Error("Invalid address")
+		return ErrInvalidAddress
+	}
+
+	return px.peerlist.setTrusted(cleanAddr, false)
+}
+
 // setAllUntrusted unsets the trusted field on all peers
 func (px *Pex) setAllUntrusted() {
 	px.Lock()
@@ -568,6 +696,20 @@ func (px *Pex) SetUserAgent(addr string, userAgent useragent.Data) error {
 	return px.peerlist.setUserAgent(cleanAddr, userAgent)
 }
 
+// RecordQuality records a quality sample for the peer
+func (px *Pex) RecordQuality(addr string, sample float64) error {
+	px.Lock()
+	defer px.Unlock()
+
+	cleanAddr, err := validateAddress(addr, px.Config.AllowLocalhost)
+	if err != nil {
+		logger.WithError(err).WithField("addr", addr).Error("Invalid address")
+		return ErrInvalidAddress
+	}
+
+	return px.peerlist.recordQuality(cleanAddr, sample)
+}
+
 // RemovePeer removes peer
 func (px *Pex) RemovePeer(addr string) {
 	px.Lock()
@@ -612,6 +754,13 @@ func (px *Pex) RandomPublic(n int) Peers {
 	}})
 }
 
+// BestPublic returns N random public peers, chosen with a probability proportional to their quality score
+func (px *Pex) BestPublic(n int) Peers {
+	px.RLock()
+	defer px.RUnlock()
+	return px.peerlist.weightedRandom(n, []Filter{isPublic})
+}
+
 // RandomExchangeable returns N random exchangeable peers
 func (px *Pex) RandomExchangeable(n int) Peers {
 	px.RLock()
@@ -695,6 +844,57 @@ func backoffDownloadText(url string) (string, error) {
 	return body, nil
 }
 
+// SignPeerList signs a peers list file with a secret key, at the time signedAt.
+// Returns the content of the detached signature file, which is published next to the peers list,
+// named with PeerListSignatureSuffix. It holds the unix time of signing and the hex encoded signature,
+// separated by a space. The signing time is covered by the signature, so that a stale
+// peers list can't be passed off as a recent one
+func SignPeerList(body []byte, seckey cipher.SecKey, signedAt time.Time) (string, error) {
+	timestamp := signedAt.Unix()
+	sig, err := cipher.SignHash(peerListHash(body, timestamp), seckey)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d %s\n", timestamp, sig.Hex()), nil
+}
+
+// VerifyPeerList verifies the detached signature file of a peers list file, as created by SignPeerList.
+// Returns ErrInvalidPeerListSignature if the signature is malformed or was not made by pubkey,
+// and ErrPeerListExpired if the peers list was signed more than maxAge before now.
+// A maxAge of 0 disables the expiry check
+func VerifyPeerList(body []byte, sigFile string, pubkey cipher.PubKey, now time.Time, maxAge time.Duration) error {
+	fields := strings.Fields(sigFile)
+	if len(fields) != 2 {
+		return ErrInvalidPeerListSignature
+	}
+
+	timestamp, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return ErrInvalidPeerListSignature
+	}
+
+	sig, err := cipher.SigFromHex(fields[1])
+	if err != nil {
+		return ErrInvalidPeerListSignature
+	}
+
+	if err := cipher.VerifyPubKeySignedHash(pubkey, sig, peerListHash(body, timestamp)); err != nil {
+		return ErrInvalidPeerListSignature
+	}
+
+	if maxAge != 0 && now.Sub(time.Unix(timestamp, 0)) > maxAge {
+		return ErrPeerListExpired
+	}
+
+	return nil
+}
+
+// peerListHash is the hash signed by a peers list signature, of the signing time followed by the peers list
+func peerListHash(body []byte, timestamp int64) cipher.SHA256 {
+	return cipher.SumSHA256(append([]byte(fmt.Sprintf("%d\n", timestamp)), body...))
+}
+
 // parseRemotePeerList parses a remote peers.txt file
 // The peers list format is newline separated list of ip:port strings
 // Any lines that don't parse to an ip:port are skipped, otherwise they return an error
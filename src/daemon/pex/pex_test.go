@@ -3,13 +3,17 @@ package pex
 import (
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/util/file"
 )
 
@@ -182,6 +186,86 @@ func TestNewPex(t *testing.T) {
 	}
 }
 
+func TestNewPexPinnedPubKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peerlist")
+	require.NoError(t, err)
+	defer os.Remove(dir)
+
+	pubkey := cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a")
+
+	config := NewConfig()
+	config.DataDirectory = dir
+	config.DefaultConnections = []string{
+		pubkey.Hex() + "@" + testPeers[0],
+		testPeers[1],
+	}
+
+	px, err := New(config)
+	require.NoError(t, err)
+
+	p, ok := px.GetPeer(testPeers[0])
+	require.True(t, ok)
+	require.True(t, p.Trusted)
+	require.Equal(t, pubkey, p.PubKey)
+
+	p, ok = px.GetPeer(testPeers[1])
+	require.True(t, ok)
+	require.True(t, p.Trusted)
+	require.True(t, p.PubKey.Null())
+
+	config.DefaultConnections = []string{"02ab@" + testPeers[0]}
+	_, err = New(config)
+	require.Equal(t, ErrInvalidPubKey, err)
+}
+
+func TestParseTrustedPeer(t *testing.T) {
+	pubkey := cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a")
+
+	cases := []struct {
+		name   string
+		s      string
+		addr   string
+		pubkey cipher.PubKey
+		err    error
+	}{
+		{
+			name: "addr only",
+			s:    "11.22.33.44:6000",
+			addr: "11.22.33.44:6000",
+		},
+		{
+			name:   "pubkey and addr",
+			s:      pubkey.Hex() + "@11.22.33.44:6000",
+			addr:   "11.22.33.44:6000",
+			pubkey: pubkey,
+		},
+		{
+			name: "invalid pubkey",
+			s:    "foo@11.22.33.44:6000",
+			err:  ErrInvalidPubKey,
+		},
+		{
+			name: "too many @",
+			s:    pubkey.Hex() + "@" + pubkey.Hex() + "@11.22.33.44:6000",
+			err:  ErrInvalidAddress,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			addr, pk, err := ParseTrustedPeer(tc.s)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.addr, addr)
+			require.Equal(t, tc.pubkey, pk)
+		})
+	}
+}
+
 func TestNewPexDisableTrustedPeers(t *testing.T) {
 	dir, err := ioutil.TempDir("", "peerlist")
 	require.NoError(t, err)
@@ -1356,6 +1440,60 @@ func TestPexSetTrusted(t *testing.T) {
 	}
 }
 
+func TestPexSetUntrusted(t *testing.T) {
+	pex := &Pex{
+		peerlist: newPeerlist(),
+	}
+
+	p := NewPeer(testPeers[0])
+	p.Trusted = true
+	pex.peerlist.setPeers([]Peer{*p})
+
+	err := pex.SetUntrusted(testPeers[1])
+	require.Equal(t, fmt.Errorf("set peer.Trusted failed: %v does not exist in peer list", testPeers[1]), err)
+
+	err = pex.SetUntrusted(testPeers[0])
+	require.NoError(t, err)
+	require.False(t, pex.peerlist.peers[testPeers[0]].Trusted)
+}
+
+func TestPexSavePersistsRuntimeChanges(t *testing.T) {
+	dir, removeDir := preparePeerlistDir(t)
+	defer removeDir()
+
+	cfg := NewConfig()
+	cfg.DataDirectory = dir
+
+	px, err := New(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, px.AddPeer(testPeers[0]))
+	require.NoError(t, px.AddPeer(testPeers[1]))
+	require.NoError(t, px.SetTrusted(testPeers[0]))
+	require.NoError(t, px.SetPrivate(testPeers[1], true))
+	require.NoError(t, px.Save())
+
+	px, err = New(cfg)
+	require.NoError(t, err)
+
+	p, ok := px.GetPeer(testPeers[0])
+	require.True(t, ok)
+	require.True(t, p.Trusted)
+
+	p, ok = px.GetPeer(testPeers[1])
+	require.True(t, ok)
+	require.True(t, p.Private)
+
+	px.RemovePeer(testPeers[1])
+	require.NoError(t, px.Save())
+
+	px, err = New(cfg)
+	require.NoError(t, err)
+
+	_, ok = px.GetPeer(testPeers[1])
+	require.False(t, ok)
+}
+
 func TestPexSetHasIncomingPort(t *testing.T) {
 	tt := []struct {
 		name            string
@@ -1408,6 +1546,103 @@ func TestPexSetHasIncomingPort(t *testing.T) {
 	}
 }
 
+func TestPexRecordQuality(t *testing.T) {
+	tt := []struct {
+		name     string
+		initPeer Peer
+		peer     string
+		samples  []float64
+		quality  float64
+		err      error
+	}{
+		{
+			"first sample",
+			*NewPeer(testPeers[0]),
+			testPeers[0],
+			[]float64{0.8},
+			0.8,
+			nil,
+		},
+		{
+			"smoothed samples",
+			*NewPeer(testPeers[0]),
+			testPeers[0],
+			[]float64{0.8, 0.3},
+			0.7,
+			nil,
+		},
+		{
+			"samples are clamped",
+			*NewPeer(testPeers[0]),
+			testPeers[0],
+			[]float64{0},
+			MinPeerQuality,
+			nil,
+		},
+		{
+			"set failed",
+			*NewPeer(testPeers[1]),
+			testPeers[0],
+			[]float64{0.5},
+			0,
+			fmt.Errorf("set peer.Quality failed: %v does not exist in peer list", testPeers[0]),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			pex := &Pex{
+				peerlist: newPeerlist(),
+			}
+
+			pex.peerlist.setPeers([]Peer{tc.initPeer})
+
+			var err error
+			for _, q := range tc.samples {
+				err = pex.RecordQuality(tc.peer, q)
+			}
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			p, ok := pex.GetPeer(tc.peer)
+			require.True(t, ok)
+			require.InDelta(t, tc.quality, p.Quality, 1e-9)
+			require.InDelta(t, tc.quality, p.QualityScore(), 1e-9)
+		})
+	}
+}
+
+func TestPexBestPublic(t *testing.T) {
+	pex := &Pex{
+		peerlist: newPeerlist(),
+	}
+
+	pex.peerlist.setPeers([]Peer{
+		{Addr: testPeers[0], Quality: 1},
+		{Addr: testPeers[1], Quality: MinPeerQuality},
+		{Addr: testPeers[2], Private: true},
+		{Addr: testPeers[3]},
+	})
+
+	require.Len(t, pex.BestPublic(0), 3)
+	require.Len(t, pex.BestPublic(5), 3)
+	require.Empty(t, (&Pex{peerlist: newPeerlist()}).BestPublic(1))
+
+	// Peers with a higher quality score are chosen more often
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		peers := pex.BestPublic(1)
+		require.Len(t, peers, 1)
+		require.NotEqual(t, testPeers[2], peers[0].Addr)
+		counts[peers[0].Addr]++
+	}
+
+	require.True(t, counts[testPeers[0]] > counts[testPeers[3]])
+	require.True(t, counts[testPeers[3]] > counts[testPeers[1]])
+}
+
 func TestPexGetPeerByAddr(t *testing.T) {
 	tt := []struct {
 		name      string
@@ -1497,6 +1732,119 @@ func TestParseRemotePeerList(t *testing.T) {
 	}, peers)
 }
 
+func TestSignPeerList(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	otherPubKey, _ := cipher.GenerateKeyPair()
+	body := []byte("11.22.33.44:5555\n66.55.44.33:2020\n")
+	now := time.Unix(time.Now().Unix(), 0)
+	maxAge := time.Hour
+
+	sig, err := SignPeerList(body, seckey, now)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sig, fmt.Sprintf("%d ", now.Unix())))
+
+	require.NoError(t, VerifyPeerList(body, sig, pubkey, now, maxAge))
+	require.NoError(t, VerifyPeerList(body, sig, pubkey, now.Add(maxAge), maxAge))
+	require.Equal(t, ErrPeerListExpired, VerifyPeerList(body, sig, pubkey, now.Add(maxAge+time.Second), maxAge))
+	require.NoError(t, VerifyPeerList(body, sig, pubkey, now.Add(maxAge+time.Second), 0))
+	require.Equal(t, ErrInvalidPeerListSignature, VerifyPeerList(body, sig, otherPubKey, now, maxAge))
+	require.Equal(t, ErrInvalidPeerListSignature, VerifyPeerList(append(body, '\n'), sig, pubkey, now, maxAge))
+	require.Equal(t, ErrInvalidPeerListSignature, VerifyPeerList(body, "", pubkey, now, maxAge))
+	require.Equal(t, ErrInvalidPeerListSignature, VerifyPeerList(body, "404 page not found", pubkey, now, maxAge))
+
+	// The signing time is covered by the signature
+	fields := strings.Fields(sig)
+	require.Len(t, fields, 2)
+	forged := fmt.Sprintf("%d %s", now.Add(time.Hour).Unix(), fields[1])
+	require.Equal(t, ErrInvalidPeerListSignature, VerifyPeerList(body, forged, pubkey, now, maxAge))
+	require.Equal(t, ErrInvalidPeerListSignature, VerifyPeerList(body, fields[1], pubkey, now, maxAge))
+}
+
+func TestPexDownloadPeersSigned(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	_, otherSecKey := cipher.GenerateKeyPair()
+	body := []byte("11.22.33.44:5555\n66.55.44.33:2020\n")
+
+	sig, err := SignPeerList(body, seckey, time.Now())
+	require.NoError(t, err)
+	otherSig, err := SignPeerList(body, otherSecKey, time.Now())
+	require.NoError(t, err)
+	staleSig, err := SignPeerList(body, seckey, time.Now().Add(-DefaultPeerListMaxAge-time.Hour))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		pubkey cipher.PubKey
+		sig    string
+		err    error
+	}{
+		{
+			name: "no pubkey, unsigned",
+		},
+		{
+			name:   "signed",
+			pubkey: pubkey,
+			sig:    sig,
+		},
+		{
+			name:   "unsigned",
+			pubkey: pubkey,
+			err:    ErrInvalidPeerListSignature,
+		},
+		{
+			name:   "signed by another key",
+			pubkey: pubkey,
+			sig:    otherSig,
+			err:    ErrInvalidPeerListSignature,
+		},
+		{
+			name:   "signed too long ago",
+			pubkey: pubkey,
+			sig:    staleSig,
+			err:    ErrPeerListExpired,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/peers.txt":
+					w.Write(body) // nolint: errcheck
+				case "/peers.txt" + PeerListSignatureSuffix:
+					if tc.sig == "" {
+						http.NotFound(w, r)
+						return
+					}
+					fmt.Fprint(w, tc.sig)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			cfg := NewConfig()
+			cfg.PeerListURL = srv.URL + "/peers.txt"
+			cfg.PeerListPubKey = tc.pubkey
+
+			pex := &Pex{
+				Config:   cfg,
+				peerlist: newPeerlist(),
+			}
+
+			err := pex.downloadPeers()
+			require.Equal(t, tc.err, err)
+
+			if tc.err != nil {
+				require.Empty(t, pex.peerlist.peers)
+				return
+			}
+
+			require.Len(t, pex.peerlist.peers, 2)
+		})
+	}
+}
+
 func TestParseLocalPeerList(t *testing.T) {
 	cases := []struct {
 		name           string
@@ -30,6 +30,8 @@ type PoolConfig struct {
 	MaxDefaultPeerOutgoingConnections int
 	// Default "trusted" peers
 	DefaultConnections []string
+	// Messages larger than this many bytes are compressed, for peers that can decompress messages
+	CompressionThreshold int
 	// These should be assigned by the controlling daemon
 	address string
 	port    int
@@ -50,6 +52,7 @@ func NewPoolConfig() PoolConfig {
 		MaxConnections:                    128,
 		MaxOutgoingConnections:            8,
 		MaxDefaultPeerOutgoingConnections: 1,
+		CompressionThreshold:              1024,
 	}
 }
 
@@ -72,6 +75,7 @@ func NewPool(cfg PoolConfig, d *Daemon) (*Pool, error) {
 	gnetCfg.MaxOutgoingConnections = cfg.MaxOutgoingConnections
 	gnetCfg.MaxDefaultPeerOutgoingConnections = cfg.MaxDefaultPeerOutgoingConnections
 	gnetCfg.DefaultConnections = cfg.DefaultConnections
+	gnetCfg.CompressionThreshold = cfg.CompressionThreshold
 
 	pool, err := gnet.NewConnectionPool(gnetCfg, d)
 	if err != nil {
@@ -0,0 +1,268 @@
+package daemon
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// TxnBroadcastStatus is the broadcast status of a transaction created by a local wallet
+type TxnBroadcastStatus string
+
+const (
+	// TxnBroadcastPending the transaction is in the unconfirmed pool and is rebroadcast until it is confirmed
+	TxnBroadcastPending TxnBroadcastStatus = "pending"
+	// TxnBroadcastConfirmed the transaction was executed in a block
+	TxnBroadcastConfirmed TxnBroadcastStatus = "confirmed"
+	// TxnBroadcastInvalid the transaction was dropped from the unconfirmed pool because it became invalid
+	TxnBroadcastInvalid TxnBroadcastStatus = "invalid"
+	// TxnBroadcastConflicted the transaction was dropped from the unconfirmed pool because
+	// one of its inputs was spent by another transaction
+	TxnBroadcastConflicted TxnBroadcastStatus = "conflicted"
+)
+
+// txnBroadcastRetention is how long to remember transactions that are no longer rebroadcast
+const txnBroadcastRetention = time.Hour * 24
+
+// TxnBroadcast is the broadcast state of a transaction created by a local wallet
+type TxnBroadcast struct {
+	Txid   cipher.SHA256
+	Status TxnBroadcastStatus
+	// Addresses that own the transaction's inputs
+	Owners []cipher.Address
+	// Number of times the transaction was sent to peers
+	Broadcasts int
+	// Time of the first broadcast
+	FirstBroadcast time.Time
+	// Time of the last broadcast
+	LastBroadcast time.Time
+	// Time of the next rebroadcast. Zero if the transaction is no longer rebroadcast
+	NextBroadcast time.Time
+	// Addresses of the peers that announced the transaction back to us after we broadcast it
+	AcceptedBy []string
+	// Time the status last changed
+	Updated time.Time
+
+	// Number of rebroadcast attempts, including the attempts that reached no peers
+	attempts uint
+	// Outputs spent by the transaction
+	inputs []cipher.SHA256
+}
+
+func (b *TxnBroadcast) clone() TxnBroadcast {
+	c := *b
+	c.Owners = append([]cipher.Address(nil), b.Owners...)
+	c.AcceptedBy = append([]string(nil), b.AcceptedBy...)
+	c.inputs = append([]cipher.SHA256(nil), b.inputs...)
+	return c
+}
+
+// txnBroadcasts tracks the transactions created by local wallets, so that they can be rebroadcast until confirmed.
+// Rebroadcasts use an exponential backoff, starting at initialDelay and capped at maxDelay.
+type txnBroadcasts struct {
+	sync.RWMutex
+	txns         map[cipher.SHA256]*TxnBroadcast
+	initialDelay time.Duration
+	maxDelay     time.Duration
+}
+
+func newTxnBroadcasts(initialDelay, maxDelay time.Duration) *txnBroadcasts {
+	return &txnBroadcasts{
+		txns:         make(map[cipher.SHA256]*TxnBroadcast),
+		initialDelay: initialDelay,
+		maxDelay:     maxDelay,
+	}
+}
+
+// backoff returns the delay before the next rebroadcast, after the given number of attempts
+func (b *txnBroadcasts) backoff(attempts uint) time.Duration {
+	delay := b.initialDelay
+	for i := uint(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= b.maxDelay {
+			return b.maxDelay
+		}
+	}
+
+	if delay > b.maxDelay {
+		return b.maxDelay
+	}
+	return delay
+}
+
+// add starts tracking a transaction which was just broadcast.
+// If the transaction is already tracked, it is tracked again from the start
+func (b *txnBroadcasts) add(txn coin.Transaction, owners []cipher.Address, now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	txid := txn.Hash()
+
+	var acceptedBy []string
+	if t, ok := b.txns[txid]; ok {
+		acceptedBy = t.AcceptedBy
+	}
+
+	b.txns[txid] = &TxnBroadcast{
+		Txid:           txid,
+		Status:         TxnBroadcastPending,
+		Owners:         owners,
+		Broadcasts:     1,
+		FirstBroadcast: now,
+		LastBroadcast:  now,
+		NextBroadcast:  now.Add(b.backoff(1)),
+		AcceptedBy:     acceptedBy,
+		Updated:        now,
+		attempts:       1,
+		inputs:         txn.In,
+	}
+}
+
+// pending returns the txids of the transactions which are still being rebroadcast
+func (b *txnBroadcasts) pending() []cipher.SHA256 {
+	b.RLock()
+	defer b.RUnlock()
+
+	var txids []cipher.SHA256
+	for txid, t := range b.txns {
+		if t.Status == TxnBroadcastPending {
+			txids = append(txids, txid)
+		}
+	}
+
+	return txids
+}
+
+// due returns the txids of the pending transactions whose rebroadcast time has passed
+func (b *txnBroadcasts) due(now time.Time) []cipher.SHA256 {
+	b.RLock()
+	defer b.RUnlock()
+
+	var txids []cipher.SHA256
+	for txid, t := range b.txns {
+		if t.Status == TxnBroadcastPending && !now.Before(t.NextBroadcast) {
+			txids = append(txids, txid)
+		}
+	}
+
+	return txids
+}
+
+// rebroadcast records a rebroadcast attempt and schedules the next one.
+// sent is false if the transaction could not be sent to any peer
+func (b *txnBroadcasts) rebroadcast(txid cipher.SHA256, sent bool, now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	t, ok := b.txns[txid]
+	if !ok || t.Status != TxnBroadcastPending {
+		return
+	}
+
+	t.attempts++
+	t.NextBroadcast = now.Add(b.backoff(t.attempts))
+	if sent {
+		t.Broadcasts++
+		t.LastBroadcast = now
+	}
+}
+
+// setStatus sets the status of a transaction. Transactions which are no longer pending are not rebroadcast
+func (b *txnBroadcasts) setStatus(txid cipher.SHA256, status TxnBroadcastStatus, now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	t, ok := b.txns[txid]
+	if !ok || t.Status == status {
+		return
+	}
+
+	t.Status = status
+	t.Updated = now
+	if status != TxnBroadcastPending {
+		t.NextBroadcast = time.Time{}
+	}
+}
+
+// accepted records that a peer announced tracked transactions back to us
+func (b *txnBroadcasts) accepted(addr string, txids []cipher.SHA256) {
+	b.Lock()
+	defer b.Unlock()
+
+	for _, txid := range txids {
+		t, ok := b.txns[txid]
+		if !ok || containsString(t.AcceptedBy, addr) {
+			continue
+		}
+
+		t.AcceptedBy = append(t.AcceptedBy, addr)
+	}
+}
+
+// get returns the broadcast state of a transaction
+func (b *txnBroadcasts) get(txid cipher.SHA256) (TxnBroadcast, bool) {
+	b.RLock()
+	defer b.RUnlock()
+
+	t, ok := b.txns[txid]
+	if !ok {
+		return TxnBroadcast{}, false
+	}
+
+	return t.clone(), true
+}
+
+// forAddresses returns the broadcast state of the transactions spending outputs owned by addrs,
+// ordered by the time of their first broadcast
+func (b *txnBroadcasts) forAddresses(addrs []cipher.Address) []TxnBroadcast {
+	b.RLock()
+	defer b.RUnlock()
+
+	addrsMap := make(map[cipher.Address]struct{}, len(addrs))
+	for _, a := range addrs {
+		addrsMap[a] = struct{}{}
+	}
+
+	txns := []TxnBroadcast{}
+	for _, t := range b.txns {
+		for _, a := range t.Owners {
+			if _, ok := addrsMap[a]; ok {
+				txns = append(txns, t.clone())
+				break
+			}
+		}
+	}
+
+	sort.Slice(txns, func(i, j int) bool {
+		if txns[i].FirstBroadcast.Equal(txns[j].FirstBroadcast) {
+			return txns[i].Txid.Hex() < txns[j].Txid.Hex()
+		}
+		return txns[i].FirstBroadcast.Before(txns[j].FirstBroadcast)
+	})
+
+	return txns
+}
+
+// purge forgets the transactions which have not been rebroadcast since the retention period
+func (b *txnBroadcasts) purge(now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	for txid, t := range b.txns {
+		if t.Status != TxnBroadcastPending && now.Sub(t.Updated) > txnBroadcastRetention {
+			delete(b.txns, txid)
+		}
+	}
+}
+
+func containsString(a []string, s string) bool {
+	for _, x := range a {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
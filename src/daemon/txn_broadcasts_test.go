@@ -0,0 +1,127 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestTxnBroadcastsBackoff(t *testing.T) {
+	b := newTxnBroadcasts(time.Minute, time.Minute*10)
+
+	cases := []struct {
+		attempts uint
+		delay    time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, time.Minute * 2},
+		{3, time.Minute * 4},
+		{4, time.Minute * 8},
+		{5, time.Minute * 10},
+		{100, time.Minute * 10},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.delay, b.backoff(tc.attempts), "attempts=%d", tc.attempts)
+	}
+}
+
+func TestTxnBroadcasts(t *testing.T) {
+	b := newTxnBroadcasts(time.Minute, time.Minute*10)
+
+	addrs := []cipher.Address{
+		testutil.MakeAddress(),
+		testutil.MakeAddress(),
+		testutil.MakeAddress(),
+	}
+
+	txn1 := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+	}
+	txn2 := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+	}
+
+	now := time.Unix(1000, 0).UTC()
+	b.add(txn1, []cipher.Address{addrs[0]}, now)
+	b.add(txn2, []cipher.Address{addrs[1], addrs[0]}, now.Add(time.Second))
+
+	require.Len(t, b.pending(), 2)
+	require.Empty(t, b.due(now))
+
+	// Both transactions are due after the initial delay
+	require.Len(t, b.due(now.Add(time.Minute+time.Second)), 2)
+
+	// A rebroadcast that reached no peer still backs off
+	now = now.Add(time.Minute)
+	b.rebroadcast(txn1.Hash(), false, now)
+	tb, ok := b.get(txn1.Hash())
+	require.True(t, ok)
+	require.Equal(t, 1, tb.Broadcasts)
+	require.Equal(t, now.Add(time.Minute*2), tb.NextBroadcast)
+
+	now = now.Add(time.Minute * 2)
+	b.rebroadcast(txn1.Hash(), true, now)
+	tb, ok = b.get(txn1.Hash())
+	require.True(t, ok)
+	require.Equal(t, 2, tb.Broadcasts)
+	require.Equal(t, now, tb.LastBroadcast)
+	require.Equal(t, now.Add(time.Minute*4), tb.NextBroadcast)
+
+	// Peers that announce the transaction are recorded once
+	b.accepted("127.0.0.1:6000", []cipher.SHA256{txn1.Hash(), testutil.RandSHA256(t)})
+	b.accepted("127.0.0.1:6000", []cipher.SHA256{txn1.Hash()})
+	b.accepted("127.0.0.1:6001", []cipher.SHA256{txn1.Hash()})
+	tb, ok = b.get(txn1.Hash())
+	require.True(t, ok)
+	require.Equal(t, []string{"127.0.0.1:6000", "127.0.0.1:6001"}, tb.AcceptedBy)
+
+	// Transactions are found by the owners of their inputs, ordered by first broadcast
+	txns := b.forAddresses([]cipher.Address{addrs[0]})
+	require.Len(t, txns, 2)
+	require.Equal(t, txn1.Hash(), txns[0].Txid)
+	require.Equal(t, txn2.Hash(), txns[1].Txid)
+
+	txns = b.forAddresses([]cipher.Address{addrs[1]})
+	require.Len(t, txns, 1)
+	require.Equal(t, txn2.Hash(), txns[0].Txid)
+
+	require.Empty(t, b.forAddresses([]cipher.Address{addrs[2]}))
+	require.NotNil(t, b.forAddresses(nil))
+
+	// Transactions that are no longer pending are not rebroadcast
+	b.setStatus(txn2.Hash(), TxnBroadcastConflicted, now)
+	require.Equal(t, []cipher.SHA256{txn1.Hash()}, b.pending())
+	require.Equal(t, []cipher.SHA256{txn1.Hash()}, b.due(now.Add(time.Hour)))
+	b.rebroadcast(txn2.Hash(), true, now)
+	tb, ok = b.get(txn2.Hash())
+	require.True(t, ok)
+	require.Equal(t, TxnBroadcastConflicted, tb.Status)
+	require.Equal(t, 1, tb.Broadcasts)
+	require.True(t, tb.NextBroadcast.IsZero())
+
+	// Finished transactions are forgotten after the retention period
+	b.purge(now.Add(txnBroadcastRetention))
+	_, ok = b.get(txn2.Hash())
+	require.True(t, ok)
+
+	b.purge(now.Add(txnBroadcastRetention + time.Second))
+	_, ok = b.get(txn2.Hash())
+	require.False(t, ok)
+	_, ok = b.get(txn1.Hash())
+	require.True(t, ok)
+
+	// Adding a tracked transaction again restarts its backoff and keeps the peers that accepted it
+	b.add(txn1, []cipher.Address{addrs[0]}, now)
+	tb, ok = b.get(txn1.Hash())
+	require.True(t, ok)
+	require.Equal(t, 1, tb.Broadcasts)
+	require.Equal(t, now.Add(time.Minute), tb.NextBroadcast)
+	require.Equal(t, []string{"127.0.0.1:6000", "127.0.0.1:6001"}, tb.AcceptedBy)
+}
@@ -0,0 +1,223 @@
+package daemon
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// maxTxnConflictEvents is the maximum number of conflict events kept for API consumers
+const maxTxnConflictEvents = 1000
+
+// TxnConflict is an output spent by more than one transaction seen by this node.
+// It is evidence of an attempt to spend the output twice, e.g. to defraud a merchant accepting unconfirmed payments
+type TxnConflict struct {
+	// The output spent by the conflicting transactions
+	UxID cipher.SHA256
+	// The owner of the output
+	Address cipher.Address
+	// Hashes of the transactions spending the output, in the order they were seen
+	Txids []cipher.SHA256
+	// Address of the peer that the conflicting transaction or the conflict evidence was first received from.
+	// Empty if the conflicting transaction was injected into this node
+	Source string
+	// Time the conflict was detected
+	DetectedAt time.Time
+	// Time another transaction spending the output was last seen
+	Updated time.Time
+}
+
+func (c *TxnConflict) clone() TxnConflict {
+	x := *c
+	x.Txids = append([]cipher.SHA256(nil), c.Txids...)
+	return x
+}
+
+// TxnConflictEvent is emitted when a conflict is detected, and when another transaction spending
+// the output of a known conflict is seen
+type TxnConflictEvent struct {
+	// Event IDs increase by one for each event, starting at 1
+	ID       uint64
+	Time     time.Time
+	Conflict TxnConflict
+}
+
+// txnConflicts records outputs spent by more than one transaction for a short time, and the events emitted for them.
+// A conflict is forgotten when no transaction spending its output was seen for the retention period.
+// Relays of conflict evidence to peers are limited to relayRate per minute.
+type txnConflicts struct {
+	sync.RWMutex
+	conflicts map[cipher.SHA256]*TxnConflict
+	events    []TxnConflictEvent
+	lastID    uint64
+	// notify is closed and replaced when an event is added
+	notify    chan struct{}
+	retention time.Duration
+
+	relayRate   int
+	relayWindow time.Time
+	relays      int
+}
+
+func newTxnConflicts(retention time.Duration, relayRate int) *txnConflicts {
+	return &txnConflicts{
+		conflicts: make(map[cipher.SHA256]*TxnConflict),
+		notify:    make(chan struct{}),
+		retention: retention,
+		relayRate: relayRate,
+	}
+}
+
+// add records that the transactions txids spend the output ux. The conflict is created if necessary.
+// Returns true and emits an event if the conflict is new, or if any of the transactions was not seen before
+func (c *txnConflicts) add(ux coin.UxOut, txids []cipher.SHA256, source string, now time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	uxID := ux.Hash()
+	x, ok := c.conflicts[uxID]
+	if !ok {
+		x = &TxnConflict{
+			UxID:       uxID,
+			Address:    ux.Body.Address,
+			Source:     source,
+			DetectedAt: now,
+		}
+		c.conflicts[uxID] = x
+	}
+
+	var changed bool
+	for _, txid := range txids {
+		if !containsHash(x.Txids, txid) {
+			x.Txids = append(x.Txids, txid)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false
+	}
+
+	x.Updated = now
+
+	c.lastID++
+	c.events = append(c.events, TxnConflictEvent{
+		ID:       c.lastID,
+		Time:     now,
+		Conflict: x.clone(),
+	})
+	if len(c.events) > maxTxnConflictEvents {
+		c.events = c.events[len(c.events)-maxTxnConflictEvents:]
+	}
+
+	close(c.notify)
+	c.notify = make(chan struct{})
+
+	return true
+}
+
+// allowRelay returns true if conflict evidence can be relayed to peers without exceeding the relay rate
+func (c *txnConflicts) allowRelay(now time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	if now.Sub(c.relayWindow) >= time.Minute {
+		c.relayWindow = now
+		c.relays = 0
+	}
+
+	if c.relays >= c.relayRate {
+		return false
+	}
+
+	c.relays++
+	return true
+}
+
+// filter returns the conflicts involving any of the transactions txids or outputs owned by any of addrs,
+// ordered by detection time. If both txids and addrs are empty, all conflicts are returned
+func (c *txnConflicts) filter(txids []cipher.SHA256, addrs []cipher.Address) []TxnConflict {
+	c.RLock()
+	defer c.RUnlock()
+
+	addrsMap := make(map[cipher.Address]struct{}, len(addrs))
+	for _, a := range addrs {
+		addrsMap[a] = struct{}{}
+	}
+
+	conflicts := []TxnConflict{}
+	for _, x := range c.conflicts {
+		match := len(txids) == 0 && len(addrs) == 0
+		if _, ok := addrsMap[x.Address]; ok {
+			match = true
+		}
+		for _, txid := range txids {
+			if containsHash(x.Txids, txid) {
+				match = true
+				break
+			}
+		}
+
+		if match {
+			conflicts = append(conflicts, x.clone())
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].DetectedAt.Equal(conflicts[j].DetectedAt) {
+			return conflicts[i].UxID.Hex() < conflicts[j].UxID.Hex()
+		}
+		return conflicts[i].DetectedAt.Before(conflicts[j].DetectedAt)
+	})
+
+	return conflicts
+}
+
+// eventsSince returns the events with an ID greater than id, and a channel that is closed when the next event is added
+func (c *txnConflicts) eventsSince(id uint64) ([]TxnConflictEvent, <-chan struct{}) {
+	c.RLock()
+	defer c.RUnlock()
+
+	events := []TxnConflictEvent{}
+	for _, e := range c.events {
+		if e.ID > id {
+			events = append(events, TxnConflictEvent{
+				ID:       e.ID,
+				Time:     e.Time,
+				Conflict: e.Conflict.clone(),
+			})
+		}
+	}
+
+	return events, c.notify
+}
+
+// purge forgets the conflicts and events which are older than the retention period
+func (c *txnConflicts) purge(now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	for uxID, x := range c.conflicts {
+		if now.Sub(x.Updated) > c.retention {
+			delete(c.conflicts, uxID)
+		}
+	}
+
+	i := 0
+	for i < len(c.events) && now.Sub(c.events[i].Time) > c.retention {
+		i++
+	}
+	c.events = c.events[i:]
+}
+
+func containsHash(hashes []cipher.SHA256, hash cipher.SHA256) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
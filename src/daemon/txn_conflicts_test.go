@@ -0,0 +1,131 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func makeUxOut(t *testing.T, addr cipher.Address) coin.UxOut {
+	return coin.UxOut{
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        addr,
+			Coins:          1e6,
+		},
+	}
+}
+
+func TestTxnConflicts(t *testing.T) {
+	c := newTxnConflicts(time.Minute*30, 10)
+	now := time.Now().UTC()
+
+	addrA := testutil.MakeAddress()
+	addrB := testutil.MakeAddress()
+	uxA := makeUxOut(t, addrA)
+	uxB := makeUxOut(t, addrB)
+
+	txids := make([]cipher.SHA256, 4)
+	for i := range txids {
+		txids[i] = testutil.RandSHA256(t)
+	}
+
+	events, notify := c.eventsSince(0)
+	require.Empty(t, events)
+
+	require.True(t, c.add(uxA, []cipher.SHA256{txids[0], txids[1]}, "1.1.1.1:6000", now))
+
+	// Adding an event wakes up waiting consumers
+	select {
+	case <-notify:
+	default:
+		t.Fatal("notify was not closed")
+	}
+
+	// The same conflict is not added again
+	require.False(t, c.add(uxA, []cipher.SHA256{txids[1], txids[0]}, "2.2.2.2:6000", now.Add(time.Second)))
+
+	// Another transaction spending the output extends the conflict
+	require.True(t, c.add(uxA, []cipher.SHA256{txids[0], txids[2]}, "2.2.2.2:6000", now.Add(time.Second*2)))
+
+	require.True(t, c.add(uxB, []cipher.SHA256{txids[3], txids[0]}, "", now.Add(time.Second*3)))
+
+	conflictA := TxnConflict{
+		UxID:       uxA.Hash(),
+		Address:    addrA,
+		Txids:      []cipher.SHA256{txids[0], txids[1], txids[2]},
+		Source:     "1.1.1.1:6000",
+		DetectedAt: now,
+		Updated:    now.Add(time.Second * 2),
+	}
+	conflictB := TxnConflict{
+		UxID:       uxB.Hash(),
+		Address:    addrB,
+		Txids:      []cipher.SHA256{txids[3], txids[0]},
+		DetectedAt: now.Add(time.Second * 3),
+		Updated:    now.Add(time.Second * 3),
+	}
+
+	require.Equal(t, []TxnConflict{conflictA, conflictB}, c.filter(nil, nil))
+	require.Equal(t, []TxnConflict{conflictA, conflictB}, c.filter([]cipher.SHA256{txids[0]}, nil))
+	require.Equal(t, []TxnConflict{conflictA}, c.filter([]cipher.SHA256{txids[2]}, nil))
+	require.Equal(t, []TxnConflict{conflictB}, c.filter(nil, []cipher.Address{addrB}))
+	require.Equal(t, []TxnConflict{conflictA, conflictB}, c.filter([]cipher.SHA256{txids[1]}, []cipher.Address{addrB}))
+	require.Equal(t, []TxnConflict{}, c.filter([]cipher.SHA256{testutil.RandSHA256(t)}, nil))
+
+	// Events snapshot the conflict when they were emitted
+	events, _ = c.eventsSince(0)
+	require.Len(t, events, 3)
+	require.Equal(t, uint64(1), events[0].ID)
+	require.Equal(t, []cipher.SHA256{txids[0], txids[1]}, events[0].Conflict.Txids)
+	require.Equal(t, uint64(2), events[1].ID)
+	require.Equal(t, conflictA, events[1].Conflict)
+	require.Equal(t, uint64(3), events[2].ID)
+	require.Equal(t, conflictB, events[2].Conflict)
+
+	events, _ = c.eventsSince(2)
+	require.Len(t, events, 1)
+	require.Equal(t, uint64(3), events[0].ID)
+
+	events, _ = c.eventsSince(3)
+	require.Empty(t, events)
+
+	// Conflicts and events older than the retention period are forgotten
+	c.purge(now.Add(time.Minute*30 + time.Second*2 + time.Millisecond))
+	require.Equal(t, []TxnConflict{conflictB}, c.filter(nil, nil))
+	events, _ = c.eventsSince(0)
+	require.Len(t, events, 1)
+	require.Equal(t, uint64(3), events[0].ID)
+}
+
+func TestTxnConflictsMaxEvents(t *testing.T) {
+	c := newTxnConflicts(time.Hour, 10)
+	now := time.Now().UTC()
+
+	ux := makeUxOut(t, testutil.MakeAddress())
+	first := testutil.RandSHA256(t)
+	for i := 0; i < maxTxnConflictEvents+5; i++ {
+		require.True(t, c.add(ux, []cipher.SHA256{first, testutil.RandSHA256(t)}, "", now))
+	}
+
+	events, _ := c.eventsSince(0)
+	require.Len(t, events, maxTxnConflictEvents)
+	require.Equal(t, uint64(6), events[0].ID)
+	require.Equal(t, uint64(maxTxnConflictEvents+5), events[len(events)-1].ID)
+}
+
+func TestTxnConflictsAllowRelay(t *testing.T) {
+	c := newTxnConflicts(time.Hour, 2)
+	now := time.Now().UTC()
+
+	require.True(t, c.allowRelay(now))
+	require.True(t, c.allowRelay(now.Add(time.Second)))
+	require.False(t, c.allowRelay(now.Add(time.Second*59)))
+
+	require.True(t, c.allowRelay(now.Add(time.Minute)))
+}
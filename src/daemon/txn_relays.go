@@ -0,0 +1,264 @@
+package daemon
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// maxTxnRelayPeers is the maximum number of peers recorded in each list of a TxnRelay
+const maxTxnRelayPeers = 256
+
+// TxnRelayRejection is a rejection of a transaction received from a peer
+type TxnRelayRejection struct {
+	// Address of the peer that sent the transaction
+	Addr string
+	// Error returned by InjectForeignTransaction
+	Reason string
+	// Whether the transaction was still added to the unconfirmed pool, because it only violated soft constraints
+	Soft bool
+	// Time of the rejection
+	Time time.Time
+}
+
+// TxnRelay is the relay state of an unconfirmed transaction, used for debugging transaction propagation
+type TxnRelay struct {
+	Txid cipher.SHA256
+	// Time the transaction was first received from a peer or sent to a peer
+	FirstSeen time.Time
+	// Address of the peer that the transaction was first received from and added to the unconfirmed pool.
+	// Empty if it was first sent by this node, or was only ever rejected
+	Source string
+	// Addresses of the peers that the transaction was announced or sent to
+	AnnouncedTo []string
+	// Addresses of the peers that requested the transaction with a GetTxnsMessage
+	RequestedBy []string
+	// Rejections of the transaction when received from peers, one per peer
+	Rejections []TxnRelayRejection
+	// Time of the last update
+	Updated time.Time
+}
+
+func (r *TxnRelay) clone() TxnRelay {
+	c := *r
+	c.AnnouncedTo = append([]string(nil), r.AnnouncedTo...)
+	c.RequestedBy = append([]string(nil), r.RequestedBy...)
+	c.Rejections = append([]TxnRelayRejection(nil), r.Rejections...)
+	return c
+}
+
+// txnRelayList is a list of transaction relay states, least recently updated first, indexed by txid
+type txnRelayList struct {
+	txns  map[cipher.SHA256]*list.Element
+	order *list.List
+	max   int
+}
+
+func newTxnRelayList(max int) txnRelayList {
+	return txnRelayList{
+		txns:  make(map[cipher.SHA256]*list.Element),
+		order: list.New(),
+		max:   max,
+	}
+}
+
+func (l *txnRelayList) get(txid cipher.SHA256) (*TxnRelay, bool) {
+	e, ok := l.txns[txid]
+	if !ok {
+		return nil, false
+	}
+	return e.Value.(*TxnRelay), true
+}
+
+// add adds a transaction as the most recently updated, forgetting the least recently updated if the list is full
+func (l *txnRelayList) add(t *TxnRelay) {
+	if e := l.order.Front(); e != nil && l.order.Len() >= l.max {
+		l.remove(e.Value.(*TxnRelay).Txid)
+	}
+	l.txns[t.Txid] = l.order.PushBack(t)
+}
+
+// touch marks a transaction as the most recently updated
+func (l *txnRelayList) touch(txid cipher.SHA256, now time.Time) {
+	e := l.txns[txid]
+	e.Value.(*TxnRelay).Updated = now
+	l.order.MoveToBack(e)
+}
+
+func (l *txnRelayList) remove(txid cipher.SHA256) {
+	if e, ok := l.txns[txid]; ok {
+		l.order.Remove(e)
+		delete(l.txns, txid)
+	}
+}
+
+// purge forgets the transactions which were last updated before cutoff
+func (l *txnRelayList) purge(cutoff time.Time) {
+	for e := l.order.Front(); e != nil; e = l.order.Front() {
+		t := e.Value.(*TxnRelay)
+		if !t.Updated.Before(cutoff) {
+			return
+		}
+		l.remove(t.Txid)
+	}
+}
+
+// txnRelays records how unconfirmed transactions propagate between this node and its peers.
+// A transaction is forgotten when it has not been updated for the retention period.
+// At most maxTxns transactions are remembered, the least recently updated are forgotten first.
+// Transactions that were only ever rejected were never in the unconfirmed pool, and any peer can send
+// those for free, so they are remembered apart, up to maxRejectedTxns, so that they can't push out
+// the relay states of the unconfirmed transactions
+type txnRelays struct {
+	sync.RWMutex
+	// Transactions that were added to the unconfirmed pool, or sent by this node
+	txns txnRelayList
+	// Transactions that were rejected by the unconfirmed pool each time they were received
+	rejectedTxns txnRelayList
+	retention    time.Duration
+}
+
+func newTxnRelays(retention time.Duration, maxTxns, maxRejectedTxns int) *txnRelays {
+	return &txnRelays{
+		txns:         newTxnRelayList(maxTxns),
+		rejectedTxns: newTxnRelayList(maxRejectedTxns),
+		retention:    retention,
+	}
+}
+
+// getOrAdd returns the relay state of a transaction that is in the unconfirmed pool or was sent by this node,
+// starting to track it if necessary. A transaction that was only rejected until now is moved to the tracked
+// unconfirmed transactions. The bool return value is true if the transaction was not tracked as such yet.
+// Must be called with the lock held
+func (r *txnRelays) getOrAdd(txid cipher.SHA256, now time.Time) (*TxnRelay, bool) {
+	if t, ok := r.txns.get(txid); ok {
+		r.txns.touch(txid, now)
+		return t, false
+	}
+
+	t, ok := r.rejectedTxns.get(txid)
+	if ok {
+		r.rejectedTxns.remove(txid)
+	} else {
+		t = &TxnRelay{
+			Txid:      txid,
+			FirstSeen: now,
+		}
+	}
+
+	t.Updated = now
+	r.txns.add(t)
+	return t, true
+}
+
+// received records that a transaction was received from a peer
+func (r *txnRelays) received(addr string, txid cipher.SHA256, now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	if t, added := r.getOrAdd(txid, now); added {
+		t.Source = addr
+	}
+}
+
+// announced records that transactions were announced or sent to a peer
+func (r *txnRelays) announced(addr string, txids []cipher.SHA256, now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, txid := range txids {
+		t, _ := r.getOrAdd(txid, now)
+		t.AnnouncedTo = appendPeer(t.AnnouncedTo, addr)
+	}
+}
+
+// requested records that a peer requested transactions
+func (r *txnRelays) requested(addr string, txids []cipher.SHA256, now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, txid := range txids {
+		t, _ := r.getOrAdd(txid, now)
+		t.RequestedBy = appendPeer(t.RequestedBy, addr)
+	}
+}
+
+// rejected records that a transaction received from a peer was rejected.
+// soft is true if the transaction was still added to the unconfirmed pool.
+// Only the latest rejection from each peer is kept
+func (r *txnRelays) rejected(addr string, txid cipher.SHA256, reason string, soft bool, now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	var t *TxnRelay
+	if soft {
+		t, _ = r.getOrAdd(txid, now)
+	} else if tracked, ok := r.txns.get(txid); ok {
+		t = tracked
+		r.txns.touch(txid, now)
+	} else if tracked, ok := r.rejectedTxns.get(txid); ok {
+		t = tracked
+		r.rejectedTxns.touch(txid, now)
+	} else {
+		t = &TxnRelay{
+			Txid:      txid,
+			FirstSeen: now,
+			Updated:   now,
+		}
+		r.rejectedTxns.add(t)
+	}
+
+	rejection := TxnRelayRejection{
+		Addr:   addr,
+		Reason: reason,
+		Soft:   soft,
+		Time:   now,
+	}
+
+	for i := range t.Rejections {
+		if t.Rejections[i].Addr == addr {
+			t.Rejections[i] = rejection
+			return
+		}
+	}
+
+	if len(t.Rejections) < maxTxnRelayPeers {
+		t.Rejections = append(t.Rejections, rejection)
+	}
+}
+
+// get returns the relay state of a transaction
+func (r *txnRelays) get(txid cipher.SHA256) (TxnRelay, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	t, ok := r.txns.get(txid)
+	if !ok {
+		t, ok = r.rejectedTxns.get(txid)
+		if !ok {
+			return TxnRelay{}, false
+		}
+	}
+
+	return t.clone(), true
+}
+
+// purge forgets the transactions which have not been updated since the retention period
+func (r *txnRelays) purge(now time.Time) {
+	r.Lock()
+	defer r.Unlock()
+
+	cutoff := now.Add(-r.retention)
+	r.txns.purge(cutoff)
+	r.rejectedTxns.purge(cutoff)
+}
+
+// appendPeer appends addr to peers if it is not already present and the list is not full
+func appendPeer(peers []string, addr string) []string {
+	if len(peers) >= maxTxnRelayPeers || containsString(peers, addr) {
+		return peers
+	}
+	return append(peers, addr)
+}
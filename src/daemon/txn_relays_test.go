@@ -0,0 +1,176 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestTxnRelays(t *testing.T) {
+	r := newTxnRelays(time.Hour, 10, 10)
+	now := time.Now().UTC()
+
+	txid := testutil.RandSHA256(t)
+	localTxid := testutil.RandSHA256(t)
+
+	_, ok := r.get(txid)
+	require.False(t, ok)
+
+	// The first peer that sends the transaction is its source
+	r.received("1.1.1.1:6000", txid, now)
+	r.received("2.2.2.2:6000", txid, now.Add(time.Second))
+
+	// Peers are recorded once
+	r.announced("3.3.3.3:6000", []cipher.SHA256{txid, localTxid}, now.Add(time.Second*2))
+	r.announced("3.3.3.3:6000", []cipher.SHA256{txid}, now.Add(time.Second*3))
+	r.announced("4.4.4.4:6000", []cipher.SHA256{txid}, now.Add(time.Second*3))
+	r.requested("3.3.3.3:6000", []cipher.SHA256{txid}, now.Add(time.Second*4))
+	r.requested("3.3.3.3:6000", []cipher.SHA256{txid}, now.Add(time.Second*4))
+
+	// Only the latest rejection from each peer is kept
+	r.rejected("2.2.2.2:6000", txid, "foo", false, now.Add(time.Second*5))
+	r.rejected("2.2.2.2:6000", txid, "bar", true, now.Add(time.Second*6))
+
+	tr, ok := r.get(txid)
+	require.True(t, ok)
+	require.Equal(t, TxnRelay{
+		Txid:        txid,
+		FirstSeen:   now,
+		Source:      "1.1.1.1:6000",
+		AnnouncedTo: []string{"3.3.3.3:6000", "4.4.4.4:6000"},
+		RequestedBy: []string{"3.3.3.3:6000"},
+		Rejections: []TxnRelayRejection{
+			{
+				Addr:   "2.2.2.2:6000",
+				Reason: "bar",
+				Soft:   true,
+				Time:   now.Add(time.Second * 6),
+			},
+		},
+		Updated: now.Add(time.Second * 6),
+	}, tr)
+
+	// A transaction which was sent by this node first has no source
+	tr, ok = r.get(localTxid)
+	require.True(t, ok)
+	require.Equal(t, "", tr.Source)
+	require.Equal(t, now.Add(time.Second*2), tr.FirstSeen)
+
+	// The returned value is a copy
+	tr.AnnouncedTo[0] = "5.5.5.5:6000"
+	tr, ok = r.get(localTxid)
+	require.True(t, ok)
+	require.Equal(t, []string{"3.3.3.3:6000"}, tr.AnnouncedTo)
+
+	// Transactions which were not updated during the retention period are forgotten
+	r.purge(now.Add(time.Hour + time.Second*3))
+	_, ok = r.get(localTxid)
+	require.False(t, ok)
+	_, ok = r.get(txid)
+	require.True(t, ok)
+}
+
+func TestTxnRelaysMaxTxns(t *testing.T) {
+	r := newTxnRelays(time.Hour, 3, 3)
+	now := time.Now().UTC()
+
+	txids := make([]cipher.SHA256, 4)
+	for i := range txids {
+		txids[i] = testutil.RandSHA256(t)
+	}
+
+	r.received("1.1.1.1:6000", txids[0], now)
+	r.received("1.1.1.1:6000", txids[1], now.Add(time.Second))
+	r.received("1.1.1.1:6000", txids[2], now.Add(time.Second*2))
+
+	// Updating a transaction makes it the most recent
+	r.requested("2.2.2.2:6000", []cipher.SHA256{txids[0]}, now.Add(time.Second*3))
+
+	// The least recently updated transaction is forgotten
+	r.received("1.1.1.1:6000", txids[3], now.Add(time.Second*4))
+	require.Equal(t, 3, r.txns.order.Len())
+	require.Len(t, r.txns.txns, 3)
+
+	_, ok := r.get(txids[1])
+	require.False(t, ok)
+	for _, i := range []int{0, 2, 3} {
+		_, ok := r.get(txids[i])
+		require.True(t, ok)
+	}
+}
+
+func TestTxnRelaysRejectedTxns(t *testing.T) {
+	r := newTxnRelays(time.Hour, 3, 2)
+	now := time.Now().UTC()
+
+	txids := make([]cipher.SHA256, 4)
+	for i := range txids {
+		txids[i] = testutil.RandSHA256(t)
+	}
+
+	r.received("1.1.1.1:6000", txids[0], now)
+
+	// Transactions which were never in the pool are remembered apart, up to their own limit
+	r.rejected("2.2.2.2:6000", txids[1], "foo", false, now.Add(time.Second))
+	r.rejected("2.2.2.2:6000", txids[2], "foo", false, now.Add(time.Second*2))
+	r.rejected("2.2.2.2:6000", txids[3], "foo", false, now.Add(time.Second*3))
+	require.Equal(t, 1, r.txns.order.Len())
+	require.Equal(t, 2, r.rejectedTxns.order.Len())
+
+	_, ok := r.get(txids[1])
+	require.False(t, ok)
+
+	tr, ok := r.get(txids[2])
+	require.True(t, ok)
+	require.Equal(t, TxnRelay{
+		Txid:      txids[2],
+		FirstSeen: now.Add(time.Second * 2),
+		Rejections: []TxnRelayRejection{
+			{
+				Addr:   "2.2.2.2:6000",
+				Reason: "foo",
+				Time:   now.Add(time.Second * 2),
+			},
+		},
+		Updated: now.Add(time.Second * 2),
+	}, tr)
+
+	// A rejection of a transaction in the pool updates it in place
+	r.rejected("2.2.2.2:6000", txids[0], "bar", false, now.Add(time.Second*4))
+	require.Equal(t, 1, r.txns.order.Len())
+	tr, ok = r.get(txids[0])
+	require.True(t, ok)
+	require.Equal(t, "1.1.1.1:6000", tr.Source)
+	require.Len(t, tr.Rejections, 1)
+
+	// A rejected transaction which is later added to the pool keeps its relay state
+	r.received("3.3.3.3:6000", txids[2], now.Add(time.Second*5))
+	require.Equal(t, 2, r.txns.order.Len())
+	require.Equal(t, 1, r.rejectedTxns.order.Len())
+	tr, ok = r.get(txids[2])
+	require.True(t, ok)
+	require.Equal(t, "3.3.3.3:6000", tr.Source)
+	require.Equal(t, now.Add(time.Second*2), tr.FirstSeen)
+	require.Equal(t, now.Add(time.Second*5), tr.Updated)
+	require.Len(t, tr.Rejections, 1)
+
+	// A soft rejection means that the transaction was added to the pool
+	r.rejected("2.2.2.2:6000", txids[3], "baz", true, now.Add(time.Second*6))
+	require.Equal(t, 3, r.txns.order.Len())
+	require.Equal(t, 0, r.rejectedTxns.order.Len())
+
+	// Rejected transactions are purged too
+	r.rejected("2.2.2.2:6000", txids[1], "foo", false, now.Add(time.Second*7))
+	r.rejected("2.2.2.2:6000", txids[2], "foo", false, now.Add(time.Second*8))
+	r.purge(now.Add(time.Hour + time.Second*7))
+	require.Equal(t, 1, r.txns.order.Len())
+	require.Equal(t, 1, r.rejectedTxns.order.Len())
+	_, ok = r.get(txids[2])
+	require.True(t, ok)
+	_, ok = r.get(txids[1])
+	require.True(t, ok)
+}
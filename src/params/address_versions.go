@@ -0,0 +1,14 @@
+package params
+
+// addressVersionCoins maps the address versions of known fiber coins to their coin names.
+// Fiber coins must use different address versions, add yours here when you choose one
+var addressVersionCoins = map[byte]string{
+	0: "skycoin",
+}
+
+// AddressVersionCoin returns the name of the fiber coin whose addresses have an address version,
+// or false if the address version is not of a known fiber coin
+func AddressVersionCoin(version byte) (string, bool) {
+	coin, ok := addressVersionCoins[version]
+	return coin, ok
+}
@@ -13,6 +13,7 @@ func init() {
 	loadUserBurnFactor()
 	loadUserMaxTransactionSize()
 	loadUserMaxDecimals()
+	setAddressVersion()
 	decodeDistributionAddresses()
 	sanityCheck()
 }
@@ -97,6 +98,10 @@ func loadUserMaxDecimals() {
 	UserVerifyTxn.MaxDropletPrecision = uint8(x)
 }
 
+func setAddressVersion() {
+	cipher.AddressVersion = AddressVersion
+}
+
 func decodeDistributionAddresses() {
 	distributionAddressesDecoded = make([]cipher.Address, len(distributionAddresses))
 	for i, a := range distributionAddresses {
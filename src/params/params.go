@@ -6,6 +6,12 @@ AVOID EDITING THIS MANUALLY
 */
 
 const (
+	// Address parameters
+
+	// AddressVersion is the version byte of the coin's addresses.
+	// Fiber coins must use different address versions, so that their addresses are not interchangeable
+	AddressVersion byte = 0
+
 	// Distribution locking parameteres
 
 	// MaxCoinSupply is the maximum supply of coins
@@ -22,6 +28,19 @@ const (
 	// Once the InitialUnlockedCount is exhausted,
 	// UnlockAddressRate addresses will be unlocked per UnlockTimeInterval
 	UnlockTimeInterval uint64 = 31536000 // in seconds
+
+	// Fork activation parameters
+
+	// SchnorrActivationHeight is the block seq from which transactions with Schnorr signatures are accepted
+	SchnorrActivationHeight uint64 = 180000
+	// ReplayProtectionActivationHeight is the block seq from which replay protected transactions are accepted,
+	// and from which the wallet creates them
+	ReplayProtectionActivationHeight uint64 = 180000
+	// HTLCActivationHeight is the block seq from which transactions spending HTLC outputs are accepted
+	HTLCActivationHeight uint64 = 180000
+	// EmptyBlockActivationHeight is the block seq from which blocks without transactions are accepted,
+	// and from which the block publisher creates empty heartbeat blocks
+	EmptyBlockActivationHeight uint64 = 180000
 )
 
 var (
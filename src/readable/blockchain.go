@@ -1,6 +1,7 @@
 package readable
 
 import (
+	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/visor"
 )
@@ -56,3 +57,52 @@ func NewBlockchainProgress(bp *daemon.BlockchainProgress) BlockchainProgress {
 		Peers:   peers,
 	}
 }
+
+// SignedBlock is a readable block with its signature
+type SignedBlock struct {
+	Block
+	Signature string `json:"signature"`
+}
+
+// NewSignedBlock creates a readable signed block
+func NewSignedBlock(b coin.SignedBlock) (*SignedBlock, error) {
+	rb, err := NewBlock(b.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedBlock{
+		Block:     *rb,
+		Signature: b.Sig.Hex(),
+	}, nil
+}
+
+// DoubleSignProof is evidence that the block publisher signed two different blocks at the same seq
+type DoubleSignProof struct {
+	Seq        uint64      `json:"seq"`
+	Source     string      `json:"source"`
+	DetectedAt int64       `json:"detected_at"`
+	BlockA     SignedBlock `json:"block_a"`
+	BlockB     SignedBlock `json:"block_b"`
+}
+
+// NewDoubleSignProof creates a readable double sign proof
+func NewDoubleSignProof(p visor.DoubleSignProof) (*DoubleSignProof, error) {
+	a, err := NewSignedBlock(p.BlockA)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := NewSignedBlock(p.BlockB)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DoubleSignProof{
+		Seq:        p.Seq(),
+		Source:     p.Source,
+		DetectedAt: p.DetectedAt,
+		BlockA:     *a,
+		BlockB:     *b,
+	}, nil
+}
@@ -1,11 +1,21 @@
 package readable
 
 import (
+	"time"
+
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/util/useragent"
 )
 
+const (
+	// RelayModeFull is the relay mode of a peer that relays transactions and blocks
+	RelayModeFull = "full"
+	// RelayModeBlocksOnly is the relay mode of a peer in blocks-only mode, which is not sent transactions
+	RelayModeBlocksOnly = "blocks_only"
+)
+
 // Connection a connection's state within the daemon
 type Connection struct {
 	GnetID               uint64                 `json:"id"`
@@ -20,7 +30,17 @@ type Connection struct {
 	Height               uint64                 `json:"height"`
 	UserAgent            useragent.Data         `json:"user_agent"`
 	IsTrustedPeer        bool                   `json:"is_trusted_peer"`
+	NodePubKey           string                 `json:"node_pubkey"`
+	NodeAuthenticated    bool                   `json:"node_authenticated"`
+	RelayMode            string                 `json:"relay_mode"`
+	Compression          bool                   `json:"compression"`
+	CompressionStats     CompressionStats       `json:"compression_stats"`
 	UnconfirmedVerifyTxn VerifyTxn              `json:"unconfirmed_verify_transaction"`
+	RTT                  int64                  `json:"rtt_ms"`
+	BlocksLatency        int64                  `json:"blocks_latency_ms"`
+	BlocksResponses      uint64                 `json:"blocks_responses"`
+	BlocksFailures       uint64                 `json:"blocks_failures"`
+	Quality              float64                `json:"quality"`
 }
 
 // NewConnection copies daemon.Connection to a struct with json tags
@@ -39,6 +59,16 @@ func NewConnection(c *daemon.Connection) Connection {
 		connectedAt = c.ConnectedAt.Unix()
 	}
 
+	var nodePubKey string
+	if !c.NodePubKey.Null() {
+		nodePubKey = c.NodePubKey.Hex()
+	}
+
+	relayMode := RelayModeFull
+	if c.BlocksOnly {
+		relayMode = RelayModeBlocksOnly
+	}
+
 	return Connection{
 		GnetID:               c.Gnet.ID,
 		Addr:                 c.Addr,
@@ -51,8 +81,41 @@ func NewConnection(c *daemon.Connection) Connection {
 		ListenPort:           c.ListenPort,
 		Height:               c.Height,
 		UserAgent:            c.UserAgent,
-		IsTrustedPeer:        c.Pex.Trusted,
+		IsTrustedPeer:        c.IsTrusted(),
+		NodePubKey:           nodePubKey,
+		NodeAuthenticated:    c.NodeAuthenticated,
+		RelayMode:            relayMode,
+		Compression:          c.Gnet.Compression,
+		CompressionStats:     NewCompressionStats(c.Gnet.CompressionStats),
 		UnconfirmedVerifyTxn: NewVerifyTxn(c.UnconfirmedVerifyTxn),
+		RTT:                  int64(c.RTT / time.Millisecond),
+		BlocksLatency:        int64(c.BlocksLatency / time.Millisecond),
+		BlocksResponses:      c.BlocksResponses,
+		BlocksFailures:       c.BlocksFailures,
+		Quality:              c.Pex.QualityScore(),
+	}
+}
+
+// CompressionStats counts the bytes of the compressed messages sent and received.
+// The ratios are the compressed size relative to the uncompressed size
+type CompressionStats struct {
+	SentBytes               uint64  `json:"sent_bytes"`
+	SentCompressedBytes     uint64  `json:"sent_compressed_bytes"`
+	SentRatio               float64 `json:"sent_ratio"`
+	ReceivedBytes           uint64  `json:"received_bytes"`
+	ReceivedCompressedBytes uint64  `json:"received_compressed_bytes"`
+	ReceivedRatio           float64 `json:"received_ratio"`
+}
+
+// NewCompressionStats converts gnet.CompressionStats to CompressionStats
+func NewCompressionStats(s gnet.CompressionStats) CompressionStats {
+	return CompressionStats{
+		SentBytes:               s.SentBytes,
+		SentCompressedBytes:     s.SentCompressedBytes,
+		SentRatio:               s.SentRatio(),
+		ReceivedBytes:           s.ReceivedBytes,
+		ReceivedCompressedBytes: s.ReceivedCompressedBytes,
+		ReceivedRatio:           s.ReceivedRatio(),
 	}
 }
 
@@ -71,3 +134,154 @@ func NewVerifyTxn(p params.VerifyTxn) VerifyTxn {
 		MaxDropletPrecision: p.MaxDropletPrecision,
 	}
 }
+
+// TxnBroadcast the broadcast state of a transaction created by a local wallet
+type TxnBroadcast struct {
+	Txid           string                    `json:"txid"`
+	Status         daemon.TxnBroadcastStatus `json:"status"`
+	Broadcasts     int                       `json:"broadcasts"`
+	FirstBroadcast int64                     `json:"first_broadcast"`
+	LastBroadcast  int64                     `json:"last_broadcast"`
+	NextBroadcast  int64                     `json:"next_broadcast"`
+	AcceptedBy     []string                  `json:"accepted_by"`
+}
+
+// NewTxnBroadcast copies daemon.TxnBroadcast to a struct with json tags
+func NewTxnBroadcast(b daemon.TxnBroadcast) TxnBroadcast {
+	var nextBroadcast int64
+	if !b.NextBroadcast.IsZero() {
+		nextBroadcast = b.NextBroadcast.Unix()
+	}
+
+	acceptedBy := b.AcceptedBy
+	if acceptedBy == nil {
+		acceptedBy = []string{}
+	}
+
+	return TxnBroadcast{
+		Txid:           b.Txid.Hex(),
+		Status:         b.Status,
+		Broadcasts:     b.Broadcasts,
+		FirstBroadcast: b.FirstBroadcast.Unix(),
+		LastBroadcast:  b.LastBroadcast.Unix(),
+		NextBroadcast:  nextBroadcast,
+		AcceptedBy:     acceptedBy,
+	}
+}
+
+// NewTxnBroadcasts converts []daemon.TxnBroadcast to []TxnBroadcast
+func NewTxnBroadcasts(b []daemon.TxnBroadcast) []TxnBroadcast {
+	rb := make([]TxnBroadcast, len(b))
+	for i := range b {
+		rb[i] = NewTxnBroadcast(b[i])
+	}
+	return rb
+}
+
+// TxnRelayRejection a rejection of a transaction received from a peer
+type TxnRelayRejection struct {
+	Address string `json:"address"`
+	Reason  string `json:"reason"`
+	Soft    bool   `json:"soft"`
+	Time    int64  `json:"time"`
+}
+
+// TxnRelay the relay state of an unconfirmed transaction
+type TxnRelay struct {
+	Txid        string              `json:"txid"`
+	FirstSeen   int64               `json:"first_seen"`
+	Source      string              `json:"source"`
+	AnnouncedTo []string            `json:"announced_to"`
+	RequestedBy []string            `json:"requested_by"`
+	Rejections  []TxnRelayRejection `json:"rejections"`
+	Updated     int64               `json:"updated"`
+}
+
+// NewTxnRelay copies daemon.TxnRelay to a struct with json tags
+func NewTxnRelay(r daemon.TxnRelay) TxnRelay {
+	announcedTo := r.AnnouncedTo
+	if announcedTo == nil {
+		announcedTo = []string{}
+	}
+
+	requestedBy := r.RequestedBy
+	if requestedBy == nil {
+		requestedBy = []string{}
+	}
+
+	rejections := make([]TxnRelayRejection, len(r.Rejections))
+	for i, rj := range r.Rejections {
+		rejections[i] = TxnRelayRejection{
+			Address: rj.Addr,
+			Reason:  rj.Reason,
+			Soft:    rj.Soft,
+			Time:    rj.Time.Unix(),
+		}
+	}
+
+	return TxnRelay{
+		Txid:        r.Txid.Hex(),
+		FirstSeen:   r.FirstSeen.Unix(),
+		Source:      r.Source,
+		AnnouncedTo: announcedTo,
+		RequestedBy: requestedBy,
+		Rejections:  rejections,
+		Updated:     r.Updated.Unix(),
+	}
+}
+
+// TxnConflict an output spent by more than one transaction
+type TxnConflict struct {
+	UxID       string   `json:"uxid"`
+	Address    string   `json:"address"`
+	Txids      []string `json:"txids"`
+	Source     string   `json:"source"`
+	DetectedAt int64    `json:"detected_at"`
+	Updated    int64    `json:"updated"`
+}
+
+// NewTxnConflict copies daemon.TxnConflict to a struct with json tags
+func NewTxnConflict(c daemon.TxnConflict) TxnConflict {
+	txids := make([]string, len(c.Txids))
+	for i, txid := range c.Txids {
+		txids[i] = txid.Hex()
+	}
+
+	return TxnConflict{
+		UxID:       c.UxID.Hex(),
+		Address:    c.Address.String(),
+		Txids:      txids,
+		Source:     c.Source,
+		DetectedAt: c.DetectedAt.Unix(),
+		Updated:    c.Updated.Unix(),
+	}
+}
+
+// NewTxnConflicts copies []daemon.TxnConflict to a struct with json tags
+func NewTxnConflicts(cs []daemon.TxnConflict) []TxnConflict {
+	conflicts := make([]TxnConflict, len(cs))
+	for i, c := range cs {
+		conflicts[i] = NewTxnConflict(c)
+	}
+	return conflicts
+}
+
+// TxnConflictEvent an event emitted when a transaction conflict is detected or extended
+type TxnConflictEvent struct {
+	ID       uint64      `json:"id"`
+	Time     int64       `json:"time"`
+	Conflict TxnConflict `json:"conflict"`
+}
+
+// NewTxnConflictEvents copies []daemon.TxnConflictEvent to a struct with json tags
+func NewTxnConflictEvents(es []daemon.TxnConflictEvent) []TxnConflictEvent {
+	events := make([]TxnConflictEvent, len(es))
+	for i, e := range es {
+		events[i] = TxnConflictEvent{
+			ID:       e.ID,
+			Time:     e.Time.Unix(),
+			Conflict: NewTxnConflict(e.Conflict),
+		}
+	}
+	return events
+}
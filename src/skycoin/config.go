@@ -15,6 +15,8 @@ import (
 
 	"github.com/skycoin/skycoin/src/api"
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon/lan"
+	"github.com/skycoin/skycoin/src/daemon/pex"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/util/droplet"
@@ -44,10 +46,29 @@ type NodeConfig struct {
 	DownloadPeerList bool
 	// Download the peers list from this URL
 	PeerListURL string
+	// Hex encoded pubkey that must have signed the downloaded peers list. Unsigned peers lists are refused if set
+	PeerListPubKeyStr string
+	// A signed peers list is refused if it was signed longer ago than this. 0 disables the check
+	PeerListMaxAge time.Duration
 	// Don't make any outgoing connections
 	DisableOutgoingConnections bool
 	// Don't allowing incoming connections
 	DisableIncomingConnections bool
+	// Don't relay transactions, only blocks. Transactions submitted to this node are still broadcast
+	BlocksOnly bool
+	// Don't compress messages sent to peers
+	DisableCompression bool
+	// Messages larger than this many bytes are compressed, for peers that can decompress messages
+	CompressionThreshold int
+	// Comma separated list of minimum accepted peer versions by coin, e.g. "skycoin:0.25.0"
+	MinPeerVersions string
+	minPeerVersions []string
+	// Comma separated list of peer version ranges to prefer for outgoing connections, e.g. "skycoin:>=0.25.0"
+	PreferredPeerVersions string
+	preferredPeerVersions []string
+	// Comma separated list of known-buggy peer version ranges to disconnect, e.g. "skycoin:>=0.24.0 <0.24.2"
+	BuggyPeerVersions string
+	buggyPeerVersions []string
 	// Disables networking altogether
 	DisableNetworking bool
 	// Enable GUI
@@ -72,6 +93,14 @@ type NodeConfig struct {
 
 	// Only run on localhost and only connect to others on localhost
 	LocalhostOnly bool
+	// Discover peers on the local network with UDP multicast
+	LANDiscovery bool
+	// Multicast group and port for LAN discovery
+	LANDiscoveryAddress string
+	// Network interface for LAN discovery. If empty, the system default multicast interface is used
+	LANDiscoveryInterface string
+	// How often to announce this node on the local network
+	LANAnnounceRate time.Duration
 	// Which address to serve on. Leave blank to automatically assign to a
 	// public interface
 	Address string
@@ -148,6 +177,11 @@ type NodeConfig struct {
 	CreateBlockVerifyTxn params.VerifyTxn
 	// Maximum block size
 	MaxBlockSize uint32
+	// Maximum length of a chain of unconfirmed transactions that an unconfirmed transaction may spend outputs of.
+	// Disabled (0) by default, since nodes without support for chains of unconfirmed transactions reject them
+	MaxUnconfirmedChainDepth uint64
+	// Maximum number of unconfirmed ancestors of an unconfirmed transaction
+	MaxUnconfirmedChainAncestors uint64
 
 	unconfirmedBurnFactor          uint64
 	maxUnconfirmedTransactionSize  uint64
@@ -169,6 +203,16 @@ type NodeConfig struct {
 	CustomPeersFile string
 
 	RunBlockPublisher bool
+	// Maximum time, in seconds, that valid unconfirmed transactions wait before the block publisher creates a block
+	BlockCreationInterval uint64
+	// Minimum time between blocks created by the block publisher
+	BlockPublishMinSpacing time.Duration
+	// Size in bytes of the valid unconfirmed transactions above which the block publisher creates a block immediately.
+	// If 0, the maximum block size is used
+	BlockPublishBurstSize uint64
+	// If not 0, the block publisher creates an empty block when it did not create a block for this long.
+	// Empty blocks are only created from the empty block activation height
+	BlockPublishHeartbeat time.Duration
 
 	/* Developer options */
 
@@ -187,13 +231,30 @@ type NodeConfig struct {
 	LogToFile   bool
 	Version     bool // show node version
 
+	// Keep accepting blocks after detecting that the block publisher signed conflicting blocks
+	DisableDoubleSignHalt bool
+
 	GenesisSignatureStr string
 	GenesisAddressStr   string
 	BlockchainPubkeyStr string
+	// Deprecated: the secret key is visible in process lists and shell history. Use BlockchainKeystore
 	BlockchainSeckeyStr string
 	GenesisTimestamp    uint64
 	GenesisCoinVolume   uint64
-	DefaultConnections  []string
+
+	// Keystore file with the encrypted blockchain secret key, unlocked at startup by the block publisher
+	BlockchainKeystore string
+	// File containing the password of BlockchainKeystore. If empty, the password is read from
+	// the BLOCKCHAIN_KEYSTORE_PASSWORD environment variable or the terminal
+	BlockchainKeystorePasswordFile string
+
+	// Default "trusted" peers, of the form ip:port or pubkey@ip:port
+	DefaultConnections []string
+
+	// DefaultConnections without the pinned pubkeys
+	defaultConnectionAddrs []string
+
+	peerListPubKey cipher.PubKey
 
 	genesisSignature cipher.Sig
 	genesisAddress   cipher.Address
@@ -219,6 +280,11 @@ func NewNodeConfig(mode string, node NodeParameters) NodeConfig {
 		DisableOutgoingConnections: false,
 		// Don't allowing incoming connections
 		DisableIncomingConnections: false,
+		// Don't relay transactions, only blocks
+		BlocksOnly: false,
+		// Compress messages larger than this many bytes
+		DisableCompression:   false,
+		CompressionThreshold: 1024,
 		// Disables networking altogether
 		DisableNetworking: false,
 		// Enable GUI
@@ -231,6 +297,10 @@ func NewNodeConfig(mode string, node NodeParameters) NodeConfig {
 		DisableCSP: false,
 		// Only run on localhost and only connect to others on localhost
 		LocalhostOnly: false,
+		// LAN discovery
+		LANDiscovery:        false,
+		LANDiscoveryAddress: lan.DefaultAddress,
+		LANAnnounceRate:     time.Second * 30,
 		// Which address to serve on. Leave blank to automatically assign to a
 		// public interface
 		Address: "",
@@ -244,6 +314,7 @@ func NewNodeConfig(mode string, node NodeParameters) NodeConfig {
 		MaxDefaultPeerOutgoingConnections: 1,
 		DownloadPeerList:                  true,
 		PeerListURL:                       node.PeerListURL,
+		PeerListMaxAge:                    pex.DefaultPeerListMaxAge,
 		// How often to make outgoing connections, in seconds
 		OutgoingConnectionsRate: time.Second * 5,
 		PeerlistSize:            65535,
@@ -281,6 +352,9 @@ func NewNodeConfig(mode string, node NodeParameters) NodeConfig {
 		CreateBlockVerifyTxn: params.UserVerifyTxn,
 		MaxBlockSize:         params.UserVerifyTxn.MaxTransactionSize,
 
+		MaxUnconfirmedChainDepth:     0,
+		MaxUnconfirmedChainAncestors: 25,
+
 		// Wallets
 		WalletDirectory:  "",
 		WalletCryptoType: string(wallet.CryptoTypeScryptChacha20poly1305),
@@ -291,7 +365,11 @@ func NewNodeConfig(mode string, node NodeParameters) NodeConfig {
 		HTTPWriteTimeout: time.Second * 60,
 		HTTPIdleTimeout:  time.Second * 120,
 
-		RunBlockPublisher: false,
+		RunBlockPublisher:      false,
+		BlockCreationInterval:  10,
+		BlockPublishMinSpacing: time.Second,
+		BlockPublishBurstSize:  0,
+		BlockPublishHeartbeat:  0,
 
 		// Enable cpu profiling
 		ProfileCPU: false,
@@ -332,6 +410,7 @@ func (c *Config) postProcess() error {
 		panicIfError(err, "Invalid Pubkey")
 	}
 	if c.Node.BlockchainSeckeyStr != "" {
+		log.Println("Warning: -blockchain-secret-key is deprecated, because the secret key is visible in process lists and shell history. Use -blockchain-keystore instead")
 		c.Node.blockchainSeckey, err = cipher.SecKeyFromHex(c.Node.BlockchainSeckeyStr)
 		panicIfError(err, "Invalid Seckey")
 		c.Node.BlockchainSeckeyStr = ""
@@ -340,6 +419,19 @@ func (c *Config) postProcess() error {
 		c.Node.blockchainSeckey = cipher.SecKey{}
 	}
 
+	if c.Node.BlockchainKeystore != "" {
+		if !c.Node.RunBlockPublisher {
+			log.Println("-blockchain-keystore is ignored, because the node is not a block publisher")
+		} else {
+			if !c.Node.blockchainSeckey.Null() {
+				log.Panic("-blockchain-secret-key and -blockchain-keystore can't both be set")
+			}
+
+			c.Node.blockchainSeckey, err = unlockBlockchainKeystore(c.Node.BlockchainKeystore, c.Node.BlockchainKeystorePasswordFile, c.Node.blockchainPubkey)
+			panicIfError(err, "Unlock blockchain keystore failed")
+		}
+	}
+
 	home := file.UserHome()
 	c.Node.DataDirectory, err = file.InitDataDir(replaceHome(c.Node.DataDirectory, home))
 	panicIfError(err, "Invalid DataDirectory")
@@ -405,10 +497,35 @@ func (c *Config) postProcess() error {
 		c.Node.DefaultConnections = nil
 	}
 
+	c.Node.defaultConnectionAddrs = nil
+	for _, conn := range c.Node.DefaultConnections {
+		addr, _, err := pex.ParseTrustedPeer(conn)
+		if err != nil {
+			return fmt.Errorf("Invalid default connection %q: %v", conn, err)
+		}
+		c.Node.defaultConnectionAddrs = append(c.Node.defaultConnectionAddrs, addr)
+	}
+
+	c.Node.peerListPubKey = cipher.PubKey{}
+	if c.Node.PeerListPubKeyStr != "" {
+		c.Node.peerListPubKey, err = cipher.PubKeyFromHex(c.Node.PeerListPubKeyStr)
+		if err != nil {
+			return fmt.Errorf("Invalid -peerlist-pubkey: %v", err)
+		}
+	}
+
+	if c.Node.PeerListMaxAge < 0 {
+		return errors.New("-peerlist-max-age must not be negative")
+	}
+
 	if c.Node.HostWhitelist != "" {
 		c.Node.hostWhitelist = strings.Split(c.Node.HostWhitelist, ",")
 	}
 
+	c.Node.minPeerVersions = splitCommaString(c.Node.MinPeerVersions)
+	c.Node.preferredPeerVersions = splitCommaString(c.Node.PreferredPeerVersions)
+	c.Node.buggyPeerVersions = splitCommaString(c.Node.BuggyPeerVersions)
+
 	httpAuthEnabled := c.Node.WebInterfaceUsername != "" || c.Node.WebInterfacePassword != ""
 	if httpAuthEnabled && !c.Node.WebInterfaceHTTPS && !c.Node.WebInterfacePlaintextAuth {
 		return errors.New("Web interface auth enabled but HTTPS is not enabled. Use -web-interface-plaintext-auth=true if this is desired")
@@ -476,6 +593,10 @@ func (c *Config) postProcess() error {
 		return errors.New("-max-block-size must be >= -max-txn-size-create-block")
 	}
 
+	if c.Node.MaxUnconfirmedChainAncestors < c.Node.MaxUnconfirmedChainDepth {
+		return errors.New("-max-unconfirmed-chain-ancestors must be >= -max-unconfirmed-chain-depth")
+	}
+
 	if c.Node.UnconfirmedVerifyTxn.BurnFactor < params.MinBurnFactor {
 		return fmt.Errorf("-burn-factor-unconfirmed must be >= params.MinBurnFactor (%d)", params.MinBurnFactor)
 	}
@@ -581,8 +702,16 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.BoolVar(&c.DisablePEX, "disable-pex", c.DisablePEX, "disable PEX peer discovery")
 	flag.BoolVar(&c.DownloadPeerList, "download-peerlist", c.DownloadPeerList, "download a peers.txt from -peerlist-url")
 	flag.StringVar(&c.PeerListURL, "peerlist-url", c.PeerListURL, "with -download-peerlist=true, download a peers.txt file from this url")
+	flag.StringVar(&c.PeerListPubKeyStr, "peerlist-pubkey", c.PeerListPubKeyStr, "with -download-peerlist=true, only use a downloaded peers.txt if it was signed by this pubkey (signature at -peerlist-url + \".sig\")")
+	flag.DurationVar(&c.PeerListMaxAge, "peerlist-max-age", c.PeerListMaxAge, "with -peerlist-pubkey, refuse a downloaded peers.txt signed longer ago than this. 0 disables the check")
 	flag.BoolVar(&c.DisableOutgoingConnections, "disable-outgoing", c.DisableOutgoingConnections, "Don't make outgoing connections")
 	flag.BoolVar(&c.DisableIncomingConnections, "disable-incoming", c.DisableIncomingConnections, "Don't allow incoming connections")
+	flag.BoolVar(&c.BlocksOnly, "blocks-only", c.BlocksOnly, "Don't relay transactions from peers, only blocks. Transactions submitted to this node are still broadcast")
+	flag.BoolVar(&c.DisableCompression, "disable-compression", c.DisableCompression, "Don't compress messages sent to peers")
+	flag.IntVar(&c.CompressionThreshold, "compression-threshold", c.CompressionThreshold, "Compress messages larger than this many bytes, for peers that can decompress messages")
+	flag.StringVar(&c.MinPeerVersions, "min-peer-versions", c.MinPeerVersions, "Comma separated list of minimum peer versions by coin, in the format $COIN:$VERSION. Peers with a lower user agent version are disconnected")
+	flag.StringVar(&c.PreferredPeerVersions, "preferred-peer-versions", c.PreferredPeerVersions, "Comma separated list of peer version ranges to prefer for outgoing connections, in the format [$COIN:]$RANGE, e.g. \"skycoin:>=0.25.0\"")
+	flag.StringVar(&c.BuggyPeerVersions, "buggy-peer-versions", c.BuggyPeerVersions, "Comma separated list of known-buggy peer version ranges to disconnect, in the format [$COIN:]$RANGE, e.g. \"skycoin:>=0.24.0 <0.24.2\"")
 	flag.BoolVar(&c.DisableNetworking, "disable-networking", c.DisableNetworking, "Disable all network activity")
 	flag.BoolVar(&c.EnableGUI, "enable-gui", c.EnableGUI, "Enable GUI")
 	flag.BoolVar(&c.EnableUnversionedAPI, "enable-unversioned-api", c.EnableUnversionedAPI, "Enable the deprecated unversioned API endpoints without /api/v1 prefix")
@@ -649,10 +778,18 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.Uint64Var(&c.createBlockMaxTransactionSize, "max-txn-size-create-block", uint64(c.CreateBlockVerifyTxn.MaxTransactionSize), "maximum size of a transaction applied when creating blocks")
 	flag.Uint64Var(&c.createBlockMaxDropletPrecision, "max-decimals-create-block", uint64(c.CreateBlockVerifyTxn.MaxDropletPrecision), "max number of decimal places applied when creating blocks")
 	flag.Uint64Var(&c.maxBlockSize, "max-block-size", uint64(c.MaxBlockSize), "maximum size of a block")
+	flag.Uint64Var(&c.MaxUnconfirmedChainDepth, "max-unconfirmed-chain-depth", c.MaxUnconfirmedChainDepth, "maximum length of a chain of unconfirmed transactions that an unconfirmed transaction may spend outputs of. 0 disables spending unconfirmed outputs")
+	flag.Uint64Var(&c.MaxUnconfirmedChainAncestors, "max-unconfirmed-chain-ancestors", c.MaxUnconfirmedChainAncestors, "maximum number of unconfirmed ancestors of an unconfirmed transaction")
 
 	flag.BoolVar(&c.RunBlockPublisher, "block-publisher", c.RunBlockPublisher, "run the daemon as a block publisher")
+	flag.Uint64Var(&c.BlockCreationInterval, "block-creation-interval", c.BlockCreationInterval, "maximum time in seconds that unconfirmed transactions wait before the block publisher creates a block")
+	flag.DurationVar(&c.BlockPublishMinSpacing, "block-publish-min-spacing", c.BlockPublishMinSpacing, "minimum time between blocks created by the block publisher. Must be at least 1s")
+	flag.Uint64Var(&c.BlockPublishBurstSize, "block-publish-burst-size", c.BlockPublishBurstSize, "the block publisher creates a block immediately when the unconfirmed transactions exceed this many bytes. 0 uses -max-block-size")
+	flag.DurationVar(&c.BlockPublishHeartbeat, "block-publish-heartbeat", c.BlockPublishHeartbeat, "if not 0, the block publisher creates an empty block when it did not create a block for this long, from the empty block activation height")
 	flag.StringVar(&c.BlockchainPubkeyStr, "blockchain-public-key", c.BlockchainPubkeyStr, "public key of the blockchain")
-	flag.StringVar(&c.BlockchainSeckeyStr, "blockchain-secret-key", c.BlockchainSeckeyStr, "secret key of the blockchain")
+	flag.StringVar(&c.BlockchainSeckeyStr, "blockchain-secret-key", c.BlockchainSeckeyStr, "secret key of the blockchain. Deprecated, use -blockchain-keystore")
+	flag.StringVar(&c.BlockchainKeystore, "blockchain-keystore", c.BlockchainKeystore, "keystore file with the encrypted secret key of the blockchain, unlocked by the block publisher at startup")
+	flag.StringVar(&c.BlockchainKeystorePasswordFile, "blockchain-keystore-password-file", c.BlockchainKeystorePasswordFile, fmt.Sprintf("file containing the password of -blockchain-keystore. If not set, the password is read from the %s environment variable or the terminal", KeystorePasswordEnv))
 
 	flag.StringVar(&c.GenesisAddressStr, "genesis-address", c.GenesisAddressStr, "genesis address")
 	flag.StringVar(&c.GenesisSignatureStr, "genesis-signature", c.GenesisSignatureStr, "genesis block signature")
@@ -665,7 +802,12 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.IntVar(&c.PeerlistSize, "peerlist-size", c.PeerlistSize, "Max number of peers to track in peerlist")
 	flag.DurationVar(&c.OutgoingConnectionsRate, "connection-rate", c.OutgoingConnectionsRate, "How often to make an outgoing connection")
 	flag.BoolVar(&c.LocalhostOnly, "localhost-only", c.LocalhostOnly, "Run on localhost and only connect to localhost peers")
+	flag.BoolVar(&c.LANDiscovery, "lan-discovery", c.LANDiscovery, "Discover peers on the local network with UDP multicast. Discovered peers are added as private peers")
+	flag.StringVar(&c.LANDiscoveryAddress, "lan-discovery-address", c.LANDiscoveryAddress, "Multicast group and port for -lan-discovery")
+	flag.StringVar(&c.LANDiscoveryInterface, "lan-discovery-interface", c.LANDiscoveryInterface, "Network interface for -lan-discovery, e.g. \"eth0\", or \"lo\" for nodes on this host. Defaults to the system default multicast interface")
+	flag.DurationVar(&c.LANAnnounceRate, "lan-announce-rate", c.LANAnnounceRate, "How often to announce this node on the local network with -lan-discovery")
 	flag.BoolVar(&c.Arbitrating, "arbitrating", c.Arbitrating, "Run node in arbitrating mode")
+	flag.BoolVar(&c.DisableDoubleSignHalt, "disable-double-sign-halt", c.DisableDoubleSignHalt, "Keep accepting blocks after detecting that the block publisher signed two different blocks at the same seq")
 	flag.StringVar(&c.WalletCryptoType, "wallet-crypto-type", c.WalletCryptoType, "wallet crypto type. Can be sha256-xor or scrypt-chacha20poly1305")
 	flag.BoolVar(&c.Version, "version", false, "show node version")
 }
@@ -694,6 +836,18 @@ func (c *NodeConfig) applyConfigMode(configMode string) {
 	}
 }
 
+// splitCommaString splits a comma separated string, trimming whitespace and skipping empty values
+func splitCommaString(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func panicIfError(err error, msg string, args ...interface{}) { // nolint: unparam
 	if err != nil {
 		log.Panicf(msg+": %v", append(args, err)...)
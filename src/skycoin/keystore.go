@@ -0,0 +1,216 @@
+package skycoin
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encrypt"
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+const (
+	// KeystoreVersion is the version of the keystore file format
+	KeystoreVersion = "1"
+	// KeystoreCryptoType is the encryption method of keystore files
+	KeystoreCryptoType = "scrypt-chacha20poly1305"
+	// KeystorePasswordEnv is the environment variable that the keystore password is read from,
+	// if no password file is specified. It is cleared once read
+	KeystorePasswordEnv = "BLOCKCHAIN_KEYSTORE_PASSWORD"
+)
+
+var (
+	// ErrKeystoreInvalidPassword is returned when a keystore can't be decrypted with the password
+	ErrKeystoreInvalidPassword = errors.New("keystore password is invalid")
+	// ErrKeystoreMissingPassword is returned when no keystore password is available
+	ErrKeystoreMissingPassword = fmt.Errorf("keystore password is required. Set it with a password file, the %s environment variable, or enter it in a terminal", KeystorePasswordEnv)
+
+	// keystoreCryptor encrypts keystores. Tests override it with cheaper scrypt parameters
+	keystoreCryptor = encrypt.DefaultScryptChacha20poly1305
+)
+
+// Keystore is a secret key encrypted with a password, saved as a JSON file.
+// It lets the block publisher's secret key be stored without exposing it on the command line
+type Keystore struct {
+	Version    string `json:"version"`
+	CryptoType string `json:"crypto_type"`
+	// Pubkey of the encrypted secret key, to identify the keystore without unlocking it
+	PubKey string `json:"pubkey"`
+	// Secret key encrypted with scrypt-chacha20poly1305
+	Encrypted string `json:"encrypted"`
+}
+
+// NewKeystore encrypts a secret key with a password
+func NewKeystore(sk cipher.SecKey, password []byte) (*Keystore, error) {
+	pk, err := cipher.PubKeyFromSecKey(sk)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := keystoreCryptor.Encrypt(sk[:], password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keystore{
+		Version:    KeystoreVersion,
+		CryptoType: KeystoreCryptoType,
+		PubKey:     pk.Hex(),
+		Encrypted:  string(encrypted),
+	}, nil
+}
+
+// LoadKeystore loads a keystore file
+func LoadKeystore(filename string) (*Keystore, error) {
+	var ks Keystore
+	if err := file.LoadJSON(filename, &ks); err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid keystore file %s: %v", filename, err)
+	}
+
+	if ks.Version != KeystoreVersion {
+		return nil, fmt.Errorf("invalid keystore file %s: unsupported version %q", filename, ks.Version)
+	}
+
+	if ks.CryptoType != KeystoreCryptoType {
+		return nil, fmt.Errorf("invalid keystore file %s: unsupported crypto type %q", filename, ks.CryptoType)
+	}
+
+	if _, err := cipher.PubKeyFromHex(ks.PubKey); err != nil {
+		return nil, fmt.Errorf("invalid keystore file %s: invalid pubkey: %v", filename, err)
+	}
+
+	return &ks, nil
+}
+
+// Save saves the keystore to a file readable only by the owner. An existing file is not overwritten
+func (ks *Keystore) Save(filename string) error {
+	return file.SaveJSONSafe(filename, ks, 0600)
+}
+
+// Unlock decrypts the secret key with a password.
+// Returns ErrKeystoreInvalidPassword if the password is wrong
+func (ks *Keystore) Unlock(password []byte) (cipher.SecKey, error) {
+	pk, err := cipher.PubKeyFromHex(ks.PubKey)
+	if err != nil {
+		return cipher.SecKey{}, err
+	}
+
+	if len(password) == 0 {
+		return cipher.SecKey{}, ErrKeystoreMissingPassword
+	}
+
+	b, err := keystoreCryptor.Decrypt([]byte(ks.Encrypted), password)
+	if err != nil {
+		return cipher.SecKey{}, ErrKeystoreInvalidPassword
+	}
+
+	sk, err := cipher.NewSecKey(b)
+	if err != nil {
+		return cipher.SecKey{}, err
+	}
+
+	if err := cipher.CheckSecKey(sk); err != nil {
+		return cipher.SecKey{}, err
+	}
+
+	if skPubKey := cipher.MustPubKeyFromSecKey(sk); skPubKey != pk {
+		return cipher.SecKey{}, errors.New("keystore secret key does not match its pubkey")
+	}
+
+	return sk, nil
+}
+
+// ReadKeystorePassword reads a keystore password from passwordFile if it is not empty,
+// otherwise from the KeystorePasswordEnv environment variable,
+// otherwise from the terminal if stdin is a terminal.
+// Trailing newlines are removed from the content of the password file.
+// The KeystorePasswordEnv environment variable is cleared after it is read,
+// so that the password is not inherited by child processes or exposed in the process environment
+func ReadKeystorePassword(passwordFile string) ([]byte, error) {
+	if passwordFile != "" {
+		b, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return nil, err
+		}
+
+		password := strings.TrimRight(string(b), "\r\n")
+		if password == "" {
+			return nil, fmt.Errorf("password file %s is empty", passwordFile)
+		}
+		return []byte(password), nil
+	}
+
+	if password := os.Getenv(KeystorePasswordEnv); password != "" {
+		if err := os.Unsetenv(KeystorePasswordEnv); err != nil {
+			return nil, err
+		}
+		return []byte(password), nil
+	}
+
+	if !terminal.IsTerminal(int(syscall.Stdin)) { // nolint: unconvert
+		return nil, ErrKeystoreMissingPassword
+	}
+
+	return promptPassword("Enter the keystore password: ")
+}
+
+// PromptNewKeystorePassword reads a new keystore password from the terminal, asking for it twice
+func PromptNewKeystorePassword() ([]byte, error) {
+	if !terminal.IsTerminal(int(syscall.Stdin)) { // nolint: unconvert
+		return nil, ErrKeystoreMissingPassword
+	}
+
+	password, err := promptPassword("Enter a password for the keystore: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(password) == 0 {
+		return nil, errors.New("password is empty")
+	}
+
+	confirm, err := promptPassword("Confirm the password: ")
+	if err != nil {
+		return nil, err
+	}
+
+	if string(password) != string(confirm) {
+		return nil, errors.New("passwords do not match")
+	}
+
+	return password, nil
+}
+
+// unlockBlockchainKeystore loads and unlocks the blockchain keystore file, checking that it holds the secret key of the blockchain pubkey
+func unlockBlockchainKeystore(filename, passwordFile string, pubkey cipher.PubKey) (cipher.SecKey, error) {
+	ks, err := LoadKeystore(replaceHome(filename, file.UserHome()))
+	if err != nil {
+		return cipher.SecKey{}, err
+	}
+
+	if ks.PubKey != pubkey.Hex() {
+		return cipher.SecKey{}, fmt.Errorf("keystore pubkey %s does not match the blockchain pubkey %s", ks.PubKey, pubkey.Hex())
+	}
+
+	password, err := ReadKeystorePassword(passwordFile)
+	if err != nil {
+		return cipher.SecKey{}, err
+	}
+
+	return ks.Unlock(password)
+}
+
+func promptPassword(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := terminal.ReadPassword(int(syscall.Stdin)) // nolint: unconvert
+	fmt.Fprintln(os.Stderr, "")
+	return password, err
+}
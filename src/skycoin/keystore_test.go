@@ -0,0 +1,141 @@
+package skycoin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encrypt"
+)
+
+func useTestKeystoreCryptor() func() {
+	orig := keystoreCryptor
+	keystoreCryptor = encrypt.ScryptChacha20poly1305{
+		N:      1 << 10,
+		R:      8,
+		P:      1,
+		KeyLen: 32,
+	}
+	return func() {
+		keystoreCryptor = orig
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	defer useTestKeystoreCryptor()()
+
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pk, sk := cipher.GenerateKeyPair()
+	password := []byte("pwd")
+
+	_, err = NewKeystore(sk, nil)
+	require.Error(t, err)
+
+	ks, err := NewKeystore(sk, password)
+	require.NoError(t, err)
+	require.Equal(t, KeystoreVersion, ks.Version)
+	require.Equal(t, KeystoreCryptoType, ks.CryptoType)
+	require.Equal(t, pk.Hex(), ks.PubKey)
+	require.NotContains(t, ks.Encrypted, sk.Hex())
+
+	fn := filepath.Join(dir, "blockchain.keystore")
+	require.NoError(t, ks.Save(fn))
+
+	fi, err := os.Stat(fn)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// An existing keystore is not overwritten
+	require.Error(t, ks.Save(fn))
+
+	loaded, err := LoadKeystore(fn)
+	require.NoError(t, err)
+	require.Equal(t, ks, loaded)
+
+	unlocked, err := loaded.Unlock(password)
+	require.NoError(t, err)
+	require.Equal(t, sk, unlocked)
+
+	_, err = loaded.Unlock([]byte("wrong"))
+	require.Equal(t, ErrKeystoreInvalidPassword, err)
+
+	_, err = loaded.Unlock(nil)
+	require.Equal(t, ErrKeystoreMissingPassword, err)
+
+	// The pubkey must match the encrypted secret key
+	otherPubKey, _ := cipher.GenerateKeyPair()
+	mismatched := *loaded
+	mismatched.PubKey = otherPubKey.Hex()
+	_, err = mismatched.Unlock(password)
+	require.EqualError(t, err, "keystore secret key does not match its pubkey")
+
+	_, err = LoadKeystore(filepath.Join(dir, "missing.keystore"))
+	require.True(t, os.IsNotExist(err))
+
+	badVersion := filepath.Join(dir, "bad-version.keystore")
+	require.NoError(t, ioutil.WriteFile(badVersion, []byte(`{"version":"2","crypto_type":"scrypt-chacha20poly1305","pubkey":"`+pk.Hex()+`"}`), 0600))
+	_, err = LoadKeystore(badVersion)
+	require.EqualError(t, err, "invalid keystore file "+badVersion+": unsupported version \"2\"")
+}
+
+func TestUnlockBlockchainKeystore(t *testing.T) {
+	defer useTestKeystoreCryptor()()
+
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pk, sk := cipher.GenerateKeyPair()
+
+	ks, err := NewKeystore(sk, []byte("pwd"))
+	require.NoError(t, err)
+	fn := filepath.Join(dir, "blockchain.keystore")
+	require.NoError(t, ks.Save(fn))
+
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(passwordFile, []byte("pwd\n"), 0600))
+
+	unlocked, err := unlockBlockchainKeystore(fn, passwordFile, pk)
+	require.NoError(t, err)
+	require.Equal(t, sk, unlocked)
+
+	// The password is read from the environment if there is no password file
+	origEnv, hadEnv := os.LookupEnv(KeystorePasswordEnv)
+	defer func() {
+		if hadEnv {
+			os.Setenv(KeystorePasswordEnv, origEnv) // nolint: errcheck
+		} else {
+			os.Unsetenv(KeystorePasswordEnv) // nolint: errcheck
+		}
+	}()
+
+	require.NoError(t, os.Setenv(KeystorePasswordEnv, "pwd"))
+	unlocked, err = unlockBlockchainKeystore(fn, "", pk)
+	require.NoError(t, err)
+	require.Equal(t, sk, unlocked)
+
+	// The environment variable is cleared once read
+	_, ok := os.LookupEnv(KeystorePasswordEnv)
+	require.False(t, ok)
+
+	require.NoError(t, os.Setenv(KeystorePasswordEnv, "wrong"))
+	_, err = unlockBlockchainKeystore(fn, "", pk)
+	require.Equal(t, ErrKeystoreInvalidPassword, err)
+
+	// The keystore must hold the secret key of the blockchain pubkey
+	otherPubKey, _ := cipher.GenerateKeyPair()
+	_, err = unlockBlockchainKeystore(fn, passwordFile, otherPubKey)
+	require.EqualError(t, err, "keystore pubkey "+pk.Hex()+" does not match the blockchain pubkey "+otherPubKey.Hex())
+
+	emptyPasswordFile := filepath.Join(dir, "empty")
+	require.NoError(t, ioutil.WriteFile(emptyPasswordFile, []byte("\n"), 0600))
+	_, err = unlockBlockchainKeystore(fn, emptyPasswordFile, pk)
+	require.EqualError(t, err, "password file "+emptyPasswordFile+" is empty")
+}
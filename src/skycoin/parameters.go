@@ -33,7 +33,8 @@ type NodeParameters struct {
 	GenesisTimestamp uint64 `mapstructure:"genesis_timestamp"`
 	// GenesisCoinVolume is the total number of coins in the genesis block
 	GenesisCoinVolume uint64 `mapstructure:"genesis_coin_volume"`
-	// DefaultConnections are the default "trusted" connections a node will try to connect to for bootstrapping
+	// DefaultConnections are the default "trusted" connections a node will try to connect to for bootstrapping.
+	// A connection of the form pubkey@ip:port is only trusted if the peer proves it owns the node pubkey
 	DefaultConnections []string `mapstructure:"default_connections"`
 	// PeerlistURL is a URL pointing to a newline-separated list of ip:ports that are used for bootstrapping (but they are not "trusted")
 	PeerListURL string `mapstructure:"peer_list_url"`
@@ -83,6 +84,16 @@ type ParamsParameters struct {
 	DistributionAddresses []string `mapstructure:"distribution_addresses"`
 	// UserBurnFactor inverse fraction of coinhours that must be burned, this value is used when creating transactions
 	UserBurnFactor uint64 `mapstructure:"user_burn_factor"`
+	// SchnorrActivationHeight is the block seq from which transactions with Schnorr signatures are accepted
+	SchnorrActivationHeight uint64 `mapstructure:"schnorr_activation_height"`
+	// ReplayProtectionActivationHeight is the block seq from which replay protected transactions are accepted
+	ReplayProtectionActivationHeight uint64 `mapstructure:"replay_protection_activation_height"`
+	// HTLCActivationHeight is the block seq from which transactions spending HTLC outputs are accepted
+	HTLCActivationHeight uint64 `mapstructure:"htlc_activation_height"`
+	// EmptyBlockActivationHeight is the block seq from which blocks without transactions are accepted
+	EmptyBlockActivationHeight uint64 `mapstructure:"empty_block_activation_height"`
+	// AddressVersion is the version byte of the coin's addresses
+	AddressVersion uint8 `mapstructure:"address_version"`
 }
 
 // NewParameters loads blockchain config parameters from a config file
@@ -148,4 +159,9 @@ func setDefaults() {
 	viper.SetDefault("params.user_max_decimals", 3)
 	viper.SetDefault("params.user_burn_factor", 2)
 	viper.SetDefault("params.user_max_transaction_size", 32*1024)
+	viper.SetDefault("params.schnorr_activation_height", 0)
+	viper.SetDefault("params.replay_protection_activation_height", 0)
+	viper.SetDefault("params.htlc_activation_height", 0)
+	viper.SetDefault("params.empty_block_activation_height", 0)
+	viper.SetDefault("params.address_version", 0)
 }
@@ -39,14 +39,19 @@ func TestNewParameters(t *testing.T) {
 			MaxBlockSize:                   1111,
 		},
 		Params: ParamsParameters{
-			MaxCoinSupply:              1e8,
-			DistributionAddressesTotal: 100,
-			InitialUnlockedCount:       25,
-			UnlockAddressRate:          5,
-			UnlockTimeInterval:         60 * 60 * 24 * 365,
-			UserBurnFactor:             3,
-			UserMaxTransactionSize:     999,
-			UserMaxDropletPrecision:    2,
+			MaxCoinSupply:                    1e8,
+			DistributionAddressesTotal:       100,
+			InitialUnlockedCount:             25,
+			UnlockAddressRate:                5,
+			UnlockTimeInterval:               60 * 60 * 24 * 365,
+			UserBurnFactor:                   3,
+			UserMaxTransactionSize:           999,
+			UserMaxDropletPrecision:          2,
+			SchnorrActivationHeight:          1000,
+			ReplayProtectionActivationHeight: 2000,
+			HTLCActivationHeight:             3000,
+			EmptyBlockActivationHeight:       4000,
+			AddressVersion:                   7,
 		},
 	}, coinConfig)
 }
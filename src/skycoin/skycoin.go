@@ -347,25 +347,38 @@ func (c *Coin) initLogFile() (*os.File, error) {
 func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc := daemon.NewConfig()
 
-	dc.Pool.DefaultConnections = c.config.Node.DefaultConnections
+	dc.Pool.DefaultConnections = c.config.Node.defaultConnectionAddrs
 	dc.Pool.MaxDefaultPeerOutgoingConnections = c.config.Node.MaxDefaultPeerOutgoingConnections
+	dc.Pool.CompressionThreshold = c.config.Node.CompressionThreshold
 
 	dc.Pex.DataDirectory = c.config.Node.DataDirectory
 	dc.Pex.Disabled = c.config.Node.DisablePEX
 	dc.Pex.Max = c.config.Node.PeerlistSize
 	dc.Pex.DownloadPeerList = c.config.Node.DownloadPeerList
 	dc.Pex.PeerListURL = c.config.Node.PeerListURL
+	dc.Pex.PeerListPubKey = c.config.Node.peerListPubKey
+	dc.Pex.PeerListMaxAge = c.config.Node.PeerListMaxAge
 	dc.Pex.DisableTrustedPeers = c.config.Node.DisableDefaultPeers
 	dc.Pex.CustomPeersFile = c.config.Node.CustomPeersFile
 	dc.Pex.DefaultConnections = c.config.Node.DefaultConnections
 
-	dc.Daemon.DefaultConnections = c.config.Node.DefaultConnections
+	dc.Daemon.DefaultConnections = c.config.Node.defaultConnectionAddrs
 	dc.Daemon.DisableOutgoingConnections = c.config.Node.DisableOutgoingConnections
 	dc.Daemon.DisableIncomingConnections = c.config.Node.DisableIncomingConnections
 	dc.Daemon.DisableNetworking = c.config.Node.DisableNetworking
+	dc.Daemon.BlocksOnly = c.config.Node.BlocksOnly
+	dc.Daemon.DisableCompression = c.config.Node.DisableCompression
+	dc.Daemon.MinPeerVersions = c.config.Node.minPeerVersions
+	dc.Daemon.PreferredPeerVersions = c.config.Node.preferredPeerVersions
+	dc.Daemon.BuggyPeerVersions = c.config.Node.buggyPeerVersions
 	dc.Daemon.Port = c.config.Node.Port
 	dc.Daemon.Address = c.config.Node.Address
 	dc.Daemon.LocalhostOnly = c.config.Node.LocalhostOnly
+
+	dc.LAN.Enabled = c.config.Node.LANDiscovery
+	dc.LAN.Address = c.config.Node.LANDiscoveryAddress
+	dc.LAN.Interface = c.config.Node.LANDiscoveryInterface
+	dc.LAN.AnnounceRate = c.config.Node.LANAnnounceRate
 	dc.Daemon.MaxConnections = c.config.Node.MaxConnections
 	dc.Daemon.MaxOutgoingConnections = c.config.Node.MaxOutgoingConnections
 	dc.Daemon.DataDirectory = c.config.Node.DataDirectory
@@ -379,6 +392,10 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	}
 	dc.Daemon.OutgoingRate = c.config.Node.OutgoingConnectionsRate
 	dc.Visor.IsBlockPublisher = c.config.Node.RunBlockPublisher
+	dc.Daemon.BlockCreationInterval = c.config.Node.BlockCreationInterval
+	dc.Daemon.BlockPublishMinSpacing = c.config.Node.BlockPublishMinSpacing
+	dc.Daemon.BlockPublishBurstSize = c.config.Node.BlockPublishBurstSize
+	dc.Daemon.BlockPublishHeartbeat = c.config.Node.BlockPublishHeartbeat
 
 	dc.Visor.BlockchainPubkey = c.config.Node.blockchainPubkey
 	dc.Visor.BlockchainSeckey = c.config.Node.blockchainSeckey
@@ -386,6 +403,8 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc.Visor.UnconfirmedVerifyTxn = c.config.Node.UnconfirmedVerifyTxn
 	dc.Visor.CreateBlockVerifyTxn = c.config.Node.CreateBlockVerifyTxn
 	dc.Visor.MaxBlockSize = c.config.Node.MaxBlockSize
+	dc.Visor.MaxUnconfirmedChainDepth = c.config.Node.MaxUnconfirmedChainDepth
+	dc.Visor.MaxUnconfirmedChainAncestors = c.config.Node.MaxUnconfirmedChainAncestors
 
 	dc.Visor.GenesisAddress = c.config.Node.genesisAddress
 	dc.Visor.GenesisSignature = c.config.Node.genesisSignature
@@ -393,6 +412,7 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc.Visor.GenesisCoinVolume = c.config.Node.GenesisCoinVolume
 	dc.Visor.DBPath = c.config.Node.DBPath
 	dc.Visor.Arbitrating = c.config.Node.Arbitrating
+	dc.Visor.DisableDoubleSignHalt = c.config.Node.DisableDoubleSignHalt
 	dc.Visor.WalletDirectory = c.config.Node.WalletDirectory
 	_, dc.Visor.EnableWalletAPI = c.config.Node.enabledAPISets[api.EndpointsWallet]
 	_, dc.Visor.EnableSeedAPI = c.config.Node.enabledAPISets[api.EndpointsInsecureWalletSeed]
@@ -17,7 +17,7 @@ import (
 )
 
 // PrepareDB creates and opens a temporary test DB and returns it with a cleanup callback
-func PrepareDB(t *testing.T) (*dbutil.DB, func()) {
+func PrepareDB(t testing.TB) (*dbutil.DB, func()) {
 	f, err := ioutil.TempFile("", "testdb")
 	require.NoError(t, err)
 
@@ -25,6 +25,10 @@ const (
 var (
 	// ErrVerifyStopped is returned when database verification is interrupted
 	ErrVerifyStopped = errors.New("database verification stopped")
+	// ErrBlockPrevHashMismatch is returned when a block's PrevHash is not the hash of the head block
+	ErrBlockPrevHashMismatch = errors.New("PrevHash does not match current head")
+	// ErrEmptyBlockNotActivated is returned when creating a block without transactions before the empty block activation height
+	ErrEmptyBlockNotActivated = errors.New("Blocks without transactions are not accepted before the empty block activation height")
 )
 
 // ErrBlockNotExist may be returned if a block is not found
@@ -98,6 +102,11 @@ func CreateBuckets(db *dbutil.DB) error {
 		return dbutil.CreateBuckets(tx, [][]byte{
 			UnconfirmedTxnsBkt,
 			UnconfirmedUnspentsBkt,
+			UnconfirmedUxOutsBkt,
+			UnconfirmedSpentBkt,
+			UnconfirmedAddrIncomingBkt,
+			UnconfirmedAddrOutgoingBkt,
+			DoubleSignProofsBkt,
 		})
 	})
 }
@@ -116,6 +125,14 @@ type Blockchain struct {
 	db    *dbutil.DB
 	cfg   BlockchainConfig
 	store chainStore
+	// caches the genesis block hash. Shared by copies of the Blockchain, since most methods have value receivers
+	genesisHash *genesisHashCache
+}
+
+// genesisHashCache caches the hash of the genesis block, which never changes once the genesis block is executed
+type genesisHashCache struct {
+	sync.RWMutex
+	hash *cipher.SHA256
 }
 
 // NewBlockchain creates a Blockchain
@@ -126,9 +143,10 @@ func NewBlockchain(db *dbutil.DB, cfg BlockchainConfig) (*Blockchain, error) {
 	}
 
 	return &Blockchain{
-		cfg:   cfg,
-		db:    db,
-		store: chainstore,
+		cfg:         cfg,
+		db:          db,
+		store:       chainstore,
+		genesisHash: &genesisHashCache{},
 	}, nil
 }
 
@@ -231,6 +249,38 @@ func (bc Blockchain) NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime
 	return b, nil
 }
 
+// NewEmptyBlock creates a Block without transactions, used as a heartbeat by the block publisher.
+// Returns ErrEmptyBlockNotActivated if the block would be before params.EmptyBlockActivationHeight
+func (bc Blockchain) NewEmptyBlock(tx *dbutil.Tx, currentTime uint64) (*coin.Block, error) {
+	head, err := bc.store.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if head.Seq()+1 < params.EmptyBlockActivationHeight {
+		return nil, ErrEmptyBlockNotActivated
+	}
+
+	if currentTime <= head.Time() {
+		return nil, errors.New("Time can only move forward")
+	}
+
+	uxHash, err := bc.Unspent().GetUxHash(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	b := coin.NewEmptyBlock(head.Block, currentTime, uxHash)
+
+	// make sure block is valid
+	if DebugLevel2 {
+		if err := bc.verifyBlockHeader(tx, *b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
 func (bc *Blockchain) processBlock(tx *dbutil.Tx, b coin.SignedBlock) (coin.SignedBlock, error) {
 	length, err := bc.Len(tx)
 	if err != nil {
@@ -249,12 +299,15 @@ func (bc *Blockchain) processBlock(tx *dbutil.Tx, b coin.SignedBlock) (coin.Sign
 				return coin.SignedBlock{}, err
 			}
 
-			txns, err := bc.processTransactions(tx, b.Body.Transactions)
-			if err != nil {
-				return coin.SignedBlock{}, err
-			}
+			// Blocks without transactions are accepted from the empty block activation height
+			if len(b.Body.Transactions) != 0 || b.Head.BkSeq < params.EmptyBlockActivationHeight {
+				txns, err := bc.processTransactions(tx, b.Body.Transactions)
+				if err != nil {
+					return coin.SignedBlock{}, err
+				}
 
-			b.Body.Transactions = txns
+				b.Body.Transactions = txns
+			}
 
 			if err := bc.verifyUxHash(tx, b.Block); err != nil {
 				return coin.SignedBlock{}, err
@@ -308,6 +361,38 @@ func (bc Blockchain) isGenesisBlock(tx *dbutil.Tx, b coin.Block) (bool, error) {
 	return gb.HashHeader() == b.HashHeader(), nil
 }
 
+// GenesisHash returns the hash of the genesis block, which the signatures of replay protected transactions commit to.
+// The hash is cached the first time it is read from the database
+func (bc Blockchain) GenesisHash(tx *dbutil.Tx) (cipher.SHA256, error) {
+	if bc.genesisHash != nil {
+		bc.genesisHash.RLock()
+		hash := bc.genesisHash.hash
+		bc.genesisHash.RUnlock()
+
+		if hash != nil {
+			return *hash, nil
+		}
+	}
+
+	gb, err := bc.store.GetGenesisBlock(tx)
+	if err != nil {
+		return cipher.SHA256{}, err
+	}
+	if gb == nil {
+		return cipher.SHA256{}, errors.New("Genesis block not found")
+	}
+
+	hash := gb.HashHeader()
+
+	if bc.genesisHash != nil {
+		bc.genesisHash.Lock()
+		bc.genesisHash.hash = &hash
+		bc.genesisHash.Unlock()
+	}
+
+	return hash, nil
+}
+
 // Compares the state of the current UxHash hash to state of unspent
 // output pool.
 func (bc Blockchain) verifyUxHash(tx *dbutil.Tx, b coin.Block) error {
@@ -347,7 +432,12 @@ func (bc Blockchain) VerifyBlockTxnConstraints(tx *dbutil.Tx, txn coin.Transacti
 }
 
 func (bc Blockchain) verifyBlockTxnHardConstraints(tx *dbutil.Tx, txn coin.Transaction, head *coin.SignedBlock, uxIn coin.UxArray) error {
-	if err := VerifyBlockTxnConstraints(txn, head.Head, uxIn); err != nil {
+	gbHash, err := bc.GenesisHash(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := VerifyBlockTxnConstraints(txn, head.Head, uxIn, gbHash); err != nil {
 		return err
 	}
 
@@ -424,7 +514,12 @@ func (bc Blockchain) VerifySingleTxnSoftHardConstraints(tx *dbutil.Tx, txn coin.
 }
 
 func (bc Blockchain) verifySingleTxnHardConstraints(tx *dbutil.Tx, txn coin.Transaction, head *coin.SignedBlock, uxIn coin.UxArray) error {
-	if err := VerifySingleTxnHardConstraints(txn, head.Head, uxIn); err != nil {
+	gbHash, err := bc.GenesisHash(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := VerifySingleTxnHardConstraints(txn, head.Head, uxIn, gbHash); err != nil {
 		return err
 	}
 
@@ -836,7 +931,7 @@ func (bc Blockchain) verifyBlockHeader(tx *dbutil.Tx, b coin.Block) error {
 	}
 	// Check block hash against previous head
 	if b.Head.PrevHash != head.HashHeader() {
-		return errors.New("PrevHash does not match current head")
+		return ErrBlockPrevHashMismatch
 	}
 	if b.HashBody() != b.Head.BodyHash {
 		return errors.New("Computed body hash does not match")
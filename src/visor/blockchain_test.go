@@ -8,6 +8,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/visor/blockdb"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
@@ -253,6 +254,32 @@ func TestIsGenesisBlock(t *testing.T) {
 	}
 }
 
+func TestGenesisHash(t *testing.T) {
+	bs := makeBlocks(t, 2)
+	store := &fakeChainStore{}
+	bc := &Blockchain{
+		store:       store,
+		genesisHash: &genesisHashCache{},
+	}
+
+	// The genesis block is not found in an empty chain
+	_, err := bc.GenesisHash(nil)
+	require.EqualError(t, err, "Genesis block not found")
+
+	store.blocks = bs
+	hash, err := bc.GenesisHash(nil)
+	require.NoError(t, err)
+	require.Equal(t, bs[0].HashHeader(), hash)
+
+	// The hash is cached, the store is not read again.
+	// Copies of the Blockchain share the cache
+	store.blocks = nil
+	bcCopy := *bc
+	hash, err = bcCopy.GenesisHash(nil)
+	require.NoError(t, err)
+	require.Equal(t, bs[0].HashHeader(), hash)
+}
+
 func TestVerifyBlockHeader(t *testing.T) {
 	bs := makeBlocks(t, 5)
 	tt := []struct {
@@ -303,7 +330,7 @@ func TestVerifyBlockHeader(t *testing.T) {
 				},
 			},
 
-			errors.New("PrevHash does not match current head"),
+			ErrBlockPrevHashMismatch,
 		},
 		{
 			"empty blockchain",
@@ -590,7 +617,7 @@ func TestProcessTransactions(t *testing.T) {
 					TxIndex: 0,
 					UxIndex: 0,
 					Keys:    []cipher.SecKey{genSecret},
-					ToAddr:  toAddrs[0],
+					ToAddr:  toAddrs[1],
 					Coins:   10e6,
 				},
 			},
@@ -811,6 +838,87 @@ func TestProcessBlock(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// headChainStore is a chainStore with a fake head block, to build blocks at any seq on top of a real store
+type headChainStore struct {
+	chainStore
+	head *coin.SignedBlock
+}
+
+func (s *headChainStore) Head(tx *dbutil.Tx) (*coin.SignedBlock, error) {
+	return s.head, nil
+}
+
+func TestEmptyBlock(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	err := CreateBuckets(db)
+	require.NoError(t, err)
+
+	store, err := blockdb.NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	bc := &Blockchain{
+		db:    db,
+		store: store,
+	}
+
+	gb := addGenesisBlockToBlockchain(t, bc)
+	uxHash := getUxHash(t, db, bc)
+
+	cases := []struct {
+		name       string
+		headSeq    uint64
+		processErr error
+		newErr     error
+	}{
+		{
+			name:       "before the activation height",
+			headSeq:    params.EmptyBlockActivationHeight - 2,
+			processErr: errors.New("No transactions"),
+			newErr:     ErrEmptyBlockNotActivated,
+		},
+		{
+			name:    "at the activation height",
+			headSeq: params.EmptyBlockActivationHeight - 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			head := *gb
+			head.Head.BkSeq = tc.headSeq
+			bc.store = &headChainStore{
+				chainStore: store,
+				head:       &head,
+			}
+
+			b := coin.NewEmptyBlock(head.Block, genTime+100, uxHash)
+			err := db.View("", func(tx *dbutil.Tx) error {
+				_, err := bc.processBlock(tx, coin.SignedBlock{
+					Block: *b,
+					Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+				})
+				return err
+			})
+			require.Equal(t, tc.processErr, err)
+
+			var nb *coin.Block
+			err = db.View("", func(tx *dbutil.Tx) error {
+				var err error
+				nb, err = bc.NewEmptyBlock(tx, genTime+100)
+				return err
+			})
+			require.Equal(t, tc.newErr, err)
+			if tc.newErr != nil {
+				return
+			}
+
+			require.Equal(t, b, nb)
+		})
+	}
+}
+
 func TestExecuteBlock(t *testing.T) {
 	db, closeDB := prepareDB(t)
 	defer closeDB()
@@ -497,10 +497,260 @@ func TestVerifyTxnFeeCoinHoursAdditionFails(t *testing.T) {
 	// uxIn.CoinHours() errors, which is ignored by VerifyTransactionHoursSpending if the error
 	// is because of the earned hours addition overflow
 	head.Block.Head.Time += 1e6
-	err = VerifySingleTxnHardConstraints(txn, head.Head, uxIn)
+	err = VerifySingleTxnHardConstraints(txn, head.Head, uxIn, gb.HashHeader())
 	testutil.RequireError(t, err, NewErrTxnViolatesHardConstraint(coinHoursErr).Error())
 }
 
+func TestVerifyHTLCTxnHardConstraints(t *testing.T) {
+	recipientPubKey, recipientSecKey := cipher.GenerateKeyPair()
+	senderPubKey, senderSecKey := cipher.GenerateKeyPair()
+	preimage := testutil.RandSHA256(t)
+
+	h := coin.HTLC{
+		Hashlock:   cipher.SumSHA256(preimage[:]),
+		Recipient:  cipher.AddressFromPubKey(recipientPubKey),
+		Sender:     cipher.AddressFromPubKey(senderPubKey),
+		LockHeight: params.HTLCActivationHeight + 10,
+	}
+
+	ux := coin.UxOut{
+		Head: coin.UxHead{
+			Time:  100,
+			BkSeq: 2,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        h.Address(),
+			Coins:          10e6,
+			Hours:          100,
+		},
+	}
+
+	makeHTLCSpend := func(sk cipher.SecKey, w coin.HTLCWitness) coin.Transaction {
+		txn := coin.Transaction{}
+		txn.PushInput(ux.Hash())
+		txn.PushOutput(testutil.MakeAddress(), ux.Body.Coins, 50)
+		txn.SignInputs([]cipher.SecKey{sk})
+		txn.PushHTLCWitness(w)
+		err := txn.UpdateHeader()
+		require.NoError(t, err)
+		return txn
+	}
+
+	claim := makeHTLCSpend(recipientSecKey, coin.NewHTLCClaimWitness(0, h, preimage))
+
+	// The next block is the last block before the activation height
+	head := coin.BlockHeader{
+		BkSeq: params.HTLCActivationHeight - 2,
+		Time:  200,
+	}
+
+	err := VerifySingleTxnHardConstraints(claim, head, coin.UxArray{ux}, cipher.SHA256{})
+	requireHardViolation(t, "HTLC transaction before the activation height", err)
+	err = VerifyBlockTxnConstraints(claim, head, coin.UxArray{ux}, cipher.SHA256{})
+	requireHardViolation(t, "HTLC transaction before the activation height", err)
+
+	// The next block is at the activation height
+	head.BkSeq++
+	err = VerifySingleTxnHardConstraints(claim, head, coin.UxArray{ux}, cipher.SHA256{})
+	require.NoError(t, err)
+	err = VerifyBlockTxnConstraints(claim, head, coin.UxArray{ux}, cipher.SHA256{})
+	require.NoError(t, err)
+
+	head.BkSeq = h.LockHeight - 1
+	err = VerifySingleTxnHardConstraints(claim, head, coin.UxArray{ux}, cipher.SHA256{})
+	require.NoError(t, err)
+
+	refund := makeHTLCSpend(senderSecKey, coin.NewHTLCRefundWitness(0, h))
+	err = VerifySingleTxnHardConstraints(refund, head, coin.UxArray{ux}, cipher.SHA256{})
+	requireHardViolation(t, "HTLC refund before the lock height", err)
+	err = VerifyBlockTxnConstraints(refund, head, coin.UxArray{ux}, cipher.SHA256{})
+	requireHardViolation(t, "HTLC refund before the lock height", err)
+
+	head.BkSeq = h.LockHeight
+	err = VerifySingleTxnHardConstraints(refund, head, coin.UxArray{ux}, cipher.SHA256{})
+	require.NoError(t, err)
+
+	// The recipient can't spend the output as a refund
+	wrongRefund := makeHTLCSpend(recipientSecKey, coin.NewHTLCRefundWitness(0, h))
+	err = VerifySingleTxnHardConstraints(wrongRefund, head, coin.UxArray{ux}, cipher.SHA256{})
+	requireHardViolation(t, "HTLC witness does not match the output being spent", err)
+}
+
+func TestVerifySchnorrTxnHardConstraints(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+
+	ux := coin.UxOut{
+		Head: coin.UxHead{
+			Time:  100,
+			BkSeq: 2,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        cipher.AddressFromPubKey(pubkey),
+			Coins:          10e6,
+			Hours:          100,
+		},
+	}
+
+	txn := coin.Transaction{}
+	txn.PushInput(ux.Hash())
+	txn.PushOutput(testutil.MakeAddress(), ux.Body.Coins, 50)
+	txn.SignInputsSchnorr([]cipher.SecKey{seckey})
+	err := txn.UpdateHeader()
+	require.NoError(t, err)
+
+	// The next block is the last block before the activation height
+	head := coin.BlockHeader{
+		BkSeq: params.SchnorrActivationHeight - 2,
+		Time:  200,
+	}
+
+	err = VerifySingleTxnHardConstraints(txn, head, coin.UxArray{ux}, cipher.SHA256{})
+	requireHardViolation(t, "Schnorr transaction before the activation height", err)
+	err = VerifyBlockTxnConstraints(txn, head, coin.UxArray{ux}, cipher.SHA256{})
+	requireHardViolation(t, "Schnorr transaction before the activation height", err)
+
+	// The next block is at the activation height
+	head.BkSeq++
+	err = VerifySingleTxnHardConstraints(txn, head, coin.UxArray{ux}, cipher.SHA256{})
+	require.NoError(t, err)
+	err = VerifyBlockTxnConstraints(txn, head, coin.UxArray{ux}, cipher.SHA256{})
+	require.NoError(t, err)
+}
+
+func TestVerifyReplayProtectedTxnHardConstraints(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	genesisHash := testutil.RandSHA256(t)
+
+	ux := coin.UxOut{
+		Head: coin.UxHead{
+			Time:  100,
+			BkSeq: 2,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        cipher.AddressFromPubKey(pubkey),
+			Coins:          10e6,
+			Hours:          100,
+		},
+	}
+
+	txn := coin.Transaction{}
+	txn.PushInput(ux.Hash())
+	txn.PushOutput(testutil.MakeAddress(), ux.Body.Coins, 50)
+	txn.SignInputsForChain([]cipher.SecKey{seckey}, genesisHash)
+	err := txn.UpdateHeader()
+	require.NoError(t, err)
+
+	// The next block is the last block before the activation height
+	head := coin.BlockHeader{
+		BkSeq: params.ReplayProtectionActivationHeight - 2,
+		Time:  200,
+	}
+
+	err = VerifySingleTxnHardConstraints(txn, head, coin.UxArray{ux}, genesisHash)
+	requireHardViolation(t, "Replay protected transaction before the activation height", err)
+	err = VerifyBlockTxnConstraints(txn, head, coin.UxArray{ux}, genesisHash)
+	requireHardViolation(t, "Replay protected transaction before the activation height", err)
+
+	// The next block is at the activation height
+	head.BkSeq++
+	err = VerifySingleTxnHardConstraints(txn, head, coin.UxArray{ux}, genesisHash)
+	require.NoError(t, err)
+	err = VerifyBlockTxnConstraints(txn, head, coin.UxArray{ux}, genesisHash)
+	require.NoError(t, err)
+
+	// The signatures are not valid for the genesis hash of another chain
+	err = VerifySingleTxnHardConstraints(txn, head, coin.UxArray{ux}, testutil.RandSHA256(t))
+	requireHardViolation(t, "Signature not valid for output being spent", err)
+	err = VerifyBlockTxnConstraints(txn, head, coin.UxArray{ux}, testutil.RandSHA256(t))
+	requireHardViolation(t, "Signature not valid for output being spent", err)
+}
+
+func TestVerifyReplayProtectedTxnTwoChains(t *testing.T) {
+	// Two chains whose genesis blocks create the same output, such as a chain and its fork
+	makeChain := func(genesisTime uint64) (*Blockchain, *coin.SignedBlock, func()) {
+		db, closeDB := prepareDB(t)
+
+		err := CreateBuckets(db)
+		require.NoError(t, err)
+
+		store, err := blockdb.NewBlockchain(db, DefaultWalker)
+		require.NoError(t, err)
+
+		bc := &Blockchain{
+			db:    db,
+			store: store,
+		}
+
+		gb, err := coin.NewGenesisBlock(genAddress, genCoins, genesisTime)
+		require.NoError(t, err)
+		sb := &coin.SignedBlock{
+			Block: *gb,
+			Sig:   cipher.MustSignHash(gb.HashHeader(), genSecret),
+		}
+
+		err = db.Update("", func(tx *dbutil.Tx) error {
+			return bc.store.AddBlock(tx, sb)
+		})
+		require.NoError(t, err)
+
+		return bc, sb, closeDB
+	}
+
+	bcA, gbA, closeA := makeChain(genTime)
+	defer closeA()
+	bcB, gbB, closeB := makeChain(genTime + 1)
+	defer closeB()
+
+	require.NotEqual(t, gbA.HashHeader(), gbB.HashHeader())
+
+	uxs := coin.CreateUnspents(gbA.Head, gbA.Body.Transactions[0])
+	require.Equal(t, uxs[0].Hash(), coin.CreateUnspents(gbB.Head, gbB.Body.Transactions[0])[0].Hash())
+
+	verify := func(bc *Blockchain, txn coin.Transaction) error {
+		return bc.db.View("", func(tx *dbutil.Tx) error {
+			return bc.VerifySingleTxnHardConstraints(tx, txn)
+		})
+	}
+
+	// A transaction without replay protection is valid on both chains
+	txn := makeSpendTx(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), 10e6)
+	require.NoError(t, verify(bcA, txn))
+	require.NoError(t, verify(bcB, txn))
+
+	// A replay protected transaction is only valid on the chain it was signed for.
+	// The chains are too short to accept it, so it is verified against a head at the activation height
+	txn.Sigs = nil
+	txn.SignInputsForChain([]cipher.SecKey{genSecret}, gbA.HashHeader())
+	err := txn.UpdateHeader()
+	require.NoError(t, err)
+
+	requireHardViolation(t, "Replay protected transaction before the activation height", verify(bcA, txn))
+
+	verifyAtActivation := func(bc *Blockchain, txn coin.Transaction) error {
+		return bc.db.View("", func(tx *dbutil.Tx) error {
+			uxIn, err := bc.Unspent().GetArray(tx, txn.In)
+			require.NoError(t, err)
+
+			gbHash, err := bc.GenesisHash(tx)
+			require.NoError(t, err)
+
+			head := coin.BlockHeader{
+				BkSeq: params.ReplayProtectionActivationHeight - 1,
+				Time:  genTime + 100,
+			}
+
+			return VerifySingleTxnHardConstraints(txn, head, uxIn, gbHash)
+		})
+	}
+
+	require.NoError(t, verifyAtActivation(bcA, txn))
+	err = verifyAtActivation(bcB, txn)
+	requireHardViolation(t, "Signature not valid for output being spent", err)
+}
+
 func TestVerifyTransactionIsLocked(t *testing.T) {
 	for _, addr := range params.GetLockedDistributionAddresses() {
 		t.Run(fmt.Sprintf("IsLocked: %s", addr), func(t *testing.T) {
@@ -0,0 +1,268 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// DoubleSignProofsBkt holds proofs that the block publisher signed two different blocks at the same seq, indexed by seq
+	DoubleSignProofsBkt = []byte("double_sign_proofs")
+
+	// ErrBlockAcceptanceHalted is returned when executing a block at or after the seq of a double sign proof
+	ErrBlockAcceptanceHalted = errors.New("Block acceptance is halted, the block publisher signed conflicting blocks")
+)
+
+// ErrInvalidDoubleSignProof is returned when a double sign proof does not prove that the block publisher double signed
+type ErrInvalidDoubleSignProof struct {
+	Err error
+}
+
+// NewErrInvalidDoubleSignProof creates ErrInvalidDoubleSignProof
+func NewErrInvalidDoubleSignProof(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ErrInvalidDoubleSignProof{
+		Err: err,
+	}
+}
+
+func (e ErrInvalidDoubleSignProof) Error() string {
+	return fmt.Sprintf("Invalid double sign proof: %v", e.Err)
+}
+
+// DoubleSignProof is evidence that the block publisher signed two different blocks at the same seq
+type DoubleSignProof struct {
+	// The block that this node had at the seq when the conflict was detected, or the first block of a received proof
+	BlockA coin.SignedBlock
+	// The conflicting block
+	BlockB coin.SignedBlock
+	// Address of the peer that the conflicting block or the proof was received from
+	Source string
+	// When the proof was recorded, unix time
+	DetectedAt int64
+}
+
+// Seq returns the seq of the conflicting blocks
+func (p DoubleSignProof) Seq() uint64 {
+	return p.BlockA.Seq()
+}
+
+// Verify checks that both blocks have the same seq, are different and are signed by the blockchain pubkey
+func (p DoubleSignProof) Verify(pubkey cipher.PubKey) error {
+	if p.BlockA.Seq() != p.BlockB.Seq() {
+		return NewErrInvalidDoubleSignProof(errors.New("blocks have different seqs"))
+	}
+
+	if p.BlockA.HashHeader() == p.BlockB.HashHeader() {
+		return NewErrInvalidDoubleSignProof(errors.New("blocks are the same"))
+	}
+
+	if err := p.BlockA.VerifySignature(pubkey); err != nil {
+		return NewErrInvalidDoubleSignProof(fmt.Errorf("invalid signature of the first block: %v", err))
+	}
+
+	if err := p.BlockB.VerifySignature(pubkey); err != nil {
+		return NewErrInvalidDoubleSignProof(fmt.Errorf("invalid signature of the second block: %v", err))
+	}
+
+	return nil
+}
+
+// double sign proofs bucket
+type doubleSignProofs struct{}
+
+func (d doubleSignProofs) has(tx *dbutil.Tx, seq uint64) (bool, error) {
+	return dbutil.BucketHasKey(tx, DoubleSignProofsBkt, dbutil.Itob(seq))
+}
+
+func (d doubleSignProofs) put(tx *dbutil.Tx, p DoubleSignProof) error {
+	return dbutil.PutBucketValue(tx, DoubleSignProofsBkt, dbutil.Itob(p.Seq()), encoder.Serialize(p))
+}
+
+// getAll returns all proofs, ordered by seq
+func (d doubleSignProofs) getAll(tx *dbutil.Tx) ([]DoubleSignProof, error) {
+	// The bucket does not exist in a database opened read-only before the bucket was added
+	if !dbutil.Exists(tx, DoubleSignProofsBkt) {
+		return nil, nil
+	}
+
+	var proofs []DoubleSignProof
+	if err := dbutil.ForEach(tx, DoubleSignProofsBkt, func(_, v []byte) error {
+		var p DoubleSignProof
+		if err := encoder.DeserializeRaw(v, &p); err != nil {
+			return err
+		}
+		proofs = append(proofs, p)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return proofs, nil
+}
+
+// minSeq returns the lowest seq that the block publisher double signed at
+func (d doubleSignProofs) minSeq(tx *dbutil.Tx) (uint64, bool, error) {
+	if !dbutil.Exists(tx, DoubleSignProofsBkt) {
+		return 0, false, nil
+	}
+
+	// Keys are big endian, so the first key is the lowest seq
+	k, _ := tx.Bucket(DoubleSignProofsBkt).Cursor().First()
+	if k == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(k), true, nil
+}
+
+// addDoubleSignProof verifies and saves a proof. Only the first proof for a seq is saved.
+// Returns true if the proof was saved
+func (vs *Visor) addDoubleSignProof(tx *dbutil.Tx, p DoubleSignProof) (bool, error) {
+	if err := p.Verify(vs.Config.BlockchainPubkey); err != nil {
+		return false, err
+	}
+
+	if has, err := vs.doubleSignProofs.has(tx, p.Seq()); err != nil {
+		return false, err
+	} else if has {
+		return false, nil
+	}
+
+	if err := vs.doubleSignProofs.put(tx, p); err != nil {
+		return false, err
+	}
+
+	logger.Critical().WithFields(logrus.Fields{
+		"seq":    p.Seq(),
+		"source": p.Source,
+		"hashA":  p.BlockA.HashHeader().Hex(),
+		"hashB":  p.BlockB.HashHeader().Hex(),
+	}).Error("The block publisher signed conflicting blocks")
+
+	if !vs.Config.DisableDoubleSignHalt {
+		logger.Critical().WithField("seq", p.Seq()).Error("Block acceptance is halted at the conflicting blocks")
+	}
+
+	return true, nil
+}
+
+// AddDoubleSignProof verifies and saves a proof received from a peer.
+// Returns ErrInvalidDoubleSignProof if the proof is invalid.
+// Returns true if the proof was saved, and false if a proof for the same seq is already saved
+func (vs *Visor) AddDoubleSignProof(p DoubleSignProof) (bool, error) {
+	var added bool
+	if err := vs.DB.Update("AddDoubleSignProof", func(tx *dbutil.Tx) error {
+		var err error
+		added, err = vs.addDoubleSignProof(tx, p)
+		return err
+	}); err != nil {
+		return false, err
+	}
+
+	return added, nil
+}
+
+// DetectDoubleSign compares a signed block with the block this node has at the same seq.
+// If both are validly signed but different, a proof is saved and returned.
+// The bool return value is true if the proof was saved, and false if a proof for the same seq is already saved.
+// If the block does not conflict, the proof is nil
+func (vs *Visor) DetectDoubleSign(b coin.SignedBlock, source string) (*DoubleSignProof, bool, error) {
+	// Most received blocks are the same as this node's, so look for a conflict
+	// in a read-only transaction before writing a proof
+	var ours *coin.SignedBlock
+	if err := vs.DB.View("DetectDoubleSign", func(tx *dbutil.Tx) error {
+		var err error
+		ours, err = vs.Blockchain.GetSignedBlockBySeq(tx, b.Seq())
+		return err
+	}); err != nil {
+		return nil, false, err
+	}
+
+	if ours == nil || ours.HashHeader() == b.HashHeader() {
+		return nil, false, nil
+	}
+
+	// A block with an invalid signature is not evidence of anything
+	if err := b.VerifySignature(vs.Config.BlockchainPubkey); err != nil {
+		return nil, false, nil
+	}
+
+	p := DoubleSignProof{
+		BlockA:     *ours,
+		BlockB:     b,
+		Source:     source,
+		DetectedAt: time.Now().UTC().Unix(),
+	}
+
+	added, err := vs.AddDoubleSignProof(p)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &p, added, nil
+}
+
+// GetDoubleSignProofs returns the saved double sign proofs, ordered by seq
+func (vs *Visor) GetDoubleSignProofs() ([]DoubleSignProof, error) {
+	var proofs []DoubleSignProof
+	if err := vs.DB.View("GetDoubleSignProofs", func(tx *dbutil.Tx) error {
+		var err error
+		proofs, err = vs.doubleSignProofs.getAll(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return proofs, nil
+}
+
+// BlockAcceptanceHalted returns the seq that block acceptance is halted at,
+// which is the lowest seq that the block publisher double signed at.
+// The bool return value is false if block acceptance is not halted
+func (vs *Visor) BlockAcceptanceHalted() (uint64, bool, error) {
+	if vs.Config.DisableDoubleSignHalt {
+		return 0, false, nil
+	}
+
+	var seq uint64
+	var halted bool
+	if err := vs.DB.View("BlockAcceptanceHalted", func(tx *dbutil.Tx) error {
+		var err error
+		seq, halted, err = vs.doubleSignProofs.minSeq(tx)
+		return err
+	}); err != nil {
+		return 0, false, err
+	}
+
+	return seq, halted, nil
+}
+
+// verifyNotHalted returns ErrBlockAcceptanceHalted if a block at seq may not be executed,
+// because the block publisher double signed at or before seq
+func (vs *Visor) verifyNotHalted(tx *dbutil.Tx, seq uint64) error {
+	if vs.Config.DisableDoubleSignHalt {
+		return nil
+	}
+
+	haltSeq, halted, err := vs.doubleSignProofs.minSeq(tx)
+	if err != nil {
+		return err
+	}
+
+	if halted && seq >= haltSeq {
+		return ErrBlockAcceptanceHalted
+	}
+
+	return nil
+}
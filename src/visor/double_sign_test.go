@@ -0,0 +1,241 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// makeConflictingBlocks creates two different signed blocks at seq 1, both building on the genesis block
+func makeConflictingBlocks(t *testing.T, v *Visor) (coin.SignedBlock, coin.SignedBlock) {
+	var a, b *coin.Block
+	err := v.DB.View("", func(tx *dbutil.Tx) error {
+		gb, err := v.Blockchain.GetGenesisBlock(tx)
+		require.NoError(t, err)
+
+		uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+		when := gb.Time() + 100
+
+		a, err = v.Blockchain.NewBlock(tx, coin.Transactions{
+			makeUnspentsTx(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10, params.UserVerifyTxn.MaxDropletPrecision),
+		}, when)
+		require.NoError(t, err)
+
+		b, err = v.Blockchain.NewBlock(tx, coin.Transactions{
+			makeUnspentsTx(t, uxs, []cipher.SecKey{genSecret}, genAddress, 11, params.UserVerifyTxn.MaxDropletPrecision),
+		}, when)
+		require.NoError(t, err)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	return v.signBlock(*a), v.signBlock(*b)
+}
+
+func TestDoubleSignProofVerify(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{})
+	a, b := makeConflictingBlocks(t, v)
+
+	_, otherSecKey := cipher.GenerateKeyPair()
+	badSig := b
+	badSig.Sig = cipher.MustSignHash(b.HashHeader(), otherSecKey)
+
+	otherSeq := b
+	otherSeq.Head.BkSeq = 2
+	otherSeq = v.signBlock(otherSeq.Block)
+
+	cases := []struct {
+		name  string
+		proof DoubleSignProof
+		err   error
+	}{
+		{
+			name:  "valid",
+			proof: DoubleSignProof{BlockA: a, BlockB: b},
+		},
+		{
+			name:  "different seqs",
+			proof: DoubleSignProof{BlockA: a, BlockB: otherSeq},
+			err:   NewErrInvalidDoubleSignProof(errors.New("blocks have different seqs")),
+		},
+		{
+			name:  "same block",
+			proof: DoubleSignProof{BlockA: a, BlockB: a},
+			err:   NewErrInvalidDoubleSignProof(errors.New("blocks are the same")),
+		},
+		{
+			name:  "invalid first signature",
+			proof: DoubleSignProof{BlockA: badSig, BlockB: a},
+			err:   NewErrInvalidDoubleSignProof(errors.New("invalid signature of the first block: Recovered pubkey does not match pubkey")),
+		},
+		{
+			name:  "invalid second signature",
+			proof: DoubleSignProof{BlockA: a, BlockB: badSig},
+			err:   NewErrInvalidDoubleSignProof(errors.New("invalid signature of the second block: Recovered pubkey does not match pubkey")),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.proof.Verify(genPublic)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			require.IsType(t, ErrInvalidDoubleSignProof{}, err)
+			require.Equal(t, tc.err.Error(), err.Error())
+		})
+	}
+}
+
+func TestDetectDoubleSign(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{})
+	a, b := makeConflictingBlocks(t, v)
+
+	require.NoError(t, v.ExecuteSignedBlock(a))
+
+	// No proofs yet
+	proofs, err := v.GetDoubleSignProofs()
+	require.NoError(t, err)
+	require.Empty(t, proofs)
+	_, halted, err := v.BlockAcceptanceHalted()
+	require.NoError(t, err)
+	require.False(t, halted)
+
+	// The same block, an unsigned block and a block above the head do not conflict
+	p, added, err := v.DetectDoubleSign(a, "1.1.1.1:6000")
+	require.NoError(t, err)
+	require.False(t, added)
+	require.Nil(t, p)
+
+	unsigned := b
+	unsigned.Sig = cipher.Sig{}
+	p, added, err = v.DetectDoubleSign(unsigned, "1.1.1.1:6000")
+	require.NoError(t, err)
+	require.False(t, added)
+	require.Nil(t, p)
+
+	above := b
+	above.Head.BkSeq = 5
+	above = v.signBlock(above.Block)
+	p, added, err = v.DetectDoubleSign(above, "1.1.1.1:6000")
+	require.NoError(t, err)
+	require.False(t, added)
+	require.Nil(t, p)
+
+	// A different signed block at the same seq is detected
+	p, added, err = v.DetectDoubleSign(b, "1.1.1.1:6000")
+	require.NoError(t, err)
+	require.True(t, added)
+	require.NotNil(t, p)
+	require.Equal(t, a, p.BlockA)
+	require.Equal(t, b, p.BlockB)
+	require.Equal(t, "1.1.1.1:6000", p.Source)
+	require.Equal(t, uint64(1), p.Seq())
+
+	// Only the first proof for a seq is saved
+	p2, added, err := v.DetectDoubleSign(b, "2.2.2.2:6000")
+	require.NoError(t, err)
+	require.False(t, added)
+	require.NotNil(t, p2)
+
+	proofs, err = v.GetDoubleSignProofs()
+	require.NoError(t, err)
+	require.Equal(t, []DoubleSignProof{*p}, proofs)
+
+	seq, halted, err := v.BlockAcceptanceHalted()
+	require.NoError(t, err)
+	require.True(t, halted)
+	require.Equal(t, uint64(1), seq)
+
+	// Blocks past the fork are not accepted
+	next := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq:    2,
+				PrevHash: a.HashHeader(),
+				Time:     a.Time() + 100,
+			},
+		},
+	}
+	next = v.signBlock(next.Block)
+	require.Equal(t, ErrBlockAcceptanceHalted, v.ExecuteSignedBlock(next))
+
+	v.Config.DisableDoubleSignHalt = true
+	_, halted, err = v.BlockAcceptanceHalted()
+	require.NoError(t, err)
+	require.False(t, halted)
+	require.NotEqual(t, ErrBlockAcceptanceHalted, v.ExecuteSignedBlock(next))
+}
+
+func TestAddDoubleSignProof(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{})
+	a, b := makeConflictingBlocks(t, v)
+
+	// An invalid proof is rejected
+	added, err := v.AddDoubleSignProof(DoubleSignProof{BlockA: a, BlockB: a})
+	require.IsType(t, ErrInvalidDoubleSignProof{}, err)
+	require.False(t, added)
+
+	// A proof can be received before either block is executed
+	p := DoubleSignProof{
+		BlockA:     a,
+		BlockB:     b,
+		Source:     "1.1.1.1:6000",
+		DetectedAt: time.Now().UTC().Unix(),
+	}
+	added, err = v.AddDoubleSignProof(p)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	added, err = v.AddDoubleSignProof(p)
+	require.NoError(t, err)
+	require.False(t, added)
+
+	proofs, err := v.GetDoubleSignProofs()
+	require.NoError(t, err)
+	require.Equal(t, []DoubleSignProof{p}, proofs)
+
+	// Neither of the conflicting blocks can be executed
+	require.Equal(t, ErrBlockAcceptanceHalted, v.ExecuteSignedBlock(a))
+	require.Equal(t, ErrBlockAcceptanceHalted, v.ExecuteSignedBlock(b))
+}
+
+func TestExecuteSignedBlockPrevHashMismatch(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{})
+	a, b := makeConflictingBlocks(t, v)
+
+	require.NoError(t, v.ExecuteSignedBlock(a))
+
+	// A block that builds on the conflicting block is rejected
+	next := coin.Block{
+		Head: coin.BlockHeader{
+			BkSeq:    2,
+			PrevHash: b.HashHeader(),
+			Time:     b.Time() + 100,
+		},
+	}
+	require.Equal(t, ErrBlockPrevHashMismatch, v.ExecuteSignedBlock(v.signBlock(next)))
+}
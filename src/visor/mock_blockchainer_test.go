@@ -28,6 +28,29 @@ func (_m *MockBlockchainer) ExecuteBlock(tx *dbutil.Tx, sb *coin.SignedBlock) er
 	return r0
 }
 
+// GenesisHash provides a mock function with given fields: tx
+func (_m *MockBlockchainer) GenesisHash(tx *dbutil.Tx) (cipher.SHA256, error) {
+	ret := _m.Called(tx)
+
+	var r0 cipher.SHA256
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx) cipher.SHA256); ok {
+		r0 = rf(tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(cipher.SHA256)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx) error); ok {
+		r1 = rf(tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlocks provides a mock function with given fields: tx, seqs
 func (_m *MockBlockchainer) GetBlocks(tx *dbutil.Tx, seqs []uint64) ([]coin.SignedBlock, error) {
 	ret := _m.Called(tx, seqs)
@@ -261,6 +284,29 @@ func (_m *MockBlockchainer) NewBlock(tx *dbutil.Tx, txns coin.Transactions, curr
 	return r0, r1
 }
 
+// NewEmptyBlock provides a mock function with given fields: tx, currentTime
+func (_m *MockBlockchainer) NewEmptyBlock(tx *dbutil.Tx, currentTime uint64) (*coin.Block, error) {
+	ret := _m.Called(tx, currentTime)
+
+	var r0 *coin.Block
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, uint64) *coin.Block); ok {
+		r0 = rf(tx, currentTime)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coin.Block)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, uint64) error); ok {
+		r1 = rf(tx, currentTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Time provides a mock function with given fields: tx
 func (_m *MockBlockchainer) Time(tx *dbutil.Tx) (uint64, error) {
 	ret := _m.Called(tx)
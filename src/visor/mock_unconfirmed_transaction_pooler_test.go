@@ -96,6 +96,29 @@ func (_m *MockUnconfirmedTransactionPooler) Get(tx *dbutil.Tx, hash cipher.SHA25
 	return r0, r1
 }
 
+// GetConflicts provides a mock function with given fields: tx, bc, txn
+func (_m *MockUnconfirmedTransactionPooler) GetConflicts(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction) ([]SpendConflict, error) {
+	ret := _m.Called(tx, bc, txn)
+
+	var r0 []SpendConflict
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, Blockchainer, coin.Transaction) []SpendConflict); ok {
+		r0 = rf(tx, bc, txn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SpendConflict)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, Blockchainer, coin.Transaction) error); ok {
+		r1 = rf(tx, bc, txn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFiltered provides a mock function with given fields: tx, filter
 func (_m *MockUnconfirmedTransactionPooler) GetFiltered(tx *dbutil.Tx, filter func(UnconfirmedTransaction) bool) ([]UnconfirmedTransaction, error) {
 	ret := _m.Called(tx, filter)
@@ -188,6 +211,29 @@ func (_m *MockUnconfirmedTransactionPooler) GetKnown(tx *dbutil.Tx, txns []ciphe
 	return r0, r1
 }
 
+// GetOutput provides a mock function with given fields: tx, hash
+func (_m *MockUnconfirmedTransactionPooler) GetOutput(tx *dbutil.Tx, hash cipher.SHA256) (*coin.UxOut, error) {
+	ret := _m.Called(tx, hash)
+
+	var r0 *coin.UxOut
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, cipher.SHA256) *coin.UxOut); ok {
+		r0 = rf(tx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coin.UxOut)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, cipher.SHA256) error); ok {
+		r1 = rf(tx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUnspentsOfAddr provides a mock function with given fields: tx, addr
 func (_m *MockUnconfirmedTransactionPooler) GetUnspentsOfAddr(tx *dbutil.Tx, addr cipher.Address) (coin.UxArray, error) {
 	ret := _m.Called(tx, addr)
@@ -358,3 +404,81 @@ func (_m *MockUnconfirmedTransactionPooler) SetTransactionsAnnounced(tx *dbutil.
 
 	return r0
 }
+
+// SpendsOfAddresses provides a mock function with given fields: tx, addrs
+func (_m *MockUnconfirmedTransactionPooler) SpendsOfAddresses(tx *dbutil.Tx, addrs []cipher.Address) (coin.AddressUxOuts, error) {
+	ret := _m.Called(tx, addrs)
+
+	var r0 coin.AddressUxOuts
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, []cipher.Address) coin.AddressUxOuts); ok {
+		r0 = rf(tx, addrs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(coin.AddressUxOuts)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, []cipher.Address) error); ok {
+		r1 = rf(tx, addrs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyConflict provides a mock function with given fields: tx, bc, a, b, common
+func (_m *MockUnconfirmedTransactionPooler) VerifyConflict(tx *dbutil.Tx, bc Blockchainer, a coin.Transaction, b coin.Transaction, common []cipher.SHA256) (coin.UxArray, error) {
+	ret := _m.Called(tx, bc, a, b, common)
+
+	var r0 coin.UxArray
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, Blockchainer, coin.Transaction, coin.Transaction, []cipher.SHA256) coin.UxArray); ok {
+		r0 = rf(tx, bc, a, b, common)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(coin.UxArray)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, Blockchainer, coin.Transaction, coin.Transaction, []cipher.SHA256) error); ok {
+		r1 = rf(tx, bc, a, b, common)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyTransaction provides a mock function with given fields: tx, bc, txn, verifyParams
+func (_m *MockUnconfirmedTransactionPooler) VerifyTransaction(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, verifyParams params.VerifyTxn) (*coin.SignedBlock, coin.UxArray, error) {
+	ret := _m.Called(tx, bc, txn, verifyParams)
+
+	var r0 *coin.SignedBlock
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, Blockchainer, coin.Transaction, params.VerifyTxn) *coin.SignedBlock); ok {
+		r0 = rf(tx, bc, txn, verifyParams)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coin.SignedBlock)
+		}
+	}
+
+	var r1 coin.UxArray
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, Blockchainer, coin.Transaction, params.VerifyTxn) coin.UxArray); ok {
+		r1 = rf(tx, bc, txn, verifyParams)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(coin.UxArray)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*dbutil.Tx, Blockchainer, coin.Transaction, params.VerifyTxn) error); ok {
+		r2 = rf(tx, bc, txn, verifyParams)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
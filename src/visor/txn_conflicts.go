@@ -0,0 +1,75 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// ErrInvalidTxnConflict is returned when two transactions are not evidence of an attempt to spend an output twice
+type ErrInvalidTxnConflict struct {
+	Err error
+}
+
+// NewErrInvalidTxnConflict creates ErrInvalidTxnConflict
+func NewErrInvalidTxnConflict(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ErrInvalidTxnConflict{
+		Err: err,
+	}
+}
+
+func (e ErrInvalidTxnConflict) Error() string {
+	return fmt.Sprintf("Invalid transaction conflict: %v", e.Err)
+}
+
+// GetUnconfirmedConflicts returns the outputs spent by txn which are also spent by other unconfirmed transactions
+func (vs *Visor) GetUnconfirmedConflicts(txn coin.Transaction) ([]SpendConflict, error) {
+	var conflicts []SpendConflict
+	if err := vs.DB.View("GetUnconfirmedConflicts", func(tx *dbutil.Tx) error {
+		var err error
+		conflicts, err = vs.Unconfirmed.GetConflicts(tx, vs.Blockchain, txn)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// VerifyTxnConflict checks that two different transactions both spend an unspent output,
+// and that neither violates hard constraints, so that either could be confirmed.
+// Returns the outputs spent by both transactions.
+// Returns ErrInvalidTxnConflict if the transactions are not a conflict
+func (vs *Visor) VerifyTxnConflict(a, b coin.Transaction) (coin.UxArray, error) {
+	if a.Hash() == b.Hash() {
+		return nil, NewErrInvalidTxnConflict(errors.New("transactions are the same"))
+	}
+
+	var common []cipher.SHA256
+	for _, h := range a.In {
+		if containsHash(b.In, h) && !containsHash(common, h) {
+			common = append(common, h)
+		}
+	}
+
+	if len(common) == 0 {
+		return nil, NewErrInvalidTxnConflict(errors.New("transactions do not spend a common output"))
+	}
+
+	var uxs coin.UxArray
+	if err := vs.DB.View("VerifyTxnConflict", func(tx *dbutil.Tx) error {
+		var err error
+		uxs, err = vs.Unconfirmed.VerifyConflict(tx, vs.Blockchain, a, b, common)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return uxs, nil
+}
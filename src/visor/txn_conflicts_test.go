@@ -0,0 +1,92 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestUnconfirmedConflicts(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{})
+
+	var gb *coin.SignedBlock
+	err := db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.Blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	ux := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	toAddr := testutil.MakeAddress()
+
+	a := makeSpendTx(t, coin.UxArray{ux}, []cipher.SecKey{genSecret}, toAddr, 10e6)
+	b := makeSpendTx(t, coin.UxArray{ux}, []cipher.SecKey{genSecret}, toAddr, 20e6)
+
+	// Nothing conflicts with the first transaction
+	_, _, err = v.InjectForeignTransaction(a)
+	require.NoError(t, err)
+
+	conflicts, err := v.GetUnconfirmedConflicts(a)
+	require.NoError(t, err)
+	require.Empty(t, conflicts)
+
+	// The conflicting transaction can be checked before or after it is injected
+	conflicts, err = v.GetUnconfirmedConflicts(b)
+	require.NoError(t, err)
+	require.Equal(t, []SpendConflict{{UxOut: ux, Txid: a.Hash()}}, conflicts)
+
+	_, _, err = v.InjectForeignTransaction(b)
+	require.NoError(t, err)
+
+	conflicts, err = v.GetUnconfirmedConflicts(b)
+	require.NoError(t, err)
+	require.Equal(t, []SpendConflict{{UxOut: ux, Txid: a.Hash()}}, conflicts)
+
+	conflicts, err = v.GetUnconfirmedConflicts(a)
+	require.NoError(t, err)
+	require.Equal(t, []SpendConflict{{UxOut: ux, Txid: b.Hash()}}, conflicts)
+
+	// Conflict evidence is verified
+	uxs, err := v.VerifyTxnConflict(a, b)
+	require.NoError(t, err)
+	require.Equal(t, coin.UxArray{ux}, uxs)
+
+	_, err = v.VerifyTxnConflict(a, a)
+	require.Equal(t, NewErrInvalidTxnConflict(errors.New("transactions are the same")), err)
+
+	unrelated := makeSpendTx(t, coin.UxArray{ux}, []cipher.SecKey{genSecret}, toAddr, 30e6)
+	unrelated.In = []cipher.SHA256{testutil.RandSHA256(t)}
+	_, err = v.VerifyTxnConflict(a, unrelated)
+	require.Equal(t, NewErrInvalidTxnConflict(errors.New("transactions do not spend a common output")), err)
+
+	_, otherSecKey := cipher.GenerateKeyPair()
+	badSig := b
+	badSig.Sigs = []cipher.Sig{cipher.MustSignHash(b.InnerHash, otherSecKey)}
+	_, err = v.VerifyTxnConflict(a, badSig)
+	require.Error(t, err)
+	require.IsType(t, ErrInvalidTxnConflict{}, err)
+
+	// When one of the transactions is confirmed, the evidence is no longer valid
+	var block *coin.Block
+	err = db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		block, err = v.Blockchain.NewBlock(tx, coin.Transactions{a}, gb.Time()+100)
+		return err
+	})
+	require.NoError(t, err)
+	require.NoError(t, v.ExecuteSignedBlock(v.signBlock(*block)))
+
+	_, err = v.VerifyTxnConflict(a, b)
+	require.Error(t, err)
+	require.IsType(t, ErrInvalidTxnConflict{}, err)
+}
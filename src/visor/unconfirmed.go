@@ -2,12 +2,14 @@ package visor
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
@@ -16,6 +18,24 @@ var (
 	UnconfirmedTxnsBkt = []byte("unconfirmed_txns")
 	// UnconfirmedUnspentsBkt holds unconfirmed unspent outputs
 	UnconfirmedUnspentsBkt = []byte("unconfirmed_unspents")
+	// UnconfirmedUxOutsBkt indexes the outputs created by unconfirmed transactions by their hash
+	UnconfirmedUxOutsBkt = []byte("unconfirmed_uxouts")
+	// UnconfirmedSpentBkt holds the outputs spent by unconfirmed transactions, indexed by transaction hash
+	UnconfirmedSpentBkt = []byte("unconfirmed_spent")
+	// UnconfirmedAddrIncomingBkt maps addresses to the unconfirmed transactions that create outputs for them
+	UnconfirmedAddrIncomingBkt = []byte("unconfirmed_addr_incoming")
+	// UnconfirmedAddrOutgoingBkt maps addresses to the unconfirmed transactions that spend their outputs
+	UnconfirmedAddrOutgoingBkt = []byte("unconfirmed_addr_outgoing")
+
+	// ErrUnconfirmedChainTooDeep is returned if a transaction spends outputs of a chain of unconfirmed transactions
+	// that is longer than the maximum unconfirmed chain depth
+	ErrUnconfirmedChainTooDeep = errors.New("Transaction exceeds the maximum unconfirmed chain depth")
+	// ErrUnconfirmedChainTooManyAncestors is returned if a transaction has more unconfirmed ancestors
+	// than the maximum allowed
+	ErrUnconfirmedChainTooManyAncestors = errors.New("Transaction exceeds the maximum number of unconfirmed ancestors")
+	// ErrUnconfirmedChainsDisabled is returned when trying to spend outputs of unconfirmed transactions
+	// while the maximum unconfirmed chain depth is 0
+	ErrUnconfirmedChainsDisabled = errors.New("Spending outputs of unconfirmed transactions is disabled")
 
 	errUpdateObjectDoesNotExist = errors.New("object does not exist in bucket")
 )
@@ -108,31 +128,200 @@ func (txus *txnUnspents) put(tx *dbutil.Tx, hash cipher.SHA256, uxs coin.UxArray
 	return dbutil.PutBucketValue(tx, UnconfirmedUnspentsBkt, []byte(hash.Hex()), encoder.Serialize(uxs))
 }
 
+func (txus *txnUnspents) get(tx *dbutil.Tx, hash cipher.SHA256) (coin.UxArray, error) {
+	var uxs coin.UxArray
+
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, UnconfirmedUnspentsBkt, []byte(hash.Hex()), &uxs); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	return uxs, nil
+}
+
 func (txus *txnUnspents) delete(tx *dbutil.Tx, hash cipher.SHA256) error {
 	return dbutil.Delete(tx, UnconfirmedUnspentsBkt, []byte(hash.Hex()))
 }
 
-func (txus *txnUnspents) getByAddr(tx *dbutil.Tx, a cipher.Address) (coin.UxArray, error) {
-	var uxo coin.UxArray
+// unconfirmedUxOuts maps the hash of an output created by an unconfirmed transaction to the output
+type unconfirmedUxOuts struct{}
 
-	if err := dbutil.ForEach(tx, UnconfirmedUnspentsBkt, func(_, v []byte) error {
-		var uxa coin.UxArray
-		if err := encoder.DeserializeRaw(v, &uxa); err != nil {
+func (uxb *unconfirmedUxOuts) get(tx *dbutil.Tx, hash cipher.SHA256) (*coin.UxOut, error) {
+	var ux coin.UxOut
+
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, UnconfirmedUxOutsBkt, []byte(hash.Hex()), &ux); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	return &ux, nil
+}
+
+func (uxb *unconfirmedUxOuts) put(tx *dbutil.Tx, uxs coin.UxArray) error {
+	for _, ux := range uxs {
+		if err := dbutil.PutBucketValue(tx, UnconfirmedUxOutsBkt, []byte(ux.Hash().Hex()), encoder.Serialize(ux)); err != nil {
 			return err
 		}
+	}
 
-		for i := range uxa {
-			if uxa[i].Body.Address == a {
-				uxo = append(uxo, uxa[i])
-			}
+	return nil
+}
+
+func (uxb *unconfirmedUxOuts) delete(tx *dbutil.Tx, uxs coin.UxArray) error {
+	for _, ux := range uxs {
+		if err := dbutil.Delete(tx, UnconfirmedUxOutsBkt, []byte(ux.Hash().Hex())); err != nil {
+			return err
 		}
+	}
 
-		return nil
-	}); err != nil {
+	return nil
+}
+
+func (uxb *unconfirmedUxOuts) len(tx *dbutil.Tx) (uint64, error) {
+	return dbutil.Len(tx, UnconfirmedUxOutsBkt)
+}
+
+// txnSpent maps the hash of an unconfirmed transaction to the outputs it spends.
+// The spent outputs are recorded when the transaction is injected, so that they are still
+// known after the outputs are spent by a block.
+type txnSpent struct{}
+
+func (txs *txnSpent) put(tx *dbutil.Tx, hash cipher.SHA256, uxs coin.UxArray) error {
+	return dbutil.PutBucketValue(tx, UnconfirmedSpentBkt, []byte(hash.Hex()), encoder.Serialize(uxs))
+}
+
+func (txs *txnSpent) get(tx *dbutil.Tx, hash cipher.SHA256) (coin.UxArray, error) {
+	var uxs coin.UxArray
+
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, UnconfirmedSpentBkt, []byte(hash.Hex()), &uxs); err != nil {
 		return nil, err
+	} else if !ok {
+		return nil, nil
 	}
 
-	return uxo, nil
+	return uxs, nil
+}
+
+func (txs *txnSpent) hasKey(tx *dbutil.Tx, hash cipher.SHA256) (bool, error) {
+	return dbutil.BucketHasKey(tx, UnconfirmedSpentBkt, []byte(hash.Hex()))
+}
+
+func (txs *txnSpent) delete(tx *dbutil.Tx, hash cipher.SHA256) error {
+	return dbutil.Delete(tx, UnconfirmedSpentBkt, []byte(hash.Hex()))
+}
+
+func (txs *txnSpent) len(tx *dbutil.Tx) (uint64, error) {
+	return dbutil.Len(tx, UnconfirmedSpentBkt)
+}
+
+// unconfirmedAddrIndex maps an address to the hashes of the unconfirmed transactions
+// that create or spend its outputs, depending on the bucket
+type unconfirmedAddrIndex struct {
+	bkt []byte
+}
+
+func (idx *unconfirmedAddrIndex) get(tx *dbutil.Tx, addr cipher.Address) ([]cipher.SHA256, error) {
+	var hashes []cipher.SHA256
+
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, idx.bkt, addr.Bytes(), &hashes); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	return hashes, nil
+}
+
+// add indexes a transaction hash for addresses. Hashes that are already indexed are ignored.
+func (idx *unconfirmedAddrIndex) add(tx *dbutil.Tx, addrs []cipher.Address, hash cipher.SHA256) error {
+	for _, addr := range addrs {
+		hashes, err := idx.get(tx, addr)
+		if err != nil {
+			return err
+		}
+
+		if containsHash(hashes, hash) {
+			continue
+		}
+
+		hashes = append(hashes, hash)
+		if err := dbutil.PutBucketValue(tx, idx.bkt, addr.Bytes(), encoder.Serialize(hashes)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remove removes a transaction hash from the index of addresses. Hashes that are not indexed are ignored.
+func (idx *unconfirmedAddrIndex) remove(tx *dbutil.Tx, addrs []cipher.Address, hash cipher.SHA256) error {
+	for _, addr := range addrs {
+		hashes, err := idx.get(tx, addr)
+		if err != nil {
+			return err
+		}
+
+		newHashes := make([]cipher.SHA256, 0, len(hashes))
+		for _, h := range hashes {
+			if h != hash {
+				newHashes = append(newHashes, h)
+			}
+		}
+
+		if len(newHashes) == len(hashes) {
+			continue
+		}
+
+		// Delete the row if hashes is empty, so that the bucket does not accumulate empty entries
+		if len(newHashes) == 0 {
+			if err := dbutil.Delete(tx, idx.bkt, addr.Bytes()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := dbutil.PutBucketValue(tx, idx.bkt, addr.Bytes(), encoder.Serialize(newHashes)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func containsHash(hashes []cipher.SHA256, hash cipher.SHA256) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// uxArrayAddresses returns the distinct addresses that own the outputs in uxs
+func uxArrayAddresses(uxs coin.UxArray) []cipher.Address {
+	var addrs []cipher.Address
+	addrm := make(map[cipher.Address]struct{}, len(uxs))
+	for _, ux := range uxs {
+		if _, ok := addrm[ux.Body.Address]; ok {
+			continue
+		}
+
+		addrm[ux.Body.Address] = struct{}{}
+		addrs = append(addrs, ux.Body.Address)
+	}
+	return addrs
+}
+
+// UnconfirmedChainLimits limits the chains of unconfirmed transactions that spend
+// outputs created by other unconfirmed transactions
+type UnconfirmedChainLimits struct {
+	// MaxDepth is the maximum length of a chain of unconfirmed transactions that a transaction may descend from.
+	// If 0, unconfirmed transactions may only spend confirmed outputs.
+	MaxDepth uint64
+	// MaxAncestors is the maximum number of unconfirmed transactions that a transaction may descend from
+	MaxAncestors uint64
 }
 
 // UnconfirmedTransactionPool manages unconfirmed transactions
@@ -143,10 +332,21 @@ type UnconfirmedTransactionPool struct {
 	// our future balance and avoid double spending our own coins
 	// Maps from Transaction.Hash() to UxArray.
 	unspent *txnUnspents
+	// Outputs created by unconfirmed transactions, indexed by UxOut.Hash()
+	uxouts *unconfirmedUxOuts
+	// Outputs spent by unconfirmed transactions.
+	// Maps from Transaction.Hash() to UxArray.
+	spent *txnSpent
+	// Unconfirmed transactions that create outputs for an address
+	incoming *unconfirmedAddrIndex
+	// Unconfirmed transactions that spend outputs of an address
+	outgoing *unconfirmedAddrIndex
+
+	chainLimits UnconfirmedChainLimits
 }
 
 // NewUnconfirmedTransactionPool creates an UnconfirmedTransactionPool instance
-func NewUnconfirmedTransactionPool(db *dbutil.DB) (*UnconfirmedTransactionPool, error) {
+func NewUnconfirmedTransactionPool(db *dbutil.DB, chainLimits UnconfirmedChainLimits) (*UnconfirmedTransactionPool, error) {
 	if err := db.View("Check unconfirmed txn pool size", func(tx *dbutil.Tx) error {
 		n, err := dbutil.Len(tx, UnconfirmedTxnsBkt)
 		if err != nil {
@@ -160,12 +360,149 @@ func NewUnconfirmedTransactionPool(db *dbutil.DB) (*UnconfirmedTransactionPool,
 	}
 
 	return &UnconfirmedTransactionPool{
-		db:      db,
-		txns:    &unconfirmedTxns{},
-		unspent: &txnUnspents{},
+		db:          db,
+		txns:        &unconfirmedTxns{},
+		unspent:     &txnUnspents{},
+		uxouts:      &unconfirmedUxOuts{},
+		spent:       &txnSpent{},
+		incoming:    &unconfirmedAddrIndex{bkt: UnconfirmedAddrIncomingBkt},
+		outgoing:    &unconfirmedAddrIndex{bkt: UnconfirmedAddrOutgoingBkt},
+		chainLimits: chainLimits,
 	}, nil
 }
 
+// MaybeBuildIndexes builds the unconfirmed output and address indexes if they are out of sync with the pool.
+// Databases created before the indexes were introduced have empty indexes.
+func (utp *UnconfirmedTransactionPool) MaybeBuildIndexes(tx *dbutil.Tx, bc Blockchainer) error {
+	var uxs coin.UxArray
+	if err := dbutil.ForEach(tx, UnconfirmedUnspentsBkt, func(_, v []byte) error {
+		var uxa coin.UxArray
+		if err := encoder.DeserializeRaw(v, &uxa); err != nil {
+			return err
+		}
+
+		uxs = append(uxs, uxa...)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	nUxOuts, err := utp.uxouts.len(tx)
+	if err != nil {
+		return err
+	}
+
+	nTxns, err := utp.txns.len(tx)
+	if err != nil {
+		return err
+	}
+
+	nSpent, err := utp.spent.len(tx)
+	if err != nil {
+		return err
+	}
+
+	if nUxOuts == uint64(len(uxs)) && nSpent == nTxns {
+		return nil
+	}
+
+	logger.Infof("Rebuilding unconfirmed pool indexes (indexed outputs=%d, outputs=%d, indexed txns=%d, txns=%d)", nUxOuts, len(uxs), nSpent, nTxns)
+
+	for _, bkt := range [][]byte{
+		UnconfirmedUxOutsBkt,
+		UnconfirmedSpentBkt,
+		UnconfirmedAddrIncomingBkt,
+		UnconfirmedAddrOutgoingBkt,
+	} {
+		if err := dbutil.Reset(tx, bkt); err != nil {
+			return err
+		}
+	}
+
+	// Index the outputs of all transactions before resolving the outputs spent by chained transactions
+	if err := utp.uxouts.put(tx, uxs); err != nil {
+		return err
+	}
+
+	return utp.txns.forEach(tx, func(hash cipher.SHA256, utxn UnconfirmedTransaction) error {
+		return utp.indexTransaction(tx, bc, hash, utxn.Transaction)
+	})
+}
+
+// indexTransaction adds an unconfirmed transaction to the output and address indexes.
+// The transaction's outputs must already be in the unconfirmed unspents bucket.
+// The outputs spent by the transaction are looked up in the blockchain's unspent pool,
+// then in the outputs of unconfirmed transactions. Spent outputs that cannot be found are not indexed.
+func (utp *UnconfirmedTransactionPool) indexTransaction(tx *dbutil.Tx, bc Blockchainer, hash cipher.SHA256, txn coin.Transaction) error {
+	uxs, err := utp.unspent.get(tx, hash)
+	if err != nil {
+		return err
+	}
+
+	spent := make(coin.UxArray, 0, len(txn.In))
+	for _, h := range txn.In {
+		ux, err := bc.Unspent().Get(tx, h)
+		if err != nil {
+			return err
+		}
+
+		if ux == nil {
+			ux, err = utp.uxouts.get(tx, h)
+			if err != nil {
+				return err
+			}
+		}
+
+		if ux == nil {
+			logger.Warningf("indexTransaction: output %s spent by unconfirmed txn %s not found", h.Hex(), hash.Hex())
+			continue
+		}
+
+		spent = append(spent, *ux)
+	}
+
+	if err := utp.uxouts.put(tx, uxs); err != nil {
+		return err
+	}
+
+	if err := utp.spent.put(tx, hash, spent); err != nil {
+		return err
+	}
+
+	if err := utp.incoming.add(tx, uxArrayAddresses(uxs), hash); err != nil {
+		return err
+	}
+
+	return utp.outgoing.add(tx, uxArrayAddresses(spent), hash)
+}
+
+// unindexTransaction removes an unconfirmed transaction from the output and address indexes
+func (utp *UnconfirmedTransactionPool) unindexTransaction(tx *dbutil.Tx, hash cipher.SHA256) error {
+	uxs, err := utp.unspent.get(tx, hash)
+	if err != nil {
+		return err
+	}
+
+	if err := utp.uxouts.delete(tx, uxs); err != nil {
+		return err
+	}
+
+	if err := utp.incoming.remove(tx, uxArrayAddresses(uxs), hash); err != nil {
+		return err
+	}
+
+	spent, err := utp.spent.get(tx, hash)
+	if err != nil {
+		return err
+	}
+
+	if err := utp.outgoing.remove(tx, uxArrayAddresses(spent), hash); err != nil {
+		return err
+	}
+
+	return utp.spent.delete(tx, hash)
+}
+
 // SetTransactionsAnnounced updates announced time of specific tx
 func (utp *UnconfirmedTransactionPool) SetTransactionsAnnounced(tx *dbutil.Tx, hashes map[cipher.SHA256]int64) error {
 	var txns []*UnconfirmedTransaction
@@ -203,8 +540,8 @@ func (utp *UnconfirmedTransactionPool) SetTransactionsAnnounced(tx *dbutil.Tx, h
 func (utp *UnconfirmedTransactionPool) InjectTransaction(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, verifyParams params.VerifyTxn) (bool, *ErrTxnViolatesSoftConstraint, error) {
 	var isValid int8 = 1
 	var softErr *ErrTxnViolatesSoftConstraint
-	if _, _, err := bc.VerifySingleTxnSoftHardConstraints(tx, txn, verifyParams); err != nil {
-		logger.Warningf("bc.VerifySingleTxnSoftHardConstraints failed for txn %s: %v", txn.TxIDHex(), err)
+	if _, _, err := utp.VerifyTransaction(tx, bc, txn, verifyParams); err != nil {
+		logger.Warningf("VerifyTransaction failed for txn %s: %v", txn.TxIDHex(), err)
 		switch err.(type) {
 		case ErrTxnViolatesSoftConstraint:
 			e := err.(ErrTxnViolatesSoftConstraint)
@@ -257,14 +594,31 @@ func (utp *UnconfirmedTransactionPool) InjectTransaction(tx *dbutil.Tx, bc Block
 	}
 
 	// update unconfirmed unspent
-	if err := utp.unspent.put(tx, hash, coin.CreateUnspents(head.Head, txn)); err != nil {
+	uxs := coin.CreateUnspents(nextBlockHeader(head.Head), txn)
+	if err := utp.unspent.put(tx, hash, uxs); err != nil {
 		logger.Errorf("InjectTransaction put new unspent outputs: %v", err)
 		return false, nil, err
 	}
 
+	if err := utp.indexTransaction(tx, bc, hash, txn); err != nil {
+		logger.Errorf("InjectTransaction index new txn failed: %v", err)
+		return false, nil, err
+	}
+
 	return false, softErr, nil
 }
 
+// nextBlockHeader returns a header for the block after head, which is the earliest block that an unconfirmed
+// transaction can be included in. It is used to create the outputs of unconfirmed transactions,
+// because outputs created with the genesis block header do not have a source transaction and
+// their hashes would not match the outputs that are eventually confirmed.
+func nextBlockHeader(head coin.BlockHeader) coin.BlockHeader {
+	return coin.BlockHeader{
+		Time:  head.Time,
+		BkSeq: head.BkSeq + 1,
+	}
+}
+
 // AllRawTransactions returns underlying coin.Transactions
 func (utp *UnconfirmedTransactionPool) AllRawTransactions(tx *dbutil.Tx) (coin.Transactions, error) {
 	utxns, err := utp.txns.getAll(tx)
@@ -281,6 +635,10 @@ func (utp *UnconfirmedTransactionPool) AllRawTransactions(tx *dbutil.Tx) (coin.T
 
 // Remove a single txn by hash
 func (utp *UnconfirmedTransactionPool) removeTransaction(tx *dbutil.Tx, txHash cipher.SHA256) error {
+	if err := utp.unindexTransaction(tx, txHash); err != nil {
+		return err
+	}
+
 	if err := utp.txns.delete(tx, txHash); err != nil {
 		return err
 	}
@@ -288,7 +646,9 @@ func (utp *UnconfirmedTransactionPool) removeTransaction(tx *dbutil.Tx, txHash c
 	return utp.unspent.delete(tx, txHash)
 }
 
-// RemoveTransactions remove transactions with dbutil.Tx
+// RemoveTransactions remove transactions with dbutil.Tx.
+// Transactions that spend the outputs of removed transactions are not removed,
+// since the outputs become confirmed when their transaction is included in a block.
 func (utp *UnconfirmedTransactionPool) RemoveTransactions(tx *dbutil.Tx, txHashes []cipher.SHA256) error {
 	for i := range txHashes {
 		if err := utp.removeTransaction(tx, txHashes[i]); err != nil {
@@ -302,30 +662,85 @@ func (utp *UnconfirmedTransactionPool) RemoveTransactions(tx *dbutil.Tx, txHashe
 // Refresh checks all unconfirmed txns against the blockchain.
 // If the transaction becomes invalid it is marked invalid.
 // If the transaction becomes valid it is marked valid and is returned to the caller.
+// A transaction that spends the outputs of an invalid unconfirmed transaction is also invalid,
+// since it cannot be confirmed before its parent.
+// Transactions that are missing from the address indexes are indexed.
 func (utp *UnconfirmedTransactionPool) Refresh(tx *dbutil.Tx, bc Blockchainer, verifyParams params.VerifyTxn) ([]cipher.SHA256, error) {
 	utxns, err := utp.txns.getAll(tx)
 	if err != nil {
 		return nil, err
 	}
 
+	// The head block is only needed to verify transactions that spend unconfirmed outputs
+	var head *coin.SignedBlock
+	if utp.chainLimits.MaxDepth != 0 && len(utxns) != 0 {
+		head, err = bc.Head(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	now := time.Now().UTC()
 	var nowValid []cipher.SHA256
 
+	isValid := make(map[cipher.SHA256]bool, len(utxns))
+	parents := make(map[cipher.SHA256][]cipher.SHA256)
 	for _, utxn := range utxns {
-		utxn.Checked = now.UnixNano()
+		hash := utxn.Hash()
 
-		_, _, err := bc.VerifySingleTxnSoftHardConstraints(tx, utxn.Transaction, verifyParams)
+		if indexed, err := utp.spent.hasKey(tx, hash); err != nil {
+			return nil, err
+		} else if !indexed {
+			if err := utp.indexTransaction(tx, bc, hash, utxn.Transaction); err != nil {
+				return nil, err
+			}
+		}
+
+		_, txnParents, err := utp.verifyTxn(tx, bc, head, utxn.Transaction, &verifyParams)
 
 		switch err.(type) {
 		case ErrTxnViolatesSoftConstraint, ErrTxnViolatesHardConstraint:
-			utxn.IsValid = 0
+			isValid[hash] = false
 		case nil:
+			isValid[hash] = true
+		default:
+			return nil, err
+		}
+
+		if len(txnParents) != 0 {
+			parents[hash] = txnParents
+		}
+	}
+
+	// Invalidate the descendants of invalid transactions.
+	// Repeat until no more transactions are invalidated, to walk down the chains.
+	for changed := true; changed; {
+		changed = false
+		for hash, txnParents := range parents {
+			if !isValid[hash] {
+				continue
+			}
+
+			for _, p := range txnParents {
+				if !isValid[p] {
+					isValid[hash] = false
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, utxn := range utxns {
+		utxn.Checked = now.UnixNano()
+
+		if isValid[utxn.Hash()] {
 			if utxn.IsValid == 0 {
 				nowValid = append(nowValid, utxn.Hash())
 			}
 			utxn.IsValid = 1
-		default:
-			return nil, err
+		} else {
+			utxn.IsValid = 0
 		}
 
 		if err := utp.txns.put(tx, &utxn); err != nil {
@@ -337,7 +752,8 @@ func (utp *UnconfirmedTransactionPool) Refresh(tx *dbutil.Tx, bc Blockchainer, v
 }
 
 // RemoveInvalid checks all unconfirmed txns against the blockchain.
-// If a transaction violates hard constraints it is removed from the pool.
+// If a transaction violates hard constraints it is removed from the pool,
+// along with all unconfirmed transactions that descend from it.
 // The transactions that were removed are returned.
 func (utp *UnconfirmedTransactionPool) RemoveInvalid(tx *dbutil.Tx, bc Blockchainer) ([]cipher.SHA256, error) {
 	var removeUtxns []cipher.SHA256
@@ -347,16 +763,50 @@ func (utp *UnconfirmedTransactionPool) RemoveInvalid(tx *dbutil.Tx, bc Blockchai
 		return nil, err
 	}
 
+	// The head block is only needed to verify transactions that spend unconfirmed outputs
+	var head *coin.SignedBlock
+	if utp.chainLimits.MaxDepth != 0 && len(utxns) != 0 {
+		head, err = bc.Head(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	children := make(map[cipher.SHA256][]cipher.SHA256)
 	for _, utxn := range utxns {
-		err := bc.VerifySingleTxnHardConstraints(tx, utxn.Transaction)
+		hash := utxn.Hash()
+
+		_, parents, err := utp.verifyTxn(tx, bc, head, utxn.Transaction, nil)
+
 		if err != nil {
 			switch err.(type) {
 			case ErrTxnViolatesHardConstraint:
-				removeUtxns = append(removeUtxns, utxn.Hash())
+				removeUtxns = append(removeUtxns, hash)
 			default:
 				return nil, err
 			}
 		}
+
+		for _, p := range parents {
+			children[p] = append(children[p], hash)
+		}
+	}
+
+	// Remove the descendants of removed transactions, which can no longer be confirmed
+	removed := make(map[cipher.SHA256]struct{}, len(removeUtxns))
+	for _, h := range removeUtxns {
+		removed[h] = struct{}{}
+	}
+
+	for i := 0; i < len(removeUtxns); i++ {
+		for _, c := range children[removeUtxns[i]] {
+			if _, ok := removed[c]; ok {
+				continue
+			}
+
+			removed[c] = struct{}{}
+			removeUtxns = append(removeUtxns, c)
+		}
 	}
 
 	if err := utp.RemoveTransactions(tx, removeUtxns); err != nil {
@@ -366,6 +816,295 @@ func (utp *UnconfirmedTransactionPool) RemoveInvalid(tx *dbutil.Tx, bc Blockchai
 	return removeUtxns, nil
 }
 
+// VerifyTransaction checks that the transaction does not violate hard or soft constraints,
+// for transactions that are not included in a block.
+// It is the same as Blockchain.VerifySingleTxnSoftHardConstraints, except that the transaction may also
+// spend outputs created by unconfirmed transactions, within the pool's chain limits.
+// Outputs created by unconfirmed transactions are given the head block's header,
+// since they have not accumulated any coin hours yet.
+func (utp *UnconfirmedTransactionPool) VerifyTransaction(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, verifyParams params.VerifyTxn) (*coin.SignedBlock, coin.UxArray, error) {
+	if utp.chainLimits.MaxDepth == 0 {
+		return bc.VerifySingleTxnSoftHardConstraints(tx, txn, verifyParams)
+	}
+
+	head, err := bc.Head(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uxIn, _, err := utp.verifyTxn(tx, bc, head, txn, &verifyParams)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return head, uxIn, nil
+}
+
+// GetOutput returns an output created by an unconfirmed transaction, or nil if it is not found.
+// The output's head is set to the head block the transaction was injected on.
+func (utp *UnconfirmedTransactionPool) GetOutput(tx *dbutil.Tx, hash cipher.SHA256) (*coin.UxOut, error) {
+	return utp.uxouts.get(tx, hash)
+}
+
+// getInputs returns the outputs spent by a transaction, and the hashes of the unconfirmed transactions
+// that created any of them. Inputs are looked up in the blockchain's unspent pool first.
+// If chaining is enabled, inputs that are not confirmed are looked up in the outputs of unconfirmed transactions.
+func (utp *UnconfirmedTransactionPool) getInputs(tx *dbutil.Tx, bc Blockchainer, head *coin.SignedBlock, txn coin.Transaction) (coin.UxArray, []cipher.SHA256, error) {
+	uxIn := make(coin.UxArray, 0, len(txn.In))
+	var parents []cipher.SHA256
+	parentsMap := make(map[cipher.SHA256]struct{})
+
+	for _, h := range txn.In {
+		ux, err := bc.Unspent().Get(tx, h)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if ux == nil && utp.chainLimits.MaxDepth != 0 {
+			ux, err = utp.uxouts.get(tx, h)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if ux != nil {
+				ux.Head = coin.UxHead{
+					Time:  head.Time(),
+					BkSeq: head.Seq(),
+				}
+
+				if _, ok := parentsMap[ux.Body.SrcTransaction]; !ok {
+					parentsMap[ux.Body.SrcTransaction] = struct{}{}
+					parents = append(parents, ux.Body.SrcTransaction)
+				}
+			}
+		}
+
+		if ux == nil {
+			return nil, nil, NewErrTxnViolatesHardConstraint(blockdb.NewErrUnspentNotExist(h.Hex()))
+		}
+
+		uxIn = append(uxIn, *ux)
+	}
+
+	return uxIn, parents, nil
+}
+
+// verifyTxn checks that a transaction does not violate hard constraints, and soft constraints if verifyParams is not nil.
+// Transactions that do not spend outputs of unconfirmed transactions are verified by the blockchain.
+// Returns the outputs spent by the transaction and the unconfirmed transactions that created any of them.
+func (utp *UnconfirmedTransactionPool) verifyTxn(tx *dbutil.Tx, bc Blockchainer, head *coin.SignedBlock, txn coin.Transaction, verifyParams *params.VerifyTxn) (coin.UxArray, []cipher.SHA256, error) {
+	var uxIn coin.UxArray
+	var parents []cipher.SHA256
+	if utp.chainLimits.MaxDepth != 0 {
+		var err error
+		uxIn, parents, err = utp.getInputs(tx, bc, head, txn)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(parents) == 0 {
+		if verifyParams == nil {
+			return nil, nil, bc.VerifySingleTxnHardConstraints(tx, txn)
+		}
+
+		_, uxIn, err := bc.VerifySingleTxnSoftHardConstraints(tx, txn, *verifyParams)
+		return uxIn, nil, err
+	}
+
+	// Hard constraints must be checked before soft constraints
+	if err := utp.verifyChainLimits(tx, parents); err != nil {
+		return nil, parents, err
+	}
+
+	gbHash, err := bc.GenesisHash(tx)
+	if err != nil {
+		return nil, parents, err
+	}
+
+	if err := VerifySingleTxnHardConstraints(txn, head.Head, uxIn, gbHash); err != nil {
+		return nil, parents, err
+	}
+
+	if verifyParams != nil {
+		if err := VerifySingleTxnSoftConstraints(txn, head.Time(), uxIn, *verifyParams); err != nil {
+			return nil, parents, err
+		}
+	}
+
+	return uxIn, parents, nil
+}
+
+// verifyChainLimits checks that a transaction spending outputs of the unconfirmed transactions parents
+// does not exceed the maximum chain depth or number of unconfirmed ancestors
+func (utp *UnconfirmedTransactionPool) verifyChainLimits(tx *dbutil.Tx, parents []cipher.SHA256) error {
+	// depths maps an ancestor to the length of the longest chain of unconfirmed transactions ending with it
+	depths := make(map[cipher.SHA256]uint64)
+
+	var depth func(hash cipher.SHA256) (uint64, error)
+	depth = func(hash cipher.SHA256) (uint64, error) {
+		if d, ok := depths[hash]; ok {
+			return d, nil
+		}
+
+		if uint64(len(depths)) >= utp.chainLimits.MaxAncestors {
+			return 0, NewErrTxnViolatesHardConstraint(ErrUnconfirmedChainTooManyAncestors)
+		}
+
+		// Reserve the entry, so that the ancestor count includes this transaction
+		depths[hash] = 1
+
+		utxn, err := utp.txns.get(tx, hash)
+		if err != nil {
+			return 0, err
+		}
+		if utxn == nil {
+			return 0, fmt.Errorf("unconfirmed transaction %s not found in the pool", hash.Hex())
+		}
+
+		var d uint64 = 1
+		for _, h := range utxn.Transaction.In {
+			ux, err := utp.uxouts.get(tx, h)
+			if err != nil {
+				return 0, err
+			}
+			if ux == nil {
+				continue
+			}
+
+			pd, err := depth(ux.Body.SrcTransaction)
+			if err != nil {
+				return 0, err
+			}
+
+			if pd+1 > d {
+				d = pd + 1
+			}
+		}
+
+		if d > utp.chainLimits.MaxDepth {
+			return 0, NewErrTxnViolatesHardConstraint(ErrUnconfirmedChainTooDeep)
+		}
+
+		depths[hash] = d
+		return d, nil
+	}
+
+	for _, p := range parents {
+		if _, err := depth(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SpendConflict is an output which is spent by two different transactions
+type SpendConflict struct {
+	// The output spent by both transactions
+	UxOut coin.UxOut
+	// The hash of the other transaction
+	Txid cipher.SHA256
+}
+
+// getSpentOutput returns an output spent by a transaction, looking in the blockchain's unspent pool,
+// then in the outputs of unconfirmed transactions. Returns nil if the output is not found
+func (utp *UnconfirmedTransactionPool) getSpentOutput(tx *dbutil.Tx, bc Blockchainer, hash cipher.SHA256) (*coin.UxOut, error) {
+	ux, err := bc.Unspent().Get(tx, hash)
+	if err != nil || ux != nil {
+		return ux, err
+	}
+
+	return utp.uxouts.get(tx, hash)
+}
+
+// GetConflicts returns the outputs spent by txn which are also spent by other unconfirmed transactions.
+// Outputs spent by txn that can not be found are ignored
+func (utp *UnconfirmedTransactionPool) GetConflicts(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction) ([]SpendConflict, error) {
+	hash := txn.Hash()
+
+	// The outputs spent by a transaction in the pool were recorded when it was injected
+	spent, err := utp.spent.get(tx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if spent == nil {
+		for _, h := range txn.In {
+			ux, err := utp.getSpentOutput(tx, bc, h)
+			if err != nil {
+				return nil, err
+			}
+			if ux != nil {
+				spent = append(spent, *ux)
+			}
+		}
+	}
+
+	var conflicts []SpendConflict
+	for _, ux := range spent {
+		uxHash := ux.Hash()
+
+		// The outgoing index has every unconfirmed transaction spending an output of the owner
+		hashes, err := utp.outgoing.get(tx, ux.Body.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range hashes {
+			if h == hash {
+				continue
+			}
+
+			utxn, err := utp.txns.get(tx, h)
+			if err != nil {
+				return nil, err
+			}
+
+			if utxn != nil && containsHash(utxn.Transaction.In, uxHash) {
+				conflicts = append(conflicts, SpendConflict{
+					UxOut: ux,
+					Txid:  h,
+				})
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// VerifyConflict checks that neither of two transactions spending the outputs common violates hard constraints.
+// Returns the common outputs that are unspent or created by unconfirmed transactions.
+// Returns ErrInvalidTxnConflict if either transaction violates hard constraints
+func (utp *UnconfirmedTransactionPool) VerifyConflict(tx *dbutil.Tx, bc Blockchainer, a, b coin.Transaction, common []cipher.SHA256) (coin.UxArray, error) {
+	head, err := bc.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, txn := range []coin.Transaction{a, b} {
+		if _, _, err := utp.verifyTxn(tx, bc, head, txn, nil); err != nil {
+			if _, ok := err.(ErrTxnViolatesHardConstraint); ok {
+				return nil, NewErrInvalidTxnConflict(fmt.Errorf("transaction %d: %v", i+1, err))
+			}
+			return nil, err
+		}
+	}
+
+	uxs := make(coin.UxArray, 0, len(common))
+	for _, h := range common {
+		ux, err := utp.getSpentOutput(tx, bc, h)
+		if err != nil {
+			return nil, err
+		}
+		if ux != nil {
+			uxs = append(uxs, *ux)
+		}
+	}
+
+	return uxs, nil
+}
+
 // FilterKnown returns txn hashes with known ones removed
 func (utp *UnconfirmedTransactionPool) FilterKnown(tx *dbutil.Tx, txns []cipher.SHA256) ([]cipher.SHA256, error) {
 	var unknown []cipher.SHA256
@@ -398,53 +1137,60 @@ func (utp *UnconfirmedTransactionPool) GetKnown(tx *dbutil.Tx, txns []cipher.SHA
 
 // RecvOfAddresses returns unconfirmed receiving uxouts of addresses
 func (utp *UnconfirmedTransactionPool) RecvOfAddresses(tx *dbutil.Tx, bh coin.BlockHeader, addrs []cipher.Address) (coin.AddressUxOuts, error) {
-	addrm := make(map[cipher.Address]struct{}, len(addrs))
+	auxs := make(coin.AddressUxOuts, len(addrs))
 	for _, addr := range addrs {
-		addrm[addr] = struct{}{}
-	}
+		hashes, err := utp.incoming.get(tx, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range hashes {
+			txn, err := utp.txns.get(tx, h)
+			if err != nil {
+				return nil, err
+			}
+
+			if txn == nil {
+				return nil, fmt.Errorf("unconfirmed transaction %s indexed for address %s not found in the pool", h.Hex(), addr.String())
+			}
+
+			for i, o := range txn.Transaction.Out {
+				if o.Address != addr {
+					continue
+				}
 
-	auxs := make(coin.AddressUxOuts, len(addrs))
-	if err := utp.txns.forEach(tx, func(_ cipher.SHA256, txn UnconfirmedTransaction) error {
-		for i, o := range txn.Transaction.Out {
-			if _, ok := addrm[o.Address]; ok {
 				uxout, err := coin.CreateUnspent(bh, txn.Transaction, i)
 				if err != nil {
-					return err
+					return nil, err
 				}
 
-				auxs[o.Address] = append(auxs[o.Address], uxout)
+				auxs[addr] = append(auxs[addr], uxout)
 			}
 		}
-		return nil
-	}); err != nil {
-		return nil, err
 	}
 
 	return auxs, nil
 }
 
-// txnOutputsForAddrs returns unspent outputs assigned to addresses in addrs, created by a set of transactions
-func txnOutputsForAddrs(bh coin.BlockHeader, addrs []cipher.Address, txns []coin.Transaction) (coin.AddressUxOuts, error) {
-	if len(txns) == 0 || len(addrs) == 0 {
-		return nil, nil
-	}
-
-	addrm := make(map[cipher.Address]struct{}, len(addrs))
+// SpendsOfAddresses returns the outputs of addresses that are spent by unconfirmed transactions
+func (utp *UnconfirmedTransactionPool) SpendsOfAddresses(tx *dbutil.Tx, addrs []cipher.Address) (coin.AddressUxOuts, error) {
+	auxs := make(coin.AddressUxOuts, len(addrs))
 	for _, addr := range addrs {
-		addrm[addr] = struct{}{}
-	}
+		hashes, err := utp.outgoing.get(tx, addr)
+		if err != nil {
+			return nil, err
+		}
 
-	auxs := make(coin.AddressUxOuts, len(addrs))
+		for _, h := range hashes {
+			uxs, err := utp.spent.get(tx, h)
+			if err != nil {
+				return nil, err
+			}
 
-	for _, txn := range txns {
-		for i, o := range txn.Out {
-			if _, ok := addrm[o.Address]; ok {
-				uxout, err := coin.CreateUnspent(bh, txn, i)
-				if err != nil {
-					return nil, err
+			for _, ux := range uxs {
+				if ux.Body.Address == addr {
+					auxs[addr] = append(auxs[addr], ux)
 				}
-
-				auxs[o.Address] = append(auxs[o.Address], uxout)
 			}
 		}
 	}
@@ -513,7 +1259,26 @@ func (utp *UnconfirmedTransactionPool) ForEach(tx *dbutil.Tx, f func(cipher.SHA2
 
 // GetUnspentsOfAddr returns unspent outputs of given address in unspent tx pool
 func (utp *UnconfirmedTransactionPool) GetUnspentsOfAddr(tx *dbutil.Tx, addr cipher.Address) (coin.UxArray, error) {
-	return utp.unspent.getByAddr(tx, addr)
+	hashes, err := utp.incoming.get(tx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var uxo coin.UxArray
+	for _, h := range hashes {
+		uxs, err := utp.unspent.get(tx, h)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ux := range uxs {
+			if ux.Body.Address == addr {
+				uxo = append(uxo, ux)
+			}
+		}
+	}
+
+	return uxo, nil
 }
 
 // IsValid can be used as filter function
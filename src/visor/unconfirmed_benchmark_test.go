@@ -0,0 +1,128 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+const (
+	benchmarkPoolTxns  = 5000
+	benchmarkPoolAddrs = 500
+)
+
+// newBenchmarkUnconfirmedPool creates an unconfirmed pool with benchmarkPoolTxns transactions.
+// Each transaction spends an output of the previous transaction and sends coins to two addresses.
+// The transactions are not verified, so that a large pool can be created quickly.
+func newBenchmarkUnconfirmedPool(b *testing.B) (*dbutil.DB, *UnconfirmedTransactionPool, []cipher.Address, func()) {
+	db, shutdown := testutil.PrepareDB(b)
+
+	err := CreateBuckets(db)
+	require.NoError(b, err)
+
+	bc, err := NewBlockchain(db, BlockchainConfig{})
+	require.NoError(b, err)
+
+	utp, err := NewUnconfirmedTransactionPool(db, UnconfirmedChainLimits{})
+	require.NoError(b, err)
+
+	addrs := make([]cipher.Address, benchmarkPoolAddrs)
+	for i := range addrs {
+		addrs[i] = testutil.MakeAddress()
+	}
+
+	bh := coin.BlockHeader{BkSeq: 1}
+	in := cipher.SumSHA256([]byte("genesis"))
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		for i := 0; i < benchmarkPoolTxns; i++ {
+			txn := coin.Transaction{
+				In: []cipher.SHA256{in},
+				Out: []coin.TransactionOutput{
+					{
+						Address: addrs[i%len(addrs)],
+						Coins:   1e6,
+					},
+					{
+						Address: addrs[(i+1)%len(addrs)],
+						Coins:   1e6,
+					},
+				},
+			}
+
+			utxn := NewUnconfirmedTransaction(txn)
+			if err := utp.txns.put(tx, &utxn); err != nil {
+				return err
+			}
+
+			uxs := coin.CreateUnspents(bh, txn)
+			if err := utp.unspent.put(tx, txn.Hash(), uxs); err != nil {
+				return err
+			}
+
+			if err := utp.indexTransaction(tx, bc, txn.Hash(), txn); err != nil {
+				return err
+			}
+
+			in = uxs[0].Hash()
+		}
+
+		return nil
+	})
+	require.NoError(b, err)
+
+	return db, utp, addrs, shutdown
+}
+
+func BenchmarkUnconfirmedGetUnspentsOfAddr(b *testing.B) {
+	db, utp, addrs, shutdown := newBenchmarkUnconfirmedPool(b)
+	defer shutdown()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			_, err := utp.GetUnspentsOfAddr(tx, addrs[i%len(addrs)])
+			return err
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnconfirmedRecvOfAddresses(b *testing.B) {
+	db, utp, addrs, shutdown := newBenchmarkUnconfirmedPool(b)
+	defer shutdown()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			_, err := utp.RecvOfAddresses(tx, coin.BlockHeader{}, addrs[:10])
+			return err
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnconfirmedSpendsOfAddresses(b *testing.B) {
+	db, utp, addrs, shutdown := newBenchmarkUnconfirmedPool(b)
+	defer shutdown()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			_, err := utp.SpendsOfAddresses(tx, addrs[:10])
+			return err
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/util/fee"
@@ -194,8 +195,10 @@ func verifyTxnSoftConstraints(txn coin.Transaction, headTime uint64, uxIn coin.U
 //      * That there are no duplicate outputs
 //      * That the transaction input and output coins do not overflow uint64
 //      * That the transaction input and output hours do not overflow uint64
+//      * That HTLC outputs are not refunded before their lock height
+// genesisHash is the hash of the genesis block, which the signatures of replay protected transactions commit to.
 // NOTE: Double spends are checked against the unspent output pool when querying for uxIn
-func VerifySingleTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray) error {
+func VerifySingleTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray, genesisHash cipher.SHA256) error {
 	// Check for output hours overflow
 	// When verifying a single transaction, this is considered a hard constraint.
 	// For transactions inside of a block, it is a soft constraint.
@@ -213,7 +216,7 @@ func VerifySingleTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader,
 		}
 	}
 
-	if err := verifyTxnHardConstraints(txn, head, uxIn); err != nil {
+	if err := verifyTxnHardConstraints(txn, head, uxIn, genesisHash); err != nil {
 		return NewErrTxnViolatesHardConstraint(err)
 	}
 
@@ -232,18 +235,20 @@ func VerifySingleTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader,
 //      * That there are no duplicate outputs
 //      * That the transaction input and output coins do not overflow uint64
 //      * That the transaction input hours do not overflow uint64
+//      * That HTLC outputs are not refunded before their lock height
+// genesisHash is the hash of the genesis block, which the signatures of replay protected transactions commit to.
 // NOTE: Double spends are checked against the unspent output pool when querying for uxIn
 // NOTE: output hours overflow is treated as a soft constraint for transactions inside of a block, due to a bug
 //       which allowed some blocks to be published with overflowing output hours.
-func VerifyBlockTxnConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray) error {
-	if err := verifyTxnHardConstraints(txn, head, uxIn); err != nil {
+func VerifyBlockTxnConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray, genesisHash cipher.SHA256) error {
+	if err := verifyTxnHardConstraints(txn, head, uxIn, genesisHash); err != nil {
 		return NewErrTxnViolatesHardConstraint(err)
 	}
 
 	return nil
 }
 
-func verifyTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray) error {
+func verifyTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray, genesisHash cipher.SHA256) error {
 	//CHECKLIST: DONE: check for duplicate ux inputs/double spending
 	//     NOTE: Double spends are checked against the unspent output pool when querying for uxIn
 
@@ -265,13 +270,33 @@ func verifyTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn
 	// Check for zero coin outputs
 	// Check valid looking signatures
 
+	// Check that Schnorr transactions are only included in blocks from the activation height
+	if txn.Type == coin.TxnTypeSchnorr && head.BkSeq+1 < params.SchnorrActivationHeight {
+		return errors.New("Schnorr transaction before the activation height")
+	}
+
+	// Check that replay protected transactions are only included in blocks from the activation height
+	if txn.Type == coin.TxnTypeReplayProtected && head.BkSeq+1 < params.ReplayProtectionActivationHeight {
+		return errors.New("Replay protected transaction before the activation height")
+	}
+
+	// Check that transactions spending HTLC outputs are only included in blocks from the activation height
+	if txn.Type == coin.TxnTypeHTLC && head.BkSeq+1 < params.HTLCActivationHeight {
+		return errors.New("HTLC transaction before the activation height")
+	}
+
 	if err := txn.Verify(); err != nil {
 		return err
 	}
 
 	// Checks whether ux inputs exist,
 	// Check that signatures are allowed to spend inputs
-	if err := txn.VerifyInput(uxIn); err != nil {
+	if err := txn.VerifyInputForChain(uxIn, genesisHash); err != nil {
+		return err
+	}
+
+	// Check that HTLC outputs are refunded only once the head block reaches their lock height
+	if err := txn.VerifyHTLCTimelocks(head.BkSeq); err != nil {
 		return err
 	}
 
@@ -302,11 +327,8 @@ func verifyTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn
 // This is distinct from transactions created by other users (i.e. received over the network),
 // and from transactions included in blocks.
 func VerifySingleTxnUserConstraints(txn coin.Transaction) error {
-	for _, o := range txn.Out {
-		if o.Address.Null() {
-			err := errors.New("Transaction output is sent to the null address")
-			return NewErrTxnViolatesUserConstraint(err)
-		}
+	if err := txn.VerifyUserConstraints(); err != nil {
+		return NewErrTxnViolatesUserConstraint(err)
 	}
 
 	return nil
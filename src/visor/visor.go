@@ -5,6 +5,9 @@ All conceptual database operations must use a database transaction.
 Callers of visor methods must ensure they do not make multiple calls without a transaction,
 unless it is determined safe to do so.
 
+Methods that only read from the database do so inside a single database view transaction,
+so they observe a consistent snapshot and are safe to call concurrently with each other and with writes.
+
 Wallet access is also gatewayed by visor, since the wallet data relates to the blockchain database.
 Wallets are conceptually a second database.
 */
@@ -51,6 +54,11 @@ type Config struct {
 	CreateBlockVerifyTxn params.VerifyTxn
 	// Maximum size of a block, in bytes for creating blocks
 	MaxBlockSize uint32
+	// Maximum length of a chain of unconfirmed transactions that an unconfirmed transaction may spend outputs of.
+	// If 0, unconfirmed transactions may only spend confirmed outputs.
+	MaxUnconfirmedChainDepth uint64
+	// Maximum number of unconfirmed transactions that an unconfirmed transaction may descend from
+	MaxUnconfirmedChainAncestors uint64
 
 	// Where the blockchain is saved
 	BlockchainFile string
@@ -77,6 +85,8 @@ type Config struct {
 	EnableSeedAPI bool
 	// wallet crypto type
 	WalletCryptoType wallet.CryptoType
+	// Keep accepting blocks after detecting that the block publisher signed conflicting blocks
+	DisableDoubleSignHalt bool
 }
 
 // NewConfig creates Config
@@ -91,6 +101,9 @@ func NewConfig() Config {
 		CreateBlockVerifyTxn: params.UserVerifyTxn,
 		MaxBlockSize:         params.UserVerifyTxn.MaxTransactionSize,
 
+		MaxUnconfirmedChainDepth:     0,
+		MaxUnconfirmedChainAncestors: 25,
+
 		GenesisAddress:    cipher.Address{},
 		GenesisSignature:  cipher.Sig{},
 		GenesisTimestamp:  0,
@@ -144,6 +157,10 @@ func (c Config) Verify() error {
 		return errors.New("MaxBlockSize must be >= CreateBlockVerifyTxn.MaxTransactionSize")
 	}
 
+	if c.MaxUnconfirmedChainAncestors < c.MaxUnconfirmedChainDepth {
+		return errors.New("MaxUnconfirmedChainAncestors must be >= MaxUnconfirmedChainDepth")
+	}
+
 	return nil
 }
 
@@ -168,6 +185,7 @@ type Historyer interface {
 // Blockchainer is the interface that provides methods for accessing the blockchain data
 type Blockchainer interface {
 	GetGenesisBlock(tx *dbutil.Tx) (*coin.SignedBlock, error)
+	GenesisHash(tx *dbutil.Tx) (cipher.SHA256, error)
 	GetBlocks(tx *dbutil.Tx, seqs []uint64) ([]coin.SignedBlock, error)
 	GetBlocksInRange(tx *dbutil.Tx, start, end uint64) ([]coin.SignedBlock, error)
 	GetLastBlocks(tx *dbutil.Tx, n uint64) ([]coin.SignedBlock, error)
@@ -179,6 +197,7 @@ type Blockchainer interface {
 	HeadSeq(tx *dbutil.Tx) (uint64, bool, error)
 	Time(tx *dbutil.Tx) (uint64, error)
 	NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime uint64) (*coin.Block, error)
+	NewEmptyBlock(tx *dbutil.Tx, currentTime uint64) (*coin.Block, error)
 	ExecuteBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error
 	VerifyBlockTxnConstraints(tx *dbutil.Tx, txn coin.Transaction) error
 	VerifySingleTxnHardConstraints(tx *dbutil.Tx, txn coin.Transaction) error
@@ -198,12 +217,17 @@ type UnconfirmedTransactionPooler interface {
 	FilterKnown(tx *dbutil.Tx, txns []cipher.SHA256) ([]cipher.SHA256, error)
 	GetKnown(tx *dbutil.Tx, txns []cipher.SHA256) (coin.Transactions, error)
 	RecvOfAddresses(tx *dbutil.Tx, bh coin.BlockHeader, addrs []cipher.Address) (coin.AddressUxOuts, error)
+	SpendsOfAddresses(tx *dbutil.Tx, addrs []cipher.Address) (coin.AddressUxOuts, error)
 	GetIncomingOutputs(tx *dbutil.Tx, bh coin.BlockHeader) (coin.UxArray, error)
 	Get(tx *dbutil.Tx, hash cipher.SHA256) (*UnconfirmedTransaction, error)
 	GetFiltered(tx *dbutil.Tx, filter func(tx UnconfirmedTransaction) bool) ([]UnconfirmedTransaction, error)
 	GetHashes(tx *dbutil.Tx, filter func(tx UnconfirmedTransaction) bool) ([]cipher.SHA256, error)
 	ForEach(tx *dbutil.Tx, f func(cipher.SHA256, UnconfirmedTransaction) error) error
 	GetUnspentsOfAddr(tx *dbutil.Tx, addr cipher.Address) (coin.UxArray, error)
+	GetOutput(tx *dbutil.Tx, hash cipher.SHA256) (*coin.UxOut, error)
+	VerifyTransaction(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, verifyParams params.VerifyTxn) (*coin.SignedBlock, coin.UxArray, error)
+	GetConflicts(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction) ([]SpendConflict, error)
+	VerifyConflict(tx *dbutil.Tx, bc Blockchainer, a, b coin.Transaction, common []cipher.SHA256) (coin.UxArray, error)
 	Len(tx *dbutil.Tx) (uint64, error)
 }
 
@@ -216,7 +240,8 @@ type Visor struct {
 	Wallets     *wallet.Service
 	StartedAt   time.Time
 
-	history Historyer
+	history          Historyer
+	doubleSignProofs doubleSignProofs
 }
 
 // NewVisor creates a Visor for managing the blockchain database
@@ -237,6 +262,7 @@ func NewVisor(c Config, db *dbutil.DB) (*Visor, error) {
 	logger.Infof("Max transaction size for transactions when creating blocks is %d", c.CreateBlockVerifyTxn.MaxTransactionSize)
 	logger.Infof("Max decimals for transactions when creating blocks is %d", c.CreateBlockVerifyTxn.MaxDropletPrecision)
 	logger.Infof("Max block size is %d", c.MaxBlockSize)
+	logger.Infof("Max unconfirmed chain depth is %d, max unconfirmed ancestors is %d", c.MaxUnconfirmedChainDepth, c.MaxUnconfirmedChainAncestors)
 
 	// Loads wallet
 	wltServConfig := wallet.Config{
@@ -268,6 +294,14 @@ func NewVisor(c Config, db *dbutil.DB) (*Visor, error) {
 
 	history := historydb.New()
 
+	utp, err := NewUnconfirmedTransactionPool(db, UnconfirmedChainLimits{
+		MaxDepth:     c.MaxUnconfirmedChainDepth,
+		MaxAncestors: c.MaxUnconfirmedChainAncestors,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	if !db.IsReadOnly() {
 		if err := db.Update("build unspent indexes and init history", func(tx *dbutil.Tx) error {
 			headSeq, _, err := bc.HeadSeq(tx)
@@ -279,17 +313,16 @@ func NewVisor(c Config, db *dbutil.DB) (*Visor, error) {
 				return err
 			}
 
+			if err := utp.MaybeBuildIndexes(tx, bc); err != nil {
+				return err
+			}
+
 			return initHistory(tx, bc, history)
 		}); err != nil {
 			return nil, err
 		}
 	}
 
-	utp, err := NewUnconfirmedTransactionPool(db)
-	if err != nil {
-		return nil, err
-	}
-
 	v := &Visor{
 		Config:      c,
 		DB:          db,
@@ -392,7 +425,7 @@ func (vs *Visor) maybeCreateGenesisBlock(tx *dbutil.Tx) error {
 		return err
 	}
 	if gb != nil {
-		return nil
+		return verifyGenesisAddressVersion(gb)
 	}
 
 	logger.Info("Create genesis block")
@@ -417,6 +450,22 @@ func (vs *Visor) maybeCreateGenesisBlock(tx *dbutil.Tx) error {
 	return vs.executeSignedBlock(tx, sb)
 }
 
+// verifyGenesisAddressVersion checks that the outputs of the genesis block have the active address version.
+// The address version of a chain can't be changed once it has a genesis block,
+// because the outputs of addresses with the old version could not be spent
+func verifyGenesisAddressVersion(gb *coin.SignedBlock) error {
+	for _, txn := range gb.Body.Transactions {
+		for _, o := range txn.Out {
+			if o.Address.Version != cipher.AddressVersion {
+				return fmt.Errorf("genesis block output address %s has address version %d, but the address version is %d. The address version of a chain can't be changed once it has a genesis block",
+					o.Address, o.Address.Version, cipher.AddressVersion)
+			}
+		}
+	}
+
+	return nil
+}
+
 // GenesisPreconditions panics if conditions for genesis block are not met
 func (vs *Visor) GenesisPreconditions() {
 	if vs.Config.BlockchainSeckey != (cipher.SecKey{}) {
@@ -475,9 +524,20 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 
 	logger.Infof("Unconfirmed pool has %d transactions pending", len(txns))
 
-	// Filter transactions that violate all constraints
+	// Filter transactions that violate all constraints.
+	// Transactions that spend outputs of unconfirmed transactions are skipped,
+	// they can be included in a later block once their parents are confirmed.
 	var filteredTxns coin.Transactions
+	var nChained int
 	for _, txn := range txns {
+		if chained, err := vs.spendsUnconfirmedOutputs(tx, txn); err != nil {
+			return coin.SignedBlock{}, err
+		} else if chained {
+			logger.Debugf("Transaction %s spends unconfirmed outputs, skipping", txn.TxIDHex())
+			nChained++
+			continue
+		}
+
 		if _, _, err := vs.Blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.CreateBlockVerifyTxn); err != nil {
 			switch err.(type) {
 			case ErrTxnViolatesHardConstraint, ErrTxnViolatesSoftConstraint:
@@ -490,7 +550,11 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 		}
 	}
 
-	nRemoved := len(txns) - len(filteredTxns)
+	if nChained > 0 {
+		logger.Infof("CreateBlock skipped %d transactions spending unconfirmed outputs", nChained)
+	}
+
+	nRemoved := len(txns) - len(filteredTxns) - nChained
 	if nRemoved > 0 {
 		logger.Infof("CreateBlock ignored %d transactions violating constraints", nRemoved)
 	}
@@ -553,6 +617,29 @@ func (vs *Visor) CreateAndExecuteBlock() (coin.SignedBlock, error) {
 	return sb, err
 }
 
+// CreateAndExecuteEmptyBlock creates a SignedBlock without transactions and executes it.
+// Returns ErrEmptyBlockNotActivated before the empty block activation height
+func (vs *Visor) CreateAndExecuteEmptyBlock() (coin.SignedBlock, error) {
+	if !vs.Config.IsBlockPublisher {
+		logger.Panic("Only a block publisher node can create blocks")
+	}
+
+	var sb coin.SignedBlock
+
+	err := vs.DB.Update("CreateAndExecuteEmptyBlock", func(tx *dbutil.Tx) error {
+		b, err := vs.Blockchain.NewEmptyBlock(tx, uint64(time.Now().UTC().Unix()))
+		if err != nil {
+			return err
+		}
+
+		sb = vs.signBlock(*b)
+
+		return vs.executeSignedBlock(tx, sb)
+	})
+
+	return sb, err
+}
+
 // ExecuteSignedBlock adds a block to the blockchain, or returns error.
 // Blocks must be executed in sequence, and be signed by a block publisher node
 func (vs *Visor) ExecuteSignedBlock(b coin.SignedBlock) error {
@@ -568,6 +655,20 @@ func (vs *Visor) executeSignedBlock(tx *dbutil.Tx, b coin.SignedBlock) error {
 		return err
 	}
 
+	if err := vs.verifyNotHalted(tx, b.Seq()); err != nil {
+		return err
+	}
+
+	// A validly signed block that does not build on the head block was signed on a fork.
+	// The block at the head seq that it builds on conflicts with the head block
+	head, err := vs.Blockchain.Head(tx)
+	if err != nil && err != blockdb.ErrNoHeadBlock {
+		return err
+	}
+	if head != nil && b.Seq() == head.Seq()+1 && b.Head.PrevHash != head.HashHeader() {
+		return ErrBlockPrevHashMismatch
+	}
+
 	if err := vs.Blockchain.ExecuteBlock(tx, &b); err != nil {
 		return err
 	}
@@ -659,7 +760,68 @@ func (vs *Visor) unconfirmedOutgoingOutputs(tx *dbutil.Tx) (coin.UxArray, error)
 		inputs = append(inputs, txn.In...)
 	}
 
-	return vs.Blockchain.Unspent().GetArray(tx, inputs)
+	return vs.getSpentOutputs(tx, inputs)
+}
+
+// getSpentOutputs returns the outputs for a set of input hashes of unconfirmed transactions.
+// Inputs are looked up in the unspent pool, then in the outputs created by unconfirmed transactions.
+// Returns blockdb.ErrUnspentNotExist if any of the inputs do not exist.
+func (vs *Visor) getSpentOutputs(tx *dbutil.Tx, inputs []cipher.SHA256) (coin.UxArray, error) {
+	uxa, err := vs.Blockchain.Unspent().GetArray(tx, inputs)
+	switch err.(type) {
+	case nil:
+		return uxa, nil
+	case blockdb.ErrUnspentNotExist:
+		// Some of the inputs may be outputs of unconfirmed transactions
+		if vs.Config.MaxUnconfirmedChainDepth == 0 {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	uxa = make(coin.UxArray, 0, len(inputs))
+	for _, h := range inputs {
+		ux, err := vs.Blockchain.Unspent().Get(tx, h)
+		if err != nil {
+			return nil, err
+		}
+
+		if ux == nil {
+			ux, err = vs.Unconfirmed.GetOutput(tx, h)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if ux == nil {
+			return nil, blockdb.NewErrUnspentNotExist(h.Hex())
+		}
+
+		uxa = append(uxa, *ux)
+	}
+
+	return uxa, nil
+}
+
+// spendsUnconfirmedOutputs returns true if any of the transaction's inputs are outputs of unconfirmed transactions
+func (vs *Visor) spendsUnconfirmedOutputs(tx *dbutil.Tx, txn coin.Transaction) (bool, error) {
+	if vs.Config.MaxUnconfirmedChainDepth == 0 {
+		return false, nil
+	}
+
+	for _, h := range txn.In {
+		ux, err := vs.Unconfirmed.GetOutput(tx, h)
+		if err != nil {
+			return false, err
+		}
+
+		if ux != nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
 // UnconfirmedIncomingOutputs returns all outputs that would be created by unconfirmed transactions
@@ -968,7 +1130,7 @@ func (vs *Visor) InjectUserTransactionTx(tx *dbutil.Tx, txn coin.Transaction) (b
 		return false, nil, nil, err
 	}
 
-	head, inputs, err := vs.Blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, params.UserVerifyTxn)
+	head, inputs, err := vs.Unconfirmed.VerifyTransaction(tx, vs.Blockchain, txn, params.UserVerifyTxn)
 	if err != nil {
 		return false, nil, nil, err
 	}
@@ -1539,6 +1701,34 @@ func (vs *Visor) GetAllUnconfirmedTransactions() ([]UnconfirmedTransaction, erro
 	return txns, nil
 }
 
+// GetUnconfirmedPoolSize returns the number and total size in bytes of the valid unconfirmed transactions,
+// which are the transactions that can be included in the next block
+func (vs *Visor) GetUnconfirmedPoolSize() (int, uint64, error) {
+	var n int
+	var size uint64
+
+	if err := vs.DB.View("GetUnconfirmedPoolSize", func(tx *dbutil.Tx) error {
+		return vs.Unconfirmed.ForEach(tx, func(_ cipher.SHA256, txn UnconfirmedTransaction) error {
+			if txn.IsValid != 1 {
+				return nil
+			}
+
+			txnSize, err := txn.Transaction.Size()
+			if err != nil {
+				return err
+			}
+
+			n++
+			size += uint64(txnSize)
+			return nil
+		})
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	return n, size, nil
+}
+
 // GetAllUnconfirmedTransactionsVerbose returns all unconfirmed transactions with verbose transaction input data
 func (vs *Visor) GetAllUnconfirmedTransactionsVerbose() ([]UnconfirmedTransaction, [][]TransactionInput, error) {
 	var txns []UnconfirmedTransaction
@@ -1584,7 +1774,7 @@ func (vs *Visor) getTransactionInputsForUnconfirmedTxns(tx *dbutil.Tx, txns []Un
 			continue
 		}
 
-		txnInputs, err := vs.getTransactionInputs(tx, headTime, txn.Transaction.In)
+		txnInputs, err := vs.getUnconfirmedTransactionInputs(tx, headTime, txn.Transaction.In)
 		if err != nil {
 			return nil, err
 		}
@@ -1595,6 +1785,54 @@ func (vs *Visor) getTransactionInputsForUnconfirmedTxns(tx *dbutil.Tx, txns []Un
 	return inputs, nil
 }
 
+// getUnconfirmedTransactionInputs returns []TransactionInput for the inputs of an unconfirmed transaction.
+// Inputs that are outputs of other unconfirmed transactions are read from the unconfirmed pool,
+// the other inputs are read from the history.
+func (vs *Visor) getUnconfirmedTransactionInputs(tx *dbutil.Tx, headTime uint64, inputs []cipher.SHA256) ([]TransactionInput, error) {
+	if vs.Config.MaxUnconfirmedChainDepth == 0 {
+		return vs.getTransactionInputs(tx, headTime, inputs)
+	}
+
+	ret := make([]TransactionInput, len(inputs))
+
+	var confirmed []cipher.SHA256
+	var confirmedIdx []int
+	for i, h := range inputs {
+		ux, err := vs.Unconfirmed.GetOutput(tx, h)
+		if err != nil {
+			return nil, err
+		}
+
+		if ux == nil {
+			confirmed = append(confirmed, h)
+			confirmedIdx = append(confirmedIdx, i)
+			continue
+		}
+
+		r, err := NewTransactionInput(*ux, headTime)
+		if err != nil {
+			logger.WithError(err).Error("getUnconfirmedTransactionInputs NewTransactionInput failed")
+			return nil, err
+		}
+		ret[i] = r
+	}
+
+	if len(confirmed) == 0 {
+		return ret, nil
+	}
+
+	confirmedInputs, err := vs.getTransactionInputs(tx, headTime, confirmed)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range confirmedInputs {
+		ret[confirmedIdx[i]] = r
+	}
+
+	return ret, nil
+}
+
 // getFeeCalcTimeForTransaction returns the time against which a transaction's fee should be calculated.
 // The genesis block has no inputs and thus no fee to calculate, so it returns nil.
 // A confirmed transaction's fee was calculated from the previous block's head time, when it was executed.
@@ -1966,7 +2204,7 @@ func (vs *Visor) UnconfirmedSpendsOfAddresses(addrs []cipher.Address) (coin.Addr
 
 	if err := vs.DB.View("UnconfirmedSpendsOfAddresses", func(tx *dbutil.Tx) error {
 		var err error
-		outs, err = vs.unconfirmedSpendsOfAddresses(tx, addrs)
+		outs, err = vs.Unconfirmed.SpendsOfAddresses(tx, addrs)
 		return err
 	}); err != nil {
 		return nil, err
@@ -1975,39 +2213,6 @@ func (vs *Visor) UnconfirmedSpendsOfAddresses(addrs []cipher.Address) (coin.Addr
 	return outs, nil
 }
 
-// unconfirmedSpendsOfAddresses returns all unconfirmed coin.UxOut spends of addresses
-func (vs *Visor) unconfirmedSpendsOfAddresses(tx *dbutil.Tx, addrs []cipher.Address) (coin.AddressUxOuts, error) {
-	txns, err := vs.Unconfirmed.AllRawTransactions(tx)
-	if err != nil {
-		return nil, err
-	}
-
-	var inputs []cipher.SHA256
-	for _, txn := range txns {
-		inputs = append(inputs, txn.In...)
-	}
-
-	uxa, err := vs.Blockchain.Unspent().GetArray(tx, inputs)
-	if err != nil {
-		return nil, err
-	}
-
-	outs := make(coin.AddressUxOuts, len(addrs))
-
-	addrm := make(map[cipher.Address]struct{}, len(addrs))
-	for _, addr := range addrs {
-		addrm[addr] = struct{}{}
-	}
-
-	for _, ux := range uxa {
-		if _, ok := addrm[ux.Body.Address]; ok {
-			outs[ux.Body.Address] = append(outs[ux.Body.Address], ux)
-		}
-	}
-
-	return outs, nil
-}
-
 // SetTransactionsAnnounced updates announced time of specific tx
 func (vs *Visor) SetTransactionsAnnounced(hashes map[cipher.SHA256]int64) error {
 	if len(hashes) == 0 {
@@ -2027,7 +2232,7 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 
 	auxs := make(coin.AddressUxOuts, len(addrs))
 	recvUxs := make(coin.AddressUxOuts, len(addrs))
-	var uxa coin.UxArray
+	spendUxs := make(coin.AddressUxOuts, len(addrs))
 	var head *coin.SignedBlock
 
 	if err := vs.DB.View("GetBalanceOfAddrs", func(tx *dbutil.Tx) error {
@@ -2037,27 +2242,18 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 			return err
 		}
 
-		// Get all transactions from the unconfirmed pool
-		txns, err := vs.Unconfirmed.AllRawTransactions(tx)
+		// Create predicted unspent outputs from the unconfirmed transactions.
+		// They are created like the outputs indexed by the unconfirmed pool,
+		// so that outputs spent by other unconfirmed transactions can be subtracted
+		recvUxs, err = vs.Unconfirmed.RecvOfAddresses(tx, nextBlockHeader(head.Head), addrs)
 		if err != nil {
-			return err
+			return fmt.Errorf("RecvOfAddresses failed when checking addresses balance: %v", err)
 		}
 
-		// Create predicted unspent outputs from the unconfirmed transactions
-		recvUxs, err = txnOutputsForAddrs(head.Head, addrs, txns)
+		// Get unspents being spent by the unconfirmed transactions
+		spendUxs, err = vs.Unconfirmed.SpendsOfAddresses(tx, addrs)
 		if err != nil {
-			return err
-		}
-
-		var inputs []cipher.SHA256
-		for _, txn := range txns {
-			inputs = append(inputs, txn.In...)
-		}
-
-		// Get unspents for the inputs being spent
-		uxa, err = vs.Blockchain.Unspent().GetArray(tx, inputs)
-		if err != nil {
-			return fmt.Errorf("GetArray failed when checking addresses balance: %v", err)
+			return fmt.Errorf("SpendsOfAddresses failed when checking addresses balance: %v", err)
 		}
 
 		// Get unspents owned by the addresses
@@ -2071,20 +2267,6 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 		return nil, err
 	}
 
-	// Build all unconfirmed transaction inputs that are associated with the addresses
-	spendUxs := make(coin.AddressUxOuts, len(addrs))
-
-	addrm := make(map[cipher.Address]struct{}, len(addrs))
-	for _, addr := range addrs {
-		addrm[addr] = struct{}{}
-	}
-
-	for _, ux := range uxa {
-		if _, ok := addrm[ux.Body.Address]; ok {
-			spendUxs[ux.Body.Address] = append(spendUxs[ux.Body.Address], ux)
-		}
-	}
-
 	var bps []wallet.BalancePair
 
 	headTime := head.Time()
@@ -2097,7 +2279,9 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 
 		outUxs := spendUxs[addr]
 		inUxs := recvUxs[addr]
-		predictedUxs := uxs.Sub(outUxs).Add(inUxs)
+		// Incoming outputs are added before subtracting outgoing outputs,
+		// because unconfirmed transactions may spend the outputs of other unconfirmed transactions
+		predictedUxs := uxs.Add(inUxs).Sub(outUxs)
 
 		coins, err := uxs.Coins()
 		if err != nil {
@@ -2174,7 +2358,7 @@ func (vs *Visor) VerifyTxnVerbose(txn *coin.Transaction) ([]wallet.UxBalance, bo
 			return err
 		}
 
-		uxa, err = vs.Blockchain.Unspent().GetArray(tx, txn.In)
+		uxa, err = vs.getSpentOutputs(tx, txn.In)
 		switch err.(type) {
 		case nil:
 			// For unconfirmed transactions, use the blockchain head time to calculate hours
@@ -2240,7 +2424,12 @@ func (vs *Visor) VerifyTxnVerbose(txn *coin.Transaction) ([]wallet.UxBalance, bo
 			return err
 		}
 
-		return VerifySingleTxnHardConstraints(*txn, head.Head, uxa)
+		gbHash, err := vs.Blockchain.GenesisHash(tx)
+		if err != nil {
+			return err
+		}
+
+		return VerifySingleTxnHardConstraints(*txn, head.Head, uxa, gbHash)
 	})
 
 	// If we were able to query the inputs, return the verbose inputs to the caller
@@ -2277,6 +2466,14 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 		allAddrsMap[a] = struct{}{}
 	}
 
+	if params.SpendUnconfirmedChange && vs.Config.MaxUnconfirmedChainDepth == 0 {
+		return nil, ErrUnconfirmedChainsDisabled
+	}
+
+	// Outputs spent by unconfirmed transactions are always ignored when spending unconfirmed change,
+	// since the change is created by spending them
+	ignoreUnconfirmed := params.IgnoreUnconfirmed || params.SpendUnconfirmedChange
+
 	var auxs coin.AddressUxOuts
 	if len(params.Wallet.UxOuts) != 0 {
 		// Check if any of the outputs are in an unconfirmed spend
@@ -2296,7 +2493,7 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 			unconfirmedSpends = append(unconfirmedSpends, txn.In...)
 		}
 
-		if params.IgnoreUnconfirmed {
+		if ignoreUnconfirmed {
 			// Filter unconfirmed spends
 			prevLen := len(hashesMap)
 			for _, h := range unconfirmedSpends {
@@ -2317,6 +2514,30 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 			}
 		}
 
+		// Separate the requested outputs that are unconfirmed change
+		var changeUxs coin.UxArray
+		if params.SpendUnconfirmedChange {
+			change, err := vs.unconfirmedChangeOutputs(tx, allAddrsMap)
+			if err != nil {
+				return nil, err
+			}
+
+			changeMap := make(map[cipher.SHA256]coin.UxOut, len(change))
+			for _, ux := range change {
+				changeMap[ux.Hash()] = ux
+			}
+
+			confirmed := make([]cipher.SHA256, 0, len(params.Wallet.UxOuts))
+			for _, h := range params.Wallet.UxOuts {
+				if ux, ok := changeMap[h]; ok {
+					changeUxs = append(changeUxs, ux)
+				} else {
+					confirmed = append(confirmed, h)
+				}
+			}
+			params.Wallet.UxOuts = confirmed
+		}
+
 		// Retrieve the uxouts from the pool.
 		// An error is returned if any do not exist
 		uxouts, err := vs.Blockchain.Unspent().GetArray(tx, params.Wallet.UxOuts)
@@ -2324,6 +2545,8 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 			return nil, err
 		}
 
+		uxouts = append(uxouts, changeUxs...)
+
 		// Build coin.AddressUxOuts map, and check that the address is in the wallets
 		auxs = make(coin.AddressUxOuts)
 		for _, o := range uxouts {
@@ -2348,19 +2571,107 @@ func (vs *Visor) getCreateTransactionAuxs(tx *dbutil.Tx, params wallet.CreateTra
 
 		// Get unspent outputs, while checking that there are no unconfirmed outputs
 		var err error
-		auxs, err = vs.getUnspentsForSpending(tx, addrs, params.IgnoreUnconfirmed)
+		auxs, err = vs.getUnspentsForSpending(tx, addrs, ignoreUnconfirmed)
 		if err != nil {
 			return nil, err
 		}
+
+		if params.SpendUnconfirmedChange {
+			change, err := vs.unconfirmedChangeOutputs(tx, allAddrsMap)
+			if err != nil {
+				return nil, err
+			}
+
+			addrsMap := make(map[cipher.Address]struct{}, len(addrs))
+			for _, a := range addrs {
+				addrsMap[a] = struct{}{}
+			}
+
+			if auxs == nil {
+				auxs = make(coin.AddressUxOuts)
+			}
+
+			for _, ux := range change {
+				if _, ok := addrsMap[ux.Body.Address]; ok {
+					auxs[ux.Body.Address] = append(auxs[ux.Body.Address], ux)
+				}
+			}
+		}
 	}
 
 	return auxs, nil
 }
 
+// unconfirmedChangeOutputs returns the outputs of valid unconfirmed transactions that were created by the wallet
+// and sent back to one of its addresses. A transaction was created by the wallet if all of the outputs
+// it spends belong to the wallet. Outputs that are spent by other unconfirmed transactions are excluded.
+// The outputs are given the next block's header, since they have not accumulated any coin hours yet.
+func (vs *Visor) unconfirmedChangeOutputs(tx *dbutil.Tx, walletAddrs map[cipher.Address]struct{}) (coin.UxArray, error) {
+	head, err := vs.Blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	utxns, err := vs.Unconfirmed.GetFiltered(tx, All)
+	if err != nil {
+		return nil, err
+	}
+
+	spent := make(map[cipher.SHA256]struct{})
+	for _, utxn := range utxns {
+		for _, h := range utxn.Transaction.In {
+			spent[h] = struct{}{}
+		}
+	}
+
+	var change coin.UxArray
+	for _, utxn := range utxns {
+		if !IsValid(utxn) {
+			continue
+		}
+
+		inputs, err := vs.getSpentOutputs(tx, utxn.Transaction.In)
+		if err != nil {
+			switch err.(type) {
+			case blockdb.ErrUnspentNotExist:
+				continue
+			default:
+				return nil, err
+			}
+		}
+
+		ownsInputs := true
+		for _, ux := range inputs {
+			if _, ok := walletAddrs[ux.Body.Address]; !ok {
+				ownsInputs = false
+				break
+			}
+		}
+
+		if !ownsInputs {
+			continue
+		}
+
+		for _, ux := range coin.CreateUnspents(nextBlockHeader(head.Head), utxn.Transaction) {
+			if _, ok := walletAddrs[ux.Body.Address]; !ok {
+				continue
+			}
+
+			if _, ok := spent[ux.Hash()]; ok {
+				continue
+			}
+
+			change = append(change, ux)
+		}
+	}
+
+	return change, nil
+}
+
 // getUnspentsForSpending returns the unspent outputs for a set of addresses,
 // but returns an error if any of the unspents are in the unconfirmed outputs pool
 func (vs *Visor) getUnspentsForSpending(tx *dbutil.Tx, addrs []cipher.Address, ignoredUnconfirmed bool) (coin.AddressUxOuts, error) {
-	unconfirmedAuxs, err := vs.unconfirmedSpendsOfAddresses(tx, addrs)
+	unconfirmedAuxs, err := vs.Unconfirmed.SpendsOfAddresses(tx, addrs)
 	if err != nil {
 		err = fmt.Errorf("UnconfirmedSpendsOfAddresses failed: %v", err)
 		return nil, err
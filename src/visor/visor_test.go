@@ -261,6 +261,44 @@ func TestHistorydbVerifier(t *testing.T) {
 
 }
 
+func TestVisorInitAddressVersion(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedChainLimits{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		Unconfirmed: unconfirmed,
+		Blockchain:  bc,
+		DB:          db,
+		history:     historydb.New(),
+	}
+
+	addGenesisBlockToVisor(t, v)
+	require.NoError(t, v.Init())
+
+	// The address version can't be changed once the chain has a genesis block
+	cipher.AddressVersion = params.AddressVersion + 5
+	defer func() {
+		cipher.AddressVersion = params.AddressVersion
+	}()
+
+	err = v.Init()
+	require.EqualError(t, err, fmt.Sprintf("genesis block output address %s has address version %d, but the address version is %d. The address version of a chain can't be changed once it has a genesis block",
+		genAddress, params.AddressVersion, params.AddressVersion+5))
+}
+
 func TestVisorCreateBlock(t *testing.T) {
 	when := uint64(time.Now().UTC().Unix())
 
@@ -271,7 +309,7 @@ func TestVisorCreateBlock(t *testing.T) {
 		Pubkey: genPublic,
 	})
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedChainLimits{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -497,7 +535,7 @@ func TestVisorInjectTransaction(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedChainLimits{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -637,6 +675,402 @@ func TestVisorInjectTransaction(t *testing.T) {
 	testutil.RequireError(t, err, "Transaction violates user constraint: Transaction output is sent to the null address")
 }
 
+func newChainedTxnsVisor(t *testing.T, db *dbutil.DB, limits UnconfirmedChainLimits) *Visor {
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, limits)
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.DBPath = db.Path()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+	cfg.MaxUnconfirmedChainDepth = limits.MaxDepth
+	cfg.MaxUnconfirmedChainAncestors = limits.MaxAncestors
+
+	v := &Visor{
+		Config:      cfg,
+		Unconfirmed: unconfirmed,
+		Blockchain:  bc,
+		DB:          db,
+		history:     historydb.New(),
+	}
+
+	addGenesisBlockToVisor(t, v)
+
+	return v
+}
+
+// makeChainedSpendTxns creates n transactions, each spending the change output of the previous one,
+// starting from ux
+func makeChainedSpendTxns(t *testing.T, ux coin.UxOut, toAddr cipher.Address, coins uint64, n int) coin.Transactions {
+	var txns coin.Transactions
+	for i := 0; i < n; i++ {
+		txn := makeSpendTx(t, coin.UxArray{ux}, []cipher.SecKey{genSecret}, toAddr, coins)
+		txns = append(txns, txn)
+
+		uxs := coin.CreateUnspents(coin.BlockHeader{BkSeq: 1}, txn)
+		require.Equal(t, genAddress, uxs[1].Body.Address)
+		ux = uxs[1]
+	}
+
+	return txns
+}
+
+func TestVisorInjectChainedTransactions(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{
+		MaxDepth:     2,
+		MaxAncestors: 3,
+	})
+
+	var gb *coin.SignedBlock
+	err := db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.Blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	toAddr := testutil.MakeAddress()
+	var coins uint64 = 10e6
+
+	txns := makeChainedSpendTxns(t, uxs[0], toAddr, coins, 4)
+
+	// The first transaction spends a confirmed output,
+	// the next two spend unconfirmed outputs within the chain limits
+	for _, txn := range txns[:3] {
+		known, softErr, err := v.InjectForeignTransaction(txn)
+		require.NoError(t, err)
+		require.Nil(t, softErr)
+		require.False(t, known)
+	}
+
+	// The fourth transaction exceeds the maximum chain depth
+	_, _, err = v.InjectForeignTransaction(txns[3])
+	require.Equal(t, NewErrTxnViolatesHardConstraint(ErrUnconfirmedChainTooDeep), err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		length, err := v.Unconfirmed.Len(tx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), length)
+
+		ux, err := v.Unconfirmed.GetOutput(tx, txns[3].In[0])
+		require.NoError(t, err)
+		require.NotNil(t, ux)
+		require.Equal(t, txns[2].Hash(), ux.Body.SrcTransaction)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The predicted balance includes the whole chain
+	bps, err := v.GetBalanceOfAddrs([]cipher.Address{genAddress, toAddr})
+	require.NoError(t, err)
+	require.Equal(t, genCoins, bps[0].Confirmed.Coins)
+	require.Equal(t, genCoins-3*coins, bps[0].Predicted.Coins)
+	require.Equal(t, uint64(0), bps[1].Confirmed.Coins)
+	require.Equal(t, 3*coins, bps[1].Predicted.Coins)
+
+	// Only the transaction that spends confirmed outputs is included in the next block,
+	// the remaining transactions stay in the pool
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+	require.Equal(t, coin.Transactions{txns[0]}, sb.Body.Transactions)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		removed, err := v.Unconfirmed.RemoveInvalid(tx, v.Blockchain)
+		require.NoError(t, err)
+		require.Empty(t, removed)
+
+		hashes, err := v.Unconfirmed.GetHashes(tx, IsValid)
+		require.NoError(t, err)
+		require.Len(t, hashes, 2)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The chain is now shorter, so the fourth transaction can be injected
+	_, softErr, err := v.InjectForeignTransaction(txns[3])
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		sb, err := v.createBlock(tx, sb.Time()+10)
+		require.NoError(t, err)
+		require.Equal(t, coin.Transactions{txns[1]}, sb.Body.Transactions)
+		return v.executeSignedBlock(tx, sb)
+	})
+	require.NoError(t, err)
+}
+
+func TestVisorInjectChainedTransactionsDisabled(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{})
+
+	var gb *coin.SignedBlock
+	err := db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.Blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txns := makeChainedSpendTxns(t, uxs[0], testutil.MakeAddress(), 10e6, 2)
+
+	_, _, err = v.InjectForeignTransaction(txns[0])
+	require.NoError(t, err)
+
+	_, _, err = v.InjectForeignTransaction(txns[1])
+	require.Equal(t, NewErrTxnViolatesHardConstraint(blockdb.NewErrUnspentNotExist(txns[1].In[0].Hex())), err)
+}
+
+func TestGetCreateTransactionAuxsSpendUnconfirmedChange(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{
+		MaxDepth:     2,
+		MaxAncestors: 2,
+	})
+
+	var gb *coin.SignedBlock
+	err := db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.Blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	toAddr := testutil.MakeAddress()
+	txns := makeChainedSpendTxns(t, uxs[0], toAddr, 10e6, 1)
+
+	_, _, err = v.InjectForeignTransaction(txns[0])
+	require.NoError(t, err)
+
+	change := coin.CreateUnspents(nextBlockHeader(gb.Head), txns[0])[1]
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		// The only confirmed output is spent by an unconfirmed transaction
+		_, err := v.getCreateTransactionAuxs(tx, wallet.CreateTransactionParams{}, []cipher.Address{genAddress})
+		require.Equal(t, wallet.ErrSpendingUnconfirmed, err)
+
+		// The change output is spendable, the output sent to another address is not
+		auxs, err := v.getCreateTransactionAuxs(tx, wallet.CreateTransactionParams{
+			SpendUnconfirmedChange: true,
+		}, []cipher.Address{genAddress})
+		require.NoError(t, err)
+		require.Equal(t, coin.AddressUxOuts{
+			genAddress: coin.UxArray{change},
+		}, auxs)
+
+		// The change output can be selected explicitly
+		auxs, err = v.getCreateTransactionAuxs(tx, wallet.CreateTransactionParams{
+			SpendUnconfirmedChange: true,
+			Wallet: wallet.CreateTransactionWalletParams{
+				UxOuts: []cipher.SHA256{change.Hash()},
+			},
+		}, []cipher.Address{genAddress})
+		require.NoError(t, err)
+		require.Equal(t, coin.AddressUxOuts{
+			genAddress: coin.UxArray{change},
+		}, auxs)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	v.Config.MaxUnconfirmedChainDepth = 0
+	err = db.View("", func(tx *dbutil.Tx) error {
+		_, err := v.getCreateTransactionAuxs(tx, wallet.CreateTransactionParams{
+			SpendUnconfirmedChange: true,
+		}, []cipher.Address{genAddress})
+		require.Equal(t, ErrUnconfirmedChainsDisabled, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUnconfirmedRemoveInvalidChainedTransactions(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{
+		MaxDepth:     5,
+		MaxAncestors: 5,
+	})
+
+	var gb *coin.SignedBlock
+	err := db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.Blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txns := makeChainedSpendTxns(t, uxs[0], testutil.MakeAddress(), 10e6, 3)
+
+	for _, txn := range txns {
+		_, _, err := v.InjectForeignTransaction(txn)
+		require.NoError(t, err)
+	}
+
+	// Confirm a transaction that double spends the first transaction's input
+	doubleSpend := makeSpendTx(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), 20e6)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.Blockchain.NewBlock(tx, coin.Transactions{doubleSpend}, uint64(time.Now().UTC().Unix()))
+		require.NoError(t, err)
+
+		err = v.executeSignedBlock(tx, v.signBlock(*b))
+		require.NoError(t, err)
+
+		// The first transaction is invalid, and its descendants are removed with it
+		removed, err := v.Unconfirmed.RemoveInvalid(tx, v.Blockchain)
+		require.NoError(t, err)
+		require.Equal(t, []cipher.SHA256{txns[0].Hash(), txns[1].Hash(), txns[2].Hash()}, removed)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		length, err := v.Unconfirmed.Len(tx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), length)
+
+		ux, err := v.Unconfirmed.GetOutput(tx, txns[1].In[0])
+		require.NoError(t, err)
+		require.Nil(t, ux)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// requireSameUxOuts checks that two UxArrays contain the same outputs, ignoring their order and heads
+func requireSameUxOuts(t *testing.T, expected, actual coin.UxArray) {
+	require.Len(t, actual, len(expected))
+	require.Equal(t, expected.Set(), actual.Set())
+}
+
+func TestUnconfirmedAddressIndexes(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newChainedTxnsVisor(t, db, UnconfirmedChainLimits{
+		MaxDepth:     5,
+		MaxAncestors: 5,
+	})
+
+	var gb *coin.SignedBlock
+	err := db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.Blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	toAddr := testutil.MakeAddress()
+	otherAddr := testutil.MakeAddress()
+	txns := makeChainedSpendTxns(t, uxs[0], toAddr, 10e6, 2)
+
+	for _, txn := range txns {
+		_, _, err := v.InjectForeignTransaction(txn)
+		require.NoError(t, err)
+	}
+
+	bh := nextBlockHeader(gb.Head)
+	out0 := coin.CreateUnspents(bh, txns[0])
+	out1 := coin.CreateUnspents(bh, txns[1])
+
+	requireIndexes := func(tx *dbutil.Tx) {
+		addrs := []cipher.Address{genAddress, toAddr, otherAddr}
+
+		recv, err := v.Unconfirmed.RecvOfAddresses(tx, bh, addrs)
+		require.NoError(t, err)
+		require.Len(t, recv, 2)
+		requireSameUxOuts(t, coin.UxArray{out0[1], out1[1]}, recv[genAddress])
+		requireSameUxOuts(t, coin.UxArray{out0[0], out1[0]}, recv[toAddr])
+
+		spends, err := v.Unconfirmed.SpendsOfAddresses(tx, addrs)
+		require.NoError(t, err)
+		require.Len(t, spends, 1)
+		requireSameUxOuts(t, coin.UxArray{uxs[0], out0[1]}, spends[genAddress])
+
+		unspents, err := v.Unconfirmed.GetUnspentsOfAddr(tx, toAddr)
+		require.NoError(t, err)
+		requireSameUxOuts(t, coin.UxArray{out0[0], out1[0]}, unspents)
+
+		unspents, err = v.Unconfirmed.GetUnspentsOfAddr(tx, otherAddr)
+		require.NoError(t, err)
+		require.Empty(t, unspents)
+	}
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		requireIndexes(tx)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The indexes are rebuilt if they are missing
+	utp := v.Unconfirmed.(*UnconfirmedTransactionPool)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		for _, bkt := range [][]byte{UnconfirmedSpentBkt, UnconfirmedAddrIncomingBkt, UnconfirmedAddrOutgoingBkt} {
+			require.NoError(t, dbutil.Reset(tx, bkt))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return utp.MaybeBuildIndexes(tx, v.Blockchain)
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		requireIndexes(tx)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Removed transactions are removed from the indexes
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return v.Unconfirmed.RemoveTransactions(tx, []cipher.SHA256{txns[0].Hash(), txns[1].Hash()})
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		for _, bkt := range [][]byte{UnconfirmedSpentBkt, UnconfirmedAddrIncomingBkt, UnconfirmedAddrOutgoingBkt, UnconfirmedUxOutsBkt} {
+			n, err := dbutil.Len(tx, bkt)
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), n, string(bkt))
+		}
+
+		recv, err := v.Unconfirmed.RecvOfAddresses(tx, bh, []cipher.Address{genAddress, toAddr})
+		require.NoError(t, err)
+		require.Empty(t, recv)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func makeOverflowCoinsSpendTx(t *testing.T, uxs coin.UxArray, keys []cipher.SecKey, toAddr cipher.Address) coin.Transaction {
 	spendTx := coin.Transaction{}
 	var totalHours uint64
@@ -1922,7 +2356,7 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedChainLimits{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -2054,7 +2488,7 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedChainLimits{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -2704,6 +3138,21 @@ func TestGetCreateTransactionAuxs(t *testing.T) {
 
 				return true
 			})).Return(tc.getArrayRet, nil)
+			unconfirmed.On("SpendsOfAddresses", matchTxn, mock.Anything).Return(func(_ *dbutil.Tx, addrs []cipher.Address) coin.AddressUxOuts {
+				// The pool's address index returns the spent outputs owned by the addresses
+				addrm := make(map[cipher.Address]struct{}, len(addrs))
+				for _, a := range addrs {
+					addrm[a] = struct{}{}
+				}
+
+				auxs := make(coin.AddressUxOuts)
+				for _, ux := range tc.getArrayRet {
+					if _, ok := addrm[ux.Body.Address]; ok {
+						auxs[ux.Body.Address] = append(auxs[ux.Body.Address], ux)
+					}
+				}
+				return auxs
+			}, nil)
 			if tc.getUnspentsOfAddrsRet != nil {
 				unspent.On("GetUnspentsOfAddrs", matchTxn, tc.addrs).Return(tc.getUnspentsOfAddrsRet, nil)
 			}
@@ -3023,6 +3472,7 @@ func TestVerifyTxnVerbose(t *testing.T) {
 
 			bc.On("Unspent").Return(unspent)
 			bc.On("Head", matchTxn).Return(&head, nil)
+			bc.On("GenesisHash", matchTxn).Return(head.HashHeader(), nil)
 			if tc.getHistoryTxnRet != nil {
 				bc.On("GetSignedBlockBySeq", matchTxn, tc.getHistoryTxnRet.BlockSeq-1).Return(tc.getSignedBlocksBySeqRet, tc.getSignedBlocksBySeqErr)
 			}
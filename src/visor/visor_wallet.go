@@ -130,8 +130,13 @@ func (vs *Visor) CreateTransaction(p wallet.CreateTransactionParams) (*coin.Tran
 				return err
 			}
 
+			gbHash, err := vs.replayProtectionGenesisHash(tx, head)
+			if err != nil {
+				return err
+			}
+
 			// Create and sign transaction
-			txn, inputs, err = w.CreateAndSignTransactionAdvanced(p, auxs, head.Time())
+			txn, inputs, err = w.CreateAndSignTransactionAdvanced(p, auxs, head.Time(), gbHash)
 			if err != nil {
 				logger.WithError(err).Error("CreateAndSignTransactionAdvanced failed")
 				return err
@@ -145,7 +150,56 @@ func (vs *Visor) CreateTransaction(p wallet.CreateTransactionParams) (*coin.Tran
 				return err
 			}
 
-			if _, _, err := vs.Blockchain.VerifySingleTxnSoftHardConstraints(tx, *txn, params.UserVerifyTxn); err != nil {
+			if _, _, err := vs.Unconfirmed.VerifyTransaction(tx, vs.Blockchain, *txn, params.UserVerifyTxn); err != nil {
+				logger.WithError(err).Error("Created transaction violates transaction constraints")
+				return err
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return txn, inputs, nil
+}
+
+// CreateHTLCSpend creates a transaction that claims or refunds the confirmed outputs locked by an HTLC.
+// Outputs spent by unconfirmed transactions are ignored
+func (vs *Visor) CreateHTLCSpend(p wallet.HTLCSpendParams) (*coin.Transaction, []wallet.UxBalance, error) {
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	var txn *coin.Transaction
+	var inputs []wallet.UxBalance
+
+	if err := vs.Wallets.ViewSecrets(p.WalletID, p.Password, func(w *wallet.Wallet) error {
+		return vs.DB.View("CreateHTLCSpend", func(tx *dbutil.Tx) error {
+			head, err := vs.Blockchain.Head(tx)
+			if err != nil {
+				logger.WithError(err).Error("Blockchain.Head failed")
+				return err
+			}
+
+			addr := p.Contract.Address()
+			auxs, err := vs.getUnspentsForSpending(tx, []cipher.Address{addr}, true)
+			if err != nil {
+				return err
+			}
+
+			txn, inputs, err = w.CreateAndSignHTLCSpend(p, auxs[addr], head.Time())
+			if err != nil {
+				logger.WithError(err).Error("CreateAndSignHTLCSpend failed")
+				return err
+			}
+
+			if err := VerifySingleTxnUserConstraints(*txn); err != nil {
+				logger.WithError(err).Error("Created transaction violates transaction constraints")
+				return err
+			}
+
+			if _, _, err := vs.Unconfirmed.VerifyTransaction(tx, vs.Blockchain, *txn, params.UserVerifyTxn); err != nil {
 				logger.WithError(err).Error("Created transaction violates transaction constraints")
 				return err
 			}
@@ -187,8 +241,13 @@ func (vs *Visor) CreateTransactionDeprecated(wltID string, password []byte, coin
 				return err
 			}
 
+			gbHash, err := vs.replayProtectionGenesisHash(tx, head)
+			if err != nil {
+				return err
+			}
+
 			// Create and sign transaction
-			txn, err = w.CreateAndSignTransaction(auxs, head.Time(), coins, dest)
+			txn, err = w.CreateAndSignTransaction(auxs, head.Time(), coins, dest, gbHash)
 			if err != nil {
 				logger.WithError(err).Error("CreateAndSignTransaction failed")
 				return err
@@ -215,3 +274,19 @@ func (vs *Visor) CreateTransactionDeprecated(wltID string, password []byte, coin
 
 	return txn, nil
 }
+
+// replayProtectionGenesisHash returns the genesis hash that wallet transactions are signed for,
+// or nil if the next block does not accept replay protected transactions
+func (vs *Visor) replayProtectionGenesisHash(tx *dbutil.Tx, head *coin.SignedBlock) (*cipher.SHA256, error) {
+	if head.Seq()+1 < params.ReplayProtectionActivationHeight {
+		return nil, nil
+	}
+
+	gbHash, err := vs.Blockchain.GenesisHash(tx)
+	if err != nil {
+		logger.WithError(err).Error("Blockchain.GenesisHash failed")
+		return nil, err
+	}
+
+	return &gbHash, nil
+}
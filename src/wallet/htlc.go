@@ -0,0 +1,157 @@
+package wallet
+
+import (
+	"errors"
+	"math"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/fee"
+)
+
+var (
+	// ErrHTLCInvalidPreimage is returned if the preimage of an HTLC claim does not match the hashlock
+	ErrHTLCInvalidPreimage = NewError(errors.New("preimage does not match the HTLC hashlock"))
+	// ErrHTLCUnknownUxOut is returned if an output spent by an HTLC claim or refund is not locked by the HTLC
+	ErrHTLCUnknownUxOut = NewError(errors.New("uxout is not locked by the HTLC"))
+)
+
+// HTLCSpendParams defines the parameters for claiming or refunding the coins locked by an HTLC
+type HTLCSpendParams struct {
+	WalletID string
+	Password []byte
+	Contract coin.HTLC
+	// Preimage of the hashlock. If set, the coins are claimed by the recipient,
+	// otherwise they are refunded to the sender
+	Preimage *cipher.SHA256
+	// Address that receives the coins. Defaults to the recipient for a claim and to the sender for a refund
+	To *cipher.Address
+}
+
+// Validate validates HTLCSpendParams
+func (p HTLCSpendParams) Validate() error {
+	if p.WalletID == "" {
+		return ErrMissingWalletID
+	}
+
+	if err := p.Contract.Verify(); err != nil {
+		return NewError(err)
+	}
+
+	if p.Preimage != nil && cipher.SumSHA256(p.Preimage[:]) != p.Contract.Hashlock {
+		return ErrHTLCInvalidPreimage
+	}
+
+	if p.To != nil && p.To.Null() {
+		return ErrNullAddressTo
+	}
+
+	return nil
+}
+
+// IsClaim returns true if the coins are claimed by the recipient, false if they are refunded to the sender
+func (p HTLCSpendParams) IsClaim() bool {
+	return p.Preimage != nil
+}
+
+// Spender returns the address that signs the claim or refund
+func (p HTLCSpendParams) Spender() cipher.Address {
+	if p.IsClaim() {
+		return p.Contract.Recipient
+	}
+	return p.Contract.Sender
+}
+
+func (p HTLCSpendParams) to() cipher.Address {
+	if p.To != nil {
+		return *p.To
+	}
+	return p.Spender()
+}
+
+// CreateAndSignHTLCSpend creates and signs a transaction that claims or refunds the HTLC outputs in uxouts.
+// The spender of the HTLC must be an address of the wallet.
+// All of the coins are sent to one output, and the input hours are split by BurnFactor to meet the fee requirement.
+// NOTE: Caller must ensure that the wallet is unlocked if it is encrypted
+func (w *Wallet) CreateAndSignHTLCSpend(p HTLCSpendParams, uxouts coin.UxArray, headTime uint64) (*coin.Transaction, []UxBalance, error) {
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	if p.WalletID != w.Filename() {
+		return nil, nil, ErrUnknownWalletID
+	}
+
+	if len(uxouts) == 0 {
+		return nil, nil, ErrNoUnspents
+	}
+
+	if len(uxouts) > math.MaxUint16/2 {
+		return nil, nil, NewError(errors.New("too many HTLC outputs to spend"))
+	}
+
+	addr := p.Contract.Address()
+	for _, ux := range uxouts {
+		if ux.Body.Address != addr {
+			return nil, nil, ErrHTLCUnknownUxOut
+		}
+	}
+
+	entry, ok := w.GetEntry(p.Spender())
+	if !ok {
+		return nil, nil, ErrUnknownAddress
+	}
+
+	inputs, err := NewUxBalances(headTime, uxouts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var coins, hours uint64
+	for _, in := range inputs {
+		coins, err = coin.AddUint64(coins, in.Coins)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		hours, err = coin.AddUint64(hours, in.Hours)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if hours == 0 {
+		return nil, nil, fee.ErrTxnNoFee
+	}
+
+	feeHours := fee.RequiredFee(hours, params.UserVerifyTxn.BurnFactor)
+	if err := fee.VerifyTransactionFeeForHours(hours-feeHours, feeHours, params.UserVerifyTxn.BurnFactor); err != nil {
+		return nil, nil, err
+	}
+
+	txn := coin.Transaction{}
+	keys := make([]cipher.SecKey, len(inputs))
+	for i, in := range inputs {
+		txn.PushInput(in.Hash)
+		keys[i] = entry.Secret
+	}
+
+	txn.PushOutput(p.to(), coins, hours-feeHours)
+	txn.SignInputs(keys)
+
+	for i := range inputs {
+		if p.IsClaim() {
+			txn.PushHTLCWitness(coin.NewHTLCClaimWitness(uint16(i), p.Contract, *p.Preimage))
+		} else {
+			txn.PushHTLCWitness(coin.NewHTLCRefundWitness(uint16(i), p.Contract))
+		}
+	}
+
+	if err := txn.UpdateHeader(); err != nil {
+		logger.Critical().WithError(err).Error("txn.UpdateHeader failed")
+		return nil, nil, err
+	}
+
+	return &txn, inputs, nil
+}
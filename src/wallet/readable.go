@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"strconv"
 
@@ -135,6 +137,97 @@ func NewReadableWallet(w *Wallet) *ReadableWallet {
 	}
 }
 
+// entriesEncodingCache caches the JSON encoding of a wallet's entries,
+// so that saving a wallet only encodes the entries that changed since it was last saved.
+// Wallets with many addresses spend most of the time saving on encoding the entries.
+// The whole wallet file is still written on each save, so the disk writes grow with the number of entries
+type entriesEncodingCache struct {
+	coinType CoinType
+	entries  []Entry
+	encoded  [][]byte
+}
+
+// save saves the wallet to filename. The file is the same as the file written by ReadableWallet.Save
+func (c *entriesEncodingCache) save(w *Wallet, filename string) error {
+	coinType := w.coin()
+	if coinType != c.coinType {
+		c.erase()
+		c.coinType = coinType
+	}
+
+	meta, err := json.MarshalIndent(w.Meta, "    ", "    ")
+	if err != nil {
+		return err
+	}
+
+	encoded := make([][]byte, len(w.Entries))
+	size := len(meta)
+	for i, e := range w.Entries {
+		if i < len(c.entries) && c.entries[i] == e {
+			encoded[i] = c.encoded[i]
+		} else {
+			encoded[i], err = json.MarshalIndent(NewReadableEntry(coinType, e), "        ", "    ")
+			if err != nil {
+				return err
+			}
+		}
+
+		size += len(encoded[i]) + 10
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(size + 64)
+
+	buf.WriteString("{\n    \"meta\": ")
+	buf.Write(meta)
+	buf.WriteString(",\n    \"entries\": [")
+	for i, b := range encoded {
+		if i != 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n        ")
+		buf.Write(b)
+	}
+	if len(encoded) != 0 {
+		buf.WriteString("\n    ")
+	}
+	buf.WriteString("]\n}")
+
+	if err := file.SaveBinary(filename, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	// Wipe the replaced entries, which may contain secret keys
+	for i := range c.entries {
+		if i >= len(encoded) || &c.encoded[i][0] != &encoded[i][0] {
+			wipeBytes(c.encoded[i])
+			c.entries[i].Secret = cipher.SecKey{}
+		}
+	}
+
+	c.entries = append(c.entries[:0], w.Entries...)
+	c.encoded = encoded
+
+	return nil
+}
+
+// erase wipes the cached entries
+func (c *entriesEncodingCache) erase() {
+	for i := range c.entries {
+		wipeBytes(c.encoded[i])
+		c.entries[i].Secret = cipher.SecKey{}
+	}
+
+	c.entries = nil
+	c.encoded = nil
+}
+
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
 // LoadReadableWallet loads a ReadableWallet from disk
 func LoadReadableWallet(filename string) (*ReadableWallet, error) {
 	w := &ReadableWallet{}
@@ -160,6 +253,7 @@ func (rw *ReadableWallet) ToWallet() (*Wallet, error) {
 	}
 
 	w.Entries = ets
+	w.indexEntries()
 
 	return w, nil
 }
@@ -3,6 +3,7 @@ package wallet
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -16,8 +17,10 @@ type BalanceGetter interface {
 
 // Service wallet service struct
 type Service struct {
+	// Guards the wallets and firstAddrIDMap maps.
+	// Each wallet has its own lock, so that operations on a wallet do not block the other wallets.
 	sync.RWMutex
-	wallets         Wallets
+	wallets         map[string]*serviceWallet
 	firstAddrIDMap  map[string]string // Key: first address in wallet; Value: wallet id
 	walletDirectory string
 	cryptoType      CryptoType
@@ -25,6 +28,21 @@ type Service struct {
 	enableSeedAPI   bool
 }
 
+// serviceWallet is a wallet managed by the Service.
+// The wallet is never modified in place. Updates are made to a copy of the wallet,
+// which replaces the wallet once it is saved, so that readers holding the wallet are not affected.
+type serviceWallet struct {
+	sync.RWMutex
+	wallet   *Wallet
+	encoding entriesEncodingCache
+}
+
+// save saves w to the wallet directory, encoding only the entries that changed since the last save.
+// The whole wallet file is rewritten
+func (sw *serviceWallet) save(w *Wallet, dir string) error {
+	return sw.encoding.save(w, filepath.Join(dir, w.Filename()))
+}
+
 // Config wallet service config
 type Config struct {
 	WalletDir       string
@@ -36,6 +54,7 @@ type Config struct {
 // NewService new wallet service
 func NewService(c Config) (*Service, error) {
 	serv := &Service{
+		wallets:         make(map[string]*serviceWallet),
 		firstAddrIDMap:  make(map[string]string),
 		cryptoType:      c.CryptoType,
 		enableWalletAPI: c.EnableWalletAPI,
@@ -63,7 +82,11 @@ func NewService(c Config) (*Service, error) {
 		return nil, fmt.Errorf("failed to load all wallets: %v", err)
 	}
 
-	serv.wallets = serv.removeDup(w)
+	for id, wlt := range serv.removeDup(w) {
+		serv.wallets[id] = &serviceWallet{
+			wallet: wlt,
+		}
+	}
 
 	return serv, nil
 }
@@ -71,13 +94,13 @@ func NewService(c Config) (*Service, error) {
 // CreateWallet creates a wallet with the given wallet file name and options.
 // A address will be automatically generated by default.
 func (serv *Service) CreateWallet(wltName string, options Options, bg BalanceGetter) (*Wallet, error) {
-	serv.Lock()
-	defer serv.Unlock()
 	if !serv.enableWalletAPI {
 		return nil, ErrWalletAPIDisabled
 	}
 	if wltName == "" {
+		serv.RLock()
 		wltName = serv.generateUniqueWalletFilename()
+		serv.RUnlock()
 	}
 
 	return serv.loadWallet(wltName, options, bg)
@@ -90,26 +113,33 @@ func (serv *Service) loadWallet(wltName string, options Options, bg BalanceGette
 		options.CryptoType = serv.cryptoType
 	}
 
+	// The wallet is created without holding the service lock, since scanning addresses can be slow
 	w, err := NewWalletScanAhead(wltName, options, bg)
 	if err != nil {
 		return nil, err
 	}
 
+	serv.Lock()
+	defer serv.Unlock()
+
 	// Check for duplicate wallets by initial seed
 	if _, ok := serv.firstAddrIDMap[w.Entries[0].Address.String()]; ok {
 		return nil, ErrSeedUsed
 	}
 
-	if err := serv.wallets.add(w); err != nil {
-		return nil, err
+	if _, ok := serv.wallets[w.Filename()]; ok {
+		return nil, ErrWalletNameConflict
+	}
+
+	sw := &serviceWallet{
+		wallet: w,
 	}
 
-	if err := w.Save(serv.walletDirectory); err != nil {
-		// If save fails, remove the added wallet
-		serv.wallets.remove(w.Filename())
+	if err := sw.save(w, serv.walletDirectory); err != nil {
 		return nil, err
 	}
 
+	serv.wallets[w.Filename()] = sw
 	serv.firstAddrIDMap[w.Entries[0].Address.String()] = w.Filename()
 
 	return w.clone(), nil
@@ -118,7 +148,7 @@ func (serv *Service) loadWallet(wltName string, options Options, bg BalanceGette
 func (serv *Service) generateUniqueWalletFilename() string {
 	wltName := NewWalletFilename()
 	for {
-		if w := serv.wallets.get(wltName); w == nil {
+		if _, ok := serv.wallets[wltName]; !ok {
 			break
 		}
 		wltName = NewWalletFilename()
@@ -127,87 +157,114 @@ func (serv *Service) generateUniqueWalletFilename() string {
 	return wltName
 }
 
-// EncryptWallet encrypts wallet with password
-func (serv *Service) EncryptWallet(wltID string, password []byte) (*Wallet, error) {
-	serv.Lock()
-	defer serv.Unlock()
-	if !serv.enableWalletAPI {
-		return nil, ErrWalletAPIDisabled
+// getServiceWallet returns the wallet of given id. The wallet's lock is not held.
+func (serv *Service) getServiceWallet(wltID string) (*serviceWallet, error) {
+	serv.RLock()
+	defer serv.RUnlock()
+
+	sw := serv.wallets[wltID]
+	if sw == nil {
+		return nil, ErrWalletNotExist
 	}
+	return sw, nil
+}
 
-	w, err := serv.getWallet(wltID)
+// viewWallet calls f with the wallet of given id while holding the wallet's read lock.
+// The wallet is not copied, so f must not modify or retain it.
+func (serv *Service) viewWallet(wltID string, f func(w *Wallet) error) error {
+	sw, err := serv.getServiceWallet(wltID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if w.IsEncrypted() {
-		return nil, ErrWalletEncrypted
+	sw.RLock()
+	defer sw.RUnlock()
+
+	return f(sw.wallet)
+}
+
+// updateWallet calls f with a copy of the wallet of given id while holding the wallet's write lock.
+// If f succeeds, the copy is saved and replaces the wallet.
+// Returns the updated wallet, which must not be modified.
+func (serv *Service) updateWallet(wltID string, f func(w *Wallet) error) (*Wallet, error) {
+	sw, err := serv.getServiceWallet(wltID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := w.Lock(password, serv.cryptoType); err != nil {
+	sw.Lock()
+	defer sw.Unlock()
+
+	w := sw.wallet.clone()
+	if err := f(w); err != nil {
 		return nil, err
 	}
 
 	// Save to disk first
-	if err := w.Save(serv.walletDirectory); err != nil {
+	if err := sw.save(w, serv.walletDirectory); err != nil {
 		return nil, err
 	}
 
-	// Sets the encrypted wallet
-	serv.wallets.set(w)
+	sw.wallet = w
 	return w, nil
 }
 
-// DecryptWallet decrypts wallet with password
-func (serv *Service) DecryptWallet(wltID string, password []byte) (*Wallet, error) {
-	serv.Lock()
-	defer serv.Unlock()
+// EncryptWallet encrypts wallet with password
+func (serv *Service) EncryptWallet(wltID string, password []byte) (*Wallet, error) {
 	if !serv.enableWalletAPI {
 		return nil, ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltID)
+	w, err := serv.updateWallet(wltID, func(w *Wallet) error {
+		if w.IsEncrypted() {
+			return ErrWalletEncrypted
+		}
+
+		return w.Lock(password, serv.cryptoType)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Returns error if wallet is not encrypted
-	if !w.IsEncrypted() {
-		return nil, ErrWalletNotEncrypted
-	}
+	return w.clone(), nil
+}
 
-	// Unlocks the wallet
-	unlockWlt, err := w.Unlock(password)
-	if err != nil {
-		return nil, err
+// DecryptWallet decrypts wallet with password
+func (serv *Service) DecryptWallet(wltID string, password []byte) (*Wallet, error) {
+	if !serv.enableWalletAPI {
+		return nil, ErrWalletAPIDisabled
 	}
 
-	// Updates the wallet file
-	if err := unlockWlt.Save(serv.walletDirectory); err != nil {
+	w, err := serv.updateWallet(wltID, func(w *Wallet) error {
+		// Returns error if wallet is not encrypted
+		if !w.IsEncrypted() {
+			return ErrWalletNotEncrypted
+		}
+
+		// Unlocks the wallet
+		unlockWlt, err := w.Unlock(password)
+		if err != nil {
+			return err
+		}
+
+		*w = *unlockWlt
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Sets the decrypted wallet in memory
-	serv.wallets.set(unlockWlt)
-	return unlockWlt, nil
+	return w.clone(), nil
 }
 
 // NewAddresses generate address entries in given wallet,
 // return nil if wallet does not exist.
 // Set password as nil if the wallet is not encrypted, otherwise the password must be provided.
 func (serv *Service) NewAddresses(wltID string, password []byte, num uint64) ([]cipher.Address, error) {
-	serv.Lock()
-	defer serv.Unlock()
-
 	if !serv.enableWalletAPI {
 		return nil, ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltID)
-	if err != nil {
-		return nil, err
-	}
-
 	var addrs []cipher.Address
 	f := func(wlt *Wallet) error {
 		var err error
@@ -215,50 +272,43 @@ func (serv *Service) NewAddresses(wltID string, password []byte, num uint64) ([]
 		return err
 	}
 
-	if w.IsEncrypted() {
-		if err := w.GuardUpdate(password, f); err != nil {
-			return nil, err
+	if _, err := serv.updateWallet(wltID, func(w *Wallet) error {
+		if w.IsEncrypted() {
+			return w.GuardUpdate(password, f)
 		}
-	} else {
+
 		if len(password) != 0 {
-			return nil, ErrWalletNotEncrypted
+			return ErrWalletNotEncrypted
 		}
 
-		if err := f(w); err != nil {
-			return nil, err
-		}
-	}
-
-	// Save the wallet first
-	if err := w.Save(serv.walletDirectory); err != nil {
+		return f(w)
+	}); err != nil {
 		return nil, err
 	}
 
-	serv.wallets.set(w)
-
 	return addrs, nil
 }
 
 // GetSkycoinAddresses returns all addresses in given wallet
 func (serv *Service) GetSkycoinAddresses(wltID string) ([]cipher.Address, error) {
-	serv.RLock()
-	defer serv.RUnlock()
 	if !serv.enableWalletAPI {
 		return nil, ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltID)
-	if err != nil {
+	var addrs []cipher.Address
+	if err := serv.viewWallet(wltID, func(w *Wallet) error {
+		var err error
+		addrs, err = w.GetSkycoinAddresses()
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
-	return w.GetSkycoinAddresses()
+	return addrs, nil
 }
 
 // GetWallet returns wallet by id
 func (serv *Service) GetWallet(wltID string) (*Wallet, error) {
-	serv.RLock()
-	defer serv.RUnlock()
 	if !serv.enableWalletAPI {
 		return nil, ErrWalletAPIDisabled
 	}
@@ -268,71 +318,71 @@ func (serv *Service) GetWallet(wltID string) (*Wallet, error) {
 
 // returns the clone of the wallet of given id
 func (serv *Service) getWallet(wltID string) (*Wallet, error) {
-	w := serv.wallets.get(wltID)
-	if w == nil {
-		return nil, ErrWalletNotExist
+	var wlt *Wallet
+	if err := serv.viewWallet(wltID, func(w *Wallet) error {
+		wlt = w.clone()
+		return nil
+	}); err != nil {
+		return nil, err
 	}
-	return w.clone(), nil
+
+	return wlt, nil
 }
 
 // GetWallets returns all wallet clones
 func (serv *Service) GetWallets() (Wallets, error) {
-	serv.RLock()
-	defer serv.RUnlock()
 	if !serv.enableWalletAPI {
 		return nil, ErrWalletAPIDisabled
 	}
 
+	serv.RLock()
+	defer serv.RUnlock()
+
 	wlts := make(Wallets, len(serv.wallets))
-	for k, w := range serv.wallets {
-		wlts[k] = w.clone()
+	for k, sw := range serv.wallets {
+		sw.RLock()
+		wlts[k] = sw.wallet.clone()
+		sw.RUnlock()
 	}
 	return wlts, nil
 }
 
 // CreateAndSignTransaction creates and signs a transaction from wallet.
-// Set the password as nil if the wallet is not encrypted, otherwise the password must be provided
-func (serv *Service) CreateAndSignTransaction(wltID string, password []byte, auxs coin.AddressUxOuts, headTime, coins uint64, dest cipher.Address) (*coin.Transaction, error) {
-	serv.RLock()
-	defer serv.RUnlock()
+// Set the password as nil if the wallet is not encrypted, otherwise the password must be provided.
+// If genesisHash is not nil, the transaction is replay protected for the chain of that genesis block
+func (serv *Service) CreateAndSignTransaction(wltID string, password []byte, auxs coin.AddressUxOuts, headTime, coins uint64, dest cipher.Address, genesisHash *cipher.SHA256) (*coin.Transaction, error) {
 	if !serv.enableWalletAPI {
 		return nil, ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltID)
-	if err != nil {
-		return nil, err
-	}
-
 	var tx *coin.Transaction
 	f := func(wlt *Wallet) error {
 		var err error
-		tx, err = wlt.CreateAndSignTransaction(auxs, headTime, coins, dest)
+		tx, err = wlt.CreateAndSignTransaction(auxs, headTime, coins, dest, genesisHash)
 		return err
 	}
 
-	if w.IsEncrypted() {
-		if err := w.GuardView(password, f); err != nil {
-			return nil, err
+	if err := serv.viewWallet(wltID, func(w *Wallet) error {
+		if w.IsEncrypted() {
+			return w.GuardView(password, f)
 		}
-	} else {
+
 		if len(password) != 0 {
-			return nil, ErrWalletNotEncrypted
+			return ErrWalletNotEncrypted
 		}
 
-		if err := f(w); err != nil {
-			return nil, err
-		}
+		return f(w)
+	}); err != nil {
+		return nil, err
 	}
+
 	return tx, nil
 }
 
 // CreateAndSignTransactionAdvanced creates and signs a transaction based upon CreateTransactionParams.
-// Set the password as nil if the wallet is not encrypted, otherwise the password must be provided
-func (serv *Service) CreateAndSignTransactionAdvanced(params CreateTransactionParams, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []UxBalance, error) {
-	serv.RLock()
-	defer serv.RUnlock()
-
+// Set the password as nil if the wallet is not encrypted, otherwise the password must be provided.
+// If genesisHash is not nil, the transaction is replay protected for the chain of that genesis block
+func (serv *Service) CreateAndSignTransactionAdvanced(params CreateTransactionParams, auxs coin.AddressUxOuts, headTime uint64, genesisHash *cipher.SHA256) (*coin.Transaction, []UxBalance, error) {
 	if !serv.enableWalletAPI {
 		return nil, nil, ErrWalletAPIDisabled
 	}
@@ -341,34 +391,32 @@ func (serv *Service) CreateAndSignTransactionAdvanced(params CreateTransactionPa
 		return nil, nil, err
 	}
 
-	w, err := serv.getWallet(params.Wallet.ID)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	// Check if the wallet needs a password
-	if w.IsEncrypted() {
-		if len(params.Wallet.Password) == 0 {
-			return nil, nil, ErrMissingPassword
+	var tx *coin.Transaction
+	var inputs []UxBalance
+	if err := serv.viewWallet(params.Wallet.ID, func(w *Wallet) error {
+		// Check if the wallet needs a password
+		if w.IsEncrypted() {
+			if len(params.Wallet.Password) == 0 {
+				return ErrMissingPassword
+			}
+		} else {
+			if len(params.Wallet.Password) != 0 {
+				return ErrWalletNotEncrypted
+			}
 		}
-	} else {
-		if len(params.Wallet.Password) != 0 {
-			return nil, nil, ErrWalletNotEncrypted
+
+		if w.IsEncrypted() {
+			return w.GuardView(params.Wallet.Password, func(wlt *Wallet) error {
+				var err error
+				tx, inputs, err = wlt.CreateAndSignTransactionAdvanced(params, auxs, headTime, genesisHash)
+				return err
+			})
 		}
-	}
 
-	var tx *coin.Transaction
-	var inputs []UxBalance
-	if w.IsEncrypted() {
-		err = w.GuardView(params.Wallet.Password, func(wlt *Wallet) error {
-			var err error
-			tx, inputs, err = wlt.CreateAndSignTransactionAdvanced(params, auxs, headTime)
-			return err
-		})
-	} else {
-		tx, inputs, err = w.CreateAndSignTransactionAdvanced(params, auxs, headTime)
-	}
-	if err != nil {
+		var err error
+		tx, inputs, err = w.CreateAndSignTransactionAdvanced(params, auxs, headTime, genesisHash)
+		return err
+	}); err != nil {
 		return nil, nil, err
 	}
 
@@ -377,25 +425,15 @@ func (serv *Service) CreateAndSignTransactionAdvanced(params CreateTransactionPa
 
 // UpdateWalletLabel updates the wallet label
 func (serv *Service) UpdateWalletLabel(wltID, label string) error {
-	serv.Lock()
-	defer serv.Unlock()
 	if !serv.enableWalletAPI {
 		return ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltID)
-	if err != nil {
-		return err
-	}
-
-	w.setLabel(label)
-
-	if err := w.Save(serv.walletDirectory); err != nil {
-		return err
-	}
-
-	serv.wallets.set(w)
-	return nil
+	_, err := serv.updateWallet(wltID, func(w *Wallet) error {
+		w.setLabel(label)
+		return nil
+	})
+	return err
 }
 
 // Remove removes wallet of given wallet id from the service
@@ -406,13 +444,21 @@ func (serv *Service) Remove(wltID string) error {
 		return ErrWalletAPIDisabled
 	}
 
-	wlt := serv.wallets.get(wltID)
-	if wlt != nil && len(wlt.Entries) > 0 {
-		addr := wlt.Entries[0].Address.String()
+	sw := serv.wallets[wltID]
+	if sw == nil {
+		return nil
+	}
+
+	sw.RLock()
+	defer sw.RUnlock()
+
+	if len(sw.wallet.Entries) > 0 {
+		addr := sw.wallet.Entries[0].Address.String()
 		delete(serv.firstAddrIDMap, addr)
 	}
 
-	serv.wallets.remove(wltID)
+	delete(serv.wallets, wltID)
+	sw.encoding.erase()
 	return nil
 }
 
@@ -460,8 +506,6 @@ func (serv *Service) removeDup(wlts Wallets) Wallets {
 // GetWalletSeed returns seed of encrypted wallet of given wallet id
 // Returns ErrWalletNotEncrypted if it's not encrypted
 func (serv *Service) GetWalletSeed(wltID string, password []byte) (string, error) {
-	serv.RLock()
-	defer serv.RUnlock()
 	if !serv.enableWalletAPI {
 		return "", ErrWalletAPIDisabled
 	}
@@ -470,19 +514,16 @@ func (serv *Service) GetWalletSeed(wltID string, password []byte) (string, error
 		return "", ErrSeedAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltID)
-	if err != nil {
-		return "", err
-	}
-
-	if !w.IsEncrypted() {
-		return "", ErrWalletNotEncrypted
-	}
-
 	var seed string
-	if err := w.GuardView(password, func(wlt *Wallet) error {
-		seed = wlt.seed()
-		return nil
+	if err := serv.viewWallet(wltID, func(w *Wallet) error {
+		if !w.IsEncrypted() {
+			return ErrWalletNotEncrypted
+		}
+
+		return w.GuardView(password, func(wlt *Wallet) error {
+			seed = wlt.seed()
+			return nil
+		})
 	}); err != nil {
 		return "", err
 	}
@@ -492,70 +533,34 @@ func (serv *Service) GetWalletSeed(wltID string, password []byte) (string, error
 
 // UpdateSecrets opens a wallet for modification of secret data and saves it safely
 func (serv *Service) UpdateSecrets(wltID string, password []byte, f func(*Wallet) error) error {
-	serv.Lock()
-	defer serv.Unlock()
 	if !serv.enableWalletAPI {
 		return ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltID)
-	if err != nil {
-		return err
-	}
-
-	if w.IsEncrypted() {
-		if err := w.GuardUpdate(password, f); err != nil {
-			return err
-		}
-	} else if len(password) != 0 {
-		return ErrWalletNotEncrypted
-	} else {
-		if err := f(w); err != nil {
-			return err
+	_, err := serv.updateWallet(wltID, func(w *Wallet) error {
+		if w.IsEncrypted() {
+			return w.GuardUpdate(password, f)
+		} else if len(password) != 0 {
+			return ErrWalletNotEncrypted
 		}
-	}
-
-	// Save the wallet first
-	if err := w.Save(serv.walletDirectory); err != nil {
-		return err
-	}
 
-	serv.wallets.set(w)
-
-	return nil
+		return f(w)
+	})
+	return err
 }
 
 // Update opens a wallet for modification of non-secret data and saves it safely
 func (serv *Service) Update(wltID string, f func(*Wallet) error) error {
-	serv.Lock()
-	defer serv.Unlock()
 	if !serv.enableWalletAPI {
 		return ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltID)
-	if err != nil {
-		return err
-	}
-
-	if err := f(w); err != nil {
-		return err
-	}
-
-	// Save the wallet first
-	if err := w.Save(serv.walletDirectory); err != nil {
-		return err
-	}
-
-	serv.wallets.set(w)
-
-	return nil
+	_, err := serv.updateWallet(wltID, f)
+	return err
 }
 
 // ViewSecrets opens a wallet for reading secret data
 func (serv *Service) ViewSecrets(wltID string, password []byte, f func(*Wallet) error) error {
-	serv.RLock()
-	defer serv.RUnlock()
 	if !serv.enableWalletAPI {
 		return ErrWalletAPIDisabled
 	}
@@ -576,8 +581,6 @@ func (serv *Service) ViewSecrets(wltID string, password []byte, f func(*Wallet)
 
 // View opens a wallet for reading non-secret data
 func (serv *Service) View(wltID string, f func(*Wallet) error) error {
-	serv.RLock()
-	defer serv.RUnlock()
 	if !serv.enableWalletAPI {
 		return ErrWalletAPIDisabled
 	}
@@ -593,61 +596,54 @@ func (serv *Service) View(wltID string, f func(*Wallet) error) error {
 // RecoverWallet recovers an encrypted wallet from seed.
 // The recovered wallet will be encrypted with the new password, if provided.
 func (serv *Service) RecoverWallet(wltName, seed string, password []byte) (*Wallet, error) {
-	serv.Lock()
-	defer serv.Unlock()
 	if !serv.enableWalletAPI {
 		return nil, ErrWalletAPIDisabled
 	}
 
-	w, err := serv.getWallet(wltName)
-	if err != nil {
-		return nil, err
-	}
+	w, err := serv.updateWallet(wltName, func(w *Wallet) error {
+		if !w.IsEncrypted() {
+			return ErrWalletNotEncrypted
+		}
 
-	if !w.IsEncrypted() {
-		return nil, ErrWalletNotEncrypted
-	}
+		if w.Type() != WalletTypeDeterministic {
+			return ErrWalletNotDeterministic
+		}
 
-	if w.Type() != WalletTypeDeterministic {
-		return nil, ErrWalletNotDeterministic
-	}
+		// Generate the first address from the seed
+		pk, _, err := cipher.GenerateDeterministicKeyPair([]byte(seed))
+		if err != nil {
+			return err
+		}
+		addr := w.addressConstructor()(pk)
 
-	// Generate the first address from the seed
-	var pk cipher.PubKey
-	pk, _, err = cipher.GenerateDeterministicKeyPair([]byte(seed))
-	if err != nil {
-		return nil, err
-	}
-	addr := w.addressConstructor()(pk)
+		// Compare to the wallet's first address
+		if addr != w.Entries[0].Address {
+			return ErrWalletRecoverSeedWrong
+		}
 
-	// Compare to the wallet's first address
-	if addr != w.Entries[0].Address {
-		return nil, ErrWalletRecoverSeedWrong
-	}
+		// Create a new wallet with the same number of addresses, encrypting if needed
+		w2, err := NewWallet(wltName, Options{
+			Coin:       w.coin(),
+			Label:      w.Label(),
+			Seed:       seed,
+			Encrypt:    len(password) != 0,
+			Password:   password,
+			CryptoType: w.cryptoType(),
+			GenerateN:  uint64(len(w.Entries)),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Preserve the timestamp of the old wallet
+		w2.setTimestamp(w.timestamp())
 
-	// Create a new wallet with the same number of addresses, encrypting if needed
-	w2, err := NewWallet(wltName, Options{
-		Coin:       w.coin(),
-		Label:      w.Label(),
-		Seed:       seed,
-		Encrypt:    len(password) != 0,
-		Password:   password,
-		CryptoType: w.cryptoType(),
-		GenerateN:  uint64(len(w.Entries)),
+		*w = *w2
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Preserve the timestamp of the old wallet
-	w2.setTimestamp(w.timestamp())
-
-	// Save to disk
-	if err := w2.Save(serv.walletDirectory); err != nil {
-		return nil, err
-	}
-
-	serv.wallets.set(w2)
-
-	return w2.clone(), nil
+	return w.clone(), nil
 }
@@ -434,8 +434,9 @@ func TestServiceNewAddress(t *testing.T) {
 				}
 
 				// Check the wallet again
-				w, ok := s.wallets[wltName]
+				sw, ok := s.wallets[wltName]
 				require.True(t, ok)
+				w = sw.wallet
 				require.Len(t, w.Entries, int(tc.n+1))
 
 				// Wallet has a default address, so need to start from the second address
@@ -604,6 +605,7 @@ func TestServiceGetWallets(t *testing.T) {
 func TestServiceCreateAndSignTransaction(t *testing.T) {
 	headTime := time.Now().UTC().Unix()
 	seed := []byte("seed")
+	genesisHash := testutil.RandSHA256(t)
 
 	// Generate first keys
 	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed(seed, 1)
@@ -642,6 +644,7 @@ func TestServiceCreateAndSignTransaction(t *testing.T) {
 		unspents         []coin.UxOut
 		coins            uint64
 		dest             cipher.Address
+		genesisHash      *cipher.SHA256
 		disableWalletAPI bool
 		err              error
 	}{
@@ -676,6 +679,16 @@ func TestServiceCreateAndSignTransaction(t *testing.T) {
 			coins:    1e6,
 			dest:     addrs[0],
 		},
+		{
+			name: "encrypted=false replay protected",
+			opts: Options{
+				Seed: string(seed),
+			},
+			unspents:    uxouts[:],
+			coins:       1e6,
+			dest:        addrs[0],
+			genesisHash: &genesisHash,
+		},
 		{
 			name: "encrypted=false spend zero",
 			opts: Options{
@@ -759,7 +772,7 @@ func TestServiceCreateAndSignTransaction(t *testing.T) {
 				require.NoError(t, err)
 
 				if tc.disableWalletAPI {
-					_, err = s.CreateAndSignTransaction("", tc.pwd, addrUxOuts, uint64(headTime), tc.coins, tc.dest)
+					_, err = s.CreateAndSignTransaction("", tc.pwd, addrUxOuts, uint64(headTime), tc.coins, tc.dest, tc.genesisHash)
 					require.Equal(t, tc.err, err)
 					return
 				}
@@ -769,7 +782,7 @@ func TestServiceCreateAndSignTransaction(t *testing.T) {
 				w, err := s.CreateWallet(wltName, tc.opts, nil)
 				require.NoError(t, err)
 
-				tx, err := s.CreateAndSignTransaction(w.Filename(), tc.pwd, addrUxOuts, uint64(headTime), tc.coins, tc.dest)
+				tx, err := s.CreateAndSignTransaction(w.Filename(), tc.pwd, addrUxOuts, uint64(headTime), tc.coins, tc.dest, tc.genesisHash)
 
 				if tc.err != nil {
 					require.Error(t, err)
@@ -787,6 +800,12 @@ func TestServiceCreateAndSignTransaction(t *testing.T) {
 
 				err = tx.Verify()
 				require.NoError(t, err)
+
+				if tc.genesisHash != nil {
+					require.Equal(t, coin.TxnTypeReplayProtected, tx.Type)
+				} else {
+					require.Equal(t, coin.TxnTypeDefault, tx.Type)
+				}
 			})
 		}
 	}
@@ -1680,7 +1699,7 @@ func TestServiceCreateAndSignTransactionAdvanced(t *testing.T) {
 
 				s.enableWalletAPI = !tc.disableWalletAPI
 
-				txn, inputs, err := s.CreateAndSignTransactionAdvanced(tc.params, addrUxOuts, tc.headTime)
+				txn, inputs, err := s.CreateAndSignTransactionAdvanced(tc.params, addrUxOuts, tc.headTime, nil)
 				if tc.err != nil {
 					require.Equal(t, tc.err, err)
 					return
@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"math/big"
 	"os"
 	"path/filepath"
 	"sort"
@@ -214,11 +213,12 @@ type CreateTransactionWalletParams struct {
 
 // CreateTransactionParams defines control parameters for transaction construction
 type CreateTransactionParams struct {
-	IgnoreUnconfirmed bool
-	HoursSelection    HoursSelection
-	Wallet            CreateTransactionWalletParams
-	ChangeAddress     *cipher.Address
-	To                []coin.TransactionOutput
+	IgnoreUnconfirmed      bool
+	SpendUnconfirmedChange bool
+	HoursSelection         HoursSelection
+	Wallet                 CreateTransactionWalletParams
+	ChangeAddress          *cipher.Address
+	To                     []coin.TransactionOutput
 }
 
 // Validate validates CreateTransactionParams
@@ -356,9 +356,15 @@ type Options struct {
 // Entries field stores the address entries that are deterministically generated
 // from seed.
 // For wallet encryption
+// Entries should only be modified with the Wallet's methods, which keep the index of entries by address up to date.
+// Entries modified directly are still found, but without the index
 type Wallet struct {
 	Meta    map[string]string
 	Entries []Entry
+
+	// entriesIndex maps an entry's address to its position in Entries.
+	// A position is only trusted if the entry at that position still has the address
+	entriesIndex map[cipher.Addresser]int
 }
 
 // newWallet creates a wallet instance with given name and options.
@@ -615,6 +621,7 @@ func (w *Wallet) copyFrom(src *Wallet) {
 
 	// Copies the address entries
 	w.Entries = append(w.Entries, src.Entries...)
+	w.indexEntries()
 }
 
 // Erase wipes secret fields in wallet
@@ -763,12 +770,6 @@ func filterDir(dir string, suffix string) ([]string, error) {
 	return res, nil
 }
 
-// reset resets the wallet entries and move the lastSeed to origin
-func (w *Wallet) reset() {
-	w.Entries = []Entry{}
-	w.setLastSeed(w.seed())
-}
-
 // Validate validates the wallet
 func (w *Wallet) Validate() error {
 	if fn := w.Meta[metaFilename]; fn == "" {
@@ -982,7 +983,7 @@ func (w *Wallet) GenerateAddresses(num uint64) ([]cipher.Addresser, error) {
 		p := cipher.MustPubKeyFromSecKey(s)
 		a := makeAddress(p)
 		addrs[i] = a
-		w.Entries = append(w.Entries, Entry{
+		w.appendEntry(Entry{
 			Address: a,
 			Secret:  s,
 			Public:  p,
@@ -1024,7 +1025,6 @@ func (w *Wallet) ScanAddresses(scanN uint64, bg BalanceGetter) (uint64, error) {
 
 	w2 := w.clone()
 
-	nExistingAddrs := uint64(len(w2.Entries))
 	nAddAddrs := uint64(0)
 	n := scanN
 	extraScan := uint64(0)
@@ -1065,14 +1065,15 @@ func (w *Wallet) ScanAddresses(scanN uint64, bg BalanceGetter) (uint64, error) {
 		n = scanN - extraScan
 	}
 
-	// Regenerate addresses up to nExistingAddrs + nAddAddrss.
+	// Generate the addresses to keep from the wallet's lastSeed.
 	// This is necessary to keep the lastSeed updated.
-	w2.reset()
-	if _, err := w2.GenerateSkycoinAddresses(nExistingAddrs + nAddAddrs); err != nil {
+	// The existing addresses are not regenerated, since they are unchanged.
+	w3 := w.clone()
+	if _, err := w3.GenerateSkycoinAddresses(nAddAddrs); err != nil {
 		return 0, err
 	}
 
-	*w = *w2
+	*w = *w3
 
 	return nAddAddrs, nil
 }
@@ -1101,6 +1102,15 @@ func (w *Wallet) GetSkycoinAddresses() ([]cipher.Address, error) {
 
 // GetEntry returns entry of given address
 func (w *Wallet) GetEntry(a cipher.Address) (Entry, bool) {
+	if w.entriesIndexed() {
+		if i, ok := w.entriesIndex[a]; ok && i < len(w.Entries) && w.Entries[i].SkycoinAddress() == a {
+			return w.Entries[i], true
+		}
+	}
+
+	// The address is not indexed, or Entries was modified directly so that the index is stale.
+	// Entries replaced directly are not in the index, so the entries are scanned before reporting
+	// the address as missing. Lookups of the wallet's own addresses are found in the index
 	for _, e := range w.Entries {
 		if e.SkycoinAddress() == a {
 			return e, true
@@ -1112,16 +1122,37 @@ func (w *Wallet) GetEntry(a cipher.Address) (Entry, bool) {
 // AddEntry adds new entry
 func (w *Wallet) AddEntry(entry Entry) error {
 	// dup check
-	for _, e := range w.Entries {
-		if e.SkycoinAddress() == entry.SkycoinAddress() {
-			return errors.New("duplicate address entry")
-		}
+	if _, ok := w.GetEntry(entry.SkycoinAddress()); ok {
+		return errors.New("duplicate address entry")
 	}
 
-	w.Entries = append(w.Entries, entry)
+	w.appendEntry(entry)
 	return nil
 }
 
+// appendEntry appends an entry to Entries and adds it to the index of entries by address
+func (w *Wallet) appendEntry(e Entry) {
+	if !w.entriesIndexed() {
+		w.indexEntries()
+	}
+
+	w.entriesIndex[e.Address] = len(w.Entries)
+	w.Entries = append(w.Entries, e)
+}
+
+// indexEntries rebuilds the index of entries by address
+func (w *Wallet) indexEntries() {
+	w.entriesIndex = make(map[cipher.Addresser]int, len(w.Entries))
+	for i, e := range w.Entries {
+		w.entriesIndex[e.Address] = i
+	}
+}
+
+// entriesIndexed returns true if the index of entries by address is in sync with Entries
+func (w *Wallet) entriesIndexed() bool {
+	return w.entriesIndex != nil && len(w.entriesIndex) == len(w.Entries)
+}
+
 // clone returns the clone of self
 func (w *Wallet) clone() *Wallet {
 	wlt := Wallet{Meta: make(map[string]string)}
@@ -1131,6 +1162,15 @@ func (w *Wallet) clone() *Wallet {
 
 	wlt.Entries = append(wlt.Entries, w.Entries...)
 
+	if w.entriesIndexed() {
+		wlt.entriesIndex = make(map[cipher.Addresser]int, len(w.entriesIndex))
+		for k, v := range w.entriesIndex {
+			wlt.entriesIndex[k] = v
+		}
+	} else {
+		wlt.indexEntries()
+	}
+
 	return &wlt
 }
 
@@ -1141,8 +1181,9 @@ type Validator interface {
 }
 
 // CreateAndSignTransaction Creates a Transaction
-// spending coins and hours from wallet
-func (w *Wallet) CreateAndSignTransaction(auxs coin.AddressUxOuts, headTime, coins uint64, dest cipher.Address) (*coin.Transaction, error) {
+// spending coins and hours from wallet.
+// If genesisHash is not nil, the transaction is replay protected for the chain of that genesis block
+func (w *Wallet) CreateAndSignTransaction(auxs coin.AddressUxOuts, headTime, coins uint64, dest cipher.Address, genesisHash *cipher.SHA256) (*coin.Transaction, error) {
 	if w.IsEncrypted() {
 		return nil, ErrWalletEncrypted
 	}
@@ -1210,7 +1251,7 @@ func (w *Wallet) CreateAndSignTransaction(auxs coin.AddressUxOuts, headTime, coi
 
 	txn.PushOutput(dest, coins, addrHours[0])
 
-	txn.SignInputs(toSign)
+	signInputs(&txn, toSign, genesisHash)
 	if err := txn.UpdateHeader(); err != nil {
 		logger.Critical().WithError(err).Error("txn.UpdateHeader failed")
 		return nil, err
@@ -1232,7 +1273,8 @@ func (w *Wallet) CreateAndSignTransaction(auxs coin.AddressUxOuts, headTime, coi
 //     if the coinhour cost of adding that output is less than the coinhours that would be lost as change
 // If receiving hours are not explicitly specified, hours are allocated amongst the receiving outputs proportional to the number of coins being sent to them.
 // If the change address is not specified, the address whose bytes are lexically sorted first is chosen from the owners of the outputs being spent.
-func (w *Wallet) CreateAndSignTransactionAdvanced(p CreateTransactionParams, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []UxBalance, error) {
+// If genesisHash is not nil, the transaction is replay protected for the chain of that genesis block.
+func (w *Wallet) CreateAndSignTransactionAdvanced(p CreateTransactionParams, auxs coin.AddressUxOuts, headTime uint64, genesisHash *cipher.SHA256) (*coin.Transaction, []UxBalance, error) {
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
 	}
@@ -1456,7 +1498,7 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(p CreateTransactionParams, aux
 			return nil, nil, errors.New("share factor is 1.0 but changeHours > 0 unexpectedly")
 		}
 		p.HoursSelection.ShareFactor = &oneDecimal
-		return w.CreateAndSignTransactionAdvanced(p, auxs, headTime)
+		return w.CreateAndSignTransactionAdvanced(p, auxs, headTime, genesisHash)
 	}
 
 	if changeCoins > 0 {
@@ -1491,7 +1533,7 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(p CreateTransactionParams, aux
 		txn.PushOutput(changeAddress, changeCoins, changeHours)
 	}
 
-	txn.SignInputs(toSign)
+	signInputs(txn, toSign, genesisHash)
 	if err := txn.UpdateHeader(); err != nil {
 		logger.Critical().WithError(err).Error("txn.UpdateHeader failed")
 		return nil, nil, err
@@ -1514,6 +1556,16 @@ func (w *Wallet) CreateAndSignTransactionAdvanced(p CreateTransactionParams, aux
 	return txn, inputs, nil
 }
 
+// signInputs signs the inputs of txn, replay protected for the chain of genesisHash if it is not nil
+func signInputs(txn *coin.Transaction, keys []cipher.SecKey, genesisHash *cipher.SHA256) {
+	if genesisHash != nil {
+		txn.SignInputsForChain(keys, *genesisHash)
+		return
+	}
+
+	txn.SignInputs(keys)
+}
+
 // verifyCreatedTransactionInvariants checks that the transaction that was created matches expectations.
 // Does not call visor verification methods because that causes import cycle.
 // daemon.Gateway checks that the transaction passes additional visor verification methods.
@@ -1672,99 +1724,7 @@ func DistributeSpendHours(inputHours, nAddrs uint64, haveChange bool) (uint64, [
 
 // DistributeCoinHoursProportional distributes hours amongst coins proportional to the coins amount
 func DistributeCoinHoursProportional(coins []uint64, hours uint64) ([]uint64, error) {
-	if len(coins) == 0 {
-		return nil, errors.New("DistributeCoinHoursProportional coins array must not be empty")
-	}
-
-	coinsInt := make([]*big.Int, len(coins))
-
-	var total uint64
-	for i, c := range coins {
-		if c == 0 {
-			return nil, errors.New("DistributeCoinHoursProportional coins array has a zero value")
-		}
-
-		var err error
-		total, err = coin.AddUint64(total, c)
-		if err != nil {
-			return nil, err
-		}
-
-		cInt64, err := coin.Uint64ToInt64(c)
-		if err != nil {
-			return nil, err
-		}
-
-		coinsInt[i] = big.NewInt(cInt64)
-	}
-
-	totalInt64, err := coin.Uint64ToInt64(total)
-	if err != nil {
-		return nil, err
-	}
-	totalInt := big.NewInt(totalInt64)
-
-	hoursInt64, err := coin.Uint64ToInt64(hours)
-	if err != nil {
-		return nil, err
-	}
-	hoursInt := big.NewInt(hoursInt64)
-
-	var assignedHours uint64
-	addrHours := make([]uint64, len(coins))
-	for i, c := range coinsInt {
-		// Scale the ratio of coins to total coins proportionally by calculating
-		// (coins * totalHours) / totalCoins
-		// The remainder is truncated, remaining hours are appended after this
-		num := &big.Int{}
-		num.Mul(c, hoursInt)
-
-		fracInt := big.Int{}
-		fracInt.Div(num, totalInt)
-
-		if !fracInt.IsUint64() {
-			return nil, errors.New("DistributeCoinHoursProportional calculated fractional hours is not representable as a uint64")
-		}
-
-		fracHours := fracInt.Uint64()
-
-		addrHours[i] = fracHours
-		assignedHours, err = coin.AddUint64(assignedHours, fracHours)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if hours < assignedHours {
-		return nil, errors.New("DistributeCoinHoursProportional assigned hours exceeding input hours, this is a bug")
-	}
-
-	remainingHours := hours - assignedHours
-
-	if remainingHours > uint64(len(coins)) {
-		return nil, errors.New("DistributeCoinHoursProportional remaining hours exceed len(coins), this is a bug")
-	}
-
-	// For remaining hours lost due to fractional cutoff when scaling,
-	// first provide at least 1 coin hour to coins that were assigned 0.
-	i := 0
-	for remainingHours > 0 && i < len(coins) {
-		if addrHours[i] == 0 {
-			addrHours[i] = 1
-			remainingHours--
-		}
-		i++
-	}
-
-	// Then, assign the extra coin hours
-	i = 0
-	for remainingHours > 0 {
-		addrHours[i] = addrHours[i] + 1
-		remainingHours--
-		i++
-	}
-
-	return addrHours, nil
+	return coin.DistributeCoinHoursProportional(coins, hours)
 }
 
 // UxBalance is an intermediate representation of a UxOut for sorting and spend choosing
@@ -0,0 +1,155 @@
+package wallet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+const benchmarkWalletEntries = 100000
+
+// newBenchmarkWallet creates a wallet with benchmarkWalletEntries entries.
+// Keys are not derived from the seed, so that a large wallet can be created quickly.
+func newBenchmarkWallet(b *testing.B) *Wallet {
+	w, err := NewWallet("bench.wlt", Options{
+		Coin:  CoinTypeSkycoin,
+		Label: "bench",
+		Seed:  "benchseed",
+	})
+	require.NoError(b, err)
+
+	for i := len(w.Entries); i < benchmarkWalletEntries; i++ {
+		pk := cipher.PubKey{0x02, byte(i), byte(i >> 8), byte(i >> 16)}
+		sk := cipher.SecKey{0x01, byte(i), byte(i >> 8), byte(i >> 16)}
+		w.appendEntry(Entry{
+			Address: cipher.AddressFromPubKey(pk),
+			Public:  pk,
+			Secret:  sk,
+		})
+	}
+
+	return w
+}
+
+// newBenchmarkService creates a service managing a single wallet with benchmarkWalletEntries entries
+func newBenchmarkService(b *testing.B) (*Service, *Wallet, func()) {
+	dir := prepareWltDir()
+
+	w := newBenchmarkWallet(b)
+	sw := &serviceWallet{
+		wallet: w,
+	}
+	require.NoError(b, sw.save(w, dir))
+
+	s := &Service{
+		wallets: map[string]*serviceWallet{
+			w.Filename(): sw,
+		},
+		firstAddrIDMap: map[string]string{
+			w.Entries[0].Address.String(): w.Filename(),
+		},
+		walletDirectory: dir,
+		cryptoType:      CryptoTypeSha256Xor,
+		enableWalletAPI: true,
+	}
+
+	return s, w, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func BenchmarkWalletGetEntry(b *testing.B) {
+	w := newBenchmarkWallet(b)
+	addrs, err := w.GetSkycoinAddresses()
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := w.GetEntry(addrs[i%len(addrs)]); !ok {
+			b.Fatal("entry not found")
+		}
+	}
+}
+
+func BenchmarkWalletClone(b *testing.B) {
+	w := newBenchmarkWallet(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.clone()
+	}
+}
+
+func BenchmarkWalletSave(b *testing.B) {
+	w := newBenchmarkWallet(b)
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := w.Save(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkServiceWalletSave measures saving a wallet managed by the service, whose entries' encoding is cached.
+// The cost is dominated by rewriting the whole wallet file
+func BenchmarkServiceWalletSave(b *testing.B) {
+	s, w, cleanup := newBenchmarkService(b)
+	defer cleanup()
+
+	sw := s.wallets[w.Filename()]
+	fi, err := os.Stat(filepath.Join(s.walletDirectory, w.Filename()))
+	require.NoError(b, err)
+	b.SetBytes(fi.Size())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := sw.save(w, s.walletDirectory); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkServiceNewAddresses(b *testing.B) {
+	s, w, cleanup := newBenchmarkService(b)
+	defer cleanup()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.NewAddresses(w.Filename(), nil, 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkServiceUpdateWalletLabel(b *testing.B) {
+	s, w, cleanup := newBenchmarkService(b)
+	defer cleanup()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := s.UpdateWalletLabel(w.Filename(), "label"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkServiceGetSkycoinAddressesParallel(b *testing.B) {
+	s, w, cleanup := newBenchmarkService(b)
+	defer cleanup()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := s.GetSkycoinAddresses(w.Filename()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -9,6 +9,8 @@ import (
 	"io/ioutil"
 	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -768,6 +770,43 @@ func TestWalletGetEntry(t *testing.T) {
 	}
 }
 
+func TestWalletGetEntryReplacedInPlace(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Coin:      CoinTypeSkycoin,
+		Seed:      "fooseed",
+		GenerateN: 3,
+	})
+	require.NoError(t, err)
+	require.True(t, w.entriesIndexed())
+
+	oldAddr := w.Entries[1].SkycoinAddress()
+	p, s := cipher.GenerateKeyPair()
+	newAddr := cipher.AddressFromPubKey(p)
+
+	// Replacing an entry directly leaves the index with the same length, but stale
+	w.Entries[1] = Entry{
+		Address: newAddr,
+		Public:  p,
+		Secret:  s,
+	}
+	require.True(t, w.entriesIndexed())
+
+	_, ok := w.GetEntry(oldAddr)
+	require.False(t, ok)
+
+	e, ok := w.GetEntry(newAddr)
+	require.True(t, ok)
+	require.Equal(t, w.Entries[1], e)
+
+	e, ok = w.GetEntry(w.Entries[2].SkycoinAddress())
+	require.True(t, ok)
+	require.Equal(t, w.Entries[2], e)
+
+	// The replaced entry's address can't be added again
+	err = w.AddEntry(e)
+	require.EqualError(t, err, "duplicate address entry")
+}
+
 func TestWalletAddEntry(t *testing.T) {
 	test1SecKey, err := cipher.SecKeyFromHex("1fc5396e91e60b9fc613d004ea5bd2ccea17053a12127301b3857ead76fdb93e")
 	require.NoError(t, err)
@@ -2703,3 +2742,232 @@ func TestWalletValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestReadableEntriesCacheSave(t *testing.T) {
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	w, err := NewWallet("test.wlt", Options{
+		Coin:      CoinTypeSkycoin,
+		Label:     "foowlt",
+		Seed:      "fooseed",
+		GenerateN: 3,
+	})
+	require.NoError(t, err)
+
+	var c entriesEncodingCache
+	filename := filepath.Join(dir, "cache.wlt")
+
+	requireSameFile := func(w *Wallet) {
+		err := c.save(w, filename)
+		require.NoError(t, err)
+
+		err = w.Save(dir)
+		require.NoError(t, err)
+
+		expect, err := ioutil.ReadFile(filepath.Join(dir, w.Filename()))
+		require.NoError(t, err)
+		b, err := ioutil.ReadFile(filename)
+		require.NoError(t, err)
+		require.Equal(t, string(expect), string(b))
+
+		require.Len(t, c.entries, len(w.Entries))
+		require.Len(t, c.encoded, len(w.Entries))
+		for i, e := range w.Entries {
+			require.Equal(t, e, c.entries[i])
+		}
+	}
+
+	requireSameFile(w)
+
+	// Saving again reuses all of the encoded entries
+	encoded := c.encoded
+	requireSameFile(w)
+	for i := range encoded {
+		require.True(t, &encoded[i][0] == &c.encoded[i][0])
+	}
+
+	// New entries are appended
+	w2 := w.clone()
+	_, err = w2.GenerateAddresses(2)
+	require.NoError(t, err)
+	requireSameFile(w2)
+	for i := range encoded {
+		require.True(t, &encoded[i][0] == &c.encoded[i][0])
+	}
+
+	// Encrypting the wallet replaces all of the entries, and the old secrets are wiped
+	encoded = c.encoded
+	w3 := w2.clone()
+	err = w3.Lock([]byte("pwd"), CryptoTypeSha256Xor)
+	require.NoError(t, err)
+	requireSameFile(w3)
+	for _, b := range encoded {
+		require.Equal(t, make([]byte, len(b)), b)
+	}
+	for _, e := range c.entries {
+		require.Equal(t, cipher.SecKey{}, e.Secret)
+	}
+
+	// Wallet without entries
+	w4 := w3.clone()
+	w4.Entries = nil
+	w4.indexEntries()
+	requireSameFile(w4)
+
+	c.erase()
+	require.Empty(t, c.entries)
+	require.Empty(t, c.encoded)
+}
+
+func TestWalletCreateAndSignHTLCSpend(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Coin:      CoinTypeSkycoin,
+		Seed:      "fooseed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+
+	preimage := testutil.RandSHA256(t)
+	h := coin.HTLC{
+		Hashlock:   cipher.SumSHA256(preimage[:]),
+		Recipient:  w.Entries[0].SkycoinAddress(),
+		Sender:     w.Entries[1].SkycoinAddress(),
+		LockHeight: 100,
+	}
+
+	makeHTLCUxOut := func(coins, hours uint64) coin.UxOut {
+		ux := makeUxOut(t, w.Entries[0].Secret, coins, hours)
+		ux.Body.Address = h.Address()
+		return ux
+	}
+
+	uxouts := coin.UxArray{
+		makeHTLCUxOut(2e6, 100),
+		makeHTLCUxOut(3e6, 50),
+	}
+	headTime := uxouts[0].Head.Time
+	if uxouts[1].Head.Time > headTime {
+		headTime = uxouts[1].Head.Time
+	}
+
+	to := testutil.MakeAddress()
+	wrongPreimage := testutil.RandSHA256(t)
+
+	other := h
+	other.Sender = to
+	otherUxOuts := coin.UxArray{makeUxOut(t, w.Entries[0].Secret, 1e6, 10)}
+	otherUxOuts[0].Body.Address = other.Address()
+
+	cases := []struct {
+		name   string
+		params HTLCSpendParams
+		uxouts coin.UxArray
+		err    error
+		signer cipher.Address
+		to     cipher.Address
+	}{
+		{
+			name: "wrong preimage",
+			params: HTLCSpendParams{
+				WalletID: "test.wlt",
+				Contract: h,
+				Preimage: &wrongPreimage,
+			},
+			uxouts: uxouts,
+			err:    ErrHTLCInvalidPreimage,
+		},
+		{
+			name: "wrong wallet id",
+			params: HTLCSpendParams{
+				WalletID: "foo.wlt",
+				Contract: h,
+			},
+			uxouts: uxouts,
+			err:    ErrUnknownWalletID,
+		},
+		{
+			name: "no unspents",
+			params: HTLCSpendParams{
+				WalletID: "test.wlt",
+				Contract: h,
+			},
+			err: ErrNoUnspents,
+		},
+		{
+			name: "uxout not locked by the HTLC",
+			params: HTLCSpendParams{
+				WalletID: "test.wlt",
+				Contract: h,
+			},
+			uxouts: coin.UxArray{uxouts[0], makeUxOut(t, w.Entries[0].Secret, 1e6, 10)},
+			err:    ErrHTLCUnknownUxOut,
+		},
+		{
+			name: "spender not in wallet",
+			params: HTLCSpendParams{
+				WalletID: "test.wlt",
+				Contract: other,
+			},
+			uxouts: otherUxOuts,
+			err:    ErrUnknownAddress,
+		},
+		{
+			name: "claim",
+			params: HTLCSpendParams{
+				WalletID: "test.wlt",
+				Contract: h,
+				Preimage: &preimage,
+			},
+			uxouts: uxouts,
+			signer: h.Recipient,
+			to:     h.Recipient,
+		},
+		{
+			name: "refund",
+			params: HTLCSpendParams{
+				WalletID: "test.wlt",
+				Contract: h,
+				To:       &to,
+			},
+			uxouts: uxouts,
+			signer: h.Sender,
+			to:     to,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			txn, inputs, err := w.CreateAndSignHTLCSpend(tc.params, tc.uxouts, headTime)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, coin.TxnTypeHTLC, txn.Type)
+			require.Len(t, inputs, len(tc.uxouts))
+			require.NoError(t, txn.Verify())
+			require.NoError(t, txn.VerifyInput(tc.uxouts))
+
+			witnesses, err := txn.HTLCWitnesses()
+			require.NoError(t, err)
+			require.Len(t, witnesses, len(tc.uxouts))
+			for i, wt := range witnesses {
+				require.Equal(t, uint16(i), wt.Input)
+				require.Equal(t, h, wt.Contract(tc.signer))
+			}
+
+			var coins, hours uint64
+			for _, in := range inputs {
+				coins += in.Coins
+				hours += in.Hours
+			}
+
+			require.Len(t, txn.Out, 1)
+			require.Equal(t, tc.to, txn.Out[0].Address)
+			require.Equal(t, coins, txn.Out[0].Coins)
+			require.NoError(t, fee.VerifyTransactionFeeForHours(txn.Out[0].Hours, hours-txn.Out[0].Hours, params.UserVerifyTxn.BurnFactor))
+		})
+	}
+}